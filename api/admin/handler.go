@@ -0,0 +1,97 @@
+package admin
+
+import (
+	"github.com/0xsj/mios.io/log"
+	"github.com/0xsj/mios.io/pkg/context"
+	"github.com/0xsj/mios.io/pkg/response"
+	"github.com/0xsj/mios.io/service"
+	"github.com/gin-gonic/gin"
+)
+
+// Handler handles HTTP requests for operational admin tasks
+type Handler struct {
+	appLogger        log.Logger
+	retentionService service.RetentionService
+	logger           log.Logger
+}
+
+// NewHandler creates a new admin handler. appLogger is the root logger
+// whose level the log-level endpoint changes; every logger derived from it
+// shares that level.
+func NewHandler(appLogger log.Logger, retentionService service.RetentionService, logger log.Logger) *Handler {
+	return &Handler{
+		appLogger:        appLogger,
+		retentionService: retentionService,
+		logger:           logger,
+	}
+}
+
+// RegisterRoutes registers admin routes on the given router
+func (h *Handler) RegisterRoutes(r *gin.Engine) {
+	adminGroup := r.Group("/api/admin")
+	{
+		adminGroup.GET("/log-level", h.GetLogLevel)
+		adminGroup.POST("/log-level", h.SetLogLevel)
+		adminGroup.POST("/analytics/purge", h.PurgeAnalytics)
+	}
+
+	h.logger.Info("Admin routes registered successfully")
+}
+
+// GetLogLevel returns the minimum log level currently in effect
+func (h *Handler) GetLogLevel(c *gin.Context) {
+	h.logger.Debug("GetLogLevel handler called")
+
+	response.Success(c, LogLevelResponse{
+		Level: h.appLogger.Level().String(),
+	}, "Log level retrieved successfully")
+}
+
+// SetLogLevel changes the minimum log level without a restart
+func (h *Handler) SetLogLevel(c *gin.Context) {
+	h.logger.Debug("SetLogLevel handler called")
+
+	var req SetLogLevelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warnf("Invalid request format: %v", err)
+		response.Error(c, response.ErrBadRequestResponse, err.Error())
+		return
+	}
+
+	level, err := log.ParseLevel(req.Level)
+	if err != nil {
+		h.logger.Warnf("Invalid log level: %v", err)
+		response.Error(c, response.ErrBadRequestResponse, "Invalid log level, expected one of debug, info, warn, error, fatal, panic")
+		return
+	}
+
+	previous := h.appLogger.Level()
+	h.appLogger.SetLevel(level)
+
+	// Logged at warn so the change is recorded whatever the new level is
+	userID, _ := context.GetUserID(c)
+	h.logger.Warnf("Log level changed from %s to %s by user %s", previous, level, userID)
+
+	response.Success(c, LogLevelResponse{
+		Level:         level.String(),
+		PreviousLevel: previous.String(),
+	}, "Log level updated successfully")
+}
+
+// PurgeAnalytics runs the analytics retention purge now instead of waiting
+// for the daily run
+func (h *Handler) PurgeAnalytics(c *gin.Context) {
+	h.logger.Debug("PurgeAnalytics handler called")
+
+	result, err := h.retentionService.PurgeAnalytics(c)
+	if err != nil {
+		h.logger.Errorf("Failed to purge analytics: %v", err)
+		response.HandleError(c, err, h.logger)
+		return
+	}
+
+	userID, _ := context.GetUserID(c)
+	h.logger.Warnf("Analytics purge triggered by user %s removed %d entries", userID, result.TotalRemoved)
+
+	response.Success(c, result, "Analytics purged successfully")
+}
@@ -0,0 +1,17 @@
+package admin
+
+// Request types
+
+// SetLogLevelRequest represents the payload for changing the minimum log
+// level at runtime
+type SetLogLevelRequest struct {
+	Level string `json:"level" binding:"required"`
+}
+
+// Response types
+
+// LogLevelResponse reports the minimum log level now in effect
+type LogLevelResponse struct {
+	Level         string `json:"level"`
+	PreviousLevel string `json:"previous_level,omitempty"`
+}
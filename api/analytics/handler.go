@@ -1,7 +1,13 @@
 package analytics
 
 import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/0xsj/mios.io/log"
 	"github.com/0xsj/mios.io/pkg/response"
@@ -9,6 +15,10 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+// liveHeartbeatInterval is how often an idle live stream is sent a comment
+// so the connection is not closed as idle
+const liveHeartbeatInterval = 15 * time.Second
+
 // Handler handles HTTP requests for analytics operations
 type Handler struct {
 	analyticsService service.AnalyticsService
@@ -28,6 +38,8 @@ func (h *Handler) RegisterRoutes(r *gin.Engine) {
 	analyticsGroup := r.Group("/api/analytics")
 	{
 		analyticsGroup.POST("/clicks", h.RecordClick)
+		analyticsGroup.POST("/clicks/batch", h.RecordClicksBatch)
+		analyticsGroup.POST("/interactions", h.RecordInteraction)
 		analyticsGroup.POST("/page-views", h.RecordPageView)
 
 		analyticsGroup.GET("/items/:id", h.GetContentItemAnalytics)
@@ -36,8 +48,16 @@ func (h *Handler) RegisterRoutes(r *gin.Engine) {
 		analyticsGroup.GET("/users/:id", h.GetUserAnalytics)
 		analyticsGroup.POST("/users/:id/time-range", h.GetUserAnalyticsByTimeRange)
 		analyticsGroup.POST("/users/:id/page-views", h.GetProfilePageViewsByTimeRange)
+		analyticsGroup.POST("/users/:id/dates", h.GetAnalyticsForDates)
 		analyticsGroup.GET("/users/:id/dashboard", h.GetProfileDashboard)
+		analyticsGroup.GET("/users/:id/summary", h.GetSummaryCards)
 		analyticsGroup.POST("/users/:id/referrers", h.GetReferrerAnalytics)
+		analyticsGroup.POST("/users/:id/devices", h.GetDeviceBreakdown)
+		analyticsGroup.POST("/users/:id/geo", h.GetGeoAnalytics)
+		analyticsGroup.POST("/users/:id/campaigns", h.GetCampaignAnalytics)
+		analyticsGroup.GET("/users/:id/recent", h.GetRecentClicks)
+		analyticsGroup.GET("/users/:id/export", h.ExportUserAnalytics)
+		analyticsGroup.GET("/users/:id/live", h.StreamLiveEvents)
 	}
 
 	h.logger.Info("Analytics routes registered successfully")
@@ -57,11 +77,16 @@ func (h *Handler) RecordClick(c *gin.Context) {
 	h.logger.Debugf("Received click analytics for item ID: %s, user ID: %s", req.ItemID, req.UserID)
 
 	input := service.RecordClickInput{
-		ItemID:    req.ItemID,
-		UserID:    req.UserID,
-		IPAddress: req.IPAddress,
-		UserAgent: req.UserAgent,
-		Referrer:  req.Referrer,
+		ItemID:          req.ItemID,
+		UserID:          req.UserID,
+		IPAddress:       req.IPAddress,
+		UserAgent:       req.UserAgent,
+		Referrer:        req.Referrer,
+		InteractionType: req.InteractionType,
+		UTMSource:       req.UTMSource,
+		UTMMedium:       req.UTMMedium,
+		UTMCampaign:     req.UTMCampaign,
+		Query:           c.Request.URL.Query(),
 	}
 
 	err := h.analyticsService.RecordClick(c, input)
@@ -75,6 +100,85 @@ func (h *Handler) RecordClick(c *gin.Context) {
 	response.Success(c, nil, "Click recorded successfully")
 }
 
+// RecordClicksBatch records many click events in one request and reports
+// the outcome of each
+func (h *Handler) RecordClicksBatch(c *gin.Context) {
+	h.logger.Info("RecordClicksBatch handler called")
+
+	var req RecordClicksBatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warnf("Invalid request format: %v", err)
+		response.Error(c, response.ErrBadRequestResponse, err.Error())
+		return
+	}
+
+	h.logger.Debugf("Received batch of %d clicks", len(req.Clicks))
+
+	query := c.Request.URL.Query()
+	inputs := make([]service.RecordClickInput, len(req.Clicks))
+	for i, click := range req.Clicks {
+		inputs[i] = service.RecordClickInput{
+			ItemID:          click.ItemID,
+			UserID:          click.UserID,
+			IPAddress:       click.IPAddress,
+			UserAgent:       click.UserAgent,
+			Referrer:        click.Referrer,
+			InteractionType: click.InteractionType,
+			UTMSource:       click.UTMSource,
+			UTMMedium:       click.UTMMedium,
+			UTMCampaign:     click.UTMCampaign,
+			Query:           query,
+		}
+	}
+
+	result, err := h.analyticsService.RecordClicksBatch(c, inputs)
+	if err != nil {
+		h.logger.Errorf("Failed to record click batch: %v", err)
+		response.HandleError(c, err, h.logger)
+		return
+	}
+
+	h.logger.Infof("Click batch recorded: %d succeeded, %d failed", result.Succeeded, result.Failed)
+	response.Success(c, result, "Click batch processed")
+}
+
+// RecordInteraction records a non-navigation interaction (copy, share, submit) with a content item
+func (h *Handler) RecordInteraction(c *gin.Context) {
+	h.logger.Info("RecordInteraction handler called")
+
+	var req RecordClickRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warnf("Invalid request format: %v", err)
+		response.Error(c, response.ErrBadRequestResponse, err.Error())
+		return
+	}
+
+	h.logger.Debugf("Received %s interaction for item ID: %s, user ID: %s", req.InteractionType, req.ItemID, req.UserID)
+
+	input := service.RecordClickInput{
+		ItemID:          req.ItemID,
+		UserID:          req.UserID,
+		IPAddress:       req.IPAddress,
+		UserAgent:       req.UserAgent,
+		Referrer:        req.Referrer,
+		InteractionType: req.InteractionType,
+		UTMSource:       req.UTMSource,
+		UTMMedium:       req.UTMMedium,
+		UTMCampaign:     req.UTMCampaign,
+		Query:           c.Request.URL.Query(),
+	}
+
+	err := h.analyticsService.RecordInteraction(c, input)
+	if err != nil {
+		h.logger.Errorf("Failed to record interaction: %v", err)
+		response.HandleError(c, err, h.logger)
+		return
+	}
+
+	h.logger.Infof("Interaction recorded successfully for item ID: %s", req.ItemID)
+	response.Success(c, nil, "Interaction recorded successfully")
+}
+
 // RecordPageView records a page view analytics event
 func (h *Handler) RecordPageView(c *gin.Context) {
 	h.logger.Info("RecordPageView handler called")
@@ -89,11 +193,15 @@ func (h *Handler) RecordPageView(c *gin.Context) {
 	h.logger.Debugf("Received page view analytics for profile ID: %s, user ID: %s", req.ProfileID, req.UserID)
 
 	input := service.RecordPageViewInput{
-		ProfileID: req.ProfileID,
-		UserID:    req.UserID,
-		IPAddress: req.IPAddress,
-		UserAgent: req.UserAgent,
-		Referrer:  req.Referrer,
+		ProfileID:   req.ProfileID,
+		UserID:      req.UserID,
+		IPAddress:   req.IPAddress,
+		UserAgent:   req.UserAgent,
+		Referrer:    req.Referrer,
+		UTMSource:   req.UTMSource,
+		UTMMedium:   req.UTMMedium,
+		UTMCampaign: req.UTMCampaign,
+		Query:       c.Request.URL.Query(),
 	}
 
 	err := h.analyticsService.RecordPageView(c, input)
@@ -162,9 +270,10 @@ func (h *Handler) GetUserAnalyticsByTimeRange(c *gin.Context) {
 	h.logger.Debugf("Time range parameters: start=%s, end=%s", req.StartDate, req.EndDate)
 
 	input := service.TimeRangeInput{
-		StartDate: req.StartDate,
-		EndDate:   req.EndDate,
-		Limit:     req.Limit,
+		StartDate:   req.StartDate,
+		EndDate:     req.EndDate,
+		Limit:       req.Limit,
+		IncludeBots: req.IncludeBots,
 	}
 
 	analytics, err := h.analyticsService.GetUserAnalyticsByTimeRange(c, userID, input)
@@ -179,6 +288,29 @@ func (h *Handler) GetUserAnalyticsByTimeRange(c *gin.Context) {
 	response.Success(c, analytics, "User time range analytics retrieved successfully")
 }
 
+// GetAnalyticsForDates retrieves click counts for a set of specific dates
+func (h *Handler) GetAnalyticsForDates(c *gin.Context) {
+	userID := c.Param("id")
+	h.logger.Debugf("GetAnalyticsForDates handler called for user ID: %s", userID)
+
+	var req DatesAnalyticsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warnf("Invalid request format: %v", err)
+		response.Error(c, response.ErrBadRequestResponse, err.Error())
+		return
+	}
+
+	counts, err := h.analyticsService.GetAnalyticsForDates(c, userID, req.Dates)
+	if err != nil {
+		h.logger.Warnf("Failed to retrieve analytics for dates: %v", err)
+		response.HandleError(c, err, h.logger)
+		return
+	}
+
+	h.logger.Debugf("Retrieved analytics for %d dates for user ID: %s", len(counts), userID)
+	response.Success(c, counts, "Analytics for dates retrieved successfully")
+}
+
 // GetItemAnalyticsByTimeRange retrieves content item analytics within a specific time range
 func (h *Handler) GetItemAnalyticsByTimeRange(c *gin.Context) {
 	itemID := c.Param("id")
@@ -194,9 +326,10 @@ func (h *Handler) GetItemAnalyticsByTimeRange(c *gin.Context) {
 	h.logger.Debugf("Time range parameters: start=%s, end=%s", req.StartDate, req.EndDate)
 
 	input := service.TimeRangeInput{
-		StartDate: req.StartDate,
-		EndDate:   req.EndDate,
-		Limit:     req.Limit,
+		StartDate:   req.StartDate,
+		EndDate:     req.EndDate,
+		Limit:       req.Limit,
+		IncludeBots: req.IncludeBots,
 	}
 
 	analytics, err := h.analyticsService.GetItemAnalyticsByTimeRange(c, itemID, input)
@@ -226,9 +359,10 @@ func (h *Handler) GetProfilePageViewsByTimeRange(c *gin.Context) {
 	h.logger.Debugf("Time range parameters: start=%s, end=%s", req.StartDate, req.EndDate)
 
 	input := service.TimeRangeInput{
-		StartDate: req.StartDate,
-		EndDate:   req.EndDate,
-		Limit:     req.Limit,
+		StartDate:   req.StartDate,
+		EndDate:     req.EndDate,
+		Limit:       req.Limit,
+		IncludeBots: req.IncludeBots,
 	}
 
 	analytics, err := h.analyticsService.GetProfilePageViewsByTimeRange(c, userID, input)
@@ -254,9 +388,11 @@ func (h *Handler) GetProfileDashboard(c *gin.Context) {
 		days = 30
 	}
 
+	includeBots, _ := strconv.ParseBool(c.DefaultQuery("include_bots", "false"))
+
 	h.logger.Debugf("Dashboard time range: last %d days", days)
 
-	dashboard, err := h.analyticsService.GetProfileDashboard(c, userID, days)
+	dashboard, err := h.analyticsService.GetProfileDashboard(c, userID, days, includeBots)
 	if err != nil {
 		h.logger.Warnf("Failed to retrieve profile dashboard: %v", err)
 		response.HandleError(c, err, h.logger)
@@ -268,6 +404,29 @@ func (h *Handler) GetProfileDashboard(c *gin.Context) {
 	response.Success(c, dashboard, "Profile dashboard retrieved successfully")
 }
 
+// GetSummaryCards retrieves only the headline KPIs for a user profile
+func (h *Handler) GetSummaryCards(c *gin.Context) {
+	userID := c.Param("id")
+	h.logger.Debugf("GetSummaryCards handler called for user ID: %s", userID)
+
+	days, err := strconv.Atoi(c.DefaultQuery("days", "30"))
+	if err != nil {
+		h.logger.Warnf("Invalid days parameter: %v, using default of 30", err)
+		days = 30
+	}
+
+	includeBots, _ := strconv.ParseBool(c.DefaultQuery("include_bots", "false"))
+
+	cards, err := h.analyticsService.GetSummaryCards(c, userID, days, includeBots)
+	if err != nil {
+		h.logger.Warnf("Failed to retrieve summary cards: %v", err)
+		response.HandleError(c, err, h.logger)
+		return
+	}
+
+	response.Success(c, cards, "Summary cards retrieved successfully")
+}
+
 // GetReferrerAnalytics retrieves analytics about referrers to a user's content
 func (h *Handler) GetReferrerAnalytics(c *gin.Context) {
 	userID := c.Param("id")
@@ -283,9 +442,10 @@ func (h *Handler) GetReferrerAnalytics(c *gin.Context) {
 	h.logger.Debugf("Time range parameters: start=%s, end=%s", req.StartDate, req.EndDate)
 
 	input := service.TimeRangeInput{
-		StartDate: req.StartDate,
-		EndDate:   req.EndDate,
-		Limit:     req.Limit,
+		StartDate:   req.StartDate,
+		EndDate:     req.EndDate,
+		Limit:       req.Limit,
+		IncludeBots: req.IncludeBots,
 	}
 
 	analytics, err := h.analyticsService.GetReferrerAnalytics(c, userID, input)
@@ -300,6 +460,355 @@ func (h *Handler) GetReferrerAnalytics(c *gin.Context) {
 	response.Success(c, analytics, "Referrer analytics retrieved successfully")
 }
 
+// GetDeviceBreakdown splits a user's analytics by visitor device type,
+// operating system and browser
+func (h *Handler) GetDeviceBreakdown(c *gin.Context) {
+	userID := c.Param("id")
+	h.logger.Debugf("GetDeviceBreakdown handler called for user ID: %s", userID)
+
+	var req TimeRangeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warnf("Invalid request format: %v", err)
+		response.Error(c, response.ErrBadRequestResponse, err.Error())
+		return
+	}
+
+	input := service.TimeRangeInput{
+		StartDate:   req.StartDate,
+		EndDate:     req.EndDate,
+		IncludeBots: req.IncludeBots,
+	}
+
+	breakdown, err := h.analyticsService.GetDeviceBreakdown(c, userID, input)
+	if err != nil {
+		h.logger.Warnf("Failed to retrieve device breakdown: %v", err)
+		response.HandleError(c, err, h.logger)
+		return
+	}
+
+	h.logger.Debugf("Retrieved device breakdown for user ID: %s covering %d events", userID, breakdown.TotalCount)
+	response.Success(c, breakdown, "Device breakdown retrieved successfully")
+}
+
+// GetGeoAnalytics splits a user's analytics by the country and city of the
+// visitor's IP address
+func (h *Handler) GetGeoAnalytics(c *gin.Context) {
+	userID := c.Param("id")
+	h.logger.Debugf("GetGeoAnalytics handler called for user ID: %s", userID)
+
+	var req TimeRangeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warnf("Invalid request format: %v", err)
+		response.Error(c, response.ErrBadRequestResponse, err.Error())
+		return
+	}
+
+	input := service.TimeRangeInput{
+		StartDate:   req.StartDate,
+		EndDate:     req.EndDate,
+		Limit:       req.Limit,
+		IncludeBots: req.IncludeBots,
+	}
+
+	analytics, err := h.analyticsService.GetGeoAnalytics(c, userID, input)
+	if err != nil {
+		h.logger.Warnf("Failed to retrieve geographic analytics: %v", err)
+		response.HandleError(c, err, h.logger)
+		return
+	}
+
+	h.logger.Debugf("Retrieved geographic analytics for user ID: %s with %d countries",
+		userID, len(analytics.Countries))
+	response.Success(c, analytics, "Geographic analytics retrieved successfully")
+}
+
+// GetCampaignAnalytics groups clicks and page views by their UTM source,
+// medium and campaign
+func (h *Handler) GetCampaignAnalytics(c *gin.Context) {
+	userID := c.Param("id")
+	h.logger.Debugf("GetCampaignAnalytics handler called for user ID: %s", userID)
+
+	var req TimeRangeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warnf("Invalid request format: %v", err)
+		response.Error(c, response.ErrBadRequestResponse, err.Error())
+		return
+	}
+
+	input := service.TimeRangeInput{
+		StartDate:   req.StartDate,
+		EndDate:     req.EndDate,
+		Limit:       req.Limit,
+		IncludeBots: req.IncludeBots,
+	}
+
+	analytics, err := h.analyticsService.GetCampaignAnalytics(c, userID, input)
+	if err != nil {
+		h.logger.Warnf("Failed to retrieve campaign analytics: %v", err)
+		response.HandleError(c, err, h.logger)
+		return
+	}
+
+	h.logger.Debugf("Retrieved campaign analytics for user ID: %s with %d sources",
+		userID, len(analytics.Sources))
+	response.Success(c, analytics, "Campaign analytics retrieved successfully")
+}
+
+// GetRecentClicks lists a user's latest clicks, newest first, for an
+// activity feed. The optional limit query parameter defaults to 20.
+func (h *Handler) GetRecentClicks(c *gin.Context) {
+	userID := c.Param("id")
+	h.logger.Debugf("GetRecentClicks handler called for user ID: %s", userID)
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil {
+		h.logger.Warnf("Invalid limit parameter: %v, using default of 20", err)
+		limit = 20
+	}
+
+	clicks, err := h.analyticsService.GetRecentClicks(c, userID, limit)
+	if err != nil {
+		h.logger.Warnf("Failed to retrieve recent clicks: %v", err)
+		response.HandleError(c, err, h.logger)
+		return
+	}
+
+	h.logger.Debugf("Retrieved %d recent clicks for user ID: %s", len(clicks), userID)
+	response.Success(c, clicks, "Recent clicks retrieved successfully")
+}
+
+// RebuildRollups starts a background job that recomputes daily rollups
+func (h *Handler) RebuildRollups(c *gin.Context) {
+	h.logger.Debug("RebuildRollups handler called")
+
+	var req RebuildRollupsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warnf("Invalid request format: %v", err)
+		response.Error(c, response.ErrBadRequestResponse, err.Error())
+		return
+	}
+
+	startDate, err := time.Parse(time.RFC3339, req.StartDate)
+	if err != nil {
+		h.logger.Warnf("Invalid start date format: %v", err)
+		response.Error(c, response.ErrBadRequestResponse, "Invalid start date format, expected RFC3339")
+		return
+	}
+
+	endDate, err := time.Parse(time.RFC3339, req.EndDate)
+	if err != nil {
+		h.logger.Warnf("Invalid end date format: %v", err)
+		response.Error(c, response.ErrBadRequestResponse, "Invalid end date format, expected RFC3339")
+		return
+	}
+
+	job, err := h.analyticsService.StartRollupRebuild(req.UserID, startDate, endDate)
+	if err != nil {
+		h.logger.Warnf("Failed to start rollup rebuild: %v", err)
+		response.HandleError(c, err, h.logger)
+		return
+	}
+
+	h.logger.Infof("Rollup rebuild job %s started with %d chunks", job.ID, job.TotalChunks)
+	response.Success(c, job, "Rollup rebuild started", http.StatusAccepted)
+}
+
+// GetRollupJob reports the progress of a rollup rebuild job
+func (h *Handler) GetRollupJob(c *gin.Context) {
+	jobID := c.Param("job_id")
+	h.logger.Debugf("GetRollupJob handler called for job ID: %s", jobID)
+
+	job, err := h.analyticsService.GetRollupJob(jobID)
+	if err != nil {
+		h.logger.Warnf("Failed to retrieve rollup job: %v", err)
+		response.HandleError(c, err, h.logger)
+		return
+	}
+
+	response.Success(c, job, "Rollup job retrieved successfully")
+}
+
+// ExportUserAnalytics streams a user's raw analytics as a file download.
+// The file is gzipped when the client sends Accept-Encoding: gzip or asks
+// for a compressed file with compress=true. With async=true the export is
+// generated in the background and a download link is emailed instead.
+func (h *Handler) ExportUserAnalytics(c *gin.Context) {
+	userID := c.Param("id")
+	h.logger.Debugf("ExportUserAnalytics handler called for user ID: %s", userID)
+
+	if !h.requireSelf(c, userID) {
+		return
+	}
+
+	input := service.ExportInput{
+		StartDate: c.Query("start"),
+		EndDate:   c.Query("end"),
+		Format:    c.DefaultQuery("format", service.ExportFormatJSON),
+	}
+
+	if c.Query("async") == "true" {
+		job, err := h.analyticsService.StartUserAnalyticsExport(c, userID, input)
+		if err != nil {
+			h.logger.Warnf("Failed to start analytics export: %v", err)
+			response.HandleError(c, err, h.logger)
+			return
+		}
+
+		h.logger.Infof("Analytics export job %s started for user ID: %s", job.ID, userID)
+		response.Success(c, job, "Export started, a download link will be emailed when it is ready", http.StatusAccepted)
+		return
+	}
+
+	// The export keeps reading after this handler's gin context is recycled
+	// if the client goes away, so hand it the request context instead
+	reader, err := h.analyticsService.ExportUserAnalytics(c.Request.Context(), userID, input)
+	if err != nil {
+		h.logger.Warnf("Failed to export analytics: %v", err)
+		response.HandleError(c, err, h.logger)
+		return
+	}
+	defer reader.Close()
+
+	filename := fmt.Sprintf("analytics-%s.%s", userID, input.Format)
+	contentType := exportContentTypes[input.Format]
+
+	compress := c.Query("compress") == "true"
+	encode := !compress && acceptsGzip(c.GetHeader("Accept-Encoding"))
+	if compress {
+		filename += ".gz"
+		contentType = "application/gzip"
+	}
+	if encode {
+		c.Header("Content-Encoding", "gzip")
+		c.Header("Vary", "Accept-Encoding")
+	}
+
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", filename))
+	c.Status(http.StatusOK)
+
+	var out io.Writer = c.Writer
+	var compressed *gzip.Writer
+	if compress || encode {
+		compressed = gzip.NewWriter(c.Writer)
+		out = compressed
+	}
+
+	if _, err := io.Copy(out, reader); err != nil {
+		// Headers are already sent, so the truncated body is all the
+		// client will see
+		h.logger.Errorf("Analytics export for user ID %s ended early: %v", userID, err)
+		c.Abort()
+		return
+	}
+	if compressed != nil {
+		if err := compressed.Close(); err != nil {
+			h.logger.Errorf("Failed to finish compressed analytics export for user ID %s: %v", userID, err)
+			return
+		}
+	}
+
+	h.logger.Infof("Analytics export completed for user ID: %s", userID)
+}
+
+// exportContentTypes maps export formats to their MIME types
+var exportContentTypes = map[string]string{
+	service.ExportFormatJSON: "application/json",
+	service.ExportFormatCSV:  "text/csv; charset=utf-8",
+}
+
+// acceptsGzip reports whether an Accept-Encoding header allows gzip
+func acceptsGzip(acceptEncoding string) bool {
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		encoding := strings.TrimSpace(part)
+		if name, params, found := strings.Cut(encoding, ";"); found {
+			encoding = strings.TrimSpace(name)
+			if strings.ReplaceAll(strings.TrimSpace(params), " ", "") == "q=0" {
+				continue
+			}
+		}
+		if encoding == "gzip" || encoding == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// StreamLiveEvents relays the user's clicks and page views as server-sent
+// events until the client disconnects
+func (h *Handler) StreamLiveEvents(c *gin.Context) {
+	userID := c.Param("id")
+	h.logger.Debugf("StreamLiveEvents handler called for user ID: %s", userID)
+
+	if !h.requireSelf(c, userID) {
+		return
+	}
+
+	ctx := c.Request.Context()
+	sub, err := h.analyticsService.SubscribeLiveEvents(ctx, userID)
+	if err != nil {
+		h.logger.Warnf("Failed to subscribe to live analytics: %v", err)
+		response.HandleError(c, err, h.logger)
+		return
+	}
+	defer sub.Close()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	// Stop nginx from buffering the stream
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	h.logger.Infof("Live analytics stream opened for user ID: %s", userID)
+
+	heartbeat := time.NewTicker(liveHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		var err error
+		select {
+		case <-ctx.Done():
+			h.logger.Infof("Live analytics stream closed for user ID: %s", userID)
+			return
+		case payload, ok := <-sub.Messages():
+			if !ok {
+				h.logger.Warnf("Live analytics subscription ended for user ID: %s", userID)
+				return
+			}
+			_, err = fmt.Fprintf(c.Writer, "event: analytics\ndata: %s\n\n", payload)
+		case <-heartbeat.C:
+			// Comment lines keep proxies from timing out an idle stream
+			_, err = io.WriteString(c.Writer, ": heartbeat\n\n")
+		}
+		if err != nil {
+			h.logger.Debugf("Live analytics client for user ID %s went away: %v", userID, err)
+			return
+		}
+		c.Writer.Flush()
+	}
+}
+
+// requireSelf rejects the request unless the authenticated user is the
+// user named in the path.
+func (h *Handler) requireSelf(c *gin.Context, userID string) bool {
+	authUserID, exists := c.Get("user_id")
+	if !exists {
+		h.logger.Warn("User ID not found in context")
+		response.Error(c, response.ErrUnauthorizedResponse, "User not authenticated")
+		return false
+	}
+
+	if authUserID.(string) != userID {
+		h.logger.Warnf("User %v attempted to access analytics of user %s", authUserID, userID)
+		response.Error(c, response.ErrForbiddenResponse, "You can only access your own analytics")
+		return false
+	}
+
+	return true
+}
+
 // getPaginationParams extracts and validates pagination parameters from the request
 func getPaginationParams(c *gin.Context) (int, int) {
 	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
@@ -2,50 +2,95 @@ package analytics
 
 // Request types
 
-// RecordClickRequest represents the payload for recording a click event
+// RecordClickRequest represents the payload for recording a click event.
+// Referrer is the Referer header the tracked page was loaded with. A
+// referrer named in the request's query string (?ref= or ?utm_source= by
+// default) takes precedence over it, and without either the event counts as
+// direct traffic: query parameter > header > direct.
 type RecordClickRequest struct {
-	ItemID    string `json:"item_id" binding:"required"`
-	UserID    string `json:"user_id" binding:"required"`
-	IPAddress string `json:"ip_address"`
-	UserAgent string `json:"user_agent"`
-	Referrer  string `json:"referrer"`
+	ItemID          string `json:"item_id" binding:"required"`
+	UserID          string `json:"user_id" binding:"required"`
+	IPAddress       string `json:"ip_address"`
+	UserAgent       string `json:"user_agent"`
+	Referrer        string `json:"referrer"`
+	InteractionType string `json:"interaction_type" binding:"omitempty,oneof=click copy share submit"` // click (default), copy, share or submit
+
+	// Campaign tags; when omitted they are read from the referrer
+	UTMSource   string `json:"utm_source"`
+	UTMMedium   string `json:"utm_medium"`
+	UTMCampaign string `json:"utm_campaign"`
 }
 
-// RecordPageViewRequest represents the payload for recording a page view event
+// RecordClicksBatchRequest represents the payload for recording many click
+// events at once. Entries are validated one by one by the service so a bad
+// entry is reported back instead of rejecting the whole batch.
+type RecordClicksBatchRequest struct {
+	Clicks []RecordClickRequest `json:"clicks" binding:"required,min=1"`
+}
+
+// RecordPageViewRequest represents the payload for recording a page view
+// event. The referrer precedence is the same as for RecordClickRequest.
 type RecordPageViewRequest struct {
 	ProfileID string `json:"profile_id" binding:"required"`
 	UserID    string `json:"user_id" binding:"required"`
 	IPAddress string `json:"ip_address"`
 	UserAgent string `json:"user_agent"`
 	Referrer  string `json:"referrer"`
+
+	// Campaign tags; when omitted they are read from the referrer
+	UTMSource   string `json:"utm_source"`
+	UTMMedium   string `json:"utm_medium"`
+	UTMCampaign string `json:"utm_campaign"`
 }
 
 // TimeRangeRequest represents the payload for time-range based analytics queries
 type TimeRangeRequest struct {
+	StartDate   string `json:"start_date" binding:"required"`
+	EndDate     string `json:"end_date" binding:"required"`
+	Limit       int    `json:"limit"`
+	IncludeBots bool   `json:"include_bots"`
+}
+
+// DatesAnalyticsRequest represents the payload for analytics on specific dates
+type DatesAnalyticsRequest struct {
+	Dates []string `json:"dates" binding:"required,min=1"`
+}
+
+// RebuildRollupsRequest represents the payload for an admin rollup rebuild.
+// Leaving UserID empty rebuilds rollups for every user.
+type RebuildRollupsRequest struct {
+	UserID    string `json:"user_id"`
 	StartDate string `json:"start_date" binding:"required"`
 	EndDate   string `json:"end_date" binding:"required"`
-	Limit     int    `json:"limit"`
 }
 
 // Response types
 
 // AnalyticsEntry represents a single analytics event in responses
 type AnalyticsEntry struct {
-	ID        string `json:"id"`
-	ItemID    string `json:"item_id"`
-	UserID    string `json:"user_id"`
-	IPAddress string `json:"ip_address,omitempty"`
-	UserAgent string `json:"user_agent,omitempty"`
-	Referrer  string `json:"referrer,omitempty"`
-	PageView  bool   `json:"page_view"`
-	ClickedAt string `json:"clicked_at"`
+	ID              string `json:"id"`
+	ItemID          string `json:"item_id"`
+	UserID          string `json:"user_id"`
+	IPAddress       string `json:"ip_address,omitempty"`
+	UserAgent       string `json:"user_agent,omitempty"`
+	Referrer        string `json:"referrer,omitempty"`
+	InteractionType string `json:"interaction_type,omitempty"`
+	PageView        bool   `json:"page_view"`
+	ClickedAt       string `json:"clicked_at"`
+}
+
+// InteractionStatsEntry represents the count of one interaction type
+type InteractionStatsEntry struct {
+	InteractionType string `json:"interaction_type"`
+	Count           int64  `json:"count"`
 }
 
 // ContentItemAnalyticsResponse represents analytics for a content item
 type ContentItemAnalyticsResponse struct {
-	ItemID      string            `json:"item_id"`
-	TotalClicks int64             `json:"total_clicks"`
-	ClickData   []*AnalyticsEntry `json:"click_data"`
+	ItemID       string                   `json:"item_id"`
+	TotalClicks  int64                    `json:"total_clicks"`
+	Interactions []*InteractionStatsEntry `json:"interactions"`
+	ClickData    []*AnalyticsEntry        `json:"click_data"`
 }
 
 // UserAnalyticsResponse represents analytics for a user
@@ -1,10 +1,14 @@
 package auth
 
 import (
+	"crypto/subtle"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/0xsj/mios.io/log"
 	"github.com/0xsj/mios.io/pkg/response"
+	"github.com/0xsj/mios.io/pkg/token"
 	"github.com/0xsj/mios.io/service"
 	"github.com/gin-gonic/gin"
 )
@@ -29,11 +33,20 @@ func (h *Handler) RegisterRoutes(r *gin.Engine) {
 	{
 		authGroup.POST("/register", h.Register)
 		authGroup.POST("/login", h.Login)
+		authGroup.POST("/login/2fa", h.LoginTwoFactor)
+		authGroup.GET("/oauth/:provider", h.OAuthStart)
+		authGroup.GET("/oauth/:provider/callback", h.OAuthCallback)
 		authGroup.POST("/refresh", h.RefreshToken)
 		authGroup.POST("/forgot-password", h.ForgotPassword)
 		authGroup.POST("/reset-password", h.ResetPassword)
-		// authGroup.POST("/verify-email", h.VerifyEmail)
+		authGroup.POST("/password-strength", h.CheckPasswordStrength)
+		authGroup.POST("/verify-email", h.VerifyEmail)
+		authGroup.POST("/change-password", h.ChangePassword)
 		authGroup.POST("/logout", h.Logout)
+		authGroup.GET("/sessions", h.ListSessions)
+		authGroup.DELETE("/sessions/:id", h.RevokeSession)
+		authGroup.POST("/2fa/enable", h.EnableTwoFactor)
+		authGroup.POST("/2fa/verify", h.VerifyTwoFactor)
 	}
 
 	h.logger.Info("Auth routes registered successfully")
@@ -63,6 +76,8 @@ func (h *Handler) Register(c *gin.Context) {
 		ProfileImageURL: req.ProfileImageURL,
 		LayoutVersion:   req.LayoutVersion,
 		CustomDomain:    req.CustomDomain,
+		InviteCode:      req.InviteCode,
+		RedirectURI:     req.RedirectURI,
 	}
 
 	user, err := h.authService.Register(c, input)
@@ -92,6 +107,7 @@ func (h *Handler) Login(c *gin.Context) {
 	input := service.LoginInput{
 		Email:    req.Email,
 		Password: req.Password,
+		Client:   clientInfo(c),
 	}
 
 	tokenResponse, err := h.authService.Login(c, input)
@@ -101,10 +117,171 @@ func (h *Handler) Login(c *gin.Context) {
 		return
 	}
 
+	if tokenResponse.RequiresTwoFactor {
+		h.logger.Infof("Two-factor code required for email: %s", req.Email)
+		response.Success(c, tokenResponse, "Two-factor authentication required")
+		return
+	}
+
+	if tokenResponse.PendingDeletion {
+		h.logger.Infof("Sign-in to account pending deletion for email: %s", req.Email)
+		response.Success(c, tokenResponse, "Account is pending deletion and can only be restored")
+		return
+	}
+
 	h.logger.Infof("Login successful for user: %s", tokenResponse.User.ID)
 	response.Success(c, tokenResponse, "Login successful")
 }
 
+// LoginTwoFactor completes a login by exchanging a challenge token and a TOTP
+// or recovery code for a token pair
+func (h *Handler) LoginTwoFactor(c *gin.Context) {
+	h.logger.Info("LoginTwoFactor handler called")
+
+	var req TwoFactorLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warnf("Invalid request format: %v", err)
+		response.Error(c, response.ErrBadRequestResponse, err.Error())
+		return
+	}
+
+	input := service.TOTPLoginInput{
+		ChallengeToken: req.ChallengeToken,
+		Code:           req.Code,
+		Client:         clientInfo(c),
+	}
+
+	tokenResponse, err := h.authService.LoginWithTOTP(c, input)
+	if err != nil {
+		h.logger.Warnf("Two-factor login failed: %v", err)
+		response.HandleError(c, err, h.logger)
+		return
+	}
+
+	if tokenResponse.PendingDeletion {
+		h.logger.Info("Two-factor sign-in to account pending deletion")
+		response.Success(c, tokenResponse, "Account is pending deletion and can only be restored")
+		return
+	}
+
+	h.logger.Infof("Two-factor login successful for user: %s", tokenResponse.User.ID)
+	response.Success(c, tokenResponse, "Login successful")
+}
+
+const (
+	oauthStateCookie = "oauth_state"
+	oauthStateMaxAge = 600 // seconds
+)
+
+// OAuthStart redirects the browser to the provider's consent page. The state
+// value is kept in a cookie so the callback can reject forged requests.
+func (h *Handler) OAuthStart(c *gin.Context) {
+	provider := c.Param("provider")
+	h.logger.Infof("OAuthStart handler called for provider: %s", provider)
+
+	state, err := token.GenerateRandomString(32, token.Alphanumeric)
+	if err != nil {
+		h.logger.Errorf("Failed to generate OAuth state: %v", err)
+		response.Error(c, response.ErrInternalServerResponse)
+		return
+	}
+
+	authURL, err := h.authService.OAuthAuthorizeURL(provider, state)
+	if err != nil {
+		response.HandleError(c, err, h.logger)
+		return
+	}
+
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oauthStateCookie, state, oauthStateMaxAge, "/api/auth/oauth", "", c.Request.TLS != nil, true)
+	c.Redirect(http.StatusFound, authURL)
+}
+
+// OAuthCallback completes the sign-in the provider redirected back from
+func (h *Handler) OAuthCallback(c *gin.Context) {
+	provider := c.Param("provider")
+	h.logger.Infof("OAuthCallback handler called for provider: %s", provider)
+
+	if providerErr := c.Query("error"); providerErr != "" {
+		h.logger.Warnf("OAuth sign-in with %s was not completed: %s", provider, providerErr)
+		response.Error(c, response.ErrBadRequestResponse, "Sign-in was cancelled or denied by "+provider)
+		return
+	}
+
+	state, err := c.Cookie(oauthStateCookie)
+	if err != nil || state == "" || subtle.ConstantTimeCompare([]byte(state), []byte(c.Query("state"))) != 1 {
+		h.logger.Warnf("OAuth callback for %s with missing or mismatched state", provider)
+		response.Error(c, response.ErrBadRequestResponse, "Invalid or expired sign-in state, please try again")
+		return
+	}
+	c.SetCookie(oauthStateCookie, "", -1, "/api/auth/oauth", "", c.Request.TLS != nil, true)
+
+	tokenResponse, err := h.authService.OAuthLogin(c, provider, c.Query("code"), clientInfo(c))
+	if err != nil {
+		h.logger.Warnf("OAuth login with %s failed: %v", provider, err)
+		response.HandleError(c, err, h.logger)
+		return
+	}
+
+	if tokenResponse.RequiresTwoFactor {
+		response.Success(c, tokenResponse, "Two-factor authentication required")
+		return
+	}
+
+	h.logger.Infof("OAuth login successful for user: %s", tokenResponse.User.ID)
+	response.Success(c, tokenResponse, "Login successful")
+}
+
+// EnableTwoFactor generates a TOTP secret and recovery codes for the current user
+func (h *Handler) EnableTwoFactor(c *gin.Context) {
+	h.logger.Info("EnableTwoFactor handler called")
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		h.logger.Warn("User ID not found in context")
+		response.Error(c, response.ErrUnauthorizedResponse, "User not authenticated")
+		return
+	}
+
+	setup, err := h.authService.EnableTOTP(c, userID.(string))
+	if err != nil {
+		h.logger.Warnf("Failed to start two-factor setup for user %s: %v", userID, err)
+		response.HandleError(c, err, h.logger)
+		return
+	}
+
+	h.logger.Infof("Two-factor setup started for user: %s", userID)
+	response.Success(c, setup, "Scan the QR code and confirm with a code to finish enabling two-factor authentication")
+}
+
+// VerifyTwoFactor confirms a TOTP code, enabling two-factor if setup is pending
+func (h *Handler) VerifyTwoFactor(c *gin.Context) {
+	h.logger.Info("VerifyTwoFactor handler called")
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		h.logger.Warn("User ID not found in context")
+		response.Error(c, response.ErrUnauthorizedResponse, "User not authenticated")
+		return
+	}
+
+	var req TwoFactorVerifyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warnf("Invalid request format: %v", err)
+		response.Error(c, response.ErrBadRequestResponse, err.Error())
+		return
+	}
+
+	if err := h.authService.VerifyTOTP(c, userID.(string), req.Code); err != nil {
+		h.logger.Warnf("Two-factor verification failed for user %s: %v", userID, err)
+		response.HandleError(c, err, h.logger)
+		return
+	}
+
+	h.logger.Infof("Two-factor code verified for user: %s", userID)
+	response.Success(c, nil, "Two-factor authentication enabled")
+}
+
 // RefreshToken refreshes an authentication token
 func (h *Handler) RefreshToken(c *gin.Context) {
 	h.logger.Info("RefreshToken handler called")
@@ -134,6 +311,27 @@ func (h *Handler) RefreshToken(c *gin.Context) {
 	response.Success(c, tokenResponse, "Token refreshed successfully")
 }
 
+// CheckPasswordStrength scores a candidate password against the password
+// policy so clients can give feedback before submitting it
+func (h *Handler) CheckPasswordStrength(c *gin.Context) {
+	h.logger.Debug("CheckPasswordStrength handler called")
+
+	var req PasswordStrengthRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warnf("Invalid request format: %v", err)
+		response.Error(c, response.ErrBadRequestResponse, err.Error())
+		return
+	}
+
+	result := h.authService.CheckPasswordStrength(service.PasswordStrengthInput{
+		Password: req.Password,
+		Username: req.Username,
+		Email:    req.Email,
+	})
+
+	response.Success(c, result, "Password strength checked")
+}
+
 // ForgotPassword initiates the password reset process
 func (h *Handler) ForgotPassword(c *gin.Context) {
 	h.logger.Info("ForgotPassword handler called")
@@ -147,7 +345,14 @@ func (h *Handler) ForgotPassword(c *gin.Context) {
 
 	h.logger.Debugf("Received forgot password request for email: %s", req.Email)
 
-	err := h.authService.GenerateResetToken(c, req.Email)
+	// Reject bad redirect targets up front; later failures are masked below
+	redirectURI, err := h.authService.ResolveRedirect(req.RedirectURI)
+	if err != nil {
+		response.HandleError(c, err, h.logger)
+		return
+	}
+
+	err = h.authService.GenerateResetToken(c, req.Email, redirectURI)
 	if err != nil {
 		// Log but don't expose failure details to client for security
 		h.logger.Warnf("Failed to generate reset token: %v", err)
@@ -174,6 +379,12 @@ func (h *Handler) ResetPassword(c *gin.Context) {
 
 	h.logger.Debugf("Received password reset request for email: %s", req.Email)
 
+	redirectURI, err := h.authService.ResolveRedirect(req.RedirectURI)
+	if err != nil {
+		response.HandleError(c, err, h.logger)
+		return
+	}
+
 	input := service.ResetPasswordInput{
 		Token:           req.Token,
 		Email:           req.Email,
@@ -181,7 +392,7 @@ func (h *Handler) ResetPassword(c *gin.Context) {
 		ConfirmPassword: req.ConfirmPassword,
 	}
 
-	err := h.authService.ResetPassword(c, input)
+	err = h.authService.ResetPassword(c, input)
 	if err != nil {
 		h.logger.Errorf("Failed to reset password: %v", err)
 		response.HandleError(c, err, h.logger)
@@ -189,33 +400,97 @@ func (h *Handler) ResetPassword(c *gin.Context) {
 	}
 
 	h.logger.Infof("Password reset successfully for email: %s", req.Email)
-	response.Success(c, nil, "Password has been reset successfully")
+	response.Success(c, redirectData(redirectURI), "Password has been reset successfully")
 }
 
-// VerifyEmail validates a user's email address
-// func (h *Handler) VerifyEmail(c *gin.Context) {
-// 	h.logger.Info("VerifyEmail handler called")
+// ChangePassword changes the authenticated user's password
+func (h *Handler) ChangePassword(c *gin.Context) {
+	h.logger.Info("ChangePassword handler called")
 
-// 	var req VerifyEmailRequest
-// 	if err := c.ShouldBindJSON(&req); err != nil {
-// 		h.logger.Warnf("Invalid request format: %v", err)
-// 		response.Error(c, response.ErrBadRequestResponse, err.Error())
-// 		return
-// 	}
+	userID, exists := c.Get("user_id")
+	if !exists {
+		h.logger.Warn("User ID not found in context")
+		response.Error(c, response.ErrUnauthorizedResponse, "User not authenticated")
+		return
+	}
 
-// 	// For security reasons, don't log the actual token
-// 	h.logger.Debug("Received email verification request")
+	var req ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warnf("Invalid request format: %v", err)
+		response.Error(c, response.ErrBadRequestResponse, err.Error())
+		return
+	}
 
-// 	err := h.authService.VerifyEmail(c, req.Token)
-// 	if err != nil {
-// 		h.logger.Errorf("Failed to verify email: %v", err)
-// 		response.HandleError(c, err, h.logger)
-// 		return
-// 	}
+	err := h.authService.ChangePassword(c, userID.(string), req.CurrentPassword, req.NewPassword)
+	if err != nil {
+		h.logger.Errorf("Failed to change password for user %s: %v", userID, err)
+		response.HandleError(c, err, h.logger)
+		return
+	}
 
-// 	h.logger.Info("Email verified successfully")
-// 	response.Success(c, nil, "Email has been verified successfully")
-// }
+	h.logger.Infof("Password changed successfully for user: %s", userID)
+	response.Success(c, nil, "Password has been changed successfully")
+}
+
+// ListSessions returns the devices the current user is signed in on
+func (h *Handler) ListSessions(c *gin.Context) {
+	h.logger.Info("ListSessions handler called")
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		h.logger.Warn("User ID not found in context")
+		response.Error(c, response.ErrUnauthorizedResponse, "User not authenticated")
+		return
+	}
+
+	sessions, err := h.authService.ListSessions(c, userID.(string))
+	if err != nil {
+		h.logger.Errorf("Failed to list sessions for user %s: %v", userID, err)
+		response.HandleError(c, err, h.logger)
+		return
+	}
+
+	if claims, ok := c.Get("claims"); ok {
+		if current, ok := claims.(*token.Claims); ok {
+			for i := range sessions {
+				sessions[i].Current = sessions[i].ID == current.SessionID
+			}
+		}
+	}
+
+	response.Success(c, sessions, "Sessions retrieved successfully")
+}
+
+// RevokeSession signs the current user out of one of their sessions
+func (h *Handler) RevokeSession(c *gin.Context) {
+	h.logger.Info("RevokeSession handler called")
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		h.logger.Warn("User ID not found in context")
+		response.Error(c, response.ErrUnauthorizedResponse, "User not authenticated")
+		return
+	}
+
+	sessionID := c.Param("id")
+	err := h.authService.RevokeSession(c, userID.(string), sessionID)
+	if err != nil {
+		h.logger.Errorf("Failed to revoke session %s for user %s: %v", sessionID, userID, err)
+		response.HandleError(c, err, h.logger)
+		return
+	}
+
+	h.logger.Infof("Session %s revoked for user: %s", sessionID, userID)
+	response.Success(c, nil, "Session revoked successfully")
+}
+
+// clientInfo identifies the device a login request came from
+func clientInfo(c *gin.Context) service.ClientInfo {
+	return service.ClientInfo{
+		UserAgent: c.Request.UserAgent(),
+		IPAddress: c.ClientIP(),
+	}
+}
 
 // Logout ends a user's session
 func (h *Handler) Logout(c *gin.Context) {
@@ -230,7 +505,13 @@ func (h *Handler) Logout(c *gin.Context) {
 
 	h.logger.Debugf("Received logout request for user ID: %s", req.UserID)
 
-	err := h.authService.Logout(c, req.UserID)
+	// Only the session of the access token used here is signed out
+	var accessClaims *token.Claims
+	if claims, ok := c.Get("claims"); ok {
+		accessClaims, _ = claims.(*token.Claims)
+	}
+
+	err := h.authService.Logout(c, req.UserID, accessClaims)
 	if err != nil {
 		h.logger.Errorf("Failed to logout user: %v", err)
 		response.HandleError(c, err, h.logger)
@@ -254,7 +535,13 @@ func (h *Handler) VerifyEmail(c *gin.Context) {
 
 	h.logger.Debugf("Received email verification request with token")
 
-	err := h.authService.VerifyEmail(c, req.Token)
+	redirectURI, err := h.authService.ResolveRedirect(req.RedirectURI)
+	if err != nil {
+		response.HandleError(c, err, h.logger)
+		return
+	}
+
+	err = h.authService.VerifyEmail(c, req.Token)
 	if err != nil {
 		h.logger.Errorf("Failed to verify email: %v", err)
 		response.HandleError(c, err, h.logger)
@@ -262,5 +549,110 @@ func (h *Handler) VerifyEmail(c *gin.Context) {
 	}
 
 	h.logger.Info("Email verified successfully")
-	response.Success(c, nil, "Email verified successfully")
+	response.Success(c, redirectData(redirectURI), "Email verified successfully")
+}
+
+// redirectData echoes a validated redirect target back to the client
+func redirectData(redirectURI string) gin.H {
+	if redirectURI == "" {
+		return nil
+	}
+	return gin.H{"redirect_uri": redirectURI}
+}
+
+// GetVerificationStatuses returns email verification status for many users at once
+func (h *Handler) GetVerificationStatuses(c *gin.Context) {
+	h.logger.Info("GetVerificationStatuses handler called")
+
+	var req VerificationStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warnf("Invalid request format: %v", err)
+		response.Error(c, response.ErrBadRequestResponse, err.Error())
+		return
+	}
+
+	statuses, err := h.authService.GetVerificationStatuses(c, req.UserIDs)
+	if err != nil {
+		h.logger.Warnf("Failed to get verification statuses: %v", err)
+		response.HandleError(c, err, h.logger)
+		return
+	}
+
+	response.Success(c, statuses, "Verification statuses retrieved successfully")
+}
+
+// CleanupUnverifiedAccounts deletes or re-notifies stale unverified accounts
+func (h *Handler) CleanupUnverifiedAccounts(c *gin.Context) {
+	h.logger.Info("CleanupUnverifiedAccounts handler called")
+
+	var req CleanupUnverifiedRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warnf("Invalid request format: %v", err)
+		response.Error(c, response.ErrBadRequestResponse, err.Error())
+		return
+	}
+
+	result, err := h.authService.CleanupUnverifiedAccounts(c, service.CleanupUnverifiedInput{
+		OlderThan: time.Duration(req.OlderThanHours) * time.Hour,
+		Action:    req.Action,
+		Limit:     req.Limit,
+		DryRun:    req.DryRun,
+	})
+	if err != nil {
+		h.logger.Warnf("Failed to clean up unverified accounts: %v", err)
+		response.HandleError(c, err, h.logger)
+		return
+	}
+
+	h.logger.Infof("Unverified account cleanup: %d matched, %d processed", result.Matched, result.Processed)
+	response.Success(c, result, "Unverified account cleanup completed")
+}
+
+// CreateInviteCodes generates a batch of signup invite codes
+func (h *Handler) CreateInviteCodes(c *gin.Context) {
+	h.logger.Info("CreateInviteCodes handler called")
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		h.logger.Warn("User ID not found in context")
+		response.Error(c, response.ErrUnauthorizedResponse, "User not authenticated")
+		return
+	}
+
+	var req CreateInviteCodesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warnf("Invalid request format: %v", err)
+		response.Error(c, response.ErrBadRequestResponse, err.Error())
+		return
+	}
+
+	codes, err := h.authService.CreateInviteCodes(c, userID.(string), service.CreateInviteCodesInput{
+		Count:     req.Count,
+		MaxUses:   req.MaxUses,
+		ExpiresIn: time.Duration(req.ExpiresInHours) * time.Hour,
+	})
+	if err != nil {
+		h.logger.Warnf("Failed to create invite codes: %v", err)
+		response.HandleError(c, err, h.logger)
+		return
+	}
+
+	response.Success(c, codes, "Invite codes created successfully", http.StatusCreated)
+}
+
+// ListInviteCodes lists invite codes with their usage
+func (h *Handler) ListInviteCodes(c *gin.Context) {
+	h.logger.Info("ListInviteCodes handler called")
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+	codes, err := h.authService.ListInviteCodes(c, limit, offset)
+	if err != nil {
+		h.logger.Warnf("Failed to list invite codes: %v", err)
+		response.HandleError(c, err, h.logger)
+		return
+	}
+
+	response.Success(c, codes, "Invite codes retrieved successfully")
 }
@@ -14,6 +14,8 @@ type RegisterRequest struct {
 	ProfileImageURL string `json:"profile_image_url"`
 	LayoutVersion   string `json:"layout_version"`
 	CustomDomain    string `json:"custom_domain"`
+	InviteCode      string `json:"invite_code"`
+	RedirectURI     string `json:"redirect_uri"`
 }
 
 // LoginRequest represents the payload for user authentication
@@ -22,6 +24,17 @@ type LoginRequest struct {
 	Password string `json:"password" binding:"required"`
 }
 
+// TwoFactorLoginRequest represents the second login step for accounts with two-factor enabled
+type TwoFactorLoginRequest struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+	Code           string `json:"code" binding:"required"`
+}
+
+// TwoFactorVerifyRequest represents a TOTP code confirming two-factor setup
+type TwoFactorVerifyRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
 // RefreshTokenRequest represents the payload for refreshing an authentication token
 type RefreshTokenRequest struct {
 	RefreshToken string `json:"refresh_token" binding:"required"`
@@ -29,7 +42,8 @@ type RefreshTokenRequest struct {
 
 // ForgotPasswordRequest represents the payload for requesting a password reset
 type ForgotPasswordRequest struct {
-	Email string `json:"email" binding:"required,email"`
+	Email       string `json:"email" binding:"required,email"`
+	RedirectURI string `json:"redirect_uri"`
 }
 
 // ResetPasswordRequest represents the payload for resetting a password
@@ -38,11 +52,27 @@ type ResetPasswordRequest struct {
 	Email           string `json:"email" binding:"required,email"`
 	NewPassword     string `json:"new_password" binding:"required,min=8"`
 	ConfirmPassword string `json:"confirm_password" binding:"required,min=8"`
+	RedirectURI     string `json:"redirect_uri"`
+}
+
+// PasswordStrengthRequest represents a candidate password to score, with the
+// account details it should not be built from
+type PasswordStrengthRequest struct {
+	Password string `json:"password" binding:"required"`
+	Username string `json:"username"`
+	Email    string `json:"email"`
+}
+
+// ChangePasswordRequest represents the payload for changing a signed-in user's password
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required,min=8"`
 }
 
 // VerifyEmailRequest represents the payload for email verification
 type VerifyEmailRequest struct {
-	Token string `json:"token" binding:"required"`
+	Token       string `json:"token" binding:"required"`
+	RedirectURI string `json:"redirect_uri"`
 }
 
 // LogoutRequest represents the payload for ending a user session
@@ -50,6 +80,26 @@ type LogoutRequest struct {
 	UserID string `json:"user_id" binding:"required"`
 }
 
+// VerificationStatusRequest represents a bulk email verification lookup
+type VerificationStatusRequest struct {
+	UserIDs []string `json:"user_ids" binding:"required"`
+}
+
+// CleanupUnverifiedRequest represents a bulk action on unverified accounts
+type CleanupUnverifiedRequest struct {
+	Action         string `json:"action" binding:"required,oneof=delete renotify"`
+	OlderThanHours int    `json:"older_than_hours" binding:"required,min=1"`
+	Limit          int    `json:"limit"`
+	DryRun         bool   `json:"dry_run"`
+}
+
+// CreateInviteCodesRequest represents an admin request to generate invite codes
+type CreateInviteCodesRequest struct {
+	Count          int `json:"count" binding:"required,min=1,max=100"`
+	MaxUses        int `json:"max_uses"`
+	ExpiresInHours int `json:"expires_in_hours"`
+}
+
 // Response types
 
 // UserResponse represents a user in response payloads
@@ -2,6 +2,7 @@ package content
 
 import (
 	"net/http"
+	"strconv"
 
 	"github.com/0xsj/mios.io/log"
 	"github.com/0xsj/mios.io/pkg/response"
@@ -31,9 +32,26 @@ func (h *Handler) RegisterRoutes(r *gin.Engine) {
 		contentGroup.POST("", h.CreateContentItem)
 		contentGroup.GET("/:id", h.GetContentItem)
 		contentGroup.GET("/user/:user_id", h.GetUserContentItems)
+		contentGroup.GET("/user/:user_id/summary", h.GetContentSummary)
 		contentGroup.PUT("/:id", h.UpdateContentItem)
 		contentGroup.PATCH("/:id/position", h.UpdateContentItemPosition)
+		contentGroup.PATCH("/style/bulk", h.BulkUpdateStyle)
+		contentGroup.PATCH("/reorder", h.ReorderContentItems)
+		contentGroup.PATCH("/:id/link-health", h.SetLinkAutoDeactivate)
+		contentGroup.PATCH("/:id/pin", h.SetContentItemPin)
 		contentGroup.DELETE("/:id", h.DeleteContentItem)
+		contentGroup.POST("/:id/clone", h.CloneContentItem)
+		contentGroup.GET("/trash", h.ListDeletedContentItems)
+		contentGroup.POST("/:id/restore", h.RestoreContentItem)
+
+		contentGroup.GET("/:id/history", h.GetContentHistory)
+		contentGroup.GET("/types", h.GetContentTypeSummary)
+		contentGroup.GET("/revisions/pending", h.ListPendingRevisions)
+		contentGroup.POST("/:id/revisions/:rev/approve", h.ApproveRevision)
+		contentGroup.POST("/:id/revisions/:rev/reject", h.RejectRevision)
+		contentGroup.PUT("/approval", h.SetApprovalRequired)
+		contentGroup.POST("/collaborators", h.AddCollaborator)
+		contentGroup.DELETE("/collaborators/:collaborator_id", h.RemoveCollaborator)
 	}
 
 	h.logger.Info("Content routes registered successfully")
@@ -70,6 +88,10 @@ func (h *Handler) CreateContentItem(c *gin.Context) {
 		VAlign:       req.VAlign,
 		ContentData:  req.ContentData,
 		Overrides:    req.Overrides,
+		Pinned:       req.Pinned,
+		PinOrder:     req.PinOrder,
+		PublishAt:    req.PublishAt,
+		UnpublishAt:  req.UnpublishAt,
 	}
 
 	contentItem, err := h.contentService.CreateContentItem(c, input)
@@ -116,7 +138,9 @@ func (h *Handler) GetUserContentItems(c *gin.Context) {
 		return
 	}
 
-	contentItems, err := h.contentService.GetUserContentItems(c, userID)
+	// Anonymous unless a valid token was sent, which lets owners see their
+	// own private profile
+	contentItems, err := h.contentService.GetUserContentItems(c, userID, c.GetString("user_id"))
 	if err != nil {
 		h.logger.Warnf("Failed to retrieve user content items: %v", err)
 		response.HandleError(c, err, h.logger)
@@ -127,6 +151,40 @@ func (h *Handler) GetUserContentItems(c *gin.Context) {
 	response.Success(c, contentItems, "User content items retrieved successfully")
 }
 
+// GetContentSummary returns the user's item counts by type and state. Only
+// the owner may see them, as they include inactive and draft items.
+func (h *Handler) GetContentSummary(c *gin.Context) {
+	userID := c.Param("user_id")
+	h.logger.Debugf("GetContentSummary handler called for user ID: %s", userID)
+
+	if _, err := uuid.Parse(userID); err != nil {
+		h.logger.Warnf("Invalid user ID format: %v", err)
+		response.Error(c, response.ErrBadRequestResponse, "Invalid user ID format")
+		return
+	}
+
+	authUserID, exists := c.Get("user_id")
+	if !exists {
+		h.logger.Warn("User ID not found in context")
+		response.Error(c, response.ErrUnauthorizedResponse, "User not authenticated")
+		return
+	}
+	if authUserID.(string) != userID {
+		h.logger.Warnf("User %v attempted to read the content summary of user %s", authUserID, userID)
+		response.Error(c, response.ErrForbiddenResponse, "You can only view your own content summary")
+		return
+	}
+
+	summary, err := h.contentService.GetContentSummary(c, userID)
+	if err != nil {
+		h.logger.Errorf("Failed to get content summary: %v", err)
+		response.HandleError(c, err, h.logger)
+		return
+	}
+
+	response.Success(c, summary, "Content summary retrieved successfully")
+}
+
 // UpdateContentItem updates a content item
 func (h *Handler) UpdateContentItem(c *gin.Context) {
 	itemID := c.Param("id")
@@ -138,6 +196,13 @@ func (h *Handler) UpdateContentItem(c *gin.Context) {
 		return
 	}
 
+	actorID, exists := c.Get("user_id")
+	if !exists {
+		h.logger.Warn("User ID not found in context")
+		response.Error(c, response.ErrUnauthorizedResponse, "User not authenticated")
+		return
+	}
+
 	var req UpdateContentItemRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		h.logger.Warnf("Invalid request format: %v", err)
@@ -157,17 +222,27 @@ func (h *Handler) UpdateContentItem(c *gin.Context) {
 		ContentData:  req.ContentData,
 		Overrides:    req.Overrides,
 		IsActive:     req.IsActive,
+		Pinned:       req.Pinned,
+		PinOrder:     req.PinOrder,
+		PublishAt:    req.PublishAt,
+		UnpublishAt:  req.UnpublishAt,
 	}
 
-	contentItem, err := h.contentService.UpdateContentItem(c, itemID, input)
+	result, err := h.contentService.SubmitContentUpdate(c, actorID.(string), itemID, input)
 	if err != nil {
 		h.logger.Errorf("Failed to update content item: %v", err)
 		response.HandleError(c, err, h.logger)
 		return
 	}
 
+	if result.PendingApproval {
+		h.logger.Infof("Update for content item ID: %s is pending approval", itemID)
+		response.Success(c, result.Revision, "Content update submitted for approval", http.StatusAccepted)
+		return
+	}
+
 	h.logger.Infof("Content item updated successfully with ID: %s", itemID)
-	response.Success(c, contentItem, "Content item updated successfully")
+	response.Success(c, result.Item, "Content item updated successfully")
 }
 
 // UpdateContentItemPosition updates the position of a content item
@@ -227,3 +302,424 @@ func (h *Handler) DeleteContentItem(c *gin.Context) {
 	h.logger.Infof("Content item deleted successfully with ID: %s", itemID)
 	response.Success(c, nil, "Content item deleted successfully")
 }
+
+// CloneContentItem duplicates one of the user's own items
+func (h *Handler) CloneContentItem(c *gin.Context) {
+	itemID := c.Param("id")
+	h.logger.Infof("CloneContentItem handler called for item ID: %s", itemID)
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		h.logger.Warn("User ID not found in context")
+		response.Error(c, response.ErrUnauthorizedResponse, "User not authenticated")
+		return
+	}
+
+	if _, err := uuid.Parse(itemID); err != nil {
+		h.logger.Warnf("Invalid item ID format: %v", err)
+		response.Error(c, response.ErrBadRequestResponse, "Invalid item ID format")
+		return
+	}
+
+	source, err := h.contentService.GetContentItem(c, itemID)
+	if err != nil {
+		h.logger.Warnf("Failed to retrieve content item: %v", err)
+		response.HandleError(c, err, h.logger)
+		return
+	}
+
+	if source.UserID != userID.(string) {
+		h.logger.Warnf("User %s attempted to clone item %s owned by %s", userID, itemID, source.UserID)
+		response.Error(c, response.ErrForbiddenResponse, "You can only clone your own content items")
+		return
+	}
+
+	clone, err := h.contentService.CloneContentItem(c, itemID)
+	if err != nil {
+		h.logger.Errorf("Failed to clone content item: %v", err)
+		response.HandleError(c, err, h.logger)
+		return
+	}
+
+	h.logger.Infof("Content item %s cloned to %s", itemID, clone.ID)
+	response.Success(c, clone, "Content item cloned successfully", http.StatusCreated)
+}
+
+// ListDeletedContentItems lists the current user's trash
+func (h *Handler) ListDeletedContentItems(c *gin.Context) {
+	h.logger.Info("ListDeletedContentItems handler called")
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		h.logger.Warn("User ID not found in context")
+		response.Error(c, response.ErrUnauthorizedResponse, "User not authenticated")
+		return
+	}
+
+	items, err := h.contentService.ListDeletedContentItems(c, userID.(string))
+	if err != nil {
+		h.logger.Errorf("Failed to list deleted content items: %v", err)
+		response.HandleError(c, err, h.logger)
+		return
+	}
+
+	response.Success(c, items, "Deleted content items retrieved successfully")
+}
+
+// RestoreContentItem takes one of the user's items back out of the trash
+func (h *Handler) RestoreContentItem(c *gin.Context) {
+	itemID := c.Param("id")
+	h.logger.Infof("RestoreContentItem handler called for item ID: %s", itemID)
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		h.logger.Warn("User ID not found in context")
+		response.Error(c, response.ErrUnauthorizedResponse, "User not authenticated")
+		return
+	}
+
+	item, err := h.contentService.RestoreContentItem(c, userID.(string), itemID)
+	if err != nil {
+		h.logger.Errorf("Failed to restore content item: %v", err)
+		response.HandleError(c, err, h.logger)
+		return
+	}
+
+	h.logger.Infof("Content item restored successfully with ID: %s", itemID)
+	response.Success(c, item, "Content item restored successfully")
+}
+
+// ListPendingRevisions lists content revisions awaiting the current user's review
+func (h *Handler) ListPendingRevisions(c *gin.Context) {
+	h.logger.Info("ListPendingRevisions handler called")
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		h.logger.Warn("User ID not found in context")
+		response.Error(c, response.ErrUnauthorizedResponse, "User not authenticated")
+		return
+	}
+
+	revisions, err := h.contentService.ListPendingRevisions(c, userID.(string))
+	if err != nil {
+		h.logger.Errorf("Failed to list pending revisions: %v", err)
+		response.HandleError(c, err, h.logger)
+		return
+	}
+
+	h.logger.Debugf("Retrieved %d pending revisions", len(revisions))
+	response.Success(c, revisions, "Pending revisions retrieved successfully")
+}
+
+// ApproveRevision applies a pending revision to the live content item
+func (h *Handler) ApproveRevision(c *gin.Context) {
+	itemID := c.Param("id")
+	revisionID := c.Param("rev")
+	h.logger.Infof("ApproveRevision handler called for item ID: %s, revision ID: %s", itemID, revisionID)
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		h.logger.Warn("User ID not found in context")
+		response.Error(c, response.ErrUnauthorizedResponse, "User not authenticated")
+		return
+	}
+
+	contentItem, err := h.contentService.ApproveRevision(c, userID.(string), itemID, revisionID)
+	if err != nil {
+		h.logger.Errorf("Failed to approve revision: %v", err)
+		response.HandleError(c, err, h.logger)
+		return
+	}
+
+	h.logger.Infof("Revision %s approved for item ID: %s", revisionID, itemID)
+	response.Success(c, contentItem, "Revision approved successfully")
+}
+
+// RejectRevision discards a pending revision without changing the content item
+func (h *Handler) RejectRevision(c *gin.Context) {
+	itemID := c.Param("id")
+	revisionID := c.Param("rev")
+	h.logger.Infof("RejectRevision handler called for item ID: %s, revision ID: %s", itemID, revisionID)
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		h.logger.Warn("User ID not found in context")
+		response.Error(c, response.ErrUnauthorizedResponse, "User not authenticated")
+		return
+	}
+
+	revision, err := h.contentService.RejectRevision(c, userID.(string), itemID, revisionID)
+	if err != nil {
+		h.logger.Errorf("Failed to reject revision: %v", err)
+		response.HandleError(c, err, h.logger)
+		return
+	}
+
+	h.logger.Infof("Revision %s rejected for item ID: %s", revisionID, itemID)
+	response.Success(c, revision, "Revision rejected successfully")
+}
+
+// BulkUpdateStyle applies one style change to many of the user's items
+func (h *Handler) BulkUpdateStyle(c *gin.Context) {
+	h.logger.Info("BulkUpdateStyle handler called")
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		h.logger.Warn("User ID not found in context")
+		response.Error(c, response.ErrUnauthorizedResponse, "User not authenticated")
+		return
+	}
+
+	var req BulkStyleUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warnf("Invalid request format: %v", err)
+		response.Error(c, response.ErrBadRequestResponse, err.Error())
+		return
+	}
+
+	patch := service.StylePatch{
+		DesktopStyle: req.DesktopStyle,
+		MobileStyle:  req.MobileStyle,
+		Overrides:    req.Overrides,
+		ApplyToType:  req.ContentType,
+	}
+
+	if err := h.contentService.BulkUpdateStyle(c, userID.(string), req.ItemIDs, patch); err != nil {
+		h.logger.Errorf("Failed to bulk update content style: %v", err)
+		response.HandleError(c, err, h.logger)
+		return
+	}
+
+	response.Success(c, nil, "Content styles updated successfully")
+}
+
+// ReorderContentItems saves the positions of many of the user's items at
+// once, e.g. after a drag-and-drop layout change
+func (h *Handler) ReorderContentItems(c *gin.Context) {
+	h.logger.Info("ReorderContentItems handler called")
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		h.logger.Warn("User ID not found in context")
+		response.Error(c, response.ErrUnauthorizedResponse, "User not authenticated")
+		return
+	}
+
+	var req ReorderContentItemsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warnf("Invalid request format: %v", err)
+		response.Error(c, response.ErrBadRequestResponse, err.Error())
+		return
+	}
+
+	items := make([]service.PositionUpdate, len(req.Items))
+	for i, item := range req.Items {
+		items[i] = service.PositionUpdate{
+			ItemID:   item.ItemID,
+			DesktopX: item.DesktopX,
+			DesktopY: item.DesktopY,
+			MobileX:  item.MobileX,
+			MobileY:  item.MobileY,
+		}
+	}
+
+	if err := h.contentService.ReorderContentItems(c, userID.(string), items); err != nil {
+		h.logger.Errorf("Failed to reorder content items: %v", err)
+		response.HandleError(c, err, h.logger)
+		return
+	}
+
+	response.Success(c, nil, "Content items reordered successfully")
+}
+
+// SetLinkAutoDeactivate opts an item in or out of dead-link auto-deactivation
+func (h *Handler) SetLinkAutoDeactivate(c *gin.Context) {
+	itemID := c.Param("id")
+	h.logger.Infof("SetLinkAutoDeactivate handler called for item ID: %s", itemID)
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		h.logger.Warn("User ID not found in context")
+		response.Error(c, response.ErrUnauthorizedResponse, "User not authenticated")
+		return
+	}
+
+	var req UpdateLinkHealthSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warnf("Invalid request format: %v", err)
+		response.Error(c, response.ErrBadRequestResponse, err.Error())
+		return
+	}
+
+	if err := h.contentService.SetLinkAutoDeactivate(c, userID.(string), itemID, *req.AutoDeactivate); err != nil {
+		h.logger.Errorf("Failed to update link health settings: %v", err)
+		response.HandleError(c, err, h.logger)
+		return
+	}
+
+	response.Success(c, gin.H{"auto_deactivate": *req.AutoDeactivate}, "Link health settings updated successfully")
+}
+
+// SetContentItemPin pins an item to the top of the profile or unpins it
+func (h *Handler) SetContentItemPin(c *gin.Context) {
+	itemID := c.Param("id")
+	h.logger.Infof("SetContentItemPin handler called for item ID: %s", itemID)
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		h.logger.Warn("User ID not found in context")
+		response.Error(c, response.ErrUnauthorizedResponse, "User not authenticated")
+		return
+	}
+
+	var req PinContentItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warnf("Invalid request format: %v", err)
+		response.Error(c, response.ErrBadRequestResponse, err.Error())
+		return
+	}
+
+	contentItem, err := h.contentService.SetContentItemPin(c, userID.(string), itemID, service.PinInput{
+		Pinned:   *req.Pinned,
+		PinOrder: req.PinOrder,
+	})
+	if err != nil {
+		h.logger.Errorf("Failed to update content item pin: %v", err)
+		response.HandleError(c, err, h.logger)
+		return
+	}
+
+	response.Success(c, contentItem, "Content item pin updated successfully")
+}
+
+// SetApprovalRequired toggles whether collaborator edits need the owner's approval
+func (h *Handler) SetApprovalRequired(c *gin.Context) {
+	h.logger.Info("SetApprovalRequired handler called")
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		h.logger.Warn("User ID not found in context")
+		response.Error(c, response.ErrUnauthorizedResponse, "User not authenticated")
+		return
+	}
+
+	var req UpdateApprovalSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warnf("Invalid request format: %v", err)
+		response.Error(c, response.ErrBadRequestResponse, err.Error())
+		return
+	}
+
+	if err := h.contentService.SetApprovalRequired(c, userID.(string), *req.RequiresApproval); err != nil {
+		h.logger.Errorf("Failed to update approval settings: %v", err)
+		response.HandleError(c, err, h.logger)
+		return
+	}
+
+	response.Success(c, gin.H{"requires_approval": *req.RequiresApproval}, "Approval settings updated successfully")
+}
+
+// AddCollaborator lets another user edit the current user's content
+func (h *Handler) AddCollaborator(c *gin.Context) {
+	h.logger.Info("AddCollaborator handler called")
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		h.logger.Warn("User ID not found in context")
+		response.Error(c, response.ErrUnauthorizedResponse, "User not authenticated")
+		return
+	}
+
+	var req AddCollaboratorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warnf("Invalid request format: %v", err)
+		response.Error(c, response.ErrBadRequestResponse, err.Error())
+		return
+	}
+
+	if err := h.contentService.AddCollaborator(c, userID.(string), req.CollaboratorID); err != nil {
+		h.logger.Errorf("Failed to add collaborator: %v", err)
+		response.HandleError(c, err, h.logger)
+		return
+	}
+
+	response.Success(c, nil, "Collaborator added successfully", http.StatusCreated)
+}
+
+// RemoveCollaborator revokes another user's access to the current user's content
+func (h *Handler) RemoveCollaborator(c *gin.Context) {
+	collaboratorID := c.Param("collaborator_id")
+	h.logger.Infof("RemoveCollaborator handler called for collaborator ID: %s", collaboratorID)
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		h.logger.Warn("User ID not found in context")
+		response.Error(c, response.ErrUnauthorizedResponse, "User not authenticated")
+		return
+	}
+
+	if err := h.contentService.RemoveCollaborator(c, userID.(string), collaboratorID); err != nil {
+		h.logger.Errorf("Failed to remove collaborator: %v", err)
+		response.HandleError(c, err, h.logger)
+		return
+	}
+
+	response.Success(c, nil, "Collaborator removed successfully")
+}
+
+// GetContentTypeSummary returns the current user's item counts per content type
+func (h *Handler) GetContentTypeSummary(c *gin.Context) {
+	h.logger.Info("GetContentTypeSummary handler called")
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		h.logger.Warn("User ID not found in context")
+		response.Error(c, response.ErrUnauthorizedResponse, "User not authenticated")
+		return
+	}
+
+	summary, err := h.contentService.GetContentTypeSummary(c, userID.(string))
+	if err != nil {
+		h.logger.Errorf("Failed to get content type summary: %v", err)
+		response.HandleError(c, err, h.logger)
+		return
+	}
+
+	response.Success(c, summary, "Content types retrieved successfully")
+}
+
+// GetContentHistory returns a page of a content item's change history
+func (h *Handler) GetContentHistory(c *gin.Context) {
+	itemID := c.Param("id")
+	h.logger.Debugf("GetContentHistory handler called for item ID: %s", itemID)
+
+	if _, err := uuid.Parse(itemID); err != nil {
+		h.logger.Warnf("Invalid item ID format: %v", err)
+		response.Error(c, response.ErrBadRequestResponse, "Invalid item ID format")
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		h.logger.Warn("User ID not found in context")
+		response.Error(c, response.ErrUnauthorizedResponse, "User not authenticated")
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+
+	history, err := h.contentService.GetContentHistory(c, userID.(string), itemID, page, pageSize)
+	if err != nil {
+		h.logger.Errorf("Failed to get content history: %v", err)
+		response.HandleError(c, err, h.logger)
+		return
+	}
+
+	response.WithPagination(c, history.Entries, response.PaginationMeta{
+		CurrentPage:  history.Page,
+		TotalPages:   history.TotalPages,
+		PerPage:      history.PageSize,
+		TotalRecords: int(history.TotalCount),
+	})
+}
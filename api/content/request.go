@@ -1,6 +1,10 @@
 package content
 
-import "github.com/google/uuid"
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
 
 type CreateContentItemRequest struct {
 	UserID       string                 `json:"user_id" binding:"required"`
@@ -20,6 +24,10 @@ type CreateContentItemRequest struct {
 	VAlign       *string                `json:"valign"`
 	ContentData  map[string]interface{} `json:"content_data"`
 	Overrides    map[string]interface{} `json:"overrides"`
+	Pinned       *bool                  `json:"pinned"`
+	PinOrder     *int32                 `json:"pin_order"`
+	PublishAt    *time.Time             `json:"publish_at"`
+	UnpublishAt  *time.Time             `json:"unpublish_at"`
 }
 
 type ContentItemResponse struct {
@@ -70,6 +78,10 @@ type UpdateContentItemRequest struct {
 	ContentData  map[string]interface{} `json:"content_data"`
 	Overrides    map[string]interface{} `json:"overrides"`
 	IsActive     *bool                  `json:"is_active"`
+	Pinned       *bool                  `json:"pinned"`
+	PinOrder     *int32                 `json:"pin_order"`
+	PublishAt    *time.Time             `json:"publish_at"`
+	UnpublishAt  *time.Time             `json:"unpublish_at"`
 }
 
 type UpdatePositionRequest struct {
@@ -78,3 +90,40 @@ type UpdatePositionRequest struct {
 	MobileX  *int32 `json:"mobile_x"`
 	MobileY  *int32 `json:"mobile_y"`
 }
+
+type BulkStyleUpdateRequest struct {
+	ItemIDs      []string               `json:"item_ids"`
+	ContentType  string                 `json:"content_type"`
+	DesktopStyle *string                `json:"desktop_style"`
+	MobileStyle  *string                `json:"mobile_style"`
+	Overrides    map[string]interface{} `json:"overrides"`
+}
+
+type ReorderContentItemsRequest struct {
+	Items []ItemPositionRequest `json:"items" binding:"required,dive"`
+}
+
+type ItemPositionRequest struct {
+	ItemID   string `json:"item_id" binding:"required"`
+	DesktopX *int32 `json:"desktop_x" binding:"required"`
+	DesktopY *int32 `json:"desktop_y" binding:"required"`
+	MobileX  *int32 `json:"mobile_x" binding:"required"`
+	MobileY  *int32 `json:"mobile_y" binding:"required"`
+}
+
+type UpdateApprovalSettingsRequest struct {
+	RequiresApproval *bool `json:"requires_approval" binding:"required"`
+}
+
+type PinContentItemRequest struct {
+	Pinned   *bool  `json:"pinned" binding:"required"`
+	PinOrder *int32 `json:"pin_order"`
+}
+
+type UpdateLinkHealthSettingsRequest struct {
+	AutoDeactivate *bool `json:"auto_deactivate" binding:"required"`
+}
+
+type AddCollaboratorRequest struct {
+	CollaboratorID string `json:"collaborator_id" binding:"required"`
+}
@@ -30,12 +30,15 @@ func NewHandler(fileService service.FileService, logger log.Logger) *Handler {
 func (h *Handler) RegisterRoutes(r *gin.Engine) {
 	fileGroup := r.Group("/api/files")
 	{
+		fileGroup.GET("", h.ListFiles)
 		fileGroup.POST("/upload", h.UploadFile)
 		fileGroup.POST("/upload/avatar", h.UploadAvatar)
 		fileGroup.POST("/upload/content", h.UploadContentMedia)
 		fileGroup.POST("/presigned-upload", h.GetPresignedUploadURL)
+		fileGroup.POST("/presigned-upload/complete", h.CompleteUpload)
 		fileGroup.DELETE("/:key", h.DeleteFile)
 		fileGroup.GET("/:key/url", h.GetFileURL)
+		fileGroup.GET("/:key/download-url", h.GetDownloadURL)
 	}
 
 	h.logger.Info("File routes registered successfully")
@@ -242,10 +245,75 @@ func (h *Handler) GetPresignedUploadURL(c *gin.Context) {
 	response.Success(c, result, "Presigned upload URL generated successfully")
 }
 
-// DeleteFile deletes a file
+// CompleteUpload records the size of a file uploaded to a presigned URL
+func (h *Handler) CompleteUpload(c *gin.Context) {
+	h.logger.Info("CompleteUpload handler called")
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		h.logger.Warn("User ID not found in context")
+		response.Error(c, response.ErrUnauthorizedResponse, "User not authenticated")
+		return
+	}
+
+	var req CompleteUploadRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warnf("Invalid request format: %v", err)
+		response.Error(c, response.ErrBadRequestResponse, err.Error())
+		return
+	}
+
+	file, err := h.fileService.CompleteUpload(c, userID.(string), req.Key)
+	if err != nil {
+		h.logger.Errorf("Failed to complete upload of %s: %v", req.Key, err)
+		response.HandleError(c, err, h.logger)
+		return
+	}
+
+	h.logger.Infof("Upload completed successfully for file: %s", req.Key)
+	response.Success(c, file, "Upload completed successfully")
+}
+
+// ListFiles returns a page of the authenticated user's files, most recent first
+func (h *Handler) ListFiles(c *gin.Context) {
+	h.logger.Info("ListFiles handler called")
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		h.logger.Warn("User ID not found in context")
+		response.Error(c, response.ErrUnauthorizedResponse, "User not authenticated")
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+
+	files, err := h.fileService.ListUserFiles(c, userID.(string), c.Query("category"), page, pageSize)
+	if err != nil {
+		h.logger.Errorf("Failed to list files: %v", err)
+		response.HandleError(c, err, h.logger)
+		return
+	}
+
+	response.WithPagination(c, files.Files, response.PaginationMeta{
+		CurrentPage:  files.Page,
+		TotalPages:   files.TotalPages,
+		PerPage:      files.PageSize,
+		TotalRecords: int(files.TotalCount),
+	})
+}
+
+// DeleteFile deletes a file owned by the current user; admins may delete any file
 func (h *Handler) DeleteFile(c *gin.Context) {
 	h.logger.Info("DeleteFile handler called")
 
+	userID, exists := c.Get("user_id")
+	if !exists {
+		h.logger.Warn("User ID not found in context")
+		response.Error(c, response.ErrUnauthorizedResponse, "User not authenticated")
+		return
+	}
+
 	key := c.Param("key")
 	if key == "" {
 		h.logger.Warn("File key is required")
@@ -253,10 +321,7 @@ func (h *Handler) DeleteFile(c *gin.Context) {
 		return
 	}
 
-	// TODO: Add authorization check to ensure user owns the file
-	// This would require storing file ownership information
-
-	err := h.fileService.DeleteFile(c, key)
+	err := h.fileService.DeleteFile(c, userID.(string), key)
 	if err != nil {
 		h.logger.Errorf("Failed to delete file: %v", err)
 		response.HandleError(c, err, h.logger)
@@ -290,7 +355,11 @@ func (h *Handler) GetFileURL(c *gin.Context) {
 		expires = time.Duration(expiresHours) * time.Hour
 	}
 
-	url, err := h.fileService.GetFileURL(c, key, expires)
+	// Anonymous on the public route unless a valid token was sent; files in
+	// private categories need their owner or an admin
+	userID := c.GetString("user_id")
+
+	url, err := h.fileService.GetFileURL(c, userID, key, expires)
 	if err != nil {
 		h.logger.Errorf("Failed to get file URL: %v", err)
 		response.HandleError(c, err, h.logger)
@@ -308,4 +377,48 @@ func (h *Handler) GetFileURL(c *gin.Context) {
 
 	h.logger.Infof("File URL generated successfully for key: %s", key)
 	response.Success(c, result, "File URL generated successfully")
-}
\ No newline at end of file
+}
+// GetDownloadURL gets a time-limited URL for downloading a file. expires is
+// a duration such as "15m" or "24h" and defaults to an hour.
+func (h *Handler) GetDownloadURL(c *gin.Context) {
+	h.logger.Info("GetDownloadURL handler called")
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		h.logger.Warn("User ID not found in context")
+		response.Error(c, response.ErrUnauthorizedResponse, "User not authenticated")
+		return
+	}
+
+	key := c.Param("key")
+	if key == "" {
+		h.logger.Warn("File key is required")
+		response.Error(c, response.ErrBadRequestResponse, "File key is required")
+		return
+	}
+
+	expires := time.Hour
+	if expiresStr := c.Query("expires"); expiresStr != "" {
+		var err error
+		expires, err = time.ParseDuration(expiresStr)
+		if err != nil {
+			h.logger.Warnf("Invalid expires parameter: %v", err)
+			response.Error(c, response.ErrBadRequestResponse, "Invalid expires parameter")
+			return
+		}
+	}
+
+	url, err := h.fileService.GetPresignedDownloadURL(c, userID.(string), key, expires)
+	if err != nil {
+		h.logger.Errorf("Failed to get download URL: %v", err)
+		response.HandleError(c, err, h.logger)
+		return
+	}
+
+	h.logger.Infof("Download URL generated successfully for key: %s", key)
+	response.Success(c, map[string]interface{}{
+		"key":        key,
+		"url":        url,
+		"expires_at": time.Now().Add(expires).UTC().Format(time.RFC3339),
+	}, "Download URL generated successfully")
+}
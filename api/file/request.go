@@ -8,6 +8,11 @@ type PresignedUploadRequest struct {
 	Category    string `json:"category" binding:"required"` // "avatar", "content", "general"
 }
 
+// CompleteUploadRequest reports that a presigned upload has finished
+type CompleteUploadRequest struct {
+	Key string `json:"key" binding:"required"`
+}
+
 // FileUploadResponse represents the response after a successful file upload
 type FileUploadResponse struct {
 	Key         string `json:"key"`
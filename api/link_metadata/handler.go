@@ -2,7 +2,15 @@
 package link_metadata
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
 	"github.com/0xsj/mios.io/log"
+	"github.com/0xsj/mios.io/pkg/cache"
 	"github.com/0xsj/mios.io/pkg/response"
 	"github.com/0xsj/mios.io/service"
 	"github.com/gin-gonic/gin"
@@ -28,7 +36,9 @@ func (h *Handler) RegisterRoutes(r *gin.Engine) {
 	{
 		metadataGroup.GET("/url", h.GetLinkMetadata)
 		metadataGroup.POST("/fetch", h.FetchLinkMetadata)
+		metadataGroup.POST("/freshness", h.GetFreshnessStatus)
 		metadataGroup.GET("/platforms", h.ListPlatforms)
+		metadataGroup.GET("/detect", h.DetectPlatform)
 	}
 
 	h.logger.Info("Link Metadata routes registered successfully")
@@ -52,6 +62,11 @@ func (h *Handler) GetLinkMetadata(c *gin.Context) {
 		return
 	}
 
+	if notModified := h.setMetadataCacheHeaders(c, metadata); notModified {
+		h.logger.Debugf("Link metadata not modified for URL: %s", url)
+		return
+	}
+
 	h.logger.Infof("Link metadata retrieved successfully for URL: %s", url)
 	response.Success(c, metadata, "Link metadata retrieved successfully")
 }
@@ -80,6 +95,28 @@ func (h *Handler) FetchLinkMetadata(c *gin.Context) {
 	response.Success(c, metadata, "Link metadata fetched successfully")
 }
 
+// GetFreshnessStatus reports whether stored metadata for each URL is fresh,
+// stale, pending or failed, so clients only refresh the cards that need it
+func (h *Handler) GetFreshnessStatus(c *gin.Context) {
+	h.logger.Debug("GetFreshnessStatus handler called")
+
+	var req FreshnessRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warnf("Invalid request format: %v", err)
+		response.Error(c, response.ErrBadRequestResponse, err.Error())
+		return
+	}
+
+	freshness, err := h.metadataService.GetFreshnessStatus(c, req.URLs)
+	if err != nil {
+		h.logger.Warnf("Failed to get link metadata freshness: %v", err)
+		response.HandleError(c, err, h.logger)
+		return
+	}
+
+	response.Success(c, freshness, "Link metadata freshness retrieved successfully")
+}
+
 // ListPlatforms returns a list of known platforms
 func (h *Handler) ListPlatforms(c *gin.Context) {
 	h.logger.Info("ListPlatforms handler called")
@@ -91,6 +128,162 @@ func (h *Handler) ListPlatforms(c *gin.Context) {
 		return
 	}
 
+	// Admins can edit the registry, so clients revalidate hourly against an
+	// ETag derived from its contents
+	if body, err := json.Marshal(platforms); err == nil {
+		etag := weakETag(string(body))
+		c.Header("Cache-Control", cacheControl(cache.LongTTL))
+		c.Header("ETag", etag)
+		if c.GetHeader("If-None-Match") == etag {
+			c.Status(http.StatusNotModified)
+			return
+		}
+	}
+
 	h.logger.Infof("Platforms listed successfully, found %d platforms", len(platforms))
 	response.Success(c, platforms, "Platforms listed successfully")
 }
+
+// DetectPlatform reports which known platform a URL belongs to, if any. It
+// never fetches the URL, so clients can call it as the user types.
+func (h *Handler) DetectPlatform(c *gin.Context) {
+	h.logger.Debug("DetectPlatform handler called")
+
+	url := c.Query("url")
+	if url == "" {
+		h.logger.Warn("Missing URL parameter")
+		response.Error(c, response.ErrBadRequestResponse, "URL parameter is required")
+		return
+	}
+
+	detection, err := h.metadataService.DetectPlatform(url)
+	if err != nil {
+		h.logger.Debugf("Failed to detect platform for URL %s: %v", url, err)
+		response.HandleError(c, err, h.logger)
+		return
+	}
+
+	// The answer only depends on the registry, which admins rarely change
+	c.Header("Cache-Control", cacheControl(cache.LongTTL))
+	response.Success(c, detection, "Platform detection completed")
+}
+
+// CreatePlatform adds a platform to the registry
+func (h *Handler) CreatePlatform(c *gin.Context) {
+	h.logger.Info("CreatePlatform handler called")
+
+	var req CreatePlatformRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warnf("Invalid request format: %v", err)
+		response.Error(c, response.ErrBadRequestResponse, err.Error())
+		return
+	}
+
+	platform, err := h.metadataService.CreatePlatform(c, service.CreatePlatformInput{
+		Domain:         req.Domain,
+		Name:           req.Name,
+		Type:           req.Type,
+		Color:          req.Color,
+		Icon:           req.Icon,
+		URLTemplate:    req.URLTemplate,
+		ScrapeStrategy: req.ScrapeStrategy,
+		OEmbedEndpoint: req.OEmbedEndpoint,
+		UserAgent:      req.UserAgent,
+		QueryKeys:      req.QueryKeys,
+	})
+	if err != nil {
+		h.logger.Errorf("Failed to create platform: %v", err)
+		response.HandleError(c, err, h.logger)
+		return
+	}
+
+	h.logger.Infof("Platform created successfully: %s", platform.Domain)
+	response.Success(c, platform, "Platform created successfully", http.StatusCreated)
+}
+
+// UpdatePlatform changes the provided fields of a platform
+func (h *Handler) UpdatePlatform(c *gin.Context) {
+	domain := c.Param("domain")
+	h.logger.Infof("UpdatePlatform handler called for domain: %s", domain)
+
+	var req UpdatePlatformRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warnf("Invalid request format: %v", err)
+		response.Error(c, response.ErrBadRequestResponse, err.Error())
+		return
+	}
+
+	platform, err := h.metadataService.UpdatePlatform(c, domain, service.UpdatePlatformInput{
+		Name:           req.Name,
+		Type:           req.Type,
+		Color:          req.Color,
+		Icon:           req.Icon,
+		URLTemplate:    req.URLTemplate,
+		ScrapeStrategy: req.ScrapeStrategy,
+		OEmbedEndpoint: req.OEmbedEndpoint,
+		UserAgent:      req.UserAgent,
+		QueryKeys:      req.QueryKeys,
+	})
+	if err != nil {
+		h.logger.Errorf("Failed to update platform: %v", err)
+		response.HandleError(c, err, h.logger)
+		return
+	}
+
+	h.logger.Infof("Platform updated successfully: %s", platform.Domain)
+	response.Success(c, platform, "Platform updated successfully")
+}
+
+// DeletePlatform removes a platform from the registry
+func (h *Handler) DeletePlatform(c *gin.Context) {
+	domain := c.Param("domain")
+	h.logger.Infof("DeletePlatform handler called for domain: %s", domain)
+
+	if err := h.metadataService.DeletePlatform(c, domain); err != nil {
+		h.logger.Errorf("Failed to delete platform: %v", err)
+		response.HandleError(c, err, h.logger)
+		return
+	}
+
+	h.logger.Infof("Platform deleted successfully: %s", domain)
+	response.Success(c, nil, "Platform deleted successfully")
+}
+
+// setMetadataCacheHeaders lets clients and CDNs reuse metadata until its
+// cache TTL runs out. The ETag is tied to updated_at, so a refresh produces
+// a new one. It reports true when a 304 has already been written.
+func (h *Handler) setMetadataCacheHeaders(c *gin.Context, metadata *service.LinkMetadataDTO) bool {
+	updatedAt, err := time.Parse(time.RFC3339, metadata.UpdatedAt)
+	if err != nil {
+		c.Header("Cache-Control", "no-cache")
+		return false
+	}
+
+	maxAge := cache.GetMetadataTTL() - time.Since(updatedAt)
+	if maxAge < 0 {
+		maxAge = 0
+	}
+
+	etag := weakETag(metadata.ID + "|" + metadata.UpdatedAt)
+	c.Header("Cache-Control", cacheControl(maxAge))
+	c.Header("ETag", etag)
+	c.Header("Last-Modified", updatedAt.UTC().Format(http.TimeFormat))
+
+	if c.GetHeader("If-None-Match") == etag {
+		c.Status(http.StatusNotModified)
+		return true
+	}
+	return false
+}
+
+func cacheControl(maxAge time.Duration) string {
+	if maxAge <= 0 {
+		return "public, no-cache"
+	}
+	return fmt.Sprintf("public, max-age=%d", int(maxAge.Seconds()))
+}
+
+func weakETag(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return `W/"` + hex.EncodeToString(sum[:8]) + `"`
+}
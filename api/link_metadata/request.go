@@ -7,3 +7,38 @@ package link_metadata
 type FetchLinkMetadataRequest struct {
 	URL string `json:"url" binding:"required"`
 }
+
+// FreshnessRequest represents the payload for checking how current the
+// stored metadata for a set of URLs is
+type FreshnessRequest struct {
+	URLs []string `json:"urls" binding:"required,min=1,max=100,dive,required"`
+}
+
+// CreatePlatformRequest represents the payload for adding a platform to the
+// registry
+type CreatePlatformRequest struct {
+	Domain         string   `json:"domain" binding:"required"`
+	Name           string   `json:"name" binding:"required"`
+	Type           string   `json:"type" binding:"required"`
+	Color          string   `json:"color" binding:"required"`
+	Icon           string   `json:"icon"`
+	URLTemplate    string   `json:"url_template"`
+	ScrapeStrategy string   `json:"scrape_strategy"`
+	OEmbedEndpoint string   `json:"oembed_endpoint"`
+	UserAgent      string   `json:"user_agent"`
+	QueryKeys      []string `json:"query_keys"`
+}
+
+// UpdatePlatformRequest represents the payload for changing a platform.
+// Omitted fields are left as they are; "query_keys": [] clears the keys.
+type UpdatePlatformRequest struct {
+	Name           *string  `json:"name"`
+	Type           *string  `json:"type"`
+	Color          *string  `json:"color"`
+	Icon           *string  `json:"icon"`
+	URLTemplate    *string  `json:"url_template"`
+	ScrapeStrategy *string  `json:"scrape_strategy"`
+	OEmbedEndpoint *string  `json:"oembed_endpoint"`
+	UserAgent      *string  `json:"user_agent"`
+	QueryKeys      []string `json:"query_keys"`
+}
@@ -0,0 +1,177 @@
+package profile
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/url"
+	"text/template"
+
+	"github.com/0xsj/mios.io/pkg/response"
+	"github.com/0xsj/mios.io/service"
+	"github.com/gin-gonic/gin"
+)
+
+// The embed script only changes when the handle does, so it can be cached
+// for a day; the links it loads change whenever the owner edits them.
+const (
+	embedScriptCacheControl = "public, max-age=86400, stale-while-revalidate=604800"
+	embedLinksCacheControl  = "public, max-age=300"
+)
+
+// embedScript renders a profile's published links into the embedding page.
+// It reads its settings from data attributes on its own <script> tag:
+//
+//	data-target  CSS selector of the element to render into (default: right
+//	             after the script tag)
+//	data-theme   "light" (default) or "dark"
+//	data-accent  link color
+//	data-radius  corner radius of the link buttons
+//	data-font    font family
+//	data-title   "false" hides the profile name
+//
+// Page views and clicks are sent as text/plain beacons, which need no CORS
+// preflight, with the embed referrer and the embedding site as utm_source.
+var embedScript = template.Must(template.New("embed.js").Parse(`(function () {
+  "use strict";
+  var handle = "{{js .Handle}}";
+  var channel = "{{js .Channel}}";
+  var script = document.currentScript;
+  if (!script || !script.src) { return; }
+
+  var base = new URL(script.src).origin;
+  var data = script.dataset;
+  var themes = {
+    light: { background: "#ffffff", text: "#111827", border: "#e5e7eb" },
+    dark: { background: "#111827", text: "#f9fafb", border: "#374151" }
+  };
+  var theme = themes[data.theme] || themes.light;
+  var accent = data.accent || "#2563eb";
+  var radius = data.radius || "8px";
+  var font = data.font || "inherit";
+
+  var target = data.target ? document.querySelector(data.target) : null;
+  if (!target) {
+    target = document.createElement("div");
+    script.parentNode.insertBefore(target, script.nextSibling);
+  }
+
+  function send(path, event) {
+    event.referrer = channel;
+    event.utm_source = location.hostname;
+    event.utm_medium = channel;
+    event.user_agent = navigator.userAgent;
+    var url = base + "/api/analytics/" + path;
+    var body = JSON.stringify(event);
+    if (navigator.sendBeacon && navigator.sendBeacon(url, body)) { return; }
+    fetch(url, { method: "POST", body: body, keepalive: true }).catch(function () {});
+  }
+
+  function safeURL(value) {
+    if (!/^[a-z][a-z0-9+.-]*:/i.test(value)) { return "https://" + value; }
+    return /^(https?|mailto|tel):/i.test(value) ? value : null;
+  }
+
+  function render(profile) {
+    var root = document.createElement("div");
+    root.style.cssText = "display:flex;flex-direction:column;gap:8px;padding:16px;" +
+      "background:" + theme.background + ";color:" + theme.text + ";font-family:" + font +
+      ";border:1px solid " + theme.border + ";border-radius:" + radius + ";";
+
+    if (data.title !== "false") {
+      var title = document.createElement("div");
+      title.textContent = profile.display_name;
+      title.style.cssText = "font-weight:600;text-align:center;";
+      root.appendChild(title);
+    }
+
+    profile.links.forEach(function (link) {
+      var href = safeURL(link.url);
+      if (!href) { return; }
+      var a = document.createElement("a");
+      a.href = href;
+      a.textContent = link.title;
+      a.target = "_blank";
+      a.rel = "noopener";
+      a.style.cssText = "display:block;padding:10px 14px;text-align:center;text-decoration:none;" +
+        "color:" + accent + ";border:1px solid " + accent + ";border-radius:" + radius + ";";
+      a.addEventListener("click", function () {
+        send("clicks", { item_id: link.item_id, user_id: profile.user_id });
+      });
+      root.appendChild(a);
+    });
+
+    target.innerHTML = "";
+    target.appendChild(root);
+    send("page-views", { profile_id: profile.user_id, user_id: profile.user_id });
+  }
+
+  fetch(base + "/api/profiles/" + encodeURIComponent(handle) + "/links")
+    .then(function (res) { return res.ok ? res.json() : null; })
+    .then(function (body) { if (body && body.data) { render(body.data); } })
+    .catch(function () {});
+})();
+`))
+
+// GetEmbedScript serves the script that embeds a profile's links on another
+// site, e.g. <script src="/api/profiles/jane/embed.js" data-theme="dark">
+func (h *Handler) GetEmbedScript(c *gin.Context) {
+	handle := c.Param("handle")
+	h.logger.Debugf("GetEmbedScript handler called for handle: %s", handle)
+
+	canonical, redirect, err := h.profileService.ResolveCanonical(c, handle)
+	if err != nil {
+		h.logger.Warnf("Failed to resolve canonical profile: %v", err)
+		response.HandleError(c, err, h.logger)
+		return
+	}
+
+	if redirect {
+		h.logger.Debugf("Redirecting embed script of %s to canonical handle %s", handle, canonical)
+		c.Redirect(http.StatusMovedPermanently, "/api/profiles/"+url.PathEscape(canonical)+"/embed.js")
+		return
+	}
+
+	var script bytes.Buffer
+	err = embedScript.Execute(&script, struct{ Handle, Channel string }{canonical, service.EmbedChannel})
+	if err != nil {
+		h.logger.Errorf("Failed to render embed script: %v", err)
+		response.HandleError(c, err, h.logger)
+		return
+	}
+
+	sum := sha256.Sum256(script.Bytes())
+	etag := `W/"` + hex.EncodeToString(sum[:8]) + `"`
+	c.Header("Cache-Control", embedScriptCacheControl)
+	c.Header("ETag", etag)
+	if c.GetHeader("If-None-Match") == etag {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	c.Data(http.StatusOK, "application/javascript; charset=utf-8", script.Bytes())
+}
+
+// GetEmbedLinks returns the published links the embed script renders
+func (h *Handler) GetEmbedLinks(c *gin.Context) {
+	handle := c.Param("handle")
+	h.logger.Debugf("GetEmbedLinks handler called for handle: %s", handle)
+
+	canonical, _, err := h.profileService.ResolveCanonical(c, handle)
+	if err != nil {
+		h.logger.Warnf("Failed to resolve canonical profile: %v", err)
+		response.HandleError(c, err, h.logger)
+		return
+	}
+
+	embed, err := h.profileService.GetEmbedProfile(c, canonical)
+	if err != nil {
+		h.logger.Warnf("Failed to retrieve embed links: %v", err)
+		response.HandleError(c, err, h.logger)
+		return
+	}
+
+	c.Header("Cache-Control", embedLinksCacheControl)
+	response.Success(c, embed, "Profile links retrieved successfully")
+}
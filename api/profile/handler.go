@@ -0,0 +1,138 @@
+package profile
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/0xsj/mios.io/log"
+	"github.com/0xsj/mios.io/pkg/response"
+	"github.com/0xsj/mios.io/service"
+	"github.com/gin-gonic/gin"
+)
+
+// metaCacheControl lets browsers, crawlers and CDNs hold on to profile
+// metadata; it only changes when a user edits their profile.
+const metaCacheControl = "public, max-age=3600, s-maxage=86400, stale-while-revalidate=86400"
+
+// Handler handles HTTP requests for public profile operations
+type Handler struct {
+	profileService service.ProfileService
+	logger         log.Logger
+}
+
+// NewHandler creates a new profile handler
+func NewHandler(profileService service.ProfileService, logger log.Logger) *Handler {
+	return &Handler{
+		profileService: profileService,
+		logger:         logger,
+	}
+}
+
+// GetProfileMeta returns the SEO-relevant fields of a public profile.
+// Unlisted profiles answer with a 404 so crawlers don't index them.
+func (h *Handler) GetProfileMeta(c *gin.Context) {
+	handle := c.Param("handle")
+	h.logger.Debugf("GetProfileMeta handler called for handle: %s", handle)
+
+	if handle == "" {
+		h.logger.Warn("Invalid handle parameter: empty value")
+		response.Error(c, response.ErrBadRequestResponse, "Handle cannot be empty")
+		return
+	}
+
+	canonical, redirect, err := h.profileService.ResolveCanonical(c, handle)
+	if err != nil {
+		h.logger.Warnf("Failed to resolve canonical profile: %v", err)
+		response.HandleError(c, err, h.logger)
+		return
+	}
+
+	if redirect {
+		location := "/api/profiles/" + url.PathEscape(canonical) + "/meta"
+		if c.Request.URL.RawQuery != "" {
+			location += "?" + c.Request.URL.RawQuery
+		}
+		h.logger.Debugf("Redirecting profile %s to canonical handle %s", handle, canonical)
+		c.Redirect(http.StatusMovedPermanently, location)
+		return
+	}
+
+	meta, err := h.profileService.GetProfileMeta(c, canonical)
+	if err != nil {
+		h.logger.Warnf("Failed to retrieve profile metadata: %v", err)
+		response.HandleError(c, err, h.logger)
+		return
+	}
+
+	responseData := ProfileMetaResponse{
+		Handle:      meta.Handle,
+		DisplayName: meta.DisplayName,
+		Bio:         meta.Bio,
+		AvatarURL:   meta.AvatarURL,
+		IsVerified:  meta.IsVerified,
+		Counts: ProfileCounts{
+			Links: meta.LinkCount,
+		},
+		UpdatedAt: meta.UpdatedAt,
+	}
+
+	c.Header("Cache-Control", metaCacheControl)
+	h.logger.Debugf("Profile metadata retrieved successfully for handle: %s", handle)
+	response.Success(c, responseData, "Profile metadata retrieved successfully")
+}
+
+// ListTemplates returns the profiles new users can start from
+func (h *Handler) ListTemplates(c *gin.Context) {
+	h.logger.Debug("ListTemplates handler called")
+
+	templates, err := h.profileService.ListTemplates(c)
+	if err != nil {
+		h.logger.Warnf("Failed to list profile templates: %v", err)
+		response.HandleError(c, err, h.logger)
+		return
+	}
+
+	response.Success(c, templates, "Profile templates retrieved successfully")
+}
+
+// CopyFromTemplate seeds the current user's profile with a template's items
+func (h *Handler) CopyFromTemplate(c *gin.Context) {
+	templateID := c.Param("templateId")
+	h.logger.Infof("CopyFromTemplate handler called for template: %s", templateID)
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		h.logger.Warn("User ID not found in context")
+		response.Error(c, response.ErrUnauthorizedResponse, "User not authenticated")
+		return
+	}
+
+	if err := h.profileService.CopyFromTemplate(c, userID.(string), templateID); err != nil {
+		h.logger.Warnf("Failed to copy profile template: %v", err)
+		response.HandleError(c, err, h.logger)
+		return
+	}
+
+	response.Success(c, nil, "Profile template copied successfully", http.StatusCreated)
+}
+
+// SetTemplate marks or unmarks a profile as a template
+func (h *Handler) SetTemplate(c *gin.Context) {
+	userID := c.Param("id")
+	h.logger.Infof("SetTemplate handler called for user ID: %s", userID)
+
+	var req SetTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warnf("Invalid request format: %v", err)
+		response.Error(c, response.ErrBadRequestResponse, err.Error())
+		return
+	}
+
+	if err := h.profileService.SetTemplate(c, userID, *req.IsTemplate); err != nil {
+		h.logger.Warnf("Failed to update template status: %v", err)
+		response.HandleError(c, err, h.logger)
+		return
+	}
+
+	response.Success(c, nil, "Template status updated successfully")
+}
@@ -0,0 +1,26 @@
+package profile
+
+// Request types
+
+// SetTemplateRequest represents an admin request to flag a profile as a template
+type SetTemplateRequest struct {
+	IsTemplate *bool `json:"is_template" binding:"required"`
+}
+
+// Response types
+
+// ProfileCounts holds aggregate counts shown alongside a profile
+type ProfileCounts struct {
+	Links int64 `json:"links"`
+}
+
+// ProfileMetaResponse represents the machine-readable metadata of a profile
+type ProfileMetaResponse struct {
+	Handle      string        `json:"handle"`
+	DisplayName string        `json:"display_name"`
+	Bio         string        `json:"bio,omitempty"`
+	AvatarURL   string        `json:"avatar_url,omitempty"`
+	IsVerified  bool          `json:"is_verified"`
+	Counts      ProfileCounts `json:"counts"`
+	UpdatedAt   string        `json:"updated_at,omitempty"`
+}
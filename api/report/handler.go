@@ -0,0 +1,140 @@
+package report
+
+import (
+	"net/http"
+
+	"github.com/0xsj/mios.io/log"
+	"github.com/0xsj/mios.io/pkg/response"
+	"github.com/0xsj/mios.io/service"
+	"github.com/gin-gonic/gin"
+)
+
+// Handler handles HTTP requests for analytics email reports
+type Handler struct {
+	reportService service.ReportService
+	logger        log.Logger
+}
+
+// NewHandler creates a new report handler
+func NewHandler(reportService service.ReportService, logger log.Logger) *Handler {
+	return &Handler{
+		reportService: reportService,
+		logger:        logger,
+	}
+}
+
+// RegisterRoutes registers report routes on the given router
+func (h *Handler) RegisterRoutes(r *gin.Engine) {
+	r.GET("/api/reports/unsubscribe", h.UnsubscribeByToken)
+
+	userGroup := r.Group("/api/users")
+	{
+		userGroup.GET("/:id/reports", h.ListSubscriptions)
+		userGroup.POST("/:id/reports", h.Subscribe)
+		userGroup.DELETE("/:id/reports/:subscriptionId", h.Unsubscribe)
+	}
+
+	h.logger.Info("Report routes registered successfully")
+}
+
+// ListSubscriptions lists the user's analytics report subscriptions
+func (h *Handler) ListSubscriptions(c *gin.Context) {
+	userID := c.Param("id")
+	h.logger.Debugf("ListSubscriptions handler called for user ID: %s", userID)
+
+	if !h.requireSelf(c, userID) {
+		return
+	}
+
+	subscriptions, err := h.reportService.ListSubscriptions(c, userID)
+	if err != nil {
+		h.logger.Warnf("Failed to list report subscriptions: %v", err)
+		response.HandleError(c, err, h.logger)
+		return
+	}
+
+	response.Success(c, subscriptions, "Report subscriptions retrieved successfully")
+}
+
+// Subscribe schedules a one-off or recurring analytics email report
+func (h *Handler) Subscribe(c *gin.Context) {
+	userID := c.Param("id")
+	h.logger.Infof("Subscribe handler called for user ID: %s", userID)
+
+	if !h.requireSelf(c, userID) {
+		return
+	}
+
+	var req SubscribeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warnf("Invalid request format: %v", err)
+		response.Error(c, response.ErrBadRequestResponse, err.Error())
+		return
+	}
+
+	subscription, err := h.reportService.Subscribe(c, userID, service.ReportSubscriptionInput{
+		Frequency: req.Frequency,
+		Metrics:   req.Metrics,
+		SendAt:    req.SendAt,
+	})
+	if err != nil {
+		h.logger.Warnf("Failed to subscribe to reports: %v", err)
+		response.HandleError(c, err, h.logger)
+		return
+	}
+
+	h.logger.Infof("User ID: %s subscribed to %s reports", userID, req.Frequency)
+	response.Success(c, subscription, "Report subscription created successfully", http.StatusCreated)
+}
+
+// Unsubscribe removes one of the user's report subscriptions
+func (h *Handler) Unsubscribe(c *gin.Context) {
+	userID := c.Param("id")
+	subscriptionID := c.Param("subscriptionId")
+	h.logger.Infof("Unsubscribe handler called for user ID: %s, subscription ID: %s", userID, subscriptionID)
+
+	if !h.requireSelf(c, userID) {
+		return
+	}
+
+	if err := h.reportService.Unsubscribe(c, userID, subscriptionID); err != nil {
+		h.logger.Warnf("Failed to remove report subscription: %v", err)
+		response.HandleError(c, err, h.logger)
+		return
+	}
+
+	response.Success(c, nil, "Report subscription removed successfully", http.StatusOK)
+}
+
+// UnsubscribeByToken handles the unsubscribe link in report emails. It is
+// public, since the token alone identifies the subscription.
+func (h *Handler) UnsubscribeByToken(c *gin.Context) {
+	h.logger.Info("UnsubscribeByToken handler called")
+
+	if err := h.reportService.UnsubscribeByToken(c, c.Query("token")); err != nil {
+		h.logger.Warnf("Failed to unsubscribe by token: %v", err)
+		response.HandleError(c, err, h.logger)
+		return
+	}
+
+	response.Success(c, nil, "You have been unsubscribed from this report", http.StatusOK)
+}
+
+// requireSelf rejects the request unless the authenticated user is the
+// user named in the path.
+func (h *Handler) requireSelf(c *gin.Context, userID string) bool {
+	authUserID, exists := c.Get("user_id")
+	if !exists {
+		h.logger.Warn("User ID not found in context")
+		response.Error(c, response.ErrUnauthorizedResponse, "User not authenticated")
+		return false
+	}
+
+	if authUserID.(string) != userID {
+		h.logger.Warnf("User %v attempted to manage reports of user %s", authUserID, userID)
+		response.Error(c, response.ErrForbiddenResponse, "You can only manage your own reports")
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,14 @@
+package report
+
+import "time"
+
+// Request types
+
+// SubscribeRequest represents the payload for subscribing to analytics
+// email reports. Metrics defaults to all of them; SendAt schedules the
+// first report.
+type SubscribeRequest struct {
+	Frequency string     `json:"frequency" binding:"required,oneof=once daily weekly monthly"`
+	Metrics   []string   `json:"metrics"`
+	SendAt    *time.Time `json:"send_at"`
+}
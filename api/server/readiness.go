@@ -0,0 +1,98 @@
+package api
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/0xsj/mios.io/pkg/response"
+	"github.com/gin-gonic/gin"
+)
+
+// ReadinessCheck reports whether a dependency can serve requests, such as
+// pinging the database pool
+type ReadinessCheck func(ctx context.Context) error
+
+type readinessCheck struct {
+	name  string
+	check ReadinessCheck
+}
+
+// DependencyStatus is the outcome of one readiness check
+type DependencyStatus struct {
+	Status    string  `json:"status"`
+	LatencyMS float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// AddReadinessCheck adds a dependency that must be reachable for
+// /health/ready to report the instance ready
+func (s *Server) AddReadinessCheck(name string, check ReadinessCheck) {
+	s.readinessChecks = append(s.readinessChecks, readinessCheck{name: name, check: check})
+}
+
+func (s *Server) registerHealthRoutes() {
+	s.router.GET("/health", s.handleHealthCheck)
+	s.router.GET("/health/live", s.handleLiveness)
+	s.router.GET("/health/ready", s.handleReadiness)
+}
+
+// handleLiveness reports that the process is up without touching any
+// dependency, so a database outage doesn't get healthy instances restarted
+func (s *Server) handleLiveness(c *gin.Context) {
+	response.Success(c, gin.H{"status": "ok"}, "Service is alive")
+}
+
+// handleReadiness checks every dependency concurrently and answers 503 when
+// any of them is down
+func (s *Server) handleReadiness(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), s.config.ReadinessTimeout)
+	defer cancel()
+
+	statuses := make(map[string]DependencyStatus, len(s.readinessChecks))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, rc := range s.readinessChecks {
+		wg.Add(1)
+		go func(rc readinessCheck) {
+			defer wg.Done()
+			status := runReadinessCheck(ctx, rc.check)
+
+			mu.Lock()
+			statuses[rc.name] = status
+			mu.Unlock()
+		}(rc)
+	}
+	wg.Wait()
+
+	ready := true
+	for name, status := range statuses {
+		if status.Status != "up" {
+			ready = false
+			s.logger.Warnf("Readiness check failed for %s: %s", name, status.Error)
+		}
+	}
+
+	if !ready {
+		response.Error(c, response.ErrorResponse{
+			Code:    "SERVICE_UNAVAILABLE",
+			Message: "One or more dependencies are unavailable",
+		}, statuses)
+		return
+	}
+	response.Success(c, gin.H{"status": "ok", "dependencies": statuses}, "Service is ready")
+}
+
+func runReadinessCheck(ctx context.Context, check ReadinessCheck) DependencyStatus {
+	start := time.Now()
+	err := check(ctx)
+	status := DependencyStatus{
+		Status:    "up",
+		LatencyMS: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if err != nil {
+		status.Status = "down"
+		status.Error = err.Error()
+	}
+	return status
+}
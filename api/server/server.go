@@ -2,51 +2,91 @@
 package api
 
 import (
+	"context"
 	"fmt"
+	"net/http"
 	"time"
 
+	"github.com/0xsj/mios.io/api/admin"
 	"github.com/0xsj/mios.io/api/analytics"
 	"github.com/0xsj/mios.io/api/auth"
 	"github.com/0xsj/mios.io/api/content"
 	"github.com/0xsj/mios.io/api/file" // Add file import
 	"github.com/0xsj/mios.io/api/link_metadata"
+	"github.com/0xsj/mios.io/api/profile"
+	"github.com/0xsj/mios.io/api/report"
 	"github.com/0xsj/mios.io/api/user"
 	"github.com/0xsj/mios.io/config"
 	db "github.com/0xsj/mios.io/db/sqlc"
 	"github.com/0xsj/mios.io/log"
 	"github.com/0xsj/mios.io/middleware"
+	"github.com/0xsj/mios.io/pkg/metrics"
 	"github.com/0xsj/mios.io/pkg/redis"
 	"github.com/0xsj/mios.io/pkg/response"
+	"github.com/0xsj/mios.io/pkg/storage"
 	"github.com/0xsj/mios.io/service"
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
 type Server struct {
 	config      config.Config
 	router      *gin.Engine
+	httpServer  *http.Server
 	store       db.Querier
 	logger      log.Logger
 	redisClient *redis.Client
+	cdnMonitor  *storage.CDNMonitor
+	limiter     *middleware.ConcurrencyLimiter
+
+	readinessChecks []readinessCheck
 }
 
-func NewServer(config config.Config, store db.Querier, logger log.Logger, redisClient *redis.Client) (*Server, error) {
+// NewServer creates the API server. cdnMonitor is nil when no storage CDN is
+// configured; otherwise its state is reported by the health endpoint. When m
+// is set every request is recorded and the registry is served on /metrics.
+func NewServer(config config.Config, store db.Querier, logger log.Logger, redisClient *redis.Client, cdnMonitor *storage.CDNMonitor, m *metrics.Metrics) (*Server, error) {
 	router := gin.Default()
 
 	if err := router.SetTrustedProxies([]string{"127.0.0.1"}); err != nil {
 		return nil, fmt.Errorf("failed to set trusted proxies: %w", err)
 	}
 
+	// Registered first so requests shed or recovered below are counted too
+	if m != nil {
+		router.Use(middleware.MetricsMiddleware(m))
+	}
 	router.Use(middleware.RequestLogger(logger))
 	router.Use(middleware.Recovery(logger))
 	router.Use(middleware.CORSMiddleware())
 
+	// Shed load before any handler touches the database; health and metrics
+	// stay reachable so a saturated instance can still be observed
+	var limiter *middleware.ConcurrencyLimiter
+	if config.MaxConcurrentRequests > 0 {
+		limiter = middleware.NewConcurrencyLimiter(middleware.ConcurrencyLimitConfig{
+			MaxInFlight: config.MaxConcurrentRequests,
+			RetryAfter:  config.ConcurrencyRetryAfter,
+			ExemptPaths: []string{"/health", "/health/live", "/health/ready", "/metrics"},
+		}, m, logger)
+		router.Use(limiter.Middleware())
+	}
+
+	if m != nil {
+		router.GET("/metrics", gin.WrapH(promhttp.Handler()))
+	}
+
 	server := &Server{
 		config:      config,
 		router:      router,
+		httpServer:  &http.Server{Handler: router},
 		store:       store,
 		logger:      logger,
 		redisClient: redisClient,
+		cdnMonitor:  cdnMonitor,
+		limiter:     limiter,
 	}
+	server.registerHealthRoutes()
 
 	logger.Info("API server initialized successfully")
 	return server, nil
@@ -60,12 +100,17 @@ func (s *Server) RegisterHandlers(
 	analyticsHandler *analytics.Handler,
 	linkMetadataHandler *link_metadata.Handler,
 	fileHandler *file.Handler, // Add file handler parameter
+	profileHandler *profile.Handler,
+	reportHandler *report.Handler,
+	adminHandler *admin.Handler,
 ) {
 	s.logger.Info("Registering API routes")
 
 	authMiddleware := middleware.AuthMiddleware(authService, s.logger)
 	adminMiddleware := middleware.AdminMiddleware(s.logger)
 	verifiedEmailMiddleware := middleware.RequireVerifiedEmail(authService, s.logger)
+	optionalAuthMiddleware := middleware.OptionalAuthMiddleware(authService, s.logger)
+	accountRestoreMiddleware := middleware.AccountRestoreMiddleware(authService, s.logger)
 
 	publicRoutes := s.router.Group("/api")
 	{
@@ -74,36 +119,58 @@ func (s *Server) RegisterHandlers(
 		{
 			authGroup.POST("/register", authHandler.Register)
 			authGroup.POST("/login", authHandler.Login)
+			authGroup.POST("/login/2fa", authHandler.LoginTwoFactor)
+			authGroup.GET("/oauth/:provider", authHandler.OAuthStart)
+			authGroup.GET("/oauth/:provider/callback", authHandler.OAuthCallback)
 			authGroup.POST("/refresh", authHandler.RefreshToken)
 			authGroup.POST("/forgot-password", authHandler.ForgotPassword)
 			authGroup.POST("/reset-password", authHandler.ResetPassword)
-			// authGroup.POST("/verify-email", authHandler.VerifyEmail)
+			authGroup.POST("/password-strength", authHandler.CheckPasswordStrength)
+			authGroup.POST("/verify-email", authHandler.VerifyEmail)
 		}
 
 		// Public user routes
 		publicUserGroup := publicRoutes.Group("/users")
 		{
-			publicUserGroup.GET("/username/:username", userHandler.GetUserByUsername)
-			publicUserGroup.GET("/handle/:handle", userHandler.GetUserByHandle)
+			publicUserGroup.GET("/username/:username", optionalAuthMiddleware, userHandler.GetUserByUsername)
+			publicUserGroup.GET("/handle/:handle", optionalAuthMiddleware, userHandler.GetUserByHandle)
+			publicUserGroup.GET("/handle/:handle/available", userHandler.CheckHandleAvailability)
+
+			// Accounts pending deletion sign in with a restore token, not a session
+			publicUserGroup.POST("/:id/restore", accountRestoreMiddleware, userHandler.CancelAccountDeletion)
+		}
+
+		// Public profile routes
+		publicProfileGroup := publicRoutes.Group("/profiles")
+		{
+			publicProfileGroup.GET("/:handle/meta", profileHandler.GetProfileMeta)
+			publicProfileGroup.GET("/:handle/links", profileHandler.GetEmbedLinks)
+			publicProfileGroup.GET("/:handle/embed.js", profileHandler.GetEmbedScript)
+			publicProfileGroup.GET("/templates", profileHandler.ListTemplates)
 		}
 
 		// Public content routes
 		publicContentGroup := publicRoutes.Group("/content")
 		{
-			publicContentGroup.GET("/user/:user_id", contentHandler.GetUserContentItems)
+			publicContentGroup.GET("/user/:user_id", optionalAuthMiddleware, contentHandler.GetUserContentItems)
 		}
 
 		// Public link metadata routes
 		publicMetadataGroup := publicRoutes.Group("/link-metadata")
 		{
 			publicMetadataGroup.GET("/platforms", linkMetadataHandler.ListPlatforms)
+			publicMetadataGroup.GET("/detect", linkMetadataHandler.DetectPlatform)
 			publicMetadataGroup.GET("/url", linkMetadataHandler.GetLinkMetadata)
+			publicMetadataGroup.POST("/freshness", linkMetadataHandler.GetFreshnessStatus)
 		}
 
+		// Unsubscribe link in analytics report emails
+		publicRoutes.GET("/reports/unsubscribe", reportHandler.UnsubscribeByToken)
+
 		// Public file routes (for getting file URLs)
 		publicFileGroup := publicRoutes.Group("/files")
 		{
-			publicFileGroup.GET("/:key/url", fileHandler.GetFileURL)
+			publicFileGroup.GET("/:key/url", optionalAuthMiddleware, fileHandler.GetFileURL)
 		}
 	}
 
@@ -115,6 +182,11 @@ func (s *Server) RegisterHandlers(
 		authGroup := protectedRoutes.Group("/auth")
 		{
 			authGroup.POST("/logout", authHandler.Logout)
+			authGroup.GET("/sessions", authHandler.ListSessions)
+			authGroup.DELETE("/sessions/:id", authHandler.RevokeSession)
+			authGroup.POST("/change-password", authHandler.ChangePassword)
+			authGroup.POST("/2fa/enable", authHandler.EnableTwoFactor)
+			authGroup.POST("/2fa/verify", authHandler.VerifyTwoFactor)
 		}
 
 		// User routes
@@ -123,7 +195,21 @@ func (s *Server) RegisterHandlers(
 			userGroup.GET("/:id", userHandler.GetUser)
 			userGroup.PUT("/:id", userHandler.UpdateUser)
 			userGroup.PATCH("/:id/handle", userHandler.UpdateHandle)
+			userGroup.POST("/:id/handle/transfer", userHandler.TransferHandle)
+			userGroup.POST("/:id/handle/claim", userHandler.ClaimHandle)
+			userGroup.POST("/:id/domain/verify", userHandler.InitiateDomainVerification)
+			userGroup.GET("/:id/domain/status", userHandler.CheckDomainVerification)
 			userGroup.PATCH("/:id/onboarded", userHandler.UpdateOnboardedStatus)
+			userGroup.PATCH("/:id/profile/analytics", userHandler.UpdateAnalyticsSettings)
+			userGroup.PATCH("/:id/profile/locale", userHandler.UpdateLocale)
+			userGroup.PATCH("/:id/profile/visibility", userHandler.UpdateVisibility)
+			userGroup.GET("/:id/export", userHandler.ExportUserData)
+			userGroup.GET("/:id/avatars", userHandler.ListAvatars)
+			userGroup.POST("/:id/avatars", userHandler.UploadAvatar)
+			userGroup.PATCH("/:id/avatars/:avatarId/activate", userHandler.ActivateAvatar)
+			userGroup.GET("/:id/reports", reportHandler.ListSubscriptions)
+			userGroup.POST("/:id/reports", reportHandler.Subscribe)
+			userGroup.DELETE("/:id/reports/:subscriptionId", reportHandler.Unsubscribe)
 			userGroup.DELETE("/:id", userHandler.DeleteUser)
 		}
 
@@ -137,16 +223,37 @@ func (s *Server) RegisterHandlers(
 				verifiedContentGroup.POST("", contentHandler.CreateContentItem)
 				verifiedContentGroup.PUT("/:id", contentHandler.UpdateContentItem)
 				verifiedContentGroup.PATCH("/:id/position", contentHandler.UpdateContentItemPosition)
+				verifiedContentGroup.PATCH("/style/bulk", contentHandler.BulkUpdateStyle)
+				verifiedContentGroup.PATCH("/reorder", contentHandler.ReorderContentItems)
+				verifiedContentGroup.PATCH("/:id/link-health", contentHandler.SetLinkAutoDeactivate)
+				verifiedContentGroup.PATCH("/:id/pin", contentHandler.SetContentItemPin)
 				verifiedContentGroup.DELETE("/:id", contentHandler.DeleteContentItem)
+				verifiedContentGroup.POST("/:id/clone", contentHandler.CloneContentItem)
+				verifiedContentGroup.POST("/:id/restore", contentHandler.RestoreContentItem)
+
+				// Approval workflow for accounts with collaborators
+				verifiedContentGroup.POST("/:id/revisions/:rev/approve", contentHandler.ApproveRevision)
+				verifiedContentGroup.POST("/:id/revisions/:rev/reject", contentHandler.RejectRevision)
+				verifiedContentGroup.PUT("/approval", contentHandler.SetApprovalRequired)
+				verifiedContentGroup.POST("/collaborators", contentHandler.AddCollaborator)
+				verifiedContentGroup.DELETE("/collaborators/:collaborator_id", contentHandler.RemoveCollaborator)
 			}
 
 			// Some operations might not need email verification
 			contentGroup.GET("/:id", contentHandler.GetContentItem)
+			contentGroup.GET("/:id/history", contentHandler.GetContentHistory)
+			contentGroup.GET("/types", contentHandler.GetContentTypeSummary)
+			contentGroup.GET("/trash", contentHandler.ListDeletedContentItems)
+			contentGroup.GET("/user/:user_id/summary", contentHandler.GetContentSummary)
+			contentGroup.GET("/revisions/pending", contentHandler.ListPendingRevisions)
 		}
 
 		// File upload routes - require authentication
 		fileGroup := protectedRoutes.Group("/files")
 		{
+			fileGroup.GET("", fileHandler.ListFiles)
+			fileGroup.GET("/:key/download-url", fileHandler.GetDownloadURL)
+
 			// Some operations might need email verification
 			verifiedFileGroup := fileGroup.Group("")
 			verifiedFileGroup.Use(verifiedEmailMiddleware)
@@ -155,6 +262,7 @@ func (s *Server) RegisterHandlers(
 				verifiedFileGroup.POST("/upload/avatar", fileHandler.UploadAvatar)
 				verifiedFileGroup.POST("/upload/content", fileHandler.UploadContentMedia)
 				verifiedFileGroup.POST("/presigned-upload", fileHandler.GetPresignedUploadURL)
+				verifiedFileGroup.POST("/presigned-upload/complete", fileHandler.CompleteUpload)
 				verifiedFileGroup.DELETE("/:key", fileHandler.DeleteFile)
 			}
 		}
@@ -163,14 +271,29 @@ func (s *Server) RegisterHandlers(
 		analyticsGroup := protectedRoutes.Group("/analytics")
 		{
 			analyticsGroup.POST("/clicks", analyticsHandler.RecordClick)
+			analyticsGroup.POST("/clicks/batch", analyticsHandler.RecordClicksBatch)
+			analyticsGroup.POST("/interactions", analyticsHandler.RecordInteraction)
 			analyticsGroup.POST("/page-views", analyticsHandler.RecordPageView)
 			analyticsGroup.GET("/items/:id", analyticsHandler.GetContentItemAnalytics)
 			analyticsGroup.POST("/items/:id/time-range", analyticsHandler.GetItemAnalyticsByTimeRange)
 			analyticsGroup.GET("/users/:id", analyticsHandler.GetUserAnalytics)
 			analyticsGroup.POST("/users/:id/time-range", analyticsHandler.GetUserAnalyticsByTimeRange)
 			analyticsGroup.POST("/users/:id/page-views", analyticsHandler.GetProfilePageViewsByTimeRange)
+			analyticsGroup.POST("/users/:id/dates", analyticsHandler.GetAnalyticsForDates)
 			analyticsGroup.GET("/users/:id/dashboard", analyticsHandler.GetProfileDashboard)
+			analyticsGroup.GET("/users/:id/summary", analyticsHandler.GetSummaryCards)
 			analyticsGroup.POST("/users/:id/referrers", analyticsHandler.GetReferrerAnalytics)
+			analyticsGroup.POST("/users/:id/devices", analyticsHandler.GetDeviceBreakdown)
+			analyticsGroup.POST("/users/:id/geo", analyticsHandler.GetGeoAnalytics)
+			analyticsGroup.POST("/users/:id/campaigns", analyticsHandler.GetCampaignAnalytics)
+			analyticsGroup.GET("/users/:id/export", analyticsHandler.ExportUserAnalytics)
+			analyticsGroup.GET("/users/:id/live", analyticsHandler.StreamLiveEvents)
+		}
+
+		// Profile onboarding routes
+		profileGroup := protectedRoutes.Group("/profiles")
+		{
+			profileGroup.POST("/copy/:templateId", profileHandler.CopyFromTemplate)
 		}
 
 		// Protected link metadata routes
@@ -184,13 +307,25 @@ func (s *Server) RegisterHandlers(
 	adminRoutes := protectedRoutes.Group("/admin")
 	adminRoutes.Use(adminMiddleware)
 	{
+		adminRoutes.GET("/users", userHandler.ListUsers)
 		adminRoutes.PATCH("/users/:id/premium", userHandler.UpdatePremiumStatus)
 		adminRoutes.PATCH("/users/:id/admin", userHandler.UpdateAdminStatus)
+		adminRoutes.POST("/users/:id/restore", userHandler.RestoreUser)
+		adminRoutes.PATCH("/users/:id/template", profileHandler.SetTemplate)
+		adminRoutes.POST("/users/verification-status", authHandler.GetVerificationStatuses)
+		adminRoutes.POST("/users/unverified/cleanup", authHandler.CleanupUnverifiedAccounts)
+		adminRoutes.POST("/invite-codes", authHandler.CreateInviteCodes)
+		adminRoutes.GET("/invite-codes", authHandler.ListInviteCodes)
+		adminRoutes.POST("/analytics/rebuild-rollups", analyticsHandler.RebuildRollups)
+		adminRoutes.GET("/analytics/rebuild-rollups/:job_id", analyticsHandler.GetRollupJob)
+		adminRoutes.GET("/log-level", adminHandler.GetLogLevel)
+		adminRoutes.POST("/log-level", adminHandler.SetLogLevel)
+		adminRoutes.POST("/analytics/purge", adminHandler.PurgeAnalytics)
+		adminRoutes.POST("/platforms", linkMetadataHandler.CreatePlatform)
+		adminRoutes.PATCH("/platforms/:domain", linkMetadataHandler.UpdatePlatform)
+		adminRoutes.DELETE("/platforms/:domain", linkMetadataHandler.DeletePlatform)
 	}
 
-	// Health check endpoint
-	s.router.GET("/health", s.handleHealthCheck)
-
 	s.logger.Info("API routes registered successfully")
 }
 
@@ -211,13 +346,32 @@ func (s *Server) handleHealthCheck(c *gin.Context) {
 		"version":     s.config.Version,
 	}
 
+	// A CDN outage degrades media delivery but not the API itself
+	if s.cdnMonitor != nil {
+		cdnStatus := s.cdnMonitor.Status()
+		healthInfo["cdn"] = cdnStatus
+		if !cdnStatus.Available {
+			healthInfo["status"] = "degraded"
+		}
+	}
+
+	if s.limiter != nil {
+		healthInfo["requests"] = map[string]interface{}{
+			"in_flight":     s.limiter.InFlight(),
+			"max_in_flight": s.config.MaxConcurrentRequests,
+			"shed":          s.limiter.Shed(),
+		}
+	}
+
 	response.Success(c, healthInfo, "Service is healthy")
 }
 
-// Start begins listening for HTTP requests on the specified address
+// Start begins listening for HTTP requests on the specified address. It
+// returns http.ErrServerClosed once Shutdown is called.
 func (s *Server) Start(addr string) error {
 	s.logger.Infof("Starting API server on %s", addr)
-	return s.router.Run(addr)
+	s.httpServer.Addr = addr
+	return s.httpServer.ListenAndServe()
 }
 
 // Router returns the Gin engine for testing
@@ -225,7 +379,18 @@ func (s *Server) Router() *gin.Engine {
 	return s.router
 }
 
-// Shutdown gracefully shuts down the server
-func (s *Server) Shutdown() {
+// Shutdown stops accepting connections and waits for in-flight requests to
+// finish. Connections still open when ctx is done, such as live analytics
+// streams, are closed.
+func (s *Server) Shutdown(ctx context.Context) error {
 	s.logger.Info("Shutting down API server")
+
+	if err := s.httpServer.Shutdown(ctx); err != nil {
+		s.logger.Warnf("Requests still in flight at shutdown deadline, closing connections: %v", err)
+		s.httpServer.Close()
+		return fmt.Errorf("failed to drain in-flight requests: %w", err)
+	}
+
+	s.logger.Info("API server stopped")
+	return nil
 }
\ No newline at end of file
@@ -1,7 +1,9 @@
 package user
 
 import (
+	"fmt"
 	"net/http"
+	"strconv"
 
 	"github.com/0xsj/mios.io/log"
 	"github.com/0xsj/mios.io/pkg/response"
@@ -29,13 +31,26 @@ func (h *Handler) RegisterRoutes(r *gin.Engine) {
 		userGroup.GET("/:id", h.GetUser)
 		userGroup.GET("/username/:username", h.GetUserByUsername)
 		userGroup.GET("/handle/:handle", h.GetUserByHandle)
+		userGroup.GET("/handle/:handle/available", h.CheckHandleAvailability)
 		userGroup.GET("/email/:email", h.GetUserByEmail)
 		userGroup.PUT("/:id", h.UpdateUser)
 		userGroup.PATCH("/:id/handle", h.UpdateHandle)
+		userGroup.POST("/:id/handle/transfer", h.TransferHandle)
+		userGroup.POST("/:id/handle/claim", h.ClaimHandle)
+		userGroup.POST("/:id/domain/verify", h.InitiateDomainVerification)
+		userGroup.GET("/:id/domain/status", h.CheckDomainVerification)
 		userGroup.PATCH("/:id/premium", h.UpdatePremiumStatus)
 		userGroup.PATCH("/:id/admin", h.UpdateAdminStatus)
 		userGroup.PATCH("/:id/onboarded", h.UpdateOnboardedStatus)
+		userGroup.PATCH("/:id/profile/analytics", h.UpdateAnalyticsSettings)
+		userGroup.PATCH("/:id/profile/locale", h.UpdateLocale)
+		userGroup.PATCH("/:id/profile/visibility", h.UpdateVisibility)
+		userGroup.GET("/:id/export", h.ExportUserData)
+		userGroup.GET("/:id/avatars", h.ListAvatars)
+		userGroup.POST("/:id/avatars", h.UploadAvatar)
+		userGroup.PATCH("/:id/avatars/:avatarId/activate", h.ActivateAvatar)
 		userGroup.DELETE("/:id", h.DeleteUser)
+		userGroup.POST("/:id/restore", h.CancelAccountDeletion)
 	}
 }
 
@@ -148,6 +163,10 @@ func (h *Handler) GetUserByUsername(c *gin.Context) {
 		response.HandleError(c, err, h.logger)
 		return
 	}
+	if !user.VisibleTo(c.GetString("user_id")) {
+		response.Error(c, response.ErrNotFoundResponse, "User not found")
+		return
+	}
 
 	id, _ := uuid.Parse(user.ID)
 	responseData := UserResponse{
@@ -186,6 +205,10 @@ func (h *Handler) GetUserByHandle(c *gin.Context) {
 		response.HandleError(c, err, h.logger)
 		return
 	}
+	if !user.VisibleTo(c.GetString("user_id")) {
+		response.Error(c, response.ErrNotFoundResponse, "User not found")
+		return
+	}
 
 	id, _ := uuid.Parse(user.ID)
 	responseData := UserResponse{
@@ -208,6 +231,24 @@ func (h *Handler) GetUserByHandle(c *gin.Context) {
 	response.Success(c, responseData, "User retrieved successfully")
 }
 
+// CheckHandleAvailability tells the registration form whether a handle is
+// free before it is submitted
+func (h *Handler) CheckHandleAvailability(c *gin.Context) {
+	handle := c.Param("handle")
+	h.logger.Debugf("CheckHandleAvailability handler called for handle: %s", handle)
+
+	available, err := h.userService.IsHandleAvailable(c, handle)
+	if err != nil {
+		response.HandleError(c, err, h.logger)
+		return
+	}
+
+	response.Success(c, HandleAvailabilityResponse{
+		Handle:    handle,
+		Available: available,
+	}, "Handle availability checked")
+}
+
 func (h *Handler) GetUserByEmail(c *gin.Context) {
 	email := c.Param("email")
 	h.logger.Debugf("GetUserByEmail handler called for email: %s", email)
@@ -246,6 +287,53 @@ func (h *Handler) GetUserByEmail(c *gin.Context) {
 	response.Success(c, responseData, "User retrieved successfully")
 }
 
+// ListUsers returns a page of users for the admin panel, optionally
+// narrowed by a search term and the premium, admin, onboarded and verified
+// flags
+func (h *Handler) ListUsers(c *gin.Context) {
+	h.logger.Info("ListUsers handler called")
+
+	input := service.ListUsersInput{Search: c.Query("search")}
+	input.Page, _ = strconv.Atoi(c.DefaultQuery("page", "1"))
+	input.PageSize, _ = strconv.Atoi(c.DefaultQuery("page_size", "20"))
+
+	flags := []struct {
+		param string
+		dest  **bool
+	}{
+		{"premium", &input.IsPremium},
+		{"admin", &input.IsAdmin},
+		{"onboarded", &input.Onboarded},
+		{"verified", &input.Verified},
+	}
+	for _, flag := range flags {
+		value := c.Query(flag.param)
+		if value == "" {
+			continue
+		}
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			response.Error(c, response.ErrBadRequestResponse, flag.param+" must be true or false")
+			return
+		}
+		*flag.dest = &parsed
+	}
+
+	users, err := h.userService.ListUsers(c, input)
+	if err != nil {
+		h.logger.Errorf("Failed to list users: %v", err)
+		response.HandleError(c, err, h.logger)
+		return
+	}
+
+	response.WithPagination(c, users.Users, response.PaginationMeta{
+		CurrentPage:  users.Page,
+		TotalPages:   users.TotalPages,
+		PerPage:      users.PageSize,
+		TotalRecords: int(users.TotalCount),
+	})
+}
+
 func (h *Handler) UpdateUser(c *gin.Context) {
 	userID := c.Param("id")
 	h.logger.Infof("UpdateUser handler called for user ID: %s", userID)
@@ -447,17 +535,375 @@ func (h *Handler) UpdateOnboardedStatus(c *gin.Context) {
 	response.Success(c, responseData, "User onboarded status updated successfully")
 }
 
+// UpdateAnalyticsSettings lets a user turn visitor tracking on their own
+// profile on or off
+func (h *Handler) UpdateAnalyticsSettings(c *gin.Context) {
+	userID := c.Param("id")
+	h.logger.Infof("UpdateAnalyticsSettings handler called for user ID: %s", userID)
+
+	if !h.requireSelf(c, userID) {
+		return
+	}
+
+	var req UpdateAnalyticsSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warnf("Invalid request format: %v", err)
+		response.Error(c, response.ErrBadRequestResponse, err.Error())
+		return
+	}
+
+	updatedUser, err := h.userService.UpdateAnalyticsEnabled(c, userID, *req.AnalyticsEnabled)
+	if err != nil {
+		h.logger.Errorf("Failed to update analytics settings: %v", err)
+		response.HandleError(c, err, h.logger)
+		return
+	}
+
+	h.logger.Infof("Analytics tracking set to %v for user ID: %s", *req.AnalyticsEnabled, userID)
+	response.Success(c, updatedUser, "Analytics settings updated successfully")
+}
+
+// UpdateLocale sets the language a user's emails are sent in
+func (h *Handler) UpdateLocale(c *gin.Context) {
+	userID := c.Param("id")
+	h.logger.Infof("UpdateLocale handler called for user ID: %s", userID)
+
+	if !h.requireSelf(c, userID) {
+		return
+	}
+
+	var req UpdateLocaleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warnf("Invalid request format: %v", err)
+		response.Error(c, response.ErrBadRequestResponse, err.Error())
+		return
+	}
+
+	updatedUser, err := h.userService.UpdateLocale(c, userID, *req.Locale)
+	if err != nil {
+		h.logger.Errorf("Failed to update locale: %v", err)
+		response.HandleError(c, err, h.logger)
+		return
+	}
+
+	h.logger.Infof("Locale set to %q for user ID: %s", updatedUser.Locale, userID)
+	response.Success(c, updatedUser, "Locale updated successfully")
+}
+
+// UpdateVisibility makes a profile public, unlisted or private
+func (h *Handler) UpdateVisibility(c *gin.Context) {
+	userID := c.Param("id")
+	h.logger.Infof("UpdateVisibility handler called for user ID: %s", userID)
+
+	if !h.requireSelf(c, userID) {
+		return
+	}
+
+	var req UpdateVisibilityRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warnf("Invalid request format: %v", err)
+		response.Error(c, response.ErrBadRequestResponse, err.Error())
+		return
+	}
+
+	updatedUser, err := h.userService.UpdateVisibility(c, userID, req.Visibility)
+	if err != nil {
+		h.logger.Errorf("Failed to update visibility: %v", err)
+		response.HandleError(c, err, h.logger)
+		return
+	}
+
+	h.logger.Infof("Visibility set to %s for user ID: %s", updatedUser.Visibility, userID)
+	response.Success(c, updatedUser, "Visibility updated successfully")
+}
+
+// ExportUserData sends everything stored about the user as a JSON file
+// download. Analytics are streamed after the headers go out, so an error
+// partway through can only cut the file short.
+func (h *Handler) ExportUserData(c *gin.Context) {
+	userID := c.Param("id")
+	h.logger.Infof("ExportUserData handler called for user ID: %s", userID)
+
+	if !h.requireSelf(c, userID) {
+		return
+	}
+
+	export, err := h.userService.ExportUserData(c.Request.Context(), userID)
+	if err != nil {
+		h.logger.Errorf("Failed to export user data: %v", err)
+		response.HandleError(c, err, h.logger)
+		return
+	}
+
+	c.Header("Content-Type", "application/json")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"mios-data-%s.json\"", userID))
+	c.Status(http.StatusOK)
+
+	if err := export.WriteJSON(c.Request.Context(), c.Writer); err != nil {
+		h.logger.Errorf("User data export for user ID %s ended early: %v", userID, err)
+		c.Abort()
+		return
+	}
+
+	h.logger.Infof("User data export completed for user ID: %s", userID)
+}
+
+// DeleteUser schedules the account for deletion. It disappears at once but
+// can be restored until the grace period is over.
 func (h *Handler) DeleteUser(c *gin.Context) {
 	userID := c.Param("id")
 	h.logger.Infof("DeleteUser handler called for user ID: %s", userID)
 
-	err := h.userService.DeleteUser(c, userID)
+	if !h.requireSelf(c, userID) {
+		return
+	}
+
+	deletion, err := h.userService.RequestAccountDeletion(c, userID)
 	if err != nil {
 		h.logger.Errorf("Failed to delete user: %v", err)
 		response.HandleError(c, err, h.logger)
 		return
 	}
 
-	h.logger.Infof("User deleted successfully with ID: %s", userID)
-	response.Success(c, nil, "User deleted successfully", http.StatusOK)
+	h.logger.Infof("User with ID %s scheduled for deletion at %s", userID, deletion.PurgeAt)
+	response.Success(c, deletion, "Account scheduled for deletion", http.StatusOK)
+}
+
+// CancelAccountDeletion lets a user take back their own deletion request
+func (h *Handler) CancelAccountDeletion(c *gin.Context) {
+	userID := c.Param("id")
+	h.logger.Infof("CancelAccountDeletion handler called for user ID: %s", userID)
+
+	if !h.requireSelf(c, userID) {
+		return
+	}
+
+	h.restoreUser(c, userID)
+}
+
+// RestoreUser lets an admin restore any account still in its deletion
+// grace period
+func (h *Handler) RestoreUser(c *gin.Context) {
+	userID := c.Param("id")
+	h.logger.Infof("RestoreUser handler called for user ID: %s", userID)
+
+	h.restoreUser(c, userID)
+}
+
+func (h *Handler) restoreUser(c *gin.Context, userID string) {
+	user, err := h.userService.CancelAccountDeletion(c, userID)
+	if err != nil {
+		h.logger.Errorf("Failed to restore user: %v", err)
+		response.HandleError(c, err, h.logger)
+		return
+	}
+
+	h.logger.Infof("User restored with ID: %s", userID)
+	response.Success(c, user, "Account restored successfully")
+}
+
+func (h *Handler) ListAvatars(c *gin.Context) {
+	userID := c.Param("id")
+	h.logger.Infof("ListAvatars handler called for user ID: %s", userID)
+
+	if !h.requireSelf(c, userID) {
+		return
+	}
+
+	avatars, err := h.userService.ListAvatars(c, userID)
+	if err != nil {
+		h.logger.Errorf("Failed to list avatars: %v", err)
+		response.HandleError(c, err, h.logger)
+		return
+	}
+
+	response.Success(c, avatars, "Avatars retrieved successfully")
+}
+
+func (h *Handler) UploadAvatar(c *gin.Context) {
+	userID := c.Param("id")
+	h.logger.Infof("UploadAvatar handler called for user ID: %s", userID)
+
+	if !h.requireSelf(c, userID) {
+		return
+	}
+
+	if err := c.Request.ParseMultipartForm(10 << 20); err != nil {
+		h.logger.Warnf("Failed to parse multipart form: %v", err)
+		response.Error(c, response.ErrBadRequestResponse, "Failed to parse form data")
+		return
+	}
+
+	file, header, err := c.Request.FormFile("avatar")
+	if err != nil {
+		h.logger.Warnf("Failed to get avatar file from form: %v", err)
+		response.Error(c, response.ErrBadRequestResponse, "Avatar file is required")
+		return
+	}
+	defer file.Close()
+
+	contentType := header.Header.Get("Content-Type")
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	avatar, err := h.userService.UploadAvatar(c, userID, service.UploadFileInput{
+		File:        file,
+		Filename:    header.Filename,
+		ContentType: contentType,
+	})
+	if err != nil {
+		h.logger.Errorf("Failed to upload avatar: %v", err)
+		response.HandleError(c, err, h.logger)
+		return
+	}
+
+	response.Success(c, avatar, "Avatar uploaded successfully", http.StatusCreated)
+}
+
+func (h *Handler) ActivateAvatar(c *gin.Context) {
+	userID := c.Param("id")
+	avatarID := c.Param("avatarId")
+	h.logger.Infof("ActivateAvatar handler called for user ID: %s, avatar ID: %s", userID, avatarID)
+
+	if !h.requireSelf(c, userID) {
+		return
+	}
+
+	updatedUser, err := h.userService.SetActiveAvatar(c, userID, avatarID)
+	if err != nil {
+		h.logger.Errorf("Failed to activate avatar: %v", err)
+		response.HandleError(c, err, h.logger)
+		return
+	}
+
+	response.Success(c, updatedUser, "Avatar activated successfully")
+}
+
+func (h *Handler) TransferHandle(c *gin.Context) {
+	userID := c.Param("id")
+	h.logger.Infof("TransferHandle handler called for user ID: %s", userID)
+
+	if !h.requireSelf(c, userID) {
+		return
+	}
+
+	var req TransferHandleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warnf("Invalid request format: %v", err)
+		response.Error(c, response.ErrBadRequestResponse, err.Error())
+		return
+	}
+
+	transfer, err := h.userService.TransferHandle(c, userID, service.HandleTransferInput{
+		TargetHandle: req.TargetHandle,
+		ReauthInput: service.ReauthInput{
+			Password: req.Password,
+			Code:     req.Code,
+		},
+	})
+	if err != nil {
+		h.logger.Errorf("Failed to transfer handle: %v", err)
+		response.HandleError(c, err, h.logger)
+		return
+	}
+
+	response.Success(c, transfer, "Handle reserved for the target account")
+}
+
+func (h *Handler) ClaimHandle(c *gin.Context) {
+	userID := c.Param("id")
+	h.logger.Infof("ClaimHandle handler called for user ID: %s", userID)
+
+	if !h.requireSelf(c, userID) {
+		return
+	}
+
+	var req ClaimHandleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warnf("Invalid request format: %v", err)
+		response.Error(c, response.ErrBadRequestResponse, err.Error())
+		return
+	}
+
+	updatedUser, err := h.userService.ClaimHandle(c, userID, service.HandleClaimInput{
+		Handle: req.Handle,
+		ReauthInput: service.ReauthInput{
+			Password: req.Password,
+			Code:     req.Code,
+		},
+	})
+	if err != nil {
+		h.logger.Errorf("Failed to claim handle: %v", err)
+		response.HandleError(c, err, h.logger)
+		return
+	}
+
+	response.Success(c, updatedUser, "Handle claimed successfully")
+}
+
+// InitiateDomainVerification returns the TXT record the user must publish to
+// prove they own their custom domain
+func (h *Handler) InitiateDomainVerification(c *gin.Context) {
+	userID := c.Param("id")
+	h.logger.Infof("InitiateDomainVerification handler called for user ID: %s", userID)
+
+	if !h.requireSelf(c, userID) {
+		return
+	}
+
+	var req VerifyDomainRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warnf("Invalid request format: %v", err)
+		response.Error(c, response.ErrBadRequestResponse, err.Error())
+		return
+	}
+
+	verification, err := h.userService.InitiateDomainVerification(c, userID, req.Domain)
+	if err != nil {
+		h.logger.Errorf("Failed to initiate domain verification: %v", err)
+		response.HandleError(c, err, h.logger)
+		return
+	}
+
+	response.Success(c, verification, "Publish the TXT record to verify the domain")
+}
+
+// CheckDomainVerification looks up the user's TXT record and reports whether
+// the custom domain is verified
+func (h *Handler) CheckDomainVerification(c *gin.Context) {
+	userID := c.Param("id")
+	h.logger.Debugf("CheckDomainVerification handler called for user ID: %s", userID)
+
+	if !h.requireSelf(c, userID) {
+		return
+	}
+
+	verification, err := h.userService.CheckDomainVerification(c, userID)
+	if err != nil {
+		h.logger.Errorf("Failed to check domain verification: %v", err)
+		response.HandleError(c, err, h.logger)
+		return
+	}
+
+	response.Success(c, verification, "Domain verification status retrieved")
+}
+
+// requireSelf rejects the request unless the authenticated user is the
+// user named in the path.
+func (h *Handler) requireSelf(c *gin.Context, userID string) bool {
+	authUserID, exists := c.Get("user_id")
+	if !exists {
+		h.logger.Warn("User ID not found in context")
+		response.Error(c, response.ErrUnauthorizedResponse, "User not authenticated")
+		return false
+	}
+
+	if authUserID.(string) != userID {
+		h.logger.Warnf("User %v attempted to manage account of user %s", authUserID, userID)
+		response.Error(c, response.ErrForbiddenResponse, "You can only manage your own account")
+		return false
+	}
+
+	return true
 }
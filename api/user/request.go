@@ -34,6 +34,17 @@ type UserResponse struct {
 	Onboarded       bool      `json:"onboarded"`
 }
 
+// VerifyDomainRequest starts proving ownership of a custom domain
+type VerifyDomainRequest struct {
+	Domain string `json:"domain" binding:"required"`
+}
+
+// HandleAvailabilityResponse says whether Handle can be registered
+type HandleAvailabilityResponse struct {
+	Handle    string `json:"handle"`
+	Available bool   `json:"available"`
+}
+
 type UpdateUserRequest struct {
 	Username        *string `json:"username"`
 	Email           *string `json:"email"`
@@ -49,6 +60,21 @@ type UpdateHandleRequest struct {
 	Handle string `json:"handle" binding:"required"`
 }
 
+// TransferHandleRequest gives the caller's handle to the account currently
+// using TargetHandle. Password (and Code, with two-factor on) re-authenticate.
+type TransferHandleRequest struct {
+	TargetHandle string `json:"target_handle" binding:"required"`
+	Password     string `json:"password" binding:"required"`
+	Code         string `json:"code"`
+}
+
+// ClaimHandleRequest takes a handle another account transferred to the caller
+type ClaimHandleRequest struct {
+	Handle   string `json:"handle" binding:"required"`
+	Password string `json:"password" binding:"required"`
+	Code     string `json:"code"`
+}
+
 type UpdatePremiumStatusRequest struct {
 	IsPremium bool `json:"is_premium"`
 }
@@ -61,6 +87,23 @@ type UpdateOnboardedStatusRequest struct {
 	Onboarded bool `json:"onboarded"`
 }
 
+// UpdateAnalyticsSettingsRequest turns visitor tracking on a profile on or off
+type UpdateAnalyticsSettingsRequest struct {
+	AnalyticsEnabled *bool `json:"analytics_enabled" binding:"required"`
+}
+
+// UpdateLocaleRequest sets the language of a user's emails; an empty locale
+// goes back to the default
+type UpdateLocaleRequest struct {
+	Locale *string `json:"locale" binding:"required"`
+}
+
+// UpdateVisibilityRequest sets who can see a profile: public, unlisted or
+// private
+type UpdateVisibilityRequest struct {
+	Visibility string `json:"visibility" binding:"required"`
+}
+
 type UserListResponse struct {
 	Users      []UserResponse `json:"users"`
 	TotalCount int64          `json:"total_count"`
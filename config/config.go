@@ -13,6 +13,23 @@ type Config struct {
 	Host        string `mapstructure:"HOST"`
 	Port        string `mapstructure:"PORT"`
 
+	// Requests this instance handles at once before shedding load with 503
+	// (0 = unlimited), and the Retry-After sent with a shed request
+	MaxConcurrentRequests int           `mapstructure:"MAX_CONCURRENT_REQUESTS"`
+	ConcurrencyRetryAfter time.Duration `mapstructure:"CONCURRENCY_RETRY_AFTER"`
+
+	// How long shutdown waits for in-flight requests and background workers
+	ShutdownTimeout time.Duration `mapstructure:"SHUTDOWN_TIMEOUT"`
+
+	// How long the readiness check waits for each dependency to answer
+	ReadinessTimeout time.Duration `mapstructure:"READINESS_TIMEOUT"`
+
+	// Log output format (json or console) and minimum level (debug, info,
+	// warn, error). Empty values fall back to the ENVIRONMENT defaults:
+	// JSON at info in production, console at debug otherwise
+	LogFormat string `mapstructure:"LOG_FORMAT"`
+	LogLevel  string `mapstructure:"LOG_LEVEL"`
+
 	DBUsername string `mapstructure:"DB_USERNAME"`
 	DBPassword string `mapstructure:"DB_PASSWORD"`
 	DBHost     string `mapstructure:"DB_HOSTNAME"`
@@ -23,6 +40,26 @@ type Config struct {
 	TokenHourLifespan int    `mapstructure:"TOKEN_HOUR_LIFESPAN"`
 	APISecret         string `mapstructure:"API_SECRET"`
 
+	// Consecutive failed logins before an account is locked, and for how long
+	MaxFailedLoginAttempts int           `mapstructure:"MAX_FAILED_LOGIN_ATTEMPTS"`
+	LockoutDuration        time.Duration `mapstructure:"LOCKOUT_DURATION"`
+
+	// Lowest password strength score (1-4) accepted when a password is set
+	PasswordMinScore int `mapstructure:"PASSWORD_MIN_SCORE"`
+
+	// Require a valid invite code to register; signup stays open when false
+	InviteOnlySignup bool `mapstructure:"INVITE_ONLY_SIGNUP"`
+
+	// Hosts that auth redirect targets may point to besides the API's own
+	// origin (comma separated)
+	AuthRedirectAllowedHosts []string `mapstructure:"AUTH_REDIRECT_ALLOWED_HOSTS"`
+
+	// OAuth sign-in; a provider is enabled when its client ID is set
+	GoogleClientID     string `mapstructure:"GOOGLE_CLIENT_ID"`
+	GoogleClientSecret string `mapstructure:"GOOGLE_CLIENT_SECRET"`
+	GitHubClientID     string `mapstructure:"GITHUB_CLIENT_ID"`
+	GitHubClientSecret string `mapstructure:"GITHUB_CLIENT_SECRET"`
+
 	Version string `mapstructure:"VERSION"`
 
 	RedisHost     string `mapstructure:"REDIS_HOST"`
@@ -30,21 +67,173 @@ type Config struct {
 	RedisPassword string `mapstructure:"REDIS_PASSWORD"`
 	RedisDB       int    `mapstructure:"REDIS_DB"`
 
+	// In-process cache in front of Redis: how many entries each instance
+	// keeps (0 disables it) and how long they are served before Redis is
+	// asked again
+	CacheL1MaxEntries int           `mapstructure:"CACHE_L1_MAX_ENTRIES"`
+	CacheL1TTL        time.Duration `mapstructure:"CACHE_L1_TTL"`
+
 	// File Storage Configuration
 	StorageProvider   string `mapstructure:"STORAGE_PROVIDER"`     // "local" or "s3"
 	StorageBasePath   string `mapstructure:"STORAGE_BASE_PATH"`    // For local storage
 	StorageBaseURL    string `mapstructure:"STORAGE_BASE_URL"`     // For local storage
 	StorageCDNDomain  string `mapstructure:"STORAGE_CDN_DOMAIN"`   // Optional CDN domain
+
+	// Presigned downloads from local storage are served under
+	// STORAGE_DOWNLOAD_URL and signed with STORAGE_SIGNING_SECRET, which is
+	// required for local storage and must differ from JWT_SECRET
+	StorageDownloadURL   string `mapstructure:"STORAGE_DOWNLOAD_URL"`
+	StorageSigningSecret string `mapstructure:"STORAGE_SIGNING_SECRET"`
+
+	// CDN health checks run whenever a CDN domain is set. With
+	// STORAGE_CDN_FALLBACK on, file URLs point straight at storage while
+	// STORAGE_CDN_FAILURE_THRESHOLD checks in a row have failed
+	StorageCDNFallback            bool          `mapstructure:"STORAGE_CDN_FALLBACK"`
+	StorageCDNHealthCheckPath     string        `mapstructure:"STORAGE_CDN_HEALTH_CHECK_PATH"`
+	StorageCDNHealthCheckInterval time.Duration `mapstructure:"STORAGE_CDN_HEALTH_CHECK_INTERVAL"`
+	StorageCDNFailureThreshold    int           `mapstructure:"STORAGE_CDN_FAILURE_THRESHOLD"`
 	
 	// S3 Configuration
 	S3Region          string `mapstructure:"S3_REGION"`
 	S3Bucket          string `mapstructure:"S3_BUCKET"`
 	S3AccessKeyID     string `mapstructure:"S3_ACCESS_KEY_ID"`
 	S3SecretAccessKey string `mapstructure:"S3_SECRET_ACCESS_KEY"`
+
+	// Storage retry/timeout policy (durations accept values like "30s" or "5m")
+	StorageMaxRetries       int           `mapstructure:"STORAGE_MAX_RETRIES"`
+	StorageMaxBackoff       time.Duration `mapstructure:"STORAGE_MAX_BACKOFF"`
+	StorageOperationTimeout time.Duration `mapstructure:"STORAGE_OPERATION_TIMEOUT"`
+	StorageUploadTimeout    time.Duration `mapstructure:"STORAGE_UPLOAD_TIMEOUT"`
 	
+	// Link metadata image fallback order, comma separated
+	// (og_image, twitter_image, largest_image, platform_icon, placeholder)
+	LinkImageFallbackChain []string `mapstructure:"LINK_IMAGE_FALLBACK_CHAIN"`
+
+	// Per-domain scrape strategy overrides, comma separated "domain:strategy"
+	// rules (html, oembed, api, skip), and the default scraper User-Agent
+	LinkScrapeStrategies []string `mapstructure:"LINK_SCRAPE_STRATEGIES"`
+	LinkScraperUserAgent string   `mapstructure:"LINK_SCRAPER_USER_AGENT"`
+
+	// How long a page fetch may take and how many bytes of it are read
+	// when scraping link metadata; zero uses the service defaults
+	LinkFetchTimeout time.Duration `mapstructure:"LINK_FETCH_TIMEOUT"`
+	LinkMaxPageBytes int64         `mapstructure:"LINK_MAX_PAGE_BYTES"`
+
+	// How many links can have their metadata fetched in the background at
+	// once after a content item is saved
+	LinkPrefetchConcurrency int `mapstructure:"LINK_PREFETCH_CONCURRENCY"`
+
+	// Per-type content item limits, comma separated "type:min:max" rules
+	// (e.g. "header:0:1,profile:1:0"); 0 leaves that side unbounded
+	ContentTypeLimits []string `mapstructure:"CONTENT_TYPE_LIMITS"`
+
+	// Reject plain http:// content links; domains listed in
+	// HTTPS_UPGRADE_DOMAINS (comma separated) are upgraded to https instead
+	RequireHTTPSLinks   bool     `mapstructure:"REQUIRE_HTTPS_LINKS"`
+	HTTPSUpgradeDomains []string `mapstructure:"HTTPS_UPGRADE_DOMAINS"`
+
+	// Order of profile items sharing a position: newest_first or oldest_first
+	ContentFallbackOrder string `mapstructure:"CONTENT_FALLBACK_ORDER"`
+
+	// How many content items a user may pin to the top of their profile,
+	// per tier
+	MaxPinnedItemsFree    int `mapstructure:"MAX_PINNED_ITEMS_FREE"`
+	MaxPinnedItemsPremium int `mapstructure:"MAX_PINNED_ITEMS_PREMIUM"`
+
+	// How many active content items a user may have, per tier (0 = no limit)
+	MaxActiveItemsFree    int `mapstructure:"MAX_ACTIVE_ITEMS_FREE"`
+	MaxActiveItemsPremium int `mapstructure:"MAX_ACTIVE_ITEMS_PREMIUM"`
+
+	// Days a deleted content item stays in the trash before it is purged
+	ContentTrashRetentionDays int `mapstructure:"CONTENT_TRASH_RETENTION_DAYS"`
+
+	// Redirect old handles and custom domains to the canonical profile URL
+	CanonicalProfileRedirects bool `mapstructure:"CANONICAL_PROFILE_REDIRECTS"`
+
+	// Analytics retention per tier (days) and how many days before a purge
+	// users are emailed a warning; 0 disables warnings
+	AnalyticsRetentionDaysFree    int `mapstructure:"ANALYTICS_RETENTION_DAYS_FREE"`
+	AnalyticsRetentionDaysPremium int `mapstructure:"ANALYTICS_RETENTION_DAYS_PREMIUM"`
+	AnalyticsPurgeWarningDays     int `mapstructure:"ANALYTICS_PURGE_WARNING_DAYS"`
+
+	// Cap on how long any analytics are kept regardless of tier (days);
+	// 0 keeps them for as long as the tier windows allow
+	AnalyticsRetentionDays int `mapstructure:"ANALYTICS_RETENTION_DAYS"`
+
+	// Days after which analytics lose the visitor's user agent and have their
+	// IP hashed with ANALYTICS_ANONYMIZATION_SALT (or cleared without one);
+	// 0 keeps visitor data until the row is purged
+	AnalyticsAnonymizeAfterDays int    `mapstructure:"ANALYTICS_ANONYMIZE_AFTER_DAYS"`
+	AnalyticsAnonymizationSalt  string `mapstructure:"ANALYTICS_ANONYMIZATION_SALT"`
+
+	// Rows fetched per query while streaming an analytics export, and how
+	// long the emailed link to an asynchronous export stays valid
+	AnalyticsExportBatchSize int           `mapstructure:"ANALYTICS_EXPORT_BATCH_SIZE"`
+	AnalyticsExportLinkTTL   time.Duration `mapstructure:"ANALYTICS_EXPORT_LINK_TTL"`
+
+	// User agent substrings (comma separated, case-insensitive) that flag an
+	// analytics event as bot traffic; empty uses the built-in list
+	AnalyticsBotPatterns []string `mapstructure:"ANALYTICS_BOT_PATTERNS"`
+
+	// How long an IP address counts as one unique click on an item
+	AnalyticsUniqueClickWindow time.Duration `mapstructure:"ANALYTICS_UNIQUE_CLICK_WINDOW"`
+
+	// Most clicks accepted by one batch recording request
+	AnalyticsMaxBatchSize int `mapstructure:"ANALYTICS_MAX_BATCH_SIZE"`
+
+	// Query parameters (comma separated, most authoritative first) that name
+	// an event's referrer when the Referer header was stripped; empty uses
+	// ref,utm_source
+	AnalyticsReferrerParams []string `mapstructure:"ANALYTICS_REFERRER_PARAMS"`
+
+	// CSV of "network,country,city" IPv4 ranges used to locate visitors for
+	// geographic analytics; without one every visitor is reported as Unknown
+	GeoIPDatabasePath string `mapstructure:"GEOIP_DATABASE_PATH"`
+
+	// How often due analytics email reports are sent, which report
+	// frequencies (comma separated) need a premium account, and how many
+	// emails may wait in the background send queue
+	ReportCheckInterval      time.Duration `mapstructure:"REPORT_CHECK_INTERVAL"`
+	ReportPremiumFrequencies []string      `mapstructure:"REPORT_PREMIUM_FREQUENCIES"`
+	EmailQueueSize           int           `mapstructure:"EMAIL_QUEUE_SIZE"`
+
+	// Locale whose templates are used when a user has none set or there is
+	// no translation for theirs (after trying the base language, e.g. es for
+	// es-MX); top level templates are the last resort
+	EmailFallbackLocale string `mapstructure:"EMAIL_FALLBACK_LOCALE"`
+
+	// Background link health checks; 0 disables them. With
+	// LINK_AUTO_DEACTIVATE on, items failing LINK_FAILURE_THRESHOLD checks in
+	// a row are deactivated and their owner is emailed
+	LinkHealthCheckInterval time.Duration `mapstructure:"LINK_HEALTH_CHECK_INTERVAL"`
+	LinkFailureThreshold    int           `mapstructure:"LINK_FAILURE_THRESHOLD"`
+	LinkAutoDeactivate      bool          `mapstructure:"LINK_AUTO_DEACTIVATE"`
+
 	// File Upload Limits
 	MaxFileSize   int64 `mapstructure:"MAX_FILE_SIZE"`     // in bytes
 	MaxAvatarSize int64 `mapstructure:"MAX_AVATAR_SIZE"`   // in bytes
+
+	// How many uploaded avatars a user may keep, per tier
+	MaxAvatarsFree    int `mapstructure:"MAX_AVATARS_FREE"`
+	MaxAvatarsPremium int `mapstructure:"MAX_AVATARS_PREMIUM"`
+
+	// How long a handle released for transfer stays reserved for the target
+	HandleTransferTTL time.Duration `mapstructure:"HANDLE_TRANSFER_TTL"`
+
+	// Handles (comma separated, case-insensitive) nobody may take, on top of
+	// the built-in route names: exact matches, and words no handle may contain
+	ReservedHandles          []string `mapstructure:"RESERVED_HANDLES"`
+	ReservedHandleSubstrings []string `mapstructure:"RESERVED_HANDLE_SUBSTRINGS"`
+
+	// Days a deleted account can be restored before it is purged
+	AccountDeletionGraceDays int `mapstructure:"ACCOUNT_DELETION_GRACE_DAYS"`
+
+	// Maximum lengths, in characters, of free-text user and content fields
+	MaxTitleLength int `mapstructure:"MAX_TITLE_LENGTH"`
+	MaxHrefLength  int `mapstructure:"MAX_HREF_LENGTH"`
+	MaxURLLength   int `mapstructure:"MAX_URL_LENGTH"`
+	MaxBioLength   int `mapstructure:"MAX_BIO_LENGTH"`
+	MaxNameLength  int `mapstructure:"MAX_NAME_LENGTH"`
 }
 
 func LoadConfig(name string, path string) (config Config) {
@@ -67,6 +256,26 @@ func LoadConfig(name string, path string) (config Config) {
 		config.TokenHourLifespan = 24
 	}
 	
+	if config.MaxFailedLoginAttempts <= 0 {
+		config.MaxFailedLoginAttempts = 5
+	}
+
+	if config.LockoutDuration <= 0 {
+		config.LockoutDuration = 15 * time.Minute
+	}
+
+	if config.ShutdownTimeout <= 0 {
+		config.ShutdownTimeout = 30 * time.Second
+	}
+
+	if config.ReadinessTimeout <= 0 {
+		config.ReadinessTimeout = 2 * time.Second
+	}
+
+	if config.CacheL1TTL <= 0 {
+		config.CacheL1TTL = 30 * time.Second
+	}
+	
 	if config.StorageProvider == "" {
 		config.StorageProvider = "local"
 	}
@@ -78,6 +287,21 @@ func LoadConfig(name string, path string) (config Config) {
 	if config.StorageBaseURL == "" {
 		config.StorageBaseURL = "http://localhost:8081/uploads"
 	}
+
+	if config.StorageDownloadURL == "" {
+		config.StorageDownloadURL = "http://localhost:8081/downloads"
+	}
+
+	// Download signatures get their own key, so a leaked one can't be used
+	// to forge auth tokens or the other way round
+	if config.StorageProvider == "local" {
+		if config.StorageSigningSecret == "" {
+			log.Fatalf("config: STORAGE_SIGNING_SECRET must be set for local storage")
+		}
+		if config.StorageSigningSecret == config.JWTSecret {
+			log.Fatalf("config: STORAGE_SIGNING_SECRET must differ from JWT_SECRET")
+		}
+	}
 	
 	if config.MaxFileSize <= 0 {
 		config.MaxFileSize = 50 * 1024 * 1024 // 50MB default
@@ -87,6 +311,70 @@ func LoadConfig(name string, path string) (config Config) {
 		config.MaxAvatarSize = 10 * 1024 * 1024 // 10MB default
 	}
 
+	if config.AnalyticsRetentionDaysFree <= 0 {
+		config.AnalyticsRetentionDaysFree = 90
+	}
+
+	if config.AnalyticsRetentionDaysPremium <= 0 {
+		config.AnalyticsRetentionDaysPremium = 365
+	}
+
+	if config.AnalyticsExportBatchSize <= 0 {
+		config.AnalyticsExportBatchSize = 1000
+	}
+
+	if config.AnalyticsExportLinkTTL <= 0 {
+		config.AnalyticsExportLinkTTL = 24 * time.Hour
+	}
+
+	if config.ReportCheckInterval <= 0 {
+		config.ReportCheckInterval = 15 * time.Minute
+	}
+
+	if config.LinkFailureThreshold <= 0 {
+		config.LinkFailureThreshold = 3
+	}
+
+	if config.MaxAvatarsFree <= 0 {
+		config.MaxAvatarsFree = 3
+	}
+
+	if config.MaxAvatarsPremium <= 0 {
+		config.MaxAvatarsPremium = 10
+	}
+
+	if config.HandleTransferTTL <= 0 {
+		config.HandleTransferTTL = time.Hour
+	}
+
+	if config.AccountDeletionGraceDays <= 0 {
+		config.AccountDeletionGraceDays = 30
+	}
+
+	if config.ContentTrashRetentionDays <= 0 {
+		config.ContentTrashRetentionDays = 30
+	}
+
+	if config.MaxTitleLength <= 0 {
+		config.MaxTitleLength = 200
+	}
+
+	if config.MaxHrefLength <= 0 {
+		config.MaxHrefLength = 2048
+	}
+
+	if config.MaxURLLength <= 0 {
+		config.MaxURLLength = 2048
+	}
+
+	if config.MaxBioLength <= 0 {
+		config.MaxBioLength = 1000
+	}
+
+	if config.MaxNameLength <= 0 {
+		config.MaxNameLength = 100
+	}
+
 	return
 }
 
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_analytics_item_interaction;
+
+ALTER TABLE analytics DROP COLUMN IF EXISTS interaction_type;
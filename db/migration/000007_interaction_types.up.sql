@@ -0,0 +1,4 @@
+-- Interaction types beyond plain clicks (copy, share, submit)
+ALTER TABLE analytics ADD COLUMN interaction_type VARCHAR(20) NOT NULL DEFAULT 'click';
+
+CREATE INDEX idx_analytics_item_interaction ON analytics(item_id, interaction_type) WHERE page_view = false;
@@ -0,0 +1 @@
+ALTER TABLE link_metadata DROP COLUMN IF EXISTS image_source;
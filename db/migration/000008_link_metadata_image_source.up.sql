@@ -0,0 +1,2 @@
+-- Records which step of the image fallback chain produced image_url
+ALTER TABLE link_metadata ADD COLUMN image_source VARCHAR(30);
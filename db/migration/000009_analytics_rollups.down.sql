@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_analytics_clicked_at;
+DROP INDEX IF EXISTS idx_analytics_daily_rollups_day;
+
+DROP TABLE IF EXISTS analytics_daily_rollups;
@@ -0,0 +1,14 @@
+-- Daily analytics aggregates per user and content item
+CREATE TABLE analytics_daily_rollups (
+    user_id UUID NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    item_id UUID NOT NULL REFERENCES content_items(item_id) ON DELETE CASCADE,
+    day DATE NOT NULL,
+    clicks BIGINT NOT NULL DEFAULT 0,
+    page_views BIGINT NOT NULL DEFAULT 0,
+    unique_visitors BIGINT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, item_id, day)
+);
+
+CREATE INDEX idx_analytics_daily_rollups_day ON analytics_daily_rollups(day);
+CREATE INDEX idx_analytics_clicked_at ON analytics(clicked_at);
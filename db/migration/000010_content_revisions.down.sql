@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_content_revisions_item_id;
+DROP INDEX IF EXISTS idx_content_revisions_owner_status;
+DROP TABLE IF EXISTS content_revisions;
+
+DROP INDEX IF EXISTS idx_account_collaborators_collaborator_id;
+DROP TABLE IF EXISTS account_collaborators;
+
+ALTER TABLE users DROP COLUMN IF EXISTS requires_content_approval;
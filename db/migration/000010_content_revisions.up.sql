@@ -0,0 +1,28 @@
+-- Owners can require collaborator edits to be reviewed before going live
+ALTER TABLE users ADD COLUMN requires_content_approval BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Users allowed to edit another account's content
+CREATE TABLE account_collaborators (
+    owner_id UUID NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    collaborator_id UUID NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (owner_id, collaborator_id)
+);
+
+CREATE INDEX idx_account_collaborators_collaborator_id ON account_collaborators(collaborator_id);
+
+-- Proposed content changes awaiting review
+CREATE TABLE content_revisions (
+    revision_id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    item_id UUID NOT NULL REFERENCES content_items(item_id) ON DELETE CASCADE,
+    owner_id UUID NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    author_id UUID NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    changes JSONB NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending', -- 'pending', 'approved', 'rejected'
+    reviewed_by UUID REFERENCES users(user_id) ON DELETE SET NULL,
+    reviewed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_content_revisions_owner_status ON content_revisions(owner_id, status);
+CREATE INDEX idx_content_revisions_item_id ON content_revisions(item_id);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_invite_code_redemptions_user_id;
+DROP TABLE IF EXISTS invite_code_redemptions;
+DROP TABLE IF EXISTS invite_codes;
@@ -0,0 +1,20 @@
+-- Invite codes gate registration when invite-only signup is enabled
+CREATE TABLE invite_codes (
+    code VARCHAR(64) PRIMARY KEY,
+    max_uses INTEGER NOT NULL DEFAULT 1,
+    used_count INTEGER NOT NULL DEFAULT 0,
+    expires_at TIMESTAMP WITH TIME ZONE,
+    created_by UUID REFERENCES users(user_id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CHECK (used_count <= max_uses)
+);
+
+-- Which user registered with which code
+CREATE TABLE invite_code_redemptions (
+    code VARCHAR(64) NOT NULL REFERENCES invite_codes(code) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    redeemed_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (code, user_id)
+);
+
+CREATE INDEX idx_invite_code_redemptions_user_id ON invite_code_redemptions(user_id);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_users_is_template;
+ALTER TABLE users DROP COLUMN IF EXISTS is_template;
@@ -0,0 +1,4 @@
+-- Profiles new users can copy their starting layout from
+ALTER TABLE users ADD COLUMN is_template BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE INDEX idx_users_is_template ON users(is_template) WHERE is_template = TRUE;
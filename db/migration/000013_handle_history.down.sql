@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_users_custom_domain_lower;
+DROP INDEX IF EXISTS idx_handle_history_user_id;
+DROP TABLE IF EXISTS handle_history;
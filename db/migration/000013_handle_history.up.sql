@@ -0,0 +1,9 @@
+-- Handles a user has moved away from, kept so old profile URLs can redirect
+CREATE TABLE handle_history (
+    handle VARCHAR(50) PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    changed_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_handle_history_user_id ON handle_history(user_id);
+CREATE INDEX idx_users_custom_domain_lower ON users(LOWER(custom_domain));
@@ -0,0 +1,2 @@
+-- When the user was last told their old analytics are about to be purged
+ALTER TABLE users ADD COLUMN purge_warned_at TIMESTAMP WITH TIME ZONE;
@@ -0,0 +1,11 @@
+-- Uploaded profile images; the active one is mirrored into users.profile_image_url
+CREATE TABLE user_avatars (
+    avatar_id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    file_key TEXT NOT NULL,
+    url TEXT NOT NULL,
+    is_active BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_user_avatars_user_id ON user_avatars(user_id);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_content_link_health_last_checked_at;
+DROP TABLE IF EXISTS content_link_health;
@@ -0,0 +1,11 @@
+-- Results of the background link health checks, one row per checked item
+CREATE TABLE content_link_health (
+    item_id UUID PRIMARY KEY REFERENCES content_items(item_id) ON DELETE CASCADE,
+    consecutive_failures INT NOT NULL DEFAULT 0,
+    last_status INT,
+    last_checked_at TIMESTAMP WITH TIME ZONE,
+    auto_deactivate BOOLEAN NOT NULL DEFAULT TRUE,
+    deactivated_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX idx_content_link_health_last_checked_at ON content_link_health(last_checked_at);
@@ -0,0 +1,3 @@
+ALTER TABLE auth DROP COLUMN IF EXISTS totp_recovery_codes;
+ALTER TABLE auth DROP COLUMN IF EXISTS totp_enabled;
+ALTER TABLE auth DROP COLUMN IF EXISTS totp_secret;
@@ -0,0 +1,4 @@
+-- TOTP two-factor authentication; recovery codes are stored as SHA-256 hashes
+ALTER TABLE auth ADD COLUMN totp_secret TEXT;
+ALTER TABLE auth ADD COLUMN totp_enabled BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE auth ADD COLUMN totp_recovery_codes TEXT[] NOT NULL DEFAULT '{}';
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_audit_log_target_id;
+DROP INDEX IF EXISTS idx_audit_log_actor_id;
+DROP TABLE IF EXISTS audit_log;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_handle_transfers_to_user_id;
+DROP TABLE IF EXISTS handle_transfers;
@@ -0,0 +1,10 @@
+-- Handles released by one account and held for another to claim
+CREATE TABLE handle_transfers (
+    handle VARCHAR(50) PRIMARY KEY,
+    from_user_id UUID NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    to_user_id UUID NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_handle_transfers_to_user_id ON handle_transfers(to_user_id);
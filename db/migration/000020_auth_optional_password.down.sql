@@ -0,0 +1,6 @@
+-- OAuth-only accounts cannot be represented without a password
+DELETE FROM auth WHERE password_hash IS NULL OR salt IS NULL;
+
+ALTER TABLE auth
+    ALTER COLUMN password_hash SET NOT NULL,
+    ALTER COLUMN salt SET NOT NULL;
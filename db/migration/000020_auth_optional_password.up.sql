@@ -0,0 +1,4 @@
+-- Accounts created through OAuth sign-in have no password
+ALTER TABLE auth
+    ALTER COLUMN password_hash DROP NOT NULL,
+    ALTER COLUMN salt DROP NOT NULL;
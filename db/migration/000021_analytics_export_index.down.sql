@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_analytics_user_clicked_at;
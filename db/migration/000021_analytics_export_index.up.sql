@@ -0,0 +1,2 @@
+-- Supports keyset pagination over a user's raw analytics for exports
+CREATE INDEX idx_analytics_user_clicked_at ON analytics(user_id, clicked_at, analytics_id);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_content_history_item_created;
+DROP TABLE IF EXISTS content_history;
@@ -0,0 +1,13 @@
+-- Applied changes to content items, for reviewing how an item evolved.
+-- actor_id is NULL for changes made by the system rather than a user.
+CREATE TABLE content_history (
+    history_id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    item_id UUID NOT NULL REFERENCES content_items(item_id) ON DELETE CASCADE,
+    actor_id UUID REFERENCES users(user_id) ON DELETE SET NULL,
+    source VARCHAR(20) NOT NULL DEFAULT 'user', -- 'user', 'system'
+    action VARCHAR(50) NOT NULL,
+    changes JSONB,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_content_history_item_created ON content_history(item_id, created_at DESC);
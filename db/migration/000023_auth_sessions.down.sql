@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_auth_sessions_user_id;
+DROP TABLE IF EXISTS auth_sessions;
@@ -0,0 +1,6 @@
+ALTER TABLE conversions DROP CONSTRAINT IF EXISTS conversions_analytics_id_fkey;
+ALTER TABLE conversions ADD CONSTRAINT conversions_analytics_id_fkey
+    FOREIGN KEY (analytics_id) REFERENCES analytics(analytics_id);
+
+DROP INDEX IF EXISTS idx_analytics_pending_anonymization;
+ALTER TABLE analytics DROP COLUMN IF EXISTS anonymized_at;
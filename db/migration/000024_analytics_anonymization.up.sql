@@ -0,0 +1,9 @@
+-- When a row's visitor IP and user agent were stripped by the retention worker
+ALTER TABLE analytics ADD COLUMN anonymized_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX idx_analytics_pending_anonymization ON analytics(clicked_at) WHERE anonymized_at IS NULL;
+
+-- Purging raw analytics must not be blocked by conversions recorded against them
+ALTER TABLE conversions DROP CONSTRAINT IF EXISTS conversions_analytics_id_fkey;
+ALTER TABLE conversions ADD CONSTRAINT conversions_analytics_id_fkey
+    FOREIGN KEY (analytics_id) REFERENCES analytics(analytics_id) ON DELETE SET NULL;
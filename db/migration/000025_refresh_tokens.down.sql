@@ -0,0 +1,3 @@
+ALTER TABLE auth ADD COLUMN IF NOT EXISTS refresh_token VARCHAR(500);
+
+DROP TABLE IF EXISTS refresh_tokens;
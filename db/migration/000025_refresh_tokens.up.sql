@@ -0,0 +1,16 @@
+-- Every refresh token issued, keyed by its jti. A session's tokens form one
+-- family: each refresh consumes the presented token and issues the next, so
+-- a consumed token coming back means it was stolen and the family is revoked.
+CREATE TABLE refresh_tokens (
+    token_id UUID PRIMARY KEY,
+    session_id UUID NOT NULL REFERENCES auth_sessions(session_id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    issued_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    consumed_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX idx_refresh_tokens_session_id ON refresh_tokens(session_id);
+
+-- Refresh tokens are no longer stored on the auth record
+ALTER TABLE auth DROP COLUMN IF EXISTS refresh_token;
@@ -0,0 +1,2 @@
+-- Profiles whose owner turned off visitor tracking record no analytics
+ALTER TABLE users ADD COLUMN analytics_enabled BOOLEAN NOT NULL DEFAULT TRUE;
@@ -0,0 +1,16 @@
+-- Scheduled analytics email reports. A "once" subscription is removed after
+-- its report is sent; the others move next_send_at forward each time.
+CREATE TABLE report_subscriptions (
+    subscription_id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    frequency VARCHAR(20) NOT NULL,
+    metrics TEXT[] NOT NULL DEFAULT '{}',
+    unsubscribe_token VARCHAR(64) NOT NULL UNIQUE,
+    next_send_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    last_sent_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX idx_report_subscriptions_user_frequency
+    ON report_subscriptions(user_id, frequency) WHERE frequency <> 'once';
+CREATE INDEX idx_report_subscriptions_next_send_at ON report_subscriptions(next_send_at);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_content_items_user_pinned;
+ALTER TABLE content_items DROP COLUMN IF EXISTS pin_order;
+ALTER TABLE content_items DROP COLUMN IF EXISTS pinned;
@@ -0,0 +1,5 @@
+-- Pinned items render before the rest of a profile, in pin_order
+ALTER TABLE content_items ADD COLUMN pinned BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE content_items ADD COLUMN pin_order INTEGER NOT NULL DEFAULT 0;
+
+CREATE INDEX idx_content_items_user_pinned ON content_items(user_id) WHERE pinned;
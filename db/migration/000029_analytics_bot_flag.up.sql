@@ -0,0 +1,3 @@
+-- Events from user agents matching a known bot pattern are kept but flagged,
+-- so dashboards can leave them out unless asked to include them
+ALTER TABLE analytics ADD COLUMN is_bot BOOLEAN NOT NULL DEFAULT FALSE;
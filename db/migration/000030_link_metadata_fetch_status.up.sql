@@ -0,0 +1,3 @@
+-- Set when the last attempt to fetch a URL's page failed, so clients can show
+-- an error state instead of waiting for metadata that is not coming
+ALTER TABLE link_metadata ADD COLUMN fetch_failed BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,3 @@
+-- Preferred language for emails as a BCP 47 tag such as "es" or "es-MX";
+-- NULL uses the configured fallback locale
+ALTER TABLE users ADD COLUMN locale VARCHAR(35);
@@ -0,0 +1,29 @@
+-- Platforms link metadata recognises, editable by admins without a deploy.
+-- Empty strings mean "not set" for the optional columns, matching how the
+-- service treats them.
+CREATE TABLE platforms (
+    domain VARCHAR(255) PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    type VARCHAR(50) NOT NULL,
+    color VARCHAR(7) NOT NULL,
+    icon TEXT NOT NULL DEFAULT '',
+    url_template TEXT NOT NULL DEFAULT '',
+    scrape_strategy VARCHAR(20) NOT NULL DEFAULT '',
+    oembed_endpoint TEXT NOT NULL DEFAULT '',
+    user_agent TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- The registry previously hard-coded in the link metadata service
+INSERT INTO platforms (domain, name, type, color, icon, url_template, scrape_strategy, oembed_endpoint, user_agent) VALUES
+    ('instagram.com', 'Instagram', 'social', '#E1306C', '/assets/icons/instagram.svg', 'https://instagram.com/{username}', 'api', '', ''),
+    ('twitter.com', 'Twitter', 'social', '#1DA1F2', '/assets/icons/twitter.svg', 'https://twitter.com/{username}', 'oembed', 'https://publish.twitter.com/oembed', ''),
+    ('x.com', 'X', 'social', '#000000', '/assets/icons/x.svg', 'https://x.com/{username}', 'oembed', 'https://publish.twitter.com/oembed', ''),
+    ('github.com', 'GitHub', 'dev', '#333333', '/assets/icons/github.svg', 'https://github.com/{username}', '', '', ''),
+    ('linkedin.com', 'LinkedIn', 'professional', '#0A66C2', '/assets/icons/linkedin.svg', 'https://linkedin.com/in/{username}', 'skip', '', ''),
+    ('youtube.com', 'YouTube', 'video', '#FF0000', '/assets/icons/youtube.svg', 'https://youtube.com/{channel}', 'oembed', 'https://www.youtube.com/oembed', ''),
+    ('tiktok.com', 'TikTok', 'video', '#000000', '/assets/icons/tiktok.svg', 'https://tiktok.com/@{username}', 'oembed', 'https://www.tiktok.com/oembed', ''),
+    ('facebook.com', 'Facebook', 'social', '#1877F2', '/assets/icons/facebook.svg', 'https://facebook.com/{username}', 'api', '', ''),
+    ('spotify.com', 'Spotify', 'music', '#1DB954', '/assets/icons/spotify.svg', 'https://open.spotify.com/user/{username}', 'oembed', 'https://open.spotify.com/oembed', ''),
+    ('twitch.tv', 'Twitch', 'streaming', '#9146FF', '/assets/icons/twitch.svg', 'https://twitch.tv/{username}', '', '', 'facebookexternalhit/1.1');
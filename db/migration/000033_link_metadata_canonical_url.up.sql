@@ -0,0 +1,3 @@
+-- The URL a fetch ended up at after following redirects, e.g. the full
+-- address behind a shortened link
+ALTER TABLE link_metadata ADD COLUMN canonical_url TEXT;
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_analytics_recent_clicks;
@@ -0,0 +1,5 @@
+-- Serves the recent clicks feed newest first straight from the index: a
+-- user's human clicks ordered by time, carrying the columns the feed shows
+CREATE INDEX idx_analytics_recent_clicks ON analytics(user_id, clicked_at DESC)
+    INCLUDE (item_id, referrer, ip_address, interaction_type)
+    WHERE page_view = false AND is_bot = false;
@@ -0,0 +1 @@
+ALTER TABLE platforms DROP COLUMN IF EXISTS query_keys;
@@ -0,0 +1,7 @@
+-- Query parameters that identify what a platform's links point to, e.g. the
+-- video in youtube.com/watch?v=. Link metadata is stored under URLs keeping
+-- only these; platforms without any keep every non-tracking parameter.
+ALTER TABLE platforms ADD COLUMN query_keys TEXT[] NOT NULL DEFAULT '{}';
+
+UPDATE platforms SET query_keys = '{v,list}' WHERE domain = 'youtube.com';
+UPDATE platforms SET query_keys = '{si}' WHERE domain = 'spotify.com';
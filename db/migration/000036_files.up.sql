@@ -0,0 +1,12 @@
+-- Uploaded files and who owns them, so deletes and private file URLs can be
+-- authorized
+CREATE TABLE files (
+    file_key TEXT PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    category TEXT NOT NULL,
+    size BIGINT NOT NULL,
+    content_type TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_files_user_id ON files(user_id);
@@ -0,0 +1,3 @@
+ALTER TABLE users
+    DROP COLUMN IF EXISTS custom_domain_verified_at,
+    DROP COLUMN IF EXISTS custom_domain_token;
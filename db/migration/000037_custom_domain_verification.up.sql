@@ -0,0 +1,6 @@
+-- Proof that a user controls their custom domain: the token they publish in
+-- a TXT record, and when it was found there. Profiles are only served on
+-- verified domains.
+ALTER TABLE users
+    ADD COLUMN custom_domain_token VARCHAR(64),
+    ADD COLUMN custom_domain_verified_at TIMESTAMP WITH TIME ZONE;
@@ -0,0 +1,4 @@
+-- Who can see a profile: everyone (public), anyone with the link but kept out
+-- of listings (unlisted), or only its owner (private)
+ALTER TABLE users ADD COLUMN visibility VARCHAR(10) NOT NULL DEFAULT 'public'
+    CHECK (visibility IN ('public', 'unlisted', 'private'));
@@ -0,0 +1,6 @@
+-- When the user asked for their account to be deleted. The row is kept, and
+-- hidden from every lookup, until the grace period runs out and the purge
+-- job removes it.
+ALTER TABLE users ADD COLUMN deleted_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX idx_users_deleted_at ON users (deleted_at) WHERE deleted_at IS NOT NULL;
@@ -0,0 +1,2 @@
+ALTER TABLE content_items DROP COLUMN IF EXISTS unpublish_at;
+ALTER TABLE content_items DROP COLUMN IF EXISTS publish_at;
@@ -0,0 +1,4 @@
+-- Optional publish window. An item is only shown publicly from publish_at
+-- until unpublish_at; either end may be left open.
+ALTER TABLE content_items ADD COLUMN publish_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE content_items ADD COLUMN unpublish_at TIMESTAMP WITH TIME ZONE;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_content_items_deleted_at;
+ALTER TABLE content_items DROP COLUMN IF EXISTS deleted_at;
@@ -0,0 +1,6 @@
+-- When the item was moved to the trash. It's hidden everywhere but the
+-- owner's trash until the purge job removes it. content_id has no unique
+-- constraint, so a trashed item never blocks recreating one like it.
+ALTER TABLE content_items ADD COLUMN deleted_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX idx_content_items_deleted_at ON content_items (deleted_at) WHERE deleted_at IS NOT NULL;
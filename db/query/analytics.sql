@@ -3,16 +3,40 @@
 -- Recording clicks and page views
 -- name: CreateAnalyticsEntry :one
 INSERT INTO analytics (
-    item_id, user_id, ip_address, user_agent, referrer, page_view
+    item_id, user_id, ip_address, user_agent, referrer, interaction_type, page_view, is_bot,
+    utm_source, utm_medium, utm_campaign
 ) VALUES (
-    $1, $2, $3, $4, $5, false
+    $1, $2, $3, $4, $5, $6, false, $7, $8, $9, $10
 ) RETURNING *;
 
+-- name: CreateAnalyticsEntries :execrows
+INSERT INTO analytics (
+    item_id, user_id, ip_address, user_agent, referrer, interaction_type, page_view, is_bot,
+    utm_source, utm_medium, utm_campaign
+)
+SELECT
+    e.item_id, e.user_id, NULLIF(e.ip_address, ''), NULLIF(e.user_agent, ''), NULLIF(e.referrer, ''),
+    e.interaction_type, false, e.is_bot,
+    NULLIF(e.utm_source, ''), NULLIF(e.utm_medium, ''), NULLIF(e.utm_campaign, '')
+FROM unnest(
+    sqlc.arg(item_ids)::uuid[],
+    sqlc.arg(user_ids)::uuid[],
+    sqlc.arg(ip_addresses)::text[],
+    sqlc.arg(user_agents)::text[],
+    sqlc.arg(referrers)::text[],
+    sqlc.arg(interaction_types)::text[],
+    sqlc.arg(is_bots)::boolean[],
+    sqlc.arg(utm_sources)::text[],
+    sqlc.arg(utm_mediums)::text[],
+    sqlc.arg(utm_campaigns)::text[]
+) AS e(item_id, user_id, ip_address, user_agent, referrer, interaction_type, is_bot, utm_source, utm_medium, utm_campaign);
+
 -- name: CreatePageViewEntry :one
 INSERT INTO analytics (
-    item_id, user_id, ip_address, user_agent, referrer, page_view
+    item_id, user_id, ip_address, user_agent, referrer, page_view, is_bot,
+    utm_source, utm_medium, utm_campaign
 ) VALUES (
-    $1, $2, $3, $4, $5, true
+    $1, $2, $3, $4, $5, true, $6, $7, $8, $9
 ) RETURNING *;
 
 -- Basic analytics queries
@@ -31,26 +55,62 @@ LIMIT $2 OFFSET $3;
 -- Count queries
 -- name: GetContentItemClickCount :one
 SELECT COUNT(*) FROM analytics
-WHERE item_id = $1 AND page_view = false;
+WHERE item_id = $1 AND page_view = false AND interaction_type = 'click';
+
+-- name: GetContentItemUniqueClickCount :one
+SELECT COUNT(DISTINCT (ip_address, FLOOR(EXTRACT(EPOCH FROM clicked_at) / sqlc.arg(window_seconds)::float8)))
+FROM analytics
+WHERE item_id = $1
+AND clicked_at >= $2
+AND ip_address IS NOT NULL
+AND page_view = false
+AND interaction_type = 'click'
+AND is_bot = false;
 
 -- name: GetUserItemClickCount :one
 SELECT COUNT(*) FROM analytics
-WHERE user_id = $1 AND page_view = false;
+WHERE user_id = $1 AND page_view = false AND interaction_type = 'click'
+AND (is_bot = false OR is_bot = $2);
+
+-- name: GetItemInteractionBreakdown :many
+SELECT
+    interaction_type,
+    COUNT(*) AS count
+FROM analytics
+WHERE item_id = $1 AND page_view = false
+GROUP BY interaction_type
+ORDER BY count DESC;
 
 -- name: GetProfilePageViews :one
 SELECT COUNT(*) FROM analytics
-WHERE user_id = $1 AND page_view = true;
+WHERE user_id = $1 AND page_view = true
+AND (is_bot = false OR is_bot = $2);
 
 -- Time range analytics
 -- name: GetUserAnalyticsByTimeRange :many
-SELECT 
+SELECT
+    DATE_TRUNC(sqlc.arg(unit)::text, clicked_at)::timestamptz AS bucket,
+    COUNT(*) AS clicks
+FROM analytics
+WHERE user_id = sqlc.arg(user_id)
+AND clicked_at >= sqlc.arg(start_date)
+AND clicked_at <= sqlc.arg(end_date)
+AND (is_bot = false OR is_bot = sqlc.arg(include_bots))
+AND page_view = false
+AND interaction_type = 'click'
+GROUP BY bucket
+ORDER BY bucket;
+
+-- name: GetUserClicksForDays :many
+SELECT
     DATE_TRUNC('day', clicked_at) AS day,
     COUNT(*) AS clicks
 FROM analytics
 WHERE user_id = $1
-AND clicked_at >= $2
-AND clicked_at <= $3
+AND DATE_TRUNC('day', clicked_at) = ANY(sqlc.arg(days)::timestamptz[])
 AND page_view = false
+AND interaction_type = 'click'
+AND is_bot = false
 GROUP BY DATE_TRUNC('day', clicked_at)
 ORDER BY day;
 
@@ -62,7 +122,9 @@ FROM analytics
 WHERE item_id = $1
 AND clicked_at >= $2
 AND clicked_at <= $3
+AND (is_bot = false OR is_bot = $4)
 AND page_view = false
+AND interaction_type = 'click'
 GROUP BY DATE_TRUNC('day', clicked_at)
 ORDER BY day;
 
@@ -74,6 +136,7 @@ FROM analytics
 WHERE user_id = $1
 AND clicked_at >= $2
 AND clicked_at <= $3
+AND (is_bot = false OR is_bot = $4)
 AND page_view = true
 GROUP BY DATE_TRUNC('day', clicked_at)
 ORDER BY day;
@@ -84,13 +147,17 @@ SELECT
     a.item_id,
     c.content_type,
     COALESCE(c.title, '') AS title,
-    COUNT(*) AS click_count
+    COUNT(*) AS click_count,
+    COUNT(DISTINCT (a.ip_address, FLOOR(EXTRACT(EPOCH FROM a.clicked_at) / sqlc.arg(window_seconds)::float8)))
+        FILTER (WHERE a.ip_address IS NOT NULL) AS unique_click_count
 FROM analytics a
 JOIN content_items c ON a.item_id = c.item_id
 WHERE c.user_id = $1
 AND a.clicked_at >= $2
 AND a.clicked_at <= $3
+AND (a.is_bot = false OR a.is_bot = $5)
 AND a.page_view = false
+AND a.interaction_type = 'click'
 GROUP BY a.item_id, c.content_type, c.title
 ORDER BY click_count DESC
 LIMIT $4;
@@ -103,11 +170,28 @@ FROM analytics
 WHERE user_id = $1
 AND clicked_at >= $2
 AND clicked_at <= $3
+AND (is_bot = false OR is_bot = $5)
 AND referrer IS NOT NULL
 GROUP BY referrer
 ORDER BY count DESC
 LIMIT $4;
 
+-- Campaign analytics
+-- name: GetCampaignCounts :many
+SELECT
+    COALESCE(utm_source, '') AS utm_source,
+    COALESCE(utm_medium, '') AS utm_medium,
+    COALESCE(utm_campaign, '') AS utm_campaign,
+    COUNT(*) FILTER (WHERE page_view = false AND interaction_type = 'click') AS clicks,
+    COUNT(*) FILTER (WHERE page_view = true) AS page_views
+FROM analytics
+WHERE user_id = $1
+AND clicked_at >= $2
+AND clicked_at <= $3
+AND (is_bot = false OR is_bot = $4)
+GROUP BY utm_source, utm_medium, utm_campaign
+ORDER BY COUNT(*) DESC;
+
 -- Visitor analytics
 -- name: GetUniqueVisitors :one
 SELECT COUNT(DISTINCT ip_address) 
@@ -115,6 +199,7 @@ FROM analytics
 WHERE user_id = $1
 AND clicked_at >= $2
 AND clicked_at <= $3
+AND (is_bot = false OR is_bot = $4)
 AND ip_address IS NOT NULL
 AND page_view = true;
 
@@ -126,7 +211,71 @@ FROM analytics
 WHERE user_id = $1
 AND clicked_at >= $2
 AND clicked_at <= $3
+AND (is_bot = false OR is_bot = $4)
 AND ip_address IS NOT NULL
 AND page_view = true
 GROUP BY DATE_TRUNC('day', clicked_at)
-ORDER BY day;
\ No newline at end of file
+ORDER BY day;
+
+-- Device analytics
+-- name: GetUserAgentCounts :many
+SELECT
+    COALESCE(user_agent, '') AS user_agent,
+    COUNT(*) AS count
+FROM analytics
+WHERE user_id = $1
+AND clicked_at >= $2
+AND clicked_at <= $3
+AND (is_bot = false OR is_bot = $4)
+GROUP BY user_agent;
+
+-- Geographic analytics
+-- name: GetIPAddressCounts :many
+SELECT
+    COALESCE(ip_address, '') AS ip_address,
+    COUNT(*) AS count
+FROM analytics
+WHERE user_id = $1
+AND clicked_at >= $2
+AND clicked_at <= $3
+AND (is_bot = false OR is_bot = $4)
+GROUP BY ip_address;
+
+-- name: GetUserPeriodTotals :one
+SELECT
+    COUNT(*) FILTER (WHERE page_view = true) AS views,
+    COUNT(*) FILTER (WHERE page_view = false AND interaction_type = 'click') AS clicks,
+    COUNT(DISTINCT ip_address) FILTER (WHERE page_view = true AND ip_address IS NOT NULL) AS unique_visitors
+FROM analytics
+WHERE user_id = $1
+AND clicked_at >= $2
+AND clicked_at < $3
+AND (is_bot = false OR is_bot = $4);
+
+-- Recent activity
+-- name: ListRecentClicks :many
+SELECT
+    a.item_id,
+    COALESCE(c.title, '') AS title,
+    COALESCE(a.referrer, '') AS referrer,
+    COALESCE(a.ip_address, '') AS ip_address,
+    a.interaction_type,
+    a.clicked_at
+FROM analytics a
+JOIN content_items c ON a.item_id = c.item_id
+WHERE a.user_id = $1
+AND a.page_view = false
+AND a.is_bot = false
+ORDER BY a.clicked_at DESC
+LIMIT $2;
+
+-- Export
+-- name: ListUserAnalyticsForExport :many
+SELECT sqlc.embed(analytics), content_items.content_type
+FROM analytics
+LEFT JOIN content_items ON content_items.item_id = analytics.item_id
+WHERE analytics.user_id = sqlc.arg('user_id')
+AND analytics.clicked_at <= sqlc.arg('end_at')
+AND (analytics.clicked_at, analytics.analytics_id) > (sqlc.arg('after_clicked_at')::timestamptz, sqlc.arg('after_id')::uuid)
+ORDER BY analytics.clicked_at, analytics.analytics_id
+LIMIT sqlc.arg('row_limit');
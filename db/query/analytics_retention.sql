@@ -0,0 +1,64 @@
+-- name: ListUsersDueForPurgeWarning :many
+SELECT
+    u.user_id,
+    u.username,
+    u.email,
+    u.locale,
+    COALESCE(u.is_premium, FALSE)::boolean AS is_premium,
+    MIN(a.clicked_at)::timestamptz AS oldest_event
+FROM users u
+JOIN analytics a ON a.user_id = u.user_id
+WHERE a.clicked_at < CASE
+    WHEN COALESCE(u.is_premium, FALSE) THEN sqlc.arg(premium_warn_before)::timestamptz
+    ELSE sqlc.arg(free_warn_before)::timestamptz
+END
+AND (u.purge_warned_at IS NULL OR u.purge_warned_at < CASE
+    WHEN COALESCE(u.is_premium, FALSE) THEN sqlc.arg(premium_rewarn_before)::timestamptz
+    ELSE sqlc.arg(free_rewarn_before)::timestamptz
+END)
+AND u.deleted_at IS NULL
+GROUP BY u.user_id, u.username, u.email, u.locale, u.is_premium
+ORDER BY u.user_id
+LIMIT sqlc.arg(max_users);
+
+-- name: MarkPurgeWarned :exec
+UPDATE users
+SET purge_warned_at = CURRENT_TIMESTAMP
+WHERE user_id = $1;
+
+-- name: AnonymizeAnalytics :execrows
+UPDATE analytics
+SET
+    ip_address = CASE
+        WHEN ip_address IS NULL OR sqlc.arg(salt)::text = '' THEN NULL
+        ELSE md5(sqlc.arg(salt)::text || ip_address)
+    END,
+    user_agent = NULL,
+    anonymized_at = CURRENT_TIMESTAMP
+WHERE analytics_id IN (
+    SELECT analytics_id FROM analytics
+    WHERE clicked_at < sqlc.arg(anonymize_before)::timestamptz
+    AND anonymized_at IS NULL
+    LIMIT sqlc.arg(batch_size)
+);
+
+-- name: DeleteAnalyticsOlderThan :execrows
+DELETE FROM analytics
+WHERE analytics_id IN (
+    SELECT analytics_id FROM analytics
+    WHERE clicked_at < sqlc.arg(cutoff)::timestamptz
+    LIMIT sqlc.arg(batch_size)
+);
+
+-- name: DeleteExpiredAnalytics :execrows
+DELETE FROM analytics
+WHERE analytics_id IN (
+    SELECT a.analytics_id
+    FROM analytics a
+    JOIN users u ON u.user_id = a.user_id
+    WHERE a.clicked_at < CASE
+        WHEN COALESCE(u.is_premium, FALSE) THEN sqlc.arg(premium_before)::timestamptz
+        ELSE sqlc.arg(free_before)::timestamptz
+    END
+    LIMIT sqlc.arg(batch_size)
+);
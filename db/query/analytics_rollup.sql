@@ -0,0 +1,86 @@
+-- name: DeleteAnalyticsRollups :exec
+DELETE FROM analytics_daily_rollups
+WHERE day >= sqlc.arg('start_day')
+AND day < sqlc.arg('end_day')
+AND (sqlc.narg('user_id')::uuid IS NULL OR user_id = sqlc.narg('user_id'));
+
+-- name: RebuildAnalyticsRollups :execrows
+INSERT INTO analytics_daily_rollups (
+    user_id, item_id, day, clicks, page_views, unique_visitors
+)
+SELECT
+    user_id,
+    item_id,
+    clicked_at::date AS day,
+    COUNT(*) FILTER (WHERE page_view = false AND interaction_type = 'click') AS clicks,
+    COUNT(*) FILTER (WHERE page_view = true) AS page_views,
+    COUNT(DISTINCT ip_address) AS unique_visitors
+FROM analytics
+WHERE clicked_at >= sqlc.arg('start_at')
+AND clicked_at < sqlc.arg('end_at')
+AND (sqlc.narg('user_id')::uuid IS NULL OR user_id = sqlc.narg('user_id'))
+AND is_bot = false
+GROUP BY user_id, item_id, clicked_at::date
+ON CONFLICT (user_id, item_id, day) DO UPDATE
+SET
+    clicks = EXCLUDED.clicks,
+    page_views = EXCLUDED.page_views,
+    unique_visitors = EXCLUDED.unique_visitors,
+    updated_at = CURRENT_TIMESTAMP;
+
+-- name: GetUserPeriodTotalsFromRollups :one
+-- Views and clicks for whole days in [rollup_start, rollup_end) come from the
+-- rollups and the rest of the window from raw rows. Unique visitors can't be
+-- summed across days, so they are always counted from raw rows.
+WITH raw AS (
+    SELECT page_view, interaction_type, ip_address, clicked_at
+    FROM analytics
+    WHERE user_id = sqlc.arg('user_id')
+    AND clicked_at >= sqlc.arg('start_at')::timestamptz
+    AND clicked_at < sqlc.arg('end_at')::timestamptz
+    AND is_bot = false
+), rolled AS (
+    SELECT
+        COALESCE(SUM(page_views), 0)::bigint AS views,
+        COALESCE(SUM(clicks), 0)::bigint AS clicks
+    FROM analytics_daily_rollups
+    WHERE user_id = sqlc.arg('user_id')
+    AND day >= sqlc.arg('rollup_start')::date
+    AND day < sqlc.arg('rollup_end')::date
+)
+SELECT
+    (rolled.views + COUNT(raw.clicked_at) FILTER (
+        WHERE raw.page_view = true
+        AND (raw.clicked_at < sqlc.arg('rollup_start')::date OR raw.clicked_at >= sqlc.arg('rollup_end')::date)
+    ))::bigint AS views,
+    (rolled.clicks + COUNT(raw.clicked_at) FILTER (
+        WHERE raw.page_view = false AND raw.interaction_type = 'click'
+        AND (raw.clicked_at < sqlc.arg('rollup_start')::date OR raw.clicked_at >= sqlc.arg('rollup_end')::date)
+    ))::bigint AS clicks,
+    COUNT(DISTINCT raw.ip_address) FILTER (WHERE raw.page_view = true AND raw.ip_address IS NOT NULL) AS unique_visitors
+FROM rolled
+LEFT JOIN raw ON true
+GROUP BY rolled.views, rolled.clicks;
+
+-- name: GetProfilePageViewsByDateFromRollups :many
+SELECT day, views
+FROM (
+    SELECT day::timestamptz AS day, SUM(page_views)::bigint AS views
+    FROM analytics_daily_rollups
+    WHERE user_id = sqlc.arg('user_id')
+    AND day >= sqlc.arg('rollup_start')::date
+    AND day < sqlc.arg('rollup_end')::date
+    GROUP BY day
+    HAVING SUM(page_views) > 0
+    UNION ALL
+    SELECT DATE_TRUNC('day', clicked_at) AS day, COUNT(*) AS views
+    FROM analytics
+    WHERE user_id = sqlc.arg('user_id')
+    AND clicked_at >= sqlc.arg('start_at')::timestamptz
+    AND clicked_at <= sqlc.arg('end_at')::timestamptz
+    AND (clicked_at < sqlc.arg('rollup_start')::date OR clicked_at >= sqlc.arg('rollup_end')::date)
+    AND is_bot = false
+    AND page_view = true
+    GROUP BY DATE_TRUNC('day', clicked_at)
+) days
+ORDER BY day;
@@ -49,40 +49,100 @@ SET
     updated_at = CURRENT_TIMESTAMP
 WHERE user_id = $1;
 
--- name: IncrementFailedLoginAttempts :exec
+-- name: IncrementFailedLoginAttempts :one
 UPDATE auth
 SET
-    failed_login_attempts = failed_login_attempts + 1,
+    failed_login_attempts = COALESCE(failed_login_attempts, 0) + 1,
     updated_at = CURRENT_TIMESTAMP
-WHERE user_id = $1;
+WHERE user_id = $1
+RETURNING failed_login_attempts;
 
 -- name: SetAccountLockout :exec
 UPDATE auth
 SET
     locked_until = $2,
+    failed_login_attempts = 0,
     updated_at = CURRENT_TIMESTAMP
 WHERE user_id = $1;
 
 -- name: StoreRefreshToken :exec
+INSERT INTO refresh_tokens (
+    token_id, session_id, user_id, expires_at
+) VALUES (
+    $1, $2, $3, $4
+);
+
+-- name: ConsumeRefreshToken :execrows
+UPDATE refresh_tokens
+SET consumed_at = CURRENT_TIMESTAMP
+WHERE token_id = $1
+  AND consumed_at IS NULL
+  AND expires_at > CURRENT_TIMESTAMP;
+
+-- name: GetRefreshToken :one
+SELECT * FROM refresh_tokens
+WHERE token_id = $1 LIMIT 1;
+
+-- name: InvalidateRefreshTokenFamily :exec
+UPDATE refresh_tokens
+SET consumed_at = COALESCE(consumed_at, CURRENT_TIMESTAMP)
+WHERE session_id = $1;
+
+-- name: InvalidateUserRefreshTokens :exec
+UPDATE refresh_tokens
+SET consumed_at = COALESCE(consumed_at, CURRENT_TIMESTAMP)
+WHERE user_id = $1;
+
+-- name: GetAuthByVerificationToken :one
+SELECT * FROM auth
+WHERE verification_token = $1
+LIMIT 1;
+
+-- name: ClearVerificationToken :exec
+UPDATE auth
+SET verification_token = NULL
+WHERE user_id = $1;
+
+-- name: GetVerificationStatuses :many
+SELECT user_id, is_email_verified FROM auth
+WHERE user_id = ANY(sqlc.arg('user_ids')::uuid[]);
+
+-- name: ListUnverifiedUsersCreatedBefore :many
+SELECT u.user_id, u.username, u.email, u.locale, u.created_at
+FROM users u
+JOIN auth a ON a.user_id = u.user_id
+WHERE COALESCE(a.is_email_verified, false) = false
+AND u.created_at < $1
+AND u.deleted_at IS NULL
+ORDER BY u.created_at ASC
+LIMIT $2;
+
+-- name: SetVerificationToken :exec
 UPDATE auth
 SET
-    refresh_token = $2,
+    verification_token = $2,
     updated_at = CURRENT_TIMESTAMP
 WHERE user_id = $1;
 
--- name: InvalidateRefreshToken :exec
+-- name: SetTOTPSecret :exec
 UPDATE auth
 SET
-    refresh_token = NULL,
+    totp_secret = $2,
+    totp_recovery_codes = $3,
+    totp_enabled = FALSE,
     updated_at = CURRENT_TIMESTAMP
 WHERE user_id = $1;
 
--- name: GetAuthByVerificationToken :one
-SELECT * FROM auth
-WHERE verification_token = $1
-LIMIT 1;
+-- name: EnableTOTP :exec
+UPDATE auth
+SET
+    totp_enabled = TRUE,
+    updated_at = CURRENT_TIMESTAMP
+WHERE user_id = $1 AND totp_secret IS NOT NULL;
 
--- name: ClearVerificationToken :exec
+-- name: ConsumeRecoveryCode :execrows
 UPDATE auth
-SET verification_token = NULL
-WHERE user_id = $1;
\ No newline at end of file
+SET
+    totp_recovery_codes = array_remove(totp_recovery_codes, sqlc.arg(code_hash)::text),
+    updated_at = CURRENT_TIMESTAMP
+WHERE user_id = sqlc.arg(user_id) AND sqlc.arg(code_hash)::text = ANY(totp_recovery_codes);
@@ -0,0 +1,39 @@
+-- name: CreateAuthSession :one
+INSERT INTO auth_sessions (
+    session_id, user_id, refresh_token_hash, user_agent, ip_address, expires_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6
+) RETURNING *;
+
+-- name: GetAuthSession :one
+SELECT * FROM auth_sessions
+WHERE session_id = $1 LIMIT 1;
+
+-- name: RotateAuthSessionToken :one
+UPDATE auth_sessions
+SET
+    refresh_token_hash = sqlc.arg(new_hash),
+    expires_at = sqlc.arg(expires_at),
+    last_used_at = CURRENT_TIMESTAMP
+WHERE session_id = sqlc.arg(session_id)
+  AND refresh_token_hash = sqlc.arg(old_hash)
+  AND revoked_at IS NULL
+  AND expires_at > CURRENT_TIMESTAMP
+RETURNING *;
+
+-- name: ListActiveAuthSessions :many
+SELECT * FROM auth_sessions
+WHERE user_id = $1
+  AND revoked_at IS NULL
+  AND expires_at > CURRENT_TIMESTAMP
+ORDER BY last_used_at DESC;
+
+-- name: RevokeAuthSession :execrows
+UPDATE auth_sessions
+SET revoked_at = CURRENT_TIMESTAMP
+WHERE session_id = $1 AND user_id = $2 AND revoked_at IS NULL;
+
+-- name: RevokeUserAuthSessions :exec
+UPDATE auth_sessions
+SET revoked_at = CURRENT_TIMESTAMP
+WHERE user_id = $1 AND revoked_at IS NULL;
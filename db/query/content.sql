@@ -2,20 +2,104 @@
 INSERT INTO content_items (
     user_id, content_id, content_type, title, href, url, media_type,
     desktop_x, desktop_y, desktop_style, mobile_x, mobile_y, mobile_style,
-    halign, valign, content_data, overrides, is_active
+    halign, valign, content_data, overrides, is_active, pinned, pin_order,
+    publish_at, unpublish_at, custom_styling, embed_data, auto_embed
 ) VALUES (
-    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22,
+    $23, $24, $25
 ) RETURNING *;
 
 -- name: GetContentItem :one
 SELECT * FROM content_items
-WHERE item_id = $1 LIMIT 1;
+WHERE item_id = $1 AND deleted_at IS NULL LIMIT 1;
+
+-- name: GetDeletedContentItem :one
+SELECT * FROM content_items
+WHERE item_id = $1 AND deleted_at IS NOT NULL LIMIT 1;
 
 -- name: GetUserContentItems :many
 SELECT * FROM content_items
-WHERE user_id = $1
+WHERE user_id = $1 AND deleted_at IS NULL
+ORDER BY created_at DESC;
+
+-- name: GetPublishedUserContentItems :many
+-- Only the items inside their publish window, for showing to visitors
+SELECT * FROM content_items
+WHERE user_id = $1 AND deleted_at IS NULL
+  AND (publish_at IS NULL OR publish_at <= CURRENT_TIMESTAMP)
+  AND (unpublish_at IS NULL OR unpublish_at > CURRENT_TIMESTAMP)
 ORDER BY created_at DESC;
 
+-- name: ListDeletedUserContentItems :many
+-- The user's trash, most recently deleted first
+SELECT * FROM content_items
+WHERE user_id = $1 AND deleted_at IS NOT NULL
+ORDER BY deleted_at DESC;
+
+-- name: CountActiveUserContentItems :one
+SELECT COUNT(*) FROM content_items
+WHERE user_id = $1 AND is_active = true AND deleted_at IS NULL;
+
+-- name: CountUserContentItemsByType :many
+SELECT content_type, COUNT(*) AS count FROM content_items
+WHERE user_id = $1 AND deleted_at IS NULL
+GROUP BY content_type
+ORDER BY content_type;
+
+-- name: CountUserContentItemsByTypeAndState :many
+SELECT
+    content_type,
+    (CASE
+        WHEN is_active IS NULL THEN 'draft'
+        WHEN is_active THEN 'active'
+        ELSE 'inactive'
+    END)::text AS state,
+    COUNT(*) AS count
+FROM content_items
+WHERE user_id = $1 AND deleted_at IS NULL
+GROUP BY content_type, state
+ORDER BY content_type, state;
+
+-- name: CountPinnedUserContentItems :one
+SELECT COUNT(*) FROM content_items
+WHERE user_id = $1 AND pinned AND item_id <> $2 AND deleted_at IS NULL;
+
+-- name: GetContentItemsByIDs :many
+SELECT * FROM content_items
+WHERE item_id = ANY(sqlc.arg(item_ids)::uuid[]) AND deleted_at IS NULL;
+
+-- name: GetContentItemsOwnership :many
+SELECT item_id, user_id FROM content_items
+WHERE item_id = ANY(sqlc.arg(item_ids)::uuid[]) AND deleted_at IS NULL;
+
+-- name: BulkUpdateContentStyle :execrows
+UPDATE content_items
+SET
+    desktop_style = COALESCE(sqlc.narg(desktop_style), desktop_style),
+    mobile_style = COALESCE(sqlc.narg(mobile_style), mobile_style),
+    overrides = CASE
+        WHEN sqlc.narg(overrides)::jsonb IS NULL THEN overrides
+        ELSE COALESCE(overrides, '{}'::jsonb) || sqlc.narg(overrides)::jsonb
+    END,
+    updated_at = CURRENT_TIMESTAMP
+WHERE user_id = sqlc.arg(user_id) AND deleted_at IS NULL
+AND (item_id = ANY(sqlc.arg(item_ids)::uuid[]) OR content_type = sqlc.narg(content_type));
+
+-- name: CopyContentItems :execrows
+INSERT INTO content_items (
+    user_id, content_id, content_type, title, href, url, media_type,
+    desktop_x, desktop_y, desktop_style, mobile_x, mobile_y, mobile_style,
+    halign, valign, content_data, overrides, is_active,
+    custom_styling, embed_data, auto_embed, publish_at, unpublish_at
+)
+SELECT
+    sqlc.arg(target_user_id), content_id, content_type, title, href, url, media_type,
+    desktop_x, desktop_y, desktop_style, mobile_x, mobile_y, mobile_style,
+    halign, valign, content_data, overrides, is_active,
+    custom_styling, embed_data, auto_embed, publish_at, unpublish_at
+FROM content_items
+WHERE user_id = sqlc.arg(source_user_id) AND is_active = true AND deleted_at IS NULL;
+
 -- name: UpdateContentItem :exec
 UPDATE content_items
 SET
@@ -30,8 +114,20 @@ SET
     content_data = COALESCE($10, content_data),
     overrides = COALESCE($11, overrides),
     is_active = COALESCE($12, is_active),
+    pinned = COALESCE($13, pinned),
+    pin_order = COALESCE($14, pin_order),
+    publish_at = COALESCE($15, publish_at),
+    unpublish_at = COALESCE($16, unpublish_at),
+    updated_at = CURRENT_TIMESTAMP
+WHERE item_id = $1 AND deleted_at IS NULL;
+
+-- name: UpdateContentItemPin :exec
+UPDATE content_items
+SET
+    pinned = $2,
+    pin_order = $3,
     updated_at = CURRENT_TIMESTAMP
-WHERE item_id = $1;
+WHERE item_id = $1 AND deleted_at IS NULL;
 
 -- name: UpdateContentItemPosition :exec
 UPDATE content_items
@@ -41,8 +137,51 @@ SET
     mobile_x = COALESCE($4, mobile_x),
     mobile_y = COALESCE($5, mobile_y),
     updated_at = CURRENT_TIMESTAMP
-WHERE item_id = $1;
+WHERE item_id = $1 AND deleted_at IS NULL;
+
+-- name: ReorderContentItems :execrows
+-- Moves every listed item in one statement, and only if the user owns all
+-- of them, so a layout is never left half applied.
+UPDATE content_items
+SET
+    desktop_x = p.desktop_x,
+    desktop_y = p.desktop_y,
+    mobile_x = p.mobile_x,
+    mobile_y = p.mobile_y,
+    updated_at = CURRENT_TIMESTAMP
+FROM unnest(
+    sqlc.arg(item_ids)::uuid[],
+    sqlc.arg(desktop_x)::int[],
+    sqlc.arg(desktop_y)::int[],
+    sqlc.arg(mobile_x)::int[],
+    sqlc.arg(mobile_y)::int[]
+) AS p(item_id, desktop_x, desktop_y, mobile_x, mobile_y)
+WHERE content_items.item_id = p.item_id
+AND content_items.user_id = sqlc.arg(user_id)
+AND content_items.deleted_at IS NULL
+AND (
+    SELECT COUNT(*) FROM content_items owned
+    WHERE owned.item_id = ANY(sqlc.arg(item_ids)::uuid[])
+    AND owned.user_id = sqlc.arg(user_id)
+    AND owned.deleted_at IS NULL
+) = cardinality(sqlc.arg(item_ids)::uuid[]);
+
+-- name: SoftDeleteContentItem :execrows
+-- Moves an item to the trash, from where it can be restored until it's
+-- purged
+UPDATE content_items
+SET deleted_at = CURRENT_TIMESTAMP
+WHERE item_id = $1 AND deleted_at IS NULL;
+
+-- name: RestoreContentItem :execrows
+UPDATE content_items
+SET deleted_at = NULL, updated_at = CURRENT_TIMESTAMP
+WHERE item_id = $1 AND deleted_at IS NOT NULL;
 
--- name: DeleteContentItem :exec
+-- name: PurgeDeletedContentItems :execrows
 DELETE FROM content_items
-WHERE item_id = $1;
\ No newline at end of file
+WHERE item_id IN (
+    SELECT item_id FROM content_items
+    WHERE deleted_at < sqlc.arg(cutoff)::timestamptz
+    LIMIT sqlc.arg(batch_size)
+);
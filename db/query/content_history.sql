@@ -0,0 +1,13 @@
+-- name: CreateContentHistoryEntry :exec
+INSERT INTO content_history (item_id, actor_id, source, action, changes)
+VALUES ($1, $2, $3, $4, $5);
+
+-- name: ListContentHistory :many
+SELECT * FROM content_history
+WHERE item_id = $1
+ORDER BY created_at DESC
+LIMIT $2 OFFSET $3;
+
+-- name: CountContentHistory :one
+SELECT COUNT(*) FROM content_history
+WHERE item_id = $1;
@@ -0,0 +1,44 @@
+-- name: CreateContentRevision :one
+INSERT INTO content_revisions (
+    item_id, owner_id, author_id, changes
+) VALUES (
+    $1, $2, $3, $4
+) RETURNING *;
+
+-- name: GetContentRevision :one
+SELECT * FROM content_revisions
+WHERE revision_id = $1 LIMIT 1;
+
+-- name: ListPendingContentRevisions :many
+SELECT * FROM content_revisions
+WHERE status = 'pending'
+ORDER BY created_at ASC;
+
+-- name: ListPendingContentRevisionsByOwner :many
+SELECT * FROM content_revisions
+WHERE owner_id = $1 AND status = 'pending'
+ORDER BY created_at ASC;
+
+-- name: ReviewContentRevision :one
+UPDATE content_revisions
+SET
+    status = $2,
+    reviewed_by = $3,
+    reviewed_at = CURRENT_TIMESTAMP
+WHERE revision_id = $1 AND status = 'pending'
+RETURNING *;
+
+-- name: AddAccountCollaborator :exec
+INSERT INTO account_collaborators (owner_id, collaborator_id)
+VALUES ($1, $2)
+ON CONFLICT DO NOTHING;
+
+-- name: RemoveAccountCollaborator :exec
+DELETE FROM account_collaborators
+WHERE owner_id = $1 AND collaborator_id = $2;
+
+-- name: IsAccountCollaborator :one
+SELECT EXISTS (
+    SELECT 1 FROM account_collaborators
+    WHERE owner_id = $1 AND collaborator_id = $2
+);
@@ -0,0 +1,29 @@
+-- name: CreateFileRecord :one
+INSERT INTO files (file_key, user_id, category, size, content_type)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: GetFileRecord :one
+SELECT * FROM files
+WHERE file_key = $1 LIMIT 1;
+
+-- name: DeleteFileRecord :exec
+DELETE FROM files
+WHERE file_key = $1;
+
+-- name: ListUserFiles :many
+SELECT * FROM files
+WHERE user_id = sqlc.arg('user_id')
+AND (sqlc.narg('category')::text IS NULL OR category = sqlc.narg('category'))
+ORDER BY created_at DESC, file_key
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: CountUserFiles :one
+SELECT COUNT(*) FROM files
+WHERE user_id = sqlc.arg('user_id')
+AND (sqlc.narg('category')::text IS NULL OR category = sqlc.narg('category'));
+
+-- name: UpdateFileSize :exec
+UPDATE files
+SET size = $2
+WHERE file_key = $1;
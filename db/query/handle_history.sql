@@ -0,0 +1,9 @@
+-- name: RecordHandleChange :exec
+INSERT INTO handle_history (handle, user_id)
+VALUES ($1, $2)
+ON CONFLICT (handle) DO UPDATE
+SET user_id = EXCLUDED.user_id, changed_at = CURRENT_TIMESTAMP;
+
+-- name: GetHandleHistoryOwner :one
+SELECT user_id FROM handle_history
+WHERE handle = $1 LIMIT 1;
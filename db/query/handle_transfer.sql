@@ -0,0 +1,32 @@
+-- name: ReleaseHandleForTransfer :execrows
+WITH released AS (
+    UPDATE users
+    SET handle = sqlc.arg(replacement_handle), updated_at = CURRENT_TIMESTAMP
+    WHERE user_id = sqlc.arg(from_user_id) AND handle = sqlc.arg(handle)
+    RETURNING user_id
+)
+INSERT INTO handle_transfers (handle, from_user_id, to_user_id, expires_at)
+SELECT sqlc.arg(handle), released.user_id, sqlc.arg(to_user_id), sqlc.arg(expires_at)
+FROM released
+ON CONFLICT (handle) DO UPDATE
+SET from_user_id = EXCLUDED.from_user_id,
+    to_user_id = EXCLUDED.to_user_id,
+    expires_at = EXCLUDED.expires_at,
+    created_at = CURRENT_TIMESTAMP;
+
+-- name: ClaimHandleTransfer :execrows
+WITH claimed AS (
+    DELETE FROM handle_transfers
+    WHERE handle = $1 AND to_user_id = $2 AND expires_at > CURRENT_TIMESTAMP
+    RETURNING handle, to_user_id
+)
+UPDATE users
+SET handle = claimed.handle, updated_at = CURRENT_TIMESTAMP
+FROM claimed
+WHERE users.user_id = claimed.to_user_id;
+
+-- name: IsHandleReserved :one
+SELECT EXISTS (
+    SELECT 1 FROM handle_transfers
+    WHERE handle = $1 AND expires_at > CURRENT_TIMESTAMP
+) AS reserved;
@@ -0,0 +1,32 @@
+-- name: CreateInviteCode :one
+INSERT INTO invite_codes (
+    code, max_uses, expires_at, created_by
+) VALUES (
+    $1, $2, $3, $4
+) RETURNING *;
+
+-- name: GetInviteCode :one
+SELECT * FROM invite_codes
+WHERE code = $1 LIMIT 1;
+
+-- name: ListInviteCodes :many
+SELECT * FROM invite_codes
+ORDER BY created_at DESC
+LIMIT $1 OFFSET $2;
+
+-- name: RedeemInviteCode :one
+UPDATE invite_codes
+SET used_count = used_count + 1
+WHERE code = $1
+AND used_count < max_uses
+AND (expires_at IS NULL OR expires_at > CURRENT_TIMESTAMP)
+RETURNING *;
+
+-- name: ReleaseInviteCode :exec
+UPDATE invite_codes
+SET used_count = used_count - 1
+WHERE code = $1 AND used_count > 0;
+
+-- name: RecordInviteRedemption :exec
+INSERT INTO invite_code_redemptions (code, user_id)
+VALUES ($1, $2);
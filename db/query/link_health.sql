@@ -0,0 +1,56 @@
+-- name: ListLinksForHealthCheck :many
+SELECT
+    c.item_id,
+    c.user_id,
+    c.title,
+    COALESCE(c.href, c.url)::text AS target_url,
+    u.username,
+    u.email,
+    u.locale,
+    COALESCE(h.consecutive_failures, 0)::int AS consecutive_failures,
+    COALESCE(h.auto_deactivate, TRUE)::boolean AS auto_deactivate
+FROM content_items c
+JOIN users u ON u.user_id = c.user_id
+LEFT JOIN content_link_health h ON h.item_id = c.item_id
+WHERE COALESCE(c.is_active, TRUE)
+AND c.deleted_at IS NULL
+AND u.deleted_at IS NULL
+AND COALESCE(c.href, c.url) IS NOT NULL
+AND (h.last_checked_at IS NULL OR h.last_checked_at < sqlc.arg(checked_before)::timestamptz)
+ORDER BY h.last_checked_at NULLS FIRST
+LIMIT sqlc.arg(max_items);
+
+-- name: RecordLinkCheck :one
+INSERT INTO content_link_health (item_id, consecutive_failures, last_status, last_checked_at)
+VALUES (
+    sqlc.arg(item_id),
+    CASE WHEN sqlc.arg(failed)::boolean THEN 1 ELSE 0 END,
+    sqlc.narg(last_status),
+    CURRENT_TIMESTAMP
+)
+ON CONFLICT (item_id) DO UPDATE
+SET consecutive_failures = CASE
+        WHEN sqlc.arg(failed)::boolean THEN content_link_health.consecutive_failures + 1
+        ELSE 0
+    END,
+    last_status = EXCLUDED.last_status,
+    last_checked_at = EXCLUDED.last_checked_at
+RETURNING *;
+
+-- name: DeactivateDeadLink :execrows
+WITH deactivated AS (
+    UPDATE content_items
+    SET is_active = FALSE, updated_at = CURRENT_TIMESTAMP
+    WHERE content_items.item_id = $1 AND COALESCE(content_items.is_active, TRUE)
+    RETURNING content_items.item_id
+)
+UPDATE content_link_health
+SET deactivated_at = CURRENT_TIMESTAMP
+FROM deactivated
+WHERE content_link_health.item_id = deactivated.item_id;
+
+-- name: SetLinkAutoDeactivate :exec
+INSERT INTO content_link_health (item_id, auto_deactivate)
+VALUES ($1, $2)
+ON CONFLICT (item_id) DO UPDATE
+SET auto_deactivate = EXCLUDED.auto_deactivate;
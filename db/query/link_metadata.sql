@@ -1,9 +1,10 @@
 -- name: CreateLinkMetadata :one
 INSERT INTO link_metadata (
     domain, url, title, description, favicon_url, image_url,
-    platform_name, platform_type, platform_color, is_verified
+    platform_name, platform_type, platform_color, is_verified, image_source, fetch_failed,
+    canonical_url
 ) VALUES (
-    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13
 ) RETURNING *;
 
 -- name: GetLinkMetadataByURL :one
@@ -15,6 +16,11 @@ SELECT * FROM link_metadata
 WHERE domain = $1
 ORDER BY created_at DESC;
 
+-- name: GetLinkMetadataFreshness :many
+SELECT url, COALESCE(updated_at, created_at)::timestamptz AS updated_at, fetch_failed
+FROM link_metadata
+WHERE url = ANY(sqlc.arg(urls)::text[]);
+
 -- name: UpdateLinkMetadata :one
 UPDATE link_metadata
 SET
@@ -26,6 +32,9 @@ SET
     platform_type = COALESCE($7, platform_type),
     platform_color = COALESCE($8, platform_color),
     is_verified = COALESCE($9, is_verified),
+    image_source = COALESCE($10, image_source),
+    fetch_failed = $11,
+    canonical_url = COALESCE($12, canonical_url),
     updated_at = CURRENT_TIMESTAMP
 WHERE url = $1
 RETURNING *;
@@ -0,0 +1,13 @@
+-- name: GetOAuthAccount :one
+SELECT * FROM oauth_accounts
+WHERE provider = $1 AND provider_user_id = $2 LIMIT 1;
+
+-- name: CreateOAuthAccount :one
+INSERT INTO oauth_accounts (user_id, provider, provider_user_id, email, name)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: ListUserOAuthProviders :many
+SELECT provider FROM oauth_accounts
+WHERE user_id = $1
+ORDER BY created_at;
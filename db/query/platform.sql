@@ -0,0 +1,30 @@
+-- name: ListPlatforms :many
+SELECT * FROM platforms
+ORDER BY domain;
+
+-- name: CreatePlatform :one
+INSERT INTO platforms (
+    domain, name, type, color, icon, url_template, scrape_strategy, oembed_endpoint, user_agent, query_keys
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10
+) RETURNING *;
+
+-- name: UpdatePlatform :one
+UPDATE platforms
+SET
+    name = COALESCE(sqlc.narg(name), name),
+    type = COALESCE(sqlc.narg(type), type),
+    color = COALESCE(sqlc.narg(color), color),
+    icon = COALESCE(sqlc.narg(icon), icon),
+    url_template = COALESCE(sqlc.narg(url_template), url_template),
+    scrape_strategy = COALESCE(sqlc.narg(scrape_strategy), scrape_strategy),
+    oembed_endpoint = COALESCE(sqlc.narg(oembed_endpoint), oembed_endpoint),
+    user_agent = COALESCE(sqlc.narg(user_agent), user_agent),
+    query_keys = COALESCE(sqlc.narg(query_keys)::text[], query_keys),
+    updated_at = CURRENT_TIMESTAMP
+WHERE domain = sqlc.arg(domain)
+RETURNING *;
+
+-- name: DeletePlatform :execrows
+DELETE FROM platforms
+WHERE domain = $1;
@@ -0,0 +1,38 @@
+-- name: CreateReportSubscription :one
+INSERT INTO report_subscriptions (
+    user_id, frequency, metrics, unsubscribe_token, next_send_at
+) VALUES (
+    $1, $2, $3, $4, $5
+) RETURNING *;
+
+-- name: ListUserReportSubscriptions :many
+SELECT * FROM report_subscriptions
+WHERE user_id = $1
+ORDER BY created_at;
+
+-- name: DeleteReportSubscription :execrows
+DELETE FROM report_subscriptions
+WHERE subscription_id = $1 AND user_id = $2;
+
+-- name: DeleteReportSubscriptionByToken :execrows
+DELETE FROM report_subscriptions
+WHERE unsubscribe_token = $1;
+
+-- name: ListDueReportSubscriptions :many
+SELECT sqlc.embed(report_subscriptions), users.username, users.email, users.locale, users.is_premium
+FROM report_subscriptions
+JOIN users ON users.user_id = report_subscriptions.user_id
+WHERE report_subscriptions.next_send_at <= sqlc.arg(due_before)
+AND users.deleted_at IS NULL
+ORDER BY report_subscriptions.next_send_at
+LIMIT sqlc.arg(row_limit);
+
+-- name: ClaimReportSubscription :execrows
+-- Moves a due subscription on to its next send time. Only the caller whose
+-- update lands sends the report, so instances never send it twice.
+UPDATE report_subscriptions
+SET
+    next_send_at = sqlc.arg(next_send_at),
+    last_sent_at = CURRENT_TIMESTAMP
+WHERE subscription_id = sqlc.arg(subscription_id)
+  AND next_send_at = sqlc.arg(due_at);
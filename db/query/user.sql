@@ -9,24 +9,74 @@ INSERT INTO users (
 
 -- name: GetUser :one
 SELECT * FROM users
-WHERE user_id = $1 LIMIT 1;
+WHERE user_id = $1 AND deleted_at IS NULL LIMIT 1;
 
 -- name: GetUserByUsername :one
 SELECT * FROM users
-WHERE username = $1 LIMIT 1;
+WHERE username = $1 AND deleted_at IS NULL LIMIT 1;
 
 -- name: GetUserByHandle :one
 SELECT * FROM users
-WHERE handle = $1 LIMIT 1;
+WHERE handle = $1 AND deleted_at IS NULL LIMIT 1;
+
+-- name: GetUserByCustomDomain :one
+SELECT * FROM users
+WHERE LOWER(custom_domain) = LOWER($1) AND deleted_at IS NULL LIMIT 1;
 
 -- name: GetUserByEmail :one
 SELECT * FROM users
+WHERE email = $1 AND deleted_at IS NULL LIMIT 1;
+
+-- name: GetUserByEmailIncludingDeleted :one
+-- Also finds accounts pending deletion, so their owner can sign in to
+-- restore them
+SELECT * FROM users
 WHERE email = $1 LIMIT 1;
 
+-- name: GetUserIncludingDeleted :one
+SELECT * FROM users
+WHERE user_id = $1 LIMIT 1;
+
+-- name: GetUsersByIDs :many
+SELECT * FROM users
+WHERE user_id = ANY(sqlc.arg(user_ids)::uuid[]) AND deleted_at IS NULL;
+
+-- name: IsHandleTaken :one
+SELECT (
+    EXISTS (SELECT 1 FROM users WHERE users.handle = $1)
+    OR EXISTS (
+        SELECT 1 FROM handle_transfers
+        WHERE handle_transfers.handle = $1 AND expires_at > CURRENT_TIMESTAMP
+    )
+)::boolean AS taken;
+
 -- name: ListUsers :many
 SELECT * FROM users
-ORDER BY created_at DESC
-LIMIT $1 OFFSET $2;
+WHERE (sqlc.narg('search')::text IS NULL
+    OR username ILIKE '%' || sqlc.narg('search') || '%'
+    OR handle ILIKE '%' || sqlc.narg('search') || '%'
+    OR email ILIKE '%' || sqlc.narg('search') || '%')
+AND (sqlc.narg('is_premium')::boolean IS NULL OR is_premium = sqlc.narg('is_premium'))
+AND (sqlc.narg('is_admin')::boolean IS NULL OR is_admin = sqlc.narg('is_admin'))
+AND (sqlc.narg('onboarded')::boolean IS NULL OR onboarded = sqlc.narg('onboarded'))
+AND (sqlc.narg('verified')::boolean IS NULL
+    OR COALESCE((SELECT is_email_verified FROM auth WHERE auth.user_id = users.user_id), false) = sqlc.narg('verified'))
+AND deleted_at IS NULL
+ORDER BY created_at DESC, user_id
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: CountUsers :one
+SELECT COUNT(*) FROM users
+WHERE (sqlc.narg('search')::text IS NULL
+    OR username ILIKE '%' || sqlc.narg('search') || '%'
+    OR handle ILIKE '%' || sqlc.narg('search') || '%'
+    OR email ILIKE '%' || sqlc.narg('search') || '%')
+AND (sqlc.narg('is_premium')::boolean IS NULL OR is_premium = sqlc.narg('is_premium'))
+AND (sqlc.narg('is_admin')::boolean IS NULL OR is_admin = sqlc.narg('is_admin'))
+AND (sqlc.narg('onboarded')::boolean IS NULL OR onboarded = sqlc.narg('onboarded'))
+AND (sqlc.narg('verified')::boolean IS NULL
+    OR COALESCE((SELECT is_email_verified FROM auth WHERE auth.user_id = users.user_id), false) = sqlc.narg('verified'))
+AND deleted_at IS NULL;
 
 -- name: UpdateUser :exec
 UPDATE users
@@ -37,6 +87,10 @@ SET
     profile_image_url = COALESCE($5, profile_image_url),
     layout_version = COALESCE($6, layout_version),
     custom_domain = COALESCE($7, custom_domain),
+    custom_domain_verified_at = CASE
+        WHEN COALESCE($7, custom_domain) IS DISTINCT FROM custom_domain THEN NULL
+        ELSE custom_domain_verified_at
+    END,
     updated_at = CURRENT_TIMESTAMP
 WHERE user_id = $1;
 
@@ -75,6 +129,41 @@ SET
     updated_at = CURRENT_TIMESTAMP
 WHERE user_id = $1;
 
+-- name: UpdateUserContentApproval :exec
+UPDATE users
+SET
+    requires_content_approval = $2,
+    updated_at = CURRENT_TIMESTAMP
+WHERE user_id = $1;
+
+-- name: UpdateUserTemplateStatus :exec
+UPDATE users
+SET
+    is_template = $2,
+    updated_at = CURRENT_TIMESTAMP
+WHERE user_id = $1;
+
+-- name: UpdateUserVisibility :exec
+UPDATE users
+SET
+    visibility = $2,
+    updated_at = CURRENT_TIMESTAMP
+WHERE user_id = $1;
+
+-- name: UpdateUserAnalyticsEnabled :exec
+UPDATE users
+SET
+    analytics_enabled = $2,
+    updated_at = CURRENT_TIMESTAMP
+WHERE user_id = $1;
+
+-- name: UpdateUserLocale :exec
+UPDATE users
+SET
+    locale = $2,
+    updated_at = CURRENT_TIMESTAMP
+WHERE user_id = $1;
+
 -- name: UpdateUserOnboardedStatus :exec
 UPDATE users
 SET
@@ -82,6 +171,55 @@ SET
     updated_at = CURRENT_TIMESTAMP
 WHERE user_id = $1;
 
+-- name: ReleaseUnverifiedCustomDomain :exec
+UPDATE users
+SET custom_domain = NULL,
+    custom_domain_token = NULL,
+    updated_at = CURRENT_TIMESTAMP
+WHERE LOWER(custom_domain) = LOWER(sqlc.arg(domain))
+  AND custom_domain_verified_at IS NULL
+  AND user_id <> sqlc.arg(user_id);
+
+-- name: SetCustomDomainVerification :exec
+UPDATE users
+SET custom_domain = $2,
+    custom_domain_token = $3,
+    custom_domain_verified_at = NULL,
+    updated_at = CURRENT_TIMESTAMP
+WHERE user_id = $1;
+
+-- name: MarkCustomDomainVerified :execrows
+UPDATE users
+SET custom_domain_verified_at = CURRENT_TIMESTAMP,
+    updated_at = CURRENT_TIMESTAMP
+WHERE user_id = $1 AND custom_domain = $2 AND custom_domain_token = $3;
+
 -- name: DeleteUser :exec
 DELETE FROM users
-WHERE user_id = $1;
\ No newline at end of file
+WHERE user_id = $1;
+
+-- name: SoftDeleteUser :one
+UPDATE users
+SET deleted_at = CURRENT_TIMESTAMP,
+    updated_at = CURRENT_TIMESTAMP
+WHERE user_id = $1 AND deleted_at IS NULL
+RETURNING deleted_at;
+
+-- name: RestoreUser :execrows
+UPDATE users
+SET deleted_at = NULL,
+    updated_at = CURRENT_TIMESTAMP
+WHERE user_id = $1 AND deleted_at IS NOT NULL;
+
+-- name: PurgeDeletedUsers :execrows
+DELETE FROM users
+WHERE user_id IN (
+    SELECT user_id FROM users
+    WHERE deleted_at < sqlc.arg(cutoff)::timestamptz
+    LIMIT sqlc.arg(batch_size)
+);
+
+-- name: ListTemplateUsers :many
+SELECT * FROM users
+WHERE is_template = TRUE AND visibility = 'public' AND deleted_at IS NULL
+ORDER BY handle;
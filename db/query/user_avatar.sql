@@ -0,0 +1,33 @@
+-- name: CreateUserAvatar :one
+INSERT INTO user_avatars (user_id, file_key, url)
+VALUES ($1, $2, $3)
+RETURNING *;
+
+-- name: GetUserAvatar :one
+SELECT * FROM user_avatars
+WHERE avatar_id = $1 AND user_id = $2 LIMIT 1;
+
+-- name: ListUserAvatars :many
+SELECT * FROM user_avatars
+WHERE user_id = $1
+ORDER BY created_at DESC;
+
+-- name: CountUserAvatars :one
+SELECT COUNT(*) FROM user_avatars
+WHERE user_id = $1;
+
+-- name: ActivateUserAvatar :execrows
+WITH activated AS (
+    UPDATE user_avatars
+    SET is_active = (user_avatars.avatar_id = sqlc.arg(avatar_id))
+    WHERE user_avatars.user_id = sqlc.arg(user_id)
+      AND EXISTS (
+          SELECT 1 FROM user_avatars owned
+          WHERE owned.avatar_id = sqlc.arg(avatar_id) AND owned.user_id = sqlc.arg(user_id)
+      )
+    RETURNING user_avatars.url, user_avatars.is_active
+)
+UPDATE users
+SET profile_image_url = activated.url, updated_at = CURRENT_TIMESTAMP
+FROM activated
+WHERE users.user_id = sqlc.arg(user_id) AND activated.is_active;
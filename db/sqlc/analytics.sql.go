@@ -12,25 +12,86 @@ import (
 	"github.com/google/uuid"
 )
 
-const createAnalyticsEntry = `-- name: CreateAnalyticsEntry :one
+const createAnalyticsEntries = `-- name: CreateAnalyticsEntries :execrows
 
 INSERT INTO analytics (
-    item_id, user_id, ip_address, user_agent, referrer, page_view
-) VALUES (
-    $1, $2, $3, $4, $5, false
-) RETURNING analytics_id, item_id, user_id, ip_address, user_agent, referrer, clicked_at, page_view, country, device_type, browser, utm_source, utm_medium, utm_campaign
+    item_id, user_id, ip_address, user_agent, referrer, interaction_type, page_view, is_bot,
+    utm_source, utm_medium, utm_campaign
+)
+SELECT
+    e.item_id, e.user_id, NULLIF(e.ip_address, ''), NULLIF(e.user_agent, ''), NULLIF(e.referrer, ''),
+    e.interaction_type, false, e.is_bot,
+    NULLIF(e.utm_source, ''), NULLIF(e.utm_medium, ''), NULLIF(e.utm_campaign, '')
+FROM unnest(
+    $1::uuid[],
+    $2::uuid[],
+    $3::text[],
+    $4::text[],
+    $5::text[],
+    $6::text[],
+    $7::boolean[],
+    $8::text[],
+    $9::text[],
+    $10::text[]
+) AS e(item_id, user_id, ip_address, user_agent, referrer, interaction_type, is_bot, utm_source, utm_medium, utm_campaign)
 `
 
-type CreateAnalyticsEntryParams struct {
-	ItemID    uuid.UUID `json:"item_id"`
-	UserID    uuid.UUID `json:"user_id"`
-	IpAddress *string   `json:"ip_address"`
-	UserAgent *string   `json:"user_agent"`
-	Referrer  *string   `json:"referrer"`
+type CreateAnalyticsEntriesParams struct {
+	ItemIds          []uuid.UUID `json:"item_ids"`
+	UserIds          []uuid.UUID `json:"user_ids"`
+	IpAddresses      []string    `json:"ip_addresses"`
+	UserAgents       []string    `json:"user_agents"`
+	Referrers        []string    `json:"referrers"`
+	InteractionTypes []string    `json:"interaction_types"`
+	IsBots           []bool      `json:"is_bots"`
+	UtmSources       []string    `json:"utm_sources"`
+	UtmMediums       []string    `json:"utm_mediums"`
+	UtmCampaigns     []string    `json:"utm_campaigns"`
 }
 
 // db/query/analytics.sql
 // Recording clicks and page views
+func (q *Queries) CreateAnalyticsEntries(ctx context.Context, arg CreateAnalyticsEntriesParams) (int64, error) {
+	result, err := q.db.Exec(ctx, createAnalyticsEntries,
+		arg.ItemIds,
+		arg.UserIds,
+		arg.IpAddresses,
+		arg.UserAgents,
+		arg.Referrers,
+		arg.InteractionTypes,
+		arg.IsBots,
+		arg.UtmSources,
+		arg.UtmMediums,
+		arg.UtmCampaigns,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const createAnalyticsEntry = `-- name: CreateAnalyticsEntry :one
+INSERT INTO analytics (
+    item_id, user_id, ip_address, user_agent, referrer, interaction_type, page_view, is_bot,
+    utm_source, utm_medium, utm_campaign
+) VALUES (
+    $1, $2, $3, $4, $5, $6, false, $7, $8, $9, $10
+) RETURNING analytics_id, item_id, user_id, ip_address, user_agent, referrer, clicked_at, page_view, country, device_type, browser, utm_source, utm_medium, utm_campaign, interaction_type, anonymized_at, is_bot
+`
+
+type CreateAnalyticsEntryParams struct {
+	ItemID          uuid.UUID `json:"item_id"`
+	UserID          uuid.UUID `json:"user_id"`
+	IpAddress       *string   `json:"ip_address"`
+	UserAgent       *string   `json:"user_agent"`
+	Referrer        *string   `json:"referrer"`
+	InteractionType string    `json:"interaction_type"`
+	IsBot           bool      `json:"is_bot"`
+	UtmSource       *string   `json:"utm_source"`
+	UtmMedium       *string   `json:"utm_medium"`
+	UtmCampaign     *string   `json:"utm_campaign"`
+}
+
 func (q *Queries) CreateAnalyticsEntry(ctx context.Context, arg CreateAnalyticsEntryParams) (*Analytic, error) {
 	row := q.db.QueryRow(ctx, createAnalyticsEntry,
 		arg.ItemID,
@@ -38,6 +99,11 @@ func (q *Queries) CreateAnalyticsEntry(ctx context.Context, arg CreateAnalyticsE
 		arg.IpAddress,
 		arg.UserAgent,
 		arg.Referrer,
+		arg.InteractionType,
+		arg.IsBot,
+		arg.UtmSource,
+		arg.UtmMedium,
+		arg.UtmCampaign,
 	)
 	var i Analytic
 	err := row.Scan(
@@ -55,24 +121,32 @@ func (q *Queries) CreateAnalyticsEntry(ctx context.Context, arg CreateAnalyticsE
 		&i.UtmSource,
 		&i.UtmMedium,
 		&i.UtmCampaign,
+		&i.InteractionType,
+		&i.AnonymizedAt,
+		&i.IsBot,
 	)
 	return &i, err
 }
 
 const createPageViewEntry = `-- name: CreatePageViewEntry :one
 INSERT INTO analytics (
-    item_id, user_id, ip_address, user_agent, referrer, page_view
+    item_id, user_id, ip_address, user_agent, referrer, page_view, is_bot,
+    utm_source, utm_medium, utm_campaign
 ) VALUES (
-    $1, $2, $3, $4, $5, true
-) RETURNING analytics_id, item_id, user_id, ip_address, user_agent, referrer, clicked_at, page_view, country, device_type, browser, utm_source, utm_medium, utm_campaign
+    $1, $2, $3, $4, $5, true, $6, $7, $8, $9
+) RETURNING analytics_id, item_id, user_id, ip_address, user_agent, referrer, clicked_at, page_view, country, device_type, browser, utm_source, utm_medium, utm_campaign, interaction_type, anonymized_at, is_bot
 `
 
 type CreatePageViewEntryParams struct {
-	ItemID    uuid.UUID `json:"item_id"`
-	UserID    uuid.UUID `json:"user_id"`
-	IpAddress *string   `json:"ip_address"`
-	UserAgent *string   `json:"user_agent"`
-	Referrer  *string   `json:"referrer"`
+	ItemID      uuid.UUID `json:"item_id"`
+	UserID      uuid.UUID `json:"user_id"`
+	IpAddress   *string   `json:"ip_address"`
+	UserAgent   *string   `json:"user_agent"`
+	Referrer    *string   `json:"referrer"`
+	IsBot       bool      `json:"is_bot"`
+	UtmSource   *string   `json:"utm_source"`
+	UtmMedium   *string   `json:"utm_medium"`
+	UtmCampaign *string   `json:"utm_campaign"`
 }
 
 func (q *Queries) CreatePageViewEntry(ctx context.Context, arg CreatePageViewEntryParams) (*Analytic, error) {
@@ -82,6 +156,10 @@ func (q *Queries) CreatePageViewEntry(ctx context.Context, arg CreatePageViewEnt
 		arg.IpAddress,
 		arg.UserAgent,
 		arg.Referrer,
+		arg.IsBot,
+		arg.UtmSource,
+		arg.UtmMedium,
+		arg.UtmCampaign,
 	)
 	var i Analytic
 	err := row.Scan(
@@ -99,13 +177,79 @@ func (q *Queries) CreatePageViewEntry(ctx context.Context, arg CreatePageViewEnt
 		&i.UtmSource,
 		&i.UtmMedium,
 		&i.UtmCampaign,
+		&i.InteractionType,
+		&i.AnonymizedAt,
+		&i.IsBot,
 	)
 	return &i, err
 }
 
+const getCampaignCounts = `-- name: GetCampaignCounts :many
+SELECT
+    COALESCE(utm_source, '') AS utm_source,
+    COALESCE(utm_medium, '') AS utm_medium,
+    COALESCE(utm_campaign, '') AS utm_campaign,
+    COUNT(*) FILTER (WHERE page_view = false AND interaction_type = 'click') AS clicks,
+    COUNT(*) FILTER (WHERE page_view = true) AS page_views
+FROM analytics
+WHERE user_id = $1
+AND clicked_at >= $2
+AND clicked_at <= $3
+AND (is_bot = false OR is_bot = $4)
+GROUP BY utm_source, utm_medium, utm_campaign
+ORDER BY COUNT(*) DESC
+`
+
+type GetCampaignCountsParams struct {
+	UserID      uuid.UUID  `json:"user_id"`
+	ClickedAt   *time.Time `json:"clicked_at"`
+	ClickedAt_2 *time.Time `json:"clicked_at_2"`
+	IsBot       bool       `json:"is_bot"`
+}
+
+type GetCampaignCountsRow struct {
+	UtmSource   string `json:"utm_source"`
+	UtmMedium   string `json:"utm_medium"`
+	UtmCampaign string `json:"utm_campaign"`
+	Clicks      int64  `json:"clicks"`
+	PageViews   int64  `json:"page_views"`
+}
+
+// Campaign analytics
+func (q *Queries) GetCampaignCounts(ctx context.Context, arg GetCampaignCountsParams) ([]*GetCampaignCountsRow, error) {
+	rows, err := q.db.Query(ctx, getCampaignCounts,
+		arg.UserID,
+		arg.ClickedAt,
+		arg.ClickedAt_2,
+		arg.IsBot,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []*GetCampaignCountsRow
+	for rows.Next() {
+		var i GetCampaignCountsRow
+		if err := rows.Scan(
+			&i.UtmSource,
+			&i.UtmMedium,
+			&i.UtmCampaign,
+			&i.Clicks,
+			&i.PageViews,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getContentItemClickCount = `-- name: GetContentItemClickCount :one
 SELECT COUNT(*) FROM analytics
-WHERE item_id = $1 AND page_view = false
+WHERE item_id = $1 AND page_view = false AND interaction_type = 'click'
 `
 
 // Count queries
@@ -116,8 +260,82 @@ func (q *Queries) GetContentItemClickCount(ctx context.Context, itemID uuid.UUID
 	return count, err
 }
 
+const getContentItemUniqueClickCount = `-- name: GetContentItemUniqueClickCount :one
+SELECT COUNT(DISTINCT (ip_address, FLOOR(EXTRACT(EPOCH FROM clicked_at) / $3::float8)))
+FROM analytics
+WHERE item_id = $1
+AND clicked_at >= $2
+AND ip_address IS NOT NULL
+AND page_view = false
+AND interaction_type = 'click'
+AND is_bot = false
+`
+
+type GetContentItemUniqueClickCountParams struct {
+	ItemID        uuid.UUID  `json:"item_id"`
+	ClickedAt     *time.Time `json:"clicked_at"`
+	WindowSeconds float64    `json:"window_seconds"`
+}
+
+func (q *Queries) GetContentItemUniqueClickCount(ctx context.Context, arg GetContentItemUniqueClickCountParams) (int64, error) {
+	row := q.db.QueryRow(ctx, getContentItemUniqueClickCount, arg.ItemID, arg.ClickedAt, arg.WindowSeconds)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const getIPAddressCounts = `-- name: GetIPAddressCounts :many
+SELECT
+    COALESCE(ip_address, '') AS ip_address,
+    COUNT(*) AS count
+FROM analytics
+WHERE user_id = $1
+AND clicked_at >= $2
+AND clicked_at <= $3
+AND (is_bot = false OR is_bot = $4)
+GROUP BY ip_address
+`
+
+type GetIPAddressCountsParams struct {
+	UserID      uuid.UUID  `json:"user_id"`
+	ClickedAt   *time.Time `json:"clicked_at"`
+	ClickedAt_2 *time.Time `json:"clicked_at_2"`
+	IsBot       bool       `json:"is_bot"`
+}
+
+type GetIPAddressCountsRow struct {
+	IpAddress string `json:"ip_address"`
+	Count     int64  `json:"count"`
+}
+
+// Geographic analytics
+func (q *Queries) GetIPAddressCounts(ctx context.Context, arg GetIPAddressCountsParams) ([]*GetIPAddressCountsRow, error) {
+	rows, err := q.db.Query(ctx, getIPAddressCounts,
+		arg.UserID,
+		arg.ClickedAt,
+		arg.ClickedAt_2,
+		arg.IsBot,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []*GetIPAddressCountsRow
+	for rows.Next() {
+		var i GetIPAddressCountsRow
+		if err := rows.Scan(&i.IpAddress, &i.Count); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getItemAnalytics = `-- name: GetItemAnalytics :many
-SELECT analytics_id, item_id, user_id, ip_address, user_agent, referrer, clicked_at, page_view, country, device_type, browser, utm_source, utm_medium, utm_campaign FROM analytics
+SELECT analytics_id, item_id, user_id, ip_address, user_agent, referrer, clicked_at, page_view, country, device_type, browser, utm_source, utm_medium, utm_campaign, interaction_type, anonymized_at, is_bot FROM analytics
 WHERE item_id = $1
 ORDER BY clicked_at DESC
 LIMIT $2 OFFSET $3
@@ -154,6 +372,9 @@ func (q *Queries) GetItemAnalytics(ctx context.Context, arg GetItemAnalyticsPara
 			&i.UtmSource,
 			&i.UtmMedium,
 			&i.UtmCampaign,
+			&i.InteractionType,
+			&i.AnonymizedAt,
+			&i.IsBot,
 		); err != nil {
 			return nil, err
 		}
@@ -173,7 +394,9 @@ FROM analytics
 WHERE item_id = $1
 AND clicked_at >= $2
 AND clicked_at <= $3
+AND (is_bot = false OR is_bot = $4)
 AND page_view = false
+AND interaction_type = 'click'
 GROUP BY DATE_TRUNC('day', clicked_at)
 ORDER BY day
 `
@@ -182,6 +405,7 @@ type GetItemAnalyticsByTimeRangeParams struct {
 	ItemID      uuid.UUID  `json:"item_id"`
 	ClickedAt   *time.Time `json:"clicked_at"`
 	ClickedAt_2 *time.Time `json:"clicked_at_2"`
+	IsBot       bool       `json:"is_bot"`
 }
 
 type GetItemAnalyticsByTimeRangeRow struct {
@@ -190,7 +414,12 @@ type GetItemAnalyticsByTimeRangeRow struct {
 }
 
 func (q *Queries) GetItemAnalyticsByTimeRange(ctx context.Context, arg GetItemAnalyticsByTimeRangeParams) ([]*GetItemAnalyticsByTimeRangeRow, error) {
-	rows, err := q.db.Query(ctx, getItemAnalyticsByTimeRange, arg.ItemID, arg.ClickedAt, arg.ClickedAt_2)
+	rows, err := q.db.Query(ctx, getItemAnalyticsByTimeRange,
+		arg.ItemID,
+		arg.ClickedAt,
+		arg.ClickedAt_2,
+		arg.IsBot,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -209,13 +438,54 @@ func (q *Queries) GetItemAnalyticsByTimeRange(ctx context.Context, arg GetItemAn
 	return items, nil
 }
 
+const getItemInteractionBreakdown = `-- name: GetItemInteractionBreakdown :many
+SELECT
+    interaction_type,
+    COUNT(*) AS count
+FROM analytics
+WHERE item_id = $1 AND page_view = false
+GROUP BY interaction_type
+ORDER BY count DESC
+`
+
+type GetItemInteractionBreakdownRow struct {
+	InteractionType string `json:"interaction_type"`
+	Count           int64  `json:"count"`
+}
+
+func (q *Queries) GetItemInteractionBreakdown(ctx context.Context, itemID uuid.UUID) ([]*GetItemInteractionBreakdownRow, error) {
+	rows, err := q.db.Query(ctx, getItemInteractionBreakdown, itemID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []*GetItemInteractionBreakdownRow
+	for rows.Next() {
+		var i GetItemInteractionBreakdownRow
+		if err := rows.Scan(&i.InteractionType, &i.Count); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getProfilePageViews = `-- name: GetProfilePageViews :one
 SELECT COUNT(*) FROM analytics
 WHERE user_id = $1 AND page_view = true
+AND (is_bot = false OR is_bot = $2)
 `
 
-func (q *Queries) GetProfilePageViews(ctx context.Context, userID uuid.UUID) (int64, error) {
-	row := q.db.QueryRow(ctx, getProfilePageViews, userID)
+type GetProfilePageViewsParams struct {
+	UserID uuid.UUID `json:"user_id"`
+	IsBot  bool      `json:"is_bot"`
+}
+
+func (q *Queries) GetProfilePageViews(ctx context.Context, arg GetProfilePageViewsParams) (int64, error) {
+	row := q.db.QueryRow(ctx, getProfilePageViews, arg.UserID, arg.IsBot)
 	var count int64
 	err := row.Scan(&count)
 	return count, err
@@ -229,6 +499,7 @@ FROM analytics
 WHERE user_id = $1
 AND clicked_at >= $2
 AND clicked_at <= $3
+AND (is_bot = false OR is_bot = $4)
 AND page_view = true
 GROUP BY DATE_TRUNC('day', clicked_at)
 ORDER BY day
@@ -238,6 +509,7 @@ type GetProfilePageViewsByDateParams struct {
 	UserID      uuid.UUID  `json:"user_id"`
 	ClickedAt   *time.Time `json:"clicked_at"`
 	ClickedAt_2 *time.Time `json:"clicked_at_2"`
+	IsBot       bool       `json:"is_bot"`
 }
 
 type GetProfilePageViewsByDateRow struct {
@@ -246,7 +518,12 @@ type GetProfilePageViewsByDateRow struct {
 }
 
 func (q *Queries) GetProfilePageViewsByDate(ctx context.Context, arg GetProfilePageViewsByDateParams) ([]*GetProfilePageViewsByDateRow, error) {
-	rows, err := q.db.Query(ctx, getProfilePageViewsByDate, arg.UserID, arg.ClickedAt, arg.ClickedAt_2)
+	rows, err := q.db.Query(ctx, getProfilePageViewsByDate,
+		arg.UserID,
+		arg.ClickedAt,
+		arg.ClickedAt_2,
+		arg.IsBot,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -273,6 +550,7 @@ FROM analytics
 WHERE user_id = $1
 AND clicked_at >= $2
 AND clicked_at <= $3
+AND (is_bot = false OR is_bot = $5)
 AND referrer IS NOT NULL
 GROUP BY referrer
 ORDER BY count DESC
@@ -284,6 +562,7 @@ type GetReferrerAnalyticsParams struct {
 	ClickedAt   *time.Time `json:"clicked_at"`
 	ClickedAt_2 *time.Time `json:"clicked_at_2"`
 	Limit       int64      `json:"limit"`
+	IsBot       bool       `json:"is_bot"`
 }
 
 type GetReferrerAnalyticsRow struct {
@@ -297,6 +576,7 @@ func (q *Queries) GetReferrerAnalytics(ctx context.Context, arg GetReferrerAnaly
 		arg.ClickedAt,
 		arg.ClickedAt_2,
 		arg.Limit,
+		arg.IsBot,
 	)
 	if err != nil {
 		return nil, err
@@ -321,30 +601,37 @@ SELECT
     a.item_id,
     c.content_type,
     COALESCE(c.title, '') AS title,
-    COUNT(*) AS click_count
+    COUNT(*) AS click_count,
+    COUNT(DISTINCT (a.ip_address, FLOOR(EXTRACT(EPOCH FROM a.clicked_at) / $6::float8)))
+        FILTER (WHERE a.ip_address IS NOT NULL) AS unique_click_count
 FROM analytics a
 JOIN content_items c ON a.item_id = c.item_id
 WHERE c.user_id = $1
 AND a.clicked_at >= $2
 AND a.clicked_at <= $3
+AND (a.is_bot = false OR a.is_bot = $5)
 AND a.page_view = false
+AND a.interaction_type = 'click'
 GROUP BY a.item_id, c.content_type, c.title
 ORDER BY click_count DESC
 LIMIT $4
 `
 
 type GetTopContentItemsByClicksParams struct {
-	UserID      uuid.UUID  `json:"user_id"`
-	ClickedAt   *time.Time `json:"clicked_at"`
-	ClickedAt_2 *time.Time `json:"clicked_at_2"`
-	Limit       int64      `json:"limit"`
+	UserID        uuid.UUID  `json:"user_id"`
+	ClickedAt     *time.Time `json:"clicked_at"`
+	ClickedAt_2   *time.Time `json:"clicked_at_2"`
+	Limit         int64      `json:"limit"`
+	IsBot         bool       `json:"is_bot"`
+	WindowSeconds float64    `json:"window_seconds"`
 }
 
 type GetTopContentItemsByClicksRow struct {
-	ItemID      uuid.UUID `json:"item_id"`
-	ContentType string    `json:"content_type"`
-	Title       string    `json:"title"`
-	ClickCount  int64     `json:"click_count"`
+	ItemID           uuid.UUID `json:"item_id"`
+	ContentType      string    `json:"content_type"`
+	Title            string    `json:"title"`
+	ClickCount       int64     `json:"click_count"`
+	UniqueClickCount int64     `json:"unique_click_count"`
 }
 
 // Insight queries
@@ -354,6 +641,8 @@ func (q *Queries) GetTopContentItemsByClicks(ctx context.Context, arg GetTopCont
 		arg.ClickedAt,
 		arg.ClickedAt_2,
 		arg.Limit,
+		arg.IsBot,
+		arg.WindowSeconds,
 	)
 	if err != nil {
 		return nil, err
@@ -367,6 +656,7 @@ func (q *Queries) GetTopContentItemsByClicks(ctx context.Context, arg GetTopCont
 			&i.ContentType,
 			&i.Title,
 			&i.ClickCount,
+			&i.UniqueClickCount,
 		); err != nil {
 			return nil, err
 		}
@@ -384,6 +674,7 @@ FROM analytics
 WHERE user_id = $1
 AND clicked_at >= $2
 AND clicked_at <= $3
+AND (is_bot = false OR is_bot = $4)
 AND ip_address IS NOT NULL
 AND page_view = true
 `
@@ -392,11 +683,17 @@ type GetUniqueVisitorsParams struct {
 	UserID      uuid.UUID  `json:"user_id"`
 	ClickedAt   *time.Time `json:"clicked_at"`
 	ClickedAt_2 *time.Time `json:"clicked_at_2"`
+	IsBot       bool       `json:"is_bot"`
 }
 
 // Visitor analytics
 func (q *Queries) GetUniqueVisitors(ctx context.Context, arg GetUniqueVisitorsParams) (int64, error) {
-	row := q.db.QueryRow(ctx, getUniqueVisitors, arg.UserID, arg.ClickedAt, arg.ClickedAt_2)
+	row := q.db.QueryRow(ctx, getUniqueVisitors,
+		arg.UserID,
+		arg.ClickedAt,
+		arg.ClickedAt_2,
+		arg.IsBot,
+	)
 	var count int64
 	err := row.Scan(&count)
 	return count, err
@@ -410,6 +707,7 @@ FROM analytics
 WHERE user_id = $1
 AND clicked_at >= $2
 AND clicked_at <= $3
+AND (is_bot = false OR is_bot = $4)
 AND ip_address IS NOT NULL
 AND page_view = true
 GROUP BY DATE_TRUNC('day', clicked_at)
@@ -420,6 +718,7 @@ type GetUniqueVisitorsByDayParams struct {
 	UserID      uuid.UUID  `json:"user_id"`
 	ClickedAt   *time.Time `json:"clicked_at"`
 	ClickedAt_2 *time.Time `json:"clicked_at_2"`
+	IsBot       bool       `json:"is_bot"`
 }
 
 type GetUniqueVisitorsByDayRow struct {
@@ -428,7 +727,12 @@ type GetUniqueVisitorsByDayRow struct {
 }
 
 func (q *Queries) GetUniqueVisitorsByDay(ctx context.Context, arg GetUniqueVisitorsByDayParams) ([]*GetUniqueVisitorsByDayRow, error) {
-	rows, err := q.db.Query(ctx, getUniqueVisitorsByDay, arg.UserID, arg.ClickedAt, arg.ClickedAt_2)
+	rows, err := q.db.Query(ctx, getUniqueVisitorsByDay,
+		arg.UserID,
+		arg.ClickedAt,
+		arg.ClickedAt_2,
+		arg.IsBot,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -447,8 +751,58 @@ func (q *Queries) GetUniqueVisitorsByDay(ctx context.Context, arg GetUniqueVisit
 	return items, nil
 }
 
+const getUserAgentCounts = `-- name: GetUserAgentCounts :many
+SELECT
+    COALESCE(user_agent, '') AS user_agent,
+    COUNT(*) AS count
+FROM analytics
+WHERE user_id = $1
+AND clicked_at >= $2
+AND clicked_at <= $3
+AND (is_bot = false OR is_bot = $4)
+GROUP BY user_agent
+`
+
+type GetUserAgentCountsParams struct {
+	UserID      uuid.UUID  `json:"user_id"`
+	ClickedAt   *time.Time `json:"clicked_at"`
+	ClickedAt_2 *time.Time `json:"clicked_at_2"`
+	IsBot       bool       `json:"is_bot"`
+}
+
+type GetUserAgentCountsRow struct {
+	UserAgent string `json:"user_agent"`
+	Count     int64  `json:"count"`
+}
+
+// Device analytics
+func (q *Queries) GetUserAgentCounts(ctx context.Context, arg GetUserAgentCountsParams) ([]*GetUserAgentCountsRow, error) {
+	rows, err := q.db.Query(ctx, getUserAgentCounts,
+		arg.UserID,
+		arg.ClickedAt,
+		arg.ClickedAt_2,
+		arg.IsBot,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []*GetUserAgentCountsRow
+	for rows.Next() {
+		var i GetUserAgentCountsRow
+		if err := rows.Scan(&i.UserAgent, &i.Count); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getUserAnalytics = `-- name: GetUserAnalytics :many
-SELECT analytics_id, item_id, user_id, ip_address, user_agent, referrer, clicked_at, page_view, country, device_type, browser, utm_source, utm_medium, utm_campaign FROM analytics
+SELECT analytics_id, item_id, user_id, ip_address, user_agent, referrer, clicked_at, page_view, country, device_type, browser, utm_source, utm_medium, utm_campaign, interaction_type, anonymized_at, is_bot FROM analytics
 WHERE user_id = $1
 ORDER BY clicked_at DESC
 LIMIT $2 OFFSET $3
@@ -484,6 +838,9 @@ func (q *Queries) GetUserAnalytics(ctx context.Context, arg GetUserAnalyticsPara
 			&i.UtmSource,
 			&i.UtmMedium,
 			&i.UtmCampaign,
+			&i.InteractionType,
+			&i.AnonymizedAt,
+			&i.IsBot,
 		); err != nil {
 			return nil, err
 		}
@@ -496,32 +853,42 @@ func (q *Queries) GetUserAnalytics(ctx context.Context, arg GetUserAnalyticsPara
 }
 
 const getUserAnalyticsByTimeRange = `-- name: GetUserAnalyticsByTimeRange :many
-SELECT 
-    DATE_TRUNC('day', clicked_at) AS day,
+SELECT
+    DATE_TRUNC($1::text, clicked_at)::timestamptz AS bucket,
     COUNT(*) AS clicks
 FROM analytics
-WHERE user_id = $1
-AND clicked_at >= $2
-AND clicked_at <= $3
+WHERE user_id = $2
+AND clicked_at >= $3
+AND clicked_at <= $4
+AND (is_bot = false OR is_bot = $5)
 AND page_view = false
-GROUP BY DATE_TRUNC('day', clicked_at)
-ORDER BY day
+AND interaction_type = 'click'
+GROUP BY bucket
+ORDER BY bucket
 `
 
 type GetUserAnalyticsByTimeRangeParams struct {
+	Unit        string     `json:"unit"`
 	UserID      uuid.UUID  `json:"user_id"`
-	ClickedAt   *time.Time `json:"clicked_at"`
-	ClickedAt_2 *time.Time `json:"clicked_at_2"`
+	StartDate   *time.Time `json:"start_date"`
+	EndDate     *time.Time `json:"end_date"`
+	IncludeBots bool       `json:"include_bots"`
 }
 
 type GetUserAnalyticsByTimeRangeRow struct {
-	Day    time.Time `json:"day"`
+	Bucket time.Time `json:"bucket"`
 	Clicks int64     `json:"clicks"`
 }
 
 // Time range analytics
 func (q *Queries) GetUserAnalyticsByTimeRange(ctx context.Context, arg GetUserAnalyticsByTimeRangeParams) ([]*GetUserAnalyticsByTimeRangeRow, error) {
-	rows, err := q.db.Query(ctx, getUserAnalyticsByTimeRange, arg.UserID, arg.ClickedAt, arg.ClickedAt_2)
+	rows, err := q.db.Query(ctx, getUserAnalyticsByTimeRange,
+		arg.Unit,
+		arg.UserID,
+		arg.StartDate,
+		arg.EndDate,
+		arg.IncludeBots,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -529,6 +896,50 @@ func (q *Queries) GetUserAnalyticsByTimeRange(ctx context.Context, arg GetUserAn
 	var items []*GetUserAnalyticsByTimeRangeRow
 	for rows.Next() {
 		var i GetUserAnalyticsByTimeRangeRow
+		if err := rows.Scan(&i.Bucket, &i.Clicks); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUserClicksForDays = `-- name: GetUserClicksForDays :many
+SELECT
+    DATE_TRUNC('day', clicked_at) AS day,
+    COUNT(*) AS clicks
+FROM analytics
+WHERE user_id = $1
+AND DATE_TRUNC('day', clicked_at) = ANY($2::timestamptz[])
+AND page_view = false
+AND interaction_type = 'click'
+AND is_bot = false
+GROUP BY DATE_TRUNC('day', clicked_at)
+ORDER BY day
+`
+
+type GetUserClicksForDaysParams struct {
+	UserID uuid.UUID   `json:"user_id"`
+	Days   []time.Time `json:"days"`
+}
+
+type GetUserClicksForDaysRow struct {
+	Day    time.Time `json:"day"`
+	Clicks int64     `json:"clicks"`
+}
+
+func (q *Queries) GetUserClicksForDays(ctx context.Context, arg GetUserClicksForDaysParams) ([]*GetUserClicksForDaysRow, error) {
+	rows, err := q.db.Query(ctx, getUserClicksForDays, arg.UserID, arg.Days)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []*GetUserClicksForDaysRow
+	for rows.Next() {
+		var i GetUserClicksForDaysRow
 		if err := rows.Scan(&i.Day, &i.Clicks); err != nil {
 			return nil, err
 		}
@@ -542,12 +953,184 @@ func (q *Queries) GetUserAnalyticsByTimeRange(ctx context.Context, arg GetUserAn
 
 const getUserItemClickCount = `-- name: GetUserItemClickCount :one
 SELECT COUNT(*) FROM analytics
-WHERE user_id = $1 AND page_view = false
+WHERE user_id = $1 AND page_view = false AND interaction_type = 'click'
+AND (is_bot = false OR is_bot = $2)
 `
 
-func (q *Queries) GetUserItemClickCount(ctx context.Context, userID uuid.UUID) (int64, error) {
-	row := q.db.QueryRow(ctx, getUserItemClickCount, userID)
+type GetUserItemClickCountParams struct {
+	UserID uuid.UUID `json:"user_id"`
+	IsBot  bool      `json:"is_bot"`
+}
+
+func (q *Queries) GetUserItemClickCount(ctx context.Context, arg GetUserItemClickCountParams) (int64, error) {
+	row := q.db.QueryRow(ctx, getUserItemClickCount, arg.UserID, arg.IsBot)
 	var count int64
 	err := row.Scan(&count)
 	return count, err
 }
+
+const getUserPeriodTotals = `-- name: GetUserPeriodTotals :one
+SELECT
+    COUNT(*) FILTER (WHERE page_view = true) AS views,
+    COUNT(*) FILTER (WHERE page_view = false AND interaction_type = 'click') AS clicks,
+    COUNT(DISTINCT ip_address) FILTER (WHERE page_view = true AND ip_address IS NOT NULL) AS unique_visitors
+FROM analytics
+WHERE user_id = $1
+AND clicked_at >= $2
+AND clicked_at < $3
+AND (is_bot = false OR is_bot = $4)
+`
+
+type GetUserPeriodTotalsParams struct {
+	UserID      uuid.UUID  `json:"user_id"`
+	ClickedAt   *time.Time `json:"clicked_at"`
+	ClickedAt_2 *time.Time `json:"clicked_at_2"`
+	IsBot       bool       `json:"is_bot"`
+}
+
+type GetUserPeriodTotalsRow struct {
+	Views          int64 `json:"views"`
+	Clicks         int64 `json:"clicks"`
+	UniqueVisitors int64 `json:"unique_visitors"`
+}
+
+func (q *Queries) GetUserPeriodTotals(ctx context.Context, arg GetUserPeriodTotalsParams) (*GetUserPeriodTotalsRow, error) {
+	row := q.db.QueryRow(ctx, getUserPeriodTotals,
+		arg.UserID,
+		arg.ClickedAt,
+		arg.ClickedAt_2,
+		arg.IsBot,
+	)
+	var i GetUserPeriodTotalsRow
+	err := row.Scan(&i.Views, &i.Clicks, &i.UniqueVisitors)
+	return &i, err
+}
+
+const listRecentClicks = `-- name: ListRecentClicks :many
+SELECT
+    a.item_id,
+    COALESCE(c.title, '') AS title,
+    COALESCE(a.referrer, '') AS referrer,
+    COALESCE(a.ip_address, '') AS ip_address,
+    a.interaction_type,
+    a.clicked_at
+FROM analytics a
+JOIN content_items c ON a.item_id = c.item_id
+WHERE a.user_id = $1
+AND a.page_view = false
+AND a.is_bot = false
+ORDER BY a.clicked_at DESC
+LIMIT $2
+`
+
+type ListRecentClicksParams struct {
+	UserID uuid.UUID `json:"user_id"`
+	Limit  int64     `json:"limit"`
+}
+
+type ListRecentClicksRow struct {
+	ItemID          uuid.UUID  `json:"item_id"`
+	Title           string     `json:"title"`
+	Referrer        string     `json:"referrer"`
+	IpAddress       string     `json:"ip_address"`
+	InteractionType string     `json:"interaction_type"`
+	ClickedAt       *time.Time `json:"clicked_at"`
+}
+
+// Recent activity
+func (q *Queries) ListRecentClicks(ctx context.Context, arg ListRecentClicksParams) ([]*ListRecentClicksRow, error) {
+	rows, err := q.db.Query(ctx, listRecentClicks, arg.UserID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []*ListRecentClicksRow
+	for rows.Next() {
+		var i ListRecentClicksRow
+		if err := rows.Scan(
+			&i.ItemID,
+			&i.Title,
+			&i.Referrer,
+			&i.IpAddress,
+			&i.InteractionType,
+			&i.ClickedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUserAnalyticsForExport = `-- name: ListUserAnalyticsForExport :many
+SELECT analytics.analytics_id, analytics.item_id, analytics.user_id, analytics.ip_address, analytics.user_agent, analytics.referrer, analytics.clicked_at, analytics.page_view, analytics.country, analytics.device_type, analytics.browser, analytics.utm_source, analytics.utm_medium, analytics.utm_campaign, analytics.interaction_type, analytics.anonymized_at, analytics.is_bot, content_items.content_type
+FROM analytics
+LEFT JOIN content_items ON content_items.item_id = analytics.item_id
+WHERE analytics.user_id = $1
+AND analytics.clicked_at <= $2
+AND (analytics.clicked_at, analytics.analytics_id) > ($3::timestamptz, $4::uuid)
+ORDER BY analytics.clicked_at, analytics.analytics_id
+LIMIT $5
+`
+
+type ListUserAnalyticsForExportParams struct {
+	UserID         uuid.UUID  `json:"user_id"`
+	EndAt          *time.Time `json:"end_at"`
+	AfterClickedAt time.Time  `json:"after_clicked_at"`
+	AfterID        uuid.UUID  `json:"after_id"`
+	RowLimit       int64      `json:"row_limit"`
+}
+
+type ListUserAnalyticsForExportRow struct {
+	Analytic    Analytic `json:"analytic"`
+	ContentType *string  `json:"content_type"`
+}
+
+// Export
+func (q *Queries) ListUserAnalyticsForExport(ctx context.Context, arg ListUserAnalyticsForExportParams) ([]*ListUserAnalyticsForExportRow, error) {
+	rows, err := q.db.Query(ctx, listUserAnalyticsForExport,
+		arg.UserID,
+		arg.EndAt,
+		arg.AfterClickedAt,
+		arg.AfterID,
+		arg.RowLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []*ListUserAnalyticsForExportRow
+	for rows.Next() {
+		var i ListUserAnalyticsForExportRow
+		if err := rows.Scan(
+			&i.Analytic.AnalyticsID,
+			&i.Analytic.ItemID,
+			&i.Analytic.UserID,
+			&i.Analytic.IpAddress,
+			&i.Analytic.UserAgent,
+			&i.Analytic.Referrer,
+			&i.Analytic.ClickedAt,
+			&i.Analytic.PageView,
+			&i.Analytic.Country,
+			&i.Analytic.DeviceType,
+			&i.Analytic.Browser,
+			&i.Analytic.UtmSource,
+			&i.Analytic.UtmMedium,
+			&i.Analytic.UtmCampaign,
+			&i.Analytic.InteractionType,
+			&i.Analytic.AnonymizedAt,
+			&i.Analytic.IsBot,
+			&i.ContentType,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -37,6 +37,43 @@ func (q *Queries) ClearVerificationToken(ctx context.Context, userID uuid.UUID)
 	return err
 }
 
+const consumeRecoveryCode = `-- name: ConsumeRecoveryCode :execrows
+UPDATE auth
+SET
+    totp_recovery_codes = array_remove(totp_recovery_codes, $1::text),
+    updated_at = CURRENT_TIMESTAMP
+WHERE user_id = $2 AND $1::text = ANY(totp_recovery_codes)
+`
+
+type ConsumeRecoveryCodeParams struct {
+	CodeHash string    `json:"code_hash"`
+	UserID   uuid.UUID `json:"user_id"`
+}
+
+func (q *Queries) ConsumeRecoveryCode(ctx context.Context, arg ConsumeRecoveryCodeParams) (int64, error) {
+	result, err := q.db.Exec(ctx, consumeRecoveryCode, arg.CodeHash, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const consumeRefreshToken = `-- name: ConsumeRefreshToken :execrows
+UPDATE refresh_tokens
+SET consumed_at = CURRENT_TIMESTAMP
+WHERE token_id = $1
+  AND consumed_at IS NULL
+  AND expires_at > CURRENT_TIMESTAMP
+`
+
+func (q *Queries) ConsumeRefreshToken(ctx context.Context, tokenID uuid.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, consumeRefreshToken, tokenID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const createAuth = `-- name: CreateAuth :exec
 INSERT INTO auth (
     user_id, password_hash, salt, is_email_verified, verification_token, reset_token, reset_token_expires_at
@@ -47,8 +84,8 @@ INSERT INTO auth (
 
 type CreateAuthParams struct {
 	UserID              uuid.UUID  `json:"user_id"`
-	PasswordHash        string     `json:"password_hash"`
-	Salt                string     `json:"salt"`
+	PasswordHash        *string    `json:"password_hash"`
+	Salt                *string    `json:"salt"`
 	IsEmailVerified     *bool      `json:"is_email_verified"`
 	VerificationToken   *string    `json:"verification_token"`
 	ResetToken          *string    `json:"reset_token"`
@@ -68,8 +105,21 @@ func (q *Queries) CreateAuth(ctx context.Context, arg CreateAuthParams) error {
 	return err
 }
 
+const enableTOTP = `-- name: EnableTOTP :exec
+UPDATE auth
+SET
+    totp_enabled = TRUE,
+    updated_at = CURRENT_TIMESTAMP
+WHERE user_id = $1 AND totp_secret IS NOT NULL
+`
+
+func (q *Queries) EnableTOTP(ctx context.Context, userID uuid.UUID) error {
+	_, err := q.db.Exec(ctx, enableTOTP, userID)
+	return err
+}
+
 const getAuthByUserID = `-- name: GetAuthByUserID :one
-SELECT auth_id, user_id, password_hash, salt, is_email_verified, verification_token, reset_token, reset_token_expires_at, last_login, refresh_token, failed_login_attempts, locked_until, created_at, updated_at FROM auth
+SELECT auth_id, user_id, password_hash, salt, is_email_verified, verification_token, reset_token, reset_token_expires_at, last_login, failed_login_attempts, locked_until, created_at, updated_at, totp_secret, totp_enabled, totp_recovery_codes FROM auth
 WHERE user_id = $1 LIMIT 1
 `
 
@@ -86,17 +136,19 @@ func (q *Queries) GetAuthByUserID(ctx context.Context, userID uuid.UUID) (*Auth,
 		&i.ResetToken,
 		&i.ResetTokenExpiresAt,
 		&i.LastLogin,
-		&i.RefreshToken,
 		&i.FailedLoginAttempts,
 		&i.LockedUntil,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.TotpSecret,
+		&i.TotpEnabled,
+		&i.TotpRecoveryCodes,
 	)
 	return &i, err
 }
 
 const getAuthByVerificationToken = `-- name: GetAuthByVerificationToken :one
-SELECT auth_id, user_id, password_hash, salt, is_email_verified, verification_token, reset_token, reset_token_expires_at, last_login, refresh_token, failed_login_attempts, locked_until, created_at, updated_at FROM auth
+SELECT auth_id, user_id, password_hash, salt, is_email_verified, verification_token, reset_token, reset_token_expires_at, last_login, failed_login_attempts, locked_until, created_at, updated_at, totp_secret, totp_enabled, totp_recovery_codes FROM auth
 WHERE verification_token = $1
 LIMIT 1
 `
@@ -114,45 +166,159 @@ func (q *Queries) GetAuthByVerificationToken(ctx context.Context, verificationTo
 		&i.ResetToken,
 		&i.ResetTokenExpiresAt,
 		&i.LastLogin,
-		&i.RefreshToken,
 		&i.FailedLoginAttempts,
 		&i.LockedUntil,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.TotpSecret,
+		&i.TotpEnabled,
+		&i.TotpRecoveryCodes,
+	)
+	return &i, err
+}
+
+const getRefreshToken = `-- name: GetRefreshToken :one
+SELECT token_id, session_id, user_id, issued_at, expires_at, consumed_at FROM refresh_tokens
+WHERE token_id = $1 LIMIT 1
+`
+
+func (q *Queries) GetRefreshToken(ctx context.Context, tokenID uuid.UUID) (*RefreshToken, error) {
+	row := q.db.QueryRow(ctx, getRefreshToken, tokenID)
+	var i RefreshToken
+	err := row.Scan(
+		&i.TokenID,
+		&i.SessionID,
+		&i.UserID,
+		&i.IssuedAt,
+		&i.ExpiresAt,
+		&i.ConsumedAt,
 	)
 	return &i, err
 }
 
-const incrementFailedLoginAttempts = `-- name: IncrementFailedLoginAttempts :exec
+const getVerificationStatuses = `-- name: GetVerificationStatuses :many
+SELECT user_id, is_email_verified FROM auth
+WHERE user_id = ANY($1::uuid[])
+`
+
+type GetVerificationStatusesRow struct {
+	UserID          uuid.UUID `json:"user_id"`
+	IsEmailVerified *bool     `json:"is_email_verified"`
+}
+
+func (q *Queries) GetVerificationStatuses(ctx context.Context, userIds []uuid.UUID) ([]*GetVerificationStatusesRow, error) {
+	rows, err := q.db.Query(ctx, getVerificationStatuses, userIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []*GetVerificationStatusesRow
+	for rows.Next() {
+		var i GetVerificationStatusesRow
+		if err := rows.Scan(&i.UserID, &i.IsEmailVerified); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const incrementFailedLoginAttempts = `-- name: IncrementFailedLoginAttempts :one
 UPDATE auth
 SET
-    failed_login_attempts = failed_login_attempts + 1,
+    failed_login_attempts = COALESCE(failed_login_attempts, 0) + 1,
     updated_at = CURRENT_TIMESTAMP
 WHERE user_id = $1
+RETURNING failed_login_attempts
+`
+
+func (q *Queries) IncrementFailedLoginAttempts(ctx context.Context, userID uuid.UUID) (*int32, error) {
+	row := q.db.QueryRow(ctx, incrementFailedLoginAttempts, userID)
+	var failed_login_attempts *int32
+	err := row.Scan(&failed_login_attempts)
+	return failed_login_attempts, err
+}
+
+const invalidateRefreshTokenFamily = `-- name: InvalidateRefreshTokenFamily :exec
+UPDATE refresh_tokens
+SET consumed_at = COALESCE(consumed_at, CURRENT_TIMESTAMP)
+WHERE session_id = $1
 `
 
-func (q *Queries) IncrementFailedLoginAttempts(ctx context.Context, userID uuid.UUID) error {
-	_, err := q.db.Exec(ctx, incrementFailedLoginAttempts, userID)
+func (q *Queries) InvalidateRefreshTokenFamily(ctx context.Context, sessionID uuid.UUID) error {
+	_, err := q.db.Exec(ctx, invalidateRefreshTokenFamily, sessionID)
 	return err
 }
 
-const invalidateRefreshToken = `-- name: InvalidateRefreshToken :exec
-UPDATE auth
-SET
-    refresh_token = NULL,
-    updated_at = CURRENT_TIMESTAMP
+const invalidateUserRefreshTokens = `-- name: InvalidateUserRefreshTokens :exec
+UPDATE refresh_tokens
+SET consumed_at = COALESCE(consumed_at, CURRENT_TIMESTAMP)
 WHERE user_id = $1
 `
 
-func (q *Queries) InvalidateRefreshToken(ctx context.Context, userID uuid.UUID) error {
-	_, err := q.db.Exec(ctx, invalidateRefreshToken, userID)
+func (q *Queries) InvalidateUserRefreshTokens(ctx context.Context, userID uuid.UUID) error {
+	_, err := q.db.Exec(ctx, invalidateUserRefreshTokens, userID)
 	return err
 }
 
+const listUnverifiedUsersCreatedBefore = `-- name: ListUnverifiedUsersCreatedBefore :many
+SELECT u.user_id, u.username, u.email, u.locale, u.created_at
+FROM users u
+JOIN auth a ON a.user_id = u.user_id
+WHERE COALESCE(a.is_email_verified, false) = false
+AND u.created_at < $1
+AND u.deleted_at IS NULL
+ORDER BY u.created_at ASC
+LIMIT $2
+`
+
+type ListUnverifiedUsersCreatedBeforeParams struct {
+	CreatedAt *time.Time `json:"created_at"`
+	Limit     int32      `json:"limit"`
+}
+
+type ListUnverifiedUsersCreatedBeforeRow struct {
+	UserID    uuid.UUID  `json:"user_id"`
+	Username  string     `json:"username"`
+	Email     string     `json:"email"`
+	Locale    *string    `json:"locale"`
+	CreatedAt *time.Time `json:"created_at"`
+}
+
+func (q *Queries) ListUnverifiedUsersCreatedBefore(ctx context.Context, arg ListUnverifiedUsersCreatedBeforeParams) ([]*ListUnverifiedUsersCreatedBeforeRow, error) {
+	rows, err := q.db.Query(ctx, listUnverifiedUsersCreatedBefore, arg.CreatedAt, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []*ListUnverifiedUsersCreatedBeforeRow
+	for rows.Next() {
+		var i ListUnverifiedUsersCreatedBeforeRow
+		if err := rows.Scan(
+			&i.UserID,
+			&i.Username,
+			&i.Email,
+			&i.Locale,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const setAccountLockout = `-- name: SetAccountLockout :exec
 UPDATE auth
 SET
     locked_until = $2,
+    failed_login_attempts = 0,
     updated_at = CURRENT_TIMESTAMP
 WHERE user_id = $1
 `
@@ -187,21 +353,67 @@ func (q *Queries) SetResetToken(ctx context.Context, arg SetResetTokenParams) er
 	return err
 }
 
-const storeRefreshToken = `-- name: StoreRefreshToken :exec
+const setTOTPSecret = `-- name: SetTOTPSecret :exec
+UPDATE auth
+SET
+    totp_secret = $2,
+    totp_recovery_codes = $3,
+    totp_enabled = FALSE,
+    updated_at = CURRENT_TIMESTAMP
+WHERE user_id = $1
+`
+
+type SetTOTPSecretParams struct {
+	UserID            uuid.UUID `json:"user_id"`
+	TotpSecret        *string   `json:"totp_secret"`
+	TotpRecoveryCodes []string  `json:"totp_recovery_codes"`
+}
+
+func (q *Queries) SetTOTPSecret(ctx context.Context, arg SetTOTPSecretParams) error {
+	_, err := q.db.Exec(ctx, setTOTPSecret, arg.UserID, arg.TotpSecret, arg.TotpRecoveryCodes)
+	return err
+}
+
+const setVerificationToken = `-- name: SetVerificationToken :exec
 UPDATE auth
 SET
-    refresh_token = $2,
+    verification_token = $2,
     updated_at = CURRENT_TIMESTAMP
 WHERE user_id = $1
 `
 
+type SetVerificationTokenParams struct {
+	UserID            uuid.UUID `json:"user_id"`
+	VerificationToken *string   `json:"verification_token"`
+}
+
+func (q *Queries) SetVerificationToken(ctx context.Context, arg SetVerificationTokenParams) error {
+	_, err := q.db.Exec(ctx, setVerificationToken, arg.UserID, arg.VerificationToken)
+	return err
+}
+
+const storeRefreshToken = `-- name: StoreRefreshToken :exec
+INSERT INTO refresh_tokens (
+    token_id, session_id, user_id, expires_at
+) VALUES (
+    $1, $2, $3, $4
+)
+`
+
 type StoreRefreshTokenParams struct {
-	UserID       uuid.UUID `json:"user_id"`
-	RefreshToken *string   `json:"refresh_token"`
+	TokenID   uuid.UUID `json:"token_id"`
+	SessionID uuid.UUID `json:"session_id"`
+	UserID    uuid.UUID `json:"user_id"`
+	ExpiresAt time.Time `json:"expires_at"`
 }
 
 func (q *Queries) StoreRefreshToken(ctx context.Context, arg StoreRefreshTokenParams) error {
-	_, err := q.db.Exec(ctx, storeRefreshToken, arg.UserID, arg.RefreshToken)
+	_, err := q.db.Exec(ctx, storeRefreshToken,
+		arg.TokenID,
+		arg.SessionID,
+		arg.UserID,
+		arg.ExpiresAt,
+	)
 	return err
 }
 
@@ -230,8 +442,8 @@ WHERE user_id = $1
 
 type UpdatePasswordHashParams struct {
 	UserID       uuid.UUID `json:"user_id"`
-	PasswordHash string    `json:"password_hash"`
-	Salt         string    `json:"salt"`
+	PasswordHash *string   `json:"password_hash"`
+	Salt         *string   `json:"salt"`
 }
 
 func (q *Queries) UpdatePasswordHash(ctx context.Context, arg UpdatePasswordHashParams) error {
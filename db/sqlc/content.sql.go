@@ -7,40 +7,219 @@ package db
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgtype"
 )
 
+const bulkUpdateContentStyle = `-- name: BulkUpdateContentStyle :execrows
+UPDATE content_items
+SET
+    desktop_style = COALESCE($1, desktop_style),
+    mobile_style = COALESCE($2, mobile_style),
+    overrides = CASE
+        WHEN $3::jsonb IS NULL THEN overrides
+        ELSE COALESCE(overrides, '{}'::jsonb) || $3::jsonb
+    END,
+    updated_at = CURRENT_TIMESTAMP
+WHERE user_id = $4 AND deleted_at IS NULL
+AND (item_id = ANY($5::uuid[]) OR content_type = $6)
+`
+
+type BulkUpdateContentStyleParams struct {
+	DesktopStyle *string      `json:"desktop_style"`
+	MobileStyle  *string      `json:"mobile_style"`
+	Overrides    pgtype.JSONB `json:"overrides"`
+	UserID       uuid.UUID    `json:"user_id"`
+	ItemIds      []uuid.UUID  `json:"item_ids"`
+	ContentType  *string      `json:"content_type"`
+}
+
+func (q *Queries) BulkUpdateContentStyle(ctx context.Context, arg BulkUpdateContentStyleParams) (int64, error) {
+	result, err := q.db.Exec(ctx, bulkUpdateContentStyle,
+		arg.DesktopStyle,
+		arg.MobileStyle,
+		arg.Overrides,
+		arg.UserID,
+		arg.ItemIds,
+		arg.ContentType,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const copyContentItems = `-- name: CopyContentItems :execrows
+INSERT INTO content_items (
+    user_id, content_id, content_type, title, href, url, media_type,
+    desktop_x, desktop_y, desktop_style, mobile_x, mobile_y, mobile_style,
+    halign, valign, content_data, overrides, is_active,
+    custom_styling, embed_data, auto_embed, publish_at, unpublish_at
+)
+SELECT
+    $1, content_id, content_type, title, href, url, media_type,
+    desktop_x, desktop_y, desktop_style, mobile_x, mobile_y, mobile_style,
+    halign, valign, content_data, overrides, is_active,
+    custom_styling, embed_data, auto_embed, publish_at, unpublish_at
+FROM content_items
+WHERE user_id = $2 AND is_active = true AND deleted_at IS NULL
+`
+
+type CopyContentItemsParams struct {
+	TargetUserID uuid.UUID `json:"target_user_id"`
+	SourceUserID uuid.UUID `json:"source_user_id"`
+}
+
+func (q *Queries) CopyContentItems(ctx context.Context, arg CopyContentItemsParams) (int64, error) {
+	result, err := q.db.Exec(ctx, copyContentItems, arg.TargetUserID, arg.SourceUserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const countActiveUserContentItems = `-- name: CountActiveUserContentItems :one
+SELECT COUNT(*) FROM content_items
+WHERE user_id = $1 AND is_active = true AND deleted_at IS NULL
+`
+
+func (q *Queries) CountActiveUserContentItems(ctx context.Context, userID uuid.UUID) (int64, error) {
+	row := q.db.QueryRow(ctx, countActiveUserContentItems, userID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countPinnedUserContentItems = `-- name: CountPinnedUserContentItems :one
+SELECT COUNT(*) FROM content_items
+WHERE user_id = $1 AND pinned AND item_id <> $2 AND deleted_at IS NULL
+`
+
+type CountPinnedUserContentItemsParams struct {
+	UserID uuid.UUID `json:"user_id"`
+	ItemID uuid.UUID `json:"item_id"`
+}
+
+func (q *Queries) CountPinnedUserContentItems(ctx context.Context, arg CountPinnedUserContentItemsParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countPinnedUserContentItems, arg.UserID, arg.ItemID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countUserContentItemsByType = `-- name: CountUserContentItemsByType :many
+SELECT content_type, COUNT(*) AS count FROM content_items
+WHERE user_id = $1 AND deleted_at IS NULL
+GROUP BY content_type
+ORDER BY content_type
+`
+
+type CountUserContentItemsByTypeRow struct {
+	ContentType string `json:"content_type"`
+	Count       int64  `json:"count"`
+}
+
+func (q *Queries) CountUserContentItemsByType(ctx context.Context, userID uuid.UUID) ([]*CountUserContentItemsByTypeRow, error) {
+	rows, err := q.db.Query(ctx, countUserContentItemsByType, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []*CountUserContentItemsByTypeRow
+	for rows.Next() {
+		var i CountUserContentItemsByTypeRow
+		if err := rows.Scan(&i.ContentType, &i.Count); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const countUserContentItemsByTypeAndState = `-- name: CountUserContentItemsByTypeAndState :many
+SELECT
+    content_type,
+    (CASE
+        WHEN is_active IS NULL THEN 'draft'
+        WHEN is_active THEN 'active'
+        ELSE 'inactive'
+    END)::text AS state,
+    COUNT(*) AS count
+FROM content_items
+WHERE user_id = $1 AND deleted_at IS NULL
+GROUP BY content_type, state
+ORDER BY content_type, state
+`
+
+type CountUserContentItemsByTypeAndStateRow struct {
+	ContentType string `json:"content_type"`
+	State       string `json:"state"`
+	Count       int64  `json:"count"`
+}
+
+func (q *Queries) CountUserContentItemsByTypeAndState(ctx context.Context, userID uuid.UUID) ([]*CountUserContentItemsByTypeAndStateRow, error) {
+	rows, err := q.db.Query(ctx, countUserContentItemsByTypeAndState, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []*CountUserContentItemsByTypeAndStateRow
+	for rows.Next() {
+		var i CountUserContentItemsByTypeAndStateRow
+		if err := rows.Scan(&i.ContentType, &i.State, &i.Count); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const createContentItem = `-- name: CreateContentItem :one
 INSERT INTO content_items (
     user_id, content_id, content_type, title, href, url, media_type,
     desktop_x, desktop_y, desktop_style, mobile_x, mobile_y, mobile_style,
-    halign, valign, content_data, overrides, is_active
+    halign, valign, content_data, overrides, is_active, pinned, pin_order,
+    publish_at, unpublish_at, custom_styling, embed_data, auto_embed
 ) VALUES (
-    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18
-) RETURNING item_id, user_id, content_id, content_type, title, href, url, media_type, desktop_x, desktop_y, desktop_style, mobile_x, mobile_y, mobile_style, halign, valign, content_data, overrides, is_active, created_at, updated_at, custom_styling, embed_data, auto_embed
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22,
+    $23, $24, $25
+) RETURNING item_id, user_id, content_id, content_type, title, href, url, media_type, desktop_x, desktop_y, desktop_style, mobile_x, mobile_y, mobile_style, halign, valign, content_data, overrides, is_active, created_at, updated_at, custom_styling, embed_data, auto_embed, pinned, pin_order, publish_at, unpublish_at, deleted_at
 `
 
 type CreateContentItemParams struct {
-	UserID       uuid.UUID    `json:"user_id"`
-	ContentID    string       `json:"content_id"`
-	ContentType  string       `json:"content_type"`
-	Title        *string      `json:"title"`
-	Href         *string      `json:"href"`
-	Url          *string      `json:"url"`
-	MediaType    *string      `json:"media_type"`
-	DesktopX     *int32       `json:"desktop_x"`
-	DesktopY     *int32       `json:"desktop_y"`
-	DesktopStyle *string      `json:"desktop_style"`
-	MobileX      *int32       `json:"mobile_x"`
-	MobileY      *int32       `json:"mobile_y"`
-	MobileStyle  *string      `json:"mobile_style"`
-	Halign       *string      `json:"halign"`
-	Valign       *string      `json:"valign"`
-	ContentData  pgtype.JSONB `json:"content_data"`
-	Overrides    pgtype.JSONB `json:"overrides"`
-	IsActive     *bool        `json:"is_active"`
+	UserID        uuid.UUID    `json:"user_id"`
+	ContentID     string       `json:"content_id"`
+	ContentType   string       `json:"content_type"`
+	Title         *string      `json:"title"`
+	Href          *string      `json:"href"`
+	Url           *string      `json:"url"`
+	MediaType     *string      `json:"media_type"`
+	DesktopX      *int32       `json:"desktop_x"`
+	DesktopY      *int32       `json:"desktop_y"`
+	DesktopStyle  *string      `json:"desktop_style"`
+	MobileX       *int32       `json:"mobile_x"`
+	MobileY       *int32       `json:"mobile_y"`
+	MobileStyle   *string      `json:"mobile_style"`
+	Halign        *string      `json:"halign"`
+	Valign        *string      `json:"valign"`
+	ContentData   pgtype.JSONB `json:"content_data"`
+	Overrides     pgtype.JSONB `json:"overrides"`
+	IsActive      *bool        `json:"is_active"`
+	Pinned        bool         `json:"pinned"`
+	PinOrder      int32        `json:"pin_order"`
+	PublishAt     *time.Time   `json:"publish_at"`
+	UnpublishAt   *time.Time   `json:"unpublish_at"`
+	CustomStyling pgtype.JSONB `json:"custom_styling"`
+	EmbedData     pgtype.JSONB `json:"embed_data"`
+	AutoEmbed     *bool        `json:"auto_embed"`
 }
 
 func (q *Queries) CreateContentItem(ctx context.Context, arg CreateContentItemParams) (*ContentItem, error) {
@@ -63,6 +242,13 @@ func (q *Queries) CreateContentItem(ctx context.Context, arg CreateContentItemPa
 		arg.ContentData,
 		arg.Overrides,
 		arg.IsActive,
+		arg.Pinned,
+		arg.PinOrder,
+		arg.PublishAt,
+		arg.UnpublishAt,
+		arg.CustomStyling,
+		arg.EmbedData,
+		arg.AutoEmbed,
 	)
 	var i ContentItem
 	err := row.Scan(
@@ -90,27 +276,149 @@ func (q *Queries) CreateContentItem(ctx context.Context, arg CreateContentItemPa
 		&i.CustomStyling,
 		&i.EmbedData,
 		&i.AutoEmbed,
+		&i.Pinned,
+		&i.PinOrder,
+		&i.PublishAt,
+		&i.UnpublishAt,
+		&i.DeletedAt,
 	)
 	return &i, err
 }
 
-const deleteContentItem = `-- name: DeleteContentItem :exec
-DELETE FROM content_items
-WHERE item_id = $1
+const getContentItem = `-- name: GetContentItem :one
+SELECT item_id, user_id, content_id, content_type, title, href, url, media_type, desktop_x, desktop_y, desktop_style, mobile_x, mobile_y, mobile_style, halign, valign, content_data, overrides, is_active, created_at, updated_at, custom_styling, embed_data, auto_embed, pinned, pin_order, publish_at, unpublish_at, deleted_at FROM content_items
+WHERE item_id = $1 AND deleted_at IS NULL LIMIT 1
 `
 
-func (q *Queries) DeleteContentItem(ctx context.Context, itemID uuid.UUID) error {
-	_, err := q.db.Exec(ctx, deleteContentItem, itemID)
-	return err
+func (q *Queries) GetContentItem(ctx context.Context, itemID uuid.UUID) (*ContentItem, error) {
+	row := q.db.QueryRow(ctx, getContentItem, itemID)
+	var i ContentItem
+	err := row.Scan(
+		&i.ItemID,
+		&i.UserID,
+		&i.ContentID,
+		&i.ContentType,
+		&i.Title,
+		&i.Href,
+		&i.Url,
+		&i.MediaType,
+		&i.DesktopX,
+		&i.DesktopY,
+		&i.DesktopStyle,
+		&i.MobileX,
+		&i.MobileY,
+		&i.MobileStyle,
+		&i.Halign,
+		&i.Valign,
+		&i.ContentData,
+		&i.Overrides,
+		&i.IsActive,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.CustomStyling,
+		&i.EmbedData,
+		&i.AutoEmbed,
+		&i.Pinned,
+		&i.PinOrder,
+		&i.PublishAt,
+		&i.UnpublishAt,
+		&i.DeletedAt,
+	)
+	return &i, err
 }
 
-const getContentItem = `-- name: GetContentItem :one
-SELECT item_id, user_id, content_id, content_type, title, href, url, media_type, desktop_x, desktop_y, desktop_style, mobile_x, mobile_y, mobile_style, halign, valign, content_data, overrides, is_active, created_at, updated_at, custom_styling, embed_data, auto_embed FROM content_items
-WHERE item_id = $1 LIMIT 1
+const getContentItemsByIDs = `-- name: GetContentItemsByIDs :many
+SELECT item_id, user_id, content_id, content_type, title, href, url, media_type, desktop_x, desktop_y, desktop_style, mobile_x, mobile_y, mobile_style, halign, valign, content_data, overrides, is_active, created_at, updated_at, custom_styling, embed_data, auto_embed, pinned, pin_order, publish_at, unpublish_at, deleted_at FROM content_items
+WHERE item_id = ANY($1::uuid[]) AND deleted_at IS NULL
 `
 
-func (q *Queries) GetContentItem(ctx context.Context, itemID uuid.UUID) (*ContentItem, error) {
-	row := q.db.QueryRow(ctx, getContentItem, itemID)
+func (q *Queries) GetContentItemsByIDs(ctx context.Context, itemIds []uuid.UUID) ([]*ContentItem, error) {
+	rows, err := q.db.Query(ctx, getContentItemsByIDs, itemIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []*ContentItem
+	for rows.Next() {
+		var i ContentItem
+		if err := rows.Scan(
+			&i.ItemID,
+			&i.UserID,
+			&i.ContentID,
+			&i.ContentType,
+			&i.Title,
+			&i.Href,
+			&i.Url,
+			&i.MediaType,
+			&i.DesktopX,
+			&i.DesktopY,
+			&i.DesktopStyle,
+			&i.MobileX,
+			&i.MobileY,
+			&i.MobileStyle,
+			&i.Halign,
+			&i.Valign,
+			&i.ContentData,
+			&i.Overrides,
+			&i.IsActive,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.CustomStyling,
+			&i.EmbedData,
+			&i.AutoEmbed,
+			&i.Pinned,
+			&i.PinOrder,
+			&i.PublishAt,
+			&i.UnpublishAt,
+			&i.DeletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getContentItemsOwnership = `-- name: GetContentItemsOwnership :many
+SELECT item_id, user_id FROM content_items
+WHERE item_id = ANY($1::uuid[]) AND deleted_at IS NULL
+`
+
+type GetContentItemsOwnershipRow struct {
+	ItemID uuid.UUID `json:"item_id"`
+	UserID uuid.UUID `json:"user_id"`
+}
+
+func (q *Queries) GetContentItemsOwnership(ctx context.Context, itemIds []uuid.UUID) ([]*GetContentItemsOwnershipRow, error) {
+	rows, err := q.db.Query(ctx, getContentItemsOwnership, itemIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []*GetContentItemsOwnershipRow
+	for rows.Next() {
+		var i GetContentItemsOwnershipRow
+		if err := rows.Scan(&i.ItemID, &i.UserID); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getDeletedContentItem = `-- name: GetDeletedContentItem :one
+SELECT item_id, user_id, content_id, content_type, title, href, url, media_type, desktop_x, desktop_y, desktop_style, mobile_x, mobile_y, mobile_style, halign, valign, content_data, overrides, is_active, created_at, updated_at, custom_styling, embed_data, auto_embed, pinned, pin_order, publish_at, unpublish_at, deleted_at FROM content_items
+WHERE item_id = $1 AND deleted_at IS NOT NULL LIMIT 1
+`
+
+func (q *Queries) GetDeletedContentItem(ctx context.Context, itemID uuid.UUID) (*ContentItem, error) {
+	row := q.db.QueryRow(ctx, getDeletedContentItem, itemID)
 	var i ContentItem
 	err := row.Scan(
 		&i.ItemID,
@@ -137,13 +445,77 @@ func (q *Queries) GetContentItem(ctx context.Context, itemID uuid.UUID) (*Conten
 		&i.CustomStyling,
 		&i.EmbedData,
 		&i.AutoEmbed,
+		&i.Pinned,
+		&i.PinOrder,
+		&i.PublishAt,
+		&i.UnpublishAt,
+		&i.DeletedAt,
 	)
 	return &i, err
 }
 
+const getPublishedUserContentItems = `-- name: GetPublishedUserContentItems :many
+SELECT item_id, user_id, content_id, content_type, title, href, url, media_type, desktop_x, desktop_y, desktop_style, mobile_x, mobile_y, mobile_style, halign, valign, content_data, overrides, is_active, created_at, updated_at, custom_styling, embed_data, auto_embed, pinned, pin_order, publish_at, unpublish_at, deleted_at FROM content_items
+WHERE user_id = $1 AND deleted_at IS NULL
+  AND (publish_at IS NULL OR publish_at <= CURRENT_TIMESTAMP)
+  AND (unpublish_at IS NULL OR unpublish_at > CURRENT_TIMESTAMP)
+ORDER BY created_at DESC
+`
+
+// Only the items inside their publish window, for showing to visitors
+func (q *Queries) GetPublishedUserContentItems(ctx context.Context, userID uuid.UUID) ([]*ContentItem, error) {
+	rows, err := q.db.Query(ctx, getPublishedUserContentItems, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []*ContentItem
+	for rows.Next() {
+		var i ContentItem
+		if err := rows.Scan(
+			&i.ItemID,
+			&i.UserID,
+			&i.ContentID,
+			&i.ContentType,
+			&i.Title,
+			&i.Href,
+			&i.Url,
+			&i.MediaType,
+			&i.DesktopX,
+			&i.DesktopY,
+			&i.DesktopStyle,
+			&i.MobileX,
+			&i.MobileY,
+			&i.MobileStyle,
+			&i.Halign,
+			&i.Valign,
+			&i.ContentData,
+			&i.Overrides,
+			&i.IsActive,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.CustomStyling,
+			&i.EmbedData,
+			&i.AutoEmbed,
+			&i.Pinned,
+			&i.PinOrder,
+			&i.PublishAt,
+			&i.UnpublishAt,
+			&i.DeletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getUserContentItems = `-- name: GetUserContentItems :many
-SELECT item_id, user_id, content_id, content_type, title, href, url, media_type, desktop_x, desktop_y, desktop_style, mobile_x, mobile_y, mobile_style, halign, valign, content_data, overrides, is_active, created_at, updated_at, custom_styling, embed_data, auto_embed FROM content_items
-WHERE user_id = $1
+SELECT item_id, user_id, content_id, content_type, title, href, url, media_type, desktop_x, desktop_y, desktop_style, mobile_x, mobile_y, mobile_style, halign, valign, content_data, overrides, is_active, created_at, updated_at, custom_styling, embed_data, auto_embed, pinned, pin_order, publish_at, unpublish_at, deleted_at FROM content_items
+WHERE user_id = $1 AND deleted_at IS NULL
 ORDER BY created_at DESC
 `
 
@@ -181,6 +553,68 @@ func (q *Queries) GetUserContentItems(ctx context.Context, userID uuid.UUID) ([]
 			&i.CustomStyling,
 			&i.EmbedData,
 			&i.AutoEmbed,
+			&i.Pinned,
+			&i.PinOrder,
+			&i.PublishAt,
+			&i.UnpublishAt,
+			&i.DeletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listDeletedUserContentItems = `-- name: ListDeletedUserContentItems :many
+SELECT item_id, user_id, content_id, content_type, title, href, url, media_type, desktop_x, desktop_y, desktop_style, mobile_x, mobile_y, mobile_style, halign, valign, content_data, overrides, is_active, created_at, updated_at, custom_styling, embed_data, auto_embed, pinned, pin_order, publish_at, unpublish_at, deleted_at FROM content_items
+WHERE user_id = $1 AND deleted_at IS NOT NULL
+ORDER BY deleted_at DESC
+`
+
+// The user's trash, most recently deleted first
+func (q *Queries) ListDeletedUserContentItems(ctx context.Context, userID uuid.UUID) ([]*ContentItem, error) {
+	rows, err := q.db.Query(ctx, listDeletedUserContentItems, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []*ContentItem
+	for rows.Next() {
+		var i ContentItem
+		if err := rows.Scan(
+			&i.ItemID,
+			&i.UserID,
+			&i.ContentID,
+			&i.ContentType,
+			&i.Title,
+			&i.Href,
+			&i.Url,
+			&i.MediaType,
+			&i.DesktopX,
+			&i.DesktopY,
+			&i.DesktopStyle,
+			&i.MobileX,
+			&i.MobileY,
+			&i.MobileStyle,
+			&i.Halign,
+			&i.Valign,
+			&i.ContentData,
+			&i.Overrides,
+			&i.IsActive,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.CustomStyling,
+			&i.EmbedData,
+			&i.AutoEmbed,
+			&i.Pinned,
+			&i.PinOrder,
+			&i.PublishAt,
+			&i.UnpublishAt,
+			&i.DeletedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -192,6 +626,110 @@ func (q *Queries) GetUserContentItems(ctx context.Context, userID uuid.UUID) ([]
 	return items, nil
 }
 
+const purgeDeletedContentItems = `-- name: PurgeDeletedContentItems :execrows
+DELETE FROM content_items
+WHERE item_id IN (
+    SELECT item_id FROM content_items
+    WHERE deleted_at < $1::timestamptz
+    LIMIT $2
+)
+`
+
+type PurgeDeletedContentItemsParams struct {
+	Cutoff    time.Time `json:"cutoff"`
+	BatchSize int32     `json:"batch_size"`
+}
+
+func (q *Queries) PurgeDeletedContentItems(ctx context.Context, arg PurgeDeletedContentItemsParams) (int64, error) {
+	result, err := q.db.Exec(ctx, purgeDeletedContentItems, arg.Cutoff, arg.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const reorderContentItems = `-- name: ReorderContentItems :execrows
+UPDATE content_items
+SET
+    desktop_x = p.desktop_x,
+    desktop_y = p.desktop_y,
+    mobile_x = p.mobile_x,
+    mobile_y = p.mobile_y,
+    updated_at = CURRENT_TIMESTAMP
+FROM unnest(
+    $1::uuid[],
+    $2::int[],
+    $3::int[],
+    $4::int[],
+    $5::int[]
+) AS p(item_id, desktop_x, desktop_y, mobile_x, mobile_y)
+WHERE content_items.item_id = p.item_id
+AND content_items.user_id = $6
+AND content_items.deleted_at IS NULL
+AND (
+    SELECT COUNT(*) FROM content_items owned
+    WHERE owned.item_id = ANY($1::uuid[])
+    AND owned.user_id = $6
+    AND owned.deleted_at IS NULL
+) = cardinality($1::uuid[])
+`
+
+type ReorderContentItemsParams struct {
+	ItemIds  []uuid.UUID `json:"item_ids"`
+	DesktopX []int32     `json:"desktop_x"`
+	DesktopY []int32     `json:"desktop_y"`
+	MobileX  []int32     `json:"mobile_x"`
+	MobileY  []int32     `json:"mobile_y"`
+	UserID   uuid.UUID   `json:"user_id"`
+}
+
+// Moves every listed item in one statement, and only if the user owns all
+// of them, so a layout is never left half applied.
+func (q *Queries) ReorderContentItems(ctx context.Context, arg ReorderContentItemsParams) (int64, error) {
+	result, err := q.db.Exec(ctx, reorderContentItems,
+		arg.ItemIds,
+		arg.DesktopX,
+		arg.DesktopY,
+		arg.MobileX,
+		arg.MobileY,
+		arg.UserID,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const restoreContentItem = `-- name: RestoreContentItem :execrows
+UPDATE content_items
+SET deleted_at = NULL, updated_at = CURRENT_TIMESTAMP
+WHERE item_id = $1 AND deleted_at IS NOT NULL
+`
+
+func (q *Queries) RestoreContentItem(ctx context.Context, itemID uuid.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, restoreContentItem, itemID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const softDeleteContentItem = `-- name: SoftDeleteContentItem :execrows
+UPDATE content_items
+SET deleted_at = CURRENT_TIMESTAMP
+WHERE item_id = $1 AND deleted_at IS NULL
+`
+
+// Moves an item to the trash, from where it can be restored until it's
+// purged
+func (q *Queries) SoftDeleteContentItem(ctx context.Context, itemID uuid.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, softDeleteContentItem, itemID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const updateContentItem = `-- name: UpdateContentItem :exec
 UPDATE content_items
 SET
@@ -206,8 +744,12 @@ SET
     content_data = COALESCE($10, content_data),
     overrides = COALESCE($11, overrides),
     is_active = COALESCE($12, is_active),
+    pinned = COALESCE($13, pinned),
+    pin_order = COALESCE($14, pin_order),
+    publish_at = COALESCE($15, publish_at),
+    unpublish_at = COALESCE($16, unpublish_at),
     updated_at = CURRENT_TIMESTAMP
-WHERE item_id = $1
+WHERE item_id = $1 AND deleted_at IS NULL
 `
 
 type UpdateContentItemParams struct {
@@ -223,6 +765,10 @@ type UpdateContentItemParams struct {
 	ContentData  pgtype.JSONB `json:"content_data"`
 	Overrides    pgtype.JSONB `json:"overrides"`
 	IsActive     *bool        `json:"is_active"`
+	Pinned       *bool        `json:"pinned"`
+	PinOrder     *int32       `json:"pin_order"`
+	PublishAt    *time.Time   `json:"publish_at"`
+	UnpublishAt  *time.Time   `json:"unpublish_at"`
 }
 
 func (q *Queries) UpdateContentItem(ctx context.Context, arg UpdateContentItemParams) error {
@@ -239,10 +785,34 @@ func (q *Queries) UpdateContentItem(ctx context.Context, arg UpdateContentItemPa
 		arg.ContentData,
 		arg.Overrides,
 		arg.IsActive,
+		arg.Pinned,
+		arg.PinOrder,
+		arg.PublishAt,
+		arg.UnpublishAt,
 	)
 	return err
 }
 
+const updateContentItemPin = `-- name: UpdateContentItemPin :exec
+UPDATE content_items
+SET
+    pinned = $2,
+    pin_order = $3,
+    updated_at = CURRENT_TIMESTAMP
+WHERE item_id = $1 AND deleted_at IS NULL
+`
+
+type UpdateContentItemPinParams struct {
+	ItemID   uuid.UUID `json:"item_id"`
+	Pinned   bool      `json:"pinned"`
+	PinOrder int32     `json:"pin_order"`
+}
+
+func (q *Queries) UpdateContentItemPin(ctx context.Context, arg UpdateContentItemPinParams) error {
+	_, err := q.db.Exec(ctx, updateContentItemPin, arg.ItemID, arg.Pinned, arg.PinOrder)
+	return err
+}
+
 const updateContentItemPosition = `-- name: UpdateContentItemPosition :exec
 UPDATE content_items
 SET
@@ -251,7 +821,7 @@ SET
     mobile_x = COALESCE($4, mobile_x),
     mobile_y = COALESCE($5, mobile_y),
     updated_at = CURRENT_TIMESTAMP
-WHERE item_id = $1
+WHERE item_id = $1 AND deleted_at IS NULL
 `
 
 type UpdateContentItemPositionParams struct {
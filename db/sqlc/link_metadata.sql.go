@@ -7,6 +7,7 @@ package db
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -14,10 +15,11 @@ import (
 const createLinkMetadata = `-- name: CreateLinkMetadata :one
 INSERT INTO link_metadata (
     domain, url, title, description, favicon_url, image_url,
-    platform_name, platform_type, platform_color, is_verified
+    platform_name, platform_type, platform_color, is_verified, image_source, fetch_failed,
+    canonical_url
 ) VALUES (
-    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10
-) RETURNING metadata_id, domain, url, title, description, favicon_url, image_url, platform_name, platform_type, platform_color, is_verified, created_at, updated_at
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13
+) RETURNING metadata_id, domain, url, title, description, favicon_url, image_url, platform_name, platform_type, platform_color, is_verified, created_at, updated_at, image_source, fetch_failed, canonical_url
 `
 
 type CreateLinkMetadataParams struct {
@@ -31,6 +33,9 @@ type CreateLinkMetadataParams struct {
 	PlatformType  *string `json:"platform_type"`
 	PlatformColor *string `json:"platform_color"`
 	IsVerified    *bool   `json:"is_verified"`
+	ImageSource   *string `json:"image_source"`
+	FetchFailed   bool    `json:"fetch_failed"`
+	CanonicalUrl  *string `json:"canonical_url"`
 }
 
 func (q *Queries) CreateLinkMetadata(ctx context.Context, arg CreateLinkMetadataParams) (*LinkMetadatum, error) {
@@ -45,6 +50,9 @@ func (q *Queries) CreateLinkMetadata(ctx context.Context, arg CreateLinkMetadata
 		arg.PlatformType,
 		arg.PlatformColor,
 		arg.IsVerified,
+		arg.ImageSource,
+		arg.FetchFailed,
+		arg.CanonicalUrl,
 	)
 	var i LinkMetadatum
 	err := row.Scan(
@@ -61,6 +69,9 @@ func (q *Queries) CreateLinkMetadata(ctx context.Context, arg CreateLinkMetadata
 		&i.IsVerified,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.ImageSource,
+		&i.FetchFailed,
+		&i.CanonicalUrl,
 	)
 	return &i, err
 }
@@ -76,7 +87,7 @@ func (q *Queries) DeleteLinkMetadata(ctx context.Context, metadataID uuid.UUID)
 }
 
 const getLinkMetadataByDomain = `-- name: GetLinkMetadataByDomain :many
-SELECT metadata_id, domain, url, title, description, favicon_url, image_url, platform_name, platform_type, platform_color, is_verified, created_at, updated_at FROM link_metadata
+SELECT metadata_id, domain, url, title, description, favicon_url, image_url, platform_name, platform_type, platform_color, is_verified, created_at, updated_at, image_source, fetch_failed, canonical_url FROM link_metadata
 WHERE domain = $1
 ORDER BY created_at DESC
 `
@@ -104,6 +115,9 @@ func (q *Queries) GetLinkMetadataByDomain(ctx context.Context, domain string) ([
 			&i.IsVerified,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.ImageSource,
+			&i.FetchFailed,
+			&i.CanonicalUrl,
 		); err != nil {
 			return nil, err
 		}
@@ -116,7 +130,7 @@ func (q *Queries) GetLinkMetadataByDomain(ctx context.Context, domain string) ([
 }
 
 const getLinkMetadataByURL = `-- name: GetLinkMetadataByURL :one
-SELECT metadata_id, domain, url, title, description, favicon_url, image_url, platform_name, platform_type, platform_color, is_verified, created_at, updated_at FROM link_metadata
+SELECT metadata_id, domain, url, title, description, favicon_url, image_url, platform_name, platform_type, platform_color, is_verified, created_at, updated_at, image_source, fetch_failed, canonical_url FROM link_metadata
 WHERE url = $1 LIMIT 1
 `
 
@@ -137,10 +151,45 @@ func (q *Queries) GetLinkMetadataByURL(ctx context.Context, url string) (*LinkMe
 		&i.IsVerified,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.ImageSource,
+		&i.FetchFailed,
+		&i.CanonicalUrl,
 	)
 	return &i, err
 }
 
+const getLinkMetadataFreshness = `-- name: GetLinkMetadataFreshness :many
+SELECT url, COALESCE(updated_at, created_at)::timestamptz AS updated_at, fetch_failed
+FROM link_metadata
+WHERE url = ANY($1::text[])
+`
+
+type GetLinkMetadataFreshnessRow struct {
+	Url         string    `json:"url"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	FetchFailed bool      `json:"fetch_failed"`
+}
+
+func (q *Queries) GetLinkMetadataFreshness(ctx context.Context, urls []string) ([]*GetLinkMetadataFreshnessRow, error) {
+	rows, err := q.db.Query(ctx, getLinkMetadataFreshness, urls)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []*GetLinkMetadataFreshnessRow
+	for rows.Next() {
+		var i GetLinkMetadataFreshnessRow
+		if err := rows.Scan(&i.Url, &i.UpdatedAt, &i.FetchFailed); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const updateLinkMetadata = `-- name: UpdateLinkMetadata :one
 UPDATE link_metadata
 SET
@@ -152,9 +201,12 @@ SET
     platform_type = COALESCE($7, platform_type),
     platform_color = COALESCE($8, platform_color),
     is_verified = COALESCE($9, is_verified),
+    image_source = COALESCE($10, image_source),
+    fetch_failed = $11,
+    canonical_url = COALESCE($12, canonical_url),
     updated_at = CURRENT_TIMESTAMP
 WHERE url = $1
-RETURNING metadata_id, domain, url, title, description, favicon_url, image_url, platform_name, platform_type, platform_color, is_verified, created_at, updated_at
+RETURNING metadata_id, domain, url, title, description, favicon_url, image_url, platform_name, platform_type, platform_color, is_verified, created_at, updated_at, image_source, fetch_failed, canonical_url
 `
 
 type UpdateLinkMetadataParams struct {
@@ -167,6 +219,9 @@ type UpdateLinkMetadataParams struct {
 	PlatformType  *string `json:"platform_type"`
 	PlatformColor *string `json:"platform_color"`
 	IsVerified    *bool   `json:"is_verified"`
+	ImageSource   *string `json:"image_source"`
+	FetchFailed   bool    `json:"fetch_failed"`
+	CanonicalUrl  *string `json:"canonical_url"`
 }
 
 func (q *Queries) UpdateLinkMetadata(ctx context.Context, arg UpdateLinkMetadataParams) (*LinkMetadatum, error) {
@@ -180,6 +235,9 @@ func (q *Queries) UpdateLinkMetadata(ctx context.Context, arg UpdateLinkMetadata
 		arg.PlatformType,
 		arg.PlatformColor,
 		arg.IsVerified,
+		arg.ImageSource,
+		arg.FetchFailed,
+		arg.CanonicalUrl,
 	)
 	var i LinkMetadatum
 	err := row.Scan(
@@ -196,6 +254,9 @@ func (q *Queries) UpdateLinkMetadata(ctx context.Context, arg UpdateLinkMetadata
 		&i.IsVerified,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.ImageSource,
+		&i.FetchFailed,
+		&i.CanonicalUrl,
 	)
 	return &i, err
 }
@@ -11,38 +11,80 @@ import (
 	"github.com/jackc/pgtype"
 )
 
+type AccountCollaborator struct {
+	OwnerID        uuid.UUID  `json:"owner_id"`
+	CollaboratorID uuid.UUID  `json:"collaborator_id"`
+	CreatedAt      *time.Time `json:"created_at"`
+}
+
 type Analytic struct {
-	AnalyticsID uuid.UUID  `json:"analytics_id"`
-	ItemID      uuid.UUID  `json:"item_id"`
-	UserID      uuid.UUID  `json:"user_id"`
-	IpAddress   *string    `json:"ip_address"`
-	UserAgent   *string    `json:"user_agent"`
-	Referrer    *string    `json:"referrer"`
-	ClickedAt   *time.Time `json:"clicked_at"`
-	PageView    *bool      `json:"page_view"`
-	Country     *string    `json:"country"`
-	DeviceType  *string    `json:"device_type"`
-	Browser     *string    `json:"browser"`
-	UtmSource   *string    `json:"utm_source"`
-	UtmMedium   *string    `json:"utm_medium"`
-	UtmCampaign *string    `json:"utm_campaign"`
+	AnalyticsID     uuid.UUID  `json:"analytics_id"`
+	ItemID          uuid.UUID  `json:"item_id"`
+	UserID          uuid.UUID  `json:"user_id"`
+	IpAddress       *string    `json:"ip_address"`
+	UserAgent       *string    `json:"user_agent"`
+	Referrer        *string    `json:"referrer"`
+	ClickedAt       *time.Time `json:"clicked_at"`
+	PageView        *bool      `json:"page_view"`
+	Country         *string    `json:"country"`
+	DeviceType      *string    `json:"device_type"`
+	Browser         *string    `json:"browser"`
+	UtmSource       *string    `json:"utm_source"`
+	UtmMedium       *string    `json:"utm_medium"`
+	UtmCampaign     *string    `json:"utm_campaign"`
+	InteractionType string     `json:"interaction_type"`
+	AnonymizedAt    *time.Time `json:"anonymized_at"`
+	IsBot           bool       `json:"is_bot"`
+}
+
+type AnalyticsDailyRollup struct {
+	UserID         uuid.UUID  `json:"user_id"`
+	ItemID         uuid.UUID  `json:"item_id"`
+	Day            time.Time  `json:"day"`
+	Clicks         int64      `json:"clicks"`
+	PageViews      int64      `json:"page_views"`
+	UniqueVisitors int64      `json:"unique_visitors"`
+	UpdatedAt      *time.Time `json:"updated_at"`
+}
+
+type AuditLog struct {
+	AuditID   uuid.UUID    `json:"audit_id"`
+	ActorID   *uuid.UUID   `json:"actor_id"`
+	Action    string       `json:"action"`
+	TargetID  *uuid.UUID   `json:"target_id"`
+	Details   pgtype.JSONB `json:"details"`
+	CreatedAt *time.Time   `json:"created_at"`
+}
+
+type AuthSession struct {
+	SessionID        uuid.UUID  `json:"session_id"`
+	UserID           uuid.UUID  `json:"user_id"`
+	RefreshTokenHash string     `json:"refresh_token_hash"`
+	UserAgent        *string    `json:"user_agent"`
+	IpAddress        *string    `json:"ip_address"`
+	CreatedAt        *time.Time `json:"created_at"`
+	LastUsedAt       *time.Time `json:"last_used_at"`
+	ExpiresAt        time.Time  `json:"expires_at"`
+	RevokedAt        *time.Time `json:"revoked_at"`
 }
 
 type Auth struct {
 	AuthID              uuid.UUID  `json:"auth_id"`
 	UserID              uuid.UUID  `json:"user_id"`
-	PasswordHash        string     `json:"password_hash"`
-	Salt                string     `json:"salt"`
+	PasswordHash        *string    `json:"password_hash"`
+	Salt                *string    `json:"salt"`
 	IsEmailVerified     *bool      `json:"is_email_verified"`
 	VerificationToken   *string    `json:"verification_token"`
 	ResetToken          *string    `json:"reset_token"`
 	ResetTokenExpiresAt *time.Time `json:"reset_token_expires_at"`
 	LastLogin           *time.Time `json:"last_login"`
-	RefreshToken        *string    `json:"refresh_token"`
 	FailedLoginAttempts *int32     `json:"failed_login_attempts"`
 	LockedUntil         *time.Time `json:"locked_until"`
 	CreatedAt           *time.Time `json:"created_at"`
 	UpdatedAt           *time.Time `json:"updated_at"`
+	TotpSecret          *string    `json:"totp_secret"`
+	TotpEnabled         bool       `json:"totp_enabled"`
+	TotpRecoveryCodes   []string   `json:"totp_recovery_codes"`
 }
 
 type ContentItem struct {
@@ -70,6 +112,42 @@ type ContentItem struct {
 	CustomStyling pgtype.JSONB `json:"custom_styling"`
 	EmbedData     pgtype.JSONB `json:"embed_data"`
 	AutoEmbed     *bool        `json:"auto_embed"`
+	Pinned        bool         `json:"pinned"`
+	PinOrder      int32        `json:"pin_order"`
+	PublishAt     *time.Time   `json:"publish_at"`
+	UnpublishAt   *time.Time   `json:"unpublish_at"`
+	DeletedAt     *time.Time   `json:"deleted_at"`
+}
+
+type ContentHistory struct {
+	HistoryID uuid.UUID    `json:"history_id"`
+	ItemID    uuid.UUID    `json:"item_id"`
+	ActorID   *uuid.UUID   `json:"actor_id"`
+	Source    string       `json:"source"`
+	Action    string       `json:"action"`
+	Changes   pgtype.JSONB `json:"changes"`
+	CreatedAt *time.Time   `json:"created_at"`
+}
+
+type ContentLinkHealth struct {
+	ItemID              uuid.UUID  `json:"item_id"`
+	ConsecutiveFailures int32      `json:"consecutive_failures"`
+	LastStatus          *int32     `json:"last_status"`
+	LastCheckedAt       *time.Time `json:"last_checked_at"`
+	AutoDeactivate      bool       `json:"auto_deactivate"`
+	DeactivatedAt       *time.Time `json:"deactivated_at"`
+}
+
+type ContentRevision struct {
+	RevisionID uuid.UUID    `json:"revision_id"`
+	ItemID     uuid.UUID    `json:"item_id"`
+	OwnerID    uuid.UUID    `json:"owner_id"`
+	AuthorID   uuid.UUID    `json:"author_id"`
+	Changes    pgtype.JSONB `json:"changes"`
+	Status     string       `json:"status"`
+	ReviewedBy *uuid.UUID   `json:"reviewed_by"`
+	ReviewedAt *time.Time   `json:"reviewed_at"`
+	CreatedAt  *time.Time   `json:"created_at"`
 }
 
 type Conversion struct {
@@ -89,6 +167,44 @@ type EmbedConfig struct {
 	CreatedAt     *time.Time `json:"created_at"`
 }
 
+type File struct {
+	FileKey     string     `json:"file_key"`
+	UserID      uuid.UUID  `json:"user_id"`
+	Category    string     `json:"category"`
+	Size        int64      `json:"size"`
+	ContentType string     `json:"content_type"`
+	CreatedAt   *time.Time `json:"created_at"`
+}
+
+type HandleHistory struct {
+	Handle    string     `json:"handle"`
+	UserID    uuid.UUID  `json:"user_id"`
+	ChangedAt *time.Time `json:"changed_at"`
+}
+
+type HandleTransfer struct {
+	Handle     string     `json:"handle"`
+	FromUserID uuid.UUID  `json:"from_user_id"`
+	ToUserID   uuid.UUID  `json:"to_user_id"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	CreatedAt  *time.Time `json:"created_at"`
+}
+
+type InviteCode struct {
+	Code      string     `json:"code"`
+	MaxUses   int32      `json:"max_uses"`
+	UsedCount int32      `json:"used_count"`
+	ExpiresAt *time.Time `json:"expires_at"`
+	CreatedBy *uuid.UUID `json:"created_by"`
+	CreatedAt *time.Time `json:"created_at"`
+}
+
+type InviteCodeRedemption struct {
+	Code       string     `json:"code"`
+	UserID     uuid.UUID  `json:"user_id"`
+	RedeemedAt *time.Time `json:"redeemed_at"`
+}
+
 type LinkMetadatum struct {
 	MetadataID    uuid.UUID  `json:"metadata_id"`
 	Domain        string     `json:"domain"`
@@ -103,6 +219,9 @@ type LinkMetadatum struct {
 	IsVerified    *bool      `json:"is_verified"`
 	CreatedAt     *time.Time `json:"created_at"`
 	UpdatedAt     *time.Time `json:"updated_at"`
+	ImageSource   *string    `json:"image_source"`
+	FetchFailed   bool       `json:"fetch_failed"`
+	CanonicalUrl  *string    `json:"canonical_url"`
 }
 
 type OauthAccount struct {
@@ -119,6 +238,41 @@ type OauthAccount struct {
 	UpdatedAt      *time.Time `json:"updated_at"`
 }
 
+type Platform struct {
+	Domain         string     `json:"domain"`
+	Name           string     `json:"name"`
+	Type           string     `json:"type"`
+	Color          string     `json:"color"`
+	Icon           string     `json:"icon"`
+	UrlTemplate    string     `json:"url_template"`
+	ScrapeStrategy string     `json:"scrape_strategy"`
+	OembedEndpoint string     `json:"oembed_endpoint"`
+	UserAgent      string     `json:"user_agent"`
+	CreatedAt      *time.Time `json:"created_at"`
+	UpdatedAt      *time.Time `json:"updated_at"`
+	QueryKeys      []string   `json:"query_keys"`
+}
+
+type RefreshToken struct {
+	TokenID    uuid.UUID  `json:"token_id"`
+	SessionID  uuid.UUID  `json:"session_id"`
+	UserID     uuid.UUID  `json:"user_id"`
+	IssuedAt   *time.Time `json:"issued_at"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	ConsumedAt *time.Time `json:"consumed_at"`
+}
+
+type ReportSubscription struct {
+	SubscriptionID   uuid.UUID  `json:"subscription_id"`
+	UserID           uuid.UUID  `json:"user_id"`
+	Frequency        string     `json:"frequency"`
+	Metrics          []string   `json:"metrics"`
+	UnsubscribeToken string     `json:"unsubscribe_token"`
+	NextSendAt       time.Time  `json:"next_send_at"`
+	LastSentAt       *time.Time `json:"last_sent_at"`
+	CreatedAt        *time.Time `json:"created_at"`
+}
+
 type Theme struct {
 	ThemeID         uuid.UUID    `json:"theme_id"`
 	Name            string       `json:"name"`
@@ -131,23 +285,41 @@ type Theme struct {
 }
 
 type User struct {
-	UserID             uuid.UUID    `json:"user_id"`
-	Username           string       `json:"username"`
-	Handle             string       `json:"handle"`
-	Email              string       `json:"email"`
-	FirstName          *string      `json:"first_name"`
-	LastName           *string      `json:"last_name"`
-	Bio                *string      `json:"bio"`
-	ProfileImageUrl    *string      `json:"profile_image_url"`
-	LayoutVersion      *string      `json:"layout_version"`
-	CustomDomain       *string      `json:"custom_domain"`
-	IsPremium          *bool        `json:"is_premium"`
-	IsAdmin            *bool        `json:"is_admin"`
-	Onboarded          *bool        `json:"onboarded"`
-	CreatedAt          *time.Time   `json:"created_at"`
-	UpdatedAt          *time.Time   `json:"updated_at"`
-	ThemeID            *uuid.UUID   `json:"theme_id"`
-	ThemeCustomization pgtype.JSONB `json:"theme_customization"`
+	UserID                  uuid.UUID    `json:"user_id"`
+	Username                string       `json:"username"`
+	Handle                  string       `json:"handle"`
+	Email                   string       `json:"email"`
+	FirstName               *string      `json:"first_name"`
+	LastName                *string      `json:"last_name"`
+	Bio                     *string      `json:"bio"`
+	ProfileImageUrl         *string      `json:"profile_image_url"`
+	LayoutVersion           *string      `json:"layout_version"`
+	CustomDomain            *string      `json:"custom_domain"`
+	IsPremium               *bool        `json:"is_premium"`
+	IsAdmin                 *bool        `json:"is_admin"`
+	Onboarded               *bool        `json:"onboarded"`
+	CreatedAt               *time.Time   `json:"created_at"`
+	UpdatedAt               *time.Time   `json:"updated_at"`
+	ThemeID                 *uuid.UUID   `json:"theme_id"`
+	ThemeCustomization      pgtype.JSONB `json:"theme_customization"`
+	RequiresContentApproval bool         `json:"requires_content_approval"`
+	IsTemplate              bool         `json:"is_template"`
+	PurgeWarnedAt           *time.Time   `json:"purge_warned_at"`
+	AnalyticsEnabled        bool         `json:"analytics_enabled"`
+	Locale                  *string      `json:"locale"`
+	CustomDomainToken       *string      `json:"custom_domain_token"`
+	CustomDomainVerifiedAt  *time.Time   `json:"custom_domain_verified_at"`
+	Visibility              string       `json:"visibility"`
+	DeletedAt               *time.Time   `json:"deleted_at"`
+}
+
+type UserAvatar struct {
+	AvatarID  uuid.UUID  `json:"avatar_id"`
+	UserID    uuid.UUID  `json:"user_id"`
+	FileKey   string     `json:"file_key"`
+	Url       string     `json:"url"`
+	IsActive  bool       `json:"is_active"`
+	CreatedAt *time.Time `json:"created_at"`
 }
 
 type UserTheme struct {
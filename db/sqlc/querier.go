@@ -6,68 +6,206 @@ package db
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 )
 
 type Querier interface {
+	ActivateUserAvatar(ctx context.Context, arg ActivateUserAvatarParams) (int64, error)
+	AddAccountCollaborator(ctx context.Context, arg AddAccountCollaboratorParams) error
+	AnonymizeAnalytics(ctx context.Context, arg AnonymizeAnalyticsParams) (int64, error)
+	BulkUpdateContentStyle(ctx context.Context, arg BulkUpdateContentStyleParams) (int64, error)
+	ClaimHandleTransfer(ctx context.Context, arg ClaimHandleTransferParams) (int64, error)
+	// Moves a due subscription on to its next send time. Only the caller whose
+	// update lands sends the report, so instances never send it twice.
+	ClaimReportSubscription(ctx context.Context, arg ClaimReportSubscriptionParams) (int64, error)
 	ClearResetToken(ctx context.Context, userID uuid.UUID) error
 	ClearVerificationToken(ctx context.Context, userID uuid.UUID) error
+	ConsumeRecoveryCode(ctx context.Context, arg ConsumeRecoveryCodeParams) (int64, error)
+	ConsumeRefreshToken(ctx context.Context, tokenID uuid.UUID) (int64, error)
+	CopyContentItems(ctx context.Context, arg CopyContentItemsParams) (int64, error)
+	CountActiveUserContentItems(ctx context.Context, userID uuid.UUID) (int64, error)
+	CountContentHistory(ctx context.Context, itemID uuid.UUID) (int64, error)
+	CountPinnedUserContentItems(ctx context.Context, arg CountPinnedUserContentItemsParams) (int64, error)
+	CountUserAvatars(ctx context.Context, userID uuid.UUID) (int64, error)
+	CountUserContentItemsByType(ctx context.Context, userID uuid.UUID) ([]*CountUserContentItemsByTypeRow, error)
+	CountUserContentItemsByTypeAndState(ctx context.Context, userID uuid.UUID) ([]*CountUserContentItemsByTypeAndStateRow, error)
+	CountUserFiles(ctx context.Context, arg CountUserFilesParams) (int64, error)
+	CountUsers(ctx context.Context, arg CountUsersParams) (int64, error)
 	// db/query/analytics.sql
 	// Recording clicks and page views
+	CreateAnalyticsEntries(ctx context.Context, arg CreateAnalyticsEntriesParams) (int64, error)
 	CreateAnalyticsEntry(ctx context.Context, arg CreateAnalyticsEntryParams) (*Analytic, error)
+	CreateAuditLogEntry(ctx context.Context, arg CreateAuditLogEntryParams) error
 	CreateAuth(ctx context.Context, arg CreateAuthParams) error
+	CreateAuthSession(ctx context.Context, arg CreateAuthSessionParams) (*AuthSession, error)
+	CreateContentHistoryEntry(ctx context.Context, arg CreateContentHistoryEntryParams) error
 	CreateContentItem(ctx context.Context, arg CreateContentItemParams) (*ContentItem, error)
+	CreateContentRevision(ctx context.Context, arg CreateContentRevisionParams) (*ContentRevision, error)
+	CreateFileRecord(ctx context.Context, arg CreateFileRecordParams) (*File, error)
+	CreateInviteCode(ctx context.Context, arg CreateInviteCodeParams) (*InviteCode, error)
 	CreateLinkMetadata(ctx context.Context, arg CreateLinkMetadataParams) (*LinkMetadatum, error)
+	CreateOAuthAccount(ctx context.Context, arg CreateOAuthAccountParams) (*OauthAccount, error)
 	CreatePageViewEntry(ctx context.Context, arg CreatePageViewEntryParams) (*Analytic, error)
+	CreatePlatform(ctx context.Context, arg CreatePlatformParams) (*Platform, error)
+	CreateReportSubscription(ctx context.Context, arg CreateReportSubscriptionParams) (*ReportSubscription, error)
 	CreateUser(ctx context.Context, arg CreateUserParams) (*User, error)
-	DeleteContentItem(ctx context.Context, itemID uuid.UUID) error
+	CreateUserAvatar(ctx context.Context, arg CreateUserAvatarParams) (*UserAvatar, error)
+	DeactivateDeadLink(ctx context.Context, itemID uuid.UUID) (int64, error)
+	DeleteAnalyticsOlderThan(ctx context.Context, arg DeleteAnalyticsOlderThanParams) (int64, error)
+	DeleteAnalyticsRollups(ctx context.Context, arg DeleteAnalyticsRollupsParams) error
+	DeleteExpiredAnalytics(ctx context.Context, arg DeleteExpiredAnalyticsParams) (int64, error)
+	DeleteFileRecord(ctx context.Context, fileKey string) error
 	DeleteLinkMetadata(ctx context.Context, metadataID uuid.UUID) error
+	DeletePlatform(ctx context.Context, domain string) (int64, error)
+	DeleteReportSubscription(ctx context.Context, arg DeleteReportSubscriptionParams) (int64, error)
+	DeleteReportSubscriptionByToken(ctx context.Context, unsubscribeToken string) (int64, error)
 	DeleteUser(ctx context.Context, userID uuid.UUID) error
+	EnableTOTP(ctx context.Context, userID uuid.UUID) error
 	GetAuthByUserID(ctx context.Context, userID uuid.UUID) (*Auth, error)
 	GetAuthByVerificationToken(ctx context.Context, verificationToken *string) (*Auth, error)
+	GetAuthSession(ctx context.Context, sessionID uuid.UUID) (*AuthSession, error)
+	// Campaign analytics
+	GetCampaignCounts(ctx context.Context, arg GetCampaignCountsParams) ([]*GetCampaignCountsRow, error)
 	GetContentItem(ctx context.Context, itemID uuid.UUID) (*ContentItem, error)
 	// Count queries
 	GetContentItemClickCount(ctx context.Context, itemID uuid.UUID) (int64, error)
+	GetContentItemUniqueClickCount(ctx context.Context, arg GetContentItemUniqueClickCountParams) (int64, error)
+	GetContentItemsByIDs(ctx context.Context, itemIds []uuid.UUID) ([]*ContentItem, error)
+	GetContentItemsOwnership(ctx context.Context, itemIds []uuid.UUID) ([]*GetContentItemsOwnershipRow, error)
+	GetDeletedContentItem(ctx context.Context, itemID uuid.UUID) (*ContentItem, error)
+	GetContentRevision(ctx context.Context, revisionID uuid.UUID) (*ContentRevision, error)
+	GetFileRecord(ctx context.Context, fileKey string) (*File, error)
+	GetHandleHistoryOwner(ctx context.Context, handle string) (uuid.UUID, error)
+	// Geographic analytics
+	GetIPAddressCounts(ctx context.Context, arg GetIPAddressCountsParams) ([]*GetIPAddressCountsRow, error)
+	GetInviteCode(ctx context.Context, code string) (*InviteCode, error)
 	// Basic analytics queries
 	GetItemAnalytics(ctx context.Context, arg GetItemAnalyticsParams) ([]*Analytic, error)
 	GetItemAnalyticsByTimeRange(ctx context.Context, arg GetItemAnalyticsByTimeRangeParams) ([]*GetItemAnalyticsByTimeRangeRow, error)
+	GetItemInteractionBreakdown(ctx context.Context, itemID uuid.UUID) ([]*GetItemInteractionBreakdownRow, error)
 	GetLinkMetadataByDomain(ctx context.Context, domain string) ([]*LinkMetadatum, error)
 	GetLinkMetadataByURL(ctx context.Context, url string) (*LinkMetadatum, error)
-	GetProfilePageViews(ctx context.Context, userID uuid.UUID) (int64, error)
+	GetLinkMetadataFreshness(ctx context.Context, urls []string) ([]*GetLinkMetadataFreshnessRow, error)
+	GetOAuthAccount(ctx context.Context, arg GetOAuthAccountParams) (*OauthAccount, error)
+	GetProfilePageViews(ctx context.Context, arg GetProfilePageViewsParams) (int64, error)
 	GetProfilePageViewsByDate(ctx context.Context, arg GetProfilePageViewsByDateParams) ([]*GetProfilePageViewsByDateRow, error)
+	GetProfilePageViewsByDateFromRollups(ctx context.Context, arg GetProfilePageViewsByDateFromRollupsParams) ([]*GetProfilePageViewsByDateFromRollupsRow, error)
+	// Only the items inside their publish window, for showing to visitors
+	GetPublishedUserContentItems(ctx context.Context, userID uuid.UUID) ([]*ContentItem, error)
 	GetReferrerAnalytics(ctx context.Context, arg GetReferrerAnalyticsParams) ([]*GetReferrerAnalyticsRow, error)
+	GetRefreshToken(ctx context.Context, tokenID uuid.UUID) (*RefreshToken, error)
 	// Insight queries
 	GetTopContentItemsByClicks(ctx context.Context, arg GetTopContentItemsByClicksParams) ([]*GetTopContentItemsByClicksRow, error)
 	// Visitor analytics
 	GetUniqueVisitors(ctx context.Context, arg GetUniqueVisitorsParams) (int64, error)
 	GetUniqueVisitorsByDay(ctx context.Context, arg GetUniqueVisitorsByDayParams) ([]*GetUniqueVisitorsByDayRow, error)
 	GetUser(ctx context.Context, userID uuid.UUID) (*User, error)
+	// Device analytics
+	GetUserAgentCounts(ctx context.Context, arg GetUserAgentCountsParams) ([]*GetUserAgentCountsRow, error)
 	GetUserAnalytics(ctx context.Context, arg GetUserAnalyticsParams) ([]*Analytic, error)
 	// Time range analytics
 	GetUserAnalyticsByTimeRange(ctx context.Context, arg GetUserAnalyticsByTimeRangeParams) ([]*GetUserAnalyticsByTimeRangeRow, error)
+	GetUserAvatar(ctx context.Context, arg GetUserAvatarParams) (*UserAvatar, error)
+	GetUserByCustomDomain(ctx context.Context, lower string) (*User, error)
 	GetUserByEmail(ctx context.Context, email string) (*User, error)
+	// Also finds accounts pending deletion, so their owner can sign in to
+	// restore them
+	GetUserByEmailIncludingDeleted(ctx context.Context, email string) (*User, error)
 	GetUserByHandle(ctx context.Context, handle string) (*User, error)
 	GetUserByUsername(ctx context.Context, username string) (*User, error)
+	GetUserClicksForDays(ctx context.Context, arg GetUserClicksForDaysParams) ([]*GetUserClicksForDaysRow, error)
 	GetUserContentItems(ctx context.Context, userID uuid.UUID) ([]*ContentItem, error)
-	GetUserItemClickCount(ctx context.Context, userID uuid.UUID) (int64, error)
-	IncrementFailedLoginAttempts(ctx context.Context, userID uuid.UUID) error
-	InvalidateRefreshToken(ctx context.Context, userID uuid.UUID) error
+	GetUserIncludingDeleted(ctx context.Context, userID uuid.UUID) (*User, error)
+	GetUserItemClickCount(ctx context.Context, arg GetUserItemClickCountParams) (int64, error)
+	GetUserPeriodTotals(ctx context.Context, arg GetUserPeriodTotalsParams) (*GetUserPeriodTotalsRow, error)
+	// Views and clicks for whole days in [rollup_start, rollup_end) come from the
+	// rollups and the rest of the window from raw rows. Unique visitors can't be
+	// summed across days, so they are always counted from raw rows.
+	GetUserPeriodTotalsFromRollups(ctx context.Context, arg GetUserPeriodTotalsFromRollupsParams) (*GetUserPeriodTotalsFromRollupsRow, error)
+	GetUsersByIDs(ctx context.Context, userIds []uuid.UUID) ([]*User, error)
+	GetVerificationStatuses(ctx context.Context, userIds []uuid.UUID) ([]*GetVerificationStatusesRow, error)
+	IncrementFailedLoginAttempts(ctx context.Context, userID uuid.UUID) (*int32, error)
+	InvalidateRefreshTokenFamily(ctx context.Context, sessionID uuid.UUID) error
+	InvalidateUserRefreshTokens(ctx context.Context, userID uuid.UUID) error
+	IsAccountCollaborator(ctx context.Context, arg IsAccountCollaboratorParams) (bool, error)
+	IsHandleReserved(ctx context.Context, handle string) (bool, error)
+	IsHandleTaken(ctx context.Context, handle string) (bool, error)
+	ListActiveAuthSessions(ctx context.Context, userID uuid.UUID) ([]*AuthSession, error)
+	ListContentHistory(ctx context.Context, arg ListContentHistoryParams) ([]*ContentHistory, error)
+	// The user's trash, most recently deleted first
+	ListDeletedUserContentItems(ctx context.Context, userID uuid.UUID) ([]*ContentItem, error)
+	ListDueReportSubscriptions(ctx context.Context, arg ListDueReportSubscriptionsParams) ([]*ListDueReportSubscriptionsRow, error)
+	ListInviteCodes(ctx context.Context, arg ListInviteCodesParams) ([]*InviteCode, error)
+	ListLinksForHealthCheck(ctx context.Context, arg ListLinksForHealthCheckParams) ([]*ListLinksForHealthCheckRow, error)
+	ListPendingContentRevisions(ctx context.Context) ([]*ContentRevision, error)
+	ListPendingContentRevisionsByOwner(ctx context.Context, ownerID uuid.UUID) ([]*ContentRevision, error)
+	ListPlatforms(ctx context.Context) ([]*Platform, error)
+	// Recent activity
+	ListRecentClicks(ctx context.Context, arg ListRecentClicksParams) ([]*ListRecentClicksRow, error)
+	ListTemplateUsers(ctx context.Context) ([]*User, error)
+	ListUnverifiedUsersCreatedBefore(ctx context.Context, arg ListUnverifiedUsersCreatedBeforeParams) ([]*ListUnverifiedUsersCreatedBeforeRow, error)
+	// Export
+	ListUserAnalyticsForExport(ctx context.Context, arg ListUserAnalyticsForExportParams) ([]*ListUserAnalyticsForExportRow, error)
+	ListUserAvatars(ctx context.Context, userID uuid.UUID) ([]*UserAvatar, error)
+	ListUserFiles(ctx context.Context, arg ListUserFilesParams) ([]*File, error)
+	ListUserOAuthProviders(ctx context.Context, userID uuid.UUID) ([]string, error)
+	ListUserReportSubscriptions(ctx context.Context, userID uuid.UUID) ([]*ReportSubscription, error)
 	ListUsers(ctx context.Context, arg ListUsersParams) ([]*User, error)
+	ListUsersDueForPurgeWarning(ctx context.Context, arg ListUsersDueForPurgeWarningParams) ([]*ListUsersDueForPurgeWarningRow, error)
+	MarkCustomDomainVerified(ctx context.Context, arg MarkCustomDomainVerifiedParams) (int64, error)
+	MarkPurgeWarned(ctx context.Context, userID uuid.UUID) error
+	PurgeDeletedContentItems(ctx context.Context, arg PurgeDeletedContentItemsParams) (int64, error)
+	PurgeDeletedUsers(ctx context.Context, arg PurgeDeletedUsersParams) (int64, error)
+	RebuildAnalyticsRollups(ctx context.Context, arg RebuildAnalyticsRollupsParams) (int64, error)
+	RecordHandleChange(ctx context.Context, arg RecordHandleChangeParams) error
+	RecordInviteRedemption(ctx context.Context, arg RecordInviteRedemptionParams) error
+	RecordLinkCheck(ctx context.Context, arg RecordLinkCheckParams) (*ContentLinkHealth, error)
+	RedeemInviteCode(ctx context.Context, code string) (*InviteCode, error)
+	ReleaseHandleForTransfer(ctx context.Context, arg ReleaseHandleForTransferParams) (int64, error)
+	ReleaseInviteCode(ctx context.Context, code string) error
+	ReleaseUnverifiedCustomDomain(ctx context.Context, arg ReleaseUnverifiedCustomDomainParams) error
+	RemoveAccountCollaborator(ctx context.Context, arg RemoveAccountCollaboratorParams) error
+	// Moves every listed item in one statement, and only if the user owns all
+	// of them, so a layout is never left half applied.
+	ReorderContentItems(ctx context.Context, arg ReorderContentItemsParams) (int64, error)
+	RestoreContentItem(ctx context.Context, itemID uuid.UUID) (int64, error)
+	RestoreUser(ctx context.Context, userID uuid.UUID) (int64, error)
+	ReviewContentRevision(ctx context.Context, arg ReviewContentRevisionParams) (*ContentRevision, error)
+	RevokeAuthSession(ctx context.Context, arg RevokeAuthSessionParams) (int64, error)
+	RevokeUserAuthSessions(ctx context.Context, userID uuid.UUID) error
+	RotateAuthSessionToken(ctx context.Context, arg RotateAuthSessionTokenParams) (*AuthSession, error)
 	SetAccountLockout(ctx context.Context, arg SetAccountLockoutParams) error
+	SetCustomDomainVerification(ctx context.Context, arg SetCustomDomainVerificationParams) error
+	SetLinkAutoDeactivate(ctx context.Context, arg SetLinkAutoDeactivateParams) error
 	SetResetToken(ctx context.Context, arg SetResetTokenParams) error
+	SetTOTPSecret(ctx context.Context, arg SetTOTPSecretParams) error
+	SetVerificationToken(ctx context.Context, arg SetVerificationTokenParams) error
+	// Moves an item to the trash, from where it can be restored until it's
+	// purged
+	SoftDeleteContentItem(ctx context.Context, itemID uuid.UUID) (int64, error)
+	SoftDeleteUser(ctx context.Context, userID uuid.UUID) (*time.Time, error)
 	StoreRefreshToken(ctx context.Context, arg StoreRefreshTokenParams) error
 	UpdateContentItem(ctx context.Context, arg UpdateContentItemParams) error
+	UpdateContentItemPin(ctx context.Context, arg UpdateContentItemPinParams) error
 	UpdateContentItemPosition(ctx context.Context, arg UpdateContentItemPositionParams) error
 	UpdateEmail(ctx context.Context, arg UpdateEmailParams) error
+	UpdateFileSize(ctx context.Context, arg UpdateFileSizeParams) error
 	UpdateHandle(ctx context.Context, arg UpdateHandleParams) error
 	UpdateLastLogin(ctx context.Context, userID uuid.UUID) error
 	UpdateLinkMetadata(ctx context.Context, arg UpdateLinkMetadataParams) (*LinkMetadatum, error)
 	UpdatePasswordHash(ctx context.Context, arg UpdatePasswordHashParams) error
+	UpdatePlatform(ctx context.Context, arg UpdatePlatformParams) (*Platform, error)
 	UpdateUser(ctx context.Context, arg UpdateUserParams) error
 	UpdateUserAdminStatus(ctx context.Context, arg UpdateUserAdminStatusParams) error
+	UpdateUserAnalyticsEnabled(ctx context.Context, arg UpdateUserAnalyticsEnabledParams) error
+	UpdateUserContentApproval(ctx context.Context, arg UpdateUserContentApprovalParams) error
+	UpdateUserLocale(ctx context.Context, arg UpdateUserLocaleParams) error
 	UpdateUserOnboardedStatus(ctx context.Context, arg UpdateUserOnboardedStatusParams) error
 	UpdateUserPremiumStatus(ctx context.Context, arg UpdateUserPremiumStatusParams) error
+	UpdateUserTemplateStatus(ctx context.Context, arg UpdateUserTemplateStatusParams) error
+	UpdateUserVisibility(ctx context.Context, arg UpdateUserVisibilityParams) error
 	UpdateUsername(ctx context.Context, arg UpdateUsernameParams) error
 	VerifyEmail(ctx context.Context, userID uuid.UUID) error
 }
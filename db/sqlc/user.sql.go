@@ -7,10 +7,46 @@ package db
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 )
 
+const countUsers = `-- name: CountUsers :one
+SELECT COUNT(*) FROM users
+WHERE ($1::text IS NULL
+    OR username ILIKE '%' || $1 || '%'
+    OR handle ILIKE '%' || $1 || '%'
+    OR email ILIKE '%' || $1 || '%')
+AND ($2::boolean IS NULL OR is_premium = $2)
+AND ($3::boolean IS NULL OR is_admin = $3)
+AND ($4::boolean IS NULL OR onboarded = $4)
+AND ($5::boolean IS NULL
+    OR COALESCE((SELECT is_email_verified FROM auth WHERE auth.user_id = users.user_id), false) = $5)
+AND deleted_at IS NULL
+`
+
+type CountUsersParams struct {
+	Search    *string `json:"search"`
+	IsPremium *bool   `json:"is_premium"`
+	IsAdmin   *bool   `json:"is_admin"`
+	Onboarded *bool   `json:"onboarded"`
+	Verified  *bool   `json:"verified"`
+}
+
+func (q *Queries) CountUsers(ctx context.Context, arg CountUsersParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countUsers,
+		arg.Search,
+		arg.IsPremium,
+		arg.IsAdmin,
+		arg.Onboarded,
+		arg.Verified,
+	)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createUser = `-- name: CreateUser :one
 INSERT INTO users (
     username, handle, email, first_name, last_name, 
@@ -18,7 +54,7 @@ INSERT INTO users (
     is_premium, is_admin, onboarded
 ) VALUES (
     $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12
-) RETURNING user_id, username, handle, email, first_name, last_name, bio, profile_image_url, layout_version, custom_domain, is_premium, is_admin, onboarded, created_at, updated_at, theme_id, theme_customization
+) RETURNING user_id, username, handle, email, first_name, last_name, bio, profile_image_url, layout_version, custom_domain, is_premium, is_admin, onboarded, created_at, updated_at, theme_id, theme_customization, requires_content_approval, is_template, purge_warned_at, analytics_enabled, locale, custom_domain_token, custom_domain_verified_at, visibility, deleted_at
 `
 
 type CreateUserParams struct {
@@ -70,6 +106,15 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (*User,
 		&i.UpdatedAt,
 		&i.ThemeID,
 		&i.ThemeCustomization,
+		&i.RequiresContentApproval,
+		&i.IsTemplate,
+		&i.PurgeWarnedAt,
+		&i.AnalyticsEnabled,
+		&i.Locale,
+		&i.CustomDomainToken,
+		&i.CustomDomainVerifiedAt,
+		&i.Visibility,
+		&i.DeletedAt,
 	)
 	return &i, err
 }
@@ -85,8 +130,8 @@ func (q *Queries) DeleteUser(ctx context.Context, userID uuid.UUID) error {
 }
 
 const getUser = `-- name: GetUser :one
-SELECT user_id, username, handle, email, first_name, last_name, bio, profile_image_url, layout_version, custom_domain, is_premium, is_admin, onboarded, created_at, updated_at, theme_id, theme_customization FROM users
-WHERE user_id = $1 LIMIT 1
+SELECT user_id, username, handle, email, first_name, last_name, bio, profile_image_url, layout_version, custom_domain, is_premium, is_admin, onboarded, created_at, updated_at, theme_id, theme_customization, requires_content_approval, is_template, purge_warned_at, analytics_enabled, locale, custom_domain_token, custom_domain_verified_at, visibility, deleted_at FROM users
+WHERE user_id = $1 AND deleted_at IS NULL LIMIT 1
 `
 
 func (q *Queries) GetUser(ctx context.Context, userID uuid.UUID) (*User, error) {
@@ -110,13 +155,61 @@ func (q *Queries) GetUser(ctx context.Context, userID uuid.UUID) (*User, error)
 		&i.UpdatedAt,
 		&i.ThemeID,
 		&i.ThemeCustomization,
+		&i.RequiresContentApproval,
+		&i.IsTemplate,
+		&i.PurgeWarnedAt,
+		&i.AnalyticsEnabled,
+		&i.Locale,
+		&i.CustomDomainToken,
+		&i.CustomDomainVerifiedAt,
+		&i.Visibility,
+		&i.DeletedAt,
+	)
+	return &i, err
+}
+
+const getUserByCustomDomain = `-- name: GetUserByCustomDomain :one
+SELECT user_id, username, handle, email, first_name, last_name, bio, profile_image_url, layout_version, custom_domain, is_premium, is_admin, onboarded, created_at, updated_at, theme_id, theme_customization, requires_content_approval, is_template, purge_warned_at, analytics_enabled, locale, custom_domain_token, custom_domain_verified_at, visibility, deleted_at FROM users
+WHERE LOWER(custom_domain) = LOWER($1) AND deleted_at IS NULL LIMIT 1
+`
+
+func (q *Queries) GetUserByCustomDomain(ctx context.Context, lower string) (*User, error) {
+	row := q.db.QueryRow(ctx, getUserByCustomDomain, lower)
+	var i User
+	err := row.Scan(
+		&i.UserID,
+		&i.Username,
+		&i.Handle,
+		&i.Email,
+		&i.FirstName,
+		&i.LastName,
+		&i.Bio,
+		&i.ProfileImageUrl,
+		&i.LayoutVersion,
+		&i.CustomDomain,
+		&i.IsPremium,
+		&i.IsAdmin,
+		&i.Onboarded,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ThemeID,
+		&i.ThemeCustomization,
+		&i.RequiresContentApproval,
+		&i.IsTemplate,
+		&i.PurgeWarnedAt,
+		&i.AnalyticsEnabled,
+		&i.Locale,
+		&i.CustomDomainToken,
+		&i.CustomDomainVerifiedAt,
+		&i.Visibility,
+		&i.DeletedAt,
 	)
 	return &i, err
 }
 
 const getUserByEmail = `-- name: GetUserByEmail :one
-SELECT user_id, username, handle, email, first_name, last_name, bio, profile_image_url, layout_version, custom_domain, is_premium, is_admin, onboarded, created_at, updated_at, theme_id, theme_customization FROM users
-WHERE email = $1 LIMIT 1
+SELECT user_id, username, handle, email, first_name, last_name, bio, profile_image_url, layout_version, custom_domain, is_premium, is_admin, onboarded, created_at, updated_at, theme_id, theme_customization, requires_content_approval, is_template, purge_warned_at, analytics_enabled, locale, custom_domain_token, custom_domain_verified_at, visibility, deleted_at FROM users
+WHERE email = $1 AND deleted_at IS NULL LIMIT 1
 `
 
 func (q *Queries) GetUserByEmail(ctx context.Context, email string) (*User, error) {
@@ -140,13 +233,63 @@ func (q *Queries) GetUserByEmail(ctx context.Context, email string) (*User, erro
 		&i.UpdatedAt,
 		&i.ThemeID,
 		&i.ThemeCustomization,
+		&i.RequiresContentApproval,
+		&i.IsTemplate,
+		&i.PurgeWarnedAt,
+		&i.AnalyticsEnabled,
+		&i.Locale,
+		&i.CustomDomainToken,
+		&i.CustomDomainVerifiedAt,
+		&i.Visibility,
+		&i.DeletedAt,
+	)
+	return &i, err
+}
+
+const getUserByEmailIncludingDeleted = `-- name: GetUserByEmailIncludingDeleted :one
+SELECT user_id, username, handle, email, first_name, last_name, bio, profile_image_url, layout_version, custom_domain, is_premium, is_admin, onboarded, created_at, updated_at, theme_id, theme_customization, requires_content_approval, is_template, purge_warned_at, analytics_enabled, locale, custom_domain_token, custom_domain_verified_at, visibility, deleted_at FROM users
+WHERE email = $1 LIMIT 1
+`
+
+// Also finds accounts pending deletion, so their owner can sign in to
+// restore them
+func (q *Queries) GetUserByEmailIncludingDeleted(ctx context.Context, email string) (*User, error) {
+	row := q.db.QueryRow(ctx, getUserByEmailIncludingDeleted, email)
+	var i User
+	err := row.Scan(
+		&i.UserID,
+		&i.Username,
+		&i.Handle,
+		&i.Email,
+		&i.FirstName,
+		&i.LastName,
+		&i.Bio,
+		&i.ProfileImageUrl,
+		&i.LayoutVersion,
+		&i.CustomDomain,
+		&i.IsPremium,
+		&i.IsAdmin,
+		&i.Onboarded,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ThemeID,
+		&i.ThemeCustomization,
+		&i.RequiresContentApproval,
+		&i.IsTemplate,
+		&i.PurgeWarnedAt,
+		&i.AnalyticsEnabled,
+		&i.Locale,
+		&i.CustomDomainToken,
+		&i.CustomDomainVerifiedAt,
+		&i.Visibility,
+		&i.DeletedAt,
 	)
 	return &i, err
 }
 
 const getUserByHandle = `-- name: GetUserByHandle :one
-SELECT user_id, username, handle, email, first_name, last_name, bio, profile_image_url, layout_version, custom_domain, is_premium, is_admin, onboarded, created_at, updated_at, theme_id, theme_customization FROM users
-WHERE handle = $1 LIMIT 1
+SELECT user_id, username, handle, email, first_name, last_name, bio, profile_image_url, layout_version, custom_domain, is_premium, is_admin, onboarded, created_at, updated_at, theme_id, theme_customization, requires_content_approval, is_template, purge_warned_at, analytics_enabled, locale, custom_domain_token, custom_domain_verified_at, visibility, deleted_at FROM users
+WHERE handle = $1 AND deleted_at IS NULL LIMIT 1
 `
 
 func (q *Queries) GetUserByHandle(ctx context.Context, handle string) (*User, error) {
@@ -170,13 +313,22 @@ func (q *Queries) GetUserByHandle(ctx context.Context, handle string) (*User, er
 		&i.UpdatedAt,
 		&i.ThemeID,
 		&i.ThemeCustomization,
+		&i.RequiresContentApproval,
+		&i.IsTemplate,
+		&i.PurgeWarnedAt,
+		&i.AnalyticsEnabled,
+		&i.Locale,
+		&i.CustomDomainToken,
+		&i.CustomDomainVerifiedAt,
+		&i.Visibility,
+		&i.DeletedAt,
 	)
 	return &i, err
 }
 
 const getUserByUsername = `-- name: GetUserByUsername :one
-SELECT user_id, username, handle, email, first_name, last_name, bio, profile_image_url, layout_version, custom_domain, is_premium, is_admin, onboarded, created_at, updated_at, theme_id, theme_customization FROM users
-WHERE username = $1 LIMIT 1
+SELECT user_id, username, handle, email, first_name, last_name, bio, profile_image_url, layout_version, custom_domain, is_premium, is_admin, onboarded, created_at, updated_at, theme_id, theme_customization, requires_content_approval, is_template, purge_warned_at, analytics_enabled, locale, custom_domain_token, custom_domain_verified_at, visibility, deleted_at FROM users
+WHERE username = $1 AND deleted_at IS NULL LIMIT 1
 `
 
 func (q *Queries) GetUserByUsername(ctx context.Context, username string) (*User, error) {
@@ -200,23 +352,216 @@ func (q *Queries) GetUserByUsername(ctx context.Context, username string) (*User
 		&i.UpdatedAt,
 		&i.ThemeID,
 		&i.ThemeCustomization,
+		&i.RequiresContentApproval,
+		&i.IsTemplate,
+		&i.PurgeWarnedAt,
+		&i.AnalyticsEnabled,
+		&i.Locale,
+		&i.CustomDomainToken,
+		&i.CustomDomainVerifiedAt,
+		&i.Visibility,
+		&i.DeletedAt,
+	)
+	return &i, err
+}
+
+const getUserIncludingDeleted = `-- name: GetUserIncludingDeleted :one
+SELECT user_id, username, handle, email, first_name, last_name, bio, profile_image_url, layout_version, custom_domain, is_premium, is_admin, onboarded, created_at, updated_at, theme_id, theme_customization, requires_content_approval, is_template, purge_warned_at, analytics_enabled, locale, custom_domain_token, custom_domain_verified_at, visibility, deleted_at FROM users
+WHERE user_id = $1 LIMIT 1
+`
+
+func (q *Queries) GetUserIncludingDeleted(ctx context.Context, userID uuid.UUID) (*User, error) {
+	row := q.db.QueryRow(ctx, getUserIncludingDeleted, userID)
+	var i User
+	err := row.Scan(
+		&i.UserID,
+		&i.Username,
+		&i.Handle,
+		&i.Email,
+		&i.FirstName,
+		&i.LastName,
+		&i.Bio,
+		&i.ProfileImageUrl,
+		&i.LayoutVersion,
+		&i.CustomDomain,
+		&i.IsPremium,
+		&i.IsAdmin,
+		&i.Onboarded,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ThemeID,
+		&i.ThemeCustomization,
+		&i.RequiresContentApproval,
+		&i.IsTemplate,
+		&i.PurgeWarnedAt,
+		&i.AnalyticsEnabled,
+		&i.Locale,
+		&i.CustomDomainToken,
+		&i.CustomDomainVerifiedAt,
+		&i.Visibility,
+		&i.DeletedAt,
 	)
 	return &i, err
 }
 
+const getUsersByIDs = `-- name: GetUsersByIDs :many
+SELECT user_id, username, handle, email, first_name, last_name, bio, profile_image_url, layout_version, custom_domain, is_premium, is_admin, onboarded, created_at, updated_at, theme_id, theme_customization, requires_content_approval, is_template, purge_warned_at, analytics_enabled, locale, custom_domain_token, custom_domain_verified_at, visibility, deleted_at FROM users
+WHERE user_id = ANY($1::uuid[]) AND deleted_at IS NULL
+`
+
+func (q *Queries) GetUsersByIDs(ctx context.Context, userIds []uuid.UUID) ([]*User, error) {
+	rows, err := q.db.Query(ctx, getUsersByIDs, userIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []*User
+	for rows.Next() {
+		var i User
+		if err := rows.Scan(
+			&i.UserID,
+			&i.Username,
+			&i.Handle,
+			&i.Email,
+			&i.FirstName,
+			&i.LastName,
+			&i.Bio,
+			&i.ProfileImageUrl,
+			&i.LayoutVersion,
+			&i.CustomDomain,
+			&i.IsPremium,
+			&i.IsAdmin,
+			&i.Onboarded,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.ThemeID,
+			&i.ThemeCustomization,
+			&i.RequiresContentApproval,
+			&i.IsTemplate,
+			&i.PurgeWarnedAt,
+			&i.AnalyticsEnabled,
+			&i.Locale,
+			&i.CustomDomainToken,
+			&i.CustomDomainVerifiedAt,
+			&i.Visibility,
+			&i.DeletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const isHandleTaken = `-- name: IsHandleTaken :one
+SELECT (
+    EXISTS (SELECT 1 FROM users WHERE users.handle = $1)
+    OR EXISTS (
+        SELECT 1 FROM handle_transfers
+        WHERE handle_transfers.handle = $1 AND expires_at > CURRENT_TIMESTAMP
+    )
+)::boolean AS taken
+`
+
+func (q *Queries) IsHandleTaken(ctx context.Context, handle string) (bool, error) {
+	row := q.db.QueryRow(ctx, isHandleTaken, handle)
+	var taken bool
+	err := row.Scan(&taken)
+	return taken, err
+}
+
+const listTemplateUsers = `-- name: ListTemplateUsers :many
+SELECT user_id, username, handle, email, first_name, last_name, bio, profile_image_url, layout_version, custom_domain, is_premium, is_admin, onboarded, created_at, updated_at, theme_id, theme_customization, requires_content_approval, is_template, purge_warned_at, analytics_enabled, locale, custom_domain_token, custom_domain_verified_at, visibility, deleted_at FROM users
+WHERE is_template = TRUE AND visibility = 'public' AND deleted_at IS NULL
+ORDER BY handle
+`
+
+func (q *Queries) ListTemplateUsers(ctx context.Context) ([]*User, error) {
+	rows, err := q.db.Query(ctx, listTemplateUsers)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []*User
+	for rows.Next() {
+		var i User
+		if err := rows.Scan(
+			&i.UserID,
+			&i.Username,
+			&i.Handle,
+			&i.Email,
+			&i.FirstName,
+			&i.LastName,
+			&i.Bio,
+			&i.ProfileImageUrl,
+			&i.LayoutVersion,
+			&i.CustomDomain,
+			&i.IsPremium,
+			&i.IsAdmin,
+			&i.Onboarded,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.ThemeID,
+			&i.ThemeCustomization,
+			&i.RequiresContentApproval,
+			&i.IsTemplate,
+			&i.PurgeWarnedAt,
+			&i.AnalyticsEnabled,
+			&i.Locale,
+			&i.CustomDomainToken,
+			&i.CustomDomainVerifiedAt,
+			&i.Visibility,
+			&i.DeletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listUsers = `-- name: ListUsers :many
-SELECT user_id, username, handle, email, first_name, last_name, bio, profile_image_url, layout_version, custom_domain, is_premium, is_admin, onboarded, created_at, updated_at, theme_id, theme_customization FROM users
-ORDER BY created_at DESC
-LIMIT $1 OFFSET $2
+SELECT user_id, username, handle, email, first_name, last_name, bio, profile_image_url, layout_version, custom_domain, is_premium, is_admin, onboarded, created_at, updated_at, theme_id, theme_customization, requires_content_approval, is_template, purge_warned_at, analytics_enabled, locale, custom_domain_token, custom_domain_verified_at, visibility, deleted_at FROM users
+WHERE ($1::text IS NULL
+    OR username ILIKE '%' || $1 || '%'
+    OR handle ILIKE '%' || $1 || '%'
+    OR email ILIKE '%' || $1 || '%')
+AND ($2::boolean IS NULL OR is_premium = $2)
+AND ($3::boolean IS NULL OR is_admin = $3)
+AND ($4::boolean IS NULL OR onboarded = $4)
+AND ($5::boolean IS NULL
+    OR COALESCE((SELECT is_email_verified FROM auth WHERE auth.user_id = users.user_id), false) = $5)
+AND deleted_at IS NULL
+ORDER BY created_at DESC, user_id
+LIMIT $6 OFFSET $7
 `
 
 type ListUsersParams struct {
-	Limit  int64 `json:"limit"`
-	Offset int64 `json:"offset"`
+	Search    *string `json:"search"`
+	IsPremium *bool   `json:"is_premium"`
+	IsAdmin   *bool   `json:"is_admin"`
+	Onboarded *bool   `json:"onboarded"`
+	Verified  *bool   `json:"verified"`
+	Limit     int32   `json:"limit"`
+	Offset    int32   `json:"offset"`
 }
 
 func (q *Queries) ListUsers(ctx context.Context, arg ListUsersParams) ([]*User, error) {
-	rows, err := q.db.Query(ctx, listUsers, arg.Limit, arg.Offset)
+	rows, err := q.db.Query(ctx, listUsers,
+		arg.Search,
+		arg.IsPremium,
+		arg.IsAdmin,
+		arg.Onboarded,
+		arg.Verified,
+		arg.Limit,
+		arg.Offset,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -242,6 +587,15 @@ func (q *Queries) ListUsers(ctx context.Context, arg ListUsersParams) ([]*User,
 			&i.UpdatedAt,
 			&i.ThemeID,
 			&i.ThemeCustomization,
+			&i.RequiresContentApproval,
+			&i.IsTemplate,
+			&i.PurgeWarnedAt,
+			&i.AnalyticsEnabled,
+			&i.Locale,
+			&i.CustomDomainToken,
+			&i.CustomDomainVerifiedAt,
+			&i.Visibility,
+			&i.DeletedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -253,6 +607,119 @@ func (q *Queries) ListUsers(ctx context.Context, arg ListUsersParams) ([]*User,
 	return items, nil
 }
 
+const markCustomDomainVerified = `-- name: MarkCustomDomainVerified :execrows
+UPDATE users
+SET custom_domain_verified_at = CURRENT_TIMESTAMP,
+    updated_at = CURRENT_TIMESTAMP
+WHERE user_id = $1 AND custom_domain = $2 AND custom_domain_token = $3
+`
+
+type MarkCustomDomainVerifiedParams struct {
+	UserID            uuid.UUID `json:"user_id"`
+	CustomDomain      *string   `json:"custom_domain"`
+	CustomDomainToken *string   `json:"custom_domain_token"`
+}
+
+func (q *Queries) MarkCustomDomainVerified(ctx context.Context, arg MarkCustomDomainVerifiedParams) (int64, error) {
+	result, err := q.db.Exec(ctx, markCustomDomainVerified, arg.UserID, arg.CustomDomain, arg.CustomDomainToken)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const purgeDeletedUsers = `-- name: PurgeDeletedUsers :execrows
+DELETE FROM users
+WHERE user_id IN (
+    SELECT user_id FROM users
+    WHERE deleted_at < $1::timestamptz
+    LIMIT $2
+)
+`
+
+type PurgeDeletedUsersParams struct {
+	Cutoff    time.Time `json:"cutoff"`
+	BatchSize int32     `json:"batch_size"`
+}
+
+func (q *Queries) PurgeDeletedUsers(ctx context.Context, arg PurgeDeletedUsersParams) (int64, error) {
+	result, err := q.db.Exec(ctx, purgeDeletedUsers, arg.Cutoff, arg.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const releaseUnverifiedCustomDomain = `-- name: ReleaseUnverifiedCustomDomain :exec
+UPDATE users
+SET custom_domain = NULL,
+    custom_domain_token = NULL,
+    updated_at = CURRENT_TIMESTAMP
+WHERE LOWER(custom_domain) = LOWER($1)
+  AND custom_domain_verified_at IS NULL
+  AND user_id <> $2
+`
+
+type ReleaseUnverifiedCustomDomainParams struct {
+	Domain string    `json:"domain"`
+	UserID uuid.UUID `json:"user_id"`
+}
+
+func (q *Queries) ReleaseUnverifiedCustomDomain(ctx context.Context, arg ReleaseUnverifiedCustomDomainParams) error {
+	_, err := q.db.Exec(ctx, releaseUnverifiedCustomDomain, arg.Domain, arg.UserID)
+	return err
+}
+
+const restoreUser = `-- name: RestoreUser :execrows
+UPDATE users
+SET deleted_at = NULL,
+    updated_at = CURRENT_TIMESTAMP
+WHERE user_id = $1 AND deleted_at IS NOT NULL
+`
+
+func (q *Queries) RestoreUser(ctx context.Context, userID uuid.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, restoreUser, userID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const setCustomDomainVerification = `-- name: SetCustomDomainVerification :exec
+UPDATE users
+SET custom_domain = $2,
+    custom_domain_token = $3,
+    custom_domain_verified_at = NULL,
+    updated_at = CURRENT_TIMESTAMP
+WHERE user_id = $1
+`
+
+type SetCustomDomainVerificationParams struct {
+	UserID            uuid.UUID `json:"user_id"`
+	CustomDomain      *string   `json:"custom_domain"`
+	CustomDomainToken *string   `json:"custom_domain_token"`
+}
+
+func (q *Queries) SetCustomDomainVerification(ctx context.Context, arg SetCustomDomainVerificationParams) error {
+	_, err := q.db.Exec(ctx, setCustomDomainVerification, arg.UserID, arg.CustomDomain, arg.CustomDomainToken)
+	return err
+}
+
+const softDeleteUser = `-- name: SoftDeleteUser :one
+UPDATE users
+SET deleted_at = CURRENT_TIMESTAMP,
+    updated_at = CURRENT_TIMESTAMP
+WHERE user_id = $1 AND deleted_at IS NULL
+RETURNING deleted_at
+`
+
+func (q *Queries) SoftDeleteUser(ctx context.Context, userID uuid.UUID) (*time.Time, error) {
+	row := q.db.QueryRow(ctx, softDeleteUser, userID)
+	var deleted_at *time.Time
+	err := row.Scan(&deleted_at)
+	return deleted_at, err
+}
+
 const updateEmail = `-- name: UpdateEmail :exec
 UPDATE users
 SET
@@ -298,6 +765,10 @@ SET
     profile_image_url = COALESCE($5, profile_image_url),
     layout_version = COALESCE($6, layout_version),
     custom_domain = COALESCE($7, custom_domain),
+    custom_domain_verified_at = CASE
+        WHEN COALESCE($7, custom_domain) IS DISTINCT FROM custom_domain THEN NULL
+        ELSE custom_domain_verified_at
+    END,
     updated_at = CURRENT_TIMESTAMP
 WHERE user_id = $1
 `
@@ -343,6 +814,60 @@ func (q *Queries) UpdateUserAdminStatus(ctx context.Context, arg UpdateUserAdmin
 	return err
 }
 
+const updateUserAnalyticsEnabled = `-- name: UpdateUserAnalyticsEnabled :exec
+UPDATE users
+SET
+    analytics_enabled = $2,
+    updated_at = CURRENT_TIMESTAMP
+WHERE user_id = $1
+`
+
+type UpdateUserAnalyticsEnabledParams struct {
+	UserID           uuid.UUID `json:"user_id"`
+	AnalyticsEnabled bool      `json:"analytics_enabled"`
+}
+
+func (q *Queries) UpdateUserAnalyticsEnabled(ctx context.Context, arg UpdateUserAnalyticsEnabledParams) error {
+	_, err := q.db.Exec(ctx, updateUserAnalyticsEnabled, arg.UserID, arg.AnalyticsEnabled)
+	return err
+}
+
+const updateUserContentApproval = `-- name: UpdateUserContentApproval :exec
+UPDATE users
+SET
+    requires_content_approval = $2,
+    updated_at = CURRENT_TIMESTAMP
+WHERE user_id = $1
+`
+
+type UpdateUserContentApprovalParams struct {
+	UserID                  uuid.UUID `json:"user_id"`
+	RequiresContentApproval bool      `json:"requires_content_approval"`
+}
+
+func (q *Queries) UpdateUserContentApproval(ctx context.Context, arg UpdateUserContentApprovalParams) error {
+	_, err := q.db.Exec(ctx, updateUserContentApproval, arg.UserID, arg.RequiresContentApproval)
+	return err
+}
+
+const updateUserLocale = `-- name: UpdateUserLocale :exec
+UPDATE users
+SET
+    locale = $2,
+    updated_at = CURRENT_TIMESTAMP
+WHERE user_id = $1
+`
+
+type UpdateUserLocaleParams struct {
+	UserID uuid.UUID `json:"user_id"`
+	Locale *string   `json:"locale"`
+}
+
+func (q *Queries) UpdateUserLocale(ctx context.Context, arg UpdateUserLocaleParams) error {
+	_, err := q.db.Exec(ctx, updateUserLocale, arg.UserID, arg.Locale)
+	return err
+}
+
 const updateUserOnboardedStatus = `-- name: UpdateUserOnboardedStatus :exec
 UPDATE users
 SET
@@ -379,6 +904,42 @@ func (q *Queries) UpdateUserPremiumStatus(ctx context.Context, arg UpdateUserPre
 	return err
 }
 
+const updateUserTemplateStatus = `-- name: UpdateUserTemplateStatus :exec
+UPDATE users
+SET
+    is_template = $2,
+    updated_at = CURRENT_TIMESTAMP
+WHERE user_id = $1
+`
+
+type UpdateUserTemplateStatusParams struct {
+	UserID     uuid.UUID `json:"user_id"`
+	IsTemplate bool      `json:"is_template"`
+}
+
+func (q *Queries) UpdateUserTemplateStatus(ctx context.Context, arg UpdateUserTemplateStatusParams) error {
+	_, err := q.db.Exec(ctx, updateUserTemplateStatus, arg.UserID, arg.IsTemplate)
+	return err
+}
+
+const updateUserVisibility = `-- name: UpdateUserVisibility :exec
+UPDATE users
+SET
+    visibility = $2,
+    updated_at = CURRENT_TIMESTAMP
+WHERE user_id = $1
+`
+
+type UpdateUserVisibilityParams struct {
+	UserID     uuid.UUID `json:"user_id"`
+	Visibility string    `json:"visibility"`
+}
+
+func (q *Queries) UpdateUserVisibility(ctx context.Context, arg UpdateUserVisibilityParams) error {
+	_, err := q.db.Exec(ctx, updateUserVisibility, arg.UserID, arg.Visibility)
+	return err
+}
+
 const updateUsername = `-- name: UpdateUsername :exec
 UPDATE users
 SET
@@ -10,6 +10,7 @@ import (
 	"runtime"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -44,6 +45,29 @@ var levelColors = map[LogLevel]string{
 
 const ColorReset = "\033[0m"
 
+// String returns the lowercase level name, as accepted by ParseLevel
+func (l LogLevel) String() string {
+	if name, ok := levelNames[l]; ok {
+		return strings.ToLower(name)
+	}
+	return fmt.Sprintf("level(%d)", int(l))
+}
+
+// ParseLevel converts a level name such as "debug" or "WARN" to a LogLevel.
+// "warning" is accepted as an alias for warn.
+func ParseLevel(name string) (LogLevel, error) {
+	upper := strings.ToUpper(strings.TrimSpace(name))
+	if upper == "WARNING" {
+		upper = "WARN"
+	}
+	for level, levelName := range levelNames {
+		if levelName == upper {
+			return level, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown log level %q", name)
+}
+
 type LogFormat string
 
 const (
@@ -51,6 +75,19 @@ const (
 	JSONFormat LogFormat = "json"
 )
 
+// ParseFormat converts a format name to a LogFormat. "console" is accepted
+// as an alias for the human-readable text format.
+func ParseFormat(name string) (LogFormat, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "json":
+		return JSONFormat, nil
+	case "text", "console":
+		return TextFormat, nil
+	default:
+		return "", fmt.Errorf("unknown log format %q", name)
+	}
+}
+
 type Logger interface {
 	Debug(args ...any)
 	Debugf(format string, args ...any)
@@ -73,6 +110,13 @@ type Logger interface {
 	Timer(name string) *Timer
 	TimerStart(name string)
 	TimerStop(name string)
+
+	// Level and SetLevel read and change the minimum level at runtime. The
+	// level is shared with every logger derived through With, WithFields,
+	// WithLayer and WithStackTrace, so changing it on any of them applies
+	// to all.
+	Level() LogLevel
+	SetLevel(level LogLevel)
 }
 
 type Config struct {
@@ -105,6 +149,7 @@ func DefaultConfig() Config {
 
 type StandardLogger struct {
 	config Config
+	level  *atomic.Int32
 	fields map[string]any
 	layer  string
 	trace  bool
@@ -125,8 +170,12 @@ func New(config Config) Logger {
 		config.Writer = os.Stdout
 	}
 
+	level := &atomic.Int32{}
+	level.Store(int32(config.Level))
+
 	return &StandardLogger{
 		config: config,
+		level:  level,
 		fields: make(map[string]any),
 		timers: make(map[string]*Timer),
 	}
@@ -137,6 +186,12 @@ func Default() Logger {
 }
 
 func Production() Logger {
+	return New(ProductionConfig())
+}
+
+// ProductionConfig is the configuration used by Production: JSON output at
+// info level without caller information or colors
+func ProductionConfig() Config {
 	config := DefaultConfig()
 	config.Level = InfoLevel
 	config.Format = JSONFormat
@@ -144,10 +199,16 @@ func Production() Logger {
 	config.DisableColors = true
 	config.Environment = "production"
 
-	return New(config)
+	return config
 }
 
 func Development() Logger {
+	return New(DevelopmentConfig())
+}
+
+// DevelopmentConfig is the configuration used by Development: colored text
+// output at debug level with caller information
+func DevelopmentConfig() Config {
 	config := DefaultConfig()
 	config.Level = DebugLevel
 	config.Format = TextFormat
@@ -155,12 +216,13 @@ func Development() Logger {
 	config.DisableColors = false
 	config.Environment = "development"
 
-	return New(config)
+	return config
 }
 
 func (l *StandardLogger) With(key string, value any) Logger {
 	newLogger := &StandardLogger{
 		config: l.config,
+		level:  l.level,
 		fields: make(map[string]any),
 		layer:  l.layer,
 		trace:  l.trace,
@@ -177,6 +239,7 @@ func (l *StandardLogger) With(key string, value any) Logger {
 func (l *StandardLogger) WithFields(fields map[string]any) Logger {
 	newLogger := &StandardLogger{
 		config: l.config,
+		level:  l.level,
 		fields: make(map[string]any),
 		layer:  l.layer,
 		trace:  l.trace,
@@ -193,6 +256,7 @@ func (l *StandardLogger) WithFields(fields map[string]any) Logger {
 func (l *StandardLogger) WithLayer(layer string) Logger {
 	newLogger := &StandardLogger{
 		config: l.config,
+		level:  l.level,
 		fields: make(map[string]any),
 		layer:  layer,
 		trace:  l.trace,
@@ -207,6 +271,7 @@ func (l *StandardLogger) WithLayer(layer string) Logger {
 func (l *StandardLogger) WithStackTrace() Logger {
 	newLogger := &StandardLogger{
 		config: l.config,
+		level:  l.level,
 		fields: make(map[string]any),
 		layer:  l.layer,
 		trace:  true,
@@ -218,6 +283,14 @@ func (l *StandardLogger) WithStackTrace() Logger {
 	return newLogger
 }
 
+func (l *StandardLogger) Level() LogLevel {
+	return LogLevel(l.level.Load())
+}
+
+func (l *StandardLogger) SetLevel(level LogLevel) {
+	l.level.Store(int32(level))
+}
+
 func (l *StandardLogger) Timer(name string) *Timer {
 	l.mu.Lock()
 	defer l.mu.Unlock()
@@ -270,7 +343,7 @@ func (t *Timer) Stop() time.Duration {
 }
 
 func (l *StandardLogger) log(level LogLevel, args ...any) {
-	if level < l.config.Level {
+	if level < l.Level() {
 		return
 	}
 
@@ -279,7 +352,7 @@ func (l *StandardLogger) log(level LogLevel, args ...any) {
 }
 
 func (l *StandardLogger) logf(level LogLevel, format string, args ...any) {
-	if level < l.config.Level {
+	if level < l.Level() {
 		return
 	}
 
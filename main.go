@@ -4,23 +4,33 @@ package main
 import (
 	"context"
 	"fmt"
-	"net/http"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"sync"
 	"syscall"
+	"time"
 
+	"github.com/0xsj/mios.io/api/admin"
 	"github.com/0xsj/mios.io/api/analytics"
 	"github.com/0xsj/mios.io/api/auth"
 	"github.com/0xsj/mios.io/api/content"
 	"github.com/0xsj/mios.io/api/file"
 	"github.com/0xsj/mios.io/api/link_metadata"
+	"github.com/0xsj/mios.io/api/profile"
+	"github.com/0xsj/mios.io/api/report"
 	api "github.com/0xsj/mios.io/api/server"
 	"github.com/0xsj/mios.io/api/user"
 	"github.com/0xsj/mios.io/config"
 	db "github.com/0xsj/mios.io/db/sqlc"
 	"github.com/0xsj/mios.io/log"
 	"github.com/0xsj/mios.io/middleware"
+	"github.com/0xsj/mios.io/pkg/cache"
 	"github.com/0xsj/mios.io/pkg/email"
+	"github.com/0xsj/mios.io/pkg/geoip"
+	"github.com/0xsj/mios.io/pkg/live"
+	"github.com/0xsj/mios.io/pkg/metrics"
+	"github.com/0xsj/mios.io/pkg/oauth"
 	"github.com/0xsj/mios.io/pkg/redis"
 	"github.com/0xsj/mios.io/pkg/storage"
 	"github.com/0xsj/mios.io/repository"
@@ -38,14 +48,40 @@ func main() {
 		environment = "development"
 	}
 
-	var baseLogger log.Logger
+	cfg := config.LoadConfig("dev", ".")
+
+	// LOG_FORMAT and LOG_LEVEL override the environment defaults so JSON
+	// output can be tested locally and console output used in production
+	logConfig := log.DevelopmentConfig()
 	if environment == "production" {
-		baseLogger = log.Production()
-	} else {
-		baseLogger = log.Development()
+		logConfig = log.ProductionConfig()
+	}
+
+	var logConfigWarnings []string
+	if cfg.LogFormat != "" {
+		format, err := log.ParseFormat(cfg.LogFormat)
+		if err != nil {
+			logConfigWarnings = append(logConfigWarnings, fmt.Sprintf("Ignoring LOG_FORMAT: %v", err))
+		} else {
+			logConfig.Format = format
+			logConfig.DisableColors = format == log.JSONFormat
+		}
+	}
+	if cfg.LogLevel != "" {
+		level, err := log.ParseLevel(cfg.LogLevel)
+		if err != nil {
+			logConfigWarnings = append(logConfigWarnings, fmt.Sprintf("Ignoring LOG_LEVEL: %v", err))
+		} else {
+			logConfig.Level = level
+		}
 	}
 
-	baseLogger.Info("Starting application with custom logger...")
+	baseLogger := log.New(logConfig)
+	for _, warning := range logConfigWarnings {
+		baseLogger.Warn(warning)
+	}
+
+	baseLogger.Infof("Starting application with custom logger (format: %s, level: %s)...", logConfig.Format, logConfig.Level)
 	appLogger := baseLogger.WithLayer("App")
 	repoLogger := baseLogger.WithLayer("Repository")
 	serviceLogger := baseLogger.WithLayer("Service")
@@ -53,24 +89,33 @@ func main() {
 	middlewareLogger := baseLogger.WithLayer("Middleware")
 	serverLogger := baseLogger.WithLayer("Server")
 	redisLogger := baseLogger.WithLayer("redis")
+	cacheLogger := baseLogger.WithLayer("Cache")
 	storageLogger := baseLogger.WithLayer("Storage")
 
-	appLogger.Info("Loading configuration...")
-	cfg := config.LoadConfig("dev", ".")
 	appLogger.Debugf("Loaded configuration: %+v", cfg)
 	
 	redisClient, err := redis.NewClient(cfg, redisLogger)
 	if err != nil {
 		appLogger.Fatalf("Failed to connect to Redis: %v", err)
 	}
-	defer redisClient.Close()
+
+	appMetrics := metrics.NewMetrics()
+
+	invalidationBus := cache.NewInvalidationBus(redisClient, cacheLogger, cache.DefaultInvalidationChannel)
+	cacheService := cache.NewRedisCache(redisClient, cacheLogger, "cache", invalidationBus, appMetrics)
+	if cfg.CacheL1MaxEntries > 0 {
+		cacheService = cache.NewL1Cache(cacheService, "cache", invalidationBus, cache.L1Config{
+			MaxEntries: cfg.CacheL1MaxEntries,
+			TTL:        cfg.CacheL1TTL,
+		}, cacheLogger)
+	}
 
 	if cfg.DBUsername == "" || cfg.DBPassword == "" || cfg.DBHost == "" || cfg.DBPort == "" || cfg.DBName == "" {
 		appLogger.Fatal("ERROR: Database configuration values are missing")
 		return
 	}
 
-	templateManager, err := email.NewTemplateManager("./pkg/email/templates")
+	templateManager, err := email.NewTemplateManager("./pkg/email/templates", cfg.EmailFallbackLocale)
 	if err != nil {
 		appLogger.Fatalf("Failed to initialize email template manager: %v", err)
 	}
@@ -97,14 +142,14 @@ func main() {
 	}
 
 	appLogger.Info("Database connection successful!")
-	defer dbpool.Close()
 
 	appLogger.Info("Initializing database queries...")
-	queries := db.New(dbpool)
+	queries := db.New(repository.NewInstrumentedDB(dbpool, appMetrics))
 
 	// Initialize storage
 	appLogger.Info("Initializing storage...")
 	var storageService storage.Storage
+	var localStorage *storage.LocalStorage
 	
 	switch cfg.StorageProvider {
 	case "s3":
@@ -115,6 +160,11 @@ func main() {
 			CDNDomain:   cfg.StorageCDNDomain,
 			AccessKeyID: cfg.S3AccessKeyID,
 			SecretKey:   cfg.S3SecretAccessKey,
+
+			MaxRetries:       cfg.StorageMaxRetries,
+			MaxBackoff:       cfg.StorageMaxBackoff,
+			OperationTimeout: cfg.StorageOperationTimeout,
+			UploadTimeout:    cfg.StorageUploadTimeout,
 		}
 		storageService, err = storage.NewS3Storage(s3Config, storageLogger)
 		if err != nil {
@@ -122,7 +172,9 @@ func main() {
 		}
 	case "local":
 		appLogger.Info("Using local storage")
-		storageService = storage.NewLocalStorage(cfg.StorageBasePath, cfg.StorageBaseURL, storageLogger)
+		localStorage = storage.NewLocalStorage(cfg.StorageBasePath, cfg.StorageBaseURL, storageLogger).
+			WithSignedDownloads(storage.NewURLSigner(cfg.StorageSigningSecret), cfg.StorageDownloadURL)
+		storageService = localStorage
 		
 		// Create uploads directory if it doesn't exist
 		if err := os.MkdirAll(cfg.StorageBasePath, 0755); err != nil {
@@ -132,31 +184,122 @@ func main() {
 		appLogger.Fatalf("Unknown storage provider: %s", cfg.StorageProvider)
 	}
 
+	var cdnMonitor *storage.CDNMonitor
+	if cfg.StorageCDNDomain != "" {
+		cdnMonitor = storage.NewCDNMonitor(storage.CDNMonitorConfig{
+			Domain:           cfg.StorageCDNDomain,
+			Path:             cfg.StorageCDNHealthCheckPath,
+			Interval:         cfg.StorageCDNHealthCheckInterval,
+			FailureThreshold: cfg.StorageCDNFailureThreshold,
+		}, storageLogger.With("component", "CDNMonitor"))
+	}
+
 	appLogger.Info("Initializing repositories...")
 	userRepo := repository.NewUserRepository(queries, repoLogger.With("repository", "User"))
 	authRepo := repository.NewAuthRepository(queries, repoLogger.With("repository", "Auth"))
 	contentRepo := repository.NewContentRepository(queries, repoLogger.With("repository", "Content"))
-	analyticsRepo := repository.NewAnalyticsRepository(queries, repoLogger.With("repository", "Analytics"))
+	analyticsRepo := repository.NewAnalyticsRepository(queries, repository.NewTxManager(dbpool), repoLogger.With("repository", "Analytics"))
 	linkMetadataRepo := repository.NewLinkMetadataRepository(queries, repoLogger.With("repository", "LinkMetadata"))
+	contentRevisionRepo := repository.NewContentRevisionRepository(queries, repoLogger.With("repository", "ContentRevision"))
+	inviteCodeRepo := repository.NewInviteCodeRepository(queries, repoLogger.With("repository", "InviteCode"))
+	userAvatarRepo := repository.NewUserAvatarRepository(queries, repoLogger.With("repository", "UserAvatar"))
+	linkHealthRepo := repository.NewLinkHealthRepository(queries, repoLogger.With("repository", "LinkHealth"))
+	contentHistoryRepo := repository.NewContentHistoryRepository(queries, repoLogger.With("repository", "ContentHistory"))
+	auditRepo := repository.NewAuditRepository(queries, repoLogger.With("repository", "Audit"))
+	oauthRepo := repository.NewOAuthRepository(queries, repoLogger.With("repository", "OAuth"))
+	sessionRepo := repository.NewSessionRepository(queries, repoLogger.With("repository", "Session"))
+	reportRepo := repository.NewReportSubscriptionRepository(queries, repoLogger.With("repository", "ReportSubscription"))
+	fileRepo := repository.NewFileRepository(queries, repoLogger.With("repository", "File"))
 	emailClient := email.NewEmailClient(baseLogger.WithLayer("Email"), templateManager)
+	emailQueue := email.NewQueue(emailClient, baseLogger.WithLayer("Email"), cfg.EmailQueueSize)
+
+	var geoLookup geoip.Lookup
+	if cfg.GeoIPDatabasePath != "" {
+		geoDB, err := geoip.Open(cfg.GeoIPDatabasePath)
+		if err != nil {
+			appLogger.Fatalf("Failed to load GeoIP database: %v", err)
+		}
+		appLogger.Infof("Loaded %d GeoIP ranges from %s", geoDB.Len(), cfg.GeoIPDatabasePath)
+		geoLookup = geoDB
+	} else {
+		appLogger.Warn("GEOIP_DATABASE_PATH not set, geographic analytics will report every visitor as Unknown")
+	}
 
 	appLogger.Info("Initializing services...")
-	userService := service.NewUserService(userRepo, serviceLogger.With("service", "User"))
-	authService := service.NewAuthService(
-		userRepo,
-		authRepo,
-		emailClient,
-		cfg.JWTSecret,
-		cfg.GetTokenDuration(),
-		serviceLogger.With("service", "Auth"),
-		baseURL,
-	)
-	contentService := service.NewContentService(contentRepo, userRepo,
-		serviceLogger.With("service", "Content"))
-	analyticsService := service.NewAnalyticsService(analyticsRepo, contentRepo, userRepo,
-		serviceLogger.With("service", "Analytics"))
-	linkMetadataService := service.NewLinkMetadataService(linkMetadataRepo,
+	oauthProviders := make(map[string]oauth.Provider)
+	if cfg.GoogleClientID != "" {
+		oauthProviders[oauth.Google] = oauth.NewGoogle(oauth.Config{
+			ClientID:     cfg.GoogleClientID,
+			ClientSecret: cfg.GoogleClientSecret,
+			RedirectURL:  baseURL + "/api/auth/oauth/google/callback",
+		})
+	}
+	if cfg.GitHubClientID != "" {
+		oauthProviders[oauth.GitHub] = oauth.NewGitHub(oauth.Config{
+			ClientID:     cfg.GitHubClientID,
+			ClientSecret: cfg.GitHubClientSecret,
+			RedirectURL:  baseURL + "/api/auth/oauth/github/callback",
+		})
+	}
+	fieldLimits := service.FieldLimits{
+		Title: cfg.MaxTitleLength,
+		Href:  cfg.MaxHrefLength,
+		URL:   cfg.MaxURLLength,
+		Bio:   cfg.MaxBioLength,
+		Name:  cfg.MaxNameLength,
+	}
+	reservedHandles := service.ReservedHandles{
+		Exact:      cfg.ReservedHandles,
+		Substrings: cfg.ReservedHandleSubstrings,
+	}
+	contentTypeLimits, err := service.ParseContentTypeLimits(cfg.ContentTypeLimits)
+	if err != nil {
+		appLogger.Fatalf("Invalid content type limits: %v", err)
+	}
+	contentConfig := service.ContentConfig{
+		TypeLimits:          contentTypeLimits,
+		RequireHTTPSLinks:   cfg.RequireHTTPSLinks,
+		HTTPSUpgradeDomains: cfg.HTTPSUpgradeDomains,
+		FieldLimits:         fieldLimits,
+		FallbackOrder:       cfg.ContentFallbackOrder,
+		MaxPinnedFree:       cfg.MaxPinnedItemsFree,
+		MaxPinnedPremium:    cfg.MaxPinnedItemsPremium,
+
+		MaxActiveItemsFree:    cfg.MaxActiveItemsFree,
+		MaxActiveItemsPremium: cfg.MaxActiveItemsPremium,
+
+		TrashRetention: time.Duration(cfg.ContentTrashRetentionDays) * 24 * time.Hour,
+	}
+	linkMetadataConfig := service.LinkMetadataConfig{
+		ImageFallbackChain: cfg.LinkImageFallbackChain,
+		ScrapeStrategies:   cfg.LinkScrapeStrategies,
+		UserAgent:          cfg.LinkScraperUserAgent,
+		FetchTimeout:       cfg.LinkFetchTimeout,
+		MaxPageBytes:       cfg.LinkMaxPageBytes,
+	}
+	linkMetadataService := service.NewLinkMetadataService(linkMetadataRepo, linkMetadataConfig,
 		serviceLogger.With("service", "LinkMetadata"))
+	if err := linkMetadataService.LoadPlatforms(context.Background()); err != nil {
+		appLogger.Warnf("Failed to load platforms, using the built-in registry: %v", err)
+	}
+	linkPrefetcher := service.NewLinkPrefetcher(linkMetadataService, cfg.LinkPrefetchConcurrency,
+		serviceLogger.With("component", "LinkPrefetcher"))
+	contentService := service.NewContentService(contentRepo, userRepo, contentRevisionRepo, linkHealthRepo, contentHistoryRepo,
+		repository.NewTxManager(dbpool), linkPrefetcher, contentConfig, serviceLogger.With("service", "Content"))
+	contentService = service.NewCachedContentService(contentService, cacheService, serviceLogger.With("service", "CachedContent"))
+	liveEvents := live.NewRedisBroker(redisClient, serviceLogger.With("component", "LiveEvents"), live.DefaultChannelPrefix)
+	analyticsService := service.NewAnalyticsService(analyticsRepo, contentRepo, userRepo, storageService, emailClient, geoLookup, liveEvents,
+		service.AnalyticsExportConfig{
+			BatchSize: cfg.AnalyticsExportBatchSize,
+			LinkTTL:   cfg.AnalyticsExportLinkTTL,
+		},
+		service.AnalyticsConfig{
+			BotPatterns:       cfg.AnalyticsBotPatterns,
+			UniqueClickWindow: cfg.AnalyticsUniqueClickWindow,
+			MaxBatchSize:      cfg.AnalyticsMaxBatchSize,
+			ReferrerParams:    cfg.AnalyticsReferrerParams,
+		},
+		serviceLogger.With("service", "Analytics"))
 	
 	// Initialize file service
 	fileServiceConfig := service.FileServiceConfig{
@@ -171,9 +314,67 @@ func main() {
 		AllowedFileTypes: []string{
 			"application/pdf", "text/plain", "application/json",
 		},
-		CDNDomain: cfg.StorageCDNDomain,
+		CDNDomain:   cfg.StorageCDNDomain,
+		CDNFallback: cfg.StorageCDNFallback,
 	}
-	fileService := service.NewFileService(storageService, fileServiceConfig, serviceLogger.With("service", "File"))
+	fileService := service.NewFileService(storageService, cdnMonitor, fileRepo, userRepo, fileServiceConfig, serviceLogger.With("service", "File"))
+	userService := service.NewUserService(userRepo, authRepo, sessionRepo, userAvatarRepo, auditRepo, contentRepo, analyticsRepo, fileService, service.UserConfig{
+		MaxAvatarsFree:    cfg.MaxAvatarsFree,
+		MaxAvatarsPremium: cfg.MaxAvatarsPremium,
+		HandleTransferTTL: cfg.HandleTransferTTL,
+		FieldLimits:       fieldLimits,
+		ReservedHandles:   reservedHandles,
+
+		DeletionGracePeriod: time.Duration(cfg.AccountDeletionGraceDays) * 24 * time.Hour,
+	}, serviceLogger.With("service", "User"))
+	userService.OnPremiumStatusChange(contentService.PremiumStatusChanged)
+	authService := service.NewAuthService(
+		userRepo,
+		authRepo,
+		inviteCodeRepo,
+		oauthRepo,
+		sessionRepo,
+		emailClient,
+		redisClient,
+		cfg.JWTSecret,
+		cfg.GetTokenDuration(),
+		service.AuthConfig{
+			InviteOnly:           cfg.InviteOnlySignup,
+			AllowedRedirectHosts: cfg.AuthRedirectAllowedHosts,
+			FieldLimits:          fieldLimits,
+			OAuthProviders:       oauthProviders,
+			ReservedHandles:      reservedHandles,
+
+			MaxFailedLoginAttempts: cfg.MaxFailedLoginAttempts,
+			LockoutDuration:        cfg.LockoutDuration,
+			MinPasswordScore:       cfg.PasswordMinScore,
+			AccountDeleter:         userService,
+		},
+		serviceLogger.With("service", "Auth"),
+		baseURL,
+	)
+	userService.SetReauthenticator(authService)
+	retentionService := service.NewRetentionService(analyticsRepo, emailClient, service.RetentionConfig{
+		FreeDays:      cfg.AnalyticsRetentionDaysFree,
+		PremiumDays:   cfg.AnalyticsRetentionDaysPremium,
+		WarningDays:   cfg.AnalyticsPurgeWarningDays,
+		RetentionDays: cfg.AnalyticsRetentionDays,
+
+		AnonymizeDays:     cfg.AnalyticsAnonymizeAfterDays,
+		AnonymizationSalt: cfg.AnalyticsAnonymizationSalt,
+	}, serviceLogger.With("service", "Retention"), baseURL)
+	linkHealthService := service.NewLinkHealthService(linkHealthRepo, contentHistoryRepo, emailClient, service.LinkHealthConfig{
+		CheckInterval:    cfg.LinkHealthCheckInterval,
+		FailureThreshold: cfg.LinkFailureThreshold,
+		AutoDeactivate:   cfg.LinkAutoDeactivate,
+	}, serviceLogger.With("service", "LinkHealth"), baseURL)
+	reportService := service.NewReportService(reportRepo, userRepo, analyticsService, emailQueue, service.ReportConfig{
+		PremiumFrequencies: cfg.ReportPremiumFrequencies,
+	}, serviceLogger.With("service", "Report"), baseURL)
+	profileService := service.NewProfileService(userRepo, authRepo, contentRepo, contentConfig,
+		service.ProfileConfig{CanonicalRedirects: cfg.CanonicalProfileRedirects},
+		serviceLogger.With("service", "Profile"))
+	profileService = service.NewCachedProfileService(profileService, cacheService, serviceLogger.With("service", "CachedProfile"))
 
 	appLogger.Info("Initializing handlers...")
 	userHandler := user.NewHandler(userService, handlerLogger.With("handler", "User"))
@@ -182,38 +383,102 @@ func main() {
 	analyticsHandler := analytics.NewHandler(analyticsService, handlerLogger.With("handler", "Analytics"))
 	linkMetadataHandler := link_metadata.NewHandler(linkMetadataService, handlerLogger.With("handler", "LinkMetadata"))
 	fileHandler := file.NewHandler(fileService, handlerLogger.With("handler", "File"))
+	profileHandler := profile.NewHandler(profileService, handlerLogger.With("handler", "Profile"))
+	reportHandler := report.NewHandler(reportService, handlerLogger.With("handler", "Report"))
+	adminHandler := admin.NewHandler(baseLogger, retentionService, handlerLogger.With("handler", "Admin"))
 
 	appLogger.Info("Initializing OpenAPI handler...")
 
 	appLogger.Info("Setting up server...")
-	server, err := api.NewServer(cfg, queries, serverLogger, redisClient)
+	server, err := api.NewServer(cfg, queries, serverLogger, redisClient, cdnMonitor, appMetrics)
 	if err != nil {
 		appLogger.Fatalf("Failed to initialize server: %v", err)
 	}
 
+	server.AddReadinessCheck("database", dbpool.Ping)
+	server.AddReadinessCheck("redis", redisClient.Ping)
+
 	server.Router().Use(middleware.LoggingMiddleware(middlewareLogger))
 
-	// Serve static files for local storage
+	// Serve public files for local storage; everything else is only
+	// reachable through signed download URLs
 	if cfg.StorageProvider == "local" {
-		server.Router().Static("/uploads", cfg.StorageBasePath)
+		for _, category := range service.PublicFileCategories() {
+			server.Router().Static("/uploads/"+category, filepath.Join(cfg.StorageBasePath, category))
+		}
+		server.Router().GET("/downloads/*key", gin.WrapH(localStorage.DownloadHandler("/downloads")))
 	}
 
-	server.RegisterHandlers(userHandler, authHandler, contentHandler, authService, analyticsHandler, linkMetadataHandler, fileHandler)
+	server.RegisterHandlers(userHandler, authHandler, contentHandler, authService, analyticsHandler, linkMetadataHandler, fileHandler, profileHandler, reportHandler, adminHandler)
 
 	appLogger.Info("Registering OpenAPI handlers...")
 
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+
+	var workers sync.WaitGroup
+	startWorker := func(run func(ctx context.Context)) {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			run(workerCtx)
+		}()
+	}
+	startWorker(func(ctx context.Context) { retentionService.StartPurgeWarnings(ctx, 24*time.Hour) })
+	startWorker(func(ctx context.Context) { retentionService.StartRetentionEnforcement(ctx, 24*time.Hour) })
+	startWorker(func(ctx context.Context) { userService.StartDeletionPurge(ctx, 24*time.Hour) })
+	startWorker(func(ctx context.Context) { contentService.StartTrashPurge(ctx, 24*time.Hour) })
+	startWorker(func(ctx context.Context) { analyticsService.StartRollupRefresh(ctx, time.Hour) })
+	startWorker(linkHealthService.StartHealthChecks)
+	startWorker(emailQueue.Run)
+	startWorker(func(ctx context.Context) { reportService.StartReportScheduler(ctx, cfg.ReportCheckInterval) })
+	if cdnMonitor != nil {
+		startWorker(cdnMonitor.Run)
+	}
+
+	startWorker(invalidationBus.Listen)
+	startWorker(func(ctx context.Context) { metrics.NewSystemMetricsCollector(appMetrics).StartCollection(ctx, 15*time.Second) })
+	startWorker(func(ctx context.Context) { metrics.NewDatabaseMetricsCollector(dbpool, appMetrics).StartCollection(ctx, 15*time.Second) })
+
 	appLogger.Infof("Starting HTTP server on %s:%s...", cfg.Host, cfg.Port)
+	serverErr := make(chan error, 1)
 	go func() {
-		addr := fmt.Sprintf("%s:%s", cfg.Host, cfg.Port)
-		if err := server.Start(addr); err != nil && err != http.ErrServerClosed {
-			appLogger.Fatalf("Server error: %v", err)
-		}
+		serverErr <- server.Start(fmt.Sprintf("%s:%s", cfg.Host, cfg.Port))
 	}()
 
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-	<-quit
 
-	appLogger.Info("Shutdown signal received...")
+	select {
+	case err := <-serverErr:
+		appLogger.Errorf("Server error: %v", err)
+	case sig := <-quit:
+		appLogger.Infof("Shutdown signal received: %v", sig)
+	}
+
+	shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancelShutdown()
+
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		appLogger.Errorf("Server shutdown error: %v", err)
+	}
+
+	// Workers go last, once no request can hand them more work
+	stopWorkers()
+	workersDone := make(chan struct{})
+	go func() {
+		workers.Wait()
+		close(workersDone)
+	}()
+	select {
+	case <-workersDone:
+	case <-shutdownCtx.Done():
+		appLogger.Warn("Timed out waiting for background workers to stop")
+	}
+
+	dbpool.Close()
+	if err := redisClient.Close(); err != nil {
+		appLogger.Errorf("Failed to close Redis connection: %v", err)
+	}
 	appLogger.Info("Server successfully shut down")
 }
\ No newline at end of file
@@ -47,6 +47,58 @@ func AuthMiddleware(authService service.AuthService, logger log.Logger) gin.Hand
 	}
 }
 
+// OptionalAuthMiddleware identifies the user on public routes that serve
+// more to authenticated users. Requests without a valid bearer token carry
+// on anonymously instead of being rejected.
+func OptionalAuthMiddleware(authService service.AuthService, logger log.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tokenString, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !ok || tokenString == "" {
+			c.Next()
+			return
+		}
+
+		claims, err := authService.ValidateToken(c, tokenString)
+		if err != nil {
+			logger.Debugf("Ignoring invalid token on public route: %v", err)
+			c.Next()
+			return
+		}
+
+		context.SetUserID(c, claims.UserID)
+		c.Set("claims", claims)
+		c.Next()
+	}
+}
+
+// AccountRestoreMiddleware authenticates the owner of an account pending
+// deletion with the restore token they got on sign-in. It guards only the
+// restore endpoint; the token is refused everywhere else.
+func AccountRestoreMiddleware(authService service.AuthService, logger log.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tokenString, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !ok || tokenString == "" {
+			logger.Warn("Account restore failed: missing bearer token")
+			response.Error(c, response.ErrUnauthorizedResponse)
+			c.Abort()
+			return
+		}
+
+		claims, err := authService.ValidateRestoreToken(c, tokenString)
+		if err != nil {
+			logger.Warn("Account restore failed: invalid token:", err)
+			response.HandleError(c, err, logger)
+			c.Abort()
+			return
+		}
+
+		logger.Debugf("Account restore authenticated for user: %s", claims.UserID)
+		context.SetUserID(c, claims.UserID)
+		c.Set("claims", claims)
+		c.Next()
+	}
+}
+
 // AdminMiddleware ensures that the authenticated user has admin privileges
 func AdminMiddleware(logger log.Logger) gin.HandlerFunc {
 	return func(c *gin.Context) {
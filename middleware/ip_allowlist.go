@@ -0,0 +1,50 @@
+// middleware/ip_allowlist.go
+package middleware
+
+import (
+	"fmt"
+	"net/netip"
+	"strings"
+)
+
+// ipAllowlist matches client IPs against single addresses and CIDR ranges
+type ipAllowlist []netip.Prefix
+
+// parseIPAllowlist accepts entries such as "10.0.0.5", "10.0.0.0/8" or
+// "2001:db8::/32"
+func parseIPAllowlist(entries []string) (ipAllowlist, error) {
+	list := make(ipAllowlist, 0, len(entries))
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if strings.Contains(entry, "/") {
+			prefix, err := netip.ParsePrefix(entry)
+			if err != nil {
+				return nil, fmt.Errorf("invalid allowed IP range %q: %w", entry, err)
+			}
+			list = append(list, prefix.Masked())
+			continue
+		}
+
+		addr, err := netip.ParseAddr(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid allowed IP %q: %w", entry, err)
+		}
+		addr = addr.Unmap()
+		list = append(list, netip.PrefixFrom(addr, addr.BitLen()))
+	}
+	return list, nil
+}
+
+func (l ipAllowlist) contains(ip string) bool {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, prefix := range l {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
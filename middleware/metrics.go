@@ -27,7 +27,6 @@ func MetricsMiddleware(m *metrics.Metrics) gin.HandlerFunc {
 		// Create a response writer that captures the response size
 		writer := &metricsResponseWriter{
 			ResponseWriter: c.Writer,
-			statusCode:     200,
 			bytesWritten:   0,
 		}
 		c.Writer = writer
@@ -48,7 +47,7 @@ func MetricsMiddleware(m *metrics.Metrics) gin.HandlerFunc {
 		m.RecordHTTPRequest(
 			c.Request.Method,
 			endpoint,
-			writer.statusCode,
+			writer.Status(),
 			duration,
 			requestSize,
 			int64(writer.bytesWritten),
@@ -56,18 +55,14 @@ func MetricsMiddleware(m *metrics.Metrics) gin.HandlerFunc {
 	}
 }
 
-// metricsResponseWriter wraps gin.ResponseWriter to capture response size and status
+// metricsResponseWriter wraps gin.ResponseWriter to capture response size.
+// The status is read from the underlying writer, which gin also sets directly
+// for unmatched routes.
 type metricsResponseWriter struct {
 	gin.ResponseWriter
-	statusCode   int
 	bytesWritten int
 }
 
-func (w *metricsResponseWriter) WriteHeader(statusCode int) {
-	w.statusCode = statusCode
-	w.ResponseWriter.WriteHeader(statusCode)
-}
-
 func (w *metricsResponseWriter) Write(data []byte) (int, error) {
 	size, err := w.ResponseWriter.Write(data)
 	w.bytesWritten += size
@@ -3,29 +3,51 @@ package middleware
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"math"
 	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/0xsj/mios.io/log"
+	"github.com/0xsj/mios.io/pkg/metrics"
 	"github.com/0xsj/mios.io/pkg/redis"
 	"github.com/0xsj/mios.io/pkg/response"
+	"github.com/0xsj/mios.io/pkg/token"
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 )
 
 type RateLimitConfig struct {
-	RequestsPerMinute int           // Number of requests allowed per minute
-	BurstSize         int           // Number of requests allowed in burst
+	RequestsPerMinute int           // Number of requests allowed in any rolling WindowSize span
+	BurstSize         int           // Number of requests allowed in any rolling 10 seconds
 	KeyGenerator      KeyGenerator  // Function to generate rate limit key
 	SkipSuccessful    bool          // Skip counting successful requests (2xx responses)
 	WindowSize        time.Duration // Time window for rate limiting
+	Scope             string        // Separates the counters of route groups sharing a key generator
+	AllowedIPs        []string      // Client IPs and CIDR ranges that are never limited
 }
 
 type KeyGenerator func(c *gin.Context) string
 
+// RateLimitResolver picks the limits that apply to a request, e.g. by route
+// group. Premium users get PremiumRateLimitMultiplier times whatever it returns.
+type RateLimitResolver func(c *gin.Context) RateLimitConfig
+
+// PremiumRateLimitMultiplier scales the request and burst limits of users
+// whose access token marks them as premium
+const PremiumRateLimitMultiplier = 2
+
+// keyHashWidth is the number of hex characters kept from a hashed key part,
+// giving every client identity the same fixed-size footprint in Redis.
+const keyHashWidth = 16
+
 // Default key generators
 func IPBasedKeyGenerator(c *gin.Context) string {
-	return fmt.Sprintf("rate_limit:ip:%s", c.ClientIP())
+	return fmt.Sprintf("rate_limit:ip:%s", hashKeyPart(c.ClientIP()))
 }
 
 func UserBasedKeyGenerator(c *gin.Context) string {
@@ -36,8 +58,24 @@ func UserBasedKeyGenerator(c *gin.Context) string {
 	return fmt.Sprintf("rate_limit:user:%v", userID)
 }
 
+// EndpointBasedKeyGenerator keys on the route template rather than the
+// concrete path, so requests for different resource IDs share one counter.
 func EndpointBasedKeyGenerator(c *gin.Context) string {
-	return fmt.Sprintf("rate_limit:endpoint:%s:%s:%s", c.Request.Method, c.FullPath(), c.ClientIP())
+	return fmt.Sprintf("rate_limit:endpoint:%s:%s:%s", c.Request.Method, routeTemplate(c), hashKeyPart(c.ClientIP()))
+}
+
+// routeTemplate returns the matched route pattern. Requests that didn't match
+// any route share a single bucket so arbitrary paths can't mint new keys.
+func routeTemplate(c *gin.Context) string {
+	if path := c.FullPath(); path != "" {
+		return metrics.NormalizeEndpoint(path)
+	}
+	return "unmatched"
+}
+
+func hashKeyPart(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:])[:keyHashWidth]
 }
 
 // Pre-configured rate limit configs
@@ -84,23 +122,86 @@ func ExpensiveOperationRateLimit() RateLimitConfig {
 type RateLimiter struct {
 	redisClient *redis.Client
 	logger      log.Logger
-	config      RateLimitConfig
+	resolve     RateLimitResolver
+
+	// Parsed AllowedIPs by their joined entries
+	allowlists sync.Map
+}
+
+// NewRateLimiter creates a rate limiter with fixed limits, failing if
+// AllowedIPs holds anything but IPs and CIDR ranges
+func NewRateLimiter(redisClient *redis.Client, logger log.Logger, config RateLimitConfig) (*RateLimiter, error) {
+	allowlist, err := parseIPAllowlist(config.AllowedIPs)
+	if err != nil {
+		return nil, err
+	}
+
+	limiter := NewResolvingRateLimiter(redisClient, logger, func(*gin.Context) RateLimitConfig {
+		return config
+	})
+	limiter.allowlists.Store(strings.Join(config.AllowedIPs, ","), allowlist)
+	return limiter, nil
 }
 
-func NewRateLimiter(redisClient *redis.Client, logger log.Logger, config RateLimitConfig) *RateLimiter {
+// NewResolvingRateLimiter creates a rate limiter that looks up its limits
+// for every request. The AllowedIPs of resolved configs are parsed the first
+// time they are seen; invalid ones are logged and allow nobody.
+func NewResolvingRateLimiter(redisClient *redis.Client, logger log.Logger, resolve RateLimitResolver) *RateLimiter {
 	return &RateLimiter{
 		redisClient: redisClient,
 		logger:      logger,
-		config:      config,
+		resolve:     resolve,
+	}
+}
+
+func (rl *RateLimiter) allowlist(entries []string) ipAllowlist {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	key := strings.Join(entries, ",")
+	if allowlist, ok := rl.allowlists.Load(key); ok {
+		return allowlist.(ipAllowlist)
+	}
+
+	allowlist, err := parseIPAllowlist(entries)
+	if err != nil {
+		rl.logger.Errorf("Ignoring rate limit allowlist: %v", err)
+	}
+	rl.allowlists.Store(key, allowlist)
+	return allowlist
+}
+
+// configFor resolves the limits for a request, raised for premium users
+func (rl *RateLimiter) configFor(c *gin.Context) RateLimitConfig {
+	config := rl.resolve(c)
+	if claims, ok := c.Get("claims"); ok {
+		if claims, ok := claims.(*token.Claims); ok && claims.IsPremium {
+			config.RequestsPerMinute *= PremiumRateLimitMultiplier
+			config.BurstSize *= PremiumRateLimitMultiplier
+		}
 	}
+	return config
 }
 
 func (rl *RateLimiter) Middleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		key := rl.config.KeyGenerator(c)
-		
-		// Check rate limit before processing request
-		allowed, remaining, resetTime, err := rl.checkRateLimit(c, key)
+		config := rl.configFor(c)
+		if rl.allowlist(config.AllowedIPs).contains(c.ClientIP()) {
+			rl.logger.Debugf("Rate limit bypassed for allowlisted IP: %s", c.ClientIP())
+			c.Next()
+			return
+		}
+
+		key := config.KeyGenerator(c)
+		if config.Scope != "" {
+			key += ":" + config.Scope
+		}
+		requestID := uuid.New().String()
+
+		// Record the request and check it against the limits in one step, so
+		// concurrent requests can't all see the same count and slip through
+		allowed, remaining, resetIn, err := rl.acquire(c, config, key, requestID)
 		if err != nil {
 			rl.logger.Errorf("Rate limit check failed: %v", err)
 			// Allow request on Redis errors (fail open)
@@ -109,16 +210,14 @@ func (rl *RateLimiter) Middleware() gin.HandlerFunc {
 		}
 
 		// Set rate limit headers
-		c.Header("X-RateLimit-Limit", strconv.Itoa(rl.config.RequestsPerMinute))
+		c.Header("X-RateLimit-Limit", strconv.Itoa(config.RequestsPerMinute))
 		c.Header("X-RateLimit-Remaining", strconv.Itoa(remaining))
-		c.Header("X-RateLimit-Reset", strconv.FormatInt(resetTime.Unix(), 10))
+		c.Header("X-RateLimit-Reset", strconv.FormatInt(time.Now().Add(resetIn).Unix(), 10))
 
 		if !allowed {
 			rl.logger.Warnf("Rate limit exceeded for key: %s", key)
-			response.Error(c, response.ErrorResponse{
-				Code:    "RATE_LIMIT_EXCEEDED",
-				Message: "Too many requests. Please try again later.",
-			})
+			c.Header("Retry-After", strconv.Itoa(retryAfterSeconds(resetIn)))
+			response.Error(c, response.ErrRateLimitExceededResponse)
 			c.Abort()
 			return
 		}
@@ -130,15 +229,11 @@ func (rl *RateLimiter) Middleware() gin.HandlerFunc {
 		// Process request
 		c.Next()
 
-		// Update rate limit counter based on response
-		shouldCount := true
-		if rl.config.SkipSuccessful && writer.statusCode >= 200 && writer.statusCode < 300 {
-			shouldCount = false
-		}
-
-		if shouldCount {
-			if err := rl.incrementCounter(c, key); err != nil {
-				rl.logger.Errorf("Failed to increment rate limit counter: %v", err)
+		// The request was recorded up front; take it back out if successful
+		// requests don't count
+		if config.SkipSuccessful && writer.statusCode >= 200 && writer.statusCode < 300 {
+			if err := rl.release(c, key, requestID); err != nil {
+				rl.logger.Errorf("Failed to release rate limit entry: %v", err)
 			}
 		}
 	}
@@ -149,78 +244,97 @@ func (rw *responseWriter) WriteHeader(code int) {
 	rw.ResponseWriter.WriteHeader(code)
 }
 
-func (rl *RateLimiter) checkRateLimit(ctx context.Context, key string) (allowed bool, remaining int, resetTime time.Time, err error) {
-	now := time.Now()
-	windowStart := now.Truncate(rl.config.WindowSize)
-	resetTime = windowStart.Add(rl.config.WindowSize)
-
-	// Use sliding window with Redis
-	currentCountStr, err := rl.redisClient.Get(ctx, key)
-	if err != nil && err != redis.Nil {
-		return false, 0, resetTime, err
-	}
-
-	currentCount := 0
-	if currentCountStr != "" {
-		currentCount, _ = strconv.Atoi(currentCountStr)
-	}
-
-	// Check burst limit
-	burstKey := key + ":burst"
-	burstCountStr, err := rl.redisClient.Get(ctx, burstKey)
-	if err != nil && err != redis.Nil {
-		return false, 0, resetTime, err
-	}
-
-	burstCount := 0
-	if burstCountStr != "" {
-		burstCount, _ = strconv.Atoi(burstCountStr)
-	}
-
-	// Check if within limits
-	if currentCount >= rl.config.RequestsPerMinute || burstCount >= rl.config.BurstSize {
-		remaining = max(0, rl.config.RequestsPerMinute-currentCount)
-		return false, remaining, resetTime, nil
-	}
-
-	remaining = rl.config.RequestsPerMinute - currentCount - 1
-	return true, remaining, resetTime, nil
+// burstWindow is the rolling window BurstSize applies to
+const burstWindow = 10 * time.Second
+
+// acquireScript keeps a sliding log of requests per key in sorted sets
+// scored by arrival time in microseconds, one for the window (KEYS[1]) and
+// one for bursts (KEYS[2]). ARGV holds the limit, burst size, window and
+// burst window in microseconds, and the request's ID. Entries older than
+// their window are dropped first, so the limits hold over any span of that
+// length rather than per clock-aligned window. Rejected requests are not
+// logged. It returns whether the request is allowed, how many requests the
+// window holds and how many microseconds until the next entry leaves it.
+//
+// The clock is Redis's own, so every app server agrees on it.
+var acquireScript = redis.NewScript(`
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000000 + tonumber(time[2])
+local limit, burstSize = tonumber(ARGV[1]), tonumber(ARGV[2])
+local window, burstWindow = tonumber(ARGV[3]), tonumber(ARGV[4])
+
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', now - window)
+redis.call('ZREMRANGEBYSCORE', KEYS[2], '-inf', now - burstWindow)
+local count = redis.call('ZCARD', KEYS[1])
+local burst = redis.call('ZCARD', KEYS[2])
+
+local function frees(key, length)
+	local oldest = redis.call('ZRANGE', key, 0, 0, 'WITHSCORES')
+	if oldest[2] then
+		return tonumber(oldest[2]) + length
+	end
+	return now + length
+end
+
+if count >= limit or burst >= burstSize then
+	local reset = 0
+	if count >= limit then
+		reset = frees(KEYS[1], window)
+	end
+	if burst >= burstSize then
+		reset = math.max(reset, frees(KEYS[2], burstWindow))
+	end
+	return {0, count, reset - now}
+end
+
+redis.call('ZADD', KEYS[1], now, ARGV[5])
+redis.call('ZADD', KEYS[2], now, ARGV[5])
+redis.call('PEXPIRE', KEYS[1], math.ceil(window / 1000))
+redis.call('PEXPIRE', KEYS[2], math.ceil(burstWindow / 1000))
+return {1, count + 1, frees(KEYS[1], window) - now}
+`)
+
+// releaseScript removes a request from both logs. Logs that have expired
+// stay gone.
+var releaseScript = redis.NewScript(`
+redis.call('ZREM', KEYS[1], ARGV[1])
+redis.call('ZREM', KEYS[2], ARGV[1])
+return 0
+`)
+
+func (rl *RateLimiter) keys(key string) []string {
+	return []string{key, key + ":burst"}
 }
 
-func (rl *RateLimiter) incrementCounter(ctx context.Context, key string) error {
-	now := time.Now()
-	fmt.Println(now)
-	
-	// Increment main counter
-	count, err := rl.redisClient.Incr(ctx, key)
+func (rl *RateLimiter) acquire(ctx context.Context, config RateLimitConfig, key, requestID string) (allowed bool, remaining int, resetIn time.Duration, err error) {
+	result, err := rl.redisClient.RunScript(ctx, acquireScript, rl.keys(key),
+		config.RequestsPerMinute, config.BurstSize,
+		config.WindowSize.Microseconds(), burstWindow.Microseconds(), requestID)
 	if err != nil {
-		return err
+		return false, 0, 0, err
 	}
 
-	// Set expiration on first increment
-	if count == 1 {
-		err = rl.redisClient.Expire(ctx, key, rl.config.WindowSize)
-		if err != nil {
-			return err
-		}
+	values, ok := result.([]interface{})
+	if !ok || len(values) != 3 {
+		return false, 0, 0, fmt.Errorf("unexpected rate limit script result: %v", result)
 	}
+	flag, _ := values[0].(int64)
+	count, _ := values[1].(int64)
+	wait, _ := values[2].(int64)
 
-	// Increment burst counter (shorter window for burst protection)
-	burstKey := key + ":burst"
-	burstCount, err := rl.redisClient.Incr(ctx, burstKey)
-	if err != nil {
-		return err
-	}
+	remaining = max(0, config.RequestsPerMinute-int(count))
+	return flag == 1, remaining, time.Duration(wait) * time.Microsecond, nil
+}
 
-	// Set shorter expiration for burst counter (e.g., 10 seconds)
-	if burstCount == 1 {
-		err = rl.redisClient.Expire(ctx, burstKey, 10*time.Second)
-		if err != nil {
-			return err
-		}
-	}
+func (rl *RateLimiter) release(ctx context.Context, key, requestID string) error {
+	_, err := rl.redisClient.RunScript(ctx, releaseScript, rl.keys(key), requestID)
+	return err
+}
 
-	return nil
+// retryAfterSeconds rounds a wait up to whole seconds, and at least one so
+// clients never retry immediately
+func retryAfterSeconds(wait time.Duration) int {
+	return max(1, int(math.Ceil(wait.Seconds())))
 }
 
 // Helper function for max
@@ -233,21 +347,33 @@ func max(a, b int) int {
 
 // Rate limit middleware factory functions
 func RateLimitMiddleware(redisClient *redis.Client, logger log.Logger) gin.HandlerFunc {
-	limiter := NewRateLimiter(redisClient, logger, DefaultRateLimit())
-	return limiter.Middleware()
+	return presetRateLimit(redisClient, logger, DefaultRateLimit())
 }
 
 func StrictRateLimitMiddleware(redisClient *redis.Client, logger log.Logger) gin.HandlerFunc {
-	limiter := NewRateLimiter(redisClient, logger, StrictRateLimit())
-	return limiter.Middleware()
+	return presetRateLimit(redisClient, logger, StrictRateLimit())
 }
 
 func AuthUserRateLimitMiddleware(redisClient *redis.Client, logger log.Logger) gin.HandlerFunc {
-	limiter := NewRateLimiter(redisClient, logger, AuthenticatedUserRateLimit())
-	return limiter.Middleware()
+	return presetRateLimit(redisClient, logger, AuthenticatedUserRateLimit())
 }
 
 func ExpensiveOpRateLimitMiddleware(redisClient *redis.Client, logger log.Logger) gin.HandlerFunc {
-	limiter := NewRateLimiter(redisClient, logger, ExpensiveOperationRateLimit())
+	return presetRateLimit(redisClient, logger, ExpensiveOperationRateLimit())
+}
+
+// presetRateLimit applies one of the preset configs, which have no
+// allowlist that could fail to parse
+func presetRateLimit(redisClient *redis.Client, logger log.Logger, config RateLimitConfig) gin.HandlerFunc {
+	limiter := NewResolvingRateLimiter(redisClient, logger, func(*gin.Context) RateLimitConfig {
+		return config
+	})
 	return limiter.Middleware()
-}
\ No newline at end of file
+}
+
+// RateLimitFor limits requests with configs resolved per request, so one
+// middleware can apply different limits to different route groups
+func RateLimitFor(redisClient *redis.Client, logger log.Logger, configFn func(*gin.Context) RateLimitConfig) gin.HandlerFunc {
+	limiter := NewResolvingRateLimiter(redisClient, logger, configFn)
+	return limiter.Middleware()
+}
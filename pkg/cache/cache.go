@@ -9,7 +9,9 @@ import (
 	"time"
 
 	"github.com/0xsj/mios.io/log"
+	"github.com/0xsj/mios.io/pkg/metrics"
 	"github.com/0xsj/mios.io/pkg/redis"
+	"golang.org/x/sync/singleflight"
 )
 
 type CacheService interface {
@@ -24,13 +26,27 @@ type RedisCache struct {
 	client *redis.Client
 	logger log.Logger
 	prefix string
+
+	// bus, when set, tells other instances about deletions
+	bus *InvalidationBus
+
+	// fetches coalesces concurrent misses on the same key into one fetch
+	fetches singleflight.Group
+
+	// metrics, when set, records every operation and the hit ratio of each
+	// cache type
+	metrics   *metrics.Metrics
+	hitRatios *hitRatios
 }
 
-func NewRedisCache(client *redis.Client, logger log.Logger, prefix string) CacheService {
+func NewRedisCache(client *redis.Client, logger log.Logger, prefix string, bus *InvalidationBus, m *metrics.Metrics) CacheService {
 	return &RedisCache{
-		client: client,
-		logger: logger,
-		prefix: prefix,
+		client:    client,
+		logger:    logger,
+		prefix:    prefix,
+		bus:       bus,
+		metrics:   m,
+		hitRatios: newHitRatios(),
 	}
 }
 
@@ -42,6 +58,21 @@ func (c *RedisCache) buildKey(key string) string {
 }
 
 func (c *RedisCache) Get(ctx context.Context, key string, dest interface{}) (bool, error) {
+	start := time.Now()
+	found, err := c.get(ctx, key, dest)
+
+	result := "miss"
+	if err != nil {
+		result = "error"
+	} else if found {
+		result = "hit"
+	}
+	c.recordLookup("get", key, result, start)
+
+	return found, err
+}
+
+func (c *RedisCache) get(ctx context.Context, key string, dest interface{}) (bool, error) {
 	fullKey := c.buildKey(key)
 	
 	data, err := c.client.Get(ctx, fullKey)
@@ -62,7 +93,8 @@ func (c *RedisCache) Get(ctx context.Context, key string, dest interface{}) (boo
 	return true, nil
 }
 
-func (c *RedisCache) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+func (c *RedisCache) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) (err error) {
+	defer c.recordOperation("set", time.Now(), &err)
 	fullKey := c.buildKey(key)
 	
 	data, err := json.Marshal(value)
@@ -80,7 +112,8 @@ func (c *RedisCache) Set(ctx context.Context, key string, value interface{}, ttl
 	return nil
 }
 
-func (c *RedisCache) Delete(ctx context.Context, keys ...string) error {
+func (c *RedisCache) Delete(ctx context.Context, keys ...string) (err error) {
+	defer c.recordOperation("delete", time.Now(), &err)
 	if len(keys) == 0 {
 		return nil
 	}
@@ -96,10 +129,12 @@ func (c *RedisCache) Delete(ctx context.Context, keys ...string) error {
 	}
 
 	c.logger.Debugf("Cache deleted keys: %v", fullKeys)
+	c.publishInvalidation(ctx, Invalidation{Keys: fullKeys})
 	return nil
 }
 
-func (c *RedisCache) DeletePattern(ctx context.Context, pattern string) error {
+func (c *RedisCache) DeletePattern(ctx context.Context, pattern string) (err error) {
+	defer c.recordOperation("delete_pattern", time.Now(), &err)
 	fullPattern := c.buildKey(pattern)
 	
 	keys, err := c.client.Keys(ctx, fullPattern)
@@ -108,6 +143,9 @@ func (c *RedisCache) DeletePattern(ctx context.Context, pattern string) error {
 		return err
 	}
 
+	// Other instances may hold entries for the pattern that Redis does not
+	c.publishInvalidation(ctx, Invalidation{Patterns: []string{fullPattern}})
+
 	if len(keys) == 0 {
 		return nil
 	}
@@ -121,38 +159,89 @@ func (c *RedisCache) DeletePattern(ctx context.Context, pattern string) error {
 	return nil
 }
 
+// publishInvalidation broadcasts a deletion; failures only delay other
+// instances until their copies expire, so they are logged and not returned
+func (c *RedisCache) publishInvalidation(ctx context.Context, inv Invalidation) {
+	if c.bus == nil {
+		return
+	}
+	if err := c.bus.Publish(ctx, inv); err != nil {
+		c.logger.Warnf("Failed to broadcast cache invalidation: %v", err)
+	}
+}
+
+// GetOrSet records a single "get_or_set" lookup whose result is "hit" when
+// dest was served from the cache, "miss" when this call fetched it, "shared"
+// when it waited on another caller's fetch, or "error"
 func (c *RedisCache) GetOrSet(ctx context.Context, key string, dest interface{}, ttl time.Duration, fetchFn func() (interface{}, error)) error {
+	start := time.Now()
+
 	// Try to get from cache first
-	found, err := c.Get(ctx, key, dest)
+	found, err := c.get(ctx, key, dest)
 	if err != nil {
 		c.logger.Warnf("Cache get error, fetching fresh data: %v", err)
 	}
 	
 	if found && err == nil {
+		c.recordLookup("get_or_set", key, "hit", start)
 		return nil // Cache hit
 	}
 
-	// Cache miss, fetch fresh data
+	// Cache miss, fetch fresh data. Concurrent misses on the key wait for the
+	// first caller's fetch instead of each querying the database; they share
+	// its JSON so every caller decodes into its own dest.
 	c.logger.Debugf("Cache miss for key: %s, fetching fresh data", key)
-	
-	value, err := fetchFn()
+
+	data, err, shared := c.fetches.Do(c.buildKey(key), func() (interface{}, error) {
+		value, err := fetchFn()
+		if err != nil {
+			return nil, err
+		}
+
+		// Store in cache for next time
+		if setErr := c.Set(ctx, key, value, ttl); setErr != nil {
+			c.logger.Warnf("Failed to cache data for key %s: %v", key, setErr)
+			// Don't return error, just log it since we have the data
+		}
+
+		return json.Marshal(value)
+	})
 	if err != nil {
+		c.recordLookup("get_or_set", key, "error", start)
 		return err
 	}
 
-	// Store in cache for next time
-	if setErr := c.Set(ctx, key, value, ttl); setErr != nil {
-		c.logger.Warnf("Failed to cache data for key %s: %v", key, setErr)
-		// Don't return error, just log it since we have the data
+	result := "miss"
+	if shared {
+		c.logger.Debugf("Shared fetch for key: %s", key)
+		result = "shared"
 	}
+	c.recordLookup("get_or_set", key, result, start)
 
-	// Marshal the fresh data into dest
-	data, err := json.Marshal(value)
-	if err != nil {
-		return err
+	return json.Unmarshal(data.([]byte), dest)
+}
+
+// recordLookup records a read and updates the hit ratio of the key's type
+func (c *RedisCache) recordLookup(operation, key, result string, start time.Time) {
+	if c.metrics == nil {
+		return
 	}
+	c.metrics.RecordCacheOperation(operation, result, time.Since(start))
+	kind := cacheType(key)
+	c.metrics.CacheHitRatio.WithLabelValues(kind).Set(c.hitRatios.record(kind, result == "hit"))
+}
 
-	return json.Unmarshal(data, dest)
+// recordOperation records a write; it is deferred with the operation's
+// named error result
+func (c *RedisCache) recordOperation(operation string, start time.Time, err *error) {
+	if c.metrics == nil {
+		return
+	}
+	result := "success"
+	if *err != nil {
+		result = "error"
+	}
+	c.metrics.RecordCacheOperation(operation, result, time.Since(start))
 }
 
 // Cache key builders for different operations
@@ -170,14 +259,18 @@ func (kb *CacheKeyBuilder) ProfileDashboard(userID string, days int) string {
 	return fmt.Sprintf("dashboard:user:%s:days:%d", userID, days)
 }
 
+func (kb *CacheKeyBuilder) SummaryCards(userID string, days int) string {
+	return fmt.Sprintf("summary:user:%s:days:%d", userID, days)
+}
+
 func (kb *CacheKeyBuilder) ContentItemAnalytics(itemID string, timeRange string) string {
 	hash := kb.HashString(timeRange)
 	return fmt.Sprintf("analytics:item:%s:range:%s", itemID, hash)
 }
 
-func (kb *CacheKeyBuilder) TimeRangeAnalytics(userID, startDate, endDate string) string {
+func (kb *CacheKeyBuilder) TimeRangeAnalytics(userID, startDate, endDate, granularity string) string {
 	hash := kb.HashString(startDate + endDate)
-	return fmt.Sprintf("analytics:user:%s:timerange:%s", userID, hash)
+	return fmt.Sprintf("analytics:user:%s:timerange:%s:%s", userID, granularity, hash)
 }
 
 func (kb *CacheKeyBuilder) ReferrerAnalytics(userID, startDate, endDate string, limit int) string {
@@ -185,6 +278,21 @@ func (kb *CacheKeyBuilder) ReferrerAnalytics(userID, startDate, endDate string,
 	return fmt.Sprintf("analytics:referrer:%s:range:%s", userID, hash)
 }
 
+func (kb *CacheKeyBuilder) DeviceBreakdown(userID, startDate, endDate string) string {
+	hash := kb.HashString(startDate + endDate)
+	return fmt.Sprintf("analytics:user:%s:devices:%s", userID, hash)
+}
+
+func (kb *CacheKeyBuilder) GeoAnalytics(userID, startDate, endDate string, limit int) string {
+	hash := kb.HashString(fmt.Sprintf("%s:%s:%d", startDate, endDate, limit))
+	return fmt.Sprintf("analytics:user:%s:geo:%s", userID, hash)
+}
+
+func (kb *CacheKeyBuilder) CampaignAnalytics(userID, startDate, endDate string, limit int) string {
+	hash := kb.HashString(fmt.Sprintf("%s:%s:%d", startDate, endDate, limit))
+	return fmt.Sprintf("analytics:user:%s:campaigns:%s", userID, hash)
+}
+
 func (kb *CacheKeyBuilder) PageViewAnalytics(userID, startDate, endDate string, limit int) string {
 	hash := kb.HashString(fmt.Sprintf("%s:%s:%d", startDate, endDate, limit))
 	return fmt.Sprintf("analytics:pageviews:user:%s:range:%s", userID, hash)
@@ -199,6 +307,18 @@ func (kb *CacheKeyBuilder) UserContent(userID string) string {
 	return fmt.Sprintf("content:user:%s", userID)
 }
 
+func (kb *CacheKeyBuilder) ContentSummary(userID string) string {
+	return fmt.Sprintf("content:user:%s:summary", userID)
+}
+
+func (kb *CacheKeyBuilder) ProfileMeta(handle string) string {
+	return fmt.Sprintf("profile:handle:%s:meta", handle)
+}
+
+func (kb *CacheKeyBuilder) ProfileEmbed(handle string) string {
+	return fmt.Sprintf("profile:handle:%s:embed", handle)
+}
+
 // HashString is exported so it can be used from other packages
 func (kb *CacheKeyBuilder) HashString(s string) string {
 	return fmt.Sprintf("%x", md5.Sum([]byte(s)))[:8]
@@ -239,6 +359,10 @@ func GetContentTTL() time.Duration {
 	return DefaultTTL // Content changes require quick updates
 }
 
+func GetProfileTTL() time.Duration {
+	return ShortTTL // Public profiles change through other services
+}
+
 func GetMetadataTTL() time.Duration {
 	return DayTTL // Link metadata rarely changes
 }
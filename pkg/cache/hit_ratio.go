@@ -0,0 +1,63 @@
+// pkg/cache/hit_ratio.go
+package cache
+
+import (
+	"strings"
+	"sync"
+)
+
+// hitRatioWindow is how many of the most recent lookups of a cache type the
+// hit ratio covers
+const hitRatioWindow = 1000
+
+// hitRatios tracks a rolling hit ratio per cache type
+type hitRatios struct {
+	mu      sync.Mutex
+	windows map[string]*hitWindow
+}
+
+// hitWindow is a ring of the outcomes of recent lookups
+type hitWindow struct {
+	outcomes [hitRatioWindow]bool
+	next     int
+	count    int
+	hits     int
+}
+
+func newHitRatios() *hitRatios {
+	return &hitRatios{windows: make(map[string]*hitWindow)}
+}
+
+// record adds a lookup of cacheType and returns its updated hit ratio
+func (r *hitRatios) record(cacheType string, hit bool) float64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	w, ok := r.windows[cacheType]
+	if !ok {
+		w = &hitWindow{}
+		r.windows[cacheType] = w
+	}
+
+	if w.count == hitRatioWindow {
+		if w.outcomes[w.next] {
+			w.hits--
+		}
+	} else {
+		w.count++
+	}
+	w.outcomes[w.next] = hit
+	if hit {
+		w.hits++
+	}
+	w.next = (w.next + 1) % hitRatioWindow
+
+	return float64(w.hits) / float64(w.count)
+}
+
+// cacheType is the first segment of a key built by CacheKeyBuilder, such as
+// "analytics" or "content"
+func cacheType(key string) string {
+	kind, _, _ := strings.Cut(key, ":")
+	return kind
+}
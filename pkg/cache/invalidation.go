@@ -0,0 +1,107 @@
+// pkg/cache/invalidation.go
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+
+	"github.com/0xsj/mios.io/log"
+	"github.com/0xsj/mios.io/pkg/redis"
+)
+
+// DefaultInvalidationChannel is the Redis channel instances share for
+// cache invalidation messages
+const DefaultInvalidationChannel = "cache:invalidations"
+
+// Invalidation names the cache entries a mutation made stale, as full keys
+// (prefix included) and key patterns
+type Invalidation struct {
+	Keys     []string `json:"keys,omitempty"`
+	Patterns []string `json:"patterns,omitempty"`
+}
+
+// InvalidationHandler drops local copies of the entries in an invalidation
+type InvalidationHandler func(ctx context.Context, inv Invalidation)
+
+// InvalidationBus broadcasts cache invalidations to every server instance
+// over Redis pub/sub. Deleting from Redis is visible to all instances, but
+// anything an instance keeps in process (an L1 layer) must be dropped by a
+// handler registered here. Every instance, the publisher included, receives
+// each message.
+type InvalidationBus struct {
+	client  *redis.Client
+	channel string
+	logger  log.Logger
+
+	mu       sync.RWMutex
+	handlers []InvalidationHandler
+}
+
+func NewInvalidationBus(client *redis.Client, logger log.Logger, channel string) *InvalidationBus {
+	if channel == "" {
+		channel = DefaultInvalidationChannel
+	}
+	return &InvalidationBus{
+		client:  client,
+		channel: channel,
+		logger:  logger,
+	}
+}
+
+// Publish tells every instance that the given entries are stale
+func (b *InvalidationBus) Publish(ctx context.Context, inv Invalidation) error {
+	data, err := json.Marshal(inv)
+	if err != nil {
+		return err
+	}
+
+	if err := b.client.Publish(ctx, b.channel, data); err != nil {
+		b.logger.Errorf("Failed to publish cache invalidation on %s: %v", b.channel, err)
+		return err
+	}
+	return nil
+}
+
+// OnInvalidate registers a handler for invalidations from any instance
+func (b *InvalidationBus) OnInvalidate(handler InvalidationHandler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers = append(b.handlers, handler)
+}
+
+// Listen delivers invalidations to the registered handlers until ctx is
+// cancelled
+func (b *InvalidationBus) Listen(ctx context.Context) {
+	pubsub := b.client.Subscribe(ctx, b.channel)
+	defer pubsub.Close()
+
+	b.logger.Infof("Listening for cache invalidations on %s", b.channel)
+
+	messages := pubsub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg, ok := <-messages:
+			if !ok {
+				return
+			}
+
+			var inv Invalidation
+			if err := json.Unmarshal([]byte(msg.Payload), &inv); err != nil {
+				b.logger.Warnf("Ignoring malformed cache invalidation: %v", err)
+				continue
+			}
+
+			b.logger.Debugf("Cache invalidation received: %d keys, %d patterns", len(inv.Keys), len(inv.Patterns))
+
+			b.mu.RLock()
+			handlers := b.handlers
+			b.mu.RUnlock()
+			for _, handler := range handlers {
+				handler(ctx, inv)
+			}
+		}
+	}
+}
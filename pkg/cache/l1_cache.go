@@ -0,0 +1,238 @@
+// pkg/cache/l1_cache.go
+package cache
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/0xsj/mios.io/log"
+)
+
+// L1Config bounds the in-process layer of an L1Cache
+type L1Config struct {
+	// MaxEntries is how many entries are kept before the least recently used
+	// is evicted
+	MaxEntries int
+	// TTL caps how long an entry is served from memory. Writes on other
+	// instances only reach this one through invalidations, so keep it short.
+	TTL time.Duration
+}
+
+// L1Cache keeps hot entries in process memory in front of another
+// CacheService, usually a RedisCache, saving a round trip on repeated reads.
+// Writes and deletes go to both layers. Deletions made by any instance are
+// dropped from memory when they arrive on the invalidation bus.
+type L1Cache struct {
+	next   CacheService
+	prefix string
+	config L1Config
+	logger log.Logger
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	lru     *list.List // front is most recently used
+}
+
+type l1Entry struct {
+	key       string
+	data      []byte
+	expiresAt time.Time
+}
+
+// NewL1Cache wraps next with an in-memory layer. prefix must match the one
+// next builds keys with so invalidations from the bus can be matched; bus
+// may be nil.
+func NewL1Cache(next CacheService, prefix string, bus *InvalidationBus, config L1Config, logger log.Logger) CacheService {
+	c := &L1Cache{
+		next:    next,
+		prefix:  prefix,
+		config:  config,
+		logger:  logger,
+		entries: make(map[string]*list.Element),
+		lru:     list.New(),
+	}
+	if bus != nil {
+		bus.OnInvalidate(c.handleInvalidation)
+	}
+	return c
+}
+
+func (c *L1Cache) Get(ctx context.Context, key string, dest interface{}) (bool, error) {
+	if data, ok := c.load(key); ok {
+		return true, json.Unmarshal(data, dest)
+	}
+
+	found, err := c.next.Get(ctx, key, dest)
+	if err != nil || !found {
+		return found, err
+	}
+	c.storeValue(key, dest, c.config.TTL)
+	return true, nil
+}
+
+func (c *L1Cache) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	if err := c.next.Set(ctx, key, value, ttl); err != nil {
+		c.remove(key)
+		return err
+	}
+	c.storeValue(key, value, ttl)
+	return nil
+}
+
+func (c *L1Cache) Delete(ctx context.Context, keys ...string) error {
+	for _, key := range keys {
+		c.remove(key)
+	}
+	return c.next.Delete(ctx, keys...)
+}
+
+func (c *L1Cache) DeletePattern(ctx context.Context, pattern string) error {
+	c.removeMatching(pattern)
+	return c.next.DeletePattern(ctx, pattern)
+}
+
+func (c *L1Cache) GetOrSet(ctx context.Context, key string, dest interface{}, ttl time.Duration, fetchFn func() (interface{}, error)) error {
+	if data, ok := c.load(key); ok {
+		return json.Unmarshal(data, dest)
+	}
+
+	if err := c.next.GetOrSet(ctx, key, dest, ttl, fetchFn); err != nil {
+		return err
+	}
+	c.storeValue(key, dest, ttl)
+	return nil
+}
+
+// load returns the data stored for key unless it has expired
+func (c *L1Cache) load(key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	entry := elem.Value.(*l1Entry)
+	if time.Now().After(entry.expiresAt) {
+		c.lru.Remove(elem)
+		delete(c.entries, key)
+		return nil, false
+	}
+
+	c.lru.MoveToFront(elem)
+	return entry.data, true
+}
+
+// storeValue keeps value as JSON so every reader decodes its own copy. It
+// expires after the L1 TTL or ttl, whichever is sooner.
+func (c *L1Cache) storeValue(key string, value interface{}, ttl time.Duration) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		c.logger.Warnf("L1 cache marshal error for key %s: %v", key, err)
+		return
+	}
+	if ttl <= 0 || ttl > c.config.TTL {
+		ttl = c.config.TTL
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry := &l1Entry{key: key, data: data, expiresAt: time.Now().Add(ttl)}
+	if elem, ok := c.entries[key]; ok {
+		elem.Value = entry
+		c.lru.MoveToFront(elem)
+		return
+	}
+
+	c.entries[key] = c.lru.PushFront(entry)
+	for c.lru.Len() > c.config.MaxEntries {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(*l1Entry).key)
+	}
+}
+
+func (c *L1Cache) remove(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.entries[key]; ok {
+		c.lru.Remove(elem)
+		delete(c.entries, key)
+	}
+}
+
+// removeMatching drops entries whose keys match a Redis glob pattern
+func (c *L1Cache) removeMatching(pattern string) {
+	re, err := globPattern(pattern)
+	if err != nil {
+		c.logger.Warnf("Clearing L1 cache, unsupported pattern %q: %v", pattern, err)
+		re = regexp.MustCompile(".*")
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key, elem := range c.entries {
+		if re.MatchString(key) {
+			c.lru.Remove(elem)
+			delete(c.entries, key)
+		}
+	}
+}
+
+// handleInvalidation drops entries another instance (or this one) deleted.
+// Invalidations carry full keys, so the prefix is stripped to match ours.
+func (c *L1Cache) handleInvalidation(ctx context.Context, inv Invalidation) {
+	for _, key := range inv.Keys {
+		if key, ok := c.unprefixed(key); ok {
+			c.remove(key)
+		}
+	}
+	for _, pattern := range inv.Patterns {
+		if pattern, ok := c.unprefixed(pattern); ok {
+			c.removeMatching(pattern)
+		}
+	}
+}
+
+func (c *L1Cache) unprefixed(key string) (string, bool) {
+	if c.prefix == "" {
+		return key, true
+	}
+	return strings.CutPrefix(key, c.prefix+":")
+}
+
+// globPattern compiles the subset of Redis glob syntax the key builders use:
+// * and ? wildcards, with \ escaping the next character
+func globPattern(pattern string) (*regexp.Regexp, error) {
+	var b strings.Builder
+	b.WriteString("(?s)^")
+	runes := []rune(pattern)
+	for i := 0; i < len(runes); i++ {
+		switch r := runes[i]; r {
+		case '*':
+			b.WriteString(".*")
+		case '?':
+			b.WriteString(".")
+		case '[':
+			return nil, fmt.Errorf("character classes are not supported")
+		case '\\':
+			if i+1 < len(runes) {
+				i++
+				b.WriteString(regexp.QuoteMeta(string(runes[i])))
+			}
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	b.WriteString("$")
+	return regexp.Compile(b.String())
+}
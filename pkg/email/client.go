@@ -58,12 +58,18 @@ func (c *EmailClient) Send(msg Message) error {
 	return c.sendEmail(msg.To, msg.Subject, msg.Body, msg.IsHTML)
 }
 
-// SendTemplate sends an email using a template
+// SendTemplate sends an email using the default version of a template
 func (c *EmailClient) SendTemplate(to []string, subject, templateName string, data interface{}) error {
-	c.logger.Debugf("Sending template email '%s' to %v", templateName, to)
+	return c.SendLocalizedTemplate(to, subject, templateName, "", data)
+}
+
+// SendLocalizedTemplate sends an email using the template translated for
+// locale, falling back through the locale chain when there is no exact match
+func (c *EmailClient) SendLocalizedTemplate(to []string, subject, templateName, locale string, data interface{}) error {
+	c.logger.Debugf("Sending template email '%s' (locale %q) to %v", templateName, locale, to)
 
 	// Render the template
-	body, err := c.templateManager.Render(templateName, data)
+	body, err := c.templateManager.RenderLocalized(templateName, locale, data)
 	if err != nil {
 		c.logger.Errorf("Failed to render template %s: %v", templateName, err)
 		return err
@@ -0,0 +1,107 @@
+// pkg/email/locale.go
+package email
+
+import (
+	"regexp"
+	"strings"
+)
+
+// DefaultFallbackLocale is used when no fallback locale is configured
+const DefaultFallbackLocale = "en"
+
+// Text directions passed to templates as Dir
+const (
+	DirLTR = "ltr"
+	DirRTL = "rtl"
+)
+
+var localePattern = regexp.MustCompile(`^[a-zA-Z]{2,3}([-_][a-zA-Z0-9]{2,8})*$`)
+
+// rtlLanguages are the languages written right to left
+var rtlLanguages = map[string]bool{
+	"ar": true,
+	"fa": true,
+	"he": true,
+	"iw": true,
+	"ps": true,
+	"sd": true,
+	"ug": true,
+	"ur": true,
+	"yi": true,
+}
+
+// NormalizeLocale checks that locale looks like a BCP 47 tag such as "es" or
+// "es-MX" and returns it with a lowercase language and uppercase region, so
+// "pt_br" becomes "pt-BR"
+func NormalizeLocale(locale string) (string, bool) {
+	locale = strings.TrimSpace(locale)
+	if !localePattern.MatchString(locale) {
+		return "", false
+	}
+
+	parts := strings.Split(strings.ReplaceAll(locale, "_", "-"), "-")
+	parts[0] = strings.ToLower(parts[0])
+	for i := 1; i < len(parts); i++ {
+		switch len(parts[i]) {
+		case 2:
+			parts[i] = strings.ToUpper(parts[i])
+		case 4:
+			// Script subtags are title case, e.g. zh-Hant
+			parts[i] = strings.ToUpper(parts[i][:1]) + strings.ToLower(parts[i][1:])
+		default:
+			parts[i] = strings.ToLower(parts[i])
+		}
+	}
+	return strings.Join(parts, "-"), true
+}
+
+// LocaleChain lists the locales to try for a template, most specific first:
+// "es-MX" gives es-MX, es, then fallback. Invalid locales are skipped, so an
+// unknown or empty locale resolves straight to the fallback.
+func LocaleChain(locale, fallback string) []string {
+	var chain []string
+	seen := make(map[string]bool)
+	add := func(tag string) {
+		if !seen[tag] {
+			seen[tag] = true
+			chain = append(chain, tag)
+		}
+	}
+
+	for _, tag := range []string{locale, fallback} {
+		normalized, ok := NormalizeLocale(tag)
+		if !ok {
+			continue
+		}
+		parts := strings.Split(normalized, "-")
+		for i := len(parts); i > 0; i-- {
+			add(strings.Join(parts[:i], "-"))
+		}
+	}
+
+	return chain
+}
+
+// TextDirection returns DirRTL for locales of right-to-left languages such
+// as Arabic and Hebrew, and DirLTR otherwise
+func TextDirection(locale string) string {
+	normalized, ok := NormalizeLocale(locale)
+	if !ok {
+		return DirLTR
+	}
+
+	parts := strings.Split(normalized, "-")
+	for _, part := range parts[1:] {
+		switch part {
+		case "Arab", "Hebr", "Thaa", "Syrc", "Nkoo", "Adlm", "Rohg":
+			return DirRTL
+		case "Latn", "Cyrl":
+			return DirLTR
+		}
+	}
+
+	if rtlLanguages[parts[0]] {
+		return DirRTL
+	}
+	return DirLTR
+}
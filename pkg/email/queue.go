@@ -0,0 +1,76 @@
+// pkg/email/queue.go
+package email
+
+import (
+	"context"
+	"errors"
+
+	"github.com/0xsj/mios.io/log"
+)
+
+// ErrQueueFull is returned when an email cannot be queued without blocking
+var ErrQueueFull = errors.New("email queue is full")
+
+const defaultQueueSize = 100
+
+type queuedEmail struct {
+	to       []string
+	subject  string
+	template string
+	locale   string
+	data     interface{}
+}
+
+// Queue sends template emails in the background so callers don't wait on
+// SMTP. Emails still queued when Run stops are dropped.
+type Queue struct {
+	client *EmailClient
+	jobs   chan queuedEmail
+	logger log.Logger
+}
+
+// NewQueue creates a queue holding up to size pending emails
+func NewQueue(client *EmailClient, logger log.Logger, size int) *Queue {
+	if size <= 0 {
+		size = defaultQueueSize
+	}
+
+	return &Queue{
+		client: client,
+		jobs:   make(chan queuedEmail, size),
+		logger: logger.WithLayer("EmailQueue"),
+	}
+}
+
+// EnqueueTemplate queues a template email, failing with ErrQueueFull
+// rather than blocking when the queue has no room
+func (q *Queue) EnqueueTemplate(to []string, subject, templateName string, data interface{}) error {
+	return q.EnqueueLocalizedTemplate(to, subject, templateName, "", data)
+}
+
+// EnqueueLocalizedTemplate queues a template email to be rendered for locale
+func (q *Queue) EnqueueLocalizedTemplate(to []string, subject, templateName, locale string, data interface{}) error {
+	select {
+	case q.jobs <- queuedEmail{to: to, subject: subject, template: templateName, locale: locale, data: data}:
+		return nil
+	default:
+		return ErrQueueFull
+	}
+}
+
+// Run sends queued emails one at a time until ctx is cancelled
+func (q *Queue) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			if pending := len(q.jobs); pending > 0 {
+				q.logger.Warnf("Email queue stopped with %d emails unsent", pending)
+			}
+			return
+		case job := <-q.jobs:
+			if err := q.client.SendLocalizedTemplate(job.to, job.subject, job.template, job.locale, job.data); err != nil {
+				q.logger.Errorf("Failed to send queued email '%s' to %v: %v", job.template, job.to, err)
+			}
+		}
+	}
+}
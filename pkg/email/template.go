@@ -8,15 +8,27 @@ import (
 	"path/filepath"
 )
 
+// TemplateManager loads the email templates under baseDir. Templates at the
+// top level are the default versions; translations live in a directory per
+// locale, e.g. es/verification.html or es-MX/verification.html.
 type TemplateManager struct {
-	templates map[string]*template.Template
-	baseDir   string
+	templates      map[string]*template.Template
+	baseDir        string
+	fallbackLocale string
 }
 
-func NewTemplateManager(baseDir string) (*TemplateManager, error) {
+// NewTemplateManager loads every template under baseDir. Localized renders
+// fall back to fallbackLocale, then to the top level template, when no
+// translation exists for the requested locale.
+func NewTemplateManager(baseDir, fallbackLocale string) (*TemplateManager, error) {
+	if fallbackLocale == "" {
+		fallbackLocale = DefaultFallbackLocale
+	}
+
 	manager := &TemplateManager{
-		templates: make(map[string]*template.Template),
-		baseDir:   baseDir,
+		templates:      make(map[string]*template.Template),
+		baseDir:        baseDir,
+		fallbackLocale: fallbackLocale,
 	}
 
 	// Load all templates
@@ -58,8 +70,12 @@ func (m *TemplateManager) loadTemplates() error {
 			return err
 		}
 
-		// Parse template
-		name := filepath.Base(path)
+		// Parse template, keyed by its path so translations don't collide
+		rel, err := filepath.Rel(m.baseDir, path)
+		if err != nil {
+			return err
+		}
+		name := filepath.ToSlash(rel)
 		tmpl, err := template.New(name).Parse(string(content))
 		if err != nil {
 			return err
@@ -86,6 +102,46 @@ func (m *TemplateManager) Render(name string, data interface{}) (string, error)
 	return buf.String(), nil
 }
 
+// Resolve picks the template to use for name in locale, walking the locale
+// chain (es-MX, es, then the fallback locale) before settling on the top
+// level template. It returns the template key and the locale it was found
+// for, which is the fallback locale for top level templates.
+func (m *TemplateManager) Resolve(name, locale string) (string, string, error) {
+	for _, tag := range LocaleChain(locale, m.fallbackLocale) {
+		key := tag + "/" + name
+		if _, exists := m.templates[key]; exists {
+			return key, tag, nil
+		}
+	}
+
+	if _, exists := m.templates[name]; !exists {
+		return "", "", fmt.Errorf("template %s not found", name)
+	}
+	return name, m.fallbackLocale, nil
+}
+
+// RenderLocalized renders the best template for locale. Map data gets
+// Locale and Dir ("ltr" or "rtl") added for the html lang and dir
+// attributes, unless the caller already set them.
+func (m *TemplateManager) RenderLocalized(name, locale string, data interface{}) (string, error) {
+	key, resolved, err := m.Resolve(name, locale)
+	if err != nil {
+		return "", err
+	}
+
+	if values, ok := data.(map[string]interface{}); ok {
+		localized := make(map[string]interface{}, len(values)+2)
+		localized["Locale"] = resolved
+		localized["Dir"] = TextDirection(resolved)
+		for k, v := range values {
+			localized[k] = v
+		}
+		data = localized
+	}
+
+	return m.Render(key, data)
+}
+
 // Add adds a template
 func (m *TemplateManager) Add(name, content string) error {
 	tmpl, err := template.New(name).Parse(content)
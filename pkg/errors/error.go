@@ -45,6 +45,13 @@ type AppError struct {
 	Code     string
 	Status   int
 	LogLevel LogLevel
+	Details  any
+}
+
+// FieldError describes why a single input field was rejected
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
 }
 
 func (e *AppError) Error() string {
@@ -161,6 +168,14 @@ func NewValidationError(message string, err error) *AppError {
 	}
 }
 
+// NewFieldValidationError is a validation error that reports each rejected
+// field in the response details
+func NewFieldValidationError(message string, fields []FieldError) *AppError {
+	appErr := NewValidationError(message, nil)
+	appErr.Details = fields
+	return appErr
+}
+
 func NewDatabaseError(message string, err error) *AppError {
 	return &AppError{
 		Err:      err,
@@ -181,6 +196,16 @@ func NewExternalServiceError(message string, err error) *AppError {
 	}
 }
 
+func NewServiceUnavailableError(message string, err error) *AppError {
+	return &AppError{
+		Err:      err,
+		Message:  message,
+		Code:     "SERVICE_UNAVAILABLE",
+		Status:   http.StatusServiceUnavailable,
+		LogLevel: LogLevelWarn,
+	}
+}
+
 func Wrap(err error, message string) error {
 	if err == nil {
 		return nil
@@ -0,0 +1,138 @@
+// Package geoip resolves IPv4 addresses to a country and city using an
+// in-memory range table loaded from a MaxMind-style CSV export. Lookups are a
+// binary search over sorted ranges, so the table is read once at startup and
+// shared by every request.
+package geoip
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Unknown is reported for addresses that cannot be located
+const Unknown = "Unknown"
+
+// Location is where an address was resolved to. City may be empty when the
+// database only knows the country.
+type Location struct {
+	Country string
+	City    string
+}
+
+// Lookup resolves an address to a location. The boolean is false when the
+// address is not covered.
+type Lookup interface {
+	Lookup(ip net.IP) (Location, bool)
+}
+
+// ipRange covers the IPv4 addresses from start to end inclusive
+type ipRange struct {
+	start    uint32
+	end      uint32
+	location Location
+}
+
+// Database is a Lookup backed by a sorted table of IPv4 ranges
+type Database struct {
+	ranges []ipRange
+}
+
+// Open loads a database from a CSV file
+func Open(path string) (*Database, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open geoip database: %w", err)
+	}
+	defer file.Close()
+
+	return Load(file)
+}
+
+// Load reads "network,country,city" rows where network is an IPv4 CIDR block.
+// Blank lines, '#' comments and a leading "network" header are skipped, as are
+// IPv6 networks.
+func Load(r io.Reader) (*Database, error) {
+	db := &Database{}
+
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		fields := strings.Split(text, ",")
+		for i := range fields {
+			fields[i] = strings.Trim(strings.TrimSpace(fields[i]), `"`)
+		}
+		if line == 1 && strings.EqualFold(fields[0], "network") {
+			continue
+		}
+		if len(fields) < 2 {
+			return nil, fmt.Errorf("geoip database line %d: expected network,country[,city]", line)
+		}
+
+		_, network, err := net.ParseCIDR(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("geoip database line %d: %w", line, err)
+		}
+		ip4 := network.IP.To4()
+		if ip4 == nil {
+			continue
+		}
+
+		start := binary.BigEndian.Uint32(ip4)
+		mask := binary.BigEndian.Uint32(net.IP(network.Mask).To4())
+		location := Location{Country: fields[1]}
+		if len(fields) > 2 {
+			location.City = fields[2]
+		}
+
+		db.ranges = append(db.ranges, ipRange{
+			start:    start,
+			end:      start | ^mask,
+			location: location,
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read geoip database: %w", err)
+	}
+
+	sort.Slice(db.ranges, func(i, j int) bool {
+		return db.ranges[i].start < db.ranges[j].start
+	})
+
+	return db, nil
+}
+
+// Len returns the number of ranges in the database
+func (d *Database) Len() int {
+	return len(d.ranges)
+}
+
+// Lookup finds the range containing ip. Only IPv4 addresses are covered.
+func (d *Database) Lookup(ip net.IP) (Location, bool) {
+	ip4 := ip.To4()
+	if ip4 == nil {
+		return Location{}, false
+	}
+	addr := binary.BigEndian.Uint32(ip4)
+
+	// First range starting after addr; the candidate is the one before it
+	i := sort.Search(len(d.ranges), func(i int) bool {
+		return d.ranges[i].start > addr
+	}) - 1
+	if i < 0 || addr > d.ranges[i].end {
+		return Location{}, false
+	}
+
+	return d.ranges[i].location, true
+}
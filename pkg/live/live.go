@@ -0,0 +1,178 @@
+// Package live fans analytics events out to the clients watching a user's
+// stream. Events are published on a per-user channel so an instance only
+// receives the traffic its connected clients asked for.
+package live
+
+import (
+	"context"
+	"sync"
+
+	"github.com/0xsj/mios.io/log"
+	"github.com/0xsj/mios.io/pkg/redis"
+)
+
+// DefaultChannelPrefix is prepended to the user ID to name a user's channel
+const DefaultChannelPrefix = "analytics:live:"
+
+// subscriptionBuffer is how many undelivered events a subscription holds
+// before newer ones are dropped
+const subscriptionBuffer = 64
+
+// Broker publishes a user's events to every subscriber of that user
+type Broker interface {
+	Publish(ctx context.Context, userID string, payload []byte) error
+	Subscribe(ctx context.Context, userID string) (Subscription, error)
+}
+
+// Subscription delivers the payloads published for one user until it is
+// closed. Messages is closed once the subscription ends.
+type Subscription interface {
+	Messages() <-chan []byte
+	Close() error
+}
+
+// RedisBroker is a Broker backed by Redis pub/sub, so events recorded on
+// one instance reach clients connected to any other
+type RedisBroker struct {
+	client *redis.Client
+	prefix string
+	logger log.Logger
+}
+
+func NewRedisBroker(client *redis.Client, logger log.Logger, prefix string) *RedisBroker {
+	if prefix == "" {
+		prefix = DefaultChannelPrefix
+	}
+	return &RedisBroker{
+		client: client,
+		prefix: prefix,
+		logger: logger,
+	}
+}
+
+// Publish sends a payload to the user's subscribers
+func (b *RedisBroker) Publish(ctx context.Context, userID string, payload []byte) error {
+	return b.client.Publish(ctx, b.prefix+userID, payload)
+}
+
+// Subscribe listens on the user's channel. The subscription is confirmed
+// before returning so a Redis failure surfaces here rather than as a
+// silently empty stream.
+func (b *RedisBroker) Subscribe(ctx context.Context, userID string) (Subscription, error) {
+	channel := b.prefix + userID
+	pubsub := b.client.Subscribe(ctx, channel)
+	if _, err := pubsub.Receive(ctx); err != nil {
+		pubsub.Close()
+		return nil, err
+	}
+
+	b.logger.Debugf("Subscribed to live events on %s", channel)
+
+	sub := &redisSubscription{
+		pubsub:   pubsub,
+		messages: make(chan []byte, subscriptionBuffer),
+	}
+	go sub.relay()
+	return sub, nil
+}
+
+type redisSubscription struct {
+	pubsub   *redis.PubSub
+	messages chan []byte
+}
+
+// relay copies payloads off the Redis channel until the pubsub is closed,
+// dropping events a slow reader has no room for
+func (s *redisSubscription) relay() {
+	defer close(s.messages)
+	for msg := range s.pubsub.Channel() {
+		select {
+		case s.messages <- []byte(msg.Payload):
+		default:
+		}
+	}
+}
+
+func (s *redisSubscription) Messages() <-chan []byte {
+	return s.messages
+}
+
+func (s *redisSubscription) Close() error {
+	return s.pubsub.Close()
+}
+
+// MemoryBroker is an in-process Broker. It only reaches subscribers on the
+// same instance, which is enough for a single server and for tests.
+type MemoryBroker struct {
+	mu   sync.Mutex
+	subs map[string]map[*memorySubscription]struct{}
+}
+
+func NewMemoryBroker() *MemoryBroker {
+	return &MemoryBroker{
+		subs: make(map[string]map[*memorySubscription]struct{}),
+	}
+}
+
+// Publish hands the payload to each subscriber of the user. A subscriber
+// whose buffer is full misses the event instead of blocking the publisher.
+func (b *MemoryBroker) Publish(ctx context.Context, userID string, payload []byte) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for sub := range b.subs[userID] {
+		select {
+		case sub.messages <- payload:
+		default:
+		}
+	}
+	return nil
+}
+
+func (b *MemoryBroker) Subscribe(ctx context.Context, userID string) (Subscription, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	sub := &memorySubscription{
+		broker:   b,
+		userID:   userID,
+		messages: make(chan []byte, subscriptionBuffer),
+	}
+	if b.subs[userID] == nil {
+		b.subs[userID] = make(map[*memorySubscription]struct{})
+	}
+	b.subs[userID][sub] = struct{}{}
+	return sub, nil
+}
+
+// Subscribers returns how many open subscriptions the user has
+func (b *MemoryBroker) Subscribers(userID string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.subs[userID])
+}
+
+type memorySubscription struct {
+	broker   *MemoryBroker
+	userID   string
+	messages chan []byte
+	once     sync.Once
+}
+
+func (s *memorySubscription) Messages() <-chan []byte {
+	return s.messages
+}
+
+func (s *memorySubscription) Close() error {
+	s.once.Do(func() {
+		s.broker.mu.Lock()
+		defer s.broker.mu.Unlock()
+
+		delete(s.broker.subs[s.userID], s)
+		if len(s.broker.subs[s.userID]) == 0 {
+			delete(s.broker.subs, s.userID)
+		}
+		close(s.messages)
+	})
+	return nil
+}
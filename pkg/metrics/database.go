@@ -45,5 +45,7 @@ func (d *DatabaseMetricsCollector) collectPoolMetrics() {
 	// Connection pool metrics
 	d.metrics.DBConnectionsActive.Set(float64(stats.AcquiredConns()))
 	d.metrics.DBConnectionsIdle.Set(float64(stats.IdleConns()))
+	d.metrics.DBConnectionsTotal.Set(float64(stats.TotalConns()))
+	d.metrics.DBConnectionsMax.Set(float64(stats.MaxConns()))
 }
 
@@ -2,7 +2,9 @@
 package metrics
 
 import (
+	"regexp"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
@@ -22,10 +24,14 @@ type Metrics struct {
 	HTTPRequestSize       *prometheus.HistogramVec
 	HTTPResponseSize      *prometheus.HistogramVec
 	HTTPActiveConnections prometheus.Gauge
+	HTTPInFlightRequests  prometheus.Gauge
+	HTTPRequestsShedTotal *prometheus.CounterVec
 
 	// Database metrics
 	DBConnectionsActive   prometheus.Gauge
 	DBConnectionsIdle     prometheus.Gauge
+	DBConnectionsTotal    prometheus.Gauge
+	DBConnectionsMax      prometheus.Gauge
 	DBQueriesTotal        *prometheus.CounterVec
 	DBQueryDuration       *prometheus.HistogramVec
 	DBTransactionsTotal   *prometheus.CounterVec
@@ -112,6 +118,25 @@ func NewMetrics() *Metrics {
 			},
 		),
 
+		HTTPInFlightRequests: promauto.NewGauge(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Subsystem: subsystem,
+				Name:      "http_in_flight_requests",
+				Help:      "Number of requests holding a concurrency limiter slot",
+			},
+		),
+
+		HTTPRequestsShedTotal: promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Subsystem: subsystem,
+				Name:      "http_requests_shed_total",
+				Help:      "Total number of requests rejected because the instance was saturated",
+			},
+			[]string{"endpoint"},
+		),
+
 		// Database metrics
 		DBConnectionsActive: promauto.NewGauge(
 			prometheus.GaugeOpts{
@@ -131,6 +156,24 @@ func NewMetrics() *Metrics {
 			},
 		),
 
+		DBConnectionsTotal: promauto.NewGauge(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Subsystem: "database",
+				Name:      "connections_total",
+				Help:      "Number of open database connections, including those still being established",
+			},
+		),
+
+		DBConnectionsMax: promauto.NewGauge(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Subsystem: "database",
+				Name:      "connections_max",
+				Help:      "Maximum size of the database connection pool",
+			},
+		),
+
 		DBQueriesTotal: promauto.NewCounterVec(
 			prometheus.CounterOpts{
 				Namespace: namespace,
@@ -382,10 +425,20 @@ func getStatusClass(statusCode int) string {
 	}
 }
 
-// normalizeEndpoint normalizes endpoints for metrics (remove IDs, etc.)
+// NormalizeEndpoint collapses concrete resource IDs (UUIDs and numeric
+// segments) into ":id" so paths can be used as low-cardinality labels and keys.
+// Route templates such as "/api/users/:id" pass through unchanged.
 func NormalizeEndpoint(path string) string {
-	// This is a simple implementation - you might want to use a more sophisticated
-	// approach like replacing UUIDs and IDs with placeholders
-	// For now, return the path as-is, but you could implement pattern matching
-	return path
-}
\ No newline at end of file
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if uuidSegment.MatchString(segment) || numericSegment.MatchString(segment) {
+			segments[i] = ":id"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+var (
+	uuidSegment    = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+	numericSegment = regexp.MustCompile(`^[0-9]+$`)
+)
@@ -0,0 +1,45 @@
+package password
+
+// commonPasswords are the most used passwords and password words from
+// public breach corpora, most common first. Entries are lowercase; the
+// estimator tries capitalised and leet variants itself.
+var commonPasswords = []string{
+	"123456", "password", "12345678", "qwerty", "123456789", "12345", "1234",
+	"111111", "1234567", "dragon", "123123", "baseball", "abc123", "football",
+	"monkey", "letmein", "shadow", "master", "666666", "qwertyuiop", "123321",
+	"mustang", "1234567890", "michael", "654321", "superman", "1qaz2wsx",
+	"7777777", "121212", "000000", "qazwsx", "123qwe", "killer", "trustno1",
+	"jordan", "jennifer", "zxcvbnm", "asdfgh", "hunter", "buster", "soccer",
+	"harley", "batman", "andrew", "tigger", "sunshine", "iloveyou", "charlie",
+	"robert", "thomas", "hockey", "ranger", "daniel", "starwars", "112233",
+	"george", "computer", "michelle", "jessica", "pepper", "zxcvbn", "555555",
+	"11111111", "131313", "freedom", "777777", "pass", "maggie", "159753",
+	"aaaaaa", "ginger", "princess", "joshua", "cheese", "amanda", "summer",
+	"love", "ashley", "nicole", "chelsea", "biteme", "matthew", "access",
+	"yankees", "987654321", "dallas", "austin", "thunder", "taylor", "matrix",
+	"welcome", "admin", "login", "secret", "winter", "spring", "autumn",
+	"flower", "hello", "monday", "changeme", "default", "passport", "whatever",
+	"dragonfly", "orange", "banana", "cookie", "chocolate", "purple", "silver",
+	"golden", "diamond", "angel", "blessed", "family", "friends", "forever",
+	"lovely", "qwerty123", "password1", "welcome1", "football1", "baseball1",
+	"superstar", "pokemon", "naruto", "minecraft", "google", "facebook",
+	"samsung", "apple", "internet", "liverpool", "arsenal", "barcelona",
+	"london", "america", "canada", "mexico", "london1", "newyork", "jesus",
+	"christ", "heaven", "money", "dollar", "lucky", "happy", "smile", "sunday",
+	"friday", "january", "february", "september", "october", "november",
+	"december", "tiger", "lion", "eagle", "falcon", "wolf", "bear", "dog",
+	"cat", "fish", "horse", "mother", "father", "sister", "brother", "baby",
+	"darling", "sweet", "honey", "sugar", "candy", "magic", "wizard", "ninja",
+	"hacker", "user", "guest", "root", "test", "demo", "mios",
+}
+
+// commonPasswordRanks maps each common password to its 1-based rank
+var commonPasswordRanks = func() map[string]int {
+	ranks := make(map[string]int, len(commonPasswords))
+	for i, word := range commonPasswords {
+		if _, ok := ranks[word]; !ok {
+			ranks[word] = i + 1
+		}
+	}
+	return ranks
+}()
@@ -20,6 +20,10 @@ type PasswordConfig struct {
 	RequireLowercase bool
 	RequireDigits    bool
 	RequireSpecial   bool
+
+	// MinScore is the lowest EstimateStrength score accepted, from 0 (no
+	// strength check) to 4
+	MinScore int
 }
 
 func DefaultPasswordConfig() PasswordConfig {
@@ -29,10 +33,14 @@ func DefaultPasswordConfig() PasswordConfig {
 		RequireLowercase: true,
 		RequireDigits:    true,
 		RequireSpecial:   true,
+		MinScore:         DefaultMinScore,
 	}
 }
 
-func ValidatePassword(password string, config PasswordConfig) error {
+// ValidatePassword checks the character rules, then that the password is
+// hard enough to guess. userInputs (username, email) count as guessable
+// words. The strength error names the pattern that made it weak.
+func ValidatePassword(password string, config PasswordConfig, userInputs ...string) error {
 	if len(password) < config.MinLength {
 		return ErrPasswordTooShort
 	}
@@ -53,6 +61,16 @@ func ValidatePassword(password string, config PasswordConfig) error {
 		return ErrPasswordTooWeak
 	}
 
+	if config.MinScore > MinScore {
+		strength := EstimateStrength(password, userInputs...)
+		if strength.Score < config.MinScore {
+			if strength.Reason != nil {
+				return strength.Reason
+			}
+			return ErrPasswordTooGuessable
+		}
+	}
+
 	return nil
 }
 
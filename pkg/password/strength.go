@@ -0,0 +1,395 @@
+package password
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"unicode"
+)
+
+// Strength scores run from 0 (guessable in a handful of tries) to 4 (safe
+// against offline attacks), following zxcvbn's guess thresholds
+const (
+	MinScore        = 0
+	MaxScore        = 4
+	DefaultMinScore = 2
+)
+
+// maxEstimateLength bounds the work done on very long inputs; anything past
+// it is counted as brute force
+const maxEstimateLength = 100
+
+var (
+	ErrPasswordTooCommon       = errors.New("password is too common")
+	ErrPasswordPersonalInfo    = errors.New("password contains your name or email")
+	ErrPasswordSequential      = errors.New("password contains a sequence like abc or 123")
+	ErrPasswordRepeated        = errors.New("password contains repeated characters like aaa")
+	ErrPasswordKeyboardPattern = errors.New("password contains a keyboard pattern like qwerty")
+	ErrPasswordContainsYear    = errors.New("password contains a year")
+	ErrPasswordTooGuessable    = errors.New("password is too easy to guess")
+)
+
+// Strength is how hard a password is to guess. Reason names the weakest
+// pattern found and is nil when the password is only brute-forceable.
+type Strength struct {
+	Score   int
+	Guesses float64
+	Reason  error
+}
+
+// matchKind is the kind of guessable pattern a match found
+type matchKind int
+
+const (
+	matchDictionary matchKind = iota
+	matchUserInput
+	matchSequence
+	matchRepeat
+	matchKeyboard
+	matchYear
+)
+
+var matchReasons = map[matchKind]error{
+	matchDictionary: ErrPasswordTooCommon,
+	matchUserInput:  ErrPasswordPersonalInfo,
+	matchSequence:   ErrPasswordSequential,
+	matchRepeat:     ErrPasswordRepeated,
+	matchKeyboard:   ErrPasswordKeyboardPattern,
+	matchYear:       ErrPasswordContainsYear,
+}
+
+// match is a guessable pattern covering runes [start, end) of the password
+type match struct {
+	kind    matchKind
+	start   int
+	end     int
+	guesses float64
+}
+
+// keyboardRows are runs of adjacent keys on a US layout
+var keyboardRows = []string{
+	"`1234567890-=",
+	"qwertyuiop[]\\",
+	"asdfghjkl;'",
+	"zxcvbnm,./",
+	"1qaz2wsx3edc4rfv5tgb6yhn7ujm8ik9ol0p",
+}
+
+// leetSubstitutions undoes the common character swaps in "p@ssw0rd"
+var leetSubstitutions = map[rune]rune{
+	'4': 'a', '@': 'a', '3': 'e', '1': 'i', '!': 'i',
+	'0': 'o', '$': 's', '5': 's', '7': 't', '+': 't',
+}
+
+// EstimateStrength estimates how many guesses an attacker needs, zxcvbn
+// style: the password is covered by the cheapest combination of common
+// words, user inputs, sequences, repeats, keyboard runs and years, with
+// anything left over brute forced. userInputs are words an attacker would
+// try first, such as the username and email.
+func EstimateStrength(password string, userInputs ...string) Strength {
+	runes := []rune(password)
+	if len(runes) == 0 {
+		return Strength{Score: MinScore, Guesses: 1, Reason: ErrPasswordTooGuessable}
+	}
+
+	tail := 1.0
+	if len(runes) > maxEstimateLength {
+		for _, r := range runes[maxEstimateLength:] {
+			tail *= bruteForceGuesses(r)
+		}
+		runes = runes[:maxEstimateLength]
+	}
+
+	matches := findMatches(runes, userInputs)
+
+	// best[i] is the fewest guesses covering the first i runes; via[i] is
+	// the match ending there on that path, or -1 for a brute-forced rune
+	n := len(runes)
+	best := make([]float64, n+1)
+	via := make([]int, n+1)
+	best[0] = 1
+	for i := 1; i <= n; i++ {
+		best[i] = best[i-1] * bruteForceGuesses(runes[i-1])
+		via[i] = -1
+		for m, candidate := range matches {
+			if candidate.end != i {
+				continue
+			}
+			if guesses := best[candidate.start] * candidate.guesses; guesses < best[i] {
+				best[i] = guesses
+				via[i] = m
+			}
+		}
+	}
+
+	// The widest pattern on the cheapest path is the one worth reporting
+	var reason error
+	widest := 0
+	for i := n; i > 0; {
+		if via[i] < 0 {
+			i--
+			continue
+		}
+		used := matches[via[i]]
+		if width := used.end - used.start; width > widest {
+			widest = width
+			reason = matchReasons[used.kind]
+		}
+		i = used.start
+	}
+
+	guesses := best[n] * tail
+	return Strength{
+		Score:   scoreForGuesses(guesses),
+		Guesses: guesses,
+		Reason:  reason,
+	}
+}
+
+func scoreForGuesses(guesses float64) int {
+	switch {
+	case guesses < 1e3:
+		return 0
+	case guesses < 1e6:
+		return 1
+	case guesses < 1e8:
+		return 2
+	case guesses < 1e10:
+		return 3
+	default:
+		return 4
+	}
+}
+
+// bruteForceGuesses is the size of the character class a rune belongs to
+func bruteForceGuesses(r rune) float64 {
+	switch {
+	case r >= '0' && r <= '9':
+		return 10
+	case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z':
+		return 26
+	case r < unicode.MaxASCII:
+		return 33
+	default:
+		return 100
+	}
+}
+
+func findMatches(runes []rune, userInputs []string) []match {
+	lower := []rune(strings.ToLower(string(runes)))
+
+	inputs := make(map[string]int)
+	for _, input := range userInputs {
+		for _, word := range userInputWords(input) {
+			if _, ok := inputs[word]; !ok {
+				inputs[word] = len(inputs) + 1
+			}
+		}
+	}
+
+	var matches []match
+	matches = append(matches, dictionaryMatches(runes, lower, commonPasswordRanks, matchDictionary)...)
+	matches = append(matches, dictionaryMatches(runes, lower, inputs, matchUserInput)...)
+	matches = append(matches, sequenceMatches(lower)...)
+	matches = append(matches, repeatMatches(lower)...)
+	matches = append(matches, keyboardMatches(lower)...)
+	matches = append(matches, yearMatches(lower)...)
+	return matches
+}
+
+// userInputWords splits a username or email into the parts worth matching
+func userInputWords(input string) []string {
+	input = strings.ToLower(strings.TrimSpace(input))
+	if local, _, found := strings.Cut(input, "@"); found {
+		input = local
+	}
+
+	words := []string{input}
+	words = append(words, strings.FieldsFunc(input, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})...)
+
+	var kept []string
+	for _, word := range words {
+		if len([]rune(word)) >= 3 {
+			kept = append(kept, word)
+		}
+	}
+	return kept
+}
+
+// dictionaryMatches finds every substring of three or more runes that is a
+// ranked word, as typed or with leet substitutions undone. Capitals and
+// substitutions multiply the guesses since an attacker has to try them.
+func dictionaryMatches(runes, lower []rune, ranks map[string]int, kind matchKind) []match {
+	if len(ranks) == 0 {
+		return nil
+	}
+
+	var matches []match
+	for i := 0; i < len(lower); i++ {
+		for j := i + 3; j <= len(lower); j++ {
+			word := string(lower[i:j])
+			rank, ok := ranks[word]
+			leet := false
+			if !ok {
+				rank, ok = ranks[unleet(lower[i:j])]
+				leet = ok
+			}
+			if !ok {
+				continue
+			}
+
+			guesses := float64(rank) * uppercaseVariations(runes[i:j])
+			if leet {
+				guesses *= 2
+			}
+			matches = append(matches, match{kind: kind, start: i, end: j, guesses: guesses})
+		}
+	}
+	return matches
+}
+
+func unleet(word []rune) string {
+	var b strings.Builder
+	for _, r := range word {
+		if sub, ok := leetSubstitutions[r]; ok {
+			r = sub
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// uppercaseVariations is how many capitalisations an attacker tries before
+// reaching this one: leading or all caps are tried early, anything else is
+// a mix of positions
+func uppercaseVariations(word []rune) float64 {
+	upper, letters := 0, 0
+	for _, r := range word {
+		if unicode.IsLetter(r) {
+			letters++
+			if unicode.IsUpper(r) {
+				upper++
+			}
+		}
+	}
+
+	switch {
+	case upper == 0:
+		return 1
+	case upper == letters, upper == 1 && unicode.IsUpper(word[0]):
+		return 2
+	default:
+		return math.Pow(2, float64(upper))
+	}
+}
+
+// sequenceMatches finds runs like "abcd" or "9876" of three or more
+func sequenceMatches(lower []rune) []match {
+	var matches []match
+	for i := 0; i < len(lower)-2; {
+		delta := lower[i+1] - lower[i]
+		if (delta != 1 && delta != -1) || !sameClass(lower[i], lower[i+1]) {
+			i++
+			continue
+		}
+
+		j := i + 1
+		for j+1 < len(lower) && lower[j+1]-lower[j] == delta && sameClass(lower[j], lower[j+1]) {
+			j++
+		}
+		if length := j - i + 1; length >= 3 {
+			base := 26.0
+			switch {
+			case strings.ContainsRune("az019", lower[i]):
+				base = 4
+			case unicode.IsDigit(lower[i]):
+				base = 10
+			}
+			if delta < 0 {
+				base *= 2
+			}
+			matches = append(matches, match{kind: matchSequence, start: i, end: j + 1, guesses: base * float64(length)})
+		}
+		i = j
+	}
+	return matches
+}
+
+func sameClass(a, b rune) bool {
+	return (unicode.IsDigit(a) && unicode.IsDigit(b)) || (unicode.IsLower(a) && unicode.IsLower(b))
+}
+
+// repeatMatches finds a character repeated three or more times in a row
+func repeatMatches(lower []rune) []match {
+	var matches []match
+	for i := 0; i < len(lower); {
+		j := i + 1
+		for j < len(lower) && lower[j] == lower[i] {
+			j++
+		}
+		if length := j - i; length >= 3 {
+			matches = append(matches, match{
+				kind:    matchRepeat,
+				start:   i,
+				end:     j,
+				guesses: bruteForceGuesses(lower[i]) * float64(length),
+			})
+		}
+		i = j
+	}
+	return matches
+}
+
+// keyboardMatches finds runs of four or more adjacent keys in either
+// direction
+func keyboardMatches(lower []rune) []match {
+	var matches []match
+	for i := 0; i < len(lower); i++ {
+		longest := 0
+		for j := i + 4; j <= len(lower); j++ {
+			if onKeyboardRow(string(lower[i:j])) {
+				longest = j - i
+			}
+		}
+		if longest > 0 {
+			matches = append(matches, match{kind: matchKeyboard, start: i, end: i + longest, guesses: 20 * float64(longest)})
+		}
+	}
+	return matches
+}
+
+func onKeyboardRow(run string) bool {
+	reversed := []rune(run)
+	for a, b := 0, len(reversed)-1; a < b; a, b = a+1, b-1 {
+		reversed[a], reversed[b] = reversed[b], reversed[a]
+	}
+	for _, row := range keyboardRows {
+		if strings.Contains(row, run) || strings.Contains(row, string(reversed)) {
+			return true
+		}
+	}
+	return false
+}
+
+// yearMatches finds years from 1900 to 2049
+func yearMatches(lower []rune) []match {
+	var matches []match
+	for i := 0; i+4 <= len(lower); i++ {
+		year := string(lower[i : i+4])
+		if (strings.HasPrefix(year, "19") || (year >= "2000" && year <= "2049")) && isDigits(year) {
+			matches = append(matches, match{kind: matchYear, start: i, end: i + 4, guesses: 150})
+		}
+	}
+	return matches
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if !unicode.IsDigit(r) {
+			return false
+		}
+	}
+	return true
+}
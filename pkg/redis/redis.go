@@ -13,6 +13,16 @@ import (
 
 var Nil = redis.Nil
 
+// PubSub is a subscription to one or more channels
+type PubSub = redis.PubSub
+
+// Script is a Lua script run atomically on the server
+type Script = redis.Script
+
+func NewScript(src string) *Script {
+	return redis.NewScript(src)
+}
+
 type Client struct {
 	rdb    *redis.Client
 	logger log.Logger
@@ -98,18 +108,41 @@ func (c *Client) Incr(ctx context.Context, key string) (int64, error) {
 	return c.rdb.Incr(ctx, key).Result()
 }
 
+// RunScript runs a Lua script, loading it into the script cache on first use
+func (c *Client) RunScript(ctx context.Context, script *Script, keys []string, args ...interface{}) (interface{}, error) {
+	c.logger.Debugf("Running Redis script on keys: %v", keys)
+	return script.Run(ctx, c.rdb, keys, args...).Result()
+}
+
 // Keys returns all keys matching a pattern
 func (c *Client) Keys(ctx context.Context, pattern string) ([]string, error) {
 	c.logger.Debugf("Getting Redis keys matching pattern: %s", pattern)
 	return c.rdb.Keys(ctx, pattern).Result()
 }
 
+// Publish sends a message to every subscriber of a channel
+func (c *Client) Publish(ctx context.Context, channel string, message interface{}) error {
+	c.logger.Debugf("Publishing to Redis channel: %s", channel)
+	return c.rdb.Publish(ctx, channel, message).Err()
+}
+
+// Subscribe listens on channels until the returned subscription is closed
+func (c *Client) Subscribe(ctx context.Context, channels ...string) *PubSub {
+	c.logger.Debugf("Subscribing to Redis channels: %v", channels)
+	return c.rdb.Subscribe(ctx, channels...)
+}
+
 // FlushDB removes all keys from the current database
 func (c *Client) FlushDB(ctx context.Context) error {
 	c.logger.Warn("Flushing Redis database")
 	return c.rdb.FlushDB(ctx).Err()
 }
 
+// Ping checks that the server is reachable
+func (c *Client) Ping(ctx context.Context) error {
+	return c.rdb.Ping(ctx).Err()
+}
+
 // Close closes the Redis client connection
 func (c *Client) Close() error {
 	c.logger.Info("Closing Redis connection")
@@ -64,6 +64,11 @@ var (
 		Code:    "SERVICE_UNAVAILABLE",
 		Message: "The service is currently unavailable",
 	}
+
+	ErrRateLimitExceededResponse = ErrorResponse{
+		Code:    "RATE_LIMIT_EXCEEDED",
+		Message: "Too many requests. Please try again later.",
+	}
 )
 
 // Success sends a successful response
@@ -118,6 +123,8 @@ func Error(c *gin.Context, err ErrorResponse, details ...any) {
 		statusCode = http.StatusConflict
 	case "SERVICE_UNAVAILABLE":
 		statusCode = http.StatusServiceUnavailable
+	case "RATE_LIMIT_EXCEEDED":
+		statusCode = http.StatusTooManyRequests
 	}
 
 	c.JSON(statusCode, err)
@@ -131,6 +138,7 @@ func HandleError(c *gin.Context, err error, logger log.Logger) {
 		c.JSON(appErr.Status, ErrorResponse{
 			Code:    appErr.Code,
 			Message: appErr.Message,
+			Details: appErr.Details,
 		})
 		return
 	}
@@ -0,0 +1,169 @@
+// pkg/storage/cdn_monitor.go
+package storage
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/0xsj/mios.io/log"
+)
+
+// CDNMonitorConfig contains configuration for CDN health checks
+type CDNMonitorConfig struct {
+	Domain           string        // CDN base URL, e.g. https://cdn.example.com
+	Path             string        // Path probed on the CDN; defaults to "/"
+	Interval         time.Duration // Time between probes
+	Timeout          time.Duration // Deadline for a single probe
+	FailureThreshold int           // Consecutive failed probes before the CDN is reported down
+}
+
+const (
+	defaultCDNCheckInterval    = time.Minute
+	defaultCDNCheckTimeout     = 5 * time.Second
+	defaultCDNFailureThreshold = 2
+)
+
+// CDNStatus is the last known state of the CDN, as reported by the health
+// endpoint
+type CDNStatus struct {
+	Domain              string    `json:"domain"`
+	Available           bool      `json:"available"`
+	ConsecutiveFailures int       `json:"consecutive_failures"`
+	LastChecked         time.Time `json:"last_checked,omitempty"`
+	LastError           string    `json:"last_error,omitempty"`
+}
+
+// CDNMonitor periodically probes the CDN domain in front of storage so file
+// URLs can fall back to the origin while it is down. The CDN counts as
+// reachable until FailureThreshold probes in a row fail; one successful
+// probe brings it back.
+type CDNMonitor struct {
+	config CDNMonitorConfig
+	client *http.Client
+	logger log.Logger
+
+	mu     sync.RWMutex
+	status CDNStatus
+}
+
+// NewCDNMonitor creates a monitor for the configured CDN domain
+func NewCDNMonitor(cfg CDNMonitorConfig, logger log.Logger) *CDNMonitor {
+	if cfg.Path == "" {
+		cfg.Path = "/"
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = defaultCDNCheckInterval
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultCDNCheckTimeout
+	}
+	if cfg.FailureThreshold <= 0 {
+		cfg.FailureThreshold = defaultCDNFailureThreshold
+	}
+
+	return &CDNMonitor{
+		config: cfg,
+		client: &http.Client{Timeout: cfg.Timeout},
+		logger: logger,
+		status: CDNStatus{
+			Domain:    cfg.Domain,
+			Available: true,
+		},
+	}
+}
+
+// Available reports whether the CDN is currently considered reachable. A nil
+// monitor always reports true so callers need not check for one.
+func (m *CDNMonitor) Available() bool {
+	if m == nil {
+		return true
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.status.Available
+}
+
+// Status returns the last known state of the CDN
+func (m *CDNMonitor) Status() CDNStatus {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.status
+}
+
+// Check probes the CDN once and updates its state. Any response below 500
+// means the CDN is serving; transport errors and 5xx responses are failures.
+func (m *CDNMonitor) Check(ctx context.Context) CDNStatus {
+	err := m.probe(ctx)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	wasAvailable := m.status.Available
+	m.status.LastChecked = time.Now().UTC()
+
+	if err != nil {
+		m.status.ConsecutiveFailures++
+		m.status.LastError = err.Error()
+		if m.status.ConsecutiveFailures >= m.config.FailureThreshold {
+			m.status.Available = false
+		}
+	} else {
+		m.status.ConsecutiveFailures = 0
+		m.status.LastError = ""
+		m.status.Available = true
+	}
+
+	switch {
+	case wasAvailable && !m.status.Available:
+		m.logger.Errorf("CDN %s is unreachable after %d failed checks: %v",
+			m.config.Domain, m.status.ConsecutiveFailures, err)
+	case !wasAvailable && m.status.Available:
+		m.logger.Infof("CDN %s is reachable again", m.config.Domain)
+	case err != nil:
+		m.logger.Warnf("CDN health check failed (%d/%d): %v",
+			m.status.ConsecutiveFailures, m.config.FailureThreshold, err)
+	}
+
+	return m.status
+}
+
+// Run checks the CDN every Interval until ctx is cancelled
+func (m *CDNMonitor) Run(ctx context.Context) {
+	ticker := time.NewTicker(m.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		m.Check(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (m *CDNMonitor) probe(ctx context.Context) error {
+	url := strings.TrimRight(m.config.Domain, "/") + "/" + strings.TrimLeft(m.config.Path, "/")
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, url, nil)
+	if err != nil {
+		return fmt.Errorf("invalid CDN health check URL: %w", err)
+	}
+
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return fmt.Errorf("CDN responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
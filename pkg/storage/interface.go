@@ -14,6 +14,11 @@ type Storage interface {
 	Delete(ctx context.Context, key string) error
 	GetURL(ctx context.Context, key string, opts GetURLOptions) (string, error)
 	GetPresignedUploadURL(ctx context.Context, key string, opts PresignedUploadOptions) (*PresignedUploadResult, error)
+	// GetPresignedDownloadURL returns a URL that grants access to a private
+	// file until it expires. It never goes through the CDN.
+	GetPresignedDownloadURL(ctx context.Context, key string, expires time.Duration) (string, error)
+	// Stat describes a stored file without downloading it
+	Stat(ctx context.Context, key string) (*FileInfo, error)
 }
 
 // UploadOptions contains options for upload operations
@@ -22,6 +27,7 @@ type UploadOptions struct {
 	ACL         string
 	Metadata    map[string]string
 	MaxSize     int64 // Maximum file size in bytes
+	DisableCDN  bool  // Return the origin URL even when a CDN domain is configured
 }
 
 // UploadResult contains the result of an upload operation
@@ -33,10 +39,18 @@ type UploadResult struct {
 	URL         string
 }
 
+// FileInfo describes a stored file
+type FileInfo struct {
+	Key         string
+	Size        int64
+	ContentType string
+}
+
 // GetURLOptions contains options for getting file URLs
 type GetURLOptions struct {
-	Expires   time.Duration
-	CDNDomain string
+	Expires    time.Duration
+	CDNDomain  string
+	DisableCDN bool // Ignore CDNDomain and any configured CDN, e.g. during a CDN outage
 }
 
 // PresignedUploadOptions contains options for generating presigned upload URLs
@@ -5,11 +5,16 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"mime"
+	"net/http"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/0xsj/mios.io/log"
+	"github.com/0xsj/mios.io/pkg/errors"
 )
 
 // LocalStorage implements Storage interface for local file system
@@ -17,6 +22,10 @@ type LocalStorage struct {
 	basePath  string
 	baseURL   string
 	logger    log.Logger
+
+	// Presigned downloads, enabled by WithSignedDownloads
+	signer      *URLSigner
+	downloadURL string
 }
 
 // NewLocalStorage creates a new local storage instance
@@ -77,8 +86,24 @@ func (l *LocalStorage) Delete(ctx context.Context, key string) error {
 	return os.Remove(fullPath)
 }
 
+func (l *LocalStorage) Stat(ctx context.Context, key string) (*FileInfo, error) {
+	info, err := os.Stat(filepath.Join(l.basePath, key))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, errors.NewNotFoundError("File not found", err)
+		}
+		return nil, fmt.Errorf("failed to stat file: %w", err)
+	}
+
+	return &FileInfo{
+		Key:         key,
+		Size:        info.Size(),
+		ContentType: mime.TypeByExtension(filepath.Ext(key)),
+	}, nil
+}
+
 func (l *LocalStorage) GetURL(ctx context.Context, key string, opts GetURLOptions) (string, error) {
-	if opts.CDNDomain != "" {
+	if opts.CDNDomain != "" && !opts.DisableCDN {
 		return fmt.Sprintf("%s/%s", opts.CDNDomain, key), nil
 	}
 	return fmt.Sprintf("%s/%s", l.baseURL, key), nil
@@ -87,4 +112,58 @@ func (l *LocalStorage) GetURL(ctx context.Context, key string, opts GetURLOption
 func (l *LocalStorage) GetPresignedUploadURL(ctx context.Context, key string, opts PresignedUploadOptions) (*PresignedUploadResult, error) {
 	// Local storage doesn't support presigned URLs
 	return nil, fmt.Errorf("presigned uploads not supported for local storage")
-}
\ No newline at end of file
+}
+
+// WithSignedDownloads enables presigned download URLs for local storage.
+// URLs point at downloadURL, which must be served by DownloadHandler.
+func (l *LocalStorage) WithSignedDownloads(signer *URLSigner, downloadURL string) *LocalStorage {
+	l.signer = signer
+	l.downloadURL = strings.TrimSuffix(downloadURL, "/")
+	return l
+}
+
+func (l *LocalStorage) GetPresignedDownloadURL(ctx context.Context, key string, expires time.Duration) (string, error) {
+	if l.signer == nil {
+		return "", fmt.Errorf("signed downloads not configured for local storage")
+	}
+
+	expiresAt := time.Now().Add(expires)
+	return fmt.Sprintf("%s/%s?expires=%d&signature=%s", l.downloadURL, key, expiresAt.Unix(), l.signer.Sign(key, expiresAt)), nil
+}
+
+// DownloadHandler serves files behind URLs from GetPresignedDownloadURL,
+// taking the file key from the request path below its mount point
+func (l *LocalStorage) DownloadHandler(prefix string) http.Handler {
+	return http.StripPrefix(prefix, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := strings.TrimPrefix(r.URL.Path, "/")
+		if l.signer == nil || key == "" {
+			http.NotFound(w, r)
+			return
+		}
+
+		unix, err := strconv.ParseInt(r.URL.Query().Get("expires"), 10, 64)
+		if err != nil {
+			http.Error(w, "invalid download URL", http.StatusBadRequest)
+			return
+		}
+
+		if err := l.signer.Verify(key, time.Unix(unix, 0), r.URL.Query().Get("signature")); err != nil {
+			l.logger.Warnf("Rejected download of %s: %v", key, err)
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
+
+		fullPath := filepath.Join(l.basePath, filepath.FromSlash(key))
+		if !strings.HasPrefix(fullPath, filepath.Clean(l.basePath)+string(filepath.Separator)) {
+			http.NotFound(w, r)
+			return
+		}
+		if _, err := os.Stat(fullPath); err != nil {
+			http.NotFound(w, r)
+			return
+		}
+
+		w.Header().Set("Cache-Control", "private, no-store")
+		http.ServeFile(w, r, fullPath)
+	}))
+}
@@ -3,12 +3,15 @@ package storage
 
 import (
 	"context"
+	stderrors "errors"
 	"fmt"
 	"io"
 	"time"
 
 	"github.com/0xsj/mios.io/log"
+	"github.com/0xsj/mios.io/pkg/errors"
 	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
@@ -17,12 +20,14 @@ import (
 
 // S3Storage implements Storage interface for AWS S3
 type S3Storage struct {
-	client    *s3.Client
-	uploader  *manager.Uploader
-	bucket    string
-	region    string
-	cdnDomain string
-	logger    log.Logger
+	client           *s3.Client
+	uploader         *manager.Uploader
+	bucket           string
+	region           string
+	cdnDomain        string
+	operationTimeout time.Duration
+	uploadTimeout    time.Duration
+	logger           log.Logger
 }
 
 // S3Config contains configuration for S3 storage
@@ -32,13 +37,47 @@ type S3Config struct {
 	CDNDomain   string
 	AccessKeyID string
 	SecretKey   string
+
+	// Retry and timeout policy. Zero values fall back to the defaults below.
+	MaxRetries       int           // Retries after the first attempt
+	MaxBackoff       time.Duration // Upper bound for the delay between retries
+	OperationTimeout time.Duration // Deadline for metadata, download and delete calls
+	UploadTimeout    time.Duration // Deadline for a whole (possibly multipart) upload
 }
 
+const (
+	defaultS3MaxRetries       = 3
+	defaultS3MaxBackoff       = 20 * time.Second
+	defaultS3OperationTimeout = 30 * time.Second
+	defaultS3UploadTimeout    = 5 * time.Minute
+)
+
 // NewS3Storage creates a new S3 storage instance
 func NewS3Storage(cfg S3Config, logger log.Logger) (*S3Storage, error) {
+	if cfg.MaxRetries < 0 {
+		cfg.MaxRetries = 0
+	} else if cfg.MaxRetries == 0 {
+		cfg.MaxRetries = defaultS3MaxRetries
+	}
+	if cfg.MaxBackoff <= 0 {
+		cfg.MaxBackoff = defaultS3MaxBackoff
+	}
+	if cfg.OperationTimeout <= 0 {
+		cfg.OperationTimeout = defaultS3OperationTimeout
+	}
+	if cfg.UploadTimeout <= 0 {
+		cfg.UploadTimeout = defaultS3UploadTimeout
+	}
+
 	// Load AWS config
 	awsCfg, err := config.LoadDefaultConfig(context.TODO(),
 		config.WithRegion(cfg.Region),
+		config.WithRetryer(func() aws.Retryer {
+			return retry.NewStandard(func(o *retry.StandardOptions) {
+				o.MaxAttempts = cfg.MaxRetries + 1
+				o.MaxBackoff = cfg.MaxBackoff
+			})
+		}),
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load AWS config: %w", err)
@@ -57,13 +96,18 @@ func NewS3Storage(cfg S3Config, logger log.Logger) (*S3Storage, error) {
 	client := s3.NewFromConfig(awsCfg)
 	uploader := manager.NewUploader(client)
 
+	logger.Infof("S3 storage configured with %d retries, %v operation timeout, %v upload timeout",
+		cfg.MaxRetries, cfg.OperationTimeout, cfg.UploadTimeout)
+
 	return &S3Storage{
-		client:    client,
-		uploader:  uploader,
-		bucket:    cfg.Bucket,
-		region:    cfg.Region,
-		cdnDomain: cfg.CDNDomain,
-		logger:    logger,
+		client:           client,
+		uploader:         uploader,
+		bucket:           cfg.Bucket,
+		region:           cfg.Region,
+		cdnDomain:        cfg.CDNDomain,
+		operationTimeout: cfg.OperationTimeout,
+		uploadTimeout:    cfg.UploadTimeout,
+		logger:           logger,
 	}, nil
 }
 
@@ -85,13 +129,19 @@ func (s *S3Storage) Upload(ctx context.Context, key string, reader io.Reader, op
 		input.Metadata = opts.Metadata
 	}
 
-	result, err := s.uploader.Upload(ctx, input)
+	uploadCtx, cancel := context.WithTimeout(ctx, s.uploadTimeout)
+	defer cancel()
+
+	result, err := s.uploader.Upload(uploadCtx, input)
 	if err != nil {
-		return nil, fmt.Errorf("failed to upload to S3: %w", err)
+		return nil, s.mapError(err, "upload", key)
 	}
 
 	// Get object to retrieve size and ETag
-	headOutput, err := s.client.HeadObject(ctx, &s3.HeadObjectInput{
+	headCtx, headCancel := context.WithTimeout(ctx, s.operationTimeout)
+	defer headCancel()
+
+	headOutput, err := s.client.HeadObject(headCtx, &s3.HeadObjectInput{
 		Bucket: aws.String(s.bucket),
 		Key:    aws.String(key),
 	})
@@ -102,12 +152,12 @@ func (s *S3Storage) Upload(ctx context.Context, key string, reader io.Reader, op
 	var size int64
 	var etag string
 	if headOutput != nil {
-		size = *headOutput.ContentLength
-		etag = *headOutput.ETag
+		size = aws.ToInt64(headOutput.ContentLength)
+		etag = aws.ToString(headOutput.ETag)
 	}
 
 	url := result.Location
-	if s.cdnDomain != "" {
+	if s.cdnDomain != "" && !opts.DisableCDN {
 		url = fmt.Sprintf("%s/%s", s.cdnDomain, key)
 	}
 
@@ -121,35 +171,71 @@ func (s *S3Storage) Upload(ctx context.Context, key string, reader io.Reader, op
 }
 
 func (s *S3Storage) Download(ctx context.Context, key string) (io.ReadCloser, error) {
-	result, err := s.client.GetObject(ctx, &s3.GetObjectInput{
+	// The timeout only covers getting the response. The caller streams the
+	// body afterwards, for as long as the request context allows, so the
+	// context is released when the returned reader is closed.
+	downloadCtx, cancel := context.WithCancelCause(ctx)
+	timer := time.AfterFunc(s.operationTimeout, func() { cancel(context.DeadlineExceeded) })
+
+	result, err := s.client.GetObject(downloadCtx, &s3.GetObjectInput{
 		Bucket: aws.String(s.bucket),
 		Key:    aws.String(key),
 	})
+	if !timer.Stop() {
+		// The timeout fired, possibly just as the response arrived
+		if err == nil {
+			result.Body.Close()
+		}
+		err = context.Cause(downloadCtx)
+	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to download from S3: %w", err)
+		cancel(nil)
+		return nil, s.mapError(err, "download", key)
 	}
 
-	return result.Body, nil
+	return &cancelOnCloseReader{ReadCloser: result.Body, cancel: func() { cancel(nil) }}, nil
 }
 
 func (s *S3Storage) Delete(ctx context.Context, key string) error {
-	_, err := s.client.DeleteObject(ctx, &s3.DeleteObjectInput{
+	deleteCtx, cancel := context.WithTimeout(ctx, s.operationTimeout)
+	defer cancel()
+
+	_, err := s.client.DeleteObject(deleteCtx, &s3.DeleteObjectInput{
 		Bucket: aws.String(s.bucket),
 		Key:    aws.String(key),
 	})
 	if err != nil {
-		return fmt.Errorf("failed to delete from S3: %w", err)
+		return s.mapError(err, "delete", key)
 	}
 
 	return nil
 }
 
+func (s *S3Storage) Stat(ctx context.Context, key string) (*FileInfo, error) {
+	headCtx, cancel := context.WithTimeout(ctx, s.operationTimeout)
+	defer cancel()
+
+	result, err := s.client.HeadObject(headCtx, &s3.HeadObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return nil, s.mapError(err, "stat", key)
+	}
+
+	return &FileInfo{
+		Key:         key,
+		Size:        aws.ToInt64(result.ContentLength),
+		ContentType: aws.ToString(result.ContentType),
+	}, nil
+}
+
 func (s *S3Storage) GetURL(ctx context.Context, key string, opts GetURLOptions) (string, error) {
-	if opts.CDNDomain != "" {
+	if opts.CDNDomain != "" && !opts.DisableCDN {
 		return fmt.Sprintf("%s/%s", opts.CDNDomain, key), nil
 	}
 
-	if s.cdnDomain != "" {
+	if s.cdnDomain != "" && !opts.DisableCDN {
 		return fmt.Sprintf("%s/%s", s.cdnDomain, key), nil
 	}
 
@@ -160,8 +246,8 @@ func (s *S3Storage) GetURL(ctx context.Context, key string, opts GetURLOptions)
 		request, err := presigner.PresignGetObject(ctx, &s3.GetObjectInput{
 			Bucket: aws.String(s.bucket),
 			Key:    aws.String(key),
-		}, func(opts *s3.PresignOptions) {
-			opts.Expires = opts.Expires
+		}, func(presignOpts *s3.PresignOptions) {
+			presignOpts.Expires = opts.Expires
 		})
 		if err != nil {
 			return "", fmt.Errorf("failed to generate presigned URL: %w", err)
@@ -199,4 +285,62 @@ func (s *S3Storage) GetPresignedUploadURL(ctx context.Context, key string, opts
 		Key:       key,
 		Expires:   time.Now().Add(opts.Expires),
 	}, nil
-}
\ No newline at end of file
+}
+
+func (s *S3Storage) GetPresignedDownloadURL(ctx context.Context, key string, expires time.Duration) (string, error) {
+	presigner := s3.NewPresignClient(s.client)
+	request, err := presigner.PresignGetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	}, func(presignOpts *s3.PresignOptions) {
+		presignOpts.Expires = expires
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to generate presigned download URL: %w", err)
+	}
+	return request.URL, nil
+}
+
+// mapError converts an S3 error into an application error, separating failures
+// worth retrying later (timeouts, throttling, 5xx) from permanent ones.
+func (s *S3Storage) mapError(err error, operation, key string) error {
+	var noSuchKey *types.NoSuchKey
+	var notFound *types.NotFound
+
+	switch {
+	case stderrors.As(err, &noSuchKey), stderrors.As(err, &notFound):
+		return errors.NewNotFoundError("File not found", err)
+	case stderrors.Is(err, context.Canceled):
+		return errors.NewInternalError("Storage request canceled", err)
+	case isRetryableS3Error(err):
+		s.logger.Warnf("Transient S3 %s failure for key %s: %v", operation, key, err)
+		return errors.NewServiceUnavailableError("Storage is temporarily unavailable, please retry", err)
+	default:
+		s.logger.Errorf("S3 %s failed for key %s: %v", operation, key, err)
+		return errors.NewExternalServiceError(fmt.Sprintf("Failed to %s file", operation), err)
+	}
+}
+
+func isRetryableS3Error(err error) bool {
+	if stderrors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	if retry.IsErrorTimeouts(retry.DefaultTimeouts).IsErrorTimeout(err).Bool() {
+		return true
+	}
+	if retry.IsErrorThrottles(retry.DefaultThrottles).IsErrorThrottle(err).Bool() {
+		return true
+	}
+	return retry.IsErrorRetryables(retry.DefaultRetryables).IsErrorRetryable(err).Bool()
+}
+
+// cancelOnCloseReader releases a download's context once the body is consumed
+type cancelOnCloseReader struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (r *cancelOnCloseReader) Close() error {
+	defer r.cancel()
+	return r.ReadCloser.Close()
+}
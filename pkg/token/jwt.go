@@ -14,6 +14,14 @@ type TokenType string
 const (
 	AccessToken  TokenType = "access"
 	RefreshToken TokenType = "refresh"
+
+	// TwoFactorChallenge is issued after a correct password when the account
+	// has two-factor enabled, and is only accepted by the second login step
+	TwoFactorChallenge TokenType = "2fa_challenge"
+
+	// AccountRestore is issued when the owner of an account pending deletion
+	// signs in, and is only accepted by the account restore endpoint
+	AccountRestore TokenType = "account_restore"
 )
 
 type Claims struct {
@@ -23,6 +31,10 @@ type Claims struct {
 	IsAdmin   bool      `json:"is_admin"`
 	IsPremium bool      `json:"is_premium"`
 	TokenType TokenType `json:"token_type"`
+
+	// SessionID ties access and refresh tokens to the login session that
+	// issued them, so a single device can be signed out
+	SessionID string `json:"sid,omitempty"`
 	jwt.RegisteredClaims
 }
 
@@ -30,6 +42,9 @@ type TokenPair struct {
 	AccessToken  string `json:"access_token"`
 	RefreshToken string `json:"refresh_token"`
 	ExpiresAt    int64  `json:"expires_at"`
+
+	// RefreshTokenID is the refresh token's jti, for tracking its use
+	RefreshTokenID string `json:"-"`
 }
 
 type JWTMaker struct {
@@ -51,6 +66,20 @@ func (maker *JWTMaker) CreateToken(
 	isPremium bool,
 	tokenType TokenType,
 	duration time.Duration,
+) (string, time.Time, error) {
+	return maker.createToken(userID, username, email, isAdmin, isPremium, tokenType, "", uuid.NewString(), duration)
+}
+
+func (maker *JWTMaker) createToken(
+	userID string,
+	username string,
+	email string,
+	isAdmin bool,
+	isPremium bool,
+	tokenType TokenType,
+	sessionID string,
+	tokenID string,
+	duration time.Duration,
 ) (string, time.Time, error) {
 	fmt.Printf("Creating token for user: %s with token type: %s\n", userID, tokenType)
 
@@ -63,11 +92,12 @@ func (maker *JWTMaker) CreateToken(
 		IsAdmin:   isAdmin,
 		IsPremium: isPremium,
 		TokenType: tokenType,
+		SessionID: sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(expiresAt),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			NotBefore: jwt.NewNumericDate(time.Now()),
-			ID:        uuid.NewString(),
+			ID:        tokenID,
 		},
 	}
 
@@ -93,13 +123,14 @@ func (maker *JWTMaker) CreateTokenPair(
 	email string,
 	isAdmin bool,
 	isPremium bool,
+	sessionID string,
 	accessDuration time.Duration,
 	refreshDuration time.Duration,
 ) (*TokenPair, error) {
 	fmt.Printf("Creating token pair for user: %s\n", userID)
 
-	accessToken, expiresAt, err := maker.CreateToken(
-		userID, username, email, isAdmin, isPremium, AccessToken, accessDuration,
+	accessToken, expiresAt, err := maker.createToken(
+		userID, username, email, isAdmin, isPremium, AccessToken, sessionID, uuid.NewString(), accessDuration,
 	)
 	if err != nil {
 		fmt.Printf("Failed to create access token: %v\n", err)
@@ -108,8 +139,9 @@ func (maker *JWTMaker) CreateTokenPair(
 
 	fmt.Printf("Access token created successfully\n")
 
-	refreshToken, _, err := maker.CreateToken(
-		userID, username, email, isAdmin, isPremium, RefreshToken, refreshDuration,
+	refreshTokenID := uuid.NewString()
+	refreshToken, _, err := maker.createToken(
+		userID, username, email, isAdmin, isPremium, RefreshToken, sessionID, refreshTokenID, refreshDuration,
 	)
 	if err != nil {
 		fmt.Printf("Failed to create refresh token: %v\n", err)
@@ -119,9 +151,10 @@ func (maker *JWTMaker) CreateTokenPair(
 	fmt.Printf("Refresh token created successfully\n")
 
 	return &TokenPair{
-		AccessToken:  accessToken,
-		RefreshToken: refreshToken,
-		ExpiresAt:    expiresAt.Unix(),
+		AccessToken:    accessToken,
+		RefreshToken:   refreshToken,
+		ExpiresAt:      expiresAt.Unix(),
+		RefreshTokenID: refreshTokenID,
 	}, nil
 }
 
@@ -0,0 +1,140 @@
+// Package useragent classifies HTTP User-Agent strings into device type,
+// operating system and browser family. It matches well-known tokens rather
+// than versions, which is enough for aggregate analytics.
+package useragent
+
+import "strings"
+
+// Device types
+const (
+	DeviceMobile  = "mobile"
+	DeviceTablet  = "tablet"
+	DeviceDesktop = "desktop"
+	DeviceBot     = "bot"
+)
+
+// Unknown is reported for every field of an empty user agent
+const Unknown = "unknown"
+
+// Other is reported for an OS or browser that was not recognised
+const Other = "Other"
+
+// Info is the classification of one user agent
+type Info struct {
+	DeviceType string
+	OS         string
+	Browser    string
+}
+
+// token maps a lowercase substring to the name reported when it matches
+type token struct {
+	match string
+	name  string
+}
+
+// DefaultBotPatterns are the lowercase substrings that mark a user agent as
+// a crawler or other automated client
+var DefaultBotPatterns = []string{"bot", "crawler", "spider", "slurp", "facebookexternalhit", "headless"}
+
+var tabletTokens = []string{"ipad", "tablet", "kindle", "silk/", "playbook"}
+
+var mobileTokens = []string{"mobi", "iphone", "ipod", "android", "windows phone", "blackberry", "opera mini"}
+
+// Order matters: iOS user agents claim to be "like Mac OS X", and Windows
+// Phone ones also mention Android.
+var osTokens = []token{
+	{"windows phone", "Windows Phone"},
+	{"iphone", "iOS"},
+	{"ipad", "iOS"},
+	{"ipod", "iOS"},
+	{"android", "Android"},
+	{"windows", "Windows"},
+	{"cros", "ChromeOS"},
+	{"mac os x", "macOS"},
+	{"macintosh", "macOS"},
+	{"linux", "Linux"},
+}
+
+// Order matters: most browsers include "Chrome/" and "Safari/" for
+// compatibility, so the more specific tokens are tried first.
+var browserTokens = []token{
+	{"edg/", "Edge"},
+	{"edge/", "Edge"},
+	{"edga/", "Edge"},
+	{"edgios/", "Edge"},
+	{"opr/", "Opera"},
+	{"opera", "Opera"},
+	{"samsungbrowser/", "Samsung Internet"},
+	{"firefox/", "Firefox"},
+	{"fxios/", "Firefox"},
+	{"crios/", "Chrome"},
+	{"chromium/", "Chrome"},
+	{"chrome/", "Chrome"},
+	{"safari/", "Safari"},
+	{"msie", "Internet Explorer"},
+	{"trident/", "Internet Explorer"},
+}
+
+// Parse classifies a user agent string
+func Parse(ua string) Info {
+	ua = strings.ToLower(strings.TrimSpace(ua))
+	if ua == "" {
+		return Info{DeviceType: Unknown, OS: Unknown, Browser: Unknown}
+	}
+
+	return Info{
+		DeviceType: deviceType(ua),
+		OS:         lookup(ua, osTokens),
+		Browser:    lookup(ua, browserTokens),
+	}
+}
+
+// IsBot reports whether ua contains any of patterns, ignoring case. An empty
+// user agent is not treated as a bot.
+func IsBot(ua string, patterns []string) bool {
+	ua = strings.ToLower(strings.TrimSpace(ua))
+	if ua == "" {
+		return false
+	}
+	for _, pattern := range patterns {
+		pattern = strings.ToLower(strings.TrimSpace(pattern))
+		if pattern != "" && strings.Contains(ua, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+func deviceType(ua string) string {
+	switch {
+	case containsAny(ua, DefaultBotPatterns):
+		return DeviceBot
+	case containsAny(ua, tabletTokens):
+		return DeviceTablet
+	// Android tablets leave "Mobile" out of their user agent
+	case strings.Contains(ua, "android") && !strings.Contains(ua, "mobile"):
+		return DeviceTablet
+	case containsAny(ua, mobileTokens):
+		return DeviceMobile
+	default:
+		return DeviceDesktop
+	}
+}
+
+func lookup(ua string, tokens []token) string {
+	for _, t := range tokens {
+		if strings.Contains(ua, t.match) {
+			return t.name
+		}
+	}
+	return Other
+}
+
+func containsAny(ua string, tokens []string) bool {
+	for _, t := range tokens {
+		if strings.Contains(ua, t) {
+			return true
+		}
+	}
+	return false
+}
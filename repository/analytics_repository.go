@@ -7,12 +7,14 @@ import (
 	db "github.com/0xsj/mios.io/db/sqlc"
 	"github.com/0xsj/mios.io/log"
 	"github.com/0xsj/mios.io/pkg/errors"
+	"github.com/0xsj/mios.io/pkg/ptr"
 	"github.com/google/uuid"
 )
 
 type AnalyticsRepository interface {
 	// Recording data
 	CreateAnalyticsEntry(ctx context.Context, params CreateAnalyticsParams) (*db.Analytic, error)
+	CreateAnalyticsEntries(ctx context.Context, entries []CreateAnalyticsParams) (int64, error)
 	CreatePageViewEntry(ctx context.Context, params CreatePageViewParams) (*db.Analytic, error)
 
 	// Basic analytics
@@ -21,29 +23,61 @@ type AnalyticsRepository interface {
 
 	// Count queries
 	GetContentItemClickCount(ctx context.Context, itemID uuid.UUID) (int64, error)
-	GetUserItemClickCount(ctx context.Context, userID uuid.UUID) (int64, error)
-	GetProfilePageViews(ctx context.Context, userID uuid.UUID) (int64, error)
+	GetContentItemUniqueClickCount(ctx context.Context, itemID uuid.UUID, since time.Time, window time.Duration) (int64, error)
+	GetUserItemClickCount(ctx context.Context, userID uuid.UUID, includeBots bool) (int64, error)
+	GetProfilePageViews(ctx context.Context, userID uuid.UUID, includeBots bool) (int64, error)
+	GetPeriodTotals(ctx context.Context, params TimeRangeParams) (*PeriodTotals, error)
+
+	// Interaction breakdown
+	GetItemInteractionBreakdown(ctx context.Context, itemID uuid.UUID) ([]InteractionStats, error)
 
 	// Time range analytics
 	GetUserAnalyticsByTimeRange(ctx context.Context, params TimeRangeParams) ([]DailyAnalytics, error)
 	GetItemAnalyticsByTimeRange(ctx context.Context, params ItemTimeRangeParams) ([]DailyAnalytics, error)
 	GetProfilePageViewsByDate(ctx context.Context, params TimeRangeParams) ([]DailyAnalytics, error)
+	GetUserClicksForDays(ctx context.Context, userID uuid.UUID, days []time.Time) ([]DailyAnalytics, error)
 
 	// Insight queries
 	GetTopContentItemsByClicks(ctx context.Context, params TopItemsParams) ([]TopContentItem, error)
 	GetReferrerAnalytics(ctx context.Context, params ReferrerParams) ([]ReferrerStats, error)
+	GetCampaignCounts(ctx context.Context, params TimeRangeParams) ([]CampaignCount, error)
 
 	// Visitor analytics
 	GetUniqueVisitors(ctx context.Context, params TimeRangeParams) (int64, error)
 	GetUniqueVisitorsByDay(ctx context.Context, params TimeRangeParams) ([]VisitorAnalytics, error)
+
+	// Device analytics
+	GetUserAgentCounts(ctx context.Context, params TimeRangeParams) ([]UserAgentCount, error)
+
+	// Geographic analytics
+	GetIPAddressCounts(ctx context.Context, params TimeRangeParams) ([]IPAddressCount, error)
+
+	// Recent activity
+	ListRecentClicks(ctx context.Context, userID uuid.UUID, limit int) ([]*db.ListRecentClicksRow, error)
+
+	// Export
+	ListAnalyticsForExport(ctx context.Context, params ExportPageParams) ([]*db.ListUserAnalyticsForExportRow, error)
+
+	// Rollups
+	RebuildDailyRollups(ctx context.Context, params RollupRangeParams) (int64, error)
+
+	// Retention
+	ListUsersDueForPurgeWarning(ctx context.Context, params PurgeWarningParams) ([]PurgeWarningCandidate, error)
+	MarkPurgeWarned(ctx context.Context, userID uuid.UUID) error
+	AnonymizeAnalytics(ctx context.Context, params AnonymizeParams) (int64, error)
+	DeleteExpiredAnalytics(ctx context.Context, params PurgeParams) (int64, error)
+	PurgeAnalyticsOlderThan(ctx context.Context, cutoff time.Time) (int64, error)
 }
 
 type CreateAnalyticsParams struct {
-	ItemID    uuid.UUID
-	UserID    uuid.UUID
-	IPAddress string
-	UserAgent string
-	Referrer  string
+	ItemID          uuid.UUID
+	UserID          uuid.UUID
+	IPAddress       string
+	UserAgent       string
+	Referrer        string
+	InteractionType string
+	IsBot           bool
+	UTM             UTMParams
 }
 
 type CreatePageViewParams struct {
@@ -52,45 +86,71 @@ type CreatePageViewParams struct {
 	IPAddress string
 	UserAgent string
 	Referrer  string
+	IsBot     bool
+	UTM       UTMParams
+}
+
+// UTMParams are the campaign tags an event arrived with; empty fields are
+// stored as NULL
+type UTMParams struct {
+	Source   string
+	Medium   string
+	Campaign string
 }
 
+// TimeRangeParams selects a user's analytics between two dates. Events
+// flagged as bot traffic are left out unless IncludeBots is set.
 type TimeRangeParams struct {
-	UserID    uuid.UUID
-	StartDate time.Time
-	EndDate   time.Time
+	UserID      uuid.UUID
+	StartDate   time.Time
+	EndDate     time.Time
+	IncludeBots bool
+	// Granularity is the date_trunc unit GetUserAnalyticsByTimeRange buckets
+	// clicks by ("hour", "day" or "week"); empty means day
+	Granularity string
 }
 
 type ItemTimeRangeParams struct {
-	ItemID    uuid.UUID
-	StartDate time.Time
-	EndDate   time.Time
+	ItemID      uuid.UUID
+	StartDate   time.Time
+	EndDate     time.Time
+	IncludeBots bool
 }
 
+// TopItemsParams selects a user's most clicked items. Each item's unique
+// clicks count one click per IP address per UniqueWindow.
 type TopItemsParams struct {
-	UserID    uuid.UUID
-	StartDate time.Time
-	EndDate   time.Time
-	Limit     int
+	UserID       uuid.UUID
+	StartDate    time.Time
+	EndDate      time.Time
+	Limit        int
+	IncludeBots  bool
+	UniqueWindow time.Duration
 }
 
 type ReferrerParams struct {
-	UserID    uuid.UUID
-	StartDate time.Time
-	EndDate   time.Time
-	Limit     int
+	UserID      uuid.UUID
+	StartDate   time.Time
+	EndDate     time.Time
+	Limit       int
+	IncludeBots bool
 }
 
 // Output data types
+
+// DailyAnalytics counts clicks in the bucket starting at Day, which is a
+// whole day unless a finer granularity was requested
 type DailyAnalytics struct {
 	Day    time.Time `json:"day"`
 	Clicks int64     `json:"clicks"`
 }
 
 type TopContentItem struct {
-	ItemID      string `json:"item_id"`
-	ContentType string `json:"content_type"`
-	Title       string `json:"title"`
-	ClickCount  int64  `json:"click_count"`
+	ItemID           string `json:"item_id"`
+	ContentType      string `json:"content_type"`
+	Title            string `json:"title"`
+	ClickCount       int64  `json:"click_count"`
+	UniqueClickCount int64  `json:"unique_click_count"`
 }
 
 type ReferrerStats struct {
@@ -98,21 +158,119 @@ type ReferrerStats struct {
 	Count    int64  `json:"count"`
 }
 
+// CampaignCount is the clicks and page views recorded with one combination
+// of UTM tags; events without tags have all three empty
+type CampaignCount struct {
+	Source    string `json:"source"`
+	Medium    string `json:"medium"`
+	Campaign  string `json:"campaign"`
+	Clicks    int64  `json:"clicks"`
+	PageViews int64  `json:"page_views"`
+}
+
+// UserAgentCount is the number of events recorded with one distinct user
+// agent; an empty UserAgent covers events without one
+type UserAgentCount struct {
+	UserAgent string `json:"user_agent"`
+	Count     int64  `json:"count"`
+}
+
+type IPAddressCount struct {
+	IPAddress string `json:"ip_address"`
+	Count     int64  `json:"count"`
+}
+
 type VisitorAnalytics struct {
 	Day      time.Time `json:"day"`
 	Visitors int64     `json:"visitors"`
 }
 
+// PeriodTotals are the headline counts for one time range
+type PeriodTotals struct {
+	Views          int64 `json:"views"`
+	Clicks         int64 `json:"clicks"`
+	UniqueVisitors int64 `json:"unique_visitors"`
+}
+
+type InteractionStats struct {
+	InteractionType string `json:"interaction_type"`
+	Count           int64  `json:"count"`
+}
+
+// ExportPageParams selects the next page of a user's raw analytics, in
+// clicked_at order, after the cursor left by the previous page. A zero
+// AfterID starts from StartDate. Each row carries its item's content type,
+// which is nil once the item has been deleted.
+type ExportPageParams struct {
+	UserID         uuid.UUID
+	StartDate      time.Time
+	EndDate        time.Time
+	AfterClickedAt time.Time
+	AfterID        uuid.UUID
+	Limit          int
+}
+
+// RollupRangeParams selects the raw analytics window to aggregate. A nil
+// UserID rebuilds rollups for every user.
+type RollupRangeParams struct {
+	UserID    *uuid.UUID
+	StartDate time.Time
+	EndDate   time.Time
+}
+
+// PurgeWarningParams selects users holding analytics that will age out of
+// their tier's retention window soon. WarnBefore is the event age that
+// triggers a warning; users warned after RewarnBefore are skipped.
+type PurgeWarningParams struct {
+	FreeWarnBefore      time.Time
+	PremiumWarnBefore   time.Time
+	FreeRewarnBefore    time.Time
+	PremiumRewarnBefore time.Time
+	Limit               int
+}
+
+// AnonymizeParams selects up to Limit not yet anonymized analytics recorded
+// before Before. Visitor IPs are replaced by a salted hash, so unique visitor
+// counts survive, or cleared when Salt is empty.
+type AnonymizeParams struct {
+	Before time.Time
+	Salt   string
+	Limit  int
+}
+
+// PurgeParams selects up to Limit analytics older than their owner's tier
+// retention window
+type PurgeParams struct {
+	FreeBefore    time.Time
+	PremiumBefore time.Time
+	Limit         int
+}
+
+type PurgeWarningCandidate struct {
+	UserID      uuid.UUID
+	Username    string
+	Email       string
+	Locale      string
+	IsPremium   bool
+	OldestEvent time.Time
+}
+
 // Implementation
+// purgeBatchSize is how many rows one PurgeAnalyticsOlderThan statement
+// deletes
+const purgeBatchSize = 1000
+
 type SQLCAnalyticsRepository struct {
-	db     *db.Queries
-	logger log.Logger
+	db        *db.Queries
+	txManager TxManager
+	logger    log.Logger
 }
 
-func NewAnalyticsRepository(db *db.Queries, logger log.Logger) AnalyticsRepository {
+func NewAnalyticsRepository(db *db.Queries, txManager TxManager, logger log.Logger) AnalyticsRepository {
 	return &SQLCAnalyticsRepository{
-		db:     db,
-		logger: logger,
+		db:        db,
+		txManager: txManager,
+		logger:    logger,
 	}
 }
 
@@ -135,12 +293,22 @@ func (r *SQLCAnalyticsRepository) CreateAnalyticsEntry(ctx context.Context, para
 		referrerPtr = nil
 	}
 
+	interactionType := params.InteractionType
+	if interactionType == "" {
+		interactionType = "click"
+	}
+
 	sqlcParams := db.CreateAnalyticsEntryParams{
-		ItemID:    params.ItemID,
-		UserID:    params.UserID,
-		IpAddress: ipAddressPtr,
-		UserAgent: userAgentPtr,
-		Referrer:  referrerPtr,
+		ItemID:          params.ItemID,
+		UserID:          params.UserID,
+		IpAddress:       ipAddressPtr,
+		UserAgent:       userAgentPtr,
+		Referrer:        referrerPtr,
+		InteractionType: interactionType,
+		IsBot:           params.IsBot,
+		UtmSource:       ptr.String(params.UTM.Source),
+		UtmMedium:       ptr.String(params.UTM.Medium),
+		UtmCampaign:     ptr.String(params.UTM.Campaign),
 	}
 
 	start := time.Now()
@@ -157,6 +325,56 @@ func (r *SQLCAnalyticsRepository) CreateAnalyticsEntry(ctx context.Context, para
 	return entry, nil
 }
 
+// CreateAnalyticsEntries stores many interactions with one INSERT, so
+// either all of them are recorded or none are
+func (r *SQLCAnalyticsRepository) CreateAnalyticsEntries(ctx context.Context, entries []CreateAnalyticsParams) (int64, error) {
+	r.logger.Infof("Creating %d analytics entries", len(entries))
+
+	sqlcParams := db.CreateAnalyticsEntriesParams{
+		ItemIds:          make([]uuid.UUID, len(entries)),
+		UserIds:          make([]uuid.UUID, len(entries)),
+		IpAddresses:      make([]string, len(entries)),
+		UserAgents:       make([]string, len(entries)),
+		Referrers:        make([]string, len(entries)),
+		InteractionTypes: make([]string, len(entries)),
+		IsBots:           make([]bool, len(entries)),
+		UtmSources:       make([]string, len(entries)),
+		UtmMediums:       make([]string, len(entries)),
+		UtmCampaigns:     make([]string, len(entries)),
+	}
+
+	for i, entry := range entries {
+		interactionType := entry.InteractionType
+		if interactionType == "" {
+			interactionType = "click"
+		}
+
+		sqlcParams.ItemIds[i] = entry.ItemID
+		sqlcParams.UserIds[i] = entry.UserID
+		sqlcParams.IpAddresses[i] = entry.IPAddress
+		sqlcParams.UserAgents[i] = entry.UserAgent
+		sqlcParams.Referrers[i] = entry.Referrer
+		sqlcParams.InteractionTypes[i] = interactionType
+		sqlcParams.IsBots[i] = entry.IsBot
+		sqlcParams.UtmSources[i] = entry.UTM.Source
+		sqlcParams.UtmMediums[i] = entry.UTM.Medium
+		sqlcParams.UtmCampaigns[i] = entry.UTM.Campaign
+	}
+
+	start := time.Now()
+	created, err := r.db.CreateAnalyticsEntries(ctx, sqlcParams)
+	duration := time.Since(start)
+
+	if err != nil {
+		appErr := errors.HandleDBError(err, "analytics entries")
+		appErr.Log(r.logger)
+		return 0, appErr
+	}
+
+	r.logger.Infof("Created %d analytics entries in %v", created, duration)
+	return created, nil
+}
+
 func (r *SQLCAnalyticsRepository) CreatePageViewEntry(ctx context.Context, params CreatePageViewParams) (*db.Analytic, error) {
 	r.logger.Infof("Creating page view entry for user ID: %s, item ID: %s", params.UserID, params.ItemID)
 
@@ -177,11 +395,15 @@ func (r *SQLCAnalyticsRepository) CreatePageViewEntry(ctx context.Context, param
 	}
 
 	sqlcParams := db.CreatePageViewEntryParams{
-		ItemID:    params.ItemID,
-		UserID:    params.UserID,
-		IpAddress: ipAddressPtr,
-		UserAgent: userAgentPtr,
-		Referrer:  referrerPtr,
+		ItemID:      params.ItemID,
+		UserID:      params.UserID,
+		IpAddress:   ipAddressPtr,
+		UserAgent:   userAgentPtr,
+		Referrer:    referrerPtr,
+		IsBot:       params.IsBot,
+		UtmSource:   ptr.String(params.UTM.Source),
+		UtmMedium:   ptr.String(params.UTM.Medium),
+		UtmCampaign: ptr.String(params.UTM.Campaign),
 	}
 
 	start := time.Now()
@@ -199,13 +421,20 @@ func (r *SQLCAnalyticsRepository) CreatePageViewEntry(ctx context.Context, param
 }
 
 func (r *SQLCAnalyticsRepository) GetUserAnalyticsByTimeRange(ctx context.Context, params TimeRangeParams) ([]DailyAnalytics, error) {
-	r.logger.Debugf("Getting user analytics for user ID: %s from %s to %s",
+	granularity := params.Granularity
+	if granularity == "" {
+		granularity = "day"
+	}
+
+	r.logger.Debugf("Getting user analytics by %s for user ID: %s from %s to %s", granularity,
 		params.UserID, params.StartDate.Format(time.RFC3339), params.EndDate.Format(time.RFC3339))
 
 	sqlcParams := db.GetUserAnalyticsByTimeRangeParams{
+		Unit:        granularity,
 		UserID:      params.UserID,
-		ClickedAt:   &params.StartDate,
-		ClickedAt_2: &params.EndDate,
+		StartDate:   &params.StartDate,
+		EndDate:     &params.EndDate,
+		IncludeBots: params.IncludeBots,
 	}
 
 	start := time.Now()
@@ -218,6 +447,36 @@ func (r *SQLCAnalyticsRepository) GetUserAnalyticsByTimeRange(ctx context.Contex
 		return nil, appErr
 	}
 
+	result := make([]DailyAnalytics, len(rows))
+	for i, row := range rows {
+		result[i] = DailyAnalytics{
+			Day:    row.Bucket,
+			Clicks: row.Clicks,
+		}
+	}
+
+	r.logger.Debugf("Retrieved %d %s buckets of analytics for user ID: %s in %v", len(result), granularity, params.UserID, duration)
+	return result, nil
+}
+
+// GetUserClicksForDays counts clicks on each of the given days. Days must be
+// truncated to midnight; days without clicks are omitted from the result.
+func (r *SQLCAnalyticsRepository) GetUserClicksForDays(ctx context.Context, userID uuid.UUID, days []time.Time) ([]DailyAnalytics, error) {
+	r.logger.Debugf("Getting user clicks for %d days for user ID: %s", len(days), userID)
+
+	start := time.Now()
+	rows, err := r.db.GetUserClicksForDays(ctx, db.GetUserClicksForDaysParams{
+		UserID: userID,
+		Days:   days,
+	})
+	duration := time.Since(start)
+
+	if err != nil {
+		appErr := errors.HandleDBError(err, "user analytics")
+		appErr.Log(r.logger)
+		return nil, appErr
+	}
+
 	result := make([]DailyAnalytics, len(rows))
 	for i, row := range rows {
 		result[i] = DailyAnalytics{
@@ -226,7 +485,7 @@ func (r *SQLCAnalyticsRepository) GetUserAnalyticsByTimeRange(ctx context.Contex
 		}
 	}
 
-	r.logger.Debugf("Retrieved %d daily analytics for user ID: %s in %v", len(result), params.UserID, duration)
+	r.logger.Debugf("Retrieved clicks for %d of %d days for user ID: %s in %v", len(result), len(days), userID, duration)
 	return result, nil
 }
 
@@ -238,6 +497,7 @@ func (r *SQLCAnalyticsRepository) GetItemAnalyticsByTimeRange(ctx context.Contex
 		ItemID:      params.ItemID,
 		ClickedAt:   &params.StartDate,
 		ClickedAt_2: &params.EndDate,
+		IsBot:       params.IncludeBots,
 	}
 
 	start := time.Now()
@@ -266,14 +526,30 @@ func (r *SQLCAnalyticsRepository) GetProfilePageViewsByDate(ctx context.Context,
 	r.logger.Debugf("Getting profile page views for user ID: %s from %s to %s",
 		params.UserID, params.StartDate.Format(time.RFC3339), params.EndDate.Format(time.RFC3339))
 
-	sqlcParams := db.GetProfilePageViewsByDateParams{
-		UserID:      params.UserID,
-		ClickedAt:   &params.StartDate,
-		ClickedAt_2: &params.EndDate,
-	}
+	var rows []*db.GetProfilePageViewsByDateRow
+	var err error
 
 	start := time.Now()
-	rows, err := r.db.GetProfilePageViewsByDate(ctx, sqlcParams)
+	if rollupStart, rollupEnd, ok := rollupDays(params.StartDate, params.EndDate, start); ok && !params.IncludeBots {
+		var rolled []*db.GetProfilePageViewsByDateFromRollupsRow
+		rolled, err = r.db.GetProfilePageViewsByDateFromRollups(ctx, db.GetProfilePageViewsByDateFromRollupsParams{
+			UserID:      params.UserID,
+			RollupStart: rollupStart,
+			RollupEnd:   rollupEnd,
+			StartAt:     params.StartDate,
+			EndAt:       params.EndDate,
+		})
+		for _, row := range rolled {
+			rows = append(rows, &db.GetProfilePageViewsByDateRow{Day: row.Day, Views: row.Views})
+		}
+	} else {
+		rows, err = r.db.GetProfilePageViewsByDate(ctx, db.GetProfilePageViewsByDateParams{
+			UserID:      params.UserID,
+			ClickedAt:   &params.StartDate,
+			ClickedAt_2: &params.EndDate,
+			IsBot:       params.IncludeBots,
+		})
+	}
 	duration := time.Since(start)
 
 	if err != nil {
@@ -298,10 +574,12 @@ func (r *SQLCAnalyticsRepository) GetTopContentItemsByClicks(ctx context.Context
 	r.logger.Debugf("Getting top content items by clicks for user ID: %s, limit: %d", params.UserID, params.Limit)
 
 	sqlcParams := db.GetTopContentItemsByClicksParams{
-		UserID:      params.UserID,
-		ClickedAt:   &params.StartDate,
-		ClickedAt_2: &params.EndDate,
-		Limit:       int64(params.Limit),
+		UserID:        params.UserID,
+		ClickedAt:     &params.StartDate,
+		ClickedAt_2:   &params.EndDate,
+		Limit:         int64(params.Limit),
+		IsBot:         params.IncludeBots,
+		WindowSeconds: params.UniqueWindow.Seconds(),
 	}
 
 	start := time.Now()
@@ -317,10 +595,11 @@ func (r *SQLCAnalyticsRepository) GetTopContentItemsByClicks(ctx context.Context
 	result := make([]TopContentItem, len(rows))
 	for i, row := range rows {
 		result[i] = TopContentItem{
-			ItemID:      row.ItemID.String(),
-			ContentType: row.ContentType,
-			Title:       row.Title,
-			ClickCount:  row.ClickCount,
+			ItemID:           row.ItemID.String(),
+			ContentType:      row.ContentType,
+			Title:            row.Title,
+			ClickCount:       row.ClickCount,
+			UniqueClickCount: row.UniqueClickCount,
 		}
 	}
 
@@ -336,6 +615,7 @@ func (r *SQLCAnalyticsRepository) GetReferrerAnalytics(ctx context.Context, para
 		ClickedAt:   &params.StartDate,
 		ClickedAt_2: &params.EndDate,
 		Limit:       int64(params.Limit),
+		IsBot:       params.IncludeBots,
 	}
 
 	start := time.Now()
@@ -360,6 +640,108 @@ func (r *SQLCAnalyticsRepository) GetReferrerAnalytics(ctx context.Context, para
 	return result, nil
 }
 
+func (r *SQLCAnalyticsRepository) GetCampaignCounts(ctx context.Context, params TimeRangeParams) ([]CampaignCount, error) {
+	r.logger.Debugf("Getting campaign counts for user ID: %s from %s to %s",
+		params.UserID, params.StartDate.Format(time.RFC3339), params.EndDate.Format(time.RFC3339))
+
+	sqlcParams := db.GetCampaignCountsParams{
+		UserID:      params.UserID,
+		ClickedAt:   &params.StartDate,
+		ClickedAt_2: &params.EndDate,
+		IsBot:       params.IncludeBots,
+	}
+
+	start := time.Now()
+	rows, err := r.db.GetCampaignCounts(ctx, sqlcParams)
+	duration := time.Since(start)
+
+	if err != nil {
+		appErr := errors.HandleDBError(err, "campaign analytics")
+		appErr.Log(r.logger)
+		return nil, appErr
+	}
+
+	result := make([]CampaignCount, len(rows))
+	for i, row := range rows {
+		result[i] = CampaignCount{
+			Source:    row.UtmSource,
+			Medium:    row.UtmMedium,
+			Campaign:  row.UtmCampaign,
+			Clicks:    row.Clicks,
+			PageViews: row.PageViews,
+		}
+	}
+
+	r.logger.Debugf("Retrieved %d campaign counts for user ID: %s in %v", len(result), params.UserID, duration)
+	return result, nil
+}
+
+func (r *SQLCAnalyticsRepository) GetUserAgentCounts(ctx context.Context, params TimeRangeParams) ([]UserAgentCount, error) {
+	r.logger.Debugf("Getting user agent counts for user ID: %s from %s to %s",
+		params.UserID, params.StartDate.Format(time.RFC3339), params.EndDate.Format(time.RFC3339))
+
+	sqlcParams := db.GetUserAgentCountsParams{
+		UserID:      params.UserID,
+		ClickedAt:   &params.StartDate,
+		ClickedAt_2: &params.EndDate,
+		IsBot:       params.IncludeBots,
+	}
+
+	start := time.Now()
+	rows, err := r.db.GetUserAgentCounts(ctx, sqlcParams)
+	duration := time.Since(start)
+
+	if err != nil {
+		appErr := errors.HandleDBError(err, "user agent analytics")
+		appErr.Log(r.logger)
+		return nil, appErr
+	}
+
+	result := make([]UserAgentCount, len(rows))
+	for i, row := range rows {
+		result[i] = UserAgentCount{
+			UserAgent: row.UserAgent,
+			Count:     row.Count,
+		}
+	}
+
+	r.logger.Debugf("Retrieved %d distinct user agents for user ID: %s in %v", len(result), params.UserID, duration)
+	return result, nil
+}
+
+func (r *SQLCAnalyticsRepository) GetIPAddressCounts(ctx context.Context, params TimeRangeParams) ([]IPAddressCount, error) {
+	r.logger.Debugf("Getting IP address counts for user ID: %s from %s to %s",
+		params.UserID, params.StartDate.Format(time.RFC3339), params.EndDate.Format(time.RFC3339))
+
+	sqlcParams := db.GetIPAddressCountsParams{
+		UserID:      params.UserID,
+		ClickedAt:   &params.StartDate,
+		ClickedAt_2: &params.EndDate,
+		IsBot:       params.IncludeBots,
+	}
+
+	start := time.Now()
+	rows, err := r.db.GetIPAddressCounts(ctx, sqlcParams)
+	duration := time.Since(start)
+
+	if err != nil {
+		appErr := errors.HandleDBError(err, "geographic analytics")
+		appErr.Log(r.logger)
+		return nil, appErr
+	}
+
+	result := make([]IPAddressCount, len(rows))
+	for i, row := range rows {
+		result[i] = IPAddressCount{
+			IPAddress: row.IpAddress,
+			Count:     row.Count,
+		}
+	}
+
+	r.logger.Debugf("Retrieved %d distinct IP addresses for user ID: %s in %v", len(result), params.UserID, duration)
+	return result, nil
+}
+
 func (r *SQLCAnalyticsRepository) GetUniqueVisitors(ctx context.Context, params TimeRangeParams) (int64, error) {
 	r.logger.Debugf("Getting unique visitors count for user ID: %s from %s to %s",
 		params.UserID, params.StartDate.Format(time.RFC3339), params.EndDate.Format(time.RFC3339))
@@ -368,6 +750,7 @@ func (r *SQLCAnalyticsRepository) GetUniqueVisitors(ctx context.Context, params
 		UserID:      params.UserID,
 		ClickedAt:   &params.StartDate,
 		ClickedAt_2: &params.EndDate,
+		IsBot:       params.IncludeBots,
 	}
 
 	start := time.Now()
@@ -384,6 +767,57 @@ func (r *SQLCAnalyticsRepository) GetUniqueVisitors(ctx context.Context, params
 	return count, nil
 }
 
+// GetPeriodTotals counts views, clicks and unique visitors in
+// [StartDate, EndDate). Without bots, whole settled days are summed from the
+// daily rollups instead of counted from raw analytics.
+func (r *SQLCAnalyticsRepository) GetPeriodTotals(ctx context.Context, params TimeRangeParams) (*PeriodTotals, error) {
+	r.logger.Debugf("Getting period totals for user ID: %s from %s to %s",
+		params.UserID, params.StartDate.Format(time.RFC3339), params.EndDate.Format(time.RFC3339))
+
+	var row *db.GetUserPeriodTotalsRow
+	var err error
+
+	start := time.Now()
+	if rollupStart, rollupEnd, ok := rollupDays(params.StartDate, params.EndDate, start); ok && !params.IncludeBots {
+		var rolled *db.GetUserPeriodTotalsFromRollupsRow
+		rolled, err = r.db.GetUserPeriodTotalsFromRollups(ctx, db.GetUserPeriodTotalsFromRollupsParams{
+			UserID:      params.UserID,
+			StartAt:     params.StartDate,
+			EndAt:       params.EndDate,
+			RollupStart: rollupStart,
+			RollupEnd:   rollupEnd,
+		})
+		if rolled != nil {
+			row = &db.GetUserPeriodTotalsRow{
+				Views:          rolled.Views,
+				Clicks:         rolled.Clicks,
+				UniqueVisitors: rolled.UniqueVisitors,
+			}
+		}
+	} else {
+		row, err = r.db.GetUserPeriodTotals(ctx, db.GetUserPeriodTotalsParams{
+			UserID:      params.UserID,
+			ClickedAt:   &params.StartDate,
+			ClickedAt_2: &params.EndDate,
+			IsBot:       params.IncludeBots,
+		})
+	}
+	duration := time.Since(start)
+
+	if err != nil {
+		appErr := errors.HandleDBError(err, "period totals")
+		appErr.Log(r.logger)
+		return nil, appErr
+	}
+
+	r.logger.Debugf("Retrieved period totals for user ID: %s in %v", params.UserID, duration)
+	return &PeriodTotals{
+		Views:          row.Views,
+		Clicks:         row.Clicks,
+		UniqueVisitors: row.UniqueVisitors,
+	}, nil
+}
+
 func (r *SQLCAnalyticsRepository) GetUniqueVisitorsByDay(ctx context.Context, params TimeRangeParams) ([]VisitorAnalytics, error) {
 	r.logger.Debugf("Getting unique visitors by day for user ID: %s from %s to %s",
 		params.UserID, params.StartDate.Format(time.RFC3339), params.EndDate.Format(time.RFC3339))
@@ -392,6 +826,7 @@ func (r *SQLCAnalyticsRepository) GetUniqueVisitorsByDay(ctx context.Context, pa
 		UserID:      params.UserID,
 		ClickedAt:   &params.StartDate,
 		ClickedAt_2: &params.EndDate,
+		IsBot:       params.IncludeBots,
 	}
 
 	start := time.Now()
@@ -479,11 +914,38 @@ func (r *SQLCAnalyticsRepository) GetContentItemClickCount(ctx context.Context,
 	return count, nil
 }
 
-func (r *SQLCAnalyticsRepository) GetProfilePageViews(ctx context.Context, userID uuid.UUID) (int64, error) {
+// GetContentItemUniqueClickCount counts an item's clicks since the given
+// time, counting each IP address at most once per window. Bot traffic and
+// clicks without an IP address are left out.
+func (r *SQLCAnalyticsRepository) GetContentItemUniqueClickCount(ctx context.Context, itemID uuid.UUID, since time.Time, window time.Duration) (int64, error) {
+	r.logger.Debugf("Getting unique click count for content item ID: %s since %v (window %v)", itemID, since, window)
+
+	start := time.Now()
+	count, err := r.db.GetContentItemUniqueClickCount(ctx, db.GetContentItemUniqueClickCountParams{
+		ItemID:        itemID,
+		ClickedAt:     &since,
+		WindowSeconds: window.Seconds(),
+	})
+	duration := time.Since(start)
+
+	if err != nil {
+		appErr := errors.HandleDBError(err, "content item unique click count")
+		appErr.Log(r.logger)
+		return 0, appErr
+	}
+
+	r.logger.Debugf("Retrieved unique click count: %d for content item ID: %s in %v", count, itemID, duration)
+	return count, nil
+}
+
+func (r *SQLCAnalyticsRepository) GetProfilePageViews(ctx context.Context, userID uuid.UUID, includeBots bool) (int64, error) {
 	r.logger.Debugf("Getting profile page views for user ID: %s", userID)
 
 	start := time.Now()
-	count, err := r.db.GetProfilePageViews(ctx, userID)
+	count, err := r.db.GetProfilePageViews(ctx, db.GetProfilePageViewsParams{
+		UserID: userID,
+		IsBot:  includeBots,
+	})
 	duration := time.Since(start)
 
 	if err != nil {
@@ -496,11 +958,14 @@ func (r *SQLCAnalyticsRepository) GetProfilePageViews(ctx context.Context, userI
 	return count, nil
 }
 
-func (r *SQLCAnalyticsRepository) GetUserItemClickCount(ctx context.Context, userID uuid.UUID) (int64, error) {
+func (r *SQLCAnalyticsRepository) GetUserItemClickCount(ctx context.Context, userID uuid.UUID, includeBots bool) (int64, error) {
 	r.logger.Debugf("Getting total clicks for user ID: %s", userID)
 
 	start := time.Now()
-	count, err := r.db.GetUserItemClickCount(ctx, userID)
+	count, err := r.db.GetUserItemClickCount(ctx, db.GetUserItemClickCountParams{
+		UserID: userID,
+		IsBot:  includeBots,
+	})
 	duration := time.Since(start)
 
 	if err != nil {
@@ -512,3 +977,260 @@ func (r *SQLCAnalyticsRepository) GetUserItemClickCount(ctx context.Context, use
 	r.logger.Debugf("Retrieved total click count: %d for user ID: %s in %v", count, userID, duration)
 	return count, nil
 }
+
+func (r *SQLCAnalyticsRepository) GetItemInteractionBreakdown(ctx context.Context, itemID uuid.UUID) ([]InteractionStats, error) {
+	r.logger.Debugf("Getting interaction breakdown for item ID: %s", itemID)
+
+	start := time.Now()
+	rows, err := r.db.GetItemInteractionBreakdown(ctx, itemID)
+	duration := time.Since(start)
+
+	if err != nil {
+		appErr := errors.HandleDBError(err, "item interaction breakdown")
+		appErr.Log(r.logger)
+		return nil, appErr
+	}
+
+	result := make([]InteractionStats, len(rows))
+	for i, row := range rows {
+		result[i] = InteractionStats{
+			InteractionType: row.InteractionType,
+			Count:           row.Count,
+		}
+	}
+
+	r.logger.Debugf("Retrieved %d interaction types for item ID: %s in %v", len(result), itemID, duration)
+	return result, nil
+}
+
+func (r *SQLCAnalyticsRepository) ListRecentClicks(ctx context.Context, userID uuid.UUID, limit int) ([]*db.ListRecentClicksRow, error) {
+	r.logger.Debugf("Listing %d recent clicks for user ID: %s", limit, userID)
+
+	start := time.Now()
+	clicks, err := r.db.ListRecentClicks(ctx, db.ListRecentClicksParams{
+		UserID: userID,
+		Limit:  int64(limit),
+	})
+	duration := time.Since(start)
+
+	if err != nil {
+		appErr := errors.HandleDBError(err, "recent clicks")
+		appErr.Log(r.logger)
+		return nil, appErr
+	}
+
+	r.logger.Debugf("Retrieved %d recent clicks for user ID: %s in %v", len(clicks), userID, duration)
+	return clicks, nil
+}
+
+func (r *SQLCAnalyticsRepository) ListAnalyticsForExport(ctx context.Context, params ExportPageParams) ([]*db.ListUserAnalyticsForExportRow, error) {
+	r.logger.Debugf("Listing analytics for export for user ID: %s after %s", params.UserID, params.AfterID)
+
+	afterClickedAt := params.AfterClickedAt
+	if params.AfterID == uuid.Nil {
+		afterClickedAt = params.StartDate
+	}
+
+	start := time.Now()
+	analytics, err := r.db.ListUserAnalyticsForExport(ctx, db.ListUserAnalyticsForExportParams{
+		UserID:         params.UserID,
+		EndAt:          &params.EndDate,
+		AfterClickedAt: afterClickedAt,
+		AfterID:        params.AfterID,
+		RowLimit:       int64(params.Limit),
+	})
+	duration := time.Since(start)
+
+	if err != nil {
+		appErr := errors.HandleDBError(err, "analytics export")
+		appErr.Log(r.logger)
+		return nil, appErr
+	}
+
+	r.logger.Debugf("Retrieved %d analytics entries for export for user ID: %s in %v", len(analytics), params.UserID, duration)
+	return analytics, nil
+}
+
+// rollupLag is how long a day must have been over before its rollup is
+// trusted. The refresh worker rebuilds the last couple of days every run, so
+// anything newer may still be missing or partial.
+const rollupLag = 24 * time.Hour
+
+// rollupDays returns the whole UTC days inside [start, end) that can be read
+// from the daily rollups, and false when there are none.
+func rollupDays(start, end, now time.Time) (time.Time, time.Time, bool) {
+	rollupStart := start.UTC().Truncate(24 * time.Hour)
+	if rollupStart.Before(start) {
+		rollupStart = rollupStart.Add(24 * time.Hour)
+	}
+
+	rollupEnd := end.UTC().Truncate(24 * time.Hour)
+	if settled := now.UTC().Add(-rollupLag).Truncate(24 * time.Hour); settled.Before(rollupEnd) {
+		rollupEnd = settled
+	}
+
+	return rollupStart, rollupEnd, rollupStart.Before(rollupEnd)
+}
+
+func (r *SQLCAnalyticsRepository) RebuildDailyRollups(ctx context.Context, params RollupRangeParams) (int64, error) {
+	r.logger.Debugf("Rebuilding daily rollups from %s to %s", params.StartDate.Format(time.RFC3339), params.EndDate.Format(time.RFC3339))
+
+	start := time.Now()
+
+	// Clear the window first so days whose raw rows have since been purged
+	// don't keep stale aggregates around. Both steps share a transaction so
+	// readers never see the window emptied.
+	var rows int64
+	err := r.txManager.WithTransaction(ctx, func(ctx context.Context) error {
+		q := queriesFor(ctx, r.db)
+
+		err := q.DeleteAnalyticsRollups(ctx, db.DeleteAnalyticsRollupsParams{
+			StartDay: params.StartDate,
+			EndDay:   params.EndDate,
+			UserID:   params.UserID,
+		})
+		if err != nil {
+			return err
+		}
+
+		rows, err = q.RebuildAnalyticsRollups(ctx, db.RebuildAnalyticsRollupsParams{
+			StartAt: &params.StartDate,
+			EndAt:   &params.EndDate,
+			UserID:  params.UserID,
+		})
+		return err
+	})
+	duration := time.Since(start)
+
+	if err != nil {
+		appErr := errors.HandleDBError(err, "analytics rollups")
+		appErr.Log(r.logger)
+		return 0, appErr
+	}
+
+	r.logger.Debugf("Rebuilt %d daily rollup rows in %v", rows, duration)
+	return rows, nil
+}
+
+func (r *SQLCAnalyticsRepository) ListUsersDueForPurgeWarning(ctx context.Context, params PurgeWarningParams) ([]PurgeWarningCandidate, error) {
+	r.logger.Debugf("Listing up to %d users due for an analytics purge warning", params.Limit)
+
+	start := time.Now()
+	rows, err := r.db.ListUsersDueForPurgeWarning(ctx, db.ListUsersDueForPurgeWarningParams{
+		PremiumWarnBefore:   params.PremiumWarnBefore,
+		FreeWarnBefore:      params.FreeWarnBefore,
+		PremiumRewarnBefore: params.PremiumRewarnBefore,
+		FreeRewarnBefore:    params.FreeRewarnBefore,
+		MaxUsers:            int32(params.Limit),
+	})
+	duration := time.Since(start)
+
+	if err != nil {
+		appErr := errors.HandleDBError(err, "purge warning candidates")
+		appErr.Log(r.logger)
+		return nil, appErr
+	}
+
+	result := make([]PurgeWarningCandidate, len(rows))
+	for i, row := range rows {
+		result[i] = PurgeWarningCandidate{
+			UserID:      row.UserID,
+			Username:    row.Username,
+			Email:       row.Email,
+			Locale:      ptr.GetValueOrEmpty(row.Locale),
+			IsPremium:   row.IsPremium,
+			OldestEvent: row.OldestEvent,
+		}
+	}
+
+	r.logger.Debugf("Found %d users due for a purge warning in %v", len(result), duration)
+	return result, nil
+}
+
+func (r *SQLCAnalyticsRepository) MarkPurgeWarned(ctx context.Context, userID uuid.UUID) error {
+	r.logger.Debugf("Marking purge warning sent for user ID: %s", userID)
+
+	start := time.Now()
+	err := r.db.MarkPurgeWarned(ctx, userID)
+	duration := time.Since(start)
+
+	if err != nil {
+		appErr := errors.HandleDBError(err, "user")
+		appErr.Log(r.logger)
+		return appErr
+	}
+
+	r.logger.Debugf("Marked purge warning for user ID: %s in %v", userID, duration)
+	return nil
+}
+
+func (r *SQLCAnalyticsRepository) AnonymizeAnalytics(ctx context.Context, params AnonymizeParams) (int64, error) {
+	r.logger.Debugf("Anonymizing up to %d analytics entries recorded before %v", params.Limit, params.Before)
+
+	start := time.Now()
+	rows, err := r.db.AnonymizeAnalytics(ctx, db.AnonymizeAnalyticsParams{
+		Salt:            params.Salt,
+		AnonymizeBefore: params.Before,
+		BatchSize:       int32(params.Limit),
+	})
+	duration := time.Since(start)
+
+	if err != nil {
+		appErr := errors.HandleDBError(err, "analytics")
+		appErr.Log(r.logger)
+		return 0, appErr
+	}
+
+	r.logger.Debugf("Anonymized %d analytics entries in %v", rows, duration)
+	return rows, nil
+}
+
+func (r *SQLCAnalyticsRepository) DeleteExpiredAnalytics(ctx context.Context, params PurgeParams) (int64, error) {
+	r.logger.Debugf("Deleting up to %d expired analytics entries", params.Limit)
+
+	start := time.Now()
+	rows, err := r.db.DeleteExpiredAnalytics(ctx, db.DeleteExpiredAnalyticsParams{
+		PremiumBefore: params.PremiumBefore,
+		FreeBefore:    params.FreeBefore,
+		BatchSize:     int32(params.Limit),
+	})
+	duration := time.Since(start)
+
+	if err != nil {
+		appErr := errors.HandleDBError(err, "analytics")
+		appErr.Log(r.logger)
+		return 0, appErr
+	}
+
+	r.logger.Debugf("Deleted %d expired analytics entries in %v", rows, duration)
+	return rows, nil
+}
+
+// PurgeAnalyticsOlderThan deletes every analytics entry recorded before
+// cutoff. Rows go in batches of purgeBatchSize, each its own statement, so
+// no single delete holds its locks for long.
+func (r *SQLCAnalyticsRepository) PurgeAnalyticsOlderThan(ctx context.Context, cutoff time.Time) (int64, error) {
+	r.logger.Debugf("Purging analytics entries recorded before %v", cutoff)
+
+	start := time.Now()
+	var total int64
+	for ctx.Err() == nil {
+		rows, err := r.db.DeleteAnalyticsOlderThan(ctx, db.DeleteAnalyticsOlderThanParams{
+			Cutoff:    cutoff,
+			BatchSize: purgeBatchSize,
+		})
+		if err != nil {
+			appErr := errors.HandleDBError(err, "analytics")
+			appErr.Log(r.logger)
+			return total, appErr
+		}
+		total += rows
+		if rows < purgeBatchSize {
+			break
+		}
+	}
+	duration := time.Since(start)
+
+	r.logger.Debugf("Purged %d analytics entries recorded before %v in %v", total, cutoff, duration)
+	return total, nil
+}
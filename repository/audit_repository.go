@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	db "github.com/0xsj/mios.io/db/sqlc"
+	"github.com/0xsj/mios.io/log"
+	"github.com/0xsj/mios.io/pkg/errors"
+	"github.com/google/uuid"
+	"github.com/jackc/pgtype"
+)
+
+type AuditRepository interface {
+	Record(ctx context.Context, entry AuditEntry) error
+}
+
+// AuditEntry is one security-relevant action. ActorID is the account that
+// performed it and TargetID, when set, the account it was performed on.
+type AuditEntry struct {
+	ActorID  uuid.UUID
+	Action   string
+	TargetID *uuid.UUID
+	Details  map[string]interface{}
+}
+
+type SQLAuditRepository struct {
+	db     *db.Queries
+	logger log.Logger
+}
+
+func NewAuditRepository(db *db.Queries, logger log.Logger) AuditRepository {
+	return &SQLAuditRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *SQLAuditRepository) Record(ctx context.Context, entry AuditEntry) error {
+	r.logger.Infof("Recording audit entry %s by user ID: %s", entry.Action, entry.ActorID)
+
+	details := pgtype.JSONB{Status: pgtype.Null}
+	if len(entry.Details) > 0 {
+		bytes, err := json.Marshal(entry.Details)
+		if err != nil {
+			return errors.NewInternalError("Failed to encode audit details", err)
+		}
+		details = pgtype.JSONB{Bytes: bytes, Status: pgtype.Present}
+	}
+
+	start := time.Now()
+	err := r.db.CreateAuditLogEntry(ctx, db.CreateAuditLogEntryParams{
+		ActorID:  &entry.ActorID,
+		Action:   entry.Action,
+		TargetID: entry.TargetID,
+		Details:  details,
+	})
+	duration := time.Since(start)
+
+	if err != nil {
+		appErr := errors.HandleDBError(err, "audit log")
+		appErr.Log(r.logger)
+		return appErr
+	}
+
+	r.logger.Debugf("Audit entry %s recorded in %v", entry.Action, duration)
+	return nil
+}
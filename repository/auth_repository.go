@@ -8,6 +8,7 @@ import (
 	db "github.com/0xsj/mios.io/db/sqlc"
 	"github.com/0xsj/mios.io/log"
 	"github.com/0xsj/mios.io/pkg/errors"
+	"github.com/0xsj/mios.io/pkg/ptr"
 	"github.com/google/uuid"
 )
 
@@ -19,15 +20,44 @@ type AuthRepository interface {
 	ClearResetToken(ctx context.Context, userID uuid.UUID) error
 	VerifyEmail(ctx context.Context, userID uuid.UUID) error
 	UpdateLastLogin(ctx context.Context, userID uuid.UUID) error
-	IncrementFailedLoginAttempts(ctx context.Context, userID uuid.UUID) error
+	IncrementFailedLoginAttempts(ctx context.Context, userID uuid.UUID) (int, error)
 	SetAccountLockout(ctx context.Context, userID uuid.UUID, lockedUntil time.Time) error
-	StoreRefreshToken(ctx context.Context, userID uuid.UUID, refreshToken string) error
-	InvalidateRefreshToken(ctx context.Context, userID uuid.UUID) error
+	StoreRefreshToken(ctx context.Context, params StoreRefreshTokenParams) error
+	ConsumeRefreshToken(ctx context.Context, tokenID uuid.UUID) (bool, error)
+	GetRefreshToken(ctx context.Context, tokenID uuid.UUID) (*db.RefreshToken, error)
+	InvalidateRefreshTokenFamily(ctx context.Context, sessionID uuid.UUID) error
+	InvalidateUserRefreshTokens(ctx context.Context, userID uuid.UUID) error
 	GetAuthByVerificationToken(ctx context.Context, verificationToken string) (*db.Auth, error) // Add this if not present
 	UpdateEmailVerificationStatus(ctx context.Context, userID uuid.UUID, isVerified bool) error // Add this
 	ClearVerificationToken(ctx context.Context, userID uuid.UUID) error // Add this
+	SetVerificationToken(ctx context.Context, userID uuid.UUID, verificationToken string) error
+	GetVerificationStatuses(ctx context.Context, userIDs []uuid.UUID) (map[uuid.UUID]bool, error)
+	ListUnverifiedUsersCreatedBefore(ctx context.Context, before time.Time, limit int) ([]UnverifiedUser, error)
+	SetTOTPSecret(ctx context.Context, userID uuid.UUID, secret string, recoveryCodeHashes []string) error
+	EnableTOTP(ctx context.Context, userID uuid.UUID) error
+	ConsumeRecoveryCode(ctx context.Context, userID uuid.UUID, codeHash string) (bool, error)
 }
 
+// UnverifiedUser is an account whose email address was never confirmed
+type UnverifiedUser struct {
+	UserID    uuid.UUID
+	Username  string
+	Email     string
+	Locale    string
+	CreatedAt time.Time
+}
+
+// StoreRefreshTokenParams records an issued refresh token by its jti. The
+// tokens issued to one session form a family.
+type StoreRefreshTokenParams struct {
+	TokenID   uuid.UUID
+	SessionID uuid.UUID
+	UserID    uuid.UUID
+	ExpiresAt time.Time
+}
+
+// CreateAuthParams describes a new auth record. An empty PasswordHash and
+// Salt create an OAuth-only account that cannot sign in with a password.
 type CreateAuthParams struct {
 	UserID            uuid.UUID
 	PasswordHash      string
@@ -57,8 +87,8 @@ func (r *SQLCAuthRepository) CreateAuth(ctx context.Context, params CreateAuthPa
 
 	dbParams := db.CreateAuthParams{
 		UserID:              params.UserID,
-		PasswordHash:        params.PasswordHash,
-		Salt:                params.Salt,
+		PasswordHash:        ptr.String(params.PasswordHash),
+		Salt:                ptr.String(params.Salt),
 		IsEmailVerified:     isEmailVerified,
 		VerificationToken:   verificationToken,
 		ResetToken:          nil,
@@ -101,8 +131,8 @@ func (r *SQLCAuthRepository) UpdatePassword(ctx context.Context, userID uuid.UUI
 
 	params := db.UpdatePasswordHashParams{
 		UserID:       userID,
-		PasswordHash: passwordHash,
-		Salt:         salt,
+		PasswordHash: &passwordHash,
+		Salt:         &salt,
 	}
 
 	start := time.Now()
@@ -193,23 +223,31 @@ func (r *SQLCAuthRepository) UpdateLastLogin(ctx context.Context, userID uuid.UU
 	return nil
 }
 
-func (r *SQLCAuthRepository) IncrementFailedLoginAttempts(ctx context.Context, userID uuid.UUID) error {
+// IncrementFailedLoginAttempts bumps the failure counter and returns its new value
+func (r *SQLCAuthRepository) IncrementFailedLoginAttempts(ctx context.Context, userID uuid.UUID) (int, error) {
 	r.logger.Warnf("Incrementing failed login attempts for user ID: %s", userID)
 
 	start := time.Now()
-	err := r.db.IncrementFailedLoginAttempts(ctx, userID)
+	attempts, err := r.db.IncrementFailedLoginAttempts(ctx, userID)
 	duration := time.Since(start)
 
 	if err != nil {
 		appErr := errors.HandleDBError(err, "failed login attempts update")
 		appErr.Log(r.logger)
-		return appErr
+		return 0, appErr
 	}
 
-	r.logger.Warnf("Failed login attempts incremented for user ID: %s in %v", userID, duration)
-	return nil
+	count := 0
+	if attempts != nil {
+		count = int(*attempts)
+	}
+
+	r.logger.Warnf("Failed login attempts incremented to %d for user ID: %s in %v", count, userID, duration)
+	return count, nil
 }
 
+// SetAccountLockout locks the account until lockedUntil and starts the
+// failure count afresh for when the lock lapses
 func (r *SQLCAuthRepository) SetAccountLockout(ctx context.Context, userID uuid.UUID, lockedUntil time.Time) error {
 	r.logger.Warnf("Setting account lockout for user ID: %s until %v", userID, lockedUntil)
 
@@ -232,33 +270,86 @@ func (r *SQLCAuthRepository) SetAccountLockout(ctx context.Context, userID uuid.
 	return nil
 }
 
-func (r *SQLCAuthRepository) StoreRefreshToken(ctx context.Context, userID uuid.UUID, refreshToken string) error {
-	r.logger.Debugf("Storing refresh token for user ID: %s, token length: %d", userID, len(refreshToken))
+func (r *SQLCAuthRepository) StoreRefreshToken(ctx context.Context, params StoreRefreshTokenParams) error {
+	r.logger.Debugf("Storing refresh token %s for session %s", params.TokenID, params.SessionID)
 
-	params := db.StoreRefreshTokenParams{
-		UserID:       userID,
-		RefreshToken: &refreshToken,
+	start := time.Now()
+	err := r.db.StoreRefreshToken(ctx, db.StoreRefreshTokenParams{
+		TokenID:   params.TokenID,
+		SessionID: params.SessionID,
+		UserID:    params.UserID,
+		ExpiresAt: params.ExpiresAt,
+	})
+	duration := time.Since(start)
+
+	if err != nil {
+		appErr := errors.HandleDBError(err, "refresh token storage")
+		appErr.Log(r.logger)
+		return appErr
 	}
 
+	r.logger.Debugf("Refresh token stored successfully for user ID: %s in %v", params.UserID, duration)
+	return nil
+}
+
+// ConsumeRefreshToken marks an unexpired refresh token as used. It reports
+// false if the token is unknown, expired or was already consumed.
+func (r *SQLCAuthRepository) ConsumeRefreshToken(ctx context.Context, tokenID uuid.UUID) (bool, error) {
 	start := time.Now()
-	err := r.db.StoreRefreshToken(ctx, params)
+	rows, err := r.db.ConsumeRefreshToken(ctx, tokenID)
 	duration := time.Since(start)
 
 	if err != nil {
-		appErr := errors.HandleDBError(err, "refresh token storage")
+		appErr := errors.HandleDBError(err, "refresh token")
+		appErr.Log(r.logger)
+		return false, appErr
+	}
+
+	r.logger.Debugf("Refresh token %s consume completed in %v (consumed: %v)", tokenID, duration, rows > 0)
+	return rows > 0, nil
+}
+
+func (r *SQLCAuthRepository) GetRefreshToken(ctx context.Context, tokenID uuid.UUID) (*db.RefreshToken, error) {
+	start := time.Now()
+	refreshToken, err := r.db.GetRefreshToken(ctx, tokenID)
+	duration := time.Since(start)
+
+	if err != nil {
+		appErr := errors.HandleDBError(err, "refresh token")
+		appErr.Log(r.logger)
+		return nil, appErr
+	}
+
+	r.logger.Debugf("Refresh token %s retrieved in %v", tokenID, duration)
+	return refreshToken, nil
+}
+
+// InvalidateRefreshTokenFamily marks every refresh token issued to the
+// session as consumed
+func (r *SQLCAuthRepository) InvalidateRefreshTokenFamily(ctx context.Context, sessionID uuid.UUID) error {
+	r.logger.Infof("Invalidating refresh token family for session: %s", sessionID)
+
+	start := time.Now()
+	err := r.db.InvalidateRefreshTokenFamily(ctx, sessionID)
+	duration := time.Since(start)
+
+	if err != nil {
+		appErr := errors.HandleDBError(err, "refresh token invalidation")
 		appErr.Log(r.logger)
 		return appErr
 	}
 
-	r.logger.Debugf("Refresh token stored successfully for user ID: %s in %v", userID, duration)
+	r.logger.Infof("Refresh token family for session %s invalidated in %v", sessionID, duration)
 	return nil
 }
 
-func (r *SQLCAuthRepository) InvalidateRefreshToken(ctx context.Context, userID uuid.UUID) error {
-	r.logger.Infof("Invalidating refresh token for user ID: %s", userID)
+// InvalidateUserRefreshTokens marks every refresh token issued to the user,
+// across all sessions, as consumed
+func (r *SQLCAuthRepository) InvalidateUserRefreshTokens(ctx context.Context, userID uuid.UUID) error {
+	r.logger.Infof("Invalidating all refresh tokens for user: %s", userID)
 
 	start := time.Now()
-	err := r.db.InvalidateRefreshToken(ctx, userID)
+	err := r.db.InvalidateUserRefreshTokens(ctx, userID)
 	duration := time.Since(start)
 
 	if err != nil {
@@ -267,7 +358,7 @@ func (r *SQLCAuthRepository) InvalidateRefreshToken(ctx context.Context, userID
 		return appErr
 	}
 
-	r.logger.Infof("Refresh token invalidated successfully for user ID: %s in %v", userID, duration)
+	r.logger.Infof("Refresh tokens for user %s invalidated in %v", userID, duration)
 	return nil
 }
 
@@ -315,4 +406,142 @@ func (r *SQLCAuthRepository) ClearVerificationToken(ctx context.Context, userID
 
 	r.logger.Infof("Verification token cleared successfully for user ID: %s in %v", userID, duration)
 	return nil
-}
\ No newline at end of file
+}
+func (r *SQLCAuthRepository) SetVerificationToken(ctx context.Context, userID uuid.UUID, verificationToken string) error {
+	r.logger.Infof("Setting verification token for user ID: %s", userID)
+
+	start := time.Now()
+	err := r.db.SetVerificationToken(ctx, db.SetVerificationTokenParams{
+		UserID:            userID,
+		VerificationToken: &verificationToken,
+	})
+	duration := time.Since(start)
+
+	if err != nil {
+		appErr := errors.HandleDBError(err, "verification token")
+		appErr.Log(r.logger)
+		return appErr
+	}
+
+	r.logger.Infof("Verification token set for user ID: %s in %v", userID, duration)
+	return nil
+}
+
+// GetVerificationStatuses looks up verification state for many users in one
+// query. Users without an auth record are left out of the result.
+func (r *SQLCAuthRepository) GetVerificationStatuses(ctx context.Context, userIDs []uuid.UUID) (map[uuid.UUID]bool, error) {
+	r.logger.Debugf("Getting verification statuses for %d users", len(userIDs))
+
+	start := time.Now()
+	rows, err := r.db.GetVerificationStatuses(ctx, userIDs)
+	duration := time.Since(start)
+
+	if err != nil {
+		appErr := errors.HandleDBError(err, "verification statuses")
+		appErr.Log(r.logger)
+		return nil, appErr
+	}
+
+	statuses := make(map[uuid.UUID]bool, len(rows))
+	for _, row := range rows {
+		statuses[row.UserID] = row.IsEmailVerified != nil && *row.IsEmailVerified
+	}
+
+	r.logger.Debugf("Retrieved %d verification statuses in %v", len(statuses), duration)
+	return statuses, nil
+}
+
+func (r *SQLCAuthRepository) ListUnverifiedUsersCreatedBefore(ctx context.Context, before time.Time, limit int) ([]UnverifiedUser, error) {
+	r.logger.Debugf("Listing unverified users created before %s", before.Format(time.RFC3339))
+
+	start := time.Now()
+	rows, err := r.db.ListUnverifiedUsersCreatedBefore(ctx, db.ListUnverifiedUsersCreatedBeforeParams{
+		CreatedAt: &before,
+		Limit:     int32(limit),
+	})
+	duration := time.Since(start)
+
+	if err != nil {
+		appErr := errors.HandleDBError(err, "unverified users")
+		appErr.Log(r.logger)
+		return nil, appErr
+	}
+
+	users := make([]UnverifiedUser, len(rows))
+	for i, row := range rows {
+		users[i] = UnverifiedUser{
+			UserID:   row.UserID,
+			Username: row.Username,
+			Email:    row.Email,
+			Locale:   ptr.GetValueOrEmpty(row.Locale),
+		}
+		if row.CreatedAt != nil {
+			users[i].CreatedAt = *row.CreatedAt
+		}
+	}
+
+	r.logger.Debugf("Found %d unverified users in %v", len(users), duration)
+	return users, nil
+}
+
+// SetTOTPSecret stores a pending TOTP secret and its recovery codes. Two-factor
+// stays disabled until EnableTOTP is called after the first valid code.
+func (r *SQLCAuthRepository) SetTOTPSecret(ctx context.Context, userID uuid.UUID, secret string, recoveryCodeHashes []string) error {
+	r.logger.Infof("Setting TOTP secret for user ID: %s", userID)
+
+	start := time.Now()
+	err := r.db.SetTOTPSecret(ctx, db.SetTOTPSecretParams{
+		UserID:            userID,
+		TotpSecret:        &secret,
+		TotpRecoveryCodes: recoveryCodeHashes,
+	})
+	duration := time.Since(start)
+
+	if err != nil {
+		appErr := errors.HandleDBError(err, "totp secret")
+		appErr.Log(r.logger)
+		return appErr
+	}
+
+	r.logger.Infof("TOTP secret set for user ID: %s in %v", userID, duration)
+	return nil
+}
+
+func (r *SQLCAuthRepository) EnableTOTP(ctx context.Context, userID uuid.UUID) error {
+	r.logger.Infof("Enabling TOTP for user ID: %s", userID)
+
+	start := time.Now()
+	err := r.db.EnableTOTP(ctx, userID)
+	duration := time.Since(start)
+
+	if err != nil {
+		appErr := errors.HandleDBError(err, "totp")
+		appErr.Log(r.logger)
+		return appErr
+	}
+
+	r.logger.Infof("TOTP enabled for user ID: %s in %v", userID, duration)
+	return nil
+}
+
+// ConsumeRecoveryCode removes the recovery code so it cannot be used again. It
+// reports false when the user has no such unused code.
+func (r *SQLCAuthRepository) ConsumeRecoveryCode(ctx context.Context, userID uuid.UUID, codeHash string) (bool, error) {
+	r.logger.Infof("Consuming recovery code for user ID: %s", userID)
+
+	start := time.Now()
+	rows, err := r.db.ConsumeRecoveryCode(ctx, db.ConsumeRecoveryCodeParams{
+		CodeHash: codeHash,
+		UserID:   userID,
+	})
+	duration := time.Since(start)
+
+	if err != nil {
+		appErr := errors.HandleDBError(err, "recovery code")
+		appErr.Log(r.logger)
+		return false, appErr
+	}
+
+	r.logger.Infof("Recovery code check for user ID: %s completed in %v (consumed: %v)", userID, duration, rows > 0)
+	return rows > 0, nil
+}
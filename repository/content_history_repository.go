@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	db "github.com/0xsj/mios.io/db/sqlc"
+	"github.com/0xsj/mios.io/log"
+	"github.com/0xsj/mios.io/pkg/errors"
+	"github.com/google/uuid"
+	"github.com/jackc/pgtype"
+)
+
+type ContentHistoryRepository interface {
+	Record(ctx context.Context, entry ContentHistoryEntry) error
+	ListHistory(ctx context.Context, itemID uuid.UUID, limit, offset int) ([]*db.ContentHistory, error)
+	CountHistory(ctx context.Context, itemID uuid.UUID) (int64, error)
+}
+
+// ContentHistoryEntry is one applied change to a content item. ActorID is
+// nil for changes made by the system, and Changes maps each changed field
+// to its before and after values.
+type ContentHistoryEntry struct {
+	ItemID  uuid.UUID
+	ActorID *uuid.UUID
+	Source  string
+	Action  string
+	Changes map[string]interface{}
+}
+
+type SQLContentHistoryRepository struct {
+	db     *db.Queries
+	logger log.Logger
+}
+
+func NewContentHistoryRepository(db *db.Queries, logger log.Logger) ContentHistoryRepository {
+	return &SQLContentHistoryRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *SQLContentHistoryRepository) Record(ctx context.Context, entry ContentHistoryEntry) error {
+	r.logger.Debugf("Recording %s history entry (%s) for item ID: %s", entry.Action, entry.Source, entry.ItemID)
+
+	changes := pgtype.JSONB{Status: pgtype.Null}
+	if len(entry.Changes) > 0 {
+		bytes, err := json.Marshal(entry.Changes)
+		if err != nil {
+			return errors.NewInternalError("Failed to encode content history changes", err)
+		}
+		changes = pgtype.JSONB{Bytes: bytes, Status: pgtype.Present}
+	}
+
+	start := time.Now()
+	err := r.db.CreateContentHistoryEntry(ctx, db.CreateContentHistoryEntryParams{
+		ItemID:  entry.ItemID,
+		ActorID: entry.ActorID,
+		Source:  entry.Source,
+		Action:  entry.Action,
+		Changes: changes,
+	})
+	duration := time.Since(start)
+
+	if err != nil {
+		appErr := errors.HandleDBError(err, "content history")
+		appErr.Log(r.logger)
+		return appErr
+	}
+
+	r.logger.Debugf("Content history entry for item ID: %s recorded in %v", entry.ItemID, duration)
+	return nil
+}
+
+func (r *SQLContentHistoryRepository) ListHistory(ctx context.Context, itemID uuid.UUID, limit, offset int) ([]*db.ContentHistory, error) {
+	r.logger.Debugf("Listing content history for item ID: %s (limit %d, offset %d)", itemID, limit, offset)
+
+	start := time.Now()
+	entries, err := r.db.ListContentHistory(ctx, db.ListContentHistoryParams{
+		ItemID: itemID,
+		Limit:  int32(limit),
+		Offset: int32(offset),
+	})
+	duration := time.Since(start)
+
+	if err != nil {
+		appErr := errors.HandleDBError(err, "content history")
+		appErr.Log(r.logger)
+		return nil, appErr
+	}
+
+	r.logger.Debugf("Retrieved %d content history entries for item ID: %s in %v", len(entries), itemID, duration)
+	return entries, nil
+}
+
+func (r *SQLContentHistoryRepository) CountHistory(ctx context.Context, itemID uuid.UUID) (int64, error) {
+	start := time.Now()
+	count, err := r.db.CountContentHistory(ctx, itemID)
+	duration := time.Since(start)
+
+	if err != nil {
+		appErr := errors.HandleDBError(err, "content history")
+		appErr.Log(r.logger)
+		return 0, appErr
+	}
+
+	r.logger.Debugf("Counted %d content history entries for item ID: %s in %v", count, itemID, duration)
+	return count, nil
+}
@@ -14,32 +14,72 @@ import (
 type ContentRepository interface {
 	CreateContentItem(ctx context.Context, params CreateContentItemParams) (*db.ContentItem, error)
 	GetContentItem(ctx context.Context, itemID uuid.UUID) (*db.ContentItem, error)
+	// GetContentItemsByIDs loads the given items in one query, leaving out
+	// any that don't exist or are in the trash
+	GetContentItemsByIDs(ctx context.Context, itemIDs []uuid.UUID) ([]*db.ContentItem, error)
 	GetUserContentItems(ctx context.Context, userID uuid.UUID) ([]*db.ContentItem, error)
+	// GetPublishedUserContentItems leaves out items that are scheduled for
+	// later or have been unpublished
+	GetPublishedUserContentItems(ctx context.Context, userID uuid.UUID) ([]*db.ContentItem, error)
+	CountActiveUserContentItems(ctx context.Context, userID uuid.UUID) (int64, error)
+	CountUserContentItemsByType(ctx context.Context, userID uuid.UUID) (map[string]int64, error)
+	CountUserContentItemsByTypeAndState(ctx context.Context, userID uuid.UUID) ([]ContentStateCount, error)
+	CopyContentItems(ctx context.Context, sourceUserID, targetUserID uuid.UUID) (int64, error)
+	GetItemsOwnership(ctx context.Context, itemIDs []uuid.UUID) (map[uuid.UUID]uuid.UUID, error)
+	CountPinnedContentItems(ctx context.Context, userID, excludeItemID uuid.UUID) (int64, error)
+	BulkUpdateStyle(ctx context.Context, params BulkStyleParams) (int64, error)
 	UpdateContentItem(ctx context.Context, params UpdateContentItemParams) error
 	UpdateContentItemPosition(ctx context.Context, params UpdatePositionParams) error
+	// ReorderContentItems moves all the user's listed items or, if any of
+	// them isn't the user's, none of them
+	ReorderContentItems(ctx context.Context, userID uuid.UUID, positions []ItemPosition) (int64, error)
+	UpdateContentItemPin(ctx context.Context, itemID uuid.UUID, pinned bool, pinOrder int32) error
+	// DeleteContentItem moves an item to the trash; every other lookup
+	// except the trash ones below then treats it as gone
 	DeleteContentItem(ctx context.Context, itemID uuid.UUID) error
+	GetDeletedContentItem(ctx context.Context, itemID uuid.UUID) (*db.ContentItem, error)
+	ListDeletedUserContentItems(ctx context.Context, userID uuid.UUID) ([]*db.ContentItem, error)
+	RestoreContentItem(ctx context.Context, itemID uuid.UUID) error
+	// PurgeDeletedContentItems permanently removes up to limit items trashed
+	// before deletedBefore
+	PurgeDeletedContentItems(ctx context.Context, deletedBefore time.Time, limit int) (int64, error)
+}
+
+// ContentStateCount is how many of a user's items of one type are in one
+// state: active, inactive, or draft (no active flag set)
+type ContentStateCount struct {
+	ContentType string
+	State       string
+	Count       int64
 }
 
 // CreateContentItemParams matches the service input types
 type CreateContentItemParams struct {
-	UserID       uuid.UUID
-	ContentID    string
-	ContentType  string
-	Title        *string
-	Href         *string
-	URL          *string
-	MediaType    *string
-	DesktopX     *int32
-	DesktopY     *int32
-	DesktopStyle *string
-	MobileX      *int32
-	MobileY      *int32
-	MobileStyle  *string
-	HAlign       *string
-	VAlign       *string
-	ContentData  pgtype.JSONB
-	Overrides    pgtype.JSONB
-	IsActive     bool
+	UserID        uuid.UUID
+	ContentID     string
+	ContentType   string
+	Title         *string
+	Href          *string
+	URL           *string
+	MediaType     *string
+	DesktopX      *int32
+	DesktopY      *int32
+	DesktopStyle  *string
+	MobileX       *int32
+	MobileY       *int32
+	MobileStyle   *string
+	HAlign        *string
+	VAlign        *string
+	ContentData   pgtype.JSONB
+	Overrides     pgtype.JSONB
+	IsActive      bool
+	Pinned        bool
+	PinOrder      int32
+	PublishAt     *time.Time
+	UnpublishAt   *time.Time
+	CustomStyling pgtype.JSONB
+	EmbedData     pgtype.JSONB
+	AutoEmbed     bool
 }
 
 // UpdateContentItemParams matches the service input types
@@ -56,6 +96,22 @@ type UpdateContentItemParams struct {
 	ContentData  *pgtype.JSONB
 	Overrides    *pgtype.JSONB
 	IsActive     *bool
+	Pinned       *bool
+	PinOrder     *int32
+	PublishAt    *time.Time
+	UnpublishAt  *time.Time
+}
+
+// BulkStyleParams selects a user's items by ID and/or content type and
+// carries the style fields to apply. Nil fields are left unchanged;
+// Overrides is merged into each item's existing overrides.
+type BulkStyleParams struct {
+	UserID       uuid.UUID
+	ItemIDs      []uuid.UUID
+	ContentType  *string
+	DesktopStyle *string
+	MobileStyle  *string
+	Overrides    *pgtype.JSONB
 }
 
 // UpdatePositionParams matches the service input types
@@ -67,6 +123,15 @@ type UpdatePositionParams struct {
 	MobileY  *int32
 }
 
+// ItemPosition is where one item goes in a bulk reorder
+type ItemPosition struct {
+	ItemID   uuid.UUID
+	DesktopX int32
+	DesktopY int32
+	MobileX  int32
+	MobileY  int32
+}
+
 type SQLContentRepository struct {
 	db     *db.Queries
 	logger log.Logger
@@ -84,24 +149,31 @@ func (r *SQLContentRepository) CreateContentItem(ctx context.Context, params Cre
 
 	// We directly pass the pointers since the types now match
 	sqlcParams := db.CreateContentItemParams{
-		UserID:       params.UserID,
-		ContentID:    params.ContentID,
-		ContentType:  params.ContentType,
-		Title:        params.Title,
-		Href:         params.Href,
-		Url:          params.URL,
-		MediaType:    params.MediaType,
-		DesktopX:     params.DesktopX,
-		DesktopY:     params.DesktopY,
-		DesktopStyle: params.DesktopStyle,
-		MobileX:      params.MobileX,
-		MobileY:      params.MobileY,
-		MobileStyle:  params.MobileStyle,
-		Halign:       params.HAlign,
-		Valign:       params.VAlign,
-		ContentData:  params.ContentData,
-		Overrides:    params.Overrides,
-		IsActive:     &params.IsActive,
+		UserID:        params.UserID,
+		ContentID:     params.ContentID,
+		ContentType:   params.ContentType,
+		Title:         params.Title,
+		Href:          params.Href,
+		Url:           params.URL,
+		MediaType:     params.MediaType,
+		DesktopX:      params.DesktopX,
+		DesktopY:      params.DesktopY,
+		DesktopStyle:  params.DesktopStyle,
+		MobileX:       params.MobileX,
+		MobileY:       params.MobileY,
+		MobileStyle:   params.MobileStyle,
+		Halign:        params.HAlign,
+		Valign:        params.VAlign,
+		ContentData:   params.ContentData,
+		Overrides:     params.Overrides,
+		IsActive:      &params.IsActive,
+		Pinned:        params.Pinned,
+		PinOrder:      params.PinOrder,
+		PublishAt:     params.PublishAt,
+		UnpublishAt:   params.UnpublishAt,
+		CustomStyling: params.CustomStyling,
+		EmbedData:     params.EmbedData,
+		AutoEmbed:     &params.AutoEmbed,
 	}
 
 	start := time.Now()
@@ -122,7 +194,7 @@ func (r *SQLContentRepository) GetContentItem(ctx context.Context, itemID uuid.U
 	r.logger.Debugf("Getting content item with ID: %s", itemID)
 
 	start := time.Now()
-	item, err := r.db.GetContentItem(ctx, itemID)
+	item, err := queriesFor(ctx, r.db).GetContentItem(ctx, itemID)
 	duration := time.Since(start)
 
 	if err != nil {
@@ -135,6 +207,23 @@ func (r *SQLContentRepository) GetContentItem(ctx context.Context, itemID uuid.U
 	return item, nil
 }
 
+func (r *SQLContentRepository) GetContentItemsByIDs(ctx context.Context, itemIDs []uuid.UUID) ([]*db.ContentItem, error) {
+	r.logger.Debugf("Getting %d content items by ID", len(itemIDs))
+
+	start := time.Now()
+	items, err := r.db.GetContentItemsByIDs(ctx, itemIDs)
+	duration := time.Since(start)
+
+	if err != nil {
+		appErr := errors.HandleDBError(err, "content items")
+		appErr.Log(r.logger)
+		return nil, appErr
+	}
+
+	r.logger.Debugf("Retrieved %d of %d content items in %v", len(items), len(itemIDs), duration)
+	return items, nil
+}
+
 func (r *SQLContentRepository) GetUserContentItems(ctx context.Context, userID uuid.UUID) ([]*db.ContentItem, error) {
 	r.logger.Debugf("Getting content items for user ID: %s", userID)
 
@@ -152,6 +241,40 @@ func (r *SQLContentRepository) GetUserContentItems(ctx context.Context, userID u
 	return items, nil
 }
 
+func (r *SQLContentRepository) GetPublishedUserContentItems(ctx context.Context, userID uuid.UUID) ([]*db.ContentItem, error) {
+	r.logger.Debugf("Getting published content items for user ID: %s", userID)
+
+	start := time.Now()
+	items, err := r.db.GetPublishedUserContentItems(ctx, userID)
+	duration := time.Since(start)
+
+	if err != nil {
+		appErr := errors.HandleDBError(err, "content items")
+		appErr.Log(r.logger)
+		return nil, appErr
+	}
+
+	r.logger.Debugf("Retrieved %d published content items for user ID: %s in %v", len(items), userID, duration)
+	return items, nil
+}
+
+func (r *SQLContentRepository) CountActiveUserContentItems(ctx context.Context, userID uuid.UUID) (int64, error) {
+	r.logger.Debugf("Counting active content items for user ID: %s", userID)
+
+	start := time.Now()
+	count, err := r.db.CountActiveUserContentItems(ctx, userID)
+	duration := time.Since(start)
+
+	if err != nil {
+		appErr := errors.HandleDBError(err, "content items")
+		appErr.Log(r.logger)
+		return 0, appErr
+	}
+
+	r.logger.Debugf("Counted %d active content items for user ID: %s in %v", count, userID, duration)
+	return count, nil
+}
+
 func (r *SQLContentRepository) UpdateContentItem(ctx context.Context, params UpdateContentItemParams) error {
 	r.logger.Infof("Updating content item with ID: %s", params.ItemID)
 
@@ -182,10 +305,14 @@ func (r *SQLContentRepository) UpdateContentItem(ctx context.Context, params Upd
 		ContentData:  contentData,
 		Overrides:    overrides,
 		IsActive:     params.IsActive,
+		Pinned:       params.Pinned,
+		PinOrder:     params.PinOrder,
+		PublishAt:    params.PublishAt,
+		UnpublishAt:  params.UnpublishAt,
 	}
 
 	start := time.Now()
-	err := r.db.UpdateContentItem(ctx, sqlcParams)
+	err := queriesFor(ctx, r.db).UpdateContentItem(ctx, sqlcParams)
 	duration := time.Since(start)
 
 	if err != nil {
@@ -223,11 +350,65 @@ func (r *SQLContentRepository) UpdateContentItemPosition(ctx context.Context, pa
 	return nil
 }
 
+func (r *SQLContentRepository) ReorderContentItems(ctx context.Context, userID uuid.UUID, positions []ItemPosition) (int64, error) {
+	r.logger.Infof("Reordering %d content items for user ID: %s", len(positions), userID)
+
+	params := db.ReorderContentItemsParams{
+		ItemIds:  make([]uuid.UUID, len(positions)),
+		DesktopX: make([]int32, len(positions)),
+		DesktopY: make([]int32, len(positions)),
+		MobileX:  make([]int32, len(positions)),
+		MobileY:  make([]int32, len(positions)),
+		UserID:   userID,
+	}
+	for i, position := range positions {
+		params.ItemIds[i] = position.ItemID
+		params.DesktopX[i] = position.DesktopX
+		params.DesktopY[i] = position.DesktopY
+		params.MobileX[i] = position.MobileX
+		params.MobileY[i] = position.MobileY
+	}
+
+	start := time.Now()
+	updated, err := r.db.ReorderContentItems(ctx, params)
+	duration := time.Since(start)
+
+	if err != nil {
+		appErr := errors.HandleDBError(err, "content item position update")
+		appErr.Log(r.logger)
+		return 0, appErr
+	}
+
+	r.logger.Infof("Reordered %d content items for user ID: %s in %v", updated, userID, duration)
+	return updated, nil
+}
+
+func (r *SQLContentRepository) UpdateContentItemPin(ctx context.Context, itemID uuid.UUID, pinned bool, pinOrder int32) error {
+	r.logger.Infof("Setting pinned=%v (order %d) for content item with ID: %s", pinned, pinOrder, itemID)
+
+	start := time.Now()
+	err := r.db.UpdateContentItemPin(ctx, db.UpdateContentItemPinParams{
+		ItemID:   itemID,
+		Pinned:   pinned,
+		PinOrder: pinOrder,
+	})
+	duration := time.Since(start)
+
+	if err != nil {
+		appErr := errors.HandleDBError(err, "content item pin update")
+		appErr.Log(r.logger)
+		return appErr
+	}
+
+	r.logger.Infof("Pin updated successfully for content item ID: %s in %v", itemID, duration)
+	return nil
+}
+
 func (r *SQLContentRepository) DeleteContentItem(ctx context.Context, itemID uuid.UUID) error {
 	r.logger.Infof("Deleting content item with ID: %s", itemID)
 
 	start := time.Now()
-	err := r.db.DeleteContentItem(ctx, itemID)
+	rows, err := r.db.SoftDeleteContentItem(ctx, itemID)
 	duration := time.Since(start)
 
 	if err != nil {
@@ -236,6 +417,231 @@ func (r *SQLContentRepository) DeleteContentItem(ctx context.Context, itemID uui
 		return appErr
 	}
 
+	if rows == 0 {
+		return errors.NewNotFoundError("Content item not found", nil)
+	}
+
 	r.logger.Infof("Content item deleted successfully with ID: %s in %v", itemID, duration)
 	return nil
 }
+
+func (r *SQLContentRepository) GetDeletedContentItem(ctx context.Context, itemID uuid.UUID) (*db.ContentItem, error) {
+	r.logger.Debugf("Getting deleted content item with ID: %s", itemID)
+
+	start := time.Now()
+	item, err := r.db.GetDeletedContentItem(ctx, itemID)
+	duration := time.Since(start)
+
+	if err != nil {
+		appErr := errors.HandleDBError(err, "deleted content item")
+		appErr.Log(r.logger)
+		return nil, appErr
+	}
+
+	r.logger.Debugf("Deleted content item retrieved with ID: %s in %v", itemID, duration)
+	return item, nil
+}
+
+func (r *SQLContentRepository) ListDeletedUserContentItems(ctx context.Context, userID uuid.UUID) ([]*db.ContentItem, error) {
+	r.logger.Debugf("Listing deleted content items for user ID: %s", userID)
+
+	start := time.Now()
+	items, err := r.db.ListDeletedUserContentItems(ctx, userID)
+	duration := time.Since(start)
+
+	if err != nil {
+		appErr := errors.HandleDBError(err, "deleted content items")
+		appErr.Log(r.logger)
+		return nil, appErr
+	}
+
+	r.logger.Debugf("Retrieved %d deleted content items for user ID: %s in %v", len(items), userID, duration)
+	return items, nil
+}
+
+func (r *SQLContentRepository) RestoreContentItem(ctx context.Context, itemID uuid.UUID) error {
+	r.logger.Infof("Restoring deleted content item with ID: %s", itemID)
+
+	start := time.Now()
+	rows, err := r.db.RestoreContentItem(ctx, itemID)
+	duration := time.Since(start)
+
+	if err != nil {
+		appErr := errors.HandleDBError(err, "content item")
+		appErr.Log(r.logger)
+		return appErr
+	}
+
+	if rows == 0 {
+		return errors.NewNotFoundError("Deleted content item not found", nil)
+	}
+
+	r.logger.Infof("Restored content item with ID: %s in %v", itemID, duration)
+	return nil
+}
+
+func (r *SQLContentRepository) PurgeDeletedContentItems(ctx context.Context, deletedBefore time.Time, limit int) (int64, error) {
+	r.logger.Debugf("Purging content items deleted before %s", deletedBefore.Format(time.RFC3339))
+
+	start := time.Now()
+	rows, err := r.db.PurgeDeletedContentItems(ctx, db.PurgeDeletedContentItemsParams{
+		Cutoff:    deletedBefore,
+		BatchSize: int32(limit),
+	})
+	duration := time.Since(start)
+
+	if err != nil {
+		appErr := errors.HandleDBError(err, "content item")
+		appErr.Log(r.logger)
+		return 0, appErr
+	}
+
+	r.logger.Infof("Purged %d deleted content items in %v", rows, duration)
+	return rows, nil
+}
+
+func (r *SQLContentRepository) CountUserContentItemsByType(ctx context.Context, userID uuid.UUID) (map[string]int64, error) {
+	r.logger.Debugf("Counting content items by type for user ID: %s", userID)
+
+	start := time.Now()
+	rows, err := r.db.CountUserContentItemsByType(ctx, userID)
+	duration := time.Since(start)
+
+	if err != nil {
+		appErr := errors.HandleDBError(err, "content item counts")
+		appErr.Log(r.logger)
+		return nil, appErr
+	}
+
+	counts := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		counts[row.ContentType] = row.Count
+	}
+
+	r.logger.Debugf("Counted %d content types for user ID: %s in %v", len(counts), userID, duration)
+	return counts, nil
+}
+
+func (r *SQLContentRepository) CountUserContentItemsByTypeAndState(ctx context.Context, userID uuid.UUID) ([]ContentStateCount, error) {
+	r.logger.Debugf("Counting content items by type and state for user ID: %s", userID)
+
+	start := time.Now()
+	rows, err := r.db.CountUserContentItemsByTypeAndState(ctx, userID)
+	duration := time.Since(start)
+
+	if err != nil {
+		appErr := errors.HandleDBError(err, "content item counts")
+		appErr.Log(r.logger)
+		return nil, appErr
+	}
+
+	counts := make([]ContentStateCount, len(rows))
+	for i, row := range rows {
+		counts[i] = ContentStateCount{
+			ContentType: row.ContentType,
+			State:       row.State,
+			Count:       row.Count,
+		}
+	}
+
+	r.logger.Debugf("Counted %d type and state groups for user ID: %s in %v", len(counts), userID, duration)
+	return counts, nil
+}
+
+// CopyContentItems duplicates every active item of one user into another
+// account. The copy runs as a single INSERT ... SELECT, so either all items
+// land or none do.
+func (r *SQLContentRepository) CopyContentItems(ctx context.Context, sourceUserID, targetUserID uuid.UUID) (int64, error) {
+	r.logger.Infof("Copying content items from user ID: %s to user ID: %s", sourceUserID, targetUserID)
+
+	start := time.Now()
+	copied, err := r.db.CopyContentItems(ctx, db.CopyContentItemsParams{
+		TargetUserID: targetUserID,
+		SourceUserID: sourceUserID,
+	})
+	duration := time.Since(start)
+
+	if err != nil {
+		appErr := errors.HandleDBError(err, "content item")
+		appErr.Log(r.logger)
+		return 0, appErr
+	}
+
+	r.logger.Infof("Copied %d content items to user ID: %s in %v", copied, targetUserID, duration)
+	return copied, nil
+}
+
+// GetItemsOwnership maps each of the given items to its owner in a single
+// query. Items that don't exist are absent from the map.
+func (r *SQLContentRepository) GetItemsOwnership(ctx context.Context, itemIDs []uuid.UUID) (map[uuid.UUID]uuid.UUID, error) {
+	r.logger.Debugf("Getting owners of %d content items", len(itemIDs))
+
+	start := time.Now()
+	rows, err := r.db.GetContentItemsOwnership(ctx, itemIDs)
+	duration := time.Since(start)
+
+	if err != nil {
+		appErr := errors.HandleDBError(err, "content item")
+		appErr.Log(r.logger)
+		return nil, appErr
+	}
+
+	owners := make(map[uuid.UUID]uuid.UUID, len(rows))
+	for _, row := range rows {
+		owners[row.ItemID] = row.UserID
+	}
+
+	r.logger.Debugf("Found owners for %d of %d content items in %v", len(owners), len(itemIDs), duration)
+	return owners, nil
+}
+
+// CountPinnedContentItems counts the user's pinned items other than
+// excludeItemID, so an item being re-pinned doesn't count against itself
+func (r *SQLContentRepository) CountPinnedContentItems(ctx context.Context, userID, excludeItemID uuid.UUID) (int64, error) {
+	start := time.Now()
+	count, err := r.db.CountPinnedUserContentItems(ctx, db.CountPinnedUserContentItemsParams{
+		UserID: userID,
+		ItemID: excludeItemID,
+	})
+	duration := time.Since(start)
+
+	if err != nil {
+		appErr := errors.HandleDBError(err, "content item")
+		appErr.Log(r.logger)
+		return 0, appErr
+	}
+
+	r.logger.Debugf("User ID: %s has %d other pinned content items (%v)", userID, count, duration)
+	return count, nil
+}
+
+// BulkUpdateStyle applies one style patch to every selected item in a
+// single UPDATE, so the change lands on all items or none.
+func (r *SQLContentRepository) BulkUpdateStyle(ctx context.Context, params BulkStyleParams) (int64, error) {
+	r.logger.Infof("Bulk updating style for user ID: %s", params.UserID)
+
+	overrides := pgtype.JSONB{Status: pgtype.Null}
+	if params.Overrides != nil {
+		overrides = *params.Overrides
+	}
+
+	start := time.Now()
+	updated, err := r.db.BulkUpdateContentStyle(ctx, db.BulkUpdateContentStyleParams{
+		DesktopStyle: params.DesktopStyle,
+		MobileStyle:  params.MobileStyle,
+		Overrides:    overrides,
+		UserID:       params.UserID,
+		ItemIds:      params.ItemIDs,
+		ContentType:  params.ContentType,
+	})
+	duration := time.Since(start)
+
+	if err != nil {
+		appErr := errors.HandleDBError(err, "content item")
+		appErr.Log(r.logger)
+		return 0, appErr
+	}
+
+	r.logger.Infof("Bulk updated style on %d content items for user ID: %s in %v", updated, params.UserID, duration)
+	return updated, nil
+}
@@ -0,0 +1,200 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	db "github.com/0xsj/mios.io/db/sqlc"
+	"github.com/0xsj/mios.io/log"
+	"github.com/0xsj/mios.io/pkg/errors"
+	"github.com/google/uuid"
+	"github.com/jackc/pgtype"
+)
+
+type ContentRevisionRepository interface {
+	CreateRevision(ctx context.Context, params CreateRevisionParams) (*db.ContentRevision, error)
+	GetRevision(ctx context.Context, revisionID uuid.UUID) (*db.ContentRevision, error)
+	ListPendingRevisions(ctx context.Context) ([]*db.ContentRevision, error)
+	ListPendingRevisionsByOwner(ctx context.Context, ownerID uuid.UUID) ([]*db.ContentRevision, error)
+	ReviewRevision(ctx context.Context, revisionID uuid.UUID, status string, reviewerID uuid.UUID) (*db.ContentRevision, error)
+
+	// Collaborators
+	AddCollaborator(ctx context.Context, ownerID, collaboratorID uuid.UUID) error
+	RemoveCollaborator(ctx context.Context, ownerID, collaboratorID uuid.UUID) error
+	IsCollaborator(ctx context.Context, ownerID, collaboratorID uuid.UUID) (bool, error)
+}
+
+type CreateRevisionParams struct {
+	ItemID   uuid.UUID
+	OwnerID  uuid.UUID
+	AuthorID uuid.UUID
+	Changes  pgtype.JSONB
+}
+
+type SQLContentRevisionRepository struct {
+	db     *db.Queries
+	logger log.Logger
+}
+
+func NewContentRevisionRepository(db *db.Queries, logger log.Logger) ContentRevisionRepository {
+	return &SQLContentRevisionRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *SQLContentRevisionRepository) CreateRevision(ctx context.Context, params CreateRevisionParams) (*db.ContentRevision, error) {
+	r.logger.Infof("Creating content revision for item ID: %s by author ID: %s", params.ItemID, params.AuthorID)
+
+	start := time.Now()
+	revision, err := r.db.CreateContentRevision(ctx, db.CreateContentRevisionParams{
+		ItemID:   params.ItemID,
+		OwnerID:  params.OwnerID,
+		AuthorID: params.AuthorID,
+		Changes:  params.Changes,
+	})
+	duration := time.Since(start)
+
+	if err != nil {
+		appErr := errors.HandleDBError(err, "content revision")
+		appErr.Log(r.logger)
+		return nil, appErr
+	}
+
+	r.logger.Infof("Content revision created with ID: %s in %v", revision.RevisionID, duration)
+	return revision, nil
+}
+
+func (r *SQLContentRevisionRepository) GetRevision(ctx context.Context, revisionID uuid.UUID) (*db.ContentRevision, error) {
+	r.logger.Debugf("Getting content revision with ID: %s", revisionID)
+
+	start := time.Now()
+	revision, err := r.db.GetContentRevision(ctx, revisionID)
+	duration := time.Since(start)
+
+	if err != nil {
+		appErr := errors.HandleDBError(err, "content revision")
+		appErr.Log(r.logger)
+		return nil, appErr
+	}
+
+	r.logger.Debugf("Retrieved content revision with ID: %s in %v", revisionID, duration)
+	return revision, nil
+}
+
+func (r *SQLContentRevisionRepository) ListPendingRevisions(ctx context.Context) ([]*db.ContentRevision, error) {
+	r.logger.Debug("Listing all pending content revisions")
+
+	start := time.Now()
+	revisions, err := r.db.ListPendingContentRevisions(ctx)
+	duration := time.Since(start)
+
+	if err != nil {
+		appErr := errors.HandleDBError(err, "content revision")
+		appErr.Log(r.logger)
+		return nil, appErr
+	}
+
+	r.logger.Debugf("Retrieved %d pending content revisions in %v", len(revisions), duration)
+	return revisions, nil
+}
+
+func (r *SQLContentRevisionRepository) ListPendingRevisionsByOwner(ctx context.Context, ownerID uuid.UUID) ([]*db.ContentRevision, error) {
+	r.logger.Debugf("Listing pending content revisions for owner ID: %s", ownerID)
+
+	start := time.Now()
+	revisions, err := r.db.ListPendingContentRevisionsByOwner(ctx, ownerID)
+	duration := time.Since(start)
+
+	if err != nil {
+		appErr := errors.HandleDBError(err, "content revision")
+		appErr.Log(r.logger)
+		return nil, appErr
+	}
+
+	r.logger.Debugf("Retrieved %d pending content revisions for owner ID: %s in %v", len(revisions), ownerID, duration)
+	return revisions, nil
+}
+
+// ReviewRevision moves a pending revision to its final status. It returns a
+// not found error when the revision is missing or has already been reviewed.
+func (r *SQLContentRevisionRepository) ReviewRevision(ctx context.Context, revisionID uuid.UUID, status string, reviewerID uuid.UUID) (*db.ContentRevision, error) {
+	r.logger.Infof("Marking content revision %s as %s by reviewer ID: %s", revisionID, status, reviewerID)
+
+	start := time.Now()
+	revision, err := queriesFor(ctx, r.db).ReviewContentRevision(ctx, db.ReviewContentRevisionParams{
+		RevisionID: revisionID,
+		Status:     status,
+		ReviewedBy: &reviewerID,
+	})
+	duration := time.Since(start)
+
+	if err != nil {
+		appErr := errors.HandleDBError(err, "pending content revision")
+		appErr.Log(r.logger)
+		return nil, appErr
+	}
+
+	r.logger.Infof("Content revision %s marked as %s in %v", revisionID, status, duration)
+	return revision, nil
+}
+
+func (r *SQLContentRevisionRepository) AddCollaborator(ctx context.Context, ownerID, collaboratorID uuid.UUID) error {
+	r.logger.Infof("Adding collaborator %s to account %s", collaboratorID, ownerID)
+
+	start := time.Now()
+	err := r.db.AddAccountCollaborator(ctx, db.AddAccountCollaboratorParams{
+		OwnerID:        ownerID,
+		CollaboratorID: collaboratorID,
+	})
+	duration := time.Since(start)
+
+	if err != nil {
+		appErr := errors.HandleDBError(err, "account collaborator")
+		appErr.Log(r.logger)
+		return appErr
+	}
+
+	r.logger.Infof("Added collaborator %s to account %s in %v", collaboratorID, ownerID, duration)
+	return nil
+}
+
+func (r *SQLContentRevisionRepository) RemoveCollaborator(ctx context.Context, ownerID, collaboratorID uuid.UUID) error {
+	r.logger.Infof("Removing collaborator %s from account %s", collaboratorID, ownerID)
+
+	start := time.Now()
+	err := r.db.RemoveAccountCollaborator(ctx, db.RemoveAccountCollaboratorParams{
+		OwnerID:        ownerID,
+		CollaboratorID: collaboratorID,
+	})
+	duration := time.Since(start)
+
+	if err != nil {
+		appErr := errors.HandleDBError(err, "account collaborator")
+		appErr.Log(r.logger)
+		return appErr
+	}
+
+	r.logger.Infof("Removed collaborator %s from account %s in %v", collaboratorID, ownerID, duration)
+	return nil
+}
+
+func (r *SQLContentRevisionRepository) IsCollaborator(ctx context.Context, ownerID, collaboratorID uuid.UUID) (bool, error) {
+	r.logger.Debugf("Checking whether %s collaborates on account %s", collaboratorID, ownerID)
+
+	start := time.Now()
+	isCollaborator, err := r.db.IsAccountCollaborator(ctx, db.IsAccountCollaboratorParams{
+		OwnerID:        ownerID,
+		CollaboratorID: collaboratorID,
+	})
+	duration := time.Since(start)
+
+	if err != nil {
+		appErr := errors.HandleDBError(err, "account collaborator")
+		appErr.Log(r.logger)
+		return false, appErr
+	}
+
+	r.logger.Debugf("Collaborator check for %s on account %s completed in %v", collaboratorID, ownerID, duration)
+	return isCollaborator, nil
+}
@@ -0,0 +1,167 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	db "github.com/0xsj/mios.io/db/sqlc"
+	"github.com/0xsj/mios.io/log"
+	"github.com/0xsj/mios.io/pkg/errors"
+	"github.com/google/uuid"
+)
+
+// FileRepository records who owns each uploaded file
+type FileRepository interface {
+	CreateFile(ctx context.Context, params CreateFileParams) (*db.File, error)
+	GetFile(ctx context.Context, key string) (*db.File, error)
+	DeleteFile(ctx context.Context, key string) error
+	// UpdateFileSize records the size of a file uploaded straight to storage
+	UpdateFileSize(ctx context.Context, key string, size int64) error
+	// An empty category lists files of every category
+	ListUserFiles(ctx context.Context, userID uuid.UUID, category string, limit, offset int) ([]*db.File, error)
+	CountUserFiles(ctx context.Context, userID uuid.UUID, category string) (int64, error)
+}
+
+type CreateFileParams struct {
+	Key         string
+	UserID      uuid.UUID
+	Category    string
+	Size        int64
+	ContentType string
+}
+
+type SQLFileRepository struct {
+	db     *db.Queries
+	logger log.Logger
+}
+
+func NewFileRepository(db *db.Queries, logger log.Logger) FileRepository {
+	return &SQLFileRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *SQLFileRepository) CreateFile(ctx context.Context, params CreateFileParams) (*db.File, error) {
+	r.logger.Infof("Recording file %s for user ID: %s", params.Key, params.UserID)
+
+	start := time.Now()
+	file, err := r.db.CreateFileRecord(ctx, db.CreateFileRecordParams{
+		FileKey:     params.Key,
+		UserID:      params.UserID,
+		Category:    params.Category,
+		Size:        params.Size,
+		ContentType: params.ContentType,
+	})
+	duration := time.Since(start)
+
+	if err != nil {
+		appErr := errors.HandleDBError(err, "file")
+		appErr.Log(r.logger)
+		return nil, appErr
+	}
+
+	r.logger.Infof("File %s recorded in %v", params.Key, duration)
+	return file, nil
+}
+
+func (r *SQLFileRepository) GetFile(ctx context.Context, key string) (*db.File, error) {
+	r.logger.Debugf("Getting file record: %s", key)
+
+	start := time.Now()
+	file, err := r.db.GetFileRecord(ctx, key)
+	duration := time.Since(start)
+
+	if err != nil {
+		appErr := errors.HandleDBError(err, "file")
+		appErr.Log(r.logger)
+		return nil, appErr
+	}
+
+	r.logger.Debugf("Retrieved file record %s in %v", key, duration)
+	return file, nil
+}
+
+func (r *SQLFileRepository) DeleteFile(ctx context.Context, key string) error {
+	r.logger.Infof("Deleting file record: %s", key)
+
+	start := time.Now()
+	err := r.db.DeleteFileRecord(ctx, key)
+	duration := time.Since(start)
+
+	if err != nil {
+		appErr := errors.HandleDBError(err, "file")
+		appErr.Log(r.logger)
+		return appErr
+	}
+
+	r.logger.Infof("File record %s deleted in %v", key, duration)
+	return nil
+}
+
+func (r *SQLFileRepository) UpdateFileSize(ctx context.Context, key string, size int64) error {
+	r.logger.Debugf("Updating size of file %s to %d", key, size)
+
+	start := time.Now()
+	err := r.db.UpdateFileSize(ctx, db.UpdateFileSizeParams{
+		FileKey: key,
+		Size:    size,
+	})
+	duration := time.Since(start)
+
+	if err != nil {
+		appErr := errors.HandleDBError(err, "file")
+		appErr.Log(r.logger)
+		return appErr
+	}
+
+	r.logger.Debugf("File %s size updated in %v", key, duration)
+	return nil
+}
+
+func (r *SQLFileRepository) ListUserFiles(ctx context.Context, userID uuid.UUID, category string, limit, offset int) ([]*db.File, error) {
+	r.logger.Debugf("Listing files for user ID: %s (category %q, limit %d, offset %d)", userID, category, limit, offset)
+
+	start := time.Now()
+	files, err := r.db.ListUserFiles(ctx, db.ListUserFilesParams{
+		UserID:   userID,
+		Category: categoryFilter(category),
+		Limit:    int32(limit),
+		Offset:   int32(offset),
+	})
+	duration := time.Since(start)
+
+	if err != nil {
+		appErr := errors.HandleDBError(err, "file")
+		appErr.Log(r.logger)
+		return nil, appErr
+	}
+
+	r.logger.Debugf("Retrieved %d files for user ID: %s in %v", len(files), userID, duration)
+	return files, nil
+}
+
+func (r *SQLFileRepository) CountUserFiles(ctx context.Context, userID uuid.UUID, category string) (int64, error) {
+	start := time.Now()
+	count, err := r.db.CountUserFiles(ctx, db.CountUserFilesParams{
+		UserID:   userID,
+		Category: categoryFilter(category),
+	})
+	duration := time.Since(start)
+
+	if err != nil {
+		appErr := errors.HandleDBError(err, "file")
+		appErr.Log(r.logger)
+		return 0, appErr
+	}
+
+	r.logger.Debugf("Counted %d files for user ID: %s in %v", count, userID, duration)
+	return count, nil
+}
+
+func categoryFilter(category string) *string {
+	if category == "" {
+		return nil
+	}
+	return &category
+}
@@ -0,0 +1,176 @@
+package repository
+
+import (
+	"context"
+	stderrors "errors"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	db "github.com/0xsj/mios.io/db/sqlc"
+	"github.com/0xsj/mios.io/pkg/metrics"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+)
+
+// InstrumentedDB wraps the connection every repository queries through and
+// records each statement with RecordDBQuery, labelled by its SQL verb and
+// the table it targets
+type InstrumentedDB struct {
+	base    db.DBTX
+	metrics *metrics.Metrics
+	labels  sync.Map // sql -> queryLabels
+}
+
+func NewInstrumentedDB(base db.DBTX, metrics *metrics.Metrics) db.DBTX {
+	return &InstrumentedDB{
+		base:    base,
+		metrics: metrics,
+	}
+}
+
+func (d *InstrumentedDB) Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error) {
+	start := time.Now()
+	tag, err := d.base.Exec(ctx, sql, args...)
+	d.record(sql, start, err)
+	return tag, err
+}
+
+// Query records once the rows are closed, since errors while reading them
+// only surface then
+func (d *InstrumentedDB) Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
+	start := time.Now()
+	rows, err := d.base.Query(ctx, sql, args...)
+	if err != nil {
+		d.record(sql, start, err)
+		return nil, err
+	}
+	return &instrumentedRows{Rows: rows, done: func(err error) { d.record(sql, start, err) }}, nil
+}
+
+// QueryRow records once the row is scanned, which is when pgx reports errors
+func (d *InstrumentedDB) QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row {
+	start := time.Now()
+	row := d.base.QueryRow(ctx, sql, args...)
+	return &instrumentedRow{row: row, done: func(err error) { d.record(sql, start, err) }}
+}
+
+func (d *InstrumentedDB) record(sql string, start time.Time, err error) {
+	labels := d.labelsFor(sql)
+	// No rows is an expected outcome rather than a failed query
+	if stderrors.Is(err, pgx.ErrNoRows) {
+		err = nil
+	}
+	d.metrics.RecordDBQuery(labels.operation, labels.table, time.Since(start), err)
+}
+
+type queryLabels struct {
+	operation string
+	table     string
+}
+
+var (
+	sqlComment   = regexp.MustCompile(`(?m)^\s*--.*$`)
+	sqlCTEs      = regexp.MustCompile(`(?i)^with\s+(recursive\s+)?(\w+\s+as\s+(not\s+)?(materialized\s+)?\(\)\s*,?\s*)+`)
+	sqlTableRefs = map[string]*regexp.Regexp{
+		"INSERT": regexp.MustCompile(`(?i)\binto\s+(\w+)`),
+		"UPDATE": regexp.MustCompile(`(?i)^update\s+(\w+)`),
+		"DELETE": regexp.MustCompile(`(?i)\bfrom\s+(\w+)`),
+		"SELECT": regexp.MustCompile(`(?i)\bfrom\s+(\w+)`),
+	}
+)
+
+// labelsFor parses the verb and table out of a statement. The queries are
+// the fixed set sqlc generates, so each is parsed once.
+func (d *InstrumentedDB) labelsFor(sql string) queryLabels {
+	if cached, ok := d.labels.Load(sql); ok {
+		return cached.(queryLabels)
+	}
+
+	labels := queryLabels{operation: "OTHER", table: "unknown"}
+	// Subqueries, CTE bodies and calls like EXTRACT(... FROM col) are dropped
+	// so only the outermost statement is matched, unless all it does is wrap
+	// a subquery as in SELECT EXISTS(...)
+	full := strings.TrimSpace(sqlComment.ReplaceAllString(sql, ""))
+	statement := sqlCTEs.ReplaceAllString(topLevel(full), "")
+	if fields := strings.Fields(statement); len(fields) > 0 {
+		verb := strings.ToUpper(fields[0])
+		if ref, ok := sqlTableRefs[verb]; ok {
+			labels.operation = verb
+			match := ref.FindStringSubmatch(statement)
+			if match == nil {
+				match = ref.FindStringSubmatch(full)
+			}
+			if match != nil {
+				labels.table = strings.ToLower(match[1])
+			}
+		}
+	}
+
+	d.labels.Store(sql, labels)
+	return labels
+}
+
+// topLevel removes everything inside parentheses from a statement
+func topLevel(sql string) string {
+	var b strings.Builder
+	depth := 0
+	for _, r := range sql {
+		switch {
+		case r == '(':
+			if depth == 0 {
+				b.WriteRune(r)
+			}
+			depth++
+		case r == ')':
+			if depth > 0 {
+				depth--
+			}
+			if depth == 0 {
+				b.WriteRune(r)
+			}
+		case depth == 0:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+type instrumentedRows struct {
+	pgx.Rows
+	done     func(err error)
+	finished bool
+}
+
+func (r *instrumentedRows) Next() bool {
+	if r.Rows.Next() {
+		return true
+	}
+	r.finish()
+	return false
+}
+
+func (r *instrumentedRows) Close() {
+	r.Rows.Close()
+	r.finish()
+}
+
+func (r *instrumentedRows) finish() {
+	if r.finished {
+		return
+	}
+	r.finished = true
+	r.done(r.Rows.Err())
+}
+
+type instrumentedRow struct {
+	row  pgx.Row
+	done func(err error)
+}
+
+func (r *instrumentedRow) Scan(dest ...interface{}) error {
+	err := r.row.Scan(dest...)
+	r.done(err)
+	return err
+}
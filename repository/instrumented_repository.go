@@ -58,6 +58,13 @@ func (r *InstrumentedUserRepository) GetUserByHandle(ctx context.Context, handle
 	return user, err
 }
 
+func (r *InstrumentedUserRepository) GetUserByCustomDomain(ctx context.Context, domain string) (*db.User, error) {
+	start := time.Now()
+	user, err := r.base.GetUserByCustomDomain(ctx, domain)
+	r.metrics.RecordDBQuery("SELECT", "users", time.Since(start), err)
+	return user, err
+}
+
 func (r *InstrumentedUserRepository) GetUserByEmail(ctx context.Context, email string) (*db.User, error) {
 	start := time.Now()
 	user, err := r.base.GetUserByEmail(ctx, email)
@@ -65,6 +72,27 @@ func (r *InstrumentedUserRepository) GetUserByEmail(ctx context.Context, email s
 	return user, err
 }
 
+func (r *InstrumentedUserRepository) GetUsersByIDs(ctx context.Context, userIDs []uuid.UUID) ([]*db.User, error) {
+	start := time.Now()
+	users, err := r.base.GetUsersByIDs(ctx, userIDs)
+	r.metrics.RecordDBQuery("SELECT", "users", time.Since(start), err)
+	return users, err
+}
+
+func (r *InstrumentedUserRepository) GetUserIncludingDeleted(ctx context.Context, userID uuid.UUID) (*db.User, error) {
+	start := time.Now()
+	user, err := r.base.GetUserIncludingDeleted(ctx, userID)
+	r.metrics.RecordDBQuery("SELECT", "users", time.Since(start), err)
+	return user, err
+}
+
+func (r *InstrumentedUserRepository) GetUserByEmailIncludingDeleted(ctx context.Context, email string) (*db.User, error) {
+	start := time.Now()
+	user, err := r.base.GetUserByEmailIncludingDeleted(ctx, email)
+	r.metrics.RecordDBQuery("SELECT", "users", time.Since(start), err)
+	return user, err
+}
+
 func (r *InstrumentedUserRepository) UpdateUser(ctx context.Context, arg UpdateUserParams) error {
 	start := time.Now()
 	err := r.base.UpdateUser(ctx, arg)
@@ -114,6 +142,118 @@ func (r *InstrumentedUserRepository) UpdateOnboardedStatus(ctx context.Context,
 	return err
 }
 
+func (r *InstrumentedUserRepository) UpdateAnalyticsEnabled(ctx context.Context, userID uuid.UUID, enabled bool) error {
+	start := time.Now()
+	err := r.base.UpdateAnalyticsEnabled(ctx, userID, enabled)
+	r.metrics.RecordDBQuery("UPDATE", "users", time.Since(start), err)
+	return err
+}
+
+func (r *InstrumentedUserRepository) UpdateLocale(ctx context.Context, userID uuid.UUID, locale *string) error {
+	start := time.Now()
+	err := r.base.UpdateLocale(ctx, userID, locale)
+	r.metrics.RecordDBQuery("UPDATE", "users", time.Since(start), err)
+	return err
+}
+
+func (r *InstrumentedUserRepository) UpdateVisibility(ctx context.Context, userID uuid.UUID, visibility string) error {
+	start := time.Now()
+	err := r.base.UpdateVisibility(ctx, userID, visibility)
+	r.metrics.RecordDBQuery("UPDATE", "users", time.Since(start), err)
+	return err
+}
+
+func (r *InstrumentedUserRepository) UpdateContentApproval(ctx context.Context, userID uuid.UUID, requiresApproval bool) error {
+	start := time.Now()
+	err := r.base.UpdateContentApproval(ctx, userID, requiresApproval)
+	r.metrics.RecordDBQuery("UPDATE", "users", time.Since(start), err)
+	return err
+}
+
+func (r *InstrumentedUserRepository) UpdateTemplateStatus(ctx context.Context, userID uuid.UUID, isTemplate bool) error {
+	start := time.Now()
+	err := r.base.UpdateTemplateStatus(ctx, userID, isTemplate)
+	r.metrics.RecordDBQuery("UPDATE", "users", time.Since(start), err)
+	return err
+}
+
+func (r *InstrumentedUserRepository) ListTemplateUsers(ctx context.Context) ([]*db.User, error) {
+	start := time.Now()
+	users, err := r.base.ListTemplateUsers(ctx)
+	r.metrics.RecordDBQuery("SELECT", "users", time.Since(start), err)
+	return users, err
+}
+
+func (r *InstrumentedUserRepository) ListUsers(ctx context.Context, filter UserFilter, limit, offset int) ([]*db.User, error) {
+	start := time.Now()
+	users, err := r.base.ListUsers(ctx, filter, limit, offset)
+	r.metrics.RecordDBQuery("SELECT", "users", time.Since(start), err)
+	return users, err
+}
+
+func (r *InstrumentedUserRepository) CountUsers(ctx context.Context, filter UserFilter) (int64, error) {
+	start := time.Now()
+	count, err := r.base.CountUsers(ctx, filter)
+	r.metrics.RecordDBQuery("SELECT", "users", time.Since(start), err)
+	return count, err
+}
+
+func (r *InstrumentedUserRepository) RecordHandleChange(ctx context.Context, userID uuid.UUID, oldHandle string) error {
+	start := time.Now()
+	err := r.base.RecordHandleChange(ctx, userID, oldHandle)
+	r.metrics.RecordDBQuery("INSERT", "handle_history", time.Since(start), err)
+	return err
+}
+
+func (r *InstrumentedUserRepository) GetUserIDByPreviousHandle(ctx context.Context, handle string) (uuid.UUID, error) {
+	start := time.Now()
+	userID, err := r.base.GetUserIDByPreviousHandle(ctx, handle)
+	r.metrics.RecordDBQuery("SELECT", "handle_history", time.Since(start), err)
+	return userID, err
+}
+
+func (r *InstrumentedUserRepository) ReleaseHandleForTransfer(ctx context.Context, arg ReleaseHandleParams) error {
+	start := time.Now()
+	err := r.base.ReleaseHandleForTransfer(ctx, arg)
+	r.metrics.RecordDBQuery("UPDATE", "users", time.Since(start), err)
+	return err
+}
+
+func (r *InstrumentedUserRepository) ClaimHandleTransfer(ctx context.Context, userID uuid.UUID, handle string) error {
+	start := time.Now()
+	err := r.base.ClaimHandleTransfer(ctx, userID, handle)
+	r.metrics.RecordDBQuery("UPDATE", "users", time.Since(start), err)
+	return err
+}
+
+func (r *InstrumentedUserRepository) IsHandleReserved(ctx context.Context, handle string) (bool, error) {
+	start := time.Now()
+	reserved, err := r.base.IsHandleReserved(ctx, handle)
+	r.metrics.RecordDBQuery("SELECT", "handle_transfers", time.Since(start), err)
+	return reserved, err
+}
+
+func (r *InstrumentedUserRepository) IsHandleTaken(ctx context.Context, handle string) (bool, error) {
+	start := time.Now()
+	taken, err := r.base.IsHandleTaken(ctx, handle)
+	r.metrics.RecordDBQuery("SELECT", "users", time.Since(start), err)
+	return taken, err
+}
+
+func (r *InstrumentedUserRepository) SetCustomDomainVerification(ctx context.Context, userID uuid.UUID, domain, token string) error {
+	start := time.Now()
+	err := r.base.SetCustomDomainVerification(ctx, userID, domain, token)
+	r.metrics.RecordDBQuery("UPDATE", "users", time.Since(start), err)
+	return err
+}
+
+func (r *InstrumentedUserRepository) MarkCustomDomainVerified(ctx context.Context, userID uuid.UUID, domain, token string) error {
+	start := time.Now()
+	err := r.base.MarkCustomDomainVerified(ctx, userID, domain, token)
+	r.metrics.RecordDBQuery("UPDATE", "users", time.Since(start), err)
+	return err
+}
+
 func (r *InstrumentedUserRepository) DeleteUser(ctx context.Context, userID uuid.UUID) error {
 	start := time.Now()
 	err := r.base.DeleteUser(ctx, userID)
@@ -124,4 +264,35 @@ func (r *InstrumentedUserRepository) DeleteUser(ctx context.Context, userID uuid
 	}
 	
 	return err
-}
\ No newline at end of file
+}
+
+func (r *InstrumentedUserRepository) SoftDeleteUser(ctx context.Context, userID uuid.UUID) (time.Time, error) {
+	start := time.Now()
+	deletedAt, err := r.base.SoftDeleteUser(ctx, userID)
+	r.metrics.RecordDBQuery("UPDATE", "users", time.Since(start), err)
+
+	if err == nil {
+		r.metrics.UsersTotal.Dec()
+	}
+
+	return deletedAt, err
+}
+
+func (r *InstrumentedUserRepository) RestoreUser(ctx context.Context, userID uuid.UUID) error {
+	start := time.Now()
+	err := r.base.RestoreUser(ctx, userID)
+	r.metrics.RecordDBQuery("UPDATE", "users", time.Since(start), err)
+
+	if err == nil {
+		r.metrics.UsersTotal.Inc()
+	}
+
+	return err
+}
+
+func (r *InstrumentedUserRepository) PurgeDeletedUsers(ctx context.Context, deletedBefore time.Time, limit int) (int64, error) {
+	start := time.Now()
+	rows, err := r.base.PurgeDeletedUsers(ctx, deletedBefore, limit)
+	r.metrics.RecordDBQuery("DELETE", "users", time.Since(start), err)
+	return rows, err
+}
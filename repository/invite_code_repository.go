@@ -0,0 +1,155 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	db "github.com/0xsj/mios.io/db/sqlc"
+	"github.com/0xsj/mios.io/log"
+	"github.com/0xsj/mios.io/pkg/errors"
+	"github.com/google/uuid"
+)
+
+type InviteCodeRepository interface {
+	CreateInviteCode(ctx context.Context, params CreateInviteCodeParams) (*db.InviteCode, error)
+	GetInviteCode(ctx context.Context, code string) (*db.InviteCode, error)
+	ListInviteCodes(ctx context.Context, limit, offset int) ([]*db.InviteCode, error)
+	RedeemInviteCode(ctx context.Context, code string) (*db.InviteCode, error)
+	ReleaseInviteCode(ctx context.Context, code string) error
+	RecordRedemption(ctx context.Context, code string, userID uuid.UUID) error
+}
+
+type CreateInviteCodeParams struct {
+	Code      string
+	MaxUses   int
+	ExpiresAt *time.Time
+	CreatedBy *uuid.UUID
+}
+
+type SQLInviteCodeRepository struct {
+	db     *db.Queries
+	logger log.Logger
+}
+
+func NewInviteCodeRepository(db *db.Queries, logger log.Logger) InviteCodeRepository {
+	return &SQLInviteCodeRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *SQLInviteCodeRepository) CreateInviteCode(ctx context.Context, params CreateInviteCodeParams) (*db.InviteCode, error) {
+	r.logger.Info("Creating invite code")
+
+	start := time.Now()
+	invite, err := r.db.CreateInviteCode(ctx, db.CreateInviteCodeParams{
+		Code:      params.Code,
+		MaxUses:   int32(params.MaxUses),
+		ExpiresAt: params.ExpiresAt,
+		CreatedBy: params.CreatedBy,
+	})
+	duration := time.Since(start)
+
+	if err != nil {
+		appErr := errors.HandleDBError(err, "invite code")
+		appErr.Log(r.logger)
+		return nil, appErr
+	}
+
+	r.logger.Infof("Invite code created with %d max uses in %v", invite.MaxUses, duration)
+	return invite, nil
+}
+
+func (r *SQLInviteCodeRepository) GetInviteCode(ctx context.Context, code string) (*db.InviteCode, error) {
+	r.logger.Debug("Getting invite code")
+
+	start := time.Now()
+	invite, err := r.db.GetInviteCode(ctx, code)
+	duration := time.Since(start)
+
+	if err != nil {
+		appErr := errors.HandleDBError(err, "invite code")
+		appErr.Log(r.logger)
+		return nil, appErr
+	}
+
+	r.logger.Debugf("Retrieved invite code in %v", duration)
+	return invite, nil
+}
+
+func (r *SQLInviteCodeRepository) ListInviteCodes(ctx context.Context, limit, offset int) ([]*db.InviteCode, error) {
+	r.logger.Debugf("Listing invite codes with limit: %d, offset: %d", limit, offset)
+
+	start := time.Now()
+	invites, err := r.db.ListInviteCodes(ctx, db.ListInviteCodesParams{
+		Limit:  int32(limit),
+		Offset: int32(offset),
+	})
+	duration := time.Since(start)
+
+	if err != nil {
+		appErr := errors.HandleDBError(err, "invite code")
+		appErr.Log(r.logger)
+		return nil, appErr
+	}
+
+	r.logger.Debugf("Retrieved %d invite codes in %v", len(invites), duration)
+	return invites, nil
+}
+
+// RedeemInviteCode consumes one use of the code in a single statement so
+// concurrent registrations cannot exceed max_uses. It returns a not found
+// error when the code is unknown, expired or exhausted.
+func (r *SQLInviteCodeRepository) RedeemInviteCode(ctx context.Context, code string) (*db.InviteCode, error) {
+	r.logger.Info("Redeeming invite code")
+
+	start := time.Now()
+	invite, err := r.db.RedeemInviteCode(ctx, code)
+	duration := time.Since(start)
+
+	if err != nil {
+		appErr := errors.HandleDBError(err, "redeemable invite code")
+		appErr.Log(r.logger)
+		return nil, appErr
+	}
+
+	r.logger.Infof("Invite code redeemed (%d/%d uses) in %v", invite.UsedCount, invite.MaxUses, duration)
+	return invite, nil
+}
+
+func (r *SQLInviteCodeRepository) ReleaseInviteCode(ctx context.Context, code string) error {
+	r.logger.Info("Releasing invite code use")
+
+	start := time.Now()
+	err := r.db.ReleaseInviteCode(ctx, code)
+	duration := time.Since(start)
+
+	if err != nil {
+		appErr := errors.HandleDBError(err, "invite code")
+		appErr.Log(r.logger)
+		return appErr
+	}
+
+	r.logger.Infof("Invite code use released in %v", duration)
+	return nil
+}
+
+func (r *SQLInviteCodeRepository) RecordRedemption(ctx context.Context, code string, userID uuid.UUID) error {
+	r.logger.Infof("Recording invite code redemption for user ID: %s", userID)
+
+	start := time.Now()
+	err := r.db.RecordInviteRedemption(ctx, db.RecordInviteRedemptionParams{
+		Code:   code,
+		UserID: userID,
+	})
+	duration := time.Since(start)
+
+	if err != nil {
+		appErr := errors.HandleDBError(err, "invite code redemption")
+		appErr.Log(r.logger)
+		return appErr
+	}
+
+	r.logger.Infof("Recorded invite code redemption for user ID: %s in %v", userID, duration)
+	return nil
+}
@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	db "github.com/0xsj/mios.io/db/sqlc"
+	"github.com/0xsj/mios.io/log"
+	"github.com/0xsj/mios.io/pkg/errors"
+	"github.com/google/uuid"
+)
+
+type LinkHealthRepository interface {
+	ListLinksForHealthCheck(ctx context.Context, checkedBefore time.Time, limit int) ([]*db.ListLinksForHealthCheckRow, error)
+	RecordLinkCheck(ctx context.Context, itemID uuid.UUID, failed bool, status *int32) (*db.ContentLinkHealth, error)
+	DeactivateDeadLink(ctx context.Context, itemID uuid.UUID) (bool, error)
+	SetAutoDeactivate(ctx context.Context, itemID uuid.UUID, enabled bool) error
+}
+
+type SQLLinkHealthRepository struct {
+	db     *db.Queries
+	logger log.Logger
+}
+
+func NewLinkHealthRepository(db *db.Queries, logger log.Logger) LinkHealthRepository {
+	return &SQLLinkHealthRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *SQLLinkHealthRepository) ListLinksForHealthCheck(ctx context.Context, checkedBefore time.Time, limit int) ([]*db.ListLinksForHealthCheckRow, error) {
+	r.logger.Debugf("Listing links last checked before %v", checkedBefore)
+
+	start := time.Now()
+	links, err := r.db.ListLinksForHealthCheck(ctx, db.ListLinksForHealthCheckParams{
+		CheckedBefore: checkedBefore,
+		MaxItems:      int32(limit),
+	})
+	duration := time.Since(start)
+
+	if err != nil {
+		appErr := errors.HandleDBError(err, "link health")
+		appErr.Log(r.logger)
+		return nil, appErr
+	}
+
+	r.logger.Debugf("Found %d links due for a health check in %v", len(links), duration)
+	return links, nil
+}
+
+// RecordLinkCheck stores the outcome of one check. A failure increments the
+// item's consecutive failure count and a success resets it.
+func (r *SQLLinkHealthRepository) RecordLinkCheck(ctx context.Context, itemID uuid.UUID, failed bool, status *int32) (*db.ContentLinkHealth, error) {
+	r.logger.Debugf("Recording link check for item ID: %s (failed: %v)", itemID, failed)
+
+	start := time.Now()
+	health, err := r.db.RecordLinkCheck(ctx, db.RecordLinkCheckParams{
+		ItemID:     itemID,
+		Failed:     failed,
+		LastStatus: status,
+	})
+	duration := time.Since(start)
+
+	if err != nil {
+		appErr := errors.HandleDBError(err, "link health")
+		appErr.Log(r.logger)
+		return nil, appErr
+	}
+
+	r.logger.Debugf("Recorded link check for item ID: %s in %v", itemID, duration)
+	return health, nil
+}
+
+// DeactivateDeadLink hides the item from the profile. It reports false when
+// the item was already inactive.
+func (r *SQLLinkHealthRepository) DeactivateDeadLink(ctx context.Context, itemID uuid.UUID) (bool, error) {
+	r.logger.Infof("Deactivating dead link item ID: %s", itemID)
+
+	start := time.Now()
+	rows, err := r.db.DeactivateDeadLink(ctx, itemID)
+	duration := time.Since(start)
+
+	if err != nil {
+		appErr := errors.HandleDBError(err, "content item")
+		appErr.Log(r.logger)
+		return false, appErr
+	}
+
+	r.logger.Infof("Deactivated %d dead link items in %v", rows, duration)
+	return rows > 0, nil
+}
+
+func (r *SQLLinkHealthRepository) SetAutoDeactivate(ctx context.Context, itemID uuid.UUID, enabled bool) error {
+	r.logger.Infof("Setting auto-deactivation to %v for item ID: %s", enabled, itemID)
+
+	start := time.Now()
+	err := r.db.SetLinkAutoDeactivate(ctx, db.SetLinkAutoDeactivateParams{
+		ItemID:         itemID,
+		AutoDeactivate: enabled,
+	})
+	duration := time.Since(start)
+
+	if err != nil {
+		appErr := errors.HandleDBError(err, "link health")
+		appErr.Log(r.logger)
+		return appErr
+	}
+
+	r.logger.Infof("Auto-deactivation updated for item ID: %s in %v", itemID, duration)
+	return nil
+}
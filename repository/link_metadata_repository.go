@@ -16,7 +16,12 @@ type LinkMetadataRepository interface {
 	GetLinkMetadataByURL(ctx context.Context, url string) (*db.LinkMetadatum, error)
 	GetLinkMetadataByDomain(ctx context.Context, domain string) ([]*db.LinkMetadatum, error)
 	UpdateLinkMetadata(ctx context.Context, params UpdateLinkMetadataParams) (*db.LinkMetadatum, error)
+	GetLinkMetadataFreshness(ctx context.Context, urls []string) ([]LinkMetadataFreshness, error)
 	DeleteLinkMetadata(ctx context.Context, id uuid.UUID) error
+	ListPlatforms(ctx context.Context) ([]*db.Platform, error)
+	CreatePlatform(ctx context.Context, params CreatePlatformParams) (*db.Platform, error)
+	UpdatePlatform(ctx context.Context, params UpdatePlatformParams) (*db.Platform, error)
+	DeletePlatform(ctx context.Context, domain string) error
 }
 
 type CreateLinkMetadataParams struct {
@@ -30,6 +35,9 @@ type CreateLinkMetadataParams struct {
 	PlatformType  *string
 	PlatformColor *string
 	IsVerified    *bool
+	ImageSource   *string
+	FetchFailed   bool
+	CanonicalURL  *string
 }
 
 type UpdateLinkMetadataParams struct {
@@ -42,6 +50,45 @@ type UpdateLinkMetadataParams struct {
 	PlatformType  *string
 	PlatformColor *string
 	IsVerified    *bool
+	ImageSource   *string
+	FetchFailed   bool
+	CanonicalURL  *string
+}
+
+// LinkMetadataFreshness is when a URL's metadata was last stored and whether
+// fetching its page failed that time
+type LinkMetadataFreshness struct {
+	URL         string
+	UpdatedAt   time.Time
+	FetchFailed bool
+}
+
+type CreatePlatformParams struct {
+	Domain         string
+	Name           string
+	Type           string
+	Color          string
+	Icon           string
+	URLTemplate    string
+	ScrapeStrategy string
+	OEmbedEndpoint string
+	UserAgent      string
+	QueryKeys      []string
+}
+
+// UpdatePlatformParams changes the fields of a platform that are not nil.
+// An empty, non-nil QueryKeys clears the platform's query keys.
+type UpdatePlatformParams struct {
+	Domain         string
+	Name           *string
+	Type           *string
+	Color          *string
+	Icon           *string
+	URLTemplate    *string
+	ScrapeStrategy *string
+	OEmbedEndpoint *string
+	UserAgent      *string
+	QueryKeys      []string
 }
 
 type SQLCLinkMetadataRepository struct {
@@ -70,6 +117,9 @@ func (r *SQLCLinkMetadataRepository) CreateLinkMetadata(ctx context.Context, par
 		PlatformType:  params.PlatformType,
 		PlatformColor: params.PlatformColor,
 		IsVerified:    params.IsVerified,
+		ImageSource:   params.ImageSource,
+		FetchFailed:   params.FetchFailed,
+		CanonicalUrl:  params.CanonicalURL,
 	}
 
 	start := time.Now()
@@ -133,6 +183,9 @@ func (r *SQLCLinkMetadataRepository) UpdateLinkMetadata(ctx context.Context, par
 		PlatformType:  params.PlatformType,
 		PlatformColor: params.PlatformColor,
 		IsVerified:    params.IsVerified,
+		ImageSource:   params.ImageSource,
+		FetchFailed:   params.FetchFailed,
+		CanonicalUrl:  params.CanonicalURL,
 	}
 
 	start := time.Now()
@@ -149,6 +202,32 @@ func (r *SQLCLinkMetadataRepository) UpdateLinkMetadata(ctx context.Context, par
 	return updatedMetadata, nil
 }
 
+func (r *SQLCLinkMetadataRepository) GetLinkMetadataFreshness(ctx context.Context, urls []string) ([]LinkMetadataFreshness, error) {
+	r.logger.Debugf("Getting link metadata freshness for %d URLs", len(urls))
+
+	start := time.Now()
+	rows, err := r.db.GetLinkMetadataFreshness(ctx, urls)
+	duration := time.Since(start)
+
+	if err != nil {
+		appErr := errors.HandleDBError(err, "link metadata freshness")
+		appErr.Log(r.logger)
+		return nil, appErr
+	}
+
+	result := make([]LinkMetadataFreshness, len(rows))
+	for i, row := range rows {
+		result[i] = LinkMetadataFreshness{
+			URL:         row.Url,
+			UpdatedAt:   row.UpdatedAt,
+			FetchFailed: row.FetchFailed,
+		}
+	}
+
+	r.logger.Debugf("Found stored metadata for %d of %d URLs in %v", len(result), len(urls), duration)
+	return result, nil
+}
+
 func (r *SQLCLinkMetadataRepository) DeleteLinkMetadata(ctx context.Context, id uuid.UUID) error {
 	r.logger.Infof("Deleting link metadata with ID: %s", id)
 
@@ -165,3 +244,97 @@ func (r *SQLCLinkMetadataRepository) DeleteLinkMetadata(ctx context.Context, id
 	r.logger.Infof("Link metadata deleted successfully with ID: %s in %v", id, duration)
 	return nil
 }
+
+func (r *SQLCLinkMetadataRepository) ListPlatforms(ctx context.Context) ([]*db.Platform, error) {
+	r.logger.Debug("Listing platforms")
+
+	start := time.Now()
+	platforms, err := r.db.ListPlatforms(ctx)
+	duration := time.Since(start)
+
+	if err != nil {
+		appErr := errors.HandleDBError(err, "platforms")
+		appErr.Log(r.logger)
+		return nil, appErr
+	}
+
+	r.logger.Debugf("Retrieved %d platforms in %v", len(platforms), duration)
+	return platforms, nil
+}
+
+func (r *SQLCLinkMetadataRepository) CreatePlatform(ctx context.Context, params CreatePlatformParams) (*db.Platform, error) {
+	r.logger.Infof("Creating platform: %s", params.Domain)
+
+	start := time.Now()
+	platform, err := r.db.CreatePlatform(ctx, db.CreatePlatformParams{
+		Domain:         params.Domain,
+		Name:           params.Name,
+		Type:           params.Type,
+		Color:          params.Color,
+		Icon:           params.Icon,
+		UrlTemplate:    params.URLTemplate,
+		ScrapeStrategy: params.ScrapeStrategy,
+		OembedEndpoint: params.OEmbedEndpoint,
+		UserAgent:      params.UserAgent,
+		QueryKeys:      params.QueryKeys,
+	})
+	duration := time.Since(start)
+
+	if err != nil {
+		appErr := errors.HandleDBError(err, "platform")
+		appErr.Log(r.logger)
+		return nil, appErr
+	}
+
+	r.logger.Infof("Platform %s created in %v", params.Domain, duration)
+	return platform, nil
+}
+
+func (r *SQLCLinkMetadataRepository) UpdatePlatform(ctx context.Context, params UpdatePlatformParams) (*db.Platform, error) {
+	r.logger.Infof("Updating platform: %s", params.Domain)
+
+	start := time.Now()
+	platform, err := r.db.UpdatePlatform(ctx, db.UpdatePlatformParams{
+		Domain:         params.Domain,
+		Name:           params.Name,
+		Type:           params.Type,
+		Color:          params.Color,
+		Icon:           params.Icon,
+		UrlTemplate:    params.URLTemplate,
+		ScrapeStrategy: params.ScrapeStrategy,
+		OembedEndpoint: params.OEmbedEndpoint,
+		UserAgent:      params.UserAgent,
+		QueryKeys:      params.QueryKeys,
+	})
+	duration := time.Since(start)
+
+	if err != nil {
+		appErr := errors.HandleDBError(err, "platform")
+		appErr.Log(r.logger)
+		return nil, appErr
+	}
+
+	r.logger.Infof("Platform %s updated in %v", params.Domain, duration)
+	return platform, nil
+}
+
+func (r *SQLCLinkMetadataRepository) DeletePlatform(ctx context.Context, domain string) error {
+	r.logger.Infof("Deleting platform: %s", domain)
+
+	start := time.Now()
+	rows, err := r.db.DeletePlatform(ctx, domain)
+	duration := time.Since(start)
+
+	if err != nil {
+		appErr := errors.HandleDBError(err, "platform")
+		appErr.Log(r.logger)
+		return appErr
+	}
+
+	if rows == 0 {
+		return errors.NewNotFoundError("Platform not found", nil)
+	}
+
+	r.logger.Infof("Platform %s deleted in %v", domain, duration)
+	return nil
+}
@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	db "github.com/0xsj/mios.io/db/sqlc"
+	"github.com/0xsj/mios.io/log"
+	"github.com/0xsj/mios.io/pkg/errors"
+	"github.com/0xsj/mios.io/pkg/ptr"
+	"github.com/google/uuid"
+)
+
+type OAuthRepository interface {
+	GetAccount(ctx context.Context, provider, providerUserID string) (*db.OauthAccount, error)
+	LinkAccount(ctx context.Context, arg LinkOAuthAccountParams) (*db.OauthAccount, error)
+	ListUserProviders(ctx context.Context, userID uuid.UUID) ([]string, error)
+}
+
+type LinkOAuthAccountParams struct {
+	UserID         uuid.UUID
+	Provider       string
+	ProviderUserID string
+	Email          string
+	Name           string
+}
+
+type SQLOAuthRepository struct {
+	db     *db.Queries
+	logger log.Logger
+}
+
+func NewOAuthRepository(db *db.Queries, logger log.Logger) OAuthRepository {
+	return &SQLOAuthRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *SQLOAuthRepository) GetAccount(ctx context.Context, provider, providerUserID string) (*db.OauthAccount, error) {
+	r.logger.Debugf("Getting %s account %s", provider, providerUserID)
+
+	start := time.Now()
+	account, err := r.db.GetOAuthAccount(ctx, db.GetOAuthAccountParams{
+		Provider:       provider,
+		ProviderUserID: providerUserID,
+	})
+	duration := time.Since(start)
+
+	if err != nil {
+		appErr := errors.HandleDBError(err, "oauth account")
+		appErr.Log(r.logger)
+		return nil, appErr
+	}
+
+	r.logger.Debugf("Retrieved %s account linked to user ID: %s in %v", provider, account.UserID, duration)
+	return account, nil
+}
+
+// LinkAccount connects a provider identity to a user. Each provider identity
+// can only be linked once, so a second link reports a conflict.
+func (r *SQLOAuthRepository) LinkAccount(ctx context.Context, arg LinkOAuthAccountParams) (*db.OauthAccount, error) {
+	r.logger.Infof("Linking %s account %s to user ID: %s", arg.Provider, arg.ProviderUserID, arg.UserID)
+
+	start := time.Now()
+	account, err := r.db.CreateOAuthAccount(ctx, db.CreateOAuthAccountParams{
+		UserID:         arg.UserID,
+		Provider:       arg.Provider,
+		ProviderUserID: arg.ProviderUserID,
+		Email:          ptr.String(arg.Email),
+		Name:           ptr.String(arg.Name),
+	})
+	duration := time.Since(start)
+
+	if err != nil {
+		appErr := errors.HandleDBError(err, "oauth account")
+		appErr.Log(r.logger)
+		return nil, appErr
+	}
+
+	r.logger.Infof("Linked %s account to user ID: %s in %v", arg.Provider, arg.UserID, duration)
+	return account, nil
+}
+
+func (r *SQLOAuthRepository) ListUserProviders(ctx context.Context, userID uuid.UUID) ([]string, error) {
+	r.logger.Debugf("Listing OAuth providers for user ID: %s", userID)
+
+	start := time.Now()
+	providers, err := r.db.ListUserOAuthProviders(ctx, userID)
+	duration := time.Since(start)
+
+	if err != nil {
+		appErr := errors.HandleDBError(err, "oauth account")
+		appErr.Log(r.logger)
+		return nil, appErr
+	}
+
+	r.logger.Debugf("User ID: %s has %d linked providers (%v)", userID, len(providers), duration)
+	return providers, nil
+}
@@ -0,0 +1,167 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	db "github.com/0xsj/mios.io/db/sqlc"
+	"github.com/0xsj/mios.io/log"
+	"github.com/0xsj/mios.io/pkg/errors"
+	"github.com/google/uuid"
+)
+
+type ReportSubscriptionRepository interface {
+	CreateSubscription(ctx context.Context, params CreateReportSubscriptionParams) (*db.ReportSubscription, error)
+	ListUserSubscriptions(ctx context.Context, userID uuid.UUID) ([]*db.ReportSubscription, error)
+	DeleteSubscription(ctx context.Context, userID, subscriptionID uuid.UUID) error
+	DeleteSubscriptionByToken(ctx context.Context, token string) error
+	ListDueSubscriptions(ctx context.Context, dueBefore time.Time, limit int) ([]*db.ListDueReportSubscriptionsRow, error)
+	ClaimSubscription(ctx context.Context, subscriptionID uuid.UUID, dueAt, nextSendAt time.Time) (bool, error)
+}
+
+type CreateReportSubscriptionParams struct {
+	UserID           uuid.UUID
+	Frequency        string
+	Metrics          []string
+	UnsubscribeToken string
+	NextSendAt       time.Time
+}
+
+type SQLReportSubscriptionRepository struct {
+	db     *db.Queries
+	logger log.Logger
+}
+
+func NewReportSubscriptionRepository(db *db.Queries, logger log.Logger) ReportSubscriptionRepository {
+	return &SQLReportSubscriptionRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *SQLReportSubscriptionRepository) CreateSubscription(ctx context.Context, params CreateReportSubscriptionParams) (*db.ReportSubscription, error) {
+	r.logger.Infof("Creating %s report subscription for user ID: %s", params.Frequency, params.UserID)
+
+	start := time.Now()
+	subscription, err := r.db.CreateReportSubscription(ctx, db.CreateReportSubscriptionParams{
+		UserID:           params.UserID,
+		Frequency:        params.Frequency,
+		Metrics:          params.Metrics,
+		UnsubscribeToken: params.UnsubscribeToken,
+		NextSendAt:       params.NextSendAt,
+	})
+	duration := time.Since(start)
+
+	if err != nil {
+		appErr := errors.HandleDBError(err, "report subscription")
+		appErr.Log(r.logger)
+		return nil, appErr
+	}
+
+	r.logger.Infof("Report subscription %s created for user ID: %s in %v", subscription.SubscriptionID, params.UserID, duration)
+	return subscription, nil
+}
+
+func (r *SQLReportSubscriptionRepository) ListUserSubscriptions(ctx context.Context, userID uuid.UUID) ([]*db.ReportSubscription, error) {
+	r.logger.Debugf("Listing report subscriptions for user ID: %s", userID)
+
+	start := time.Now()
+	subscriptions, err := r.db.ListUserReportSubscriptions(ctx, userID)
+	duration := time.Since(start)
+
+	if err != nil {
+		appErr := errors.HandleDBError(err, "report subscription")
+		appErr.Log(r.logger)
+		return nil, appErr
+	}
+
+	r.logger.Debugf("Retrieved %d report subscriptions for user ID: %s in %v", len(subscriptions), userID, duration)
+	return subscriptions, nil
+}
+
+func (r *SQLReportSubscriptionRepository) DeleteSubscription(ctx context.Context, userID, subscriptionID uuid.UUID) error {
+	r.logger.Infof("Deleting report subscription %s for user ID: %s", subscriptionID, userID)
+
+	start := time.Now()
+	rows, err := r.db.DeleteReportSubscription(ctx, db.DeleteReportSubscriptionParams{
+		SubscriptionID: subscriptionID,
+		UserID:         userID,
+	})
+	duration := time.Since(start)
+
+	if err != nil {
+		appErr := errors.HandleDBError(err, "report subscription")
+		appErr.Log(r.logger)
+		return appErr
+	}
+
+	if rows == 0 {
+		return errors.NewNotFoundError("Report subscription not found", nil)
+	}
+
+	r.logger.Infof("Report subscription %s deleted in %v", subscriptionID, duration)
+	return nil
+}
+
+func (r *SQLReportSubscriptionRepository) DeleteSubscriptionByToken(ctx context.Context, token string) error {
+	r.logger.Info("Deleting report subscription by unsubscribe token")
+
+	start := time.Now()
+	rows, err := r.db.DeleteReportSubscriptionByToken(ctx, token)
+	duration := time.Since(start)
+
+	if err != nil {
+		appErr := errors.HandleDBError(err, "report subscription")
+		appErr.Log(r.logger)
+		return appErr
+	}
+
+	if rows == 0 {
+		return errors.NewNotFoundError("Report subscription not found", nil)
+	}
+
+	r.logger.Infof("Report subscription deleted by unsubscribe token in %v", duration)
+	return nil
+}
+
+func (r *SQLReportSubscriptionRepository) ListDueSubscriptions(ctx context.Context, dueBefore time.Time, limit int) ([]*db.ListDueReportSubscriptionsRow, error) {
+	r.logger.Debugf("Listing up to %d report subscriptions due before %v", limit, dueBefore)
+
+	start := time.Now()
+	subscriptions, err := r.db.ListDueReportSubscriptions(ctx, db.ListDueReportSubscriptionsParams{
+		DueBefore: dueBefore,
+		RowLimit:  int32(limit),
+	})
+	duration := time.Since(start)
+
+	if err != nil {
+		appErr := errors.HandleDBError(err, "report subscription")
+		appErr.Log(r.logger)
+		return nil, appErr
+	}
+
+	r.logger.Debugf("Found %d due report subscriptions in %v", len(subscriptions), duration)
+	return subscriptions, nil
+}
+
+// ClaimSubscription moves a due subscription on to nextSendAt and reports
+// whether this caller won it. It returns false when another instance
+// already claimed the same run.
+func (r *SQLReportSubscriptionRepository) ClaimSubscription(ctx context.Context, subscriptionID uuid.UUID, dueAt, nextSendAt time.Time) (bool, error) {
+	start := time.Now()
+	rows, err := r.db.ClaimReportSubscription(ctx, db.ClaimReportSubscriptionParams{
+		NextSendAt:     nextSendAt,
+		SubscriptionID: subscriptionID,
+		DueAt:          dueAt,
+	})
+	duration := time.Since(start)
+
+	if err != nil {
+		appErr := errors.HandleDBError(err, "report subscription")
+		appErr.Log(r.logger)
+		return false, appErr
+	}
+
+	r.logger.Debugf("Claimed report subscription %s (%d rows) in %v", subscriptionID, rows, duration)
+	return rows > 0, nil
+}
@@ -0,0 +1,169 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	db "github.com/0xsj/mios.io/db/sqlc"
+	"github.com/0xsj/mios.io/log"
+	"github.com/0xsj/mios.io/pkg/errors"
+	"github.com/google/uuid"
+)
+
+type SessionRepository interface {
+	CreateSession(ctx context.Context, params CreateSessionParams) (*db.AuthSession, error)
+	GetSession(ctx context.Context, sessionID uuid.UUID) (*db.AuthSession, error)
+	RotateSessionToken(ctx context.Context, sessionID uuid.UUID, oldHash, newHash string, expiresAt time.Time) (*db.AuthSession, error)
+	ListActiveSessions(ctx context.Context, userID uuid.UUID) ([]*db.AuthSession, error)
+	RevokeSession(ctx context.Context, userID, sessionID uuid.UUID) (bool, error)
+	RevokeUserSessions(ctx context.Context, userID uuid.UUID) error
+}
+
+// CreateSessionParams describes a new login session. RefreshTokenHash is
+// the hash of the refresh token currently issued to the session.
+type CreateSessionParams struct {
+	SessionID        uuid.UUID
+	UserID           uuid.UUID
+	RefreshTokenHash string
+	UserAgent        string
+	IPAddress        string
+	ExpiresAt        time.Time
+}
+
+type SQLSessionRepository struct {
+	db     *db.Queries
+	logger log.Logger
+}
+
+func NewSessionRepository(db *db.Queries, logger log.Logger) SessionRepository {
+	return &SQLSessionRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *SQLSessionRepository) CreateSession(ctx context.Context, params CreateSessionParams) (*db.AuthSession, error) {
+	r.logger.Infof("Creating session %s for user ID: %s", params.SessionID, params.UserID)
+
+	var userAgent, ipAddress *string
+	if params.UserAgent != "" {
+		userAgent = &params.UserAgent
+	}
+	if params.IPAddress != "" {
+		ipAddress = &params.IPAddress
+	}
+
+	start := time.Now()
+	session, err := r.db.CreateAuthSession(ctx, db.CreateAuthSessionParams{
+		SessionID:        params.SessionID,
+		UserID:           params.UserID,
+		RefreshTokenHash: params.RefreshTokenHash,
+		UserAgent:        userAgent,
+		IpAddress:        ipAddress,
+		ExpiresAt:        params.ExpiresAt,
+	})
+	duration := time.Since(start)
+
+	if err != nil {
+		appErr := errors.HandleDBError(err, "session")
+		appErr.Log(r.logger)
+		return nil, appErr
+	}
+
+	r.logger.Infof("Session %s created in %v", params.SessionID, duration)
+	return session, nil
+}
+
+func (r *SQLSessionRepository) GetSession(ctx context.Context, sessionID uuid.UUID) (*db.AuthSession, error) {
+	start := time.Now()
+	session, err := r.db.GetAuthSession(ctx, sessionID)
+	duration := time.Since(start)
+
+	if err != nil {
+		appErr := errors.HandleDBError(err, "session")
+		appErr.Log(r.logger)
+		return nil, appErr
+	}
+
+	r.logger.Debugf("Session %s retrieved in %v", sessionID, duration)
+	return session, nil
+}
+
+// RotateSessionToken swaps the session's refresh token hash for a new one.
+// It returns a not-found error unless the session is active and oldHash is
+// the hash of the token it currently holds.
+func (r *SQLSessionRepository) RotateSessionToken(ctx context.Context, sessionID uuid.UUID, oldHash, newHash string, expiresAt time.Time) (*db.AuthSession, error) {
+	r.logger.Debugf("Rotating refresh token for session %s", sessionID)
+
+	start := time.Now()
+	session, err := r.db.RotateAuthSessionToken(ctx, db.RotateAuthSessionTokenParams{
+		NewHash:   newHash,
+		ExpiresAt: expiresAt,
+		SessionID: sessionID,
+		OldHash:   oldHash,
+	})
+	duration := time.Since(start)
+
+	if err != nil {
+		appErr := errors.HandleDBError(err, "session")
+		appErr.Log(r.logger)
+		return nil, appErr
+	}
+
+	r.logger.Debugf("Refresh token for session %s rotated in %v", sessionID, duration)
+	return session, nil
+}
+
+func (r *SQLSessionRepository) ListActiveSessions(ctx context.Context, userID uuid.UUID) ([]*db.AuthSession, error) {
+	r.logger.Debugf("Listing active sessions for user ID: %s", userID)
+
+	start := time.Now()
+	sessions, err := r.db.ListActiveAuthSessions(ctx, userID)
+	duration := time.Since(start)
+
+	if err != nil {
+		appErr := errors.HandleDBError(err, "session")
+		appErr.Log(r.logger)
+		return nil, appErr
+	}
+
+	r.logger.Debugf("Retrieved %d active sessions for user ID: %s in %v", len(sessions), userID, duration)
+	return sessions, nil
+}
+
+func (r *SQLSessionRepository) RevokeSession(ctx context.Context, userID, sessionID uuid.UUID) (bool, error) {
+	r.logger.Infof("Revoking session %s for user ID: %s", sessionID, userID)
+
+	start := time.Now()
+	rows, err := r.db.RevokeAuthSession(ctx, db.RevokeAuthSessionParams{
+		SessionID: sessionID,
+		UserID:    userID,
+	})
+	duration := time.Since(start)
+
+	if err != nil {
+		appErr := errors.HandleDBError(err, "session")
+		appErr.Log(r.logger)
+		return false, appErr
+	}
+
+	r.logger.Infof("Session %s revocation completed in %v (revoked: %v)", sessionID, duration, rows > 0)
+	return rows > 0, nil
+}
+
+func (r *SQLSessionRepository) RevokeUserSessions(ctx context.Context, userID uuid.UUID) error {
+	r.logger.Infof("Revoking all sessions for user ID: %s", userID)
+
+	start := time.Now()
+	err := r.db.RevokeUserAuthSessions(ctx, userID)
+	duration := time.Since(start)
+
+	if err != nil {
+		appErr := errors.HandleDBError(err, "session")
+		appErr.Log(r.logger)
+		return appErr
+	}
+
+	r.logger.Infof("All sessions for user ID: %s revoked in %v", userID, duration)
+	return nil
+}
@@ -4,6 +4,7 @@ package repository
 import (
 	"context"
 
+	db "github.com/0xsj/mios.io/db/sqlc"
 	"github.com/0xsj/mios.io/pkg/errors"
 	"github.com/jackc/pgx/v4"
 )
@@ -16,11 +17,16 @@ type TxManager interface {
 	WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error
 }
 
+// TxBeginner starts transactions; both *pgx.Conn and *pgxpool.Pool are one
+type TxBeginner interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
 type PgxTxManager struct {
-	conn *pgx.Conn
+	conn TxBeginner
 }
 
-func NewTxManager(conn *pgx.Conn) TxManager {
+func NewTxManager(conn TxBeginner) TxManager {
 	return &PgxTxManager{conn: conn}
 }
 
@@ -56,3 +62,11 @@ func GetTxFromContext(ctx context.Context) (pgx.Tx, bool) {
 	tx, ok := ctx.Value(txContextKey).(pgx.Tx)
 	return tx, ok
 }
+
+// queriesFor returns q bound to the transaction carried by ctx, if any
+func queriesFor(ctx context.Context, q *db.Queries) *db.Queries {
+	if tx, ok := GetTxFromContext(ctx); ok {
+		return q.WithTx(tx)
+	}
+	return q
+}
@@ -0,0 +1,132 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	db "github.com/0xsj/mios.io/db/sqlc"
+	"github.com/0xsj/mios.io/log"
+	"github.com/0xsj/mios.io/pkg/errors"
+	"github.com/google/uuid"
+)
+
+type UserAvatarRepository interface {
+	CreateAvatar(ctx context.Context, userID uuid.UUID, fileKey, url string) (*db.UserAvatar, error)
+	GetAvatar(ctx context.Context, userID, avatarID uuid.UUID) (*db.UserAvatar, error)
+	ListAvatars(ctx context.Context, userID uuid.UUID) ([]*db.UserAvatar, error)
+	CountAvatars(ctx context.Context, userID uuid.UUID) (int64, error)
+	ActivateAvatar(ctx context.Context, userID, avatarID uuid.UUID) error
+}
+
+type SQLUserAvatarRepository struct {
+	db     *db.Queries
+	logger log.Logger
+}
+
+func NewUserAvatarRepository(db *db.Queries, logger log.Logger) UserAvatarRepository {
+	return &SQLUserAvatarRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *SQLUserAvatarRepository) CreateAvatar(ctx context.Context, userID uuid.UUID, fileKey, url string) (*db.UserAvatar, error) {
+	r.logger.Infof("Creating avatar for user ID: %s", userID)
+
+	start := time.Now()
+	avatar, err := r.db.CreateUserAvatar(ctx, db.CreateUserAvatarParams{
+		UserID:  userID,
+		FileKey: fileKey,
+		Url:     url,
+	})
+	duration := time.Since(start)
+
+	if err != nil {
+		appErr := errors.HandleDBError(err, "avatar")
+		appErr.Log(r.logger)
+		return nil, appErr
+	}
+
+	r.logger.Infof("Avatar %s created for user ID: %s in %v", avatar.AvatarID, userID, duration)
+	return avatar, nil
+}
+
+func (r *SQLUserAvatarRepository) GetAvatar(ctx context.Context, userID, avatarID uuid.UUID) (*db.UserAvatar, error) {
+	r.logger.Debugf("Getting avatar %s for user ID: %s", avatarID, userID)
+
+	start := time.Now()
+	avatar, err := r.db.GetUserAvatar(ctx, db.GetUserAvatarParams{
+		AvatarID: avatarID,
+		UserID:   userID,
+	})
+	duration := time.Since(start)
+
+	if err != nil {
+		appErr := errors.HandleDBError(err, "avatar")
+		appErr.Log(r.logger)
+		return nil, appErr
+	}
+
+	r.logger.Debugf("Retrieved avatar %s in %v", avatarID, duration)
+	return avatar, nil
+}
+
+func (r *SQLUserAvatarRepository) ListAvatars(ctx context.Context, userID uuid.UUID) ([]*db.UserAvatar, error) {
+	r.logger.Debugf("Listing avatars for user ID: %s", userID)
+
+	start := time.Now()
+	avatars, err := r.db.ListUserAvatars(ctx, userID)
+	duration := time.Since(start)
+
+	if err != nil {
+		appErr := errors.HandleDBError(err, "avatar")
+		appErr.Log(r.logger)
+		return nil, appErr
+	}
+
+	r.logger.Debugf("Retrieved %d avatars for user ID: %s in %v", len(avatars), userID, duration)
+	return avatars, nil
+}
+
+func (r *SQLUserAvatarRepository) CountAvatars(ctx context.Context, userID uuid.UUID) (int64, error) {
+	r.logger.Debugf("Counting avatars for user ID: %s", userID)
+
+	start := time.Now()
+	count, err := r.db.CountUserAvatars(ctx, userID)
+	duration := time.Since(start)
+
+	if err != nil {
+		appErr := errors.HandleDBError(err, "avatar")
+		appErr.Log(r.logger)
+		return 0, appErr
+	}
+
+	r.logger.Debugf("User ID: %s has %d avatars (counted in %v)", userID, count, duration)
+	return count, nil
+}
+
+// ActivateAvatar makes the avatar the user's only active one and copies its
+// URL into the user's profile image in a single statement.
+func (r *SQLUserAvatarRepository) ActivateAvatar(ctx context.Context, userID, avatarID uuid.UUID) error {
+	r.logger.Infof("Activating avatar %s for user ID: %s", avatarID, userID)
+
+	start := time.Now()
+	rows, err := r.db.ActivateUserAvatar(ctx, db.ActivateUserAvatarParams{
+		AvatarID: avatarID,
+		UserID:   userID,
+	})
+	duration := time.Since(start)
+
+	if err != nil {
+		appErr := errors.HandleDBError(err, "avatar")
+		appErr.Log(r.logger)
+		return appErr
+	}
+
+	if rows == 0 {
+		return errors.NewNotFoundError("Avatar not found", nil)
+	}
+
+	r.logger.Infof("Avatar %s activated for user ID: %s in %v", avatarID, userID, duration)
+	return nil
+}
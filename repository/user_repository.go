@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"strings"
 	"time"
 
 	db "github.com/0xsj/mios.io/db/sqlc"
@@ -17,6 +18,16 @@ type UserRepository interface {
 	GetUserByUsername(ctx context.Context, username string) (*db.User, error)
 	GetUserByHandle(ctx context.Context, handle string) (*db.User, error)
 	GetUserByEmail(ctx context.Context, email string) (*db.User, error)
+	GetUserByCustomDomain(ctx context.Context, domain string) (*db.User, error)
+	// GetUsersByIDs loads the given users in one query, leaving out any
+	// that don't exist or are pending deletion
+	GetUsersByIDs(ctx context.Context, userIDs []uuid.UUID) ([]*db.User, error)
+
+	// Lookups that also find accounts pending deletion, for signing in to
+	// restore them
+	GetUserIncludingDeleted(ctx context.Context, userID uuid.UUID) (*db.User, error)
+	GetUserByEmailIncludingDeleted(ctx context.Context, email string) (*db.User, error)
+
 	UpdateUser(ctx context.Context, arg UpdateUserParams) error
 	UpdateUsername(ctx context.Context, userID uuid.UUID, username string) error
 	UpdateHandle(ctx context.Context, userID uuid.UUID, handle string) error
@@ -24,7 +35,32 @@ type UserRepository interface {
 	UpdatePremiumStatus(ctx context.Context, userID uuid.UUID, isPremium bool) error
 	UpdateAdminStatus(ctx context.Context, userID uuid.UUID, isAdmin bool) error
 	UpdateOnboardedStatus(ctx context.Context, userID uuid.UUID, onboarded bool) error
+	UpdateAnalyticsEnabled(ctx context.Context, userID uuid.UUID, enabled bool) error
+	UpdateLocale(ctx context.Context, userID uuid.UUID, locale *string) error
+	UpdateVisibility(ctx context.Context, userID uuid.UUID, visibility string) error
+	UpdateContentApproval(ctx context.Context, userID uuid.UUID, requiresApproval bool) error
+	UpdateTemplateStatus(ctx context.Context, userID uuid.UUID, isTemplate bool) error
+	ListTemplateUsers(ctx context.Context) ([]*db.User, error)
+	ListUsers(ctx context.Context, filter UserFilter, limit, offset int) ([]*db.User, error)
+	CountUsers(ctx context.Context, filter UserFilter) (int64, error)
+	RecordHandleChange(ctx context.Context, userID uuid.UUID, oldHandle string) error
+	GetUserIDByPreviousHandle(ctx context.Context, handle string) (uuid.UUID, error)
+	ReleaseHandleForTransfer(ctx context.Context, arg ReleaseHandleParams) error
+	ClaimHandleTransfer(ctx context.Context, userID uuid.UUID, handle string) error
+	IsHandleReserved(ctx context.Context, handle string) (bool, error)
+	// IsHandleTaken reports whether a user has the handle or it is reserved
+	// for a pending transfer
+	IsHandleTaken(ctx context.Context, handle string) (bool, error)
+	// SetCustomDomainVerification points the user at domain with a new
+	// verification token, taking the domain from anyone who never verified it
+	SetCustomDomainVerification(ctx context.Context, userID uuid.UUID, domain, token string) error
+	MarkCustomDomainVerified(ctx context.Context, userID uuid.UUID, domain, token string) error
 	DeleteUser(ctx context.Context, userID uuid.UUID) error
+	// SoftDeleteUser hides the user from every lookup and returns when it
+	// happened; RestoreUser undoes it until PurgeDeletedUsers removes the row
+	SoftDeleteUser(ctx context.Context, userID uuid.UUID) (time.Time, error)
+	RestoreUser(ctx context.Context, userID uuid.UUID) error
+	PurgeDeletedUsers(ctx context.Context, deletedBefore time.Time, limit int) (int64, error)
 }
 
 type CreateUserParams struct {
@@ -42,6 +78,27 @@ type CreateUserParams struct {
 	Onboarded       bool
 }
 
+// UserFilter narrows ListUsers and CountUsers. Search matches part of the
+// username, handle or email; nil flags match either value. Verified matches
+// on whether the account's email address has been confirmed.
+type UserFilter struct {
+	Search    string
+	IsPremium *bool
+	IsAdmin   *bool
+	Onboarded *bool
+	Verified  *bool
+}
+
+// ReleaseHandleParams moves Handle from one account into a reservation for
+// another, giving the source account ReplacementHandle in its place
+type ReleaseHandleParams struct {
+	FromUserID        uuid.UUID
+	ToUserID          uuid.UUID
+	Handle            string
+	ReplacementHandle string
+	ExpiresAt         time.Time
+}
+
 type UpdateUserParams struct {
 	UserID          uuid.UUID
 	FirstName       string
@@ -156,6 +213,23 @@ func (r *SQLCUserRepository) GetUserByHandle(ctx context.Context, handle string)
 	return user, nil
 }
 
+func (r *SQLCUserRepository) GetUserByCustomDomain(ctx context.Context, domain string) (*db.User, error) {
+	r.logger.Debugf("Getting user by custom domain: %s", domain)
+
+	start := time.Now()
+	user, err := r.db.GetUserByCustomDomain(ctx, domain)
+	duration := time.Since(start)
+
+	if err != nil {
+		appErr := apperror.HandleDBError(err, "user")
+		appErr.Log(r.logger)
+		return nil, appErr
+	}
+
+	r.logger.Debugf("Retrieved user by custom domain: %s in %v", domain, duration)
+	return user, nil
+}
+
 func (r *SQLCUserRepository) GetUserByEmail(ctx context.Context, email string) (*db.User, error) {
 	r.logger.Debugf("Getting user by email: %s", email)
 
@@ -173,6 +247,57 @@ func (r *SQLCUserRepository) GetUserByEmail(ctx context.Context, email string) (
 	return user, nil
 }
 
+func (r *SQLCUserRepository) GetUsersByIDs(ctx context.Context, userIDs []uuid.UUID) ([]*db.User, error) {
+	r.logger.Debugf("Getting %d users by ID", len(userIDs))
+
+	start := time.Now()
+	users, err := r.db.GetUsersByIDs(ctx, userIDs)
+	duration := time.Since(start)
+
+	if err != nil {
+		appErr := apperror.HandleDBError(err, "users")
+		appErr.Log(r.logger)
+		return nil, appErr
+	}
+
+	r.logger.Debugf("Retrieved %d of %d users in %v", len(users), len(userIDs), duration)
+	return users, nil
+}
+
+func (r *SQLCUserRepository) GetUserIncludingDeleted(ctx context.Context, userID uuid.UUID) (*db.User, error) {
+	r.logger.Debugf("Getting user by ID including deleted: %s", userID)
+
+	start := time.Now()
+	user, err := r.db.GetUserIncludingDeleted(ctx, userID)
+	duration := time.Since(start)
+
+	if err != nil {
+		appErr := apperror.HandleDBError(err, "user")
+		appErr.Log(r.logger)
+		return nil, appErr
+	}
+
+	r.logger.Debugf("Retrieved user %s including deleted in %v", userID, duration)
+	return user, nil
+}
+
+func (r *SQLCUserRepository) GetUserByEmailIncludingDeleted(ctx context.Context, email string) (*db.User, error) {
+	r.logger.Debugf("Getting user by email including deleted: %s", email)
+
+	start := time.Now()
+	user, err := r.db.GetUserByEmailIncludingDeleted(ctx, email)
+	duration := time.Since(start)
+
+	if err != nil {
+		appErr := apperror.HandleDBError(err, "user")
+		appErr.Log(r.logger)
+		return nil, appErr
+	}
+
+	r.logger.Debugf("Retrieved user by email: %s including deleted in %v", email, duration)
+	return user, nil
+}
+
 func (r *SQLCUserRepository) UpdateUser(ctx context.Context, arg UpdateUserParams) error {
 	r.logger.Infof("Updating user with ID: %s", arg.UserID)
 
@@ -310,6 +435,304 @@ func (r *SQLCUserRepository) UpdateAdminStatus(ctx context.Context, userID uuid.
 	return nil
 }
 
+func (r *SQLCUserRepository) UpdateContentApproval(ctx context.Context, userID uuid.UUID, requiresApproval bool) error {
+	r.logger.Infof("Updating content approval mode for user ID: %s to: %v", userID, requiresApproval)
+
+	params := db.UpdateUserContentApprovalParams{
+		UserID:                  userID,
+		RequiresContentApproval: requiresApproval,
+	}
+
+	start := time.Now()
+	err := r.db.UpdateUserContentApproval(ctx, params)
+	duration := time.Since(start)
+
+	if err != nil {
+		appErr := apperror.HandleDBError(err, "user")
+		appErr.Log(r.logger)
+		return appErr
+	}
+
+	r.logger.Infof("Updated content approval mode for user ID: %s in %v", userID, duration)
+	return nil
+}
+
+func (r *SQLCUserRepository) UpdateTemplateStatus(ctx context.Context, userID uuid.UUID, isTemplate bool) error {
+	r.logger.Infof("Updating template status for user ID: %s to: %v", userID, isTemplate)
+
+	params := db.UpdateUserTemplateStatusParams{
+		UserID:     userID,
+		IsTemplate: isTemplate,
+	}
+
+	start := time.Now()
+	err := r.db.UpdateUserTemplateStatus(ctx, params)
+	duration := time.Since(start)
+
+	if err != nil {
+		appErr := apperror.HandleDBError(err, "user")
+		appErr.Log(r.logger)
+		return appErr
+	}
+
+	r.logger.Infof("Updated template status for user ID: %s in %v", userID, duration)
+	return nil
+}
+
+func (r *SQLCUserRepository) ListTemplateUsers(ctx context.Context) ([]*db.User, error) {
+	r.logger.Debug("Listing template profiles")
+
+	start := time.Now()
+	users, err := r.db.ListTemplateUsers(ctx)
+	duration := time.Since(start)
+
+	if err != nil {
+		appErr := apperror.HandleDBError(err, "user")
+		appErr.Log(r.logger)
+		return nil, appErr
+	}
+
+	r.logger.Debugf("Retrieved %d template profiles in %v", len(users), duration)
+	return users, nil
+}
+
+func (r *SQLCUserRepository) ListUsers(ctx context.Context, filter UserFilter, limit, offset int) ([]*db.User, error) {
+	r.logger.Debugf("Listing users (search %q, limit %d, offset %d)", filter.Search, limit, offset)
+
+	start := time.Now()
+	users, err := r.db.ListUsers(ctx, db.ListUsersParams{
+		Search:    searchFilter(filter.Search),
+		IsPremium: filter.IsPremium,
+		IsAdmin:   filter.IsAdmin,
+		Onboarded: filter.Onboarded,
+		Verified:  filter.Verified,
+		Limit:     int32(limit),
+		Offset:    int32(offset),
+	})
+	duration := time.Since(start)
+
+	if err != nil {
+		appErr := apperror.HandleDBError(err, "user")
+		appErr.Log(r.logger)
+		return nil, appErr
+	}
+
+	r.logger.Debugf("Retrieved %d users in %v", len(users), duration)
+	return users, nil
+}
+
+func (r *SQLCUserRepository) CountUsers(ctx context.Context, filter UserFilter) (int64, error) {
+	start := time.Now()
+	count, err := r.db.CountUsers(ctx, db.CountUsersParams{
+		Search:    searchFilter(filter.Search),
+		IsPremium: filter.IsPremium,
+		IsAdmin:   filter.IsAdmin,
+		Onboarded: filter.Onboarded,
+		Verified:  filter.Verified,
+	})
+	duration := time.Since(start)
+
+	if err != nil {
+		appErr := apperror.HandleDBError(err, "user")
+		appErr.Log(r.logger)
+		return 0, appErr
+	}
+
+	r.logger.Debugf("Counted %d users in %v", count, duration)
+	return count, nil
+}
+
+// searchFilter escapes LIKE wildcards so a search matches its text literally
+func searchFilter(search string) *string {
+	if search == "" {
+		return nil
+	}
+	escaped := strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(search)
+	return &escaped
+}
+
+// RecordHandleChange remembers a handle the user has moved away from. If
+// the handle was previously released by someone else it now points here.
+func (r *SQLCUserRepository) RecordHandleChange(ctx context.Context, userID uuid.UUID, oldHandle string) error {
+	r.logger.Infof("Recording previous handle %s for user ID: %s", oldHandle, userID)
+
+	start := time.Now()
+	err := r.db.RecordHandleChange(ctx, db.RecordHandleChangeParams{
+		Handle: oldHandle,
+		UserID: userID,
+	})
+	duration := time.Since(start)
+
+	if err != nil {
+		appErr := apperror.HandleDBError(err, "handle history")
+		appErr.Log(r.logger)
+		return appErr
+	}
+
+	r.logger.Infof("Recorded previous handle for user ID: %s in %v", userID, duration)
+	return nil
+}
+
+func (r *SQLCUserRepository) GetUserIDByPreviousHandle(ctx context.Context, handle string) (uuid.UUID, error) {
+	r.logger.Debugf("Looking up previous handle: %s", handle)
+
+	start := time.Now()
+	userID, err := r.db.GetHandleHistoryOwner(ctx, handle)
+	duration := time.Since(start)
+
+	if err != nil {
+		appErr := apperror.HandleDBError(err, "handle history")
+		appErr.Log(r.logger)
+		return uuid.Nil, appErr
+	}
+
+	r.logger.Debugf("Previous handle %s belongs to user ID: %s (%v)", handle, userID, duration)
+	return userID, nil
+}
+
+// ReleaseHandleForTransfer swaps the source account onto its replacement
+// handle and reserves the old one for the target in a single statement.
+func (r *SQLCUserRepository) ReleaseHandleForTransfer(ctx context.Context, arg ReleaseHandleParams) error {
+	r.logger.Infof("Releasing handle %s from user ID: %s for user ID: %s", arg.Handle, arg.FromUserID, arg.ToUserID)
+
+	start := time.Now()
+	rows, err := r.db.ReleaseHandleForTransfer(ctx, db.ReleaseHandleForTransferParams{
+		ReplacementHandle: arg.ReplacementHandle,
+		FromUserID:        arg.FromUserID,
+		Handle:            arg.Handle,
+		ToUserID:          arg.ToUserID,
+		ExpiresAt:         arg.ExpiresAt,
+	})
+	duration := time.Since(start)
+
+	if err != nil {
+		appErr := apperror.HandleDBError(err, "handle")
+		appErr.Log(r.logger)
+		return appErr
+	}
+
+	if rows == 0 {
+		return apperror.NewConflictError("Handle is no longer held by this account", nil)
+	}
+
+	r.logger.Infof("Handle %s reserved for user ID: %s in %v", arg.Handle, arg.ToUserID, duration)
+	return nil
+}
+
+// ClaimHandleTransfer gives the user a handle reserved for them, removing
+// the reservation. Expired or unknown reservations are reported as not found.
+func (r *SQLCUserRepository) ClaimHandleTransfer(ctx context.Context, userID uuid.UUID, handle string) error {
+	r.logger.Infof("Claiming handle %s for user ID: %s", handle, userID)
+
+	start := time.Now()
+	rows, err := r.db.ClaimHandleTransfer(ctx, db.ClaimHandleTransferParams{
+		Handle:   handle,
+		ToUserID: userID,
+	})
+	duration := time.Since(start)
+
+	if err != nil {
+		appErr := apperror.HandleDBError(err, "handle")
+		appErr.Log(r.logger)
+		return appErr
+	}
+
+	if rows == 0 {
+		return apperror.NewNotFoundError("No pending transfer of this handle to your account", nil)
+	}
+
+	r.logger.Infof("Handle %s claimed by user ID: %s in %v", handle, userID, duration)
+	return nil
+}
+
+func (r *SQLCUserRepository) IsHandleReserved(ctx context.Context, handle string) (bool, error) {
+	r.logger.Debugf("Checking whether handle %s is reserved", handle)
+
+	start := time.Now()
+	reserved, err := r.db.IsHandleReserved(ctx, handle)
+	duration := time.Since(start)
+
+	if err != nil {
+		appErr := apperror.HandleDBError(err, "handle transfer")
+		appErr.Log(r.logger)
+		return false, appErr
+	}
+
+	r.logger.Debugf("Handle %s reserved: %v (%v)", handle, reserved, duration)
+	return reserved, nil
+}
+
+func (r *SQLCUserRepository) IsHandleTaken(ctx context.Context, handle string) (bool, error) {
+	r.logger.Debugf("Checking whether handle %s is taken", handle)
+
+	start := time.Now()
+	taken, err := r.db.IsHandleTaken(ctx, handle)
+	duration := time.Since(start)
+
+	if err != nil {
+		appErr := apperror.HandleDBError(err, "user")
+		appErr.Log(r.logger)
+		return false, appErr
+	}
+
+	r.logger.Debugf("Handle %s taken: %v (%v)", handle, taken, duration)
+	return taken, nil
+}
+
+func (r *SQLCUserRepository) SetCustomDomainVerification(ctx context.Context, userID uuid.UUID, domain, token string) error {
+	r.logger.Infof("Starting verification of custom domain %s for user ID: %s", domain, userID)
+
+	start := time.Now()
+	err := r.db.ReleaseUnverifiedCustomDomain(ctx, db.ReleaseUnverifiedCustomDomainParams{
+		Domain: domain,
+		UserID: userID,
+	})
+	if err == nil {
+		err = r.db.SetCustomDomainVerification(ctx, db.SetCustomDomainVerificationParams{
+			UserID:            userID,
+			CustomDomain:      &domain,
+			CustomDomainToken: &token,
+		})
+	}
+	duration := time.Since(start)
+
+	if err != nil {
+		appErr := apperror.HandleDBError(err, "custom domain")
+		appErr.Log(r.logger)
+		return appErr
+	}
+
+	r.logger.Infof("Custom domain %s pending verification for user ID: %s (%v)", domain, userID, duration)
+	return nil
+}
+
+// MarkCustomDomainVerified records a successful check. It is reported as not
+// found if the domain or token changed while the check ran.
+func (r *SQLCUserRepository) MarkCustomDomainVerified(ctx context.Context, userID uuid.UUID, domain, token string) error {
+	r.logger.Infof("Marking custom domain %s verified for user ID: %s", domain, userID)
+
+	start := time.Now()
+	rows, err := r.db.MarkCustomDomainVerified(ctx, db.MarkCustomDomainVerifiedParams{
+		UserID:            userID,
+		CustomDomain:      &domain,
+		CustomDomainToken: &token,
+	})
+	duration := time.Since(start)
+
+	if err != nil {
+		appErr := apperror.HandleDBError(err, "custom domain")
+		appErr.Log(r.logger)
+		return appErr
+	}
+
+	if rows == 0 {
+		return apperror.NewNotFoundError("Custom domain verification is no longer pending", nil)
+	}
+
+	r.logger.Infof("Custom domain %s verified for user ID: %s in %v", domain, userID, duration)
+	return nil
+}
+
 func (r *SQLCUserRepository) UpdateOnboardedStatus(ctx context.Context, userID uuid.UUID, onboarded bool) error {
 	r.logger.Infof("Updating onboarded status for user ID: %s to: %v", userID, onboarded)
 
@@ -332,6 +755,66 @@ func (r *SQLCUserRepository) UpdateOnboardedStatus(ctx context.Context, userID u
 	return nil
 }
 
+func (r *SQLCUserRepository) UpdateAnalyticsEnabled(ctx context.Context, userID uuid.UUID, enabled bool) error {
+	r.logger.Infof("Updating analytics tracking for user ID: %s to: %v", userID, enabled)
+
+	start := time.Now()
+	err := r.db.UpdateUserAnalyticsEnabled(ctx, db.UpdateUserAnalyticsEnabledParams{
+		UserID:           userID,
+		AnalyticsEnabled: enabled,
+	})
+	duration := time.Since(start)
+
+	if err != nil {
+		appErr := apperror.HandleDBError(err, "user")
+		appErr.Log(r.logger)
+		return appErr
+	}
+
+	r.logger.Infof("Updated analytics tracking for user ID: %s in %v", userID, duration)
+	return nil
+}
+
+func (r *SQLCUserRepository) UpdateLocale(ctx context.Context, userID uuid.UUID, locale *string) error {
+	r.logger.Infof("Updating locale for user ID: %s", userID)
+
+	start := time.Now()
+	err := r.db.UpdateUserLocale(ctx, db.UpdateUserLocaleParams{
+		UserID: userID,
+		Locale: locale,
+	})
+	duration := time.Since(start)
+
+	if err != nil {
+		appErr := apperror.HandleDBError(err, "user")
+		appErr.Log(r.logger)
+		return appErr
+	}
+
+	r.logger.Infof("Updated locale for user ID: %s in %v", userID, duration)
+	return nil
+}
+
+func (r *SQLCUserRepository) UpdateVisibility(ctx context.Context, userID uuid.UUID, visibility string) error {
+	r.logger.Infof("Updating visibility for user ID: %s to: %s", userID, visibility)
+
+	start := time.Now()
+	err := r.db.UpdateUserVisibility(ctx, db.UpdateUserVisibilityParams{
+		UserID:     userID,
+		Visibility: visibility,
+	})
+	duration := time.Since(start)
+
+	if err != nil {
+		appErr := apperror.HandleDBError(err, "user")
+		appErr.Log(r.logger)
+		return appErr
+	}
+
+	r.logger.Infof("Updated visibility for user ID: %s in %v", userID, duration)
+	return nil
+}
+
 func (r *SQLCUserRepository) DeleteUser(ctx context.Context, userID uuid.UUID) error {
 	r.logger.Warnf("Deleting user with ID: %s", userID)
 
@@ -348,3 +831,61 @@ func (r *SQLCUserRepository) DeleteUser(ctx context.Context, userID uuid.UUID) e
 	r.logger.Warnf("Deleted user with ID: %s in %v", userID, duration)
 	return nil
 }
+
+func (r *SQLCUserRepository) SoftDeleteUser(ctx context.Context, userID uuid.UUID) (time.Time, error) {
+	r.logger.Warnf("Soft deleting user with ID: %s", userID)
+
+	start := time.Now()
+	deletedAt, err := r.db.SoftDeleteUser(ctx, userID)
+	duration := time.Since(start)
+
+	if err != nil {
+		appErr := apperror.HandleDBError(err, "user")
+		appErr.Log(r.logger)
+		return time.Time{}, appErr
+	}
+
+	r.logger.Warnf("Soft deleted user with ID: %s in %v", userID, duration)
+	return *deletedAt, nil
+}
+
+func (r *SQLCUserRepository) RestoreUser(ctx context.Context, userID uuid.UUID) error {
+	r.logger.Infof("Restoring deleted user with ID: %s", userID)
+
+	start := time.Now()
+	rows, err := r.db.RestoreUser(ctx, userID)
+	duration := time.Since(start)
+
+	if err != nil {
+		appErr := apperror.HandleDBError(err, "user")
+		appErr.Log(r.logger)
+		return appErr
+	}
+
+	if rows == 0 {
+		return apperror.NewNotFoundError("No pending account deletion found", nil)
+	}
+
+	r.logger.Infof("Restored user with ID: %s in %v", userID, duration)
+	return nil
+}
+
+func (r *SQLCUserRepository) PurgeDeletedUsers(ctx context.Context, deletedBefore time.Time, limit int) (int64, error) {
+	r.logger.Debugf("Purging users deleted before %s", deletedBefore.Format(time.RFC3339))
+
+	start := time.Now()
+	rows, err := r.db.PurgeDeletedUsers(ctx, db.PurgeDeletedUsersParams{
+		Cutoff:    deletedBefore,
+		BatchSize: int32(limit),
+	})
+	duration := time.Since(start)
+
+	if err != nil {
+		appErr := apperror.HandleDBError(err, "user")
+		appErr.Log(r.logger)
+		return 0, appErr
+	}
+
+	r.logger.Infof("Purged %d deleted users in %v", rows, duration)
+	return rows, nil
+}
@@ -0,0 +1,131 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	apperror "github.com/0xsj/mios.io/pkg/errors"
+	"github.com/0xsj/mios.io/repository"
+)
+
+const (
+	defaultDeletionGracePeriod = 30 * 24 * time.Hour
+	// deletionPurgeBatchSize is kept small because each user removed
+	// cascades through their content and analytics
+	deletionPurgeBatchSize = 100
+)
+
+// AccountDeletionDTO tells the user when their account was deleted and when
+// it will be gone for good
+type AccountDeletionDTO struct {
+	UserID    string `json:"user_id"`
+	DeletedAt string `json:"deleted_at"`
+	PurgeAt   string `json:"purge_at"`
+}
+
+// RequestAccountDeletion hides the user and their profile straight away and
+// leaves the account restorable until the grace period is over
+func (s *userService) RequestAccountDeletion(ctx context.Context, id string) (*AccountDeletionDTO, error) {
+	s.logger.Warnf("Account deletion requested for user ID: %s", id)
+
+	userID, err := parseUUID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	deletedAt, err := s.userRepo.SoftDeleteUser(ctx, userID)
+	if err != nil {
+		s.logger.Errorf("Failed to delete user with ID %s: %v", id, err)
+		return nil, err
+	}
+
+	// Sign the account out everywhere; its owner has to sign in again to
+	// restore it
+	if err := s.sessionRepo.RevokeUserSessions(ctx, userID); err != nil {
+		s.logger.Warnf("Failed to revoke sessions of deleted user %s: %v", id, err)
+	}
+	if err := s.authRepo.InvalidateUserRefreshTokens(ctx, userID); err != nil {
+		s.logger.Warnf("Failed to invalidate refresh tokens of deleted user %s: %v", id, err)
+	}
+
+	purgeAt := deletedAt.Add(s.config.DeletionGracePeriod)
+	s.audit(ctx, repository.AuditEntry{
+		ActorID: userID,
+		Action:  AuditAccountDeletionRequested,
+		Details: map[string]interface{}{
+			"purge_at": purgeAt.Format(time.RFC3339),
+		},
+	})
+
+	s.logger.Warnf("User with ID %s deleted, purge scheduled for %v", id, purgeAt)
+	return &AccountDeletionDTO{
+		UserID:    id,
+		DeletedAt: deletedAt.Format(time.RFC3339),
+		PurgeAt:   purgeAt.Format(time.RFC3339),
+	}, nil
+}
+
+// CancelAccountDeletion restores an account whose deletion hasn't been
+// purged yet
+func (s *userService) CancelAccountDeletion(ctx context.Context, id string) (*UserDTO, error) {
+	s.logger.Infof("Cancelling account deletion for user ID: %s", id)
+
+	userID, err := parseUUID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.userRepo.RestoreUser(ctx, userID); err != nil {
+		s.logger.Errorf("Failed to restore user with ID %s: %v", id, err)
+		return nil, err
+	}
+
+	s.audit(ctx, repository.AuditEntry{
+		ActorID: userID,
+		Action:  AuditAccountDeletionCancelled,
+	})
+
+	s.logger.Infof("Account deletion cancelled for user ID: %s", id)
+	return s.GetUser(ctx, id)
+}
+
+// PurgeDeletedUsers permanently removes users whose grace period has run
+// out, in batches, and returns how many it removed
+func (s *userService) PurgeDeletedUsers(ctx context.Context) (int64, error) {
+	cutoff := time.Now().Add(-s.config.DeletionGracePeriod)
+
+	var total int64
+	for ctx.Err() == nil {
+		rows, err := s.userRepo.PurgeDeletedUsers(ctx, cutoff, deletionPurgeBatchSize)
+		if err != nil {
+			s.logger.Errorf("Failed to purge deleted users: %v", err)
+			return total, apperror.Wrap(err, "Failed to purge deleted users")
+		}
+		total += rows
+		if rows < deletionPurgeBatchSize {
+			break
+		}
+	}
+
+	s.logger.Infof("Purged %d users deleted before %s", total, cutoff.Format(time.RFC3339))
+	return total, nil
+}
+
+// StartDeletionPurge runs PurgeDeletedUsers every interval until ctx is
+// cancelled
+func (s *userService) StartDeletionPurge(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.PurgeDeletedUsers(ctx); err != nil {
+			s.logger.Errorf("Deleted user purge run failed: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
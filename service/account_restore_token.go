@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+
+	db "github.com/0xsj/mios.io/db/sqlc"
+	"github.com/0xsj/mios.io/pkg/errors"
+	"github.com/0xsj/mios.io/pkg/token"
+	"github.com/google/uuid"
+)
+
+// issueRestoreToken answers a successful sign-in to an account pending
+// deletion. The token carries no session and is only accepted by
+// ValidateRestoreToken.
+func (s *authService) issueRestoreToken(user *db.User) (*TokenResponse, error) {
+	jwtMaker := token.NewJWTMaker(s.jwtSecret)
+	restoreToken, expiresAt, err := jwtMaker.CreateToken(
+		user.UserID.String(),
+		user.Username,
+		user.Email,
+		false,
+		false,
+		token.AccountRestore,
+		AccountRestoreTokenDuration,
+	)
+	if err != nil {
+		s.logger.Errorf("Failed to create account restore token: %v", err)
+		return nil, errors.NewInternalError("Failed to generate authentication tokens", err)
+	}
+
+	s.logger.Infof("User %s signed in to an account pending deletion", user.UserID)
+	return &TokenResponse{
+		ExpiresAt:       expiresAt.Unix(),
+		PendingDeletion: true,
+		RestoreToken:    restoreToken,
+	}, nil
+}
+
+// ValidateRestoreToken accepts only tokens from issueRestoreToken, for an
+// account that is still pending deletion
+func (s *authService) ValidateRestoreToken(ctx context.Context, tokenStr string) (*token.Claims, error) {
+	s.logger.Debugf("Validating account restore token")
+
+	jwtMaker := token.NewJWTMaker(s.jwtSecret)
+	claims, err := jwtMaker.VerifyToken(tokenStr)
+	if err != nil {
+		s.logger.Warnf("Restore token validation failed: %v", err)
+		return nil, errors.NewUnauthorizedError("Invalid token", err)
+	}
+
+	if claims.TokenType != token.AccountRestore {
+		s.logger.Warnf("Wrong token type for account restore: %s", claims.TokenType)
+		return nil, errors.NewUnauthorizedError("Invalid token type", nil)
+	}
+
+	userID, err := uuid.Parse(claims.UserID)
+	if err != nil {
+		s.logger.Warnf("Invalid user ID in restore token: %v", err)
+		return nil, errors.NewUnauthorizedError("Invalid token", err)
+	}
+
+	user, err := s.userRepo.GetUserIncludingDeleted(ctx, userID)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			s.logger.Warnf("Restore token validation failed: user not found: %s", userID)
+			return nil, errors.NewUnauthorizedError("User not found", nil)
+		}
+		s.logger.Errorf("Error retrieving user for restore token validation: %v", err)
+		return nil, errors.Wrap(err, "Failed to validate user")
+	}
+
+	if user.DeletedAt == nil {
+		s.logger.Warnf("Restore token used for user %s with no pending deletion", userID)
+		return nil, errors.NewUnauthorizedError("Account is not pending deletion", nil)
+	}
+
+	return claims, nil
+}
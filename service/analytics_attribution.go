@@ -0,0 +1,56 @@
+package service
+
+import (
+	"net/url"
+	"strings"
+)
+
+// DefaultReferrerParams are the query parameters that name a referrer
+// explicitly when none are configured, most authoritative first
+var DefaultReferrerParams = []string{"ref", "utm_source"}
+
+// attributedReferrer picks the referrer recorded for an event. Referrer
+// policies often strip the Referer header, which would count the visit as
+// direct traffic, so a referrer named in the tracked request's query string
+// wins. Precedence is:
+//
+//	query parameter (ReferrerParams, in order) > Referer header > direct
+//
+// Direct traffic is recorded with an empty referrer. Query values are
+// cleaned like UTM tags so "?ref=Newsletter" and "?ref=newsletter" group.
+func (s *analyticsService) attributedReferrer(query url.Values, header string) string {
+	for _, param := range s.config.ReferrerParams {
+		if value := cleanUTM(query.Get(param)); value != "" {
+			return value
+		}
+	}
+	return header
+}
+
+// campaignTags returns the UTM tags sent with an event, or when there are
+// none, the ones in the tracked request's query string
+func campaignTags(source, medium, campaign string, query url.Values) (string, string, string) {
+	if source != "" || medium != "" || campaign != "" {
+		return source, medium, campaign
+	}
+	return query.Get("utm_source"), query.Get("utm_medium"), query.Get("utm_campaign")
+}
+
+// normalizeReferrerParams lowercases and dedupes the configured parameter
+// names, falling back to DefaultReferrerParams
+func normalizeReferrerParams(params []string) []string {
+	var normalized []string
+	seen := make(map[string]bool)
+	for _, param := range params {
+		param = strings.ToLower(strings.TrimSpace(param))
+		if param == "" || seen[param] {
+			continue
+		}
+		seen[param] = true
+		normalized = append(normalized, param)
+	}
+	if len(normalized) == 0 {
+		return DefaultReferrerParams
+	}
+	return normalized
+}
@@ -0,0 +1,149 @@
+package service
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+
+	db "github.com/0xsj/mios.io/db/sqlc"
+	"github.com/0xsj/mios.io/pkg/errors"
+	"github.com/0xsj/mios.io/repository"
+	"github.com/google/uuid"
+)
+
+const defaultMaxBatchSize = 500
+
+// BatchResult reports what happened to each entry of a batch, in request
+// order. Entries dropped because the owner turned analytics off count as
+// succeeded, as they do when recorded one at a time.
+type BatchResult struct {
+	Total     int                `json:"total"`
+	Succeeded int                `json:"succeeded"`
+	Failed    int                `json:"failed"`
+	Results   []BatchEntryResult `json:"results"`
+}
+
+type BatchEntryResult struct {
+	Index   int    `json:"index"`
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty"`
+}
+
+// RecordClicksBatch validates each click on its own and stores the valid
+// ones with a single bulk insert, so one bad entry does not fail the rest.
+// The batch as a whole only fails when it is empty, too large, or the
+// insert itself fails, in which case nothing is stored.
+func (s *analyticsService) RecordClicksBatch(ctx context.Context, inputs []RecordClickInput) (*BatchResult, error) {
+	s.logger.Infof("Recording batch of %d clicks", len(inputs))
+
+	if len(inputs) == 0 {
+		return nil, errors.NewValidationError("Batch must contain at least one click", nil)
+	}
+	if len(inputs) > s.config.MaxBatchSize {
+		s.logger.Warnf("Rejecting batch of %d clicks, limit is %d", len(inputs), s.config.MaxBatchSize)
+		return nil, errors.NewValidationError(
+			fmt.Sprintf("Batch is too large, at most %d clicks are allowed", s.config.MaxBatchSize), nil)
+	}
+
+	result := &BatchResult{
+		Total:   len(inputs),
+		Results: make([]BatchEntryResult, len(inputs)),
+	}
+
+	lookup := newInteractionLookup()
+	var entries []repository.CreateAnalyticsParams
+
+	for i, input := range inputs {
+		result.Results[i].Index = i
+		if input.InteractionType == "" {
+			input.InteractionType = InteractionClick
+		}
+
+		params, err := s.prepareInteraction(ctx, input, lookup)
+		if err != nil {
+			result.Results[i].Error = batchErrorMessage(err)
+			result.Failed++
+			continue
+		}
+
+		result.Results[i].Success = true
+		result.Succeeded++
+		if params != nil {
+			entries = append(entries, *params)
+		}
+	}
+
+	if len(entries) > 0 {
+		if _, err := s.analyticsRepo.CreateAnalyticsEntries(ctx, entries); err != nil {
+			s.logger.Errorf("Failed to insert click batch: %v", err)
+			return nil, errors.Wrap(err, "Failed to record clicks")
+		}
+	}
+
+	for _, entry := range entries {
+		s.publishLiveEvent(ctx, entry.UserID, LiveEventDTO{
+			Type:            LiveEventInteraction,
+			ItemID:          entry.ItemID.String(),
+			InteractionType: entry.InteractionType,
+			Referrer:        entry.Referrer,
+			IsBot:           entry.IsBot,
+		})
+	}
+
+	s.logger.Infof("Click batch recorded: %d stored, %d succeeded, %d failed",
+		len(entries), result.Succeeded, result.Failed)
+	return result, nil
+}
+
+// batchErrorMessage is the client-facing reason an entry was rejected
+func batchErrorMessage(err error) string {
+	var appErr *errors.AppError
+	if stderrors.As(err, &appErr) {
+		return appErr.Message
+	}
+	return err.Error()
+}
+
+// interactionLookup remembers the items and owners already fetched while
+// recording, so a batch that clicks the same item many times looks it up
+// once
+type interactionLookup struct {
+	items    map[uuid.UUID]error
+	users    map[uuid.UUID]*db.User
+	userErrs map[uuid.UUID]error
+}
+
+func newInteractionLookup() *interactionLookup {
+	return &interactionLookup{
+		items:    make(map[uuid.UUID]error),
+		users:    make(map[uuid.UUID]*db.User),
+		userErrs: make(map[uuid.UUID]error),
+	}
+}
+
+// item reports whether the content item can be loaded
+func (l *interactionLookup) item(ctx context.Context, repo repository.ContentRepository, itemID uuid.UUID) error {
+	if err, ok := l.items[itemID]; ok {
+		return err
+	}
+	_, err := repo.GetContentItem(ctx, itemID)
+	l.items[itemID] = err
+	return err
+}
+
+func (l *interactionLookup) user(ctx context.Context, repo repository.UserRepository, userID uuid.UUID) (*db.User, error) {
+	if err, ok := l.userErrs[userID]; ok {
+		return nil, err
+	}
+	if user, ok := l.users[userID]; ok {
+		return user, nil
+	}
+
+	user, err := repo.GetUser(ctx, userID)
+	if err != nil {
+		l.userErrs[userID] = err
+		return nil, err
+	}
+	l.users[userID] = user
+	return user, nil
+}
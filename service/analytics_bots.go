@@ -0,0 +1,29 @@
+package service
+
+import (
+	"time"
+
+	"github.com/0xsj/mios.io/pkg/useragent"
+)
+
+const defaultUniqueClickWindow = 24 * time.Hour
+
+// AnalyticsConfig controls how incoming analytics are classified and counted.
+// BotPatterns are case-insensitive user agent substrings; events whose user
+// agent contains one are recorded but flagged as bot traffic, which the
+// dashboards and time range reports leave out unless asked to include it.
+// UniqueClickWindow is how long repeat clicks on an item from one IP address
+// count as a single unique click. MaxBatchSize caps how many clicks one
+// batch request may record. ReferrerParams are the query parameters that
+// override the Referer header, see attributedReferrer.
+type AnalyticsConfig struct {
+	BotPatterns       []string
+	UniqueClickWindow time.Duration
+	MaxBatchSize      int
+	ReferrerParams    []string
+}
+
+// isBot reports whether an event's user agent matches a bot pattern
+func (s *analyticsService) isBot(userAgent string) bool {
+	return useragent.IsBot(userAgent, s.config.BotPatterns)
+}
@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/0xsj/mios.io/pkg/errors"
+	"github.com/0xsj/mios.io/repository"
+	"github.com/google/uuid"
+)
+
+// Campaign group names for events that were not fully tagged: (direct)
+// covers events without any UTM tags, (not set) a tag missing from an
+// otherwise tagged event
+const (
+	CampaignDirect = "(direct)"
+	CampaignNotSet = "(not set)"
+)
+
+// maxUTMLength matches the width of the utm_* columns
+const maxUTMLength = 100
+
+// CampaignAnalyticsDTO splits a user's clicks and page views by the UTM
+// source, medium and campaign they arrived with
+type CampaignAnalyticsDTO struct {
+	UserID     string               `json:"user_id"`
+	StartDate  string               `json:"start_date"`
+	EndDate    string               `json:"end_date"`
+	TotalCount int64                `json:"total_count"`
+	Sources    []*BreakdownEntryDTO `json:"sources"`
+	Mediums    []*BreakdownEntryDTO `json:"mediums"`
+	Campaigns  []*BreakdownEntryDTO `json:"campaigns"`
+}
+
+func (s *analyticsService) GetCampaignAnalytics(ctx context.Context, userIDStr string, input TimeRangeInput) (*CampaignAnalyticsDTO, error) {
+	s.logger.Debugf("Getting campaign analytics for user ID: %s from %s to %s",
+		userIDStr, input.StartDate, input.EndDate)
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		s.logger.Warnf("Invalid user ID format: %v", err)
+		return nil, errors.NewBadRequestError("Invalid user ID format", err)
+	}
+
+	startDate, err := time.Parse(time.RFC3339, input.StartDate)
+	if err != nil {
+		s.logger.Warnf("Invalid start date format: %v", err)
+		return nil, errors.NewValidationError("Invalid start date format, expected RFC3339", err)
+	}
+
+	endDate, err := time.Parse(time.RFC3339, input.EndDate)
+	if err != nil {
+		s.logger.Warnf("Invalid end date format: %v", err)
+		return nil, errors.NewValidationError("Invalid end date format, expected RFC3339", err)
+	}
+
+	if endDate.Before(startDate) {
+		return nil, errors.NewValidationError("End date must be after start date", nil)
+	}
+
+	// Verify user exists
+	_, err = s.userRepo.GetUser(ctx, userID)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			s.logger.Warnf("User not found with ID: %s", userIDStr)
+			return nil, errors.NewNotFoundError("User not found", err)
+		}
+		s.logger.Errorf("Error retrieving user: %v", err)
+		return nil, errors.Wrap(err, "Failed to retrieve user")
+	}
+
+	counts, err := s.analyticsRepo.GetCampaignCounts(ctx, repository.TimeRangeParams{
+		UserID:      userID,
+		StartDate:   startDate,
+		EndDate:     endDate,
+		IncludeBots: input.IncludeBots,
+	})
+	if err != nil {
+		s.logger.Errorf("Failed to get campaign counts: %v", err)
+		return nil, errors.Wrap(err, "Failed to retrieve campaign data")
+	}
+
+	sources := make(map[string]int64)
+	mediums := make(map[string]int64)
+	campaigns := make(map[string]int64)
+	var totalCount int64
+
+	for _, count := range counts {
+		events := count.Clicks + count.PageViews
+		if count.Source == "" && count.Medium == "" && count.Campaign == "" {
+			sources[CampaignDirect] += events
+			mediums[CampaignDirect] += events
+			campaigns[CampaignDirect] += events
+		} else {
+			sources[campaignGroup(count.Source)] += events
+			mediums[campaignGroup(count.Medium)] += events
+			campaigns[campaignGroup(count.Campaign)] += events
+		}
+		totalCount += events
+	}
+
+	s.logger.Debugf("Grouped %d tag combinations covering %d events for user ID: %s",
+		len(counts), totalCount, userIDStr)
+
+	return &CampaignAnalyticsDTO{
+		UserID:     userIDStr,
+		StartDate:  input.StartDate,
+		EndDate:    input.EndDate,
+		TotalCount: totalCount,
+		Sources:    limitEntries(breakdownEntries(sources, totalCount), input.Limit),
+		Mediums:    limitEntries(breakdownEntries(mediums, totalCount), input.Limit),
+		Campaigns:  limitEntries(breakdownEntries(campaigns, totalCount), input.Limit),
+	}, nil
+}
+
+func campaignGroup(tag string) string {
+	if tag == "" {
+		return CampaignNotSet
+	}
+	return tag
+}
+
+// resolveUTM picks the campaign tags for an event. Tags given explicitly
+// win; otherwise they are read from the referrer's query string, which is
+// where a tagged link leaves them.
+func resolveUTM(source, medium, campaign, referrer string) repository.UTMParams {
+	utm := repository.UTMParams{
+		Source:   cleanUTM(source),
+		Medium:   cleanUTM(medium),
+		Campaign: cleanUTM(campaign),
+	}
+	if utm != (repository.UTMParams{}) || referrer == "" {
+		return utm
+	}
+
+	parsed, err := url.Parse(referrer)
+	if err != nil {
+		return utm
+	}
+	query := parsed.Query()
+	return repository.UTMParams{
+		Source:   cleanUTM(query.Get("utm_source")),
+		Medium:   cleanUTM(query.Get("utm_medium")),
+		Campaign: cleanUTM(query.Get("utm_campaign")),
+	}
+}
+
+// cleanUTM lowercases a tag so "Newsletter" and "newsletter" group
+// together, and trims it to fit the column
+func cleanUTM(tag string) string {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	if runes := []rune(tag); len(runes) > maxUTMLength {
+		tag = string(runes[:maxUTMLength])
+	}
+	return tag
+}
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/0xsj/mios.io/pkg/errors"
+	"github.com/0xsj/mios.io/repository"
+	"github.com/google/uuid"
+)
+
+// PeriodTotalsDTO holds the headline numbers for one period
+type PeriodTotalsDTO struct {
+	StartDate      string `json:"start_date"`
+	EndDate        string `json:"end_date"`
+	Views          int64  `json:"views"`
+	Clicks         int64  `json:"clicks"`
+	UniqueVisitors int64  `json:"unique_visitors"`
+}
+
+// DashboardComparisonDTO compares the dashboard period with the equally long
+// period just before it. A change is a percentage, and nil when the previous
+// period had nothing to compare against.
+type DashboardComparisonDTO struct {
+	Current              *PeriodTotalsDTO `json:"current"`
+	Previous             *PeriodTotalsDTO `json:"previous"`
+	ViewsChange          *float64         `json:"views_change"`
+	ClicksChange         *float64         `json:"clicks_change"`
+	UniqueVisitorsChange *float64         `json:"unique_visitors_change"`
+}
+
+// comparePeriods totals [start, end) and the same length of time before it
+func (s *analyticsService) comparePeriods(ctx context.Context, userID uuid.UUID, startDate, endDate time.Time, includeBots bool) (*DashboardComparisonDTO, error) {
+	previousStart := startDate.Add(-endDate.Sub(startDate))
+
+	current, err := s.analyticsRepo.GetPeriodTotals(ctx, repository.TimeRangeParams{
+		UserID:      userID,
+		StartDate:   startDate,
+		EndDate:     endDate,
+		IncludeBots: includeBots,
+	})
+	if err != nil {
+		s.logger.Errorf("Failed to get current period totals: %v", err)
+		return nil, errors.Wrap(err, "Failed to retrieve analytics totals")
+	}
+
+	previous, err := s.analyticsRepo.GetPeriodTotals(ctx, repository.TimeRangeParams{
+		UserID:      userID,
+		StartDate:   previousStart,
+		EndDate:     startDate,
+		IncludeBots: includeBots,
+	})
+	if err != nil {
+		s.logger.Errorf("Failed to get previous period totals: %v", err)
+		return nil, errors.Wrap(err, "Failed to retrieve analytics totals")
+	}
+
+	return &DashboardComparisonDTO{
+		Current:              mapPeriodTotalsToDTO(current, startDate, endDate),
+		Previous:             mapPeriodTotalsToDTO(previous, previousStart, startDate),
+		ViewsChange:          percentChange(float64(current.Views), float64(previous.Views)),
+		ClicksChange:         percentChange(float64(current.Clicks), float64(previous.Clicks)),
+		UniqueVisitorsChange: percentChange(float64(current.UniqueVisitors), float64(previous.UniqueVisitors)),
+	}, nil
+}
+
+func mapPeriodTotalsToDTO(totals *repository.PeriodTotals, startDate, endDate time.Time) *PeriodTotalsDTO {
+	return &PeriodTotalsDTO{
+		StartDate:      startDate.Format(time.RFC3339),
+		EndDate:        endDate.Format(time.RFC3339),
+		Views:          totals.Views,
+		Clicks:         totals.Clicks,
+		UniqueVisitors: totals.UniqueVisitors,
+	}
+}
@@ -0,0 +1,133 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/0xsj/mios.io/pkg/errors"
+	"github.com/0xsj/mios.io/pkg/useragent"
+	"github.com/0xsj/mios.io/repository"
+	"github.com/google/uuid"
+)
+
+// DeviceBreakdownDTO splits a user's analytics events by the visitor's
+// device type, operating system and browser family
+type DeviceBreakdownDTO struct {
+	UserID           string               `json:"user_id"`
+	StartDate        string               `json:"start_date"`
+	EndDate          string               `json:"end_date"`
+	TotalCount       int64                `json:"total_count"`
+	Devices          []*BreakdownEntryDTO `json:"devices"`
+	OperatingSystems []*BreakdownEntryDTO `json:"operating_systems"`
+	Browsers         []*BreakdownEntryDTO `json:"browsers"`
+}
+
+// BreakdownEntryDTO is one bucket of a breakdown, most common first
+type BreakdownEntryDTO struct {
+	Name       string  `json:"name"`
+	Count      int64   `json:"count"`
+	Percentage float64 `json:"percentage"`
+}
+
+func (s *analyticsService) GetDeviceBreakdown(ctx context.Context, userIDStr string, input TimeRangeInput) (*DeviceBreakdownDTO, error) {
+	s.logger.Debugf("Getting device breakdown for user ID: %s from %s to %s",
+		userIDStr, input.StartDate, input.EndDate)
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		s.logger.Warnf("Invalid user ID format: %v", err)
+		return nil, errors.NewBadRequestError("Invalid user ID format", err)
+	}
+
+	startDate, err := time.Parse(time.RFC3339, input.StartDate)
+	if err != nil {
+		s.logger.Warnf("Invalid start date format: %v", err)
+		return nil, errors.NewValidationError("Invalid start date format, expected RFC3339", err)
+	}
+
+	endDate, err := time.Parse(time.RFC3339, input.EndDate)
+	if err != nil {
+		s.logger.Warnf("Invalid end date format: %v", err)
+		return nil, errors.NewValidationError("Invalid end date format, expected RFC3339", err)
+	}
+
+	if endDate.Before(startDate) {
+		return nil, errors.NewValidationError("End date must be after start date", nil)
+	}
+
+	// Verify user exists
+	_, err = s.userRepo.GetUser(ctx, userID)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			s.logger.Warnf("User not found with ID: %s", userIDStr)
+			return nil, errors.NewNotFoundError("User not found", err)
+		}
+		s.logger.Errorf("Error retrieving user: %v", err)
+		return nil, errors.Wrap(err, "Failed to retrieve user")
+	}
+
+	// Counts come back per distinct user agent, so each string is parsed
+	// once however many events share it
+	agents, err := s.analyticsRepo.GetUserAgentCounts(ctx, repository.TimeRangeParams{
+		UserID:      userID,
+		StartDate:   startDate,
+		EndDate:     endDate,
+		IncludeBots: input.IncludeBots,
+	})
+	if err != nil {
+		s.logger.Errorf("Failed to get user agent counts: %v", err)
+		return nil, errors.Wrap(err, "Failed to retrieve device data")
+	}
+
+	devices := make(map[string]int64)
+	systems := make(map[string]int64)
+	browsers := make(map[string]int64)
+	var totalCount int64
+
+	for _, agent := range agents {
+		info := useragent.Parse(agent.UserAgent)
+		devices[info.DeviceType] += agent.Count
+		systems[info.OS] += agent.Count
+		browsers[info.Browser] += agent.Count
+		totalCount += agent.Count
+	}
+
+	s.logger.Debugf("Parsed %d distinct user agents covering %d events for user ID: %s",
+		len(agents), totalCount, userIDStr)
+
+	return &DeviceBreakdownDTO{
+		UserID:           userIDStr,
+		StartDate:        input.StartDate,
+		EndDate:          input.EndDate,
+		TotalCount:       totalCount,
+		Devices:          breakdownEntries(devices, totalCount),
+		OperatingSystems: breakdownEntries(systems, totalCount),
+		Browsers:         breakdownEntries(browsers, totalCount),
+	}, nil
+}
+
+// breakdownEntries orders buckets by count, then name so ties are stable
+func breakdownEntries(counts map[string]int64, total int64) []*BreakdownEntryDTO {
+	entries := make([]*BreakdownEntryDTO, 0, len(counts))
+	for name, count := range counts {
+		var percentage float64
+		if total > 0 {
+			percentage = float64(count) / float64(total) * 100
+		}
+		entries = append(entries, &BreakdownEntryDTO{
+			Name:       name,
+			Count:      count,
+			Percentage: percentage,
+		})
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Count != entries[j].Count {
+			return entries[i].Count > entries[j].Count
+		}
+		return entries[i].Name < entries[j].Name
+	})
+
+	return entries
+}
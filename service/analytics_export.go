@@ -0,0 +1,373 @@
+package service
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	db "github.com/0xsj/mios.io/db/sqlc"
+	"github.com/0xsj/mios.io/pkg/errors"
+	"github.com/0xsj/mios.io/pkg/ptr"
+	"github.com/0xsj/mios.io/pkg/storage"
+	"github.com/0xsj/mios.io/repository"
+	"github.com/google/uuid"
+)
+
+// Export formats
+const (
+	ExportFormatJSON = "json"
+	ExportFormatCSV  = "csv"
+)
+
+// ExportJobPending is the state of a background export that has been
+// accepted but not yet delivered
+const ExportJobPending = "pending"
+
+const (
+	defaultExportBatchSize = 1000
+	defaultExportLinkTTL   = 24 * time.Hour
+)
+
+// AnalyticsExportConfig controls how raw analytics exports are produced.
+// BatchSize is the number of rows read per query; LinkTTL is how long the
+// emailed download link for an asynchronous export stays valid.
+type AnalyticsExportConfig struct {
+	BatchSize int
+	LinkTTL   time.Duration
+}
+
+type ExportInput struct {
+	StartDate string `json:"start_date"`
+	EndDate   string `json:"end_date"`
+	Format    string `json:"format"`
+}
+
+// ExportJobDTO describes an export being generated in the background. The
+// download link is emailed to the user once the file is ready.
+type ExportJobDTO struct {
+	ID          string `json:"id"`
+	UserID      string `json:"user_id"`
+	Format      string `json:"format"`
+	StartDate   string `json:"start_date"`
+	EndDate     string `json:"end_date"`
+	Status      string `json:"status"`
+	RequestedAt string `json:"requested_at"`
+}
+
+// analyticsExport is a validated export request
+type analyticsExport struct {
+	user      *db.User
+	format    string
+	startDate time.Time
+	endDate   time.Time
+}
+
+// exportWriter encodes analytics rows in one export format
+type exportWriter interface {
+	Begin() error
+	Write(row *db.ListUserAnalyticsForExportRow) error
+	End() error
+}
+
+func (s *analyticsService) ExportUserAnalytics(ctx context.Context, userIDStr string, input ExportInput) (io.ReadCloser, error) {
+	s.logger.Infof("Exporting analytics for user ID: %s from %s to %s", userIDStr, input.StartDate, input.EndDate)
+
+	export, err := s.prepareExport(ctx, userIDStr, input)
+	if err != nil {
+		return nil, err
+	}
+
+	// Rows are written to the pipe a batch at a time as the caller reads,
+	// so only one batch is ever held in memory
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(s.writeExport(ctx, pw, export))
+	}()
+
+	return pr, nil
+}
+
+func (s *analyticsService) StartUserAnalyticsExport(ctx context.Context, userIDStr string, input ExportInput) (*ExportJobDTO, error) {
+	s.logger.Infof("Starting background analytics export for user ID: %s from %s to %s", userIDStr, input.StartDate, input.EndDate)
+
+	if s.exportStorage == nil || s.emailClient == nil {
+		return nil, errors.NewInternalError("Background exports are not available", nil)
+	}
+
+	export, err := s.prepareExport(ctx, userIDStr, input)
+	if err != nil {
+		return nil, err
+	}
+
+	job := &ExportJobDTO{
+		ID:          uuid.New().String(),
+		UserID:      userIDStr,
+		Format:      export.format,
+		StartDate:   export.startDate.Format(time.RFC3339),
+		EndDate:     export.endDate.Format(time.RFC3339),
+		Status:      ExportJobPending,
+		RequestedAt: time.Now().Format(time.RFC3339),
+	}
+
+	go func() {
+		if err := s.runBackgroundExport(context.Background(), job, export); err != nil {
+			s.logger.Errorf("Analytics export job %s failed: %v", job.ID, err)
+			return
+		}
+		s.logger.Infof("Analytics export job %s completed", job.ID)
+	}()
+
+	return job, nil
+}
+
+// prepareExport validates the request before anything is written, so
+// errors can still be reported as a normal response
+func (s *analyticsService) prepareExport(ctx context.Context, userIDStr string, input ExportInput) (*analyticsExport, error) {
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		s.logger.Warnf("Invalid user ID format: %v", err)
+		return nil, errors.NewBadRequestError("Invalid user ID format", err)
+	}
+
+	format := input.Format
+	if format == "" {
+		format = ExportFormatJSON
+	}
+	if format != ExportFormatJSON && format != ExportFormatCSV {
+		s.logger.Warnf("Unsupported export format: %s", format)
+		return nil, errors.NewValidationError("Unsupported export format, expected json or csv", nil)
+	}
+
+	startDate, err := time.Parse(time.RFC3339, input.StartDate)
+	if err != nil {
+		s.logger.Warnf("Invalid start date format: %v", err)
+		return nil, errors.NewValidationError("Invalid start date format, expected RFC3339", err)
+	}
+
+	endDate, err := time.Parse(time.RFC3339, input.EndDate)
+	if err != nil {
+		s.logger.Warnf("Invalid end date format: %v", err)
+		return nil, errors.NewValidationError("Invalid end date format, expected RFC3339", err)
+	}
+
+	if endDate.Before(startDate) {
+		return nil, errors.NewValidationError("End date must be after start date", nil)
+	}
+
+	user, err := s.userRepo.GetUser(ctx, userID)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			s.logger.Warnf("User not found with ID: %s", userIDStr)
+			return nil, errors.NewNotFoundError("User not found", err)
+		}
+		s.logger.Errorf("Error retrieving user: %v", err)
+		return nil, errors.Wrap(err, "Failed to retrieve user")
+	}
+
+	return &analyticsExport{
+		user:      user,
+		format:    format,
+		startDate: startDate,
+		endDate:   endDate,
+	}, nil
+}
+
+// writeExport pages through the user's analytics with a keyset cursor and
+// encodes each batch to w, flushing between batches
+func (s *analyticsService) writeExport(ctx context.Context, w io.Writer, export *analyticsExport) error {
+	buffered := bufio.NewWriter(w)
+	encoder := newExportWriter(export.format, buffered)
+
+	if err := encoder.Begin(); err != nil {
+		return err
+	}
+
+	params := repository.ExportPageParams{
+		UserID:    export.user.UserID,
+		StartDate: export.startDate,
+		EndDate:   export.endDate,
+		Limit:     s.exportConfig.BatchSize,
+	}
+
+	var written int
+	for {
+		if err := ctx.Err(); err != nil {
+			return errors.Wrap(err, "analytics export cancelled")
+		}
+
+		rows, err := s.analyticsRepo.ListAnalyticsForExport(ctx, params)
+		if err != nil {
+			s.logger.Errorf("Failed to read analytics batch for export: %v", err)
+			return err
+		}
+
+		for _, row := range rows {
+			if err := encoder.Write(row); err != nil {
+				return err
+			}
+		}
+		written += len(rows)
+
+		if err := buffered.Flush(); err != nil {
+			return err
+		}
+
+		if len(rows) < params.Limit {
+			break
+		}
+
+		last := rows[len(rows)-1].Analytic
+		params.AfterClickedAt = *last.ClickedAt
+		params.AfterID = last.AnalyticsID
+	}
+
+	if err := encoder.End(); err != nil {
+		return err
+	}
+
+	s.logger.Debugf("Exported %d analytics entries for user ID: %s", written, export.user.UserID)
+	return buffered.Flush()
+}
+
+// runBackgroundExport compresses the export into storage and emails the
+// user a time-limited link to it
+func (s *analyticsService) runBackgroundExport(ctx context.Context, job *ExportJobDTO, export *analyticsExport) error {
+	key := fmt.Sprintf("exports/%s/analytics-%s.%s.gz", export.user.UserID, job.ID, export.format)
+
+	pr, pw := io.Pipe()
+	go func() {
+		compressed := gzip.NewWriter(pw)
+		err := s.writeExport(ctx, compressed, export)
+		if err == nil {
+			err = compressed.Close()
+		}
+		pw.CloseWithError(err)
+	}()
+
+	_, err := s.exportStorage.Upload(ctx, key, pr, storage.UploadOptions{
+		ContentType: "application/gzip",
+		ACL:         "private",
+		Metadata: map[string]string{
+			"user-id":  export.user.UserID.String(),
+			"category": "export",
+		},
+	})
+	// Unblock the writer if the upload stopped reading early
+	pr.CloseWithError(err)
+	if err != nil {
+		return errors.Wrap(err, "Failed to store analytics export")
+	}
+
+	link, err := s.exportStorage.GetURL(ctx, key, storage.GetURLOptions{Expires: s.exportConfig.LinkTTL})
+	if err != nil {
+		return errors.Wrap(err, "Failed to create export download link")
+	}
+
+	data := map[string]interface{}{
+		"Username":  export.user.Username,
+		"Link":      link,
+		"AppName":   "Your App Name",
+		"Year":      time.Now().Year(),
+		"StartDate": export.startDate.Format("January 2, 2006"),
+		"EndDate":   export.endDate.Format("January 2, 2006"),
+		"ExpiresAt": time.Now().Add(s.exportConfig.LinkTTL).Format("January 2, 2006 15:04 MST"),
+	}
+
+	return s.emailClient.SendLocalizedTemplate([]string{export.user.Email}, "Your Analytics Export Is Ready",
+		"analytics_export_ready.html", ptr.GetValueOrEmpty(export.user.Locale), data)
+}
+
+func newExportWriter(format string, w io.Writer) exportWriter {
+	if format == ExportFormatCSV {
+		return &csvExportWriter{w: csv.NewWriter(w)}
+	}
+	return &jsonExportWriter{w: w, encoder: json.NewEncoder(w)}
+}
+
+// jsonExportWriter streams rows as a single JSON array
+type jsonExportWriter struct {
+	w       io.Writer
+	encoder *json.Encoder
+	count   int
+}
+
+func (e *jsonExportWriter) Begin() error {
+	_, err := io.WriteString(e.w, "[")
+	return err
+}
+
+func (e *jsonExportWriter) Write(row *db.ListUserAnalyticsForExportRow) error {
+	if e.count > 0 {
+		if _, err := io.WriteString(e.w, ","); err != nil {
+			return err
+		}
+	}
+	e.count++
+	return e.encoder.Encode(mapAnalyticToDTO(&row.Analytic))
+}
+
+func (e *jsonExportWriter) End() error {
+	_, err := io.WriteString(e.w, "]\n")
+	return err
+}
+
+// csvExportHeader names the columns written by csvExportWriter
+var csvExportHeader = []string{"item_id", "content_type", "clicked_at", "ip_address", "user_agent", "referrer", "page_view"}
+
+// csvExportWriter streams rows as CSV with a header line. The csv.Writer
+// buffers internally; rows reach w when the caller's buffer is flushed
+// after each batch.
+type csvExportWriter struct {
+	w *csv.Writer
+}
+
+func (e *csvExportWriter) Begin() error {
+	return e.w.Write(csvExportHeader)
+}
+
+func (e *csvExportWriter) Write(row *db.ListUserAnalyticsForExportRow) error {
+	a := row.Analytic
+
+	var clickedAt string
+	if a.ClickedAt != nil {
+		clickedAt = a.ClickedAt.Format(time.RFC3339)
+	}
+
+	record := []string{
+		a.ItemID.String(),
+		ptr.GetValueOrEmpty(row.ContentType),
+		clickedAt,
+		ptr.GetValueOrEmpty(a.IpAddress),
+		csvSafe(ptr.GetValueOrEmpty(a.UserAgent)),
+		csvSafe(ptr.GetValueOrEmpty(a.Referrer)),
+		strconv.FormatBool(a.PageView != nil && *a.PageView),
+	}
+	if err := e.w.Write(record); err != nil {
+		return err
+	}
+
+	// Hand the row to the batch buffer so it is written out with the batch
+	e.w.Flush()
+	return e.w.Error()
+}
+
+func (e *csvExportWriter) End() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+// csvSafe keeps visitor-supplied text from being read as a formula when the
+// file is opened in a spreadsheet
+func csvSafe(value string) string {
+	if value != "" && strings.ContainsAny(value[:1], "=+-@\t\r") {
+		return "'" + value
+	}
+	return value
+}
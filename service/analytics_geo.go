@@ -0,0 +1,131 @@
+package service
+
+import (
+	"context"
+	"net"
+	"time"
+
+	"github.com/0xsj/mios.io/pkg/errors"
+	"github.com/0xsj/mios.io/pkg/geoip"
+	"github.com/0xsj/mios.io/repository"
+	"github.com/google/uuid"
+)
+
+// GeoAnalyticsDTO splits a user's analytics events by the country and city
+// the visitor's IP address resolves to
+type GeoAnalyticsDTO struct {
+	UserID     string               `json:"user_id"`
+	StartDate  string               `json:"start_date"`
+	EndDate    string               `json:"end_date"`
+	TotalCount int64                `json:"total_count"`
+	Countries  []*BreakdownEntryDTO `json:"countries"`
+	Cities     []*BreakdownEntryDTO `json:"cities"`
+}
+
+func (s *analyticsService) GetGeoAnalytics(ctx context.Context, userIDStr string, input TimeRangeInput) (*GeoAnalyticsDTO, error) {
+	s.logger.Debugf("Getting geographic analytics for user ID: %s from %s to %s",
+		userIDStr, input.StartDate, input.EndDate)
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		s.logger.Warnf("Invalid user ID format: %v", err)
+		return nil, errors.NewBadRequestError("Invalid user ID format", err)
+	}
+
+	startDate, err := time.Parse(time.RFC3339, input.StartDate)
+	if err != nil {
+		s.logger.Warnf("Invalid start date format: %v", err)
+		return nil, errors.NewValidationError("Invalid start date format, expected RFC3339", err)
+	}
+
+	endDate, err := time.Parse(time.RFC3339, input.EndDate)
+	if err != nil {
+		s.logger.Warnf("Invalid end date format: %v", err)
+		return nil, errors.NewValidationError("Invalid end date format, expected RFC3339", err)
+	}
+
+	if endDate.Before(startDate) {
+		return nil, errors.NewValidationError("End date must be after start date", nil)
+	}
+
+	// Verify user exists
+	_, err = s.userRepo.GetUser(ctx, userID)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			s.logger.Warnf("User not found with ID: %s", userIDStr)
+			return nil, errors.NewNotFoundError("User not found", err)
+		}
+		s.logger.Errorf("Error retrieving user: %v", err)
+		return nil, errors.Wrap(err, "Failed to retrieve user")
+	}
+
+	addresses, err := s.analyticsRepo.GetIPAddressCounts(ctx, repository.TimeRangeParams{
+		UserID:      userID,
+		StartDate:   startDate,
+		EndDate:     endDate,
+		IncludeBots: input.IncludeBots,
+	})
+	if err != nil {
+		s.logger.Errorf("Failed to get IP address counts: %v", err)
+		return nil, errors.Wrap(err, "Failed to retrieve geographic data")
+	}
+
+	countries := make(map[string]int64)
+	cities := make(map[string]int64)
+	var totalCount int64
+
+	for _, address := range addresses {
+		location := s.locate(address.IPAddress)
+		countries[location.Country] += address.Count
+		cities[cityName(location)] += address.Count
+		totalCount += address.Count
+	}
+
+	s.logger.Debugf("Located %d distinct IP addresses covering %d events for user ID: %s",
+		len(addresses), totalCount, userIDStr)
+
+	return &GeoAnalyticsDTO{
+		UserID:     userIDStr,
+		StartDate:  input.StartDate,
+		EndDate:    input.EndDate,
+		TotalCount: totalCount,
+		Countries:  limitEntries(breakdownEntries(countries, totalCount), input.Limit),
+		Cities:     limitEntries(breakdownEntries(cities, totalCount), input.Limit),
+	}, nil
+}
+
+// locate resolves an address, reporting Unknown for anything that cannot be
+// placed: unparseable, IPv6, private or reserved addresses, and misses
+func (s *analyticsService) locate(address string) geoip.Location {
+	unknown := geoip.Location{Country: geoip.Unknown}
+
+	ip := net.ParseIP(address)
+	if ip == nil || ip.To4() == nil || s.geoLookup == nil {
+		return unknown
+	}
+	if ip.IsPrivate() || ip.IsLoopback() || ip.IsLinkLocalUnicast() || ip.IsUnspecified() {
+		return unknown
+	}
+
+	location, ok := s.geoLookup.Lookup(ip)
+	if !ok || location.Country == "" {
+		return unknown
+	}
+	return location
+}
+
+// cityName qualifies a city with its country so same-named cities in
+// different countries stay separate
+func cityName(location geoip.Location) string {
+	if location.City == "" || location.Country == geoip.Unknown {
+		return geoip.Unknown
+	}
+	return location.City + ", " + location.Country
+}
+
+func limitEntries(entries []*BreakdownEntryDTO, limit int) []*BreakdownEntryDTO {
+	if limit > 0 && len(entries) > limit {
+		return entries[:limit]
+	}
+	return entries
+}
@@ -0,0 +1,82 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/0xsj/mios.io/pkg/errors"
+	"github.com/0xsj/mios.io/pkg/live"
+	"github.com/google/uuid"
+)
+
+// Live event types
+const (
+	LiveEventInteraction = "interaction"
+	LiveEventPageView    = "page_view"
+)
+
+// LiveEventDTO is pushed to the owner's live stream each time a visitor
+// interaction or page view is recorded
+type LiveEventDTO struct {
+	Type            string `json:"type"`
+	ItemID          string `json:"item_id"`
+	InteractionType string `json:"interaction_type,omitempty"`
+	Referrer        string `json:"referrer,omitempty"`
+	IsBot           bool   `json:"is_bot"`
+	OccurredAt      string `json:"occurred_at"`
+}
+
+// SubscribeLiveEvents opens a subscription to the user's live events. The
+// caller must close it when the client goes away.
+func (s *analyticsService) SubscribeLiveEvents(ctx context.Context, userIDStr string) (live.Subscription, error) {
+	s.logger.Debugf("Subscribing to live analytics for user ID: %s", userIDStr)
+
+	if s.liveEvents == nil {
+		return nil, errors.NewServiceUnavailableError("Live analytics are not available", nil)
+	}
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		s.logger.Warnf("Invalid user ID format: %v", err)
+		return nil, errors.NewBadRequestError("Invalid user ID format", err)
+	}
+
+	// Verify user exists
+	_, err = s.userRepo.GetUser(ctx, userID)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			s.logger.Warnf("User not found with ID: %s", userIDStr)
+			return nil, errors.NewNotFoundError("User not found", err)
+		}
+		s.logger.Errorf("Error retrieving user: %v", err)
+		return nil, errors.Wrap(err, "Failed to retrieve user")
+	}
+
+	sub, err := s.liveEvents.Subscribe(ctx, userID.String())
+	if err != nil {
+		s.logger.Errorf("Failed to subscribe to live analytics: %v", err)
+		return nil, errors.NewServiceUnavailableError("Failed to subscribe to live analytics", err)
+	}
+
+	return sub, nil
+}
+
+// publishLiveEvent pushes an event to the owner's live stream without
+// failing the recording it describes
+func (s *analyticsService) publishLiveEvent(ctx context.Context, userID uuid.UUID, event LiveEventDTO) {
+	if s.liveEvents == nil {
+		return
+	}
+
+	event.OccurredAt = time.Now().UTC().Format(time.RFC3339)
+	payload, err := json.Marshal(event)
+	if err != nil {
+		s.logger.Warnf("Failed to encode live %s event: %v", event.Type, err)
+		return
+	}
+
+	if err := s.liveEvents.Publish(ctx, userID.String(), payload); err != nil {
+		s.logger.Warnf("Failed to publish live %s event for user ID %s: %v", event.Type, userID, err)
+	}
+}
@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/0xsj/mios.io/pkg/errors"
+	"github.com/google/uuid"
+)
+
+// Bounds on how many clicks the recent clicks feed returns
+const (
+	defaultRecentClicks = 20
+	maxRecentClicks     = 100
+)
+
+// RecentClickDTO is one entry in a user's recent clicks feed. Bot clicks
+// are left out; Country is only set when geolocation is enabled.
+type RecentClickDTO struct {
+	ItemID          string `json:"item_id"`
+	ItemTitle       string `json:"item_title"`
+	InteractionType string `json:"interaction_type"`
+	Referrer        string `json:"referrer,omitempty"`
+	Channel         string `json:"channel"`
+	Country         string `json:"country,omitempty"`
+	ClickedAt       string `json:"clicked_at"`
+	RelativeTime    string `json:"relative_time"`
+}
+
+// GetRecentClicks returns the user's latest clicks, newest first. It reads
+// the raw analytics through a dedicated index instead of the rollups, so
+// clicks show up as soon as they are recorded.
+func (s *analyticsService) GetRecentClicks(ctx context.Context, userIDStr string, limit int) ([]*RecentClickDTO, error) {
+	s.logger.Debugf("Getting recent clicks for user ID: %s", userIDStr)
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		s.logger.Warnf("Invalid user ID format: %v", err)
+		return nil, errors.NewBadRequestError("Invalid user ID format", err)
+	}
+
+	if limit <= 0 {
+		limit = defaultRecentClicks
+	}
+	if limit > maxRecentClicks {
+		limit = maxRecentClicks
+	}
+
+	// Verify user exists
+	_, err = s.userRepo.GetUser(ctx, userID)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			s.logger.Warnf("User not found with ID: %s", userIDStr)
+			return nil, errors.NewNotFoundError("User not found", err)
+		}
+		s.logger.Errorf("Error retrieving user: %v", err)
+		return nil, errors.Wrap(err, "Failed to retrieve user")
+	}
+
+	rows, err := s.analyticsRepo.ListRecentClicks(ctx, userID, limit)
+	if err != nil {
+		s.logger.Errorf("Failed to list recent clicks: %v", err)
+		return nil, errors.Wrap(err, "Failed to retrieve recent clicks")
+	}
+
+	now := time.Now()
+	clicks := make([]*RecentClickDTO, len(rows))
+	for i, row := range rows {
+		click := &RecentClickDTO{
+			ItemID:          row.ItemID.String(),
+			ItemTitle:       row.Title,
+			InteractionType: row.InteractionType,
+			Referrer:        row.Referrer,
+			Channel:         referrerChannel(row.Referrer),
+		}
+		if s.geoLookup != nil {
+			click.Country = s.locate(row.IpAddress).Country
+		}
+		if row.ClickedAt != nil {
+			click.ClickedAt = row.ClickedAt.UTC().Format(time.RFC3339)
+			click.RelativeTime = relativeTime(now.Sub(*row.ClickedAt))
+		}
+		clicks[i] = click
+	}
+
+	s.logger.Debugf("Retrieved %d recent clicks for user ID: %s", len(clicks), userIDStr)
+	return clicks, nil
+}
+
+// referrerChannel names where a click came from: the referring site without
+// "www.", the tag for referrers named in the query string (which are stored
+// without a scheme), or CampaignDirect when there is no referrer
+func referrerChannel(referrer string) string {
+	if referrer == "" {
+		return CampaignDirect
+	}
+	if parsed, err := url.Parse(referrer); err == nil && parsed.Hostname() != "" {
+		return strings.TrimPrefix(strings.ToLower(parsed.Hostname()), "www.")
+	}
+	return referrer
+}
+
+// relativeTime describes an elapsed duration the way an activity feed does,
+// e.g. "just now", "5m ago" or "3d ago"
+func relativeTime(elapsed time.Duration) string {
+	switch {
+	case elapsed < time.Minute:
+		return "just now"
+	case elapsed < time.Hour:
+		return fmt.Sprintf("%dm ago", int(elapsed/time.Minute))
+	case elapsed < 24*time.Hour:
+		return fmt.Sprintf("%dh ago", int(elapsed/time.Hour))
+	default:
+		return fmt.Sprintf("%dd ago", int(elapsed/(24*time.Hour)))
+	}
+}
@@ -3,11 +3,19 @@ package service
 import (
 	"context"
 	"fmt"
+	"io"
+	"net/url"
+	"sync"
 	"time"
 
 	db "github.com/0xsj/mios.io/db/sqlc"
 	"github.com/0xsj/mios.io/log"
+	"github.com/0xsj/mios.io/pkg/email"
 	"github.com/0xsj/mios.io/pkg/errors"
+	"github.com/0xsj/mios.io/pkg/geoip"
+	"github.com/0xsj/mios.io/pkg/live"
+	"github.com/0xsj/mios.io/pkg/storage"
+	"github.com/0xsj/mios.io/pkg/useragent"
 	"github.com/0xsj/mios.io/repository"
 	"github.com/google/uuid"
 )
@@ -15,7 +23,10 @@ import (
 type AnalyticsService interface {
 	// Recording data
 	RecordClick(ctx context.Context, input RecordClickInput) error
+	RecordInteraction(ctx context.Context, input RecordClickInput) error
+	RecordClicksBatch(ctx context.Context, inputs []RecordClickInput) (*BatchResult, error)
 	RecordPageView(ctx context.Context, input RecordPageViewInput) error
+	SubscribeLiveEvents(ctx context.Context, userID string) (live.Subscription, error)
 
 	// Basic analytics
 	GetContentItemAnalytics(ctx context.Context, itemID string, page, pageSize int) (*ContentItemAnalyticsDTO, error)
@@ -25,20 +36,76 @@ type AnalyticsService interface {
 	GetUserAnalyticsByTimeRange(ctx context.Context, userID string, input TimeRangeInput) (*TimeRangeAnalyticsDTO, error)
 	GetItemAnalyticsByTimeRange(ctx context.Context, itemID string, input TimeRangeInput) (*ItemTimeRangeAnalyticsDTO, error)
 	GetProfilePageViewsByTimeRange(ctx context.Context, userID string, input TimeRangeInput) (*PageViewAnalyticsDTO, error)
+	GetAnalyticsForDates(ctx context.Context, userID string, dates []string) (map[string]int64, error)
 
 	// Dashboard analytics
-	GetProfileDashboard(ctx context.Context, userID string, days int) (*ProfileDashboardDTO, error)
+	GetProfileDashboard(ctx context.Context, userID string, days int, includeBots bool) (*ProfileDashboardDTO, error)
+	GetSummaryCards(ctx context.Context, userID string, days int, includeBots bool) (*SummaryCardsDTO, error)
 
 	// Referrer analytics
 	GetReferrerAnalytics(ctx context.Context, userID string, input TimeRangeInput) (*ReferrerAnalyticsDTO, error)
+	GetDeviceBreakdown(ctx context.Context, userID string, input TimeRangeInput) (*DeviceBreakdownDTO, error)
+	GetGeoAnalytics(ctx context.Context, userID string, input TimeRangeInput) (*GeoAnalyticsDTO, error)
+	GetCampaignAnalytics(ctx context.Context, userID string, input TimeRangeInput) (*CampaignAnalyticsDTO, error)
+
+	// Recent activity
+	GetRecentClicks(ctx context.Context, userID string, limit int) ([]*RecentClickDTO, error)
+
+	// Rollup maintenance
+	RebuildRollups(ctx context.Context, userID string, start, end time.Time) error
+	StartRollupRebuild(userID string, start, end time.Time) (*RollupJobDTO, error)
+	GetRollupJob(jobID string) (*RollupJobDTO, error)
+	StartRollupRefresh(ctx context.Context, interval time.Duration)
+
+	// Raw data export
+	ExportUserAnalytics(ctx context.Context, userID string, input ExportInput) (io.ReadCloser, error)
+	StartUserAnalyticsExport(ctx context.Context, userID string, input ExportInput) (*ExportJobDTO, error)
+}
+
+// Interaction types that can be recorded against a content item
+const (
+	InteractionClick  = "click"
+	InteractionCopy   = "copy"
+	InteractionShare  = "share"
+	InteractionSubmit = "submit"
+)
+
+// Bucket sizes for time range analytics
+const (
+	GranularityHour = "hour"
+	GranularityDay  = "day"
+	GranularityWeek = "week"
+)
+
+var validGranularities = map[string]bool{
+	GranularityHour: true,
+	GranularityDay:  true,
+	GranularityWeek: true,
+}
+
+var validInteractionTypes = map[string]bool{
+	InteractionClick:  true,
+	InteractionCopy:   true,
+	InteractionShare:  true,
+	InteractionSubmit: true,
 }
 
 type RecordClickInput struct {
-	ItemID    string `json:"item_id" binding:"required"`
-	UserID    string `json:"user_id" binding:"required"`
-	IPAddress string `json:"ip_address"`
-	UserAgent string `json:"user_agent"`
-	Referrer  string `json:"referrer"`
+	ItemID          string `json:"item_id" binding:"required"`
+	UserID          string `json:"user_id" binding:"required"`
+	IPAddress       string `json:"ip_address"`
+	UserAgent       string `json:"user_agent"`
+	Referrer        string `json:"referrer"`
+	InteractionType string `json:"interaction_type"` // Defaults to "click"
+
+	// Campaign tags; when all are empty they are read from the referrer
+	UTMSource   string `json:"utm_source"`
+	UTMMedium   string `json:"utm_medium"`
+	UTMCampaign string `json:"utm_campaign"`
+
+	// Query string of the tracked request; a referrer named in it overrides
+	// Referrer and its UTM tags fill in for missing ones
+	Query url.Values `json:"-"`
 }
 
 type RecordPageViewInput struct {
@@ -47,19 +114,39 @@ type RecordPageViewInput struct {
 	IPAddress string `json:"ip_address"`
 	UserAgent string `json:"user_agent"`
 	Referrer  string `json:"referrer"`
+
+	// Campaign tags; when all are empty they are read from the referrer
+	UTMSource   string `json:"utm_source"`
+	UTMMedium   string `json:"utm_medium"`
+	UTMCampaign string `json:"utm_campaign"`
+
+	// Query string of the tracked request; a referrer named in it overrides
+	// Referrer and its UTM tags fill in for missing ones
+	Query url.Values `json:"-"`
 }
 
 type TimeRangeInput struct {
-	StartDate string `json:"start_date" binding:"required"`
-	EndDate   string `json:"end_date" binding:"required"`
-	Limit     int    `json:"limit"`
+	StartDate   string `json:"start_date" binding:"required"`
+	EndDate     string `json:"end_date" binding:"required"`
+	Limit       int    `json:"limit"`
+	IncludeBots bool   `json:"include_bots"` // Bot traffic is excluded by default
+	// Granularity buckets user time range analytics by "hour", "day" (the
+	// default) or "week"
+	Granularity string `json:"granularity"`
 }
 
 // Output types (DTOs)
 type ContentItemAnalyticsDTO struct {
-	ItemID      string          `json:"item_id"`
-	TotalClicks int64           `json:"total_clicks"`
-	ClickData   []*AnalyticsDTO `json:"click_data"`
+	ItemID       string                 `json:"item_id"`
+	TotalClicks  int64                  `json:"total_clicks"`
+	UniqueClicks int64                  `json:"unique_clicks"` // One per IP address per unique click window
+	Interactions []*InteractionStatsDTO `json:"interactions"`
+	ClickData    []*AnalyticsDTO        `json:"click_data"`
+}
+
+type InteractionStatsDTO struct {
+	InteractionType string `json:"interaction_type"`
+	Count           int64  `json:"count"`
 }
 
 type UserAnalyticsDTO struct {
@@ -69,20 +156,23 @@ type UserAnalyticsDTO struct {
 }
 
 type AnalyticsDTO struct {
-	ID        string `json:"id"`
-	ItemID    string `json:"item_id"`
-	UserID    string `json:"user_id"`
-	IPAddress string `json:"ip_address,omitempty"`
-	UserAgent string `json:"user_agent,omitempty"`
-	Referrer  string `json:"referrer,omitempty"`
-	PageView  bool   `json:"page_view"`
-	ClickedAt string `json:"clicked_at"`
+	ID              string `json:"id"`
+	ItemID          string `json:"item_id"`
+	UserID          string `json:"user_id"`
+	IPAddress       string `json:"ip_address,omitempty"`
+	UserAgent       string `json:"user_agent,omitempty"`
+	Referrer        string `json:"referrer,omitempty"`
+	InteractionType string `json:"interaction_type,omitempty"`
+	PageView        bool   `json:"page_view"`
+	IsBot           bool   `json:"is_bot"`
+	ClickedAt       string `json:"clicked_at"`
 }
 
 type TimeRangeAnalyticsDTO struct {
 	UserID      string               `json:"user_id"`
 	StartDate   string               `json:"start_date"`
 	EndDate     string               `json:"end_date"`
+	Granularity string               `json:"granularity"`
 	TotalClicks int64                `json:"total_clicks"`
 	DailyClicks []*DailyAnalyticsDTO `json:"daily_clicks"`
 }
@@ -103,6 +193,9 @@ type PageViewAnalyticsDTO struct {
 	DailyViews []*DailyAnalyticsDTO `json:"daily_views"`
 }
 
+// DailyAnalyticsDTO counts clicks in one bucket. Date is YYYY-MM-DD for day
+// and week buckets (weeks start on Monday) and an RFC3339 timestamp for hour
+// buckets.
 type DailyAnalyticsDTO struct {
 	Date      string `json:"date"`
 	DayOfWeek string `json:"day_of_week"`
@@ -120,13 +213,37 @@ type ProfileDashboardDTO struct {
 	DailyVisitors  []*DailyAnalyticsDTO `json:"daily_visitors"`
 	TopItems       []*TopContentItemDTO `json:"top_items"`
 	TopReferrers   []*ReferrerStatsDTO  `json:"top_referrers"`
+
+	// Comparison sets this period's totals against the period before it
+	Comparison *DashboardComparisonDTO `json:"comparison"`
+
+	// TrackingEnabled is false while the owner has visitor tracking turned
+	// off; the figures then only cover the time it was on
+	TrackingEnabled bool `json:"tracking_enabled"`
+}
+
+// SummaryCardsDTO carries only the headline numbers for the current period
+// and their percent change against the period before it. A change is nil
+// when the previous period had nothing to compare against.
+type SummaryCardsDTO struct {
+	UserID               string   `json:"user_id"`
+	Period               string   `json:"period"`
+	TotalViews           int64    `json:"total_views"`
+	TotalClicks          int64    `json:"total_clicks"`
+	UniqueVisitors       int64    `json:"unique_visitors"`
+	ConversionRate       float64  `json:"conversion_rate"`
+	ViewsChange          *float64 `json:"views_change"`
+	ClicksChange         *float64 `json:"clicks_change"`
+	UniqueVisitorsChange *float64 `json:"unique_visitors_change"`
+	ConversionRateChange *float64 `json:"conversion_rate_change"`
 }
 
 type TopContentItemDTO struct {
-	ItemID      string `json:"item_id"`
-	ContentType string `json:"content_type"`
-	Title       string `json:"title"`
-	ClickCount  int64  `json:"click_count"`
+	ItemID           string `json:"item_id"`
+	ContentType      string `json:"content_type"`
+	Title            string `json:"title"`
+	ClickCount       int64  `json:"click_count"`
+	UniqueClickCount int64  `json:"unique_click_count"`
 }
 
 type ReferrerAnalyticsDTO struct {
@@ -143,80 +260,211 @@ type ReferrerStatsDTO struct {
 	Percentage float64 `json:"percentage"`
 }
 
+// Rollup job states
+const (
+	RollupJobPending   = "pending"
+	RollupJobRunning   = "running"
+	RollupJobCompleted = "completed"
+	RollupJobFailed    = "failed"
+)
+
+// maxAnalyticsDates caps how many individual dates one GetAnalyticsForDates
+// call may ask for.
+const maxAnalyticsDates = 90
+
+// maxHourlyRange caps the time range hourly analytics may cover, so one
+// request can't ask for tens of thousands of buckets.
+const maxHourlyRange = 31 * 24 * time.Hour
+
+// rollupChunkDays bounds how many days of raw analytics a single rebuild
+// statement aggregates, so large ranges don't hold long-running locks.
+const rollupChunkDays = 7
+
+// rollupRefreshDays is how many of the most recent complete days the refresh
+// worker rebuilds on every run, so late-arriving events are picked up
+const rollupRefreshDays = 2
+
+// rollupJobRetention is how long a finished rollup job can still be looked
+// up before it is forgotten
+const rollupJobRetention = time.Hour
+
+type RollupJobDTO struct {
+	ID              string `json:"id"`
+	UserID          string `json:"user_id,omitempty"`
+	StartDate       string `json:"start_date"`
+	EndDate         string `json:"end_date"`
+	Status          string `json:"status"`
+	TotalChunks     int    `json:"total_chunks"`
+	CompletedChunks int    `json:"completed_chunks"`
+	RowsWritten     int64  `json:"rows_written"`
+	Error           string `json:"error,omitempty"`
+	StartedAt       string `json:"started_at"`
+	FinishedAt      string `json:"finished_at,omitempty"`
+}
+
 type analyticsService struct {
 	analyticsRepo repository.AnalyticsRepository
 	contentRepo   repository.ContentRepository
 	userRepo      repository.UserRepository
+	exportStorage storage.Storage
+	emailClient   *email.EmailClient
+	geoLookup     geoip.Lookup
+	liveEvents    live.Broker
+	exportConfig  AnalyticsExportConfig
+	config        AnalyticsConfig
 	logger        log.Logger
+
+	rollupMu   sync.Mutex
+	rollupJobs map[string]*RollupJobDTO
 }
 
 func NewAnalyticsService(
 	analyticsRepo repository.AnalyticsRepository,
 	contentRepo repository.ContentRepository,
 	userRepo repository.UserRepository,
+	exportStorage storage.Storage,
+	emailClient *email.EmailClient,
+	geoLookup geoip.Lookup,
+	liveEvents live.Broker,
+	exportConfig AnalyticsExportConfig,
+	config AnalyticsConfig,
 	logger log.Logger,
 ) AnalyticsService {
+	if exportConfig.BatchSize <= 0 {
+		exportConfig.BatchSize = defaultExportBatchSize
+	}
+	if exportConfig.LinkTTL <= 0 {
+		exportConfig.LinkTTL = defaultExportLinkTTL
+	}
+	if len(config.BotPatterns) == 0 {
+		config.BotPatterns = useragent.DefaultBotPatterns
+	}
+	if config.UniqueClickWindow <= 0 {
+		config.UniqueClickWindow = defaultUniqueClickWindow
+	}
+	if config.MaxBatchSize <= 0 {
+		config.MaxBatchSize = defaultMaxBatchSize
+	}
+	config.ReferrerParams = normalizeReferrerParams(config.ReferrerParams)
+
 	return &analyticsService{
 		analyticsRepo: analyticsRepo,
 		contentRepo:   contentRepo,
 		userRepo:      userRepo,
+		exportStorage: exportStorage,
+		emailClient:   emailClient,
+		geoLookup:     geoLookup,
+		liveEvents:    liveEvents,
+		exportConfig:  exportConfig,
+		config:        config,
 		logger:        logger,
+		rollupJobs:    make(map[string]*RollupJobDTO),
 	}
 }
 
+// RecordClick is kept for existing callers; it records a plain click unless
+// the input already names another interaction type.
 func (s *analyticsService) RecordClick(ctx context.Context, input RecordClickInput) error {
-	s.logger.Infof("Recording click for item ID: %s from user ID: %s", input.ItemID, input.UserID)
+	if input.InteractionType == "" {
+		input.InteractionType = InteractionClick
+	}
+	return s.RecordInteraction(ctx, input)
+}
+
+func (s *analyticsService) RecordInteraction(ctx context.Context, input RecordClickInput) error {
+	if input.InteractionType == "" {
+		input.InteractionType = InteractionClick
+	}
+
+	s.logger.Infof("Recording %s for item ID: %s from user ID: %s", input.InteractionType, input.ItemID, input.UserID)
+
+	params, err := s.prepareInteraction(ctx, input, newInteractionLookup())
+	if err != nil {
+		return err
+	}
+	// The owner turned off visitor tracking, so there is nothing to record
+	if params == nil {
+		return nil
+	}
+
+	_, err = s.analyticsRepo.CreateAnalyticsEntry(ctx, *params)
+	if err != nil {
+		s.logger.Errorf("Failed to create analytics entry: %v", err)
+		return errors.Wrap(err, "Failed to record interaction")
+	}
+
+	s.publishLiveEvent(ctx, params.UserID, LiveEventDTO{
+		Type:            LiveEventInteraction,
+		ItemID:          input.ItemID,
+		InteractionType: input.InteractionType,
+		Referrer:        params.Referrer,
+		IsBot:           params.IsBot,
+	})
+
+	s.logger.Infof("Interaction %s recorded successfully for item ID: %s from user ID: %s", input.InteractionType, input.ItemID, input.UserID)
+	return nil
+}
+
+// prepareInteraction validates an interaction and builds the entry to store.
+// It returns nil params when the owner has turned analytics off.
+func (s *analyticsService) prepareInteraction(ctx context.Context, input RecordClickInput, lookup *interactionLookup) (*repository.CreateAnalyticsParams, error) {
+	if !validInteractionTypes[input.InteractionType] {
+		s.logger.Warnf("Invalid interaction type: %s", input.InteractionType)
+		return nil, errors.NewValidationError("Invalid interaction type, expected one of click, copy, share, submit", nil)
+	}
 
 	itemID, err := uuid.Parse(input.ItemID)
 	if err != nil {
 		s.logger.Warnf("Invalid item ID format: %v", err)
-		return errors.NewBadRequestError("Invalid item ID format", err)
+		return nil, errors.NewBadRequestError("Invalid item ID format", err)
 	}
 
 	userID, err := uuid.Parse(input.UserID)
 	if err != nil {
 		s.logger.Warnf("Invalid user ID format: %v", err)
-		return errors.NewBadRequestError("Invalid user ID format", err)
+		return nil, errors.NewBadRequestError("Invalid user ID format", err)
 	}
 
 	// Verify content item exists
-	_, err = s.contentRepo.GetContentItem(ctx, itemID)
+	err = lookup.item(ctx, s.contentRepo, itemID)
 	if err != nil {
 		if errors.IsNotFound(err) {
 			s.logger.Warnf("Content item not found with ID: %s", input.ItemID)
-			return errors.NewNotFoundError("Content item not found", err)
+			return nil, errors.NewNotFoundError("Content item not found", err)
 		}
 		s.logger.Errorf("Error retrieving content item: %v", err)
-		return errors.Wrap(err, "Failed to retrieve content item")
+		return nil, errors.Wrap(err, "Failed to retrieve content item")
 	}
 
 	// Verify user exists
-	_, err = s.userRepo.GetUser(ctx, userID)
+	owner, err := lookup.user(ctx, s.userRepo, userID)
 	if err != nil {
 		if errors.IsNotFound(err) {
 			s.logger.Warnf("User not found with ID: %s", input.UserID)
-			return errors.NewNotFoundError("User not found", err)
+			return nil, errors.NewNotFoundError("User not found", err)
 		}
 		s.logger.Errorf("Error retrieving user: %v", err)
-		return errors.Wrap(err, "Failed to retrieve user")
+		return nil, errors.Wrap(err, "Failed to retrieve user")
 	}
 
-	params := repository.CreateAnalyticsParams{
-		ItemID:    itemID,
-		UserID:    userID,
-		IPAddress: input.IPAddress,
-		UserAgent: input.UserAgent,
-		Referrer:  input.Referrer,
+	if !owner.AnalyticsEnabled {
+		s.logger.Debugf("Analytics disabled for user ID: %s, dropping %s", input.UserID, input.InteractionType)
+		return nil, nil
 	}
 
-	_, err = s.analyticsRepo.CreateAnalyticsEntry(ctx, params)
-	if err != nil {
-		s.logger.Errorf("Failed to create analytics entry: %v", err)
-		return errors.Wrap(err, "Failed to record click")
-	}
+	referrer := s.attributedReferrer(input.Query, input.Referrer)
+	source, medium, campaign := campaignTags(input.UTMSource, input.UTMMedium, input.UTMCampaign, input.Query)
 
-	s.logger.Infof("Click recorded successfully for item ID: %s from user ID: %s", input.ItemID, input.UserID)
-	return nil
+	return &repository.CreateAnalyticsParams{
+		ItemID:          itemID,
+		UserID:          userID,
+		IPAddress:       input.IPAddress,
+		UserAgent:       input.UserAgent,
+		Referrer:        referrer,
+		InteractionType: input.InteractionType,
+		IsBot:           s.isBot(input.UserAgent),
+		UTM:             resolveUTM(source, medium, campaign, referrer),
+	}, nil
 }
 
 func (s *analyticsService) RecordPageView(ctx context.Context, input RecordPageViewInput) error {
@@ -246,7 +494,7 @@ func (s *analyticsService) RecordPageView(ctx context.Context, input RecordPageV
 	}
 
 	// Verify user exists
-	_, err = s.userRepo.GetUser(ctx, userID)
+	owner, err := s.userRepo.GetUser(ctx, userID)
 	if err != nil {
 		if errors.IsNotFound(err) {
 			s.logger.Warnf("User not found with ID: %s", input.UserID)
@@ -256,12 +504,27 @@ func (s *analyticsService) RecordPageView(ctx context.Context, input RecordPageV
 		return errors.Wrap(err, "Failed to retrieve user")
 	}
 
+	// Unlisted profiles can be visited, private ones can't
+	if isHiddenFrom(owner, "") {
+		s.logger.Warnf("Page view for private profile of user ID: %s", input.UserID)
+		return errors.NewNotFoundError("User not found", nil)
+	}
+
+	if !owner.AnalyticsEnabled {
+		s.logger.Debugf("Analytics disabled for user ID: %s, dropping page view", input.UserID)
+		return nil
+	}
+
+	referrer := s.attributedReferrer(input.Query, input.Referrer)
+	source, medium, campaign := campaignTags(input.UTMSource, input.UTMMedium, input.UTMCampaign, input.Query)
 	params := repository.CreatePageViewParams{
 		ItemID:    profileID,
 		UserID:    userID,
 		IPAddress: input.IPAddress,
 		UserAgent: input.UserAgent,
-		Referrer:  input.Referrer,
+		Referrer:  referrer,
+		IsBot:     s.isBot(input.UserAgent),
+		UTM:       resolveUTM(source, medium, campaign, referrer),
 	}
 
 	_, err = s.analyticsRepo.CreatePageViewEntry(ctx, params)
@@ -270,6 +533,13 @@ func (s *analyticsService) RecordPageView(ctx context.Context, input RecordPageV
 		return errors.Wrap(err, "Failed to record page view")
 	}
 
+	s.publishLiveEvent(ctx, userID, LiveEventDTO{
+		Type:     LiveEventPageView,
+		ItemID:   input.ProfileID,
+		Referrer: params.Referrer,
+		IsBot:    params.IsBot,
+	})
+
 	s.logger.Infof("Page view recorded successfully for profile ID: %s by user ID: %s", input.ProfileID, input.UserID)
 	return nil
 }
@@ -311,6 +581,13 @@ func (s *analyticsService) GetContentItemAnalytics(ctx context.Context, itemIDSt
 		return nil, errors.Wrap(err, "Failed to retrieve click count")
 	}
 
+	// Unique clicks over the item's whole history, like the total
+	uniqueClicks, err := s.analyticsRepo.GetContentItemUniqueClickCount(ctx, itemID, time.Time{}, s.config.UniqueClickWindow)
+	if err != nil {
+		s.logger.Errorf("Failed to get unique click count: %v", err)
+		return nil, errors.Wrap(err, "Failed to retrieve unique click count")
+	}
+
 	// Get analytics entries
 	entries, err := s.analyticsRepo.GetItemAnalytics(ctx, itemID, pageSize, offset)
 	if err != nil {
@@ -318,17 +595,34 @@ func (s *analyticsService) GetContentItemAnalytics(ctx context.Context, itemIDSt
 		return nil, errors.Wrap(err, "Failed to retrieve analytics data")
 	}
 
+	// Get breakdown by interaction type
+	breakdown, err := s.analyticsRepo.GetItemInteractionBreakdown(ctx, itemID)
+	if err != nil {
+		s.logger.Errorf("Failed to get interaction breakdown: %v", err)
+		return nil, errors.Wrap(err, "Failed to retrieve interaction breakdown")
+	}
+
 	// Map to DTOs
 	clickData := make([]*AnalyticsDTO, len(entries))
 	for i, entry := range entries {
 		clickData[i] = mapAnalyticToDTO(entry)
 	}
 
+	interactions := make([]*InteractionStatsDTO, len(breakdown))
+	for i, stat := range breakdown {
+		interactions[i] = &InteractionStatsDTO{
+			InteractionType: stat.InteractionType,
+			Count:           stat.Count,
+		}
+	}
+
 	s.logger.Debugf("Retrieved %d analytics entries for item ID: %s with total clicks: %d", len(clickData), itemIDStr, totalClicks)
 	return &ContentItemAnalyticsDTO{
-		ItemID:      itemIDStr,
-		TotalClicks: totalClicks,
-		ClickData:   clickData,
+		ItemID:       itemIDStr,
+		TotalClicks:  totalClicks,
+		UniqueClicks: uniqueClicks,
+		Interactions: interactions,
+		ClickData:    clickData,
 	}, nil
 }
 
@@ -362,8 +656,8 @@ func (s *analyticsService) GetUserAnalytics(ctx context.Context, userIDStr strin
 		return nil, errors.Wrap(err, "Failed to retrieve user")
 	}
 
-	// Get click count
-	totalClicks, err := s.analyticsRepo.GetUserItemClickCount(ctx, userID)
+	// The raw event listing includes bot traffic, so its total does too
+	totalClicks, err := s.analyticsRepo.GetUserItemClickCount(ctx, userID, true)
 	if err != nil {
 		s.logger.Errorf("Failed to get click count: %v", err)
 		return nil, errors.Wrap(err, "Failed to retrieve click count")
@@ -413,6 +707,18 @@ func (s *analyticsService) GetUserAnalyticsByTimeRange(ctx context.Context, user
 		return nil, errors.NewValidationError("Invalid end date format, expected RFC3339", err)
 	}
 
+	granularity := input.Granularity
+	if granularity == "" {
+		granularity = GranularityDay
+	}
+	if !validGranularities[granularity] {
+		return nil, errors.NewValidationError("Granularity must be one of hour, day or week", nil)
+	}
+	if granularity == GranularityHour && endDate.Sub(startDate) > maxHourlyRange {
+		return nil, errors.NewValidationError(
+			fmt.Sprintf("Hourly analytics can cover at most %d days", int(maxHourlyRange.Hours()/24)), nil)
+	}
+
 	// Verify user exists
 	_, err = s.userRepo.GetUser(ctx, userID)
 	if err != nil {
@@ -424,11 +730,13 @@ func (s *analyticsService) GetUserAnalyticsByTimeRange(ctx context.Context, user
 		return nil, errors.Wrap(err, "Failed to retrieve user")
 	}
 
-	// Get daily analytics
+	// Get bucketed analytics
 	params := repository.TimeRangeParams{
-		UserID:    userID,
-		StartDate: startDate,
-		EndDate:   endDate,
+		UserID:      userID,
+		StartDate:   startDate,
+		EndDate:     endDate,
+		IncludeBots: input.IncludeBots,
+		Granularity: granularity,
 	}
 
 	dailyAnalytics, err := s.analyticsRepo.GetUserAnalyticsByTimeRange(ctx, params)
@@ -448,20 +756,84 @@ func (s *analyticsService) GetUserAnalyticsByTimeRange(ctx context.Context, user
 			DayOfWeek: da.Day.Format("Monday"),
 			Count:     da.Clicks,
 		}
+		if granularity == GranularityHour {
+			dailyClicks[i].Date = da.Day.Format(time.RFC3339)
+		}
 	}
 
-	s.logger.Debugf("Retrieved %d days of analytics for user ID: %s with total clicks: %d",
-		len(dailyClicks), userIDStr, totalClicks)
+	s.logger.Debugf("Retrieved %d %s buckets of analytics for user ID: %s with total clicks: %d",
+		len(dailyClicks), granularity, userIDStr, totalClicks)
 
 	return &TimeRangeAnalyticsDTO{
 		UserID:      userIDStr,
 		StartDate:   input.StartDate,
 		EndDate:     input.EndDate,
+		Granularity: granularity,
 		TotalClicks: totalClicks,
 		DailyClicks: dailyClicks,
 	}, nil
 }
 
+// GetAnalyticsForDates returns the click count for each requested date
+// (YYYY-MM-DD, UTC). Unlike the time-range queries the dates need not be
+// contiguous; dates without clicks are reported as zero.
+func (s *analyticsService) GetAnalyticsForDates(ctx context.Context, userIDStr string, dates []string) (map[string]int64, error) {
+	s.logger.Debugf("Getting analytics for %d dates for user ID: %s", len(dates), userIDStr)
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		s.logger.Warnf("Invalid user ID format: %v", err)
+		return nil, errors.NewBadRequestError("Invalid user ID format", err)
+	}
+
+	if len(dates) == 0 {
+		return nil, errors.NewValidationError("At least one date is required", nil)
+	}
+	if len(dates) > maxAnalyticsDates {
+		return nil, errors.NewValidationError(fmt.Sprintf("At most %d dates can be requested at once", maxAnalyticsDates), nil)
+	}
+
+	counts := make(map[string]int64, len(dates))
+	days := make([]time.Time, 0, len(dates))
+	for _, date := range dates {
+		day, err := time.Parse("2006-01-02", date)
+		if err != nil {
+			s.logger.Warnf("Invalid date %q: %v", date, err)
+			return nil, errors.NewValidationError(fmt.Sprintf("Invalid date %q, expected YYYY-MM-DD", date), err)
+		}
+		key := day.Format("2006-01-02")
+		if _, seen := counts[key]; seen {
+			continue
+		}
+		counts[key] = 0
+		days = append(days, day)
+	}
+
+	// Verify user exists
+	_, err = s.userRepo.GetUser(ctx, userID)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			s.logger.Warnf("User not found with ID: %s", userIDStr)
+			return nil, errors.NewNotFoundError("User not found", err)
+		}
+		s.logger.Errorf("Error retrieving user: %v", err)
+		return nil, errors.Wrap(err, "Failed to retrieve user")
+	}
+
+	dailyClicks, err := s.analyticsRepo.GetUserClicksForDays(ctx, userID, days)
+	if err != nil {
+		s.logger.Errorf("Failed to get clicks for dates: %v", err)
+		return nil, errors.Wrap(err, "Failed to retrieve analytics data")
+	}
+
+	for _, dc := range dailyClicks {
+		counts[dc.Day.UTC().Format("2006-01-02")] = dc.Clicks
+	}
+
+	s.logger.Debugf("Retrieved analytics for %d dates for user ID: %s", len(counts), userIDStr)
+	return counts, nil
+}
+
 func (s *analyticsService) GetItemAnalyticsByTimeRange(ctx context.Context, itemIDStr string, input TimeRangeInput) (*ItemTimeRangeAnalyticsDTO, error) {
 	s.logger.Debugf("Getting item analytics by time range for item ID: %s from %s to %s",
 		itemIDStr, input.StartDate, input.EndDate)
@@ -498,9 +870,10 @@ func (s *analyticsService) GetItemAnalyticsByTimeRange(ctx context.Context, item
 
 	// Get daily analytics
 	params := repository.ItemTimeRangeParams{
-		ItemID:    itemID,
-		StartDate: startDate,
-		EndDate:   endDate,
+		ItemID:      itemID,
+		StartDate:   startDate,
+		EndDate:     endDate,
+		IncludeBots: input.IncludeBots,
 	}
 
 	dailyAnalytics, err := s.analyticsRepo.GetItemAnalyticsByTimeRange(ctx, params)
@@ -570,9 +943,10 @@ func (s *analyticsService) GetProfilePageViewsByTimeRange(ctx context.Context, u
 
 	// Get page view analytics
 	params := repository.TimeRangeParams{
-		UserID:    userID,
-		StartDate: startDate,
-		EndDate:   endDate,
+		UserID:      userID,
+		StartDate:   startDate,
+		EndDate:     endDate,
+		IncludeBots: input.IncludeBots,
 	}
 
 	dailyViews, err := s.analyticsRepo.GetProfilePageViewsByDate(ctx, params)
@@ -607,7 +981,7 @@ func (s *analyticsService) GetProfilePageViewsByTimeRange(ctx context.Context, u
 }
 
 // Dashboard analytics
-func (s *analyticsService) GetProfileDashboard(ctx context.Context, userIDStr string, days int) (*ProfileDashboardDTO, error) {
+func (s *analyticsService) GetProfileDashboard(ctx context.Context, userIDStr string, days int, includeBots bool) (*ProfileDashboardDTO, error) {
 	s.logger.Infof("Getting profile dashboard for user ID: %s over %d days", userIDStr, days)
 
 	userID, err := uuid.Parse(userIDStr)
@@ -626,7 +1000,7 @@ func (s *analyticsService) GetProfileDashboard(ctx context.Context, userIDStr st
 	startDate := endDate.AddDate(0, 0, -days)
 
 	// Verify user exists
-	_, err = s.userRepo.GetUser(ctx, userID)
+	user, err := s.userRepo.GetUser(ctx, userID)
 	if err != nil {
 		if errors.IsNotFound(err) {
 			s.logger.Warnf("User not found with ID: %s", userIDStr)
@@ -637,14 +1011,14 @@ func (s *analyticsService) GetProfileDashboard(ctx context.Context, userIDStr st
 	}
 
 	// Get total page views
-	totalViews, err := s.analyticsRepo.GetProfilePageViews(ctx, userID)
+	totalViews, err := s.analyticsRepo.GetProfilePageViews(ctx, userID, includeBots)
 	if err != nil {
 		s.logger.Errorf("Failed to get total page views: %v", err)
 		return nil, errors.Wrap(err, "Failed to retrieve page view count")
 	}
 
 	// Get total clicks
-	totalClicks, err := s.analyticsRepo.GetUserItemClickCount(ctx, userID)
+	totalClicks, err := s.analyticsRepo.GetUserItemClickCount(ctx, userID, includeBots)
 	if err != nil {
 		s.logger.Errorf("Failed to get total clicks: %v", err)
 		return nil, errors.Wrap(err, "Failed to retrieve click count")
@@ -652,9 +1026,10 @@ func (s *analyticsService) GetProfileDashboard(ctx context.Context, userIDStr st
 
 	// Get unique visitors
 	visitorParams := repository.TimeRangeParams{
-		UserID:    userID,
-		StartDate: startDate,
-		EndDate:   endDate,
+		UserID:      userID,
+		StartDate:   startDate,
+		EndDate:     endDate,
+		IncludeBots: includeBots,
 	}
 
 	uniqueVisitors, err := s.analyticsRepo.GetUniqueVisitors(ctx, visitorParams)
@@ -679,10 +1054,12 @@ func (s *analyticsService) GetProfileDashboard(ctx context.Context, userIDStr st
 
 	// Get top items
 	topItemsParams := repository.TopItemsParams{
-		UserID:    userID,
-		StartDate: startDate,
-		EndDate:   endDate,
-		Limit:     10, // Top 10 items
+		UserID:       userID,
+		StartDate:    startDate,
+		EndDate:      endDate,
+		Limit:        10, // Top 10 items
+		IncludeBots:  includeBots,
+		UniqueWindow: s.config.UniqueClickWindow,
 	}
 
 	topItems, err := s.analyticsRepo.GetTopContentItemsByClicks(ctx, topItemsParams)
@@ -693,10 +1070,11 @@ func (s *analyticsService) GetProfileDashboard(ctx context.Context, userIDStr st
 
 	// Get top referrers
 	referrerParams := repository.ReferrerParams{
-		UserID:    userID,
-		StartDate: startDate,
-		EndDate:   endDate,
-		Limit:     5, // Top 5 referrers
+		UserID:      userID,
+		StartDate:   startDate,
+		EndDate:     endDate,
+		Limit:       5, // Top 5 referrers
+		IncludeBots: includeBots,
 	}
 
 	topReferrers, err := s.analyticsRepo.GetReferrerAnalytics(ctx, referrerParams)
@@ -705,6 +1083,11 @@ func (s *analyticsService) GetProfileDashboard(ctx context.Context, userIDStr st
 		return nil, errors.Wrap(err, "Failed to retrieve referrer analytics")
 	}
 
+	comparison, err := s.comparePeriods(ctx, userID, startDate, endDate, includeBots)
+	if err != nil {
+		return nil, err
+	}
+
 	// Calculate conversion rate
 	var conversionRate float64
 	if totalViews > 0 {
@@ -733,10 +1116,11 @@ func (s *analyticsService) GetProfileDashboard(ctx context.Context, userIDStr st
 	topItemsDTO := make([]*TopContentItemDTO, len(topItems))
 	for i, item := range topItems {
 		topItemsDTO[i] = &TopContentItemDTO{
-			ItemID:      item.ItemID,
-			ContentType: item.ContentType,
-			Title:       item.Title,
-			ClickCount:  item.ClickCount,
+			ItemID:           item.ItemID,
+			ContentType:      item.ContentType,
+			Title:            item.Title,
+			ClickCount:       item.ClickCount,
+			UniqueClickCount: item.UniqueClickCount,
 		}
 	}
 
@@ -777,9 +1161,98 @@ func (s *analyticsService) GetProfileDashboard(ctx context.Context, userIDStr st
 		DailyVisitors:  dailyVisitors,
 		TopItems:       topItemsDTO,
 		TopReferrers:   referrersDTO,
+		Comparison:     comparison,
+
+		TrackingEnabled: user.AnalyticsEnabled,
+	}, nil
+}
+
+// GetSummaryCards returns the dashboard's headline KPIs for the last days
+// compared with the equally long period before, without any series data.
+func (s *analyticsService) GetSummaryCards(ctx context.Context, userIDStr string, days int, includeBots bool) (*SummaryCardsDTO, error) {
+	s.logger.Infof("Getting summary cards for user ID: %s over %d days", userIDStr, days)
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		s.logger.Warnf("Invalid user ID format: %v", err)
+		return nil, errors.NewBadRequestError("Invalid user ID format", err)
+	}
+
+	if days <= 0 {
+		days = 30
+	}
+
+	_, err = s.userRepo.GetUser(ctx, userID)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			s.logger.Warnf("User not found with ID: %s", userIDStr)
+			return nil, errors.NewNotFoundError("User not found", err)
+		}
+		s.logger.Errorf("Error retrieving user: %v", err)
+		return nil, errors.Wrap(err, "Failed to retrieve user")
+	}
+
+	endDate := time.Now()
+	startDate := endDate.AddDate(0, 0, -days)
+	previousStart := startDate.AddDate(0, 0, -days)
+
+	current, err := s.analyticsRepo.GetPeriodTotals(ctx, repository.TimeRangeParams{
+		UserID:      userID,
+		StartDate:   startDate,
+		EndDate:     endDate,
+		IncludeBots: includeBots,
+	})
+	if err != nil {
+		s.logger.Errorf("Failed to get current period totals: %v", err)
+		return nil, errors.Wrap(err, "Failed to retrieve analytics totals")
+	}
+
+	previous, err := s.analyticsRepo.GetPeriodTotals(ctx, repository.TimeRangeParams{
+		UserID:      userID,
+		StartDate:   previousStart,
+		EndDate:     startDate,
+		IncludeBots: includeBots,
+	})
+	if err != nil {
+		s.logger.Errorf("Failed to get previous period totals: %v", err)
+		return nil, errors.Wrap(err, "Failed to retrieve analytics totals")
+	}
+
+	currentRate := conversionRate(current.Clicks, current.Views)
+	previousRate := conversionRate(previous.Clicks, previous.Views)
+
+	return &SummaryCardsDTO{
+		UserID:               userIDStr,
+		Period:               fmt.Sprintf("Last %d days", days),
+		TotalViews:           current.Views,
+		TotalClicks:          current.Clicks,
+		UniqueVisitors:       current.UniqueVisitors,
+		ConversionRate:       currentRate,
+		ViewsChange:          percentChange(float64(current.Views), float64(previous.Views)),
+		ClicksChange:         percentChange(float64(current.Clicks), float64(previous.Clicks)),
+		UniqueVisitorsChange: percentChange(float64(current.UniqueVisitors), float64(previous.UniqueVisitors)),
+		ConversionRateChange: percentChange(currentRate, previousRate),
 	}, nil
 }
 
+// conversionRate is clicks per view as a percentage
+func conversionRate(clicks, views int64) float64 {
+	if views == 0 {
+		return 0
+	}
+	return float64(clicks) / float64(views) * 100
+}
+
+// percentChange is the change from previous to current in percent, or nil
+// when there is no previous value to compare with
+func percentChange(current, previous float64) *float64 {
+	if previous == 0 {
+		return nil
+	}
+	change := (current - previous) / previous * 100
+	return &change
+}
+
 // Referrer analytics
 func (s *analyticsService) GetReferrerAnalytics(ctx context.Context, userIDStr string, input TimeRangeInput) (*ReferrerAnalyticsDTO, error) {
 	s.logger.Debugf("Getting referrer analytics for user ID: %s from %s to %s",
@@ -823,10 +1296,11 @@ func (s *analyticsService) GetReferrerAnalytics(ctx context.Context, userIDStr s
 
 	// Get referrer stats
 	params := repository.ReferrerParams{
-		UserID:    userID,
-		StartDate: startDate,
-		EndDate:   endDate,
-		Limit:     limit,
+		UserID:      userID,
+		StartDate:   startDate,
+		EndDate:     endDate,
+		Limit:       limit,
+		IncludeBots: input.IncludeBots,
 	}
 
 	referrers, err := s.analyticsRepo.GetReferrerAnalytics(ctx, params)
@@ -868,13 +1342,213 @@ func (s *analyticsService) GetReferrerAnalytics(ctx context.Context, userIDStr s
 	}, nil
 }
 
+func (s *analyticsService) RebuildRollups(ctx context.Context, userIDStr string, start, end time.Time) error {
+	userID, start, end, err := s.validateRollupRange(userIDStr, start, end)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.rebuildRollupChunks(ctx, userID, start, end, nil)
+	return err
+}
+
+// StartRollupRefresh keeps the daily rollups for the most recent complete
+// days up to date until ctx is cancelled.
+func (s *analyticsService) StartRollupRefresh(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		end := time.Now().UTC().Truncate(24 * time.Hour)
+		start := end.AddDate(0, 0, -rollupRefreshDays)
+		if _, err := s.rebuildRollupChunks(ctx, nil, start, end, nil); err != nil {
+			s.logger.Errorf("Rollup refresh run failed: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *analyticsService) StartRollupRebuild(userIDStr string, start, end time.Time) (*RollupJobDTO, error) {
+	userID, start, end, err := s.validateRollupRange(userIDStr, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	job := &RollupJobDTO{
+		ID:          uuid.New().String(),
+		UserID:      userIDStr,
+		StartDate:   start.Format(time.RFC3339),
+		EndDate:     end.Format(time.RFC3339),
+		Status:      RollupJobPending,
+		TotalChunks: countRollupChunks(start, end),
+		StartedAt:   time.Now().Format(time.RFC3339),
+	}
+
+	s.rollupMu.Lock()
+	s.pruneRollupJobs(time.Now())
+	s.rollupJobs[job.ID] = job
+	snapshot := *job
+	s.rollupMu.Unlock()
+
+	s.logger.Infof("Starting rollup rebuild job %s from %s to %s", job.ID, job.StartDate, job.EndDate)
+
+	go func() {
+		s.updateRollupJob(job.ID, func(j *RollupJobDTO) { j.Status = RollupJobRunning })
+
+		rows, err := s.rebuildRollupChunks(context.Background(), userID, start, end, func(done int, written int64) {
+			s.updateRollupJob(job.ID, func(j *RollupJobDTO) {
+				j.CompletedChunks = done
+				j.RowsWritten = written
+			})
+		})
+
+		s.updateRollupJob(job.ID, func(j *RollupJobDTO) {
+			j.RowsWritten = rows
+			j.FinishedAt = time.Now().Format(time.RFC3339)
+			if err != nil {
+				j.Status = RollupJobFailed
+				j.Error = err.Error()
+				return
+			}
+			j.Status = RollupJobCompleted
+		})
+
+		if err != nil {
+			s.logger.Errorf("Rollup rebuild job %s failed: %v", job.ID, err)
+			return
+		}
+		s.logger.Infof("Rollup rebuild job %s completed, %d rows written", job.ID, rows)
+	}()
+
+	return &snapshot, nil
+}
+
+func (s *analyticsService) GetRollupJob(jobID string) (*RollupJobDTO, error) {
+	s.rollupMu.Lock()
+	defer s.rollupMu.Unlock()
+
+	s.pruneRollupJobs(time.Now())
+	job, ok := s.rollupJobs[jobID]
+	if !ok {
+		return nil, errors.NewNotFoundError("Rollup job not found", nil)
+	}
+
+	snapshot := *job
+	return &snapshot, nil
+}
+
+// pruneRollupJobs forgets jobs that finished more than rollupJobRetention
+// before now. Callers hold rollupMu.
+func (s *analyticsService) pruneRollupJobs(now time.Time) {
+	for id, job := range s.rollupJobs {
+		if job.FinishedAt == "" {
+			continue
+		}
+		finished, err := time.Parse(time.RFC3339, job.FinishedAt)
+		if err != nil || now.Sub(finished) > rollupJobRetention {
+			delete(s.rollupJobs, id)
+		}
+	}
+}
+
+func (s *analyticsService) updateRollupJob(jobID string, fn func(*RollupJobDTO)) {
+	s.rollupMu.Lock()
+	defer s.rollupMu.Unlock()
+
+	if job, ok := s.rollupJobs[jobID]; ok {
+		fn(job)
+	}
+}
+
+// validateRollupRange parses the optional user ID and widens the range to
+// whole UTC days, since rollups are stored per day.
+func (s *analyticsService) validateRollupRange(userIDStr string, start, end time.Time) (*uuid.UUID, time.Time, time.Time, error) {
+	var userID *uuid.UUID
+	if userIDStr != "" {
+		parsed, err := uuid.Parse(userIDStr)
+		if err != nil {
+			s.logger.Warnf("Invalid user ID format: %v", err)
+			return nil, start, end, errors.NewBadRequestError("Invalid user ID format", err)
+		}
+		userID = &parsed
+	}
+
+	start = start.UTC().Truncate(24 * time.Hour)
+	if truncated := end.UTC().Truncate(24 * time.Hour); truncated.Equal(end.UTC()) {
+		end = truncated
+	} else {
+		end = truncated.AddDate(0, 0, 1)
+	}
+
+	if !end.After(start) {
+		return nil, start, end, errors.NewValidationError("End date must be after start date", nil)
+	}
+
+	return userID, start, end, nil
+}
+
+func (s *analyticsService) rebuildRollupChunks(
+	ctx context.Context,
+	userID *uuid.UUID,
+	start, end time.Time,
+	onChunk func(done int, written int64),
+) (int64, error) {
+	var written int64
+	done := 0
+
+	for chunkStart := start; chunkStart.Before(end); chunkStart = chunkStart.AddDate(0, 0, rollupChunkDays) {
+		if err := ctx.Err(); err != nil {
+			return written, errors.Wrap(err, "rollup rebuild cancelled")
+		}
+
+		chunkEnd := chunkStart.AddDate(0, 0, rollupChunkDays)
+		if chunkEnd.After(end) {
+			chunkEnd = end
+		}
+
+		rows, err := s.analyticsRepo.RebuildDailyRollups(ctx, repository.RollupRangeParams{
+			UserID:    userID,
+			StartDate: chunkStart,
+			EndDate:   chunkEnd,
+		})
+		if err != nil {
+			s.logger.Errorf("Failed to rebuild rollups for %s to %s: %v",
+				chunkStart.Format(time.RFC3339), chunkEnd.Format(time.RFC3339), err)
+			return written, err
+		}
+
+		written += rows
+		done++
+		if onChunk != nil {
+			onChunk(done, written)
+		}
+	}
+
+	return written, nil
+}
+
+func countRollupChunks(start, end time.Time) int {
+	days := int(end.Sub(start).Hours() / 24)
+	return (days + rollupChunkDays - 1) / rollupChunkDays
+}
+
 // Helper function to map Analytic db model to DTO
 func mapAnalyticToDTO(a *db.Analytic) *AnalyticsDTO {
 	dto := &AnalyticsDTO{
 		ID:       a.AnalyticsID.String(),
 		ItemID:   a.ItemID.String(),
 		UserID:   a.UserID.String(),
-		PageView: *a.PageView,
+		PageView: a.PageView != nil && *a.PageView,
+		IsBot:    a.IsBot,
+	}
+
+	if !dto.PageView {
+		dto.InteractionType = a.InteractionType
 	}
 
 	if a.IpAddress != nil {
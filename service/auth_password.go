@@ -0,0 +1,70 @@
+package service
+
+import (
+	"math"
+
+	"github.com/0xsj/mios.io/pkg/errors"
+	"github.com/0xsj/mios.io/pkg/password"
+)
+
+// PasswordStrengthInput is a candidate password, with the account details
+// an attacker would try in it
+type PasswordStrengthInput struct {
+	Password string `json:"password" binding:"required"`
+	Username string `json:"username"`
+	Email    string `json:"email"`
+}
+
+// PasswordStrengthDTO reports how a password scores against the policy.
+// Reason is why it would be rejected, empty when it is acceptable.
+type PasswordStrengthDTO struct {
+	Score        int     `json:"score"`
+	MinScore     int     `json:"min_score"`
+	GuessesLog10 float64 `json:"guesses_log10"`
+	Acceptable   bool    `json:"acceptable"`
+	Reason       string  `json:"reason,omitempty"`
+}
+
+// CheckPasswordStrength scores a password the same way registration and
+// password changes do, without storing anything
+func (s *authService) CheckPasswordStrength(input PasswordStrengthInput) *PasswordStrengthDTO {
+	userInputs := []string{input.Username, input.Email}
+	strength := password.EstimateStrength(input.Password, userInputs...)
+
+	result := &PasswordStrengthDTO{
+		Score:        strength.Score,
+		MinScore:     s.config.MinPasswordScore,
+		GuessesLog10: math.Round(math.Log10(strength.Guesses)*100) / 100,
+		Acceptable:   true,
+	}
+
+	if err := password.ValidatePassword(input.Password, s.passwordPolicy(), userInputs...); err != nil {
+		result.Acceptable = false
+		result.Reason = err.Error()
+	}
+
+	return result
+}
+
+// passwordPolicy is the default rule set with the configured minimum
+// strength score
+func (s *authService) passwordPolicy() password.PasswordConfig {
+	policy := password.DefaultPasswordConfig()
+	policy.MinScore = s.config.MinPasswordScore
+	return policy
+}
+
+// validateNewPassword checks a password being set against the policy. The
+// rejection reason goes in the error details so clients can show it.
+func (s *authService) validateNewPassword(field, newPassword, message string, userInputs ...string) error {
+	err := password.ValidatePassword(newPassword, s.passwordPolicy(), userInputs...)
+	if err == nil {
+		return nil
+	}
+
+	appErr := errors.NewFieldValidationError(message, []errors.FieldError{
+		{Field: field, Message: err.Error()},
+	})
+	appErr.Err = err
+	return appErr
+}
@@ -2,15 +2,25 @@ package service
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
 	"fmt"
 	"net/url"
+	"strings"
 	"time"
 
+	db "github.com/0xsj/mios.io/db/sqlc"
 	"github.com/0xsj/mios.io/log"
 	"github.com/0xsj/mios.io/pkg/email"
 	"github.com/0xsj/mios.io/pkg/errors"
+	"github.com/0xsj/mios.io/pkg/oauth"
 	"github.com/0xsj/mios.io/pkg/password"
+	"github.com/0xsj/mios.io/pkg/ptr"
+	"github.com/0xsj/mios.io/pkg/redis"
 	"github.com/0xsj/mios.io/pkg/token"
+	"github.com/0xsj/mios.io/pkg/totp"
 	"github.com/0xsj/mios.io/repository"
 	"github.com/google/uuid"
 )
@@ -19,22 +29,158 @@ const (
 	DefaultAccessTokenDuration  = 24 * time.Hour
 	DefaultRefreshTokenDuration = 7 * 24 * time.Hour
 	DefaultResetTokenDuration   = 1 * time.Hour
+	TwoFactorChallengeDuration  = 5 * time.Minute
+	AccountRestoreTokenDuration = 15 * time.Minute
 )
 
 type AuthService interface {
 	Register(ctx context.Context, input RegisterInput) (*UserDTO, error)
 	Login(ctx context.Context, input LoginInput) (*TokenResponse, error)
 	RefreshToken(ctx context.Context, input RefreshTokenRequest) (*TokenResponse, error)
-	GenerateResetToken(ctx context.Context, email string) error
+	GenerateResetToken(ctx context.Context, email, redirectURI string) error
 	ResetPassword(ctx context.Context, input ResetPasswordInput) error
-	VerifyEmail(ctx context.Context, token string) error  // <-- Uncomment this line
-	Logout(ctx context.Context, userID string) error
+	ChangePassword(ctx context.Context, userID, currentPassword, newPassword string) error
+	Reauthenticate(ctx context.Context, userID, password, code string) error
+	VerifyEmail(ctx context.Context, token string) error
+	Logout(ctx context.Context, userID string, accessClaims *token.Claims) error
+	ListSessions(ctx context.Context, userID string) ([]SessionDTO, error)
+	RevokeSession(ctx context.Context, userID, sessionID string) error
 	ValidateToken(ctx context.Context, tokenStr string) (*token.Claims, error)
+	ValidateRestoreToken(ctx context.Context, tokenStr string) (*token.Claims, error)
 	IsEmailVerified(ctx context.Context, userID string) (bool, error)
-	SendVerificationEmail(ctx context.Context, email, username, token string) error
-	SendPasswordResetEmail(ctx context.Context, email, username, token string) error
-	SendPasswordChangedEmail(ctx context.Context, email, username string) error
-	SendAccountLockedEmail(ctx context.Context, email, username, unlockTime string) error
+	SendVerificationEmail(ctx context.Context, email, username, locale, token string) error
+	SendPasswordResetEmail(ctx context.Context, email, username, locale, token string) error
+	SendPasswordChangedEmail(ctx context.Context, email, username, locale string) error
+	SendAccountLockedEmail(ctx context.Context, email, username, locale, unlockTime string) error
+	ResolveRedirect(redirectURI string) (string, error)
+	CheckPasswordStrength(input PasswordStrengthInput) *PasswordStrengthDTO
+
+	// Two-factor authentication
+	EnableTOTP(ctx context.Context, userID string) (*TOTPSetupDTO, error)
+	VerifyTOTP(ctx context.Context, userID, code string) error
+	LoginWithTOTP(ctx context.Context, input TOTPLoginInput) (*TokenResponse, error)
+
+	// OAuth sign-in
+	OAuthAuthorizeURL(provider, state string) (string, error)
+	OAuthLogin(ctx context.Context, provider, code string, client ClientInfo) (*TokenResponse, error)
+
+	// Admin verification tooling
+	GetVerificationStatuses(ctx context.Context, userIDs []string) (map[string]bool, error)
+	CleanupUnverifiedAccounts(ctx context.Context, input CleanupUnverifiedInput) (*UnverifiedCleanupResultDTO, error)
+
+	// Invite codes
+	CreateInviteCodes(ctx context.Context, createdBy string, input CreateInviteCodesInput) ([]*InviteCodeDTO, error)
+	ListInviteCodes(ctx context.Context, limit, offset int) ([]*InviteCodeDTO, error)
+}
+
+// AuthConfig controls optional registration requirements
+type AuthConfig struct {
+	// InviteOnly requires a valid invite code for every registration
+	InviteOnly bool
+
+	// AllowedRedirectHosts lists the hosts (optionally with port) that
+	// client-supplied redirect targets may point to, besides the API's own
+	// origin. Empty means same-origin only.
+	AllowedRedirectHosts []string
+
+	// FieldLimits caps the length of profile fields given at registration
+	FieldLimits FieldLimits
+
+	// ReservedHandles adds to the handles nobody may register
+	ReservedHandles ReservedHandles
+
+	// OAuthProviders are the enabled social login providers, keyed by name
+	OAuthProviders map[string]oauth.Provider
+
+	// MaxFailedLoginAttempts is how many consecutive failed logins lock the
+	// account, for LockoutDuration
+	MaxFailedLoginAttempts int
+	LockoutDuration        time.Duration
+
+	// MinPasswordScore is the lowest password strength score (1-4) accepted
+	// when a password is set
+	MinPasswordScore int
+
+	// AccountDeleter schedules accounts removed by the unverified account
+	// cleanup for deletion, so they get the same grace period as any other
+	AccountDeleter AccountDeleter
+}
+
+// AccountDeleter schedules an account for deletion; UserService is one
+type AccountDeleter interface {
+	RequestAccountDeletion(ctx context.Context, id string) (*AccountDeletionDTO, error)
+}
+
+const (
+	defaultMaxFailedLoginAttempts = 5
+	defaultLockoutDuration        = 15 * time.Minute
+)
+
+const (
+	totpIssuer         = "mios.io"
+	totpSkew           = 1
+	recoveryCodeCount  = 10
+	recoveryCodeLength = 10
+)
+
+const (
+	maxOAuthUsernameBase  = 20
+	oauthUsernameAttempts = 5
+)
+
+const (
+	inviteCodeLength     = 10
+	maxInviteCodesPerRun = 100
+)
+
+type CreateInviteCodesInput struct {
+	Count     int
+	MaxUses   int
+	ExpiresIn time.Duration
+}
+
+type InviteCodeDTO struct {
+	Code      string `json:"code"`
+	MaxUses   int    `json:"max_uses"`
+	UsedCount int    `json:"used_count"`
+	ExpiresAt string `json:"expires_at,omitempty"`
+	CreatedBy string `json:"created_by,omitempty"`
+	CreatedAt string `json:"created_at"`
+}
+
+// Bulk actions for accounts that never verified their email
+const (
+	UnverifiedActionDelete   = "delete"
+	UnverifiedActionRenotify = "renotify"
+)
+
+const (
+	maxVerificationStatusBatch = 500
+	defaultUnverifiedBatch     = 100
+	maxUnverifiedBatch         = 1000
+)
+
+type CleanupUnverifiedInput struct {
+	OlderThan time.Duration
+	Action    string
+	Limit     int
+	DryRun    bool
+}
+
+type UnverifiedUserDTO struct {
+	UserID    string `json:"user_id"`
+	Username  string `json:"username"`
+	Email     string `json:"email"`
+	CreatedAt string `json:"created_at"`
+}
+
+type UnverifiedCleanupResultDTO struct {
+	Action    string               `json:"action"`
+	DryRun    bool                 `json:"dry_run"`
+	Matched   int                  `json:"matched"`
+	Processed int                  `json:"processed"`
+	Failed    []string             `json:"failed,omitempty"`
+	Users     []*UnverifiedUserDTO `json:"users"`
 }
 
 type RegisterInput struct {
@@ -48,17 +194,27 @@ type RegisterInput struct {
 	ProfileImageURL string `json:"profile_image_url"`
 	LayoutVersion   string `json:"layout_version"`
 	CustomDomain    string `json:"custom_domain"`
+	InviteCode      string `json:"invite_code"`
+	RedirectURI     string `json:"redirect_uri"`
 }
 
 type LoginInput struct {
-	Email    string `json:"email" binding:"required,email"`
-	Password string `json:"password" binding:"required"`
+	Email    string     `json:"email" binding:"required,email"`
+	Password string     `json:"password" binding:"required"`
+	Client   ClientInfo `json:"-"`
 }
 
 type RefreshTokenRequest struct {
 	RefreshToken string `json:"refresh_token" binding:"required"`
 }
 
+// ClientInfo describes the device a login or refresh request came from. It
+// is recorded on the session so users can tell their devices apart.
+type ClientInfo struct {
+	UserAgent string
+	IPAddress string
+}
+
 type ResetPasswordInput struct {
 	Token           string `json:"token" binding:"required"`
 	Email           string `json:"email" binding:"required,email"`
@@ -67,18 +223,44 @@ type ResetPasswordInput struct {
 }
 
 type TokenResponse struct {
-	AccessToken  string   `json:"access_token"`
-	RefreshToken string   `json:"refresh_token"`
-	ExpiresAt    int64    `json:"expires_at"`
-	User         *UserDTO `json:"user"`
+	AccessToken       string   `json:"access_token,omitempty"`
+	RefreshToken      string   `json:"refresh_token,omitempty"`
+	ExpiresAt         int64    `json:"expires_at"`
+	User              *UserDTO `json:"user,omitempty"`
+	RequiresTwoFactor bool     `json:"requires_2fa"`
+	ChallengeToken    string   `json:"challenge_token,omitempty"`
+
+	// PendingDeletion is set instead of issuing tokens when the account is
+	// scheduled for deletion; RestoreToken can only be used to restore it
+	PendingDeletion bool   `json:"pending_deletion,omitempty"`
+	RestoreToken    string `json:"restore_token,omitempty"`
+}
+
+type TOTPLoginInput struct {
+	ChallengeToken string     `json:"challenge_token" binding:"required"`
+	Code           string     `json:"code" binding:"required"`
+	Client         ClientInfo `json:"-"`
+}
+
+// TOTPSetupDTO is returned once when two-factor is enabled. The recovery
+// codes are stored hashed and cannot be shown again.
+type TOTPSetupDTO struct {
+	Secret        string   `json:"secret"`
+	OTPAuthURI    string   `json:"otpauth_uri"`
+	RecoveryCodes []string `json:"recovery_codes"`
 }
 
 type authService struct {
 	userRepo    repository.UserRepository
 	authRepo    repository.AuthRepository
+	inviteRepo  repository.InviteCodeRepository
+	oauthRepo   repository.OAuthRepository
+	sessionRepo repository.SessionRepository
 	emailClient *email.EmailClient
+	redisClient *redis.Client
 	jwtSecret   string
 	tokenExpiry time.Duration
+	config      AuthConfig
 	logger      log.Logger
 	baseURL     string
 }
@@ -86,22 +268,41 @@ type authService struct {
 func NewAuthService(
 	userRepo repository.UserRepository,
 	authRepo repository.AuthRepository,
+	inviteRepo repository.InviteCodeRepository,
+	oauthRepo repository.OAuthRepository,
+	sessionRepo repository.SessionRepository,
 	emailClient *email.EmailClient,
+	redisClient *redis.Client,
 	jwtSecret string,
 	tokenExpiry time.Duration,
+	config AuthConfig,
 	logger log.Logger,
 	baseURL string,
 ) AuthService {
 	if tokenExpiry == 0 {
 		tokenExpiry = DefaultAccessTokenDuration
 	}
+	if config.MaxFailedLoginAttempts <= 0 {
+		config.MaxFailedLoginAttempts = defaultMaxFailedLoginAttempts
+	}
+	if config.LockoutDuration <= 0 {
+		config.LockoutDuration = defaultLockoutDuration
+	}
+	if config.MinPasswordScore <= 0 || config.MinPasswordScore > password.MaxScore {
+		config.MinPasswordScore = password.DefaultMinScore
+	}
 
 	return &authService{
 		userRepo:    userRepo,
 		authRepo:    authRepo,
+		inviteRepo:  inviteRepo,
+		oauthRepo:   oauthRepo,
+		sessionRepo: sessionRepo,
 		emailClient: emailClient,
+		redisClient: redisClient,
 		jwtSecret:   jwtSecret,
 		tokenExpiry: tokenExpiry,
+		config:      config,
 		logger:      logger,
 		baseURL:     baseURL,
 	}
@@ -110,10 +311,24 @@ func NewAuthService(
 func (s *authService) Register(ctx context.Context, input RegisterInput) (*UserDTO, error) {
 	s.logger.Infof("Registering new user with email: %s and username: %s", input.Email, input.Username)
 
-	err := password.ValidatePassword(input.Password, password.DefaultPasswordConfig())
+	err := s.validateNewPassword("password", input.Password, "Invalid password format", input.Username, input.Handle, input.Email)
 	if err != nil {
 		s.logger.Warnf("Password validation failed for new user registration: %v", err)
-		return nil, errors.NewValidationError("Invalid password format", err)
+		return nil, err
+	}
+
+	if err := validateHandle(input.Handle, s.config.ReservedHandles); err != nil {
+		s.logger.Warnf("Registration failed: handle %s rejected: %v", input.Handle, err)
+		return nil, err
+	}
+
+	if err := validateFieldLengths(s.config.FieldLimits.profileChecks(&input.FirstName, &input.LastName, &input.Bio)...); err != nil {
+		return nil, err
+	}
+
+	redirectURI, err := s.ResolveRedirect(input.RedirectURI)
+	if err != nil {
+		return nil, err
 	}
 
 	_, err = s.userRepo.GetUserByEmail(ctx, input.Email)
@@ -134,6 +349,34 @@ func (s *authService) Register(ctx context.Context, input RegisterInput) (*UserD
 		return nil, errors.Wrap(err, "Failed to check existing username")
 	}
 
+	reserved, err := s.userRepo.IsHandleReserved(ctx, input.Handle)
+	if err != nil {
+		s.logger.Errorf("Error checking handle reservation: %v", err)
+		return nil, errors.Wrap(err, "Failed to check handle availability")
+	}
+	if reserved {
+		s.logger.Warnf("Registration failed: handle %s is reserved for a transfer", input.Handle)
+		return nil, errors.NewConflictError("Handle is reserved for a pending transfer", nil)
+	}
+
+	// Consume the invite before creating the user so concurrent signups
+	// cannot overrun it; the use is handed back if registration fails.
+	inviteRedeemed := false
+	if s.config.InviteOnly {
+		if err := s.redeemInviteCode(ctx, input.InviteCode); err != nil {
+			return nil, err
+		}
+		inviteRedeemed = true
+	}
+	registered := false
+	defer func() {
+		if inviteRedeemed && !registered {
+			if err := s.inviteRepo.ReleaseInviteCode(ctx, input.InviteCode); err != nil {
+				s.logger.Warnf("Failed to release invite code after failed registration: %v", err)
+			}
+		}
+	}()
+
 	userParams := repository.CreateUserParams{
 		Username:        input.Username,
 		Handle:          input.Handle,
@@ -183,9 +426,16 @@ func (s *authService) Register(ctx context.Context, input RegisterInput) (*UserD
 		_ = s.userRepo.DeleteUser(ctx, user.UserID)
 		return nil, errors.Wrap(err, "Failed to create auth record")
 	}
+	registered = true
+
+	if inviteRedeemed {
+		if err := s.inviteRepo.RecordRedemption(ctx, input.InviteCode, user.UserID); err != nil {
+			s.logger.Warnf("Failed to record invite code redemption for user %s: %v", user.UserID, err)
+		}
+	}
 
 	// Send verification email
-	err = s.SendVerificationEmail(ctx, user.UserID.String(), user.Username, verificationToken)
+	err = s.sendVerificationEmail(user.Email, user.Username, ptr.GetValueOrEmpty(user.Locale), verificationToken, redirectURI)
 	if err != nil {
 		s.logger.Warnf("Failed to send verification email: %v", err)
 		// Non-critical error, continue with registration
@@ -198,8 +448,9 @@ func (s *authService) Register(ctx context.Context, input RegisterInput) (*UserD
 func (s *authService) Login(ctx context.Context, input LoginInput) (*TokenResponse, error) {
 	s.logger.Infof("Login attempt for email: %s", input.Email)
 
-	// Get user by email
-	user, err := s.userRepo.GetUserByEmail(ctx, input.Email)
+	// Get user by email. Accounts pending deletion are found too, so their
+	// owner can sign in to restore them.
+	user, err := s.userRepo.GetUserByEmailIncludingDeleted(ctx, input.Email)
 	if err != nil {
 		s.logger.Warnf("Login failed: email lookup error: %v", err)
 		if errors.IsNotFound(err) {
@@ -216,41 +467,44 @@ func (s *authService) Login(ctx context.Context, input LoginInput) (*TokenRespon
 	}
 
 	// Check if account is locked
-	if auth.LockedUntil != nil && time.Now().Before(*auth.LockedUntil) {
+	if isLocked(auth) {
 		s.logger.Warnf("Login attempt for locked account: %s until %v", user.UserID, *auth.LockedUntil)
 		return nil, errors.NewForbiddenError("Account is temporarily locked", nil)
 	}
 
+	// Accounts created through OAuth have no password to check
+	if auth.PasswordHash == nil || auth.Salt == nil {
+		s.logger.Warnf("Password login attempted for OAuth-only account %s", user.UserID)
+		return nil, errors.NewUnauthorizedError(s.oauthOnlyMessage(ctx, user.UserID), nil)
+	}
+
 	// Verify password
-	err = password.VerifyPassword(input.Password, auth.PasswordHash, auth.Salt)
+	err = password.VerifyPassword(input.Password, *auth.PasswordHash, *auth.Salt)
 	if err != nil {
 		s.logger.Warnf("Login failed: invalid password for user %s", user.UserID)
 
-		// Increment failed login attempts
-		errIncrement := s.authRepo.IncrementFailedLoginAttempts(ctx, user.UserID)
-		if errIncrement != nil {
-			s.logger.Errorf("Failed to increment login attempts: %v", errIncrement)
-		}
-
-		// Check if account should be locked
-		if auth.FailedLoginAttempts != nil && *auth.FailedLoginAttempts >= 5 {
-			lockUntil := time.Now().Add(15 * time.Minute)
-			s.logger.Warnf("Locking account %s until %v due to multiple failed attempts", user.UserID, lockUntil)
+		s.recordFailedLogin(ctx, user)
+		return nil, errors.NewUnauthorizedError("Invalid credentials", nil)
+	}
 
-			errLock := s.authRepo.SetAccountLockout(ctx, user.UserID, lockUntil)
-			if errLock != nil {
-				s.logger.Errorf("Failed to lock account: %v", errLock)
-			}
+	// Accounts with two-factor enabled get a challenge instead of tokens
+	if auth.TotpEnabled {
+		return s.issueTwoFactorChallenge(user)
+	}
 
-			// Send account locked notification
-			_ = s.SendAccountLockedEmail(ctx, user.UserID.String(), user.Username, lockUntil.Format(time.RFC1123))
-		}
+	return s.completeLogin(ctx, user, input.Client)
+}
 
-		return nil, errors.NewUnauthorizedError("Invalid credentials", nil)
+// completeLogin records the login, starts a new session for the client and
+// issues a token pair bound to it. An account pending deletion gets no
+// session, only a token to restore it.
+func (s *authService) completeLogin(ctx context.Context, user *db.User, client ClientInfo) (*TokenResponse, error) {
+	if user.DeletedAt != nil {
+		return s.issueRestoreToken(user)
 	}
 
 	// Update last login time
-	err = s.authRepo.UpdateLastLogin(ctx, user.UserID)
+	err := s.authRepo.UpdateLastLogin(ctx, user.UserID)
 	if err != nil {
 		s.logger.Warnf("Failed to update last login: %v", err)
 		// Non-critical error, continue with login
@@ -260,6 +514,8 @@ func (s *authService) Login(ctx context.Context, input LoginInput) (*TokenRespon
 	isAdmin := user.IsAdmin != nil && *user.IsAdmin
 	isPremium := user.IsPremium != nil && *user.IsPremium
 
+	sessionID := uuid.New()
+
 	jwtMaker := token.NewJWTMaker(s.jwtSecret)
 	tokenPair, err := jwtMaker.CreateTokenPair(
 		user.UserID.String(),
@@ -267,6 +523,7 @@ func (s *authService) Login(ctx context.Context, input LoginInput) (*TokenRespon
 		user.Email,
 		isAdmin,
 		isPremium,
+		sessionID.String(),
 		s.tokenExpiry,
 		DefaultRefreshTokenDuration,
 	)
@@ -276,11 +533,22 @@ func (s *authService) Login(ctx context.Context, input LoginInput) (*TokenRespon
 		return nil, errors.NewInternalError("Failed to generate authentication tokens", err)
 	}
 
-	// Store refresh token
-	err = s.authRepo.StoreRefreshToken(ctx, user.UserID, tokenPair.RefreshToken)
+	// Start the session holding the refresh token
+	_, err = s.sessionRepo.CreateSession(ctx, repository.CreateSessionParams{
+		SessionID:        sessionID,
+		UserID:           user.UserID,
+		RefreshTokenHash: hashRefreshToken(tokenPair.RefreshToken),
+		UserAgent:        client.UserAgent,
+		IPAddress:        client.IPAddress,
+		ExpiresAt:        time.Now().Add(DefaultRefreshTokenDuration),
+	})
 	if err != nil {
-		s.logger.Errorf("Failed to store refresh token: %v", err)
-		return nil, errors.Wrap(err, "Failed to store refresh token")
+		s.logger.Errorf("Failed to create session: %v", err)
+		return nil, errors.Wrap(err, "Failed to create session")
+	}
+
+	if err := s.storeRefreshToken(ctx, user.UserID, sessionID, tokenPair); err != nil {
+		return nil, err
 	}
 
 	s.logger.Infof("User %s logged in successfully", user.UserID)
@@ -292,6 +560,30 @@ func (s *authService) Login(ctx context.Context, input LoginInput) (*TokenRespon
 	}, nil
 }
 
+func (s *authService) issueTwoFactorChallenge(user *db.User) (*TokenResponse, error) {
+	jwtMaker := token.NewJWTMaker(s.jwtSecret)
+	challenge, expiresAt, err := jwtMaker.CreateToken(
+		user.UserID.String(),
+		user.Username,
+		user.Email,
+		false,
+		false,
+		token.TwoFactorChallenge,
+		TwoFactorChallengeDuration,
+	)
+	if err != nil {
+		s.logger.Errorf("Failed to create two-factor challenge: %v", err)
+		return nil, errors.NewInternalError("Failed to generate authentication tokens", err)
+	}
+
+	s.logger.Infof("User %s passed password check, awaiting two-factor code", user.UserID)
+	return &TokenResponse{
+		ExpiresAt:         expiresAt.Unix(),
+		RequiresTwoFactor: true,
+		ChallengeToken:    challenge,
+	}, nil
+}
+
 func (s *authService) RefreshToken(ctx context.Context, input RefreshTokenRequest) (*TokenResponse, error) {
 	s.logger.Debugf("Processing refresh token request")
 
@@ -314,26 +606,40 @@ func (s *authService) RefreshToken(ctx context.Context, input RefreshTokenReques
 		return nil, errors.NewInternalError("Invalid user identifier in token", err)
 	}
 
-	// Get user and auth info
-	user, err := s.userRepo.GetUser(ctx, userID)
+	sessionID, err := uuid.Parse(claims.SessionID)
 	if err != nil {
-		s.logger.Warnf("User from refresh token not found: %s", userID)
+		s.logger.Warnf("Refresh token for user %s is not bound to a session", userID)
 		return nil, errors.NewUnauthorizedError("Invalid refresh token", nil)
 	}
 
-	auth, err := s.authRepo.GetAuthByUserID(ctx, userID)
+	tokenID, err := uuid.Parse(claims.ID)
+	if err != nil {
+		s.logger.Warnf("Refresh token for user %s has no valid jti", userID)
+		return nil, errors.NewUnauthorizedError("Invalid refresh token", nil)
+	}
+
+	// Get user info
+	user, err := s.userRepo.GetUser(ctx, userID)
 	if err != nil {
-		s.logger.Errorf("Failed to get auth for refresh token: %v", err)
+		s.logger.Warnf("User from refresh token not found: %s", userID)
 		return nil, errors.NewUnauthorizedError("Invalid refresh token", nil)
 	}
 
-	// Verify refresh token matches stored token
-	if auth.RefreshToken == nil || *auth.RefreshToken != input.RefreshToken {
-		s.logger.Warnf("Refresh token doesn't match stored token for user %s", userID)
-		return nil, errors.NewUnauthorizedError("Refresh token has been invalidated", nil)
+	if err := s.checkSessionActive(ctx, userID, claims.SessionID); err != nil {
+		return nil, err
+	}
+
+	// Each refresh token works once; a second use means it was copied
+	consumed, err := s.authRepo.ConsumeRefreshToken(ctx, tokenID)
+	if err != nil {
+		s.logger.Errorf("Failed to consume refresh token %s: %v", tokenID, err)
+		return nil, errors.Wrap(err, "Failed to validate refresh token")
+	}
+	if !consumed {
+		return nil, s.rejectUnusableRefreshToken(ctx, user, sessionID, tokenID)
 	}
 
-	// Create new token pair
+	// Create new token pair for the same session
 	isAdmin := user.IsAdmin != nil && *user.IsAdmin
 	isPremium := user.IsPremium != nil && *user.IsPremium
 
@@ -343,6 +649,7 @@ func (s *authService) RefreshToken(ctx context.Context, input RefreshTokenReques
 		user.Email,
 		isAdmin,
 		isPremium,
+		sessionID.String(),
 		s.tokenExpiry,
 		DefaultRefreshTokenDuration,
 	)
@@ -351,13 +658,28 @@ func (s *authService) RefreshToken(ctx context.Context, input RefreshTokenReques
 		return nil, errors.NewInternalError("Failed to generate new tokens", err)
 	}
 
-	// Store new refresh token
-	err = s.authRepo.StoreRefreshToken(ctx, userID, tokenPair.RefreshToken)
+	// Swap in the new refresh token, which only succeeds if the presented
+	// token is the one the session currently holds and it is not revoked
+	_, err = s.sessionRepo.RotateSessionToken(
+		ctx,
+		sessionID,
+		hashRefreshToken(input.RefreshToken),
+		hashRefreshToken(tokenPair.RefreshToken),
+		time.Now().Add(DefaultRefreshTokenDuration),
+	)
 	if err != nil {
-		s.logger.Errorf("Failed to store new refresh token: %v", err)
+		if errors.IsNotFound(err) {
+			s.logger.Warnf("Refresh token doesn't match session %s for user %s", sessionID, userID)
+			return nil, errors.NewUnauthorizedError("Refresh token has been invalidated", nil)
+		}
+		s.logger.Errorf("Failed to rotate refresh token for session %s: %v", sessionID, err)
 		return nil, errors.Wrap(err, "Failed to store new refresh token")
 	}
 
+	if err := s.storeRefreshToken(ctx, userID, sessionID, tokenPair); err != nil {
+		return nil, err
+	}
+
 	s.logger.Infof("Token refreshed successfully for user %s", userID)
 	return &TokenResponse{
 		AccessToken:  tokenPair.AccessToken,
@@ -367,9 +689,14 @@ func (s *authService) RefreshToken(ctx context.Context, input RefreshTokenReques
 	}, nil
 }
 
-func (s *authService) GenerateResetToken(ctx context.Context, email string) error {
+func (s *authService) GenerateResetToken(ctx context.Context, email, redirectURI string) error {
 	s.logger.Infof("Generating password reset token for email: %s", email)
 
+	redirectURI, err := s.ResolveRedirect(redirectURI)
+	if err != nil {
+		return err
+	}
+
 	// Find user by email
 	user, err := s.userRepo.GetUserByEmail(ctx, email)
 	if err != nil {
@@ -398,7 +725,7 @@ func (s *authService) GenerateResetToken(ctx context.Context, email string) erro
 	}
 
 	// Send password reset email
-	err = s.SendPasswordResetEmail(ctx, user.UserID.String(), user.Username, resetToken)
+	err = s.sendPasswordResetEmail(user.Email, user.Username, ptr.GetValueOrEmpty(user.Locale), resetToken, redirectURI)
 	if err != nil {
 		s.logger.Errorf("Failed to send password reset email: %v", err)
 		return errors.Wrap(err, "Failed to send password reset email")
@@ -418,10 +745,10 @@ func (s *authService) ResetPassword(ctx context.Context, input ResetPasswordInpu
 	}
 
 	// Validate password strength
-	err := password.ValidatePassword(input.NewPassword, password.DefaultPasswordConfig())
+	err := s.validateNewPassword("new_password", input.NewPassword, "Password does not meet requirements", input.Email)
 	if err != nil {
 		s.logger.Warnf("Password reset failed: password validation failed: %v", err)
-		return errors.NewValidationError("Password does not meet requirements", err)
+		return err
 	}
 
 	// Find user by email
@@ -472,7 +799,7 @@ func (s *authService) ResetPassword(ctx context.Context, input ResetPasswordInpu
 	}
 
 	// Send password changed confirmation email
-	err = s.SendPasswordChangedEmail(ctx, user.UserID.String(), user.Username)
+	err = s.SendPasswordChangedEmail(ctx, user.Email, user.Username, ptr.GetValueOrEmpty(user.Locale))
 	if err != nil {
 		s.logger.Warnf("Failed to send password changed notification: %v", err)
 		// Non-critical error, password was reset successfully
@@ -482,78 +809,139 @@ func (s *authService) ResetPassword(ctx context.Context, input ResetPasswordInpu
 	return nil
 }
 
-// func (s *authService) VerifyEmail(ctx context.Context, verificationToken string) error {
-//     s.logger.Infof("Processing email verification for token: %s", verificationToken)
-
-//     // Find user with this verification token
-//     // Note: You'll need to add a repository method to find auth by verification token
-//     // Below is pseudo-code for the repository method you would need to add
-//     /*
-//     GetAuthByVerificationToken(ctx context.Context, token string) (*db.Auth, error)
-//     */
-
-//     // For now, let's assume you need to manually look up auth records
-//     // This is inefficient and would be better with a direct DB query
-//     // Get all users and find the one with the matching verification token
-
-//     // Example implementation (assuming you add the necessary repository method):
-//     auth, err := s.authRepo.GetAuthByVerificationToken(ctx, verificationToken)
-//     if err != nil {
-//         if errors.IsNotFound(err) {
-//             s.logger.Warnf("Email verification failed: invalid token")
-//             return errors.NewUnauthorizedError("Invalid verification token", nil)
-//         }
-//         s.logger.Errorf("Error retrieving auth record: %v", err)
-//         return errors.Wrap(err, "Failed to verify email")
-//     }
-
-//     // Check if token is valid
-//     if auth.VerificationToken == nil || *auth.VerificationToken != verificationToken {
-//         s.logger.Warnf("Email verification failed: invalid token")
-//         return errors.NewUnauthorizedError("Invalid verification token", nil)
-//     }
-
-//     // Check if email is already verified
-//     if auth.IsEmailVerified != nil && *auth.IsEmailVerified {
-//         s.logger.Infof("Email already verified for user ID: %s", auth.UserID)
-//         return nil // Already verified, no error
-//     }
-
-//     // Verify the email
-//     err = s.authRepo.VerifyEmail(ctx, auth.UserID)
-//     if err != nil {
-//         s.logger.Errorf("Failed to verify email: %v", err)
-//         return errors.Wrap(err, "Failed to verify email")
-//     }
-
-//     // Get user information for confirmation email
-//     user, err := s.userRepo.GetUser(ctx, auth.UserID)
-//     if err != nil {
-//         s.logger.Errorf("Failed to retrieve user: %v", err)
-//         return errors.Wrap(err, "Failed to retrieve user")
-//     }
-
-//     data := map[string]interface{}{
-//         "Username": user.Username,
-//         "AppName":  "Your App Name",
-//         "Year":     time.Now().Year(),
-//     }
-
-//     emailErr := s.emailClient.SendTemplate(
-//         []string{user.Email},
-//         "Email Verification Successful",
-//         "verification_success.html",
-//         data,
-//     )
-//     if emailErr != nil {
-//         s.logger.Warnf("Failed to send verification confirmation email: %v", emailErr)
-//     }
-
-//     s.logger.Infof("Email verified successfully for user ID: %s", auth.UserID)
-//     return nil
-// }
-
-func (s *authService) Logout(ctx context.Context, userIDStr string) error {
+func (s *authService) ChangePassword(ctx context.Context, userIDStr, currentPassword, newPassword string) error {
+	s.logger.Infof("Processing password change for user ID: %s", userIDStr)
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		s.logger.Warnf("Password change failed: invalid user ID format: %v", err)
+		return errors.NewBadRequestError("Invalid user ID format", err)
+	}
+
+	user, err := s.userRepo.GetUser(ctx, userID)
+	if err != nil {
+		s.logger.Errorf("Error retrieving user %s: %v", userID, err)
+		return errors.Wrap(err, "Failed to retrieve user")
+	}
+
+	auth, err := s.authRepo.GetAuthByUserID(ctx, userID)
+	if err != nil {
+		s.logger.Errorf("Error retrieving auth for user %s: %v", userID, err)
+		return errors.Wrap(err, "Failed to retrieve authentication information")
+	}
+
+	if isLocked(auth) {
+		s.logger.Warnf("Password change attempt for locked account: %s until %v", userID, *auth.LockedUntil)
+		return errors.NewForbiddenError("Account is temporarily locked", nil)
+	}
+
+	// Accounts created through social sign-in have no password to change
+	if auth.PasswordHash == nil || auth.Salt == nil {
+		s.logger.Warnf("Password change failed: no password set for user %s", userID)
+		return errors.NewBadRequestError("This account does not have a password; use password reset to set one", nil)
+	}
+
+	// A wrong current password is a form error rather than an expired
+	// session, but it still counts towards the lockout
+	err = password.VerifyPassword(currentPassword, *auth.PasswordHash, *auth.Salt)
+	if err != nil {
+		s.logger.Warnf("Password change failed: incorrect current password for user %s", userID)
+		s.recordFailedLogin(ctx, user)
+		return errors.NewValidationError("Current password is incorrect", nil)
+	}
+
+	if newPassword == currentPassword {
+		s.logger.Warnf("Password change failed: new password matches current for user %s", userID)
+		return errors.NewValidationError("New password must be different from the current password", nil)
+	}
+
+	err = s.validateNewPassword("new_password", newPassword, "Password does not meet requirements", user.Username, user.Email)
+	if err != nil {
+		s.logger.Warnf("Password change failed: password validation failed: %v", err)
+		return err
+	}
+
+	newHash, newSalt, err := password.HashPassword(newPassword)
+	if err != nil {
+		s.logger.Errorf("Failed to hash new password: %v", err)
+		return errors.NewInternalError("Failed to secure new password", err)
+	}
+
+	err = s.authRepo.UpdatePassword(ctx, userID, newHash, newSalt)
+	if err != nil {
+		s.logger.Errorf("Failed to update password: %v", err)
+		return errors.Wrap(err, "Failed to update password")
+	}
+
+	// Sign out every session so a leaked refresh token stops working
+	err = s.sessionRepo.RevokeUserSessions(ctx, userID)
+	if err != nil {
+		s.logger.Warnf("Failed to revoke sessions: %v", err)
+		// Non-critical error, password was changed successfully
+	}
+
+	err = s.SendPasswordChangedEmail(ctx, user.Email, user.Username, ptr.GetValueOrEmpty(user.Locale))
+	if err != nil {
+		s.logger.Warnf("Failed to send password changed notification: %v", err)
+		// Non-critical error, password was changed successfully
+	}
+
+	s.logger.Infof("Password changed successfully for user ID: %s", userID)
+	return nil
+}
+
+// Reauthenticate confirms the user's password, and their two-factor code when
+// enabled, before a sensitive account change. Wrong credentials count towards
+// the same lockout as failed sign-ins.
+func (s *authService) Reauthenticate(ctx context.Context, userIDStr, currentPassword, code string) error {
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		s.logger.Warnf("Re-authentication failed: invalid user ID format: %v", err)
+		return errors.NewBadRequestError("Invalid user ID format", err)
+	}
+
+	user, err := s.userRepo.GetUser(ctx, userID)
+	if err != nil {
+		s.logger.Errorf("Error retrieving user %s: %v", userID, err)
+		return errors.Wrap(err, "Failed to retrieve user")
+	}
+
+	auth, err := s.authRepo.GetAuthByUserID(ctx, userID)
+	if err != nil {
+		s.logger.Errorf("Error retrieving auth for user %s: %v", userID, err)
+		return errors.Wrap(err, "Failed to retrieve authentication information")
+	}
+
+	if isLocked(auth) {
+		s.logger.Warnf("Re-authentication attempt for locked account: %s until %v", userID, *auth.LockedUntil)
+		return errors.NewForbiddenError("Account is temporarily locked", nil)
+	}
+
+	if auth.PasswordHash == nil || auth.Salt == nil {
+		return errors.NewForbiddenError("Set a password with a password reset before making this change", nil)
+	}
+
+	if err := password.VerifyPassword(currentPassword, *auth.PasswordHash, *auth.Salt); err != nil {
+		s.logger.Warnf("Re-authentication failed for user %s: wrong password", userID)
+		s.recordFailedLogin(ctx, user)
+		return errors.NewUnauthorizedError("Password is incorrect", nil)
+	}
+
+	if auth.TotpEnabled {
+		if auth.TotpSecret == nil || !totp.Validate(code, *auth.TotpSecret, time.Now(), totpSkew) {
+			s.logger.Warnf("Re-authentication failed for user %s: invalid two-factor code", userID)
+			s.recordFailedLogin(ctx, user)
+			return errors.NewUnauthorizedError("A valid two-factor code is required", nil)
+		}
+	}
+
+	return nil
+}
+
+// Logout signs out the session of the access token used to log out, leaving
+// the user's other devices signed in. That token is also denied for the rest
+// of its lifetime.
+func (s *authService) Logout(ctx context.Context, userIDStr string, accessClaims *token.Claims) error {
 	s.logger.Infof("Processing logout for user ID: %s", userIDStr)
 
 	userID, err := uuid.Parse(userIDStr)
@@ -562,10 +950,20 @@ func (s *authService) Logout(ctx context.Context, userIDStr string) error {
 		return errors.NewValidationError("Invalid user ID format", err)
 	}
 
-	err = s.authRepo.InvalidateRefreshToken(ctx, userID)
-	if err != nil {
-		s.logger.Errorf("Failed to invalidate refresh token: %v", err)
-		return errors.Wrap(err, "Failed to complete logout")
+	if accessClaims == nil || accessClaims.UserID != userID.String() {
+		s.logger.Warnf("Logout failed: access token does not belong to user %s", userID)
+		return errors.NewForbiddenError("You can only log out of your own session", nil)
+	}
+
+	if accessClaims.SessionID != "" {
+		if err := s.RevokeSession(ctx, userID.String(), accessClaims.SessionID); err != nil {
+			s.logger.Errorf("Failed to revoke session %s: %v", accessClaims.SessionID, err)
+			return errors.Wrap(err, "Failed to complete logout")
+		}
+	}
+
+	if err := s.denyAccessToken(ctx, accessClaims); err != nil {
+		s.logger.Warnf("Failed to deny access token on logout: %v", err)
 	}
 
 	s.logger.Infof("User %s logged out successfully", userID)
@@ -589,6 +987,12 @@ func (s *authService) ValidateToken(ctx context.Context, tokenStr string) (*toke
 		return nil, errors.NewUnauthorizedError("Invalid token type", nil)
 	}
 
+	// Tokens denied on logout stay rejected until they expire
+	if s.isAccessTokenDenied(ctx, claims) {
+		s.logger.Warnf("Rejected revoked access token for user %s", claims.UserID)
+		return nil, errors.NewUnauthorizedError("Token has been revoked", nil)
+	}
+
 	// Parse user ID
 	userID, err := uuid.Parse(claims.UserID)
 	if err != nil {
@@ -607,6 +1011,13 @@ func (s *authService) ValidateToken(ctx context.Context, tokenStr string) (*toke
 		return nil, errors.Wrap(err, "Failed to validate user")
 	}
 
+	// Tokens from a revoked session stop working before they expire
+	if claims.SessionID != "" {
+		if err := s.checkSessionActive(ctx, userID, claims.SessionID); err != nil {
+			return nil, err
+		}
+	}
+
 	s.logger.Debugf("Token validated successfully for user %s", userID)
 	return claims, nil
 }
@@ -631,10 +1042,16 @@ func (s *authService) IsEmailVerified(ctx context.Context, userIDStr string) (bo
 	return isVerified, nil
 }
 
-func (s *authService) SendVerificationEmail(ctx context.Context, email, username, token string) error {
+func (s *authService) SendVerificationEmail(ctx context.Context, email, username, locale, token string) error {
+	return s.sendVerificationEmail(email, username, locale, token, "")
+}
+
+// sendVerificationEmail sends the verification link, carrying an already
+// validated redirect target along when one was requested
+func (s *authService) sendVerificationEmail(email, username, locale, token, redirectURI string) error {
 	s.logger.Infof("Sending verification email to: %s", email)
 
-	verificationLink := fmt.Sprintf("%s/verify-email?token=%s", s.baseURL, token)
+	verificationLink := withRedirect(fmt.Sprintf("%s/verify-email?token=%s", s.baseURL, token), redirectURI)
 
 	data := map[string]interface{}{
 		"Username": username,
@@ -643,7 +1060,7 @@ func (s *authService) SendVerificationEmail(ctx context.Context, email, username
 		"Year":     time.Now().Year(),
 	}
 
-	err := s.emailClient.SendTemplate([]string{email}, "Verify Your Email", "verification.html", data)
+	err := s.emailClient.SendLocalizedTemplate([]string{email}, "Verify Your Email", "verification.html", locale, data)
 	if err != nil {
 		s.logger.Errorf("Failed to send verification email: %v", err)
 		return errors.Wrap(err, "Failed to send verification email")
@@ -653,10 +1070,15 @@ func (s *authService) SendVerificationEmail(ctx context.Context, email, username
 	return nil
 }
 
-func (s *authService) SendPasswordResetEmail(ctx context.Context, email, username, token string) error {
+func (s *authService) SendPasswordResetEmail(ctx context.Context, email, username, locale, token string) error {
+	return s.sendPasswordResetEmail(email, username, locale, token, "")
+}
+
+func (s *authService) sendPasswordResetEmail(email, username, locale, token, redirectURI string) error {
 	s.logger.Infof("Sending password reset email to: %s", email)
 
-	resetLink := fmt.Sprintf("%s/reset-password?token=%s&email=%s", s.baseURL, token, url.QueryEscape(email))
+	resetLink := withRedirect(
+		fmt.Sprintf("%s/reset-password?token=%s&email=%s", s.baseURL, token, url.QueryEscape(email)), redirectURI)
 
 	data := map[string]interface{}{
 		"Username": username,
@@ -665,7 +1087,7 @@ func (s *authService) SendPasswordResetEmail(ctx context.Context, email, usernam
 		"Year":     time.Now().Year(),
 	}
 
-	err := s.emailClient.SendTemplate([]string{email}, "Reset Your Password", "password_reset.html", data)
+	err := s.emailClient.SendLocalizedTemplate([]string{email}, "Reset Your Password", "password_reset.html", locale, data)
 	if err != nil {
 		s.logger.Errorf("Failed to send password reset email: %v", err)
 		return errors.Wrap(err, "Failed to send password reset email")
@@ -675,7 +1097,7 @@ func (s *authService) SendPasswordResetEmail(ctx context.Context, email, usernam
 	return nil
 }
 
-func (s *authService) SendPasswordChangedEmail(ctx context.Context, email, username string) error {
+func (s *authService) SendPasswordChangedEmail(ctx context.Context, email, username, locale string) error {
 	s.logger.Infof("Sending password changed notification to: %s", email)
 
 	data := map[string]interface{}{
@@ -684,7 +1106,7 @@ func (s *authService) SendPasswordChangedEmail(ctx context.Context, email, usern
 		"Year":     time.Now().Year(),
 	}
 
-	err := s.emailClient.SendTemplate([]string{email}, "Your Password Has Been Changed", "password_changed.html", data)
+	err := s.emailClient.SendLocalizedTemplate([]string{email}, "Your Password Has Been Changed", "password_changed.html", locale, data)
 	if err != nil {
 		s.logger.Errorf("Failed to send password changed email: %v", err)
 		return errors.Wrap(err, "Failed to send password changed notification")
@@ -694,7 +1116,7 @@ func (s *authService) SendPasswordChangedEmail(ctx context.Context, email, usern
 	return nil
 }
 
-func (s *authService) SendAccountLockedEmail(ctx context.Context, email, username, unlockTime string) error {
+func (s *authService) SendAccountLockedEmail(ctx context.Context, email, username, locale, unlockTime string) error {
 	s.logger.Infof("Sending account locked notification to: %s", email)
 
 	data := map[string]interface{}{
@@ -704,7 +1126,7 @@ func (s *authService) SendAccountLockedEmail(ctx context.Context, email, usernam
 		"UnlockTime": unlockTime,
 	}
 
-	err := s.emailClient.SendTemplate([]string{email}, "Your Account Has Been Temporarily Locked", "account_locked.html", data)
+	err := s.emailClient.SendLocalizedTemplate([]string{email}, "Your Account Has Been Temporarily Locked", "account_locked.html", locale, data)
 	if err != nil {
 		s.logger.Errorf("Failed to send account locked email: %v", err)
 		return errors.Wrap(err, "Failed to send account locked notification")
@@ -714,40 +1136,819 @@ func (s *authService) SendAccountLockedEmail(ctx context.Context, email, usernam
 	return nil
 }
 
-func (s *authService) VerifyEmail(ctx context.Context, token string) error {
-	s.logger.Infof("Verifying email with token")
+// VerifyEmail marks the email of the account that owns the token as
+// verified, consumes the token and sends a confirmation. Tokens are single
+// use; an account that is already verified is left as is.
+func (s *authService) VerifyEmail(ctx context.Context, verificationToken string) error {
+	s.logger.Info("Verifying email with token")
+
+	if strings.TrimSpace(verificationToken) == "" {
+		return errors.NewBadRequestError("Verification token is required", nil)
+	}
 
-	// Find the auth record with this verification token
-	auth, err := s.authRepo.GetAuthByVerificationToken(ctx, token)
+	auth, err := s.authRepo.GetAuthByVerificationToken(ctx, verificationToken)
 	if err != nil {
 		if errors.IsNotFound(err) {
-			s.logger.Warnf("Verification token not found: %s", token)
-			return errors.NewBadRequestError("Invalid or expired verification token", nil)
+			s.logger.Warn("Email verification failed: unknown or already used token")
+			return errors.NewBadRequestError("Verification token is invalid or has already been used", nil)
 		}
 		s.logger.Errorf("Failed to get auth by verification token: %v", err)
 		return errors.Wrap(err, "Failed to verify email")
 	}
 
-	// Check if already verified
 	if auth.IsEmailVerified != nil && *auth.IsEmailVerified {
 		s.logger.Infof("Email already verified for user: %s", auth.UserID)
-		return errors.NewBadRequestError("Email already verified", nil)
+		if err := s.authRepo.ClearVerificationToken(ctx, auth.UserID); err != nil {
+			s.logger.Warnf("Failed to clear verification token for user %s: %v", auth.UserID, err)
+		}
+		return nil
 	}
 
-	// Update the verification status
 	err = s.authRepo.UpdateEmailVerificationStatus(ctx, auth.UserID, true)
 	if err != nil {
 		s.logger.Errorf("Failed to update email verification status: %v", err)
 		return errors.Wrap(err, "Failed to update verification status")
 	}
 
-	// Clear the verification token
-	err = s.authRepo.ClearVerificationToken(ctx, auth.UserID)
+	// The account is verified either way; a stale token only means a second
+	// click is answered as already used
+	if err := s.authRepo.ClearVerificationToken(ctx, auth.UserID); err != nil {
+		s.logger.Warnf("Failed to clear verification token for user %s: %v", auth.UserID, err)
+	}
+
+	user, err := s.userRepo.GetUser(ctx, auth.UserID)
 	if err != nil {
-		s.logger.Errorf("Failed to clear verification token: %v", err)
-		// Not critical, continue
+		s.logger.Warnf("Failed to load user %s for verification confirmation: %v", auth.UserID, err)
+	} else if err := s.sendVerificationSuccessEmail(user.Email, user.Username, ptr.GetValueOrEmpty(user.Locale)); err != nil {
+		s.logger.Warnf("Failed to send verification confirmation email: %v", err)
 	}
 
 	s.logger.Infof("Email verified successfully for user: %s", auth.UserID)
 	return nil
 }
+
+func (s *authService) sendVerificationSuccessEmail(email, username, locale string) error {
+	data := map[string]interface{}{
+		"Username": username,
+		"AppName":  "Your App Name",
+		"Year":     time.Now().Year(),
+	}
+
+	return s.emailClient.SendLocalizedTemplate([]string{email}, "Email Verification Successful", "verification_success.html", locale, data)
+}
+
+func (s *authService) GetVerificationStatuses(ctx context.Context, userIDStrs []string) (map[string]bool, error) {
+	s.logger.Debugf("Checking email verification status for %d users", len(userIDStrs))
+
+	if len(userIDStrs) == 0 {
+		return map[string]bool{}, nil
+	}
+
+	if len(userIDStrs) > maxVerificationStatusBatch {
+		return nil, errors.NewValidationError(
+			fmt.Sprintf("At most %d user IDs can be checked at once", maxVerificationStatusBatch), nil)
+	}
+
+	userIDs := make([]uuid.UUID, len(userIDStrs))
+	for i, idStr := range userIDStrs {
+		userID, err := uuid.Parse(idStr)
+		if err != nil {
+			s.logger.Warnf("Invalid user ID format: %v", err)
+			return nil, errors.NewValidationError(fmt.Sprintf("Invalid user ID format: %s", idStr), err)
+		}
+		userIDs[i] = userID
+	}
+
+	statuses, err := s.authRepo.GetVerificationStatuses(ctx, userIDs)
+	if err != nil {
+		s.logger.Errorf("Failed to get verification statuses: %v", err)
+		return nil, errors.Wrap(err, "Failed to check email verification statuses")
+	}
+
+	result := make(map[string]bool, len(statuses))
+	for userID, verified := range statuses {
+		result[userID.String()] = verified
+	}
+
+	return result, nil
+}
+
+// CleanupUnverifiedAccounts deletes or re-sends verification emails to
+// accounts that are still unverified after input.OlderThan. With DryRun set
+// it only reports which accounts would be affected.
+func (s *authService) CleanupUnverifiedAccounts(ctx context.Context, input CleanupUnverifiedInput) (*UnverifiedCleanupResultDTO, error) {
+	s.logger.Infof("Cleaning up unverified accounts older than %v with action: %s (dry run: %v)",
+		input.OlderThan, input.Action, input.DryRun)
+
+	if input.Action != UnverifiedActionDelete && input.Action != UnverifiedActionRenotify {
+		return nil, errors.NewValidationError("Action must be either 'delete' or 'renotify'", nil)
+	}
+
+	if input.OlderThan <= 0 {
+		return nil, errors.NewValidationError("Age threshold must be positive", nil)
+	}
+
+	if input.Action == UnverifiedActionDelete && s.config.AccountDeleter == nil {
+		return nil, errors.NewInternalError("Account deletion is not available", nil)
+	}
+
+	limit := input.Limit
+	if limit <= 0 {
+		limit = defaultUnverifiedBatch
+	}
+	if limit > maxUnverifiedBatch {
+		limit = maxUnverifiedBatch
+	}
+
+	users, err := s.authRepo.ListUnverifiedUsersCreatedBefore(ctx, time.Now().Add(-input.OlderThan), limit)
+	if err != nil {
+		s.logger.Errorf("Failed to list unverified accounts: %v", err)
+		return nil, errors.Wrap(err, "Failed to list unverified accounts")
+	}
+
+	result := &UnverifiedCleanupResultDTO{
+		Action:  input.Action,
+		DryRun:  input.DryRun,
+		Matched: len(users),
+		Users:   make([]*UnverifiedUserDTO, len(users)),
+	}
+
+	for i, user := range users {
+		result.Users[i] = &UnverifiedUserDTO{
+			UserID:    user.UserID.String(),
+			Username:  user.Username,
+			Email:     user.Email,
+			CreatedAt: user.CreatedAt.Format(time.RFC3339),
+		}
+	}
+
+	if input.DryRun {
+		return result, nil
+	}
+
+	for _, user := range users {
+		var err error
+		switch input.Action {
+		case UnverifiedActionDelete:
+			_, err = s.config.AccountDeleter.RequestAccountDeletion(ctx, user.UserID.String())
+		case UnverifiedActionRenotify:
+			err = s.renotifyUnverifiedUser(ctx, user)
+		}
+
+		if err != nil {
+			s.logger.Warnf("Failed to %s unverified account %s: %v", input.Action, user.UserID, err)
+			result.Failed = append(result.Failed, user.UserID.String())
+			continue
+		}
+		result.Processed++
+	}
+
+	s.logger.Infof("Unverified account cleanup finished: %d of %d processed", result.Processed, result.Matched)
+	return result, nil
+}
+
+func (s *authService) renotifyUnverifiedUser(ctx context.Context, user repository.UnverifiedUser) error {
+	verificationToken, err := token.GenerateVerificationToken()
+	if err != nil {
+		return err
+	}
+
+	if err := s.authRepo.SetVerificationToken(ctx, user.UserID, verificationToken); err != nil {
+		return err
+	}
+
+	return s.SendVerificationEmail(ctx, user.Email, user.Username, user.Locale, verificationToken)
+}
+
+// ResolveRedirect validates a client-supplied post-action redirect target.
+// Relative paths resolve against the API's own origin; absolute URLs must be
+// http(s) and point at that origin or an allowlisted host. An empty target
+// is returned unchanged.
+func (s *authService) ResolveRedirect(redirectURI string) (string, error) {
+	redirectURI = strings.TrimSpace(redirectURI)
+	if redirectURI == "" {
+		return "", nil
+	}
+
+	// Browsers treat backslashes like slashes, so "/\evil.com" would leave
+	// the origin
+	if strings.Contains(redirectURI, "\\") {
+		s.logger.Warnf("Rejected redirect target: %s", redirectURI)
+		return "", errors.NewBadRequestError("Redirect target is not allowed", nil)
+	}
+
+	target, err := url.Parse(redirectURI)
+	if err != nil {
+		return "", errors.NewBadRequestError("Invalid redirect target", err)
+	}
+
+	base, err := url.Parse(s.baseURL)
+	if err != nil {
+		return "", errors.NewInternalError("Invalid base URL", err)
+	}
+
+	if !target.IsAbs() {
+		if target.Host != "" || !strings.HasPrefix(target.Path, "/") {
+			s.logger.Warnf("Rejected redirect target: %s", redirectURI)
+			return "", errors.NewBadRequestError("Redirect target is not allowed", nil)
+		}
+		return base.ResolveReference(target).String(), nil
+	}
+
+	if (target.Scheme != "http" && target.Scheme != "https") || target.User != nil ||
+		!s.isAllowedRedirectHost(base, target) {
+		s.logger.Warnf("Rejected redirect target: %s", redirectURI)
+		return "", errors.NewBadRequestError("Redirect target is not allowed", nil)
+	}
+
+	return target.String(), nil
+}
+
+func (s *authService) isAllowedRedirectHost(base, target *url.URL) bool {
+	host := strings.ToLower(target.Host)
+	if host == strings.ToLower(base.Host) {
+		return true
+	}
+
+	hostname := strings.ToLower(target.Hostname())
+	for _, allowed := range s.config.AllowedRedirectHosts {
+		allowed = strings.ToLower(strings.TrimSpace(allowed))
+		if allowed != "" && (allowed == host || allowed == hostname) {
+			return true
+		}
+	}
+	return false
+}
+
+// withRedirect appends a validated redirect target to an email action link
+func withRedirect(link, redirectURI string) string {
+	if redirectURI == "" {
+		return link
+	}
+	return link + "&redirect_uri=" + url.QueryEscape(redirectURI)
+}
+
+// redeemInviteCode consumes one use of an invite code and explains why the
+// code was rejected when it cannot be used.
+func (s *authService) redeemInviteCode(ctx context.Context, code string) error {
+	if code == "" {
+		s.logger.Warn("Registration rejected: invite code missing")
+		return errors.NewValidationError("Invite code is required", nil)
+	}
+
+	_, err := s.inviteRepo.RedeemInviteCode(ctx, code)
+	if err == nil {
+		return nil
+	}
+	if !errors.IsNotFound(err) {
+		s.logger.Errorf("Error redeeming invite code: %v", err)
+		return errors.Wrap(err, "Failed to redeem invite code")
+	}
+
+	invite, err := s.inviteRepo.GetInviteCode(ctx, code)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			s.logger.Warn("Registration rejected: unknown invite code")
+			return errors.NewValidationError("Invalid invite code", nil)
+		}
+		return errors.Wrap(err, "Failed to retrieve invite code")
+	}
+
+	if invite.ExpiresAt != nil && !time.Now().Before(*invite.ExpiresAt) {
+		s.logger.Warn("Registration rejected: invite code expired")
+		return errors.NewValidationError("Invite code has expired", nil)
+	}
+
+	s.logger.Warn("Registration rejected: invite code exhausted")
+	return errors.NewValidationError("Invite code has been fully used", nil)
+}
+
+func (s *authService) CreateInviteCodes(ctx context.Context, createdBy string, input CreateInviteCodesInput) ([]*InviteCodeDTO, error) {
+	s.logger.Infof("Creating %d invite codes requested by user ID: %s", input.Count, createdBy)
+
+	creatorID, err := uuid.Parse(createdBy)
+	if err != nil {
+		return nil, errors.NewValidationError("Invalid user ID format", err)
+	}
+
+	if input.Count < 1 || input.Count > maxInviteCodesPerRun {
+		return nil, errors.NewValidationError(fmt.Sprintf("Count must be between 1 and %d", maxInviteCodesPerRun), nil)
+	}
+	if input.MaxUses < 1 {
+		input.MaxUses = 1
+	}
+
+	var expiresAt *time.Time
+	if input.ExpiresIn > 0 {
+		t := time.Now().Add(input.ExpiresIn)
+		expiresAt = &t
+	}
+
+	codes := make([]*InviteCodeDTO, 0, input.Count)
+	for i := 0; i < input.Count; i++ {
+		code, err := generateInviteCode()
+		if err != nil {
+			s.logger.Errorf("Failed to generate invite code: %v", err)
+			return nil, errors.NewInternalError("Failed to generate invite code", err)
+		}
+
+		invite, err := s.inviteRepo.CreateInviteCode(ctx, repository.CreateInviteCodeParams{
+			Code:      code,
+			MaxUses:   input.MaxUses,
+			ExpiresAt: expiresAt,
+			CreatedBy: &creatorID,
+		})
+		if err != nil {
+			s.logger.Errorf("Failed to store invite code: %v", err)
+			return nil, errors.Wrap(err, "Failed to create invite code")
+		}
+		codes = append(codes, mapInviteCodeToDTO(invite))
+	}
+
+	s.logger.Infof("Created %d invite codes", len(codes))
+	return codes, nil
+}
+
+func (s *authService) ListInviteCodes(ctx context.Context, limit, offset int) ([]*InviteCodeDTO, error) {
+	s.logger.Debugf("Listing invite codes with limit: %d, offset: %d", limit, offset)
+
+	if limit <= 0 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	invites, err := s.inviteRepo.ListInviteCodes(ctx, limit, offset)
+	if err != nil {
+		s.logger.Errorf("Failed to list invite codes: %v", err)
+		return nil, errors.Wrap(err, "Failed to list invite codes")
+	}
+
+	result := make([]*InviteCodeDTO, len(invites))
+	for i, invite := range invites {
+		result[i] = mapInviteCodeToDTO(invite)
+	}
+	return result, nil
+}
+
+// generateInviteCode returns a random, unambiguous uppercase code
+func generateInviteCode() (string, error) {
+	buf := make([]byte, inviteCodeLength)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	code := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(buf)
+	return code[:inviteCodeLength], nil
+}
+
+func mapInviteCodeToDTO(invite *db.InviteCode) *InviteCodeDTO {
+	dto := &InviteCodeDTO{
+		Code:      invite.Code,
+		MaxUses:   int(invite.MaxUses),
+		UsedCount: int(invite.UsedCount),
+	}
+	if invite.ExpiresAt != nil {
+		dto.ExpiresAt = invite.ExpiresAt.Format(time.RFC3339)
+	}
+	if invite.CreatedBy != nil {
+		dto.CreatedBy = invite.CreatedBy.String()
+	}
+	if invite.CreatedAt != nil {
+		dto.CreatedAt = invite.CreatedAt.Format(time.RFC3339)
+	}
+	return dto
+}
+
+// EnableTOTP starts two-factor setup by generating a new secret and recovery
+// codes. Two-factor is only switched on once VerifyTOTP accepts a code, so a
+// user who abandons setup is not locked out.
+func (s *authService) EnableTOTP(ctx context.Context, userIDStr string) (*TOTPSetupDTO, error) {
+	s.logger.Infof("Starting two-factor setup for user ID: %s", userIDStr)
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		return nil, errors.NewBadRequestError("Invalid user ID format", err)
+	}
+
+	user, err := s.userRepo.GetUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	auth, err := s.authRepo.GetAuthByUserID(ctx, userID)
+	if err != nil {
+		s.logger.Errorf("Error retrieving auth for user %s: %v", userID, err)
+		return nil, errors.Wrap(err, "Failed to retrieve authentication information")
+	}
+
+	if auth.TotpEnabled {
+		return nil, errors.NewConflictError("Two-factor authentication is already enabled", nil)
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		s.logger.Errorf("Failed to generate TOTP secret: %v", err)
+		return nil, errors.NewInternalError("Failed to generate two-factor secret", err)
+	}
+
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+	for i := range codes {
+		code, err := generateRecoveryCode()
+		if err != nil {
+			s.logger.Errorf("Failed to generate recovery code: %v", err)
+			return nil, errors.NewInternalError("Failed to generate recovery codes", err)
+		}
+		codes[i] = code
+		hashes[i] = hashRecoveryCode(code)
+	}
+
+	if err := s.authRepo.SetTOTPSecret(ctx, userID, secret, hashes); err != nil {
+		return nil, errors.Wrap(err, "Failed to store two-factor secret")
+	}
+
+	s.logger.Infof("Two-factor setup pending verification for user ID: %s", userID)
+	return &TOTPSetupDTO{
+		Secret:        secret,
+		OTPAuthURI:    totp.URI(totpIssuer, user.Email, secret),
+		RecoveryCodes: codes,
+	}, nil
+}
+
+// VerifyTOTP checks a code against the user's secret. The first valid code
+// after EnableTOTP turns two-factor on.
+func (s *authService) VerifyTOTP(ctx context.Context, userIDStr, code string) error {
+	s.logger.Infof("Verifying two-factor code for user ID: %s", userIDStr)
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		return errors.NewBadRequestError("Invalid user ID format", err)
+	}
+
+	auth, err := s.authRepo.GetAuthByUserID(ctx, userID)
+	if err != nil {
+		s.logger.Errorf("Error retrieving auth for user %s: %v", userID, err)
+		return errors.Wrap(err, "Failed to retrieve authentication information")
+	}
+
+	if auth.TotpSecret == nil {
+		return errors.NewBadRequestError("Two-factor authentication has not been set up", nil)
+	}
+
+	if !totp.Validate(code, *auth.TotpSecret, time.Now(), totpSkew) {
+		s.logger.Warnf("Invalid two-factor code for user ID: %s", userID)
+		return errors.NewUnauthorizedError("Invalid two-factor code", nil)
+	}
+
+	if auth.TotpEnabled {
+		return nil
+	}
+
+	if err := s.authRepo.EnableTOTP(ctx, userID); err != nil {
+		return errors.Wrap(err, "Failed to enable two-factor authentication")
+	}
+
+	s.logger.Infof("Two-factor authentication enabled for user ID: %s", userID)
+	return nil
+}
+
+// LoginWithTOTP exchanges a challenge token from Login and a TOTP or recovery
+// code for a token pair. Each recovery code works only once.
+func (s *authService) LoginWithTOTP(ctx context.Context, input TOTPLoginInput) (*TokenResponse, error) {
+	s.logger.Debugf("Processing two-factor login")
+
+	jwtMaker := token.NewJWTMaker(s.jwtSecret)
+	claims, err := jwtMaker.VerifyToken(input.ChallengeToken)
+	if err != nil {
+		s.logger.Warnf("Invalid two-factor challenge: %v", err)
+		return nil, errors.NewUnauthorizedError("Invalid or expired challenge token", err)
+	}
+
+	if claims.TokenType != token.TwoFactorChallenge {
+		s.logger.Warnf("Wrong token type provided for two-factor login: %s", claims.TokenType)
+		return nil, errors.NewUnauthorizedError("Invalid token type", nil)
+	}
+
+	userID, err := uuid.Parse(claims.UserID)
+	if err != nil {
+		return nil, errors.NewUnauthorizedError("Invalid or expired challenge token", err)
+	}
+
+	user, err := s.userRepo.GetUserIncludingDeleted(ctx, userID)
+	if err != nil {
+		s.logger.Warnf("User from two-factor challenge not found: %s", userID)
+		return nil, errors.NewUnauthorizedError("Invalid or expired challenge token", nil)
+	}
+
+	auth, err := s.authRepo.GetAuthByUserID(ctx, userID)
+	if err != nil {
+		s.logger.Errorf("Error retrieving auth for user %s: %v", userID, err)
+		return nil, errors.Wrap(err, "Failed to retrieve authentication information")
+	}
+
+	if !auth.TotpEnabled || auth.TotpSecret == nil {
+		return nil, errors.NewUnauthorizedError("Invalid or expired challenge token", nil)
+	}
+
+	if auth.LockedUntil != nil && time.Now().Before(*auth.LockedUntil) {
+		s.logger.Warnf("Two-factor login attempt for locked account: %s until %v", userID, *auth.LockedUntil)
+		return nil, errors.NewForbiddenError("Account is temporarily locked", nil)
+	}
+
+	if totp.Validate(input.Code, *auth.TotpSecret, time.Now(), totpSkew) {
+		return s.completeLogin(ctx, user, input.Client)
+	}
+
+	consumed, err := s.authRepo.ConsumeRecoveryCode(ctx, userID, hashRecoveryCode(input.Code))
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to check recovery code")
+	}
+	if consumed {
+		s.logger.Infof("User %s logged in with a recovery code", userID)
+		return s.completeLogin(ctx, user, input.Client)
+	}
+
+	s.logger.Warnf("Two-factor login failed: invalid code for user %s", userID)
+	s.recordFailedLogin(ctx, user)
+
+	return nil, errors.NewUnauthorizedError("Invalid two-factor code", nil)
+}
+
+// isLocked reports whether the account is inside a lockout period
+func isLocked(auth *db.Auth) bool {
+	return auth.LockedUntil != nil && time.Now().Before(*auth.LockedUntil)
+}
+
+// recordFailedLogin counts a failed sign-in and locks the account once the
+// updated count reaches the configured threshold. Locking resets the count,
+// so a lapsed lock gives the full number of attempts again.
+func (s *authService) recordFailedLogin(ctx context.Context, user *db.User) {
+	attempts, err := s.authRepo.IncrementFailedLoginAttempts(ctx, user.UserID)
+	if err != nil {
+		s.logger.Errorf("Failed to increment login attempts: %v", err)
+		return
+	}
+
+	if attempts < s.config.MaxFailedLoginAttempts {
+		return
+	}
+
+	lockUntil := time.Now().Add(s.config.LockoutDuration)
+	s.logger.Warnf("Locking account %s until %v after %d failed attempts", user.UserID, lockUntil, attempts)
+
+	if err := s.authRepo.SetAccountLockout(ctx, user.UserID, lockUntil); err != nil {
+		s.logger.Errorf("Failed to lock account: %v", err)
+	}
+
+	// Send account locked notification
+	_ = s.SendAccountLockedEmail(ctx, user.Email, user.Username, ptr.GetValueOrEmpty(user.Locale), lockUntil.Format(time.RFC1123))
+}
+
+// generateRecoveryCode returns a code formatted as xxxxx-xxxxx
+func generateRecoveryCode() (string, error) {
+	buf := make([]byte, recoveryCodeLength)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	code := strings.ToLower(base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(buf))
+	return code[:recoveryCodeLength/2] + "-" + code[recoveryCodeLength/2:recoveryCodeLength], nil
+}
+
+// hashRecoveryCode normalises case and separators so codes can be typed
+// loosely, then hashes them for storage
+func hashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
+
+// OAuthAuthorizeURL returns the provider's consent page URL for starting a
+// sign-in. The caller is responsible for generating and checking state.
+func (s *authService) OAuthAuthorizeURL(providerName, state string) (string, error) {
+	provider, ok := s.config.OAuthProviders[providerName]
+	if !ok {
+		return "", errors.NewBadRequestError(fmt.Sprintf("Unsupported OAuth provider: %s", providerName), nil)
+	}
+	return provider.AuthCodeURL(state), nil
+}
+
+// OAuthLogin completes a provider sign-in. A known provider identity logs in
+// its linked user; otherwise the identity is linked to the user with the same
+// verified email, or a new passwordless account is created for it.
+func (s *authService) OAuthLogin(ctx context.Context, providerName, code string, client ClientInfo) (*TokenResponse, error) {
+	s.logger.Infof("OAuth login with provider: %s", providerName)
+
+	provider, ok := s.config.OAuthProviders[providerName]
+	if !ok {
+		return nil, errors.NewBadRequestError(fmt.Sprintf("Unsupported OAuth provider: %s", providerName), nil)
+	}
+
+	if code == "" {
+		return nil, errors.NewBadRequestError("Authorization code is required", nil)
+	}
+
+	accessToken, err := provider.Exchange(ctx, code)
+	if err != nil {
+		s.logger.Warnf("OAuth code exchange with %s failed: %v", providerName, err)
+		return nil, errors.NewUnauthorizedError("Failed to sign in with "+providerName, err)
+	}
+
+	profile, err := provider.FetchProfile(ctx, accessToken)
+	if err != nil {
+		s.logger.Errorf("Failed to fetch %s profile: %v", providerName, err)
+		return nil, errors.NewExternalServiceError("Failed to fetch profile from "+providerName, err)
+	}
+
+	user, err := s.resolveOAuthUser(ctx, providerName, profile)
+	if err != nil {
+		return nil, err
+	}
+
+	auth, err := s.authRepo.GetAuthByUserID(ctx, user.UserID)
+	if err != nil {
+		s.logger.Errorf("Error retrieving auth for user %s: %v", user.UserID, err)
+		return nil, errors.Wrap(err, "Failed to retrieve authentication information")
+	}
+
+	if auth.LockedUntil != nil && time.Now().Before(*auth.LockedUntil) {
+		s.logger.Warnf("OAuth login attempt for locked account: %s until %v", user.UserID, *auth.LockedUntil)
+		return nil, errors.NewForbiddenError("Account is temporarily locked", nil)
+	}
+
+	if auth.TotpEnabled {
+		return s.issueTwoFactorChallenge(user)
+	}
+
+	return s.completeLogin(ctx, user, client)
+}
+
+func (s *authService) resolveOAuthUser(ctx context.Context, providerName string, profile *oauth.Profile) (*db.User, error) {
+	account, err := s.oauthRepo.GetAccount(ctx, providerName, profile.ID)
+	if err == nil {
+		return s.userRepo.GetUser(ctx, account.UserID)
+	}
+	if !errors.IsNotFound(err) {
+		return nil, errors.Wrap(err, "Failed to look up linked account")
+	}
+
+	if profile.Email == "" || !profile.EmailVerified {
+		s.logger.Warnf("%s account %s has no verified email", providerName, profile.ID)
+		return nil, errors.NewBadRequestError(
+			fmt.Sprintf("Your %s account has no verified email address", providerName), nil)
+	}
+
+	user, err := s.userRepo.GetUserByEmail(ctx, profile.Email)
+	switch {
+	case err == nil:
+		// Linking to an unverified account would let whoever registered the
+		// address with a password share the provider identity's account
+		verified, err := s.IsEmailVerified(ctx, user.UserID.String())
+		if err != nil {
+			return nil, err
+		}
+		if !verified {
+			return nil, errors.NewConflictError(
+				"An account with this email exists but is not verified. Verify it or sign in with your password first", nil)
+		}
+	case errors.IsNotFound(err):
+		user, err = s.createOAuthUser(ctx, profile)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, errors.Wrap(err, "Failed to retrieve user")
+	}
+
+	_, err = s.oauthRepo.LinkAccount(ctx, repository.LinkOAuthAccountParams{
+		UserID:         user.UserID,
+		Provider:       providerName,
+		ProviderUserID: profile.ID,
+		Email:          profile.Email,
+		Name:           profile.Name,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to link "+providerName+" account")
+	}
+
+	s.logger.Infof("Linked %s account %s to user %s", providerName, profile.ID, user.UserID)
+	return user, nil
+}
+
+// createOAuthUser registers a passwordless account for a provider identity,
+// deriving the username and handle from the provider's profile
+func (s *authService) createOAuthUser(ctx context.Context, profile *oauth.Profile) (*db.User, error) {
+	if s.config.InviteOnly {
+		return nil, errors.NewForbiddenError("Registration requires an invite code", nil)
+	}
+
+	name, err := s.generateOAuthUsername(ctx, profile)
+	if err != nil {
+		return nil, err
+	}
+
+	firstName, lastName, _ := strings.Cut(strings.TrimSpace(profile.Name), " ")
+
+	user, err := s.userRepo.CreateUser(ctx, repository.CreateUserParams{
+		Username:  name,
+		Handle:    name,
+		Email:     profile.Email,
+		FirstName: firstName,
+		LastName:  lastName,
+	})
+	if err != nil {
+		s.logger.Errorf("Failed to create user for OAuth sign-in: %v", err)
+		return nil, errors.Wrap(err, "Failed to create user")
+	}
+
+	err = s.authRepo.CreateAuth(ctx, repository.CreateAuthParams{
+		UserID:          user.UserID,
+		IsEmailVerified: true,
+	})
+	if err != nil {
+		s.logger.Errorf("Failed to create auth record: %v", err)
+		_ = s.userRepo.DeleteUser(ctx, user.UserID)
+		return nil, errors.Wrap(err, "Failed to create auth record")
+	}
+
+	s.logger.Infof("Created user %s from OAuth sign-in", user.UserID)
+	return user, nil
+}
+
+// generateOAuthUsername picks a free name usable as both username and
+// handle, based on the provider username or the email's local part
+func (s *authService) generateOAuthUsername(ctx context.Context, profile *oauth.Profile) (string, error) {
+	source := profile.Username
+	if source == "" {
+		source, _, _ = strings.Cut(profile.Email, "@")
+	}
+
+	var base strings.Builder
+	for _, r := range strings.ToLower(source) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '_' {
+			base.WriteRune(r)
+		}
+		if base.Len() == maxOAuthUsernameBase {
+			break
+		}
+	}
+
+	candidate := base.String()
+	if len(candidate) < 3 || s.config.ReservedHandles.IsReserved(candidate) {
+		candidate = "user"
+	}
+
+	for attempt := 0; attempt < oauthUsernameAttempts; attempt++ {
+		name := candidate
+		if attempt > 0 {
+			suffix, err := token.GenerateRandomString(4, "abcdefghijklmnopqrstuvwxyz0123456789")
+			if err != nil {
+				return "", errors.NewInternalError("Failed to generate username", err)
+			}
+			name = candidate + "_" + suffix
+		}
+
+		available, err := s.isNameAvailable(ctx, name)
+		if err != nil {
+			return "", err
+		}
+		if available {
+			return name, nil
+		}
+	}
+
+	return "", errors.NewConflictError("Could not find a free username, please register with a password instead", nil)
+}
+
+func (s *authService) isNameAvailable(ctx context.Context, name string) (bool, error) {
+	if s.config.ReservedHandles.IsReserved(name) {
+		return false, nil
+	}
+
+	if _, err := s.userRepo.GetUserByUsername(ctx, name); err == nil {
+		return false, nil
+	} else if !errors.IsNotFound(err) {
+		return false, errors.Wrap(err, "Failed to check existing username")
+	}
+
+	if _, err := s.userRepo.GetUserByHandle(ctx, name); err == nil {
+		return false, nil
+	} else if !errors.IsNotFound(err) {
+		return false, errors.Wrap(err, "Failed to check existing handle")
+	}
+
+	reserved, err := s.userRepo.IsHandleReserved(ctx, name)
+	if err != nil {
+		return false, errors.Wrap(err, "Failed to check handle availability")
+	}
+	return !reserved, nil
+}
+
+// oauthOnlyMessage tells a user without a password which provider to use
+func (s *authService) oauthOnlyMessage(ctx context.Context, userID uuid.UUID) string {
+	providers, err := s.oauthRepo.ListUserProviders(ctx, userID)
+	if err != nil || len(providers) == 0 {
+		return "This account has no password. Sign in with the provider you registered with"
+	}
+	return fmt.Sprintf("This account has no password. Sign in with %s instead", strings.Join(providers, " or "))
+}
@@ -0,0 +1,202 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	db "github.com/0xsj/mios.io/db/sqlc"
+	"github.com/0xsj/mios.io/pkg/errors"
+	"github.com/0xsj/mios.io/pkg/ptr"
+	"github.com/0xsj/mios.io/pkg/token"
+	"github.com/0xsj/mios.io/repository"
+	"github.com/google/uuid"
+)
+
+// SessionDTO is one signed-in device. Current marks the session the request
+// listing it was made from.
+type SessionDTO struct {
+	ID         string `json:"id"`
+	UserAgent  string `json:"user_agent,omitempty"`
+	IPAddress  string `json:"ip_address,omitempty"`
+	CreatedAt  string `json:"created_at,omitempty"`
+	LastUsedAt string `json:"last_used_at,omitempty"`
+	Current    bool   `json:"current"`
+}
+
+// ListSessions returns the user's active sessions, most recently used first
+func (s *authService) ListSessions(ctx context.Context, userIDStr string) ([]SessionDTO, error) {
+	s.logger.Debugf("Listing sessions for user ID: %s", userIDStr)
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		s.logger.Warnf("Invalid user ID format: %v", err)
+		return nil, errors.NewBadRequestError("Invalid user ID format", err)
+	}
+
+	sessions, err := s.sessionRepo.ListActiveSessions(ctx, userID)
+	if err != nil {
+		s.logger.Errorf("Failed to list sessions for user %s: %v", userID, err)
+		return nil, errors.Wrap(err, "Failed to retrieve sessions")
+	}
+
+	dtos := make([]SessionDTO, len(sessions))
+	for i, session := range sessions {
+		dtos[i] = mapSessionToDTO(session)
+	}
+	return dtos, nil
+}
+
+// RevokeSession signs out one of the user's sessions. Its refresh token stops
+// working immediately, as do access tokens issued to it.
+func (s *authService) RevokeSession(ctx context.Context, userIDStr, sessionIDStr string) error {
+	s.logger.Infof("Revoking session %s for user ID: %s", sessionIDStr, userIDStr)
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		s.logger.Warnf("Invalid user ID format: %v", err)
+		return errors.NewBadRequestError("Invalid user ID format", err)
+	}
+
+	sessionID, err := uuid.Parse(sessionIDStr)
+	if err != nil {
+		s.logger.Warnf("Invalid session ID format: %v", err)
+		return errors.NewBadRequestError("Invalid session ID format", err)
+	}
+
+	revoked, err := s.sessionRepo.RevokeSession(ctx, userID, sessionID)
+	if err != nil {
+		s.logger.Errorf("Failed to revoke session %s: %v", sessionID, err)
+		return errors.Wrap(err, "Failed to revoke session")
+	}
+	if !revoked {
+		return errors.NewNotFoundError("Session not found", nil)
+	}
+
+	s.logger.Infof("Session %s revoked for user %s", sessionID, userID)
+	return nil
+}
+
+// checkSessionActive rejects tokens whose session was revoked or has expired
+func (s *authService) checkSessionActive(ctx context.Context, userID uuid.UUID, sessionIDStr string) error {
+	sessionID, err := uuid.Parse(sessionIDStr)
+	if err != nil {
+		return errors.NewUnauthorizedError("Invalid token", err)
+	}
+
+	session, err := s.sessionRepo.GetSession(ctx, sessionID)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			s.logger.Warnf("Token validation failed: session %s not found", sessionID)
+			return errors.NewUnauthorizedError("Session has ended", nil)
+		}
+		s.logger.Errorf("Error retrieving session for token validation: %v", err)
+		return errors.Wrap(err, "Failed to validate session")
+	}
+
+	if session.UserID != userID || session.RevokedAt != nil || time.Now().After(session.ExpiresAt) {
+		s.logger.Warnf("Token validation failed: session %s is no longer active", sessionID)
+		return errors.NewUnauthorizedError("Session has ended", nil)
+	}
+
+	return nil
+}
+
+// storeRefreshToken records a newly issued refresh token in its session's family
+func (s *authService) storeRefreshToken(ctx context.Context, userID, sessionID uuid.UUID, tokenPair *token.TokenPair) error {
+	tokenID, err := uuid.Parse(tokenPair.RefreshTokenID)
+	if err != nil {
+		return errors.NewInternalError("Failed to generate authentication tokens", err)
+	}
+
+	err = s.authRepo.StoreRefreshToken(ctx, repository.StoreRefreshTokenParams{
+		TokenID:   tokenID,
+		SessionID: sessionID,
+		UserID:    userID,
+		ExpiresAt: time.Now().Add(DefaultRefreshTokenDuration),
+	})
+	if err != nil {
+		s.logger.Errorf("Failed to store refresh token: %v", err)
+		return errors.Wrap(err, "Failed to store refresh token")
+	}
+	return nil
+}
+
+// rejectUnusableRefreshToken explains why a refresh token could not be
+// consumed. A token that was already used has been replayed, so the whole
+// session is revoked and the user is warned by email.
+func (s *authService) rejectUnusableRefreshToken(ctx context.Context, user *db.User, sessionID, tokenID uuid.UUID) error {
+	issued, err := s.authRepo.GetRefreshToken(ctx, tokenID)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			s.logger.Warnf("Unknown refresh token %s presented for session %s", tokenID, sessionID)
+			return errors.NewUnauthorizedError("Invalid refresh token", nil)
+		}
+		return errors.Wrap(err, "Failed to validate refresh token")
+	}
+
+	if issued.ConsumedAt == nil {
+		return errors.NewUnauthorizedError("Refresh token has expired", nil)
+	}
+
+	s.logger.Warnf("Refresh token %s reused for session %s of user %s; revoking the session", tokenID, sessionID, user.UserID)
+
+	if _, err := s.sessionRepo.RevokeSession(ctx, user.UserID, sessionID); err != nil {
+		s.logger.Errorf("Failed to revoke session %s after refresh token reuse: %v", sessionID, err)
+	}
+	if err := s.authRepo.InvalidateRefreshTokenFamily(ctx, sessionID); err != nil {
+		s.logger.Errorf("Failed to invalidate refresh tokens of session %s: %v", sessionID, err)
+	}
+
+	session, err := s.sessionRepo.GetSession(ctx, sessionID)
+	if err != nil {
+		s.logger.Warnf("Failed to load session %s for security alert: %v", sessionID, err)
+	}
+	if err := s.sendRefreshTokenReuseEmail(user, session); err != nil {
+		s.logger.Warnf("Failed to send security alert to user %s: %v", user.UserID, err)
+	}
+
+	return errors.NewUnauthorizedError("Refresh token has already been used; please sign in again", nil)
+}
+
+func (s *authService) sendRefreshTokenReuseEmail(user *db.User, session *db.AuthSession) error {
+	data := map[string]interface{}{
+		"Username": user.Username,
+		"AppName":  "Your App Name",
+		"Year":     time.Now().Year(),
+		"Time":     time.Now().UTC().Format("January 2, 2006 15:04 MST"),
+		"Link":     fmt.Sprintf("%s/settings/security", s.baseURL),
+	}
+	if session != nil {
+		data["UserAgent"] = ptr.GetValueOrEmpty(session.UserAgent)
+		data["IPAddress"] = ptr.GetValueOrEmpty(session.IpAddress)
+	}
+
+	return s.emailClient.SendLocalizedTemplate([]string{user.Email}, "Security Alert: A Session Was Signed Out",
+		"security_alert.html", ptr.GetValueOrEmpty(user.Locale), data)
+}
+
+// hashRefreshToken is how refresh tokens are stored on their session
+func hashRefreshToken(refreshToken string) string {
+	sum := sha256.Sum256([]byte(refreshToken))
+	return hex.EncodeToString(sum[:])
+}
+
+func mapSessionToDTO(session *db.AuthSession) SessionDTO {
+	dto := SessionDTO{
+		ID:        session.SessionID.String(),
+		UserAgent: ptr.GetValueOrEmpty(session.UserAgent),
+		IPAddress: ptr.GetValueOrEmpty(session.IpAddress),
+	}
+
+	if session.CreatedAt != nil {
+		dto.CreatedAt = session.CreatedAt.Format(time.RFC3339)
+	}
+	if session.LastUsedAt != nil {
+		dto.LastUsedAt = session.LastUsedAt.Format(time.RFC3339)
+	}
+
+	return dto
+}
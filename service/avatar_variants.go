@@ -0,0 +1,306 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/0xsj/mios.io/pkg/errors"
+	"github.com/0xsj/mios.io/pkg/storage"
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp" // registers the WebP decoder
+)
+
+// AvatarVariantSizes are the square sizes, in pixels, every avatar upload
+// is resized to. Variants are keyed by size in FileUploadResult.Variants.
+var AvatarVariantSizes = []int{64, 128, 256}
+
+// maxAvatarPixels guards against decompression bombs: a small file can
+// declare a huge canvas, which would be allocated in full when decoded
+const maxAvatarPixels = 40_000_000
+
+// Formats avatars can be decoded from, as named by the image package
+var avatarFormats = map[string]bool{
+	"jpeg": true,
+	"png":  true,
+	"webp": true,
+}
+
+// avatarImage is a decoded avatar upload
+type avatarImage struct {
+	img         image.Image
+	format      string
+	orientation int // EXIF orientation of JPEG uploads, 1 when absent
+}
+
+// decodeAvatar decodes an avatar upload, rejecting formats that can't be
+// resized, animations and canvases too large to decode safely
+func decodeAvatar(data []byte) (*avatarImage, error) {
+	config, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil || !avatarFormats[format] {
+		return nil, errors.NewValidationError("Avatar must be a JPEG, PNG or WebP image", err)
+	}
+	if config.Width <= 0 || config.Height <= 0 || config.Width*config.Height > maxAvatarPixels {
+		return nil, errors.NewValidationError(
+			fmt.Sprintf("Avatar dimensions %dx%d are not supported", config.Width, config.Height), nil)
+	}
+	if isAnimatedImage(data, format) {
+		return nil, errors.NewValidationError("Animated avatars are not supported", nil)
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, errors.NewValidationError("Avatar image could not be decoded", err)
+	}
+
+	orientation := 1
+	if format == "jpeg" {
+		orientation = jpegOrientation(data)
+	}
+	return &avatarImage{img: img, format: format, orientation: orientation}, nil
+}
+
+// isAnimatedImage spots animated PNGs (an acTL chunk ahead of the image
+// data) and animated WebPs (the animation flag of the VP8X header). The
+// decoders would silently return the first frame of either.
+func isAnimatedImage(data []byte, format string) bool {
+	switch format {
+	case "png":
+		// Chunks follow the 8 byte signature: length, type, data, CRC
+		for offset := 8; offset+8 <= len(data); {
+			length := int(binary.BigEndian.Uint32(data[offset:]))
+			switch string(data[offset+4 : offset+8]) {
+			case "acTL":
+				return true
+			case "IDAT", "IEND":
+				return false
+			}
+			offset += 12 + length
+		}
+	case "webp":
+		// RIFF header, then a VP8X chunk whose first byte holds the flags
+		if len(data) >= 21 && string(data[12:16]) == "VP8X" {
+			return data[20]&0x02 != 0
+		}
+	}
+	return false
+}
+
+// jpegOrientation reads the EXIF orientation tag (1-8) of a JPEG, or
+// returns 1 when there is none
+func jpegOrientation(data []byte) int {
+	// Walk the segments after SOI until the image data starts
+	for offset := 2; offset+4 <= len(data) && data[offset] == 0xFF; {
+		marker := data[offset+1]
+		length := int(binary.BigEndian.Uint16(data[offset+2:]))
+		if marker == 0xDA || length < 2 || offset+2+length > len(data) {
+			break
+		}
+		segment := data[offset+4 : offset+2+length]
+		if marker == 0xE1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			return exifOrientation(segment[6:])
+		}
+		offset += 2 + length
+	}
+	return 1
+}
+
+// exifOrientation finds the orientation tag in the first IFD of a TIFF
+// structure
+func exifOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+
+	ifd := int(order.Uint32(tiff[4:]))
+	if ifd+2 > len(tiff) {
+		return 1
+	}
+	entries := int(order.Uint16(tiff[ifd:]))
+	for i := 0; i < entries; i++ {
+		entry := ifd + 2 + i*12
+		if entry+12 > len(tiff) {
+			break
+		}
+		if order.Uint16(tiff[entry:]) == 0x0112 {
+			orientation := int(order.Uint16(tiff[entry+8:]))
+			if orientation < 1 || orientation > 8 {
+				return 1
+			}
+			return orientation
+		}
+	}
+	return 1
+}
+
+// avatarVariant center-crops the avatar to a square and scales it to size
+// pixels, or to the crop's size when that is smaller. A centered square is
+// unchanged by every EXIF rotation and flip, so the orientation is applied
+// after scaling, to the much smaller image.
+func avatarVariant(avatar *avatarImage, size int) image.Image {
+	bounds := avatar.img.Bounds()
+	side := min(bounds.Dx(), bounds.Dy())
+	crop := image.Rect(0, 0, side, side).Add(image.Pt(
+		bounds.Min.X+(bounds.Dx()-side)/2,
+		bounds.Min.Y+(bounds.Dy()-side)/2,
+	))
+
+	size = min(size, side)
+	scaled := image.NewNRGBA(image.Rect(0, 0, size, size))
+	draw.CatmullRom.Scale(scaled, scaled.Bounds(), avatar.img, crop, draw.Src, nil)
+
+	return orientImage(scaled, avatar.orientation)
+}
+
+// orientImage applies an EXIF orientation to a square image, so the pixels
+// are upright and the variant needs no orientation tag
+func orientImage(img *image.NRGBA, orientation int) *image.NRGBA {
+	if orientation <= 1 || orientation > 8 {
+		return img
+	}
+
+	n := img.Bounds().Dx()
+	last := n - 1
+	oriented := image.NewNRGBA(img.Bounds())
+	for y := 0; y < n; y++ {
+		for x := 0; x < n; x++ {
+			// Source pixel shown at (x, y) once the orientation is applied
+			var sx, sy int
+			switch orientation {
+			case 2: // mirrored
+				sx, sy = last-x, y
+			case 3: // rotated 180°
+				sx, sy = last-x, last-y
+			case 4: // flipped vertically
+				sx, sy = x, last-y
+			case 5: // transposed
+				sx, sy = y, x
+			case 6: // needs a 90° clockwise turn
+				sx, sy = y, last-x
+			case 7: // transversed
+				sx, sy = last-y, last-x
+			case 8: // needs a 90° counterclockwise turn
+				sx, sy = last-y, x
+			}
+			oriented.SetNRGBA(x, y, img.NRGBAAt(sx, sy))
+		}
+	}
+	return oriented
+}
+
+// encodeAvatarVariant encodes a variant as JPEG for JPEG uploads and as PNG
+// otherwise, so transparency survives. It returns the content type.
+func encodeAvatarVariant(w io.Writer, img image.Image, format string) (string, error) {
+	if format == "jpeg" {
+		return "image/jpeg", jpeg.Encode(w, img, &jpeg.Options{Quality: 85})
+	}
+	return "image/png", png.Encode(w, img)
+}
+
+// avatarVariantKey derives the storage key of an avatar variant from the
+// key of the original, e.g. avatar/u/2024/01/02/id.jpg becomes
+// avatar/u/2024/01/02/id_128.jpg
+func avatarVariantKey(key, variant, contentType string) string {
+	ext := ".png"
+	if contentType == "image/jpeg" {
+		ext = ".jpg"
+	}
+	return strings.TrimSuffix(key, path.Ext(key)) + "_" + variant + ext
+}
+
+// uploadAvatarVariants resizes the avatar to every variant size and uploads
+// the results next to the original. Variants are produced concurrently but
+// waited for, so their URLs can be returned with the upload. On failure the
+// variants that were uploaded are removed again. It returns the variant URLs
+// by size and the keys they were stored under.
+func (s *fileService) uploadAvatarVariants(ctx context.Context, originalKey string, avatar *avatarImage, input UploadFileInput) (map[string]string, []string, error) {
+	type variantResult struct {
+		name string
+		key  string
+		url  string
+		err  error
+	}
+
+	results := make([]variantResult, len(AvatarVariantSizes))
+	var wg sync.WaitGroup
+	for i, size := range AvatarVariantSizes {
+		wg.Add(1)
+		go func(i, size int) {
+			defer wg.Done()
+			name := strconv.Itoa(size)
+			results[i].name = name
+
+			var buf bytes.Buffer
+			contentType, err := encodeAvatarVariant(&buf, avatarVariant(avatar, size), avatar.format)
+			if err != nil {
+				results[i].err = fmt.Errorf("encoding %dpx variant: %w", size, err)
+				return
+			}
+
+			key := avatarVariantKey(originalKey, name, contentType)
+			uploaded, err := s.storage.Upload(ctx, key, &buf, storage.UploadOptions{
+				ContentType: contentType,
+				ACL:         "public-read",
+				DisableCDN:  s.useOrigin(),
+				Metadata: map[string]string{
+					"user-id":  input.UserID,
+					"category": input.Category,
+					"variant":  name,
+					"original": originalKey,
+				},
+			})
+			if err != nil {
+				results[i].err = fmt.Errorf("uploading %dpx variant: %w", size, err)
+				return
+			}
+			results[i].key = uploaded.Key
+			results[i].url = uploaded.URL
+
+			results[i].err = s.recordFile(ctx, uploaded.Key, input.UserID, input.Category, contentType, uploaded.Size)
+		}(i, size)
+	}
+	wg.Wait()
+
+	variants := make(map[string]string, len(results))
+	keys := make([]string, 0, len(results))
+	var firstErr error
+	for _, result := range results {
+		if result.err != nil {
+			if firstErr == nil {
+				firstErr = result.err
+			}
+			continue
+		}
+		variants[result.name] = result.url
+		keys = append(keys, result.key)
+	}
+
+	if firstErr != nil {
+		for _, result := range results {
+			if result.key == "" {
+				continue
+			}
+			s.removeFile(ctx, result.key)
+		}
+		return nil, nil, firstErr
+	}
+	return variants, keys, nil
+}
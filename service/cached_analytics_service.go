@@ -4,9 +4,12 @@ package service
 import (
 	"context"
 	"fmt"
+	"io"
+	"time"
 
 	"github.com/0xsj/mios.io/log"
 	"github.com/0xsj/mios.io/pkg/cache"
+	"github.com/0xsj/mios.io/pkg/live"
 )
 
 // CachedAnalyticsService wraps the regular analytics service with caching
@@ -43,6 +46,38 @@ func (s *CachedAnalyticsService) RecordClick(ctx context.Context, input RecordCl
 	return nil
 }
 
+func (s *CachedAnalyticsService) RecordInteraction(ctx context.Context, input RecordClickInput) error {
+	err := s.baseService.RecordInteraction(ctx, input)
+	if err != nil {
+		return err
+	}
+
+	// Invalidate related caches asynchronously
+	go s.invalidateUserAnalyticsCache(context.Background(), input.UserID)
+	
+	return nil
+}
+
+func (s *CachedAnalyticsService) RecordClicksBatch(ctx context.Context, inputs []RecordClickInput) (*BatchResult, error) {
+	result, err := s.baseService.RecordClicksBatch(ctx, inputs)
+	if err != nil {
+		return nil, err
+	}
+
+	// Invalidate each affected user's caches once
+	invalidated := make(map[string]bool)
+	for _, entry := range result.Results {
+		userID := inputs[entry.Index].UserID
+		if !entry.Success || invalidated[userID] {
+			continue
+		}
+		invalidated[userID] = true
+		go s.invalidateUserAnalyticsCache(context.Background(), userID)
+	}
+
+	return result, nil
+}
+
 func (s *CachedAnalyticsService) RecordPageView(ctx context.Context, input RecordPageViewInput) error {
 	// Recording operations should invalidate related cache
 	err := s.baseService.RecordPageView(ctx, input)
@@ -56,6 +91,11 @@ func (s *CachedAnalyticsService) RecordPageView(ctx context.Context, input Recor
 	return nil
 }
 
+// Live streams are never cached
+func (s *CachedAnalyticsService) SubscribeLiveEvents(ctx context.Context, userID string) (live.Subscription, error) {
+	return s.baseService.SubscribeLiveEvents(ctx, userID)
+}
+
 func (s *CachedAnalyticsService) GetContentItemAnalytics(ctx context.Context, itemID string, page, pageSize int) (*ContentItemAnalyticsDTO, error) {
 	// Simple operations with pagination are not cached due to complexity
 	return s.baseService.GetContentItemAnalytics(ctx, itemID, page, pageSize)
@@ -67,7 +107,16 @@ func (s *CachedAnalyticsService) GetUserAnalytics(ctx context.Context, userID st
 }
 
 func (s *CachedAnalyticsService) GetUserAnalyticsByTimeRange(ctx context.Context, userID string, input TimeRangeInput) (*TimeRangeAnalyticsDTO, error) {
-	cacheKey := s.keyBuilder.TimeRangeAnalytics(userID, input.StartDate, input.EndDate)
+	// Bot traffic is rarely requested, so those views bypass the cache
+	if input.IncludeBots {
+		return s.baseService.GetUserAnalyticsByTimeRange(ctx, userID, input)
+	}
+
+	granularity := input.Granularity
+	if granularity == "" {
+		granularity = GranularityDay
+	}
+	cacheKey := s.keyBuilder.TimeRangeAnalytics(userID, input.StartDate, input.EndDate, granularity)
 	
 	var result TimeRangeAnalyticsDTO
 	err := s.cache.GetOrSet(ctx, cacheKey, &result, cache.GetAnalyticsTTL(), func() (interface{}, error) {
@@ -84,7 +133,16 @@ func (s *CachedAnalyticsService) GetUserAnalyticsByTimeRange(ctx context.Context
 	return &result, nil
 }
 
+// GetAnalyticsForDates is not cached; arbitrary date sets rarely repeat
+func (s *CachedAnalyticsService) GetAnalyticsForDates(ctx context.Context, userID string, dates []string) (map[string]int64, error) {
+	return s.baseService.GetAnalyticsForDates(ctx, userID, dates)
+}
+
 func (s *CachedAnalyticsService) GetItemAnalyticsByTimeRange(ctx context.Context, itemID string, input TimeRangeInput) (*ItemTimeRangeAnalyticsDTO, error) {
+	if input.IncludeBots {
+		return s.baseService.GetItemAnalyticsByTimeRange(ctx, itemID, input)
+	}
+
 	timeRange := fmt.Sprintf("%s:%s:%d", input.StartDate, input.EndDate, input.Limit)
 	cacheKey := s.keyBuilder.ContentItemAnalytics(itemID, timeRange)
 	
@@ -104,6 +162,10 @@ func (s *CachedAnalyticsService) GetItemAnalyticsByTimeRange(ctx context.Context
 }
 
 func (s *CachedAnalyticsService) GetProfilePageViewsByTimeRange(ctx context.Context, userID string, input TimeRangeInput) (*PageViewAnalyticsDTO, error) {
+	if input.IncludeBots {
+		return s.baseService.GetProfilePageViewsByTimeRange(ctx, userID, input)
+	}
+
 	cacheKey := s.keyBuilder.PageViewAnalytics(userID, input.StartDate, input.EndDate, input.Limit)
 	
 	var result PageViewAnalyticsDTO
@@ -121,25 +183,55 @@ func (s *CachedAnalyticsService) GetProfilePageViewsByTimeRange(ctx context.Cont
 	return &result, nil
 }
 
-func (s *CachedAnalyticsService) GetProfileDashboard(ctx context.Context, userID string, days int) (*ProfileDashboardDTO, error) {
+func (s *CachedAnalyticsService) GetProfileDashboard(ctx context.Context, userID string, days int, includeBots bool) (*ProfileDashboardDTO, error) {
+	if includeBots {
+		return s.baseService.GetProfileDashboard(ctx, userID, days, includeBots)
+	}
+
 	cacheKey := s.keyBuilder.ProfileDashboard(userID, days)
 	
 	var result ProfileDashboardDTO
 	err := s.cache.GetOrSet(ctx, cacheKey, &result, cache.GetDashboardTTL(), func() (interface{}, error) {
 		s.logger.Debugf("Cache miss for profile dashboard, fetching from database")
-		return s.baseService.GetProfileDashboard(ctx, userID, days)
+		return s.baseService.GetProfileDashboard(ctx, userID, days, includeBots)
 	})
 	
 	if err != nil {
 		s.logger.Errorf("Failed to get cached profile dashboard: %v", err)
 		// Fallback to direct service call
-		return s.baseService.GetProfileDashboard(ctx, userID, days)
+		return s.baseService.GetProfileDashboard(ctx, userID, days, includeBots)
 	}
 	
 	return &result, nil
 }
 
+func (s *CachedAnalyticsService) GetSummaryCards(ctx context.Context, userID string, days int, includeBots bool) (*SummaryCardsDTO, error) {
+	if includeBots {
+		return s.baseService.GetSummaryCards(ctx, userID, days, includeBots)
+	}
+
+	cacheKey := s.keyBuilder.SummaryCards(userID, days)
+
+	var result SummaryCardsDTO
+	err := s.cache.GetOrSet(ctx, cacheKey, &result, cache.GetDashboardTTL(), func() (interface{}, error) {
+		s.logger.Debugf("Cache miss for summary cards, fetching from database")
+		return s.baseService.GetSummaryCards(ctx, userID, days, includeBots)
+	})
+
+	if err != nil {
+		s.logger.Errorf("Failed to get cached summary cards: %v", err)
+		// Fallback to direct service call
+		return s.baseService.GetSummaryCards(ctx, userID, days, includeBots)
+	}
+
+	return &result, nil
+}
+
 func (s *CachedAnalyticsService) GetReferrerAnalytics(ctx context.Context, userID string, input TimeRangeInput) (*ReferrerAnalyticsDTO, error) {
+	if input.IncludeBots {
+		return s.baseService.GetReferrerAnalytics(ctx, userID, input)
+	}
+
 	cacheKey := s.keyBuilder.ReferrerAnalytics(userID, input.StartDate, input.EndDate, input.Limit)
 	
 	var result ReferrerAnalyticsDTO
@@ -157,6 +249,102 @@ func (s *CachedAnalyticsService) GetReferrerAnalytics(ctx context.Context, userI
 	return &result, nil
 }
 
+func (s *CachedAnalyticsService) GetDeviceBreakdown(ctx context.Context, userID string, input TimeRangeInput) (*DeviceBreakdownDTO, error) {
+	if input.IncludeBots {
+		return s.baseService.GetDeviceBreakdown(ctx, userID, input)
+	}
+
+	cacheKey := s.keyBuilder.DeviceBreakdown(userID, input.StartDate, input.EndDate)
+
+	var result DeviceBreakdownDTO
+	err := s.cache.GetOrSet(ctx, cacheKey, &result, cache.GetAnalyticsTTL(), func() (interface{}, error) {
+		s.logger.Debugf("Cache miss for device breakdown, fetching from database")
+		return s.baseService.GetDeviceBreakdown(ctx, userID, input)
+	})
+
+	if err != nil {
+		s.logger.Errorf("Failed to get cached device breakdown: %v", err)
+		// Fallback to direct service call
+		return s.baseService.GetDeviceBreakdown(ctx, userID, input)
+	}
+
+	return &result, nil
+}
+
+func (s *CachedAnalyticsService) GetGeoAnalytics(ctx context.Context, userID string, input TimeRangeInput) (*GeoAnalyticsDTO, error) {
+	if input.IncludeBots {
+		return s.baseService.GetGeoAnalytics(ctx, userID, input)
+	}
+
+	cacheKey := s.keyBuilder.GeoAnalytics(userID, input.StartDate, input.EndDate, input.Limit)
+
+	var result GeoAnalyticsDTO
+	err := s.cache.GetOrSet(ctx, cacheKey, &result, cache.GetAnalyticsTTL(), func() (interface{}, error) {
+		s.logger.Debugf("Cache miss for geographic analytics, fetching from database")
+		return s.baseService.GetGeoAnalytics(ctx, userID, input)
+	})
+
+	if err != nil {
+		s.logger.Errorf("Failed to get cached geographic analytics: %v", err)
+		// Fallback to direct service call
+		return s.baseService.GetGeoAnalytics(ctx, userID, input)
+	}
+
+	return &result, nil
+}
+
+func (s *CachedAnalyticsService) GetCampaignAnalytics(ctx context.Context, userID string, input TimeRangeInput) (*CampaignAnalyticsDTO, error) {
+	if input.IncludeBots {
+		return s.baseService.GetCampaignAnalytics(ctx, userID, input)
+	}
+
+	cacheKey := s.keyBuilder.CampaignAnalytics(userID, input.StartDate, input.EndDate, input.Limit)
+
+	var result CampaignAnalyticsDTO
+	err := s.cache.GetOrSet(ctx, cacheKey, &result, cache.GetAnalyticsTTL(), func() (interface{}, error) {
+		s.logger.Debugf("Cache miss for campaign analytics, fetching from database")
+		return s.baseService.GetCampaignAnalytics(ctx, userID, input)
+	})
+
+	if err != nil {
+		s.logger.Errorf("Failed to get cached campaign analytics: %v", err)
+		// Fallback to direct service call
+		return s.baseService.GetCampaignAnalytics(ctx, userID, input)
+	}
+
+	return &result, nil
+}
+
+func (s *CachedAnalyticsService) RebuildRollups(ctx context.Context, userID string, start, end time.Time) error {
+	return s.baseService.RebuildRollups(ctx, userID, start, end)
+}
+
+func (s *CachedAnalyticsService) StartRollupRebuild(userID string, start, end time.Time) (*RollupJobDTO, error) {
+	return s.baseService.StartRollupRebuild(userID, start, end)
+}
+
+func (s *CachedAnalyticsService) GetRollupJob(jobID string) (*RollupJobDTO, error) {
+	return s.baseService.GetRollupJob(jobID)
+}
+
+func (s *CachedAnalyticsService) StartRollupRefresh(ctx context.Context, interval time.Duration) {
+	s.baseService.StartRollupRefresh(ctx, interval)
+}
+
+// The recent clicks feed is meant to be live, so it is never cached
+func (s *CachedAnalyticsService) GetRecentClicks(ctx context.Context, userID string, limit int) ([]*RecentClickDTO, error) {
+	return s.baseService.GetRecentClicks(ctx, userID, limit)
+}
+
+// Exports stream raw rows and are never cached
+func (s *CachedAnalyticsService) ExportUserAnalytics(ctx context.Context, userID string, input ExportInput) (io.ReadCloser, error) {
+	return s.baseService.ExportUserAnalytics(ctx, userID, input)
+}
+
+func (s *CachedAnalyticsService) StartUserAnalyticsExport(ctx context.Context, userID string, input ExportInput) (*ExportJobDTO, error) {
+	return s.baseService.StartUserAnalyticsExport(ctx, userID, input)
+}
+
 func (s *CachedAnalyticsService) invalidateUserAnalyticsCache(ctx context.Context, userID string) {
 	// Invalidate all user-related analytics caches
 	patterns := []string{
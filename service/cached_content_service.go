@@ -0,0 +1,217 @@
+// service/cached_content_service.go
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/0xsj/mios.io/log"
+	"github.com/0xsj/mios.io/pkg/cache"
+)
+
+// CachedContentService wraps the regular content service, caching each
+// user's content summary until one of their items is created, changed or
+// deleted. Items deactivated by the link health checks bypass the service,
+// so the summary can lag those by up to the content TTL.
+type CachedContentService struct {
+	baseService ContentService
+	cache       cache.CacheService
+	keyBuilder  *cache.CacheKeyBuilder
+	logger      log.Logger
+}
+
+func NewCachedContentService(
+	baseService ContentService,
+	cacheService cache.CacheService,
+	logger log.Logger,
+) ContentService {
+	return &CachedContentService{
+		baseService: baseService,
+		cache:       cacheService,
+		keyBuilder:  cache.NewCacheKeyBuilder(),
+		logger:      logger,
+	}
+}
+
+func (s *CachedContentService) CreateContentItem(ctx context.Context, input CreateContentItemInput) (*ContentItemDTO, error) {
+	item, err := s.baseService.CreateContentItem(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	s.invalidateContentSummary(ctx, item.UserID)
+	return item, nil
+}
+
+func (s *CachedContentService) GetContentItem(ctx context.Context, itemID string) (*ContentItemDTO, error) {
+	return s.baseService.GetContentItem(ctx, itemID)
+}
+
+func (s *CachedContentService) GetUserContentItems(ctx context.Context, userID, viewerID string) ([]*ContentItemDTO, error) {
+	return s.baseService.GetUserContentItems(ctx, userID, viewerID)
+}
+
+func (s *CachedContentService) UpdateContentItem(ctx context.Context, itemID string, input UpdateContentItemInput) (*ContentItemDTO, error) {
+	item, err := s.baseService.UpdateContentItem(ctx, itemID, input)
+	if err != nil {
+		return nil, err
+	}
+
+	s.invalidateContentSummary(ctx, item.UserID)
+	return item, nil
+}
+
+func (s *CachedContentService) UpdateContentItemPosition(ctx context.Context, itemID string, input UpdatePositionInput) (*ContentItemDTO, error) {
+	return s.baseService.UpdateContentItemPosition(ctx, itemID, input)
+}
+
+func (s *CachedContentService) ReorderContentItems(ctx context.Context, userID string, items []PositionUpdate) error {
+	return s.baseService.ReorderContentItems(ctx, userID, items)
+}
+
+func (s *CachedContentService) CloneContentItem(ctx context.Context, itemID string) (*ContentItemDTO, error) {
+	item, err := s.baseService.CloneContentItem(ctx, itemID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.invalidateContentSummary(ctx, item.UserID)
+	return item, nil
+}
+
+func (s *CachedContentService) ListDeletedContentItems(ctx context.Context, userID string) ([]*ContentItemDTO, error) {
+	return s.baseService.ListDeletedContentItems(ctx, userID)
+}
+
+func (s *CachedContentService) RestoreContentItem(ctx context.Context, userID, itemID string) (*ContentItemDTO, error) {
+	item, err := s.baseService.RestoreContentItem(ctx, userID, itemID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.invalidateContentSummary(ctx, item.UserID)
+	return item, nil
+}
+
+// PurgeDeletedContentItems only removes trashed items, which summaries
+// already leave out
+func (s *CachedContentService) PurgeDeletedContentItems(ctx context.Context) (int64, error) {
+	return s.baseService.PurgeDeletedContentItems(ctx)
+}
+
+func (s *CachedContentService) StartTrashPurge(ctx context.Context, interval time.Duration) {
+	s.baseService.StartTrashPurge(ctx, interval)
+}
+
+func (s *CachedContentService) DeleteContentItem(ctx context.Context, itemID string) error {
+	// Look up the owner first, the item is gone afterwards
+	item, err := s.baseService.GetContentItem(ctx, itemID)
+	if err != nil {
+		return s.baseService.DeleteContentItem(ctx, itemID)
+	}
+
+	if err := s.baseService.DeleteContentItem(ctx, itemID); err != nil {
+		return err
+	}
+
+	s.invalidateContentSummary(ctx, item.UserID)
+	return nil
+}
+
+func (s *CachedContentService) SubmitContentUpdate(ctx context.Context, actorID, itemID string, input UpdateContentItemInput) (*ContentUpdateResultDTO, error) {
+	result, err := s.baseService.SubmitContentUpdate(ctx, actorID, itemID, input)
+	if err != nil {
+		return nil, err
+	}
+
+	if !result.PendingApproval && result.Item != nil {
+		s.invalidateContentSummary(ctx, result.Item.UserID)
+	}
+	return result, nil
+}
+
+func (s *CachedContentService) ListPendingRevisions(ctx context.Context, actorID string) ([]*ContentRevisionDTO, error) {
+	return s.baseService.ListPendingRevisions(ctx, actorID)
+}
+
+func (s *CachedContentService) ApproveRevision(ctx context.Context, actorID, itemID, revisionID string) (*ContentItemDTO, error) {
+	item, err := s.baseService.ApproveRevision(ctx, actorID, itemID, revisionID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.invalidateContentSummary(ctx, item.UserID)
+	return item, nil
+}
+
+func (s *CachedContentService) RejectRevision(ctx context.Context, actorID, itemID, revisionID string) (*ContentRevisionDTO, error) {
+	return s.baseService.RejectRevision(ctx, actorID, itemID, revisionID)
+}
+
+func (s *CachedContentService) SetApprovalRequired(ctx context.Context, ownerID string, required bool) error {
+	return s.baseService.SetApprovalRequired(ctx, ownerID, required)
+}
+
+func (s *CachedContentService) AddCollaborator(ctx context.Context, ownerID, collaboratorID string) error {
+	return s.baseService.AddCollaborator(ctx, ownerID, collaboratorID)
+}
+
+func (s *CachedContentService) RemoveCollaborator(ctx context.Context, ownerID, collaboratorID string) error {
+	return s.baseService.RemoveCollaborator(ctx, ownerID, collaboratorID)
+}
+
+func (s *CachedContentService) GetContentTypeSummary(ctx context.Context, userID string) ([]*ContentTypeSummaryDTO, error) {
+	return s.baseService.GetContentTypeSummary(ctx, userID)
+}
+
+func (s *CachedContentService) GetContentSummary(ctx context.Context, userID string) (*ContentSummaryDTO, error) {
+	cacheKey := s.keyBuilder.ContentSummary(userID)
+
+	var result ContentSummaryDTO
+	err := s.cache.GetOrSet(ctx, cacheKey, &result, cache.GetContentTTL(), func() (interface{}, error) {
+		s.logger.Debugf("Cache miss for content summary, fetching from database")
+		return s.baseService.GetContentSummary(ctx, userID)
+	})
+
+	if err != nil {
+		s.logger.Errorf("Failed to get cached content summary: %v", err)
+		// Fallback to direct service call
+		return s.baseService.GetContentSummary(ctx, userID)
+	}
+
+	return &result, nil
+}
+
+func (s *CachedContentService) BulkUpdateStyle(ctx context.Context, userID string, itemIDs []string, stylePatch StylePatch) error {
+	return s.baseService.BulkUpdateStyle(ctx, userID, itemIDs, stylePatch)
+}
+
+func (s *CachedContentService) SetLinkAutoDeactivate(ctx context.Context, userID, itemID string, enabled bool) error {
+	return s.baseService.SetLinkAutoDeactivate(ctx, userID, itemID, enabled)
+}
+
+func (s *CachedContentService) GetContentHistory(ctx context.Context, actorID, itemID string, page, pageSize int) (*ContentHistoryPageDTO, error) {
+	return s.baseService.GetContentHistory(ctx, actorID, itemID, page, pageSize)
+}
+
+func (s *CachedContentService) SetContentItemPin(ctx context.Context, userID, itemID string, input PinInput) (*ContentItemDTO, error) {
+	return s.baseService.SetContentItemPin(ctx, userID, itemID, input)
+}
+
+// PremiumStatusChanged drops the user's cached summary, which carries the
+// active item limit of their plan
+func (s *CachedContentService) PremiumStatusChanged(ctx context.Context, change PremiumStatusChange) {
+	s.baseService.PremiumStatusChanged(ctx, change)
+	s.invalidateContentSummary(ctx, change.UserID)
+}
+
+// invalidateContentSummary drops the cached summary right away, so the
+// editor sees its own change on the next read
+func (s *CachedContentService) invalidateContentSummary(ctx context.Context, userID string) {
+	cacheKey := s.keyBuilder.ContentSummary(userID)
+	if err := s.cache.Delete(ctx, cacheKey); err != nil {
+		s.logger.Warnf("Failed to invalidate content summary cache for user %s: %v", userID, err)
+		return
+	}
+	s.logger.Debugf("Invalidated content summary cache for user %s", userID)
+}
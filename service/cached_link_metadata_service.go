@@ -9,6 +9,9 @@ import (
 	"github.com/0xsj/mios.io/pkg/cache"
 )
 
+// knownPlatformsCacheKey holds the ListKnownPlatforms result
+const knownPlatformsCacheKey = "platforms:known"
+
 // CachedLinkMetadataService wraps the regular link metadata service with caching
 type CachedLinkMetadataService struct {
 	baseService LinkMetadataService
@@ -64,6 +67,11 @@ func (s *CachedLinkMetadataService) FetchAndStoreMetadata(ctx context.Context, u
 	return result, nil
 }
 
+// GetFreshnessStatus is not cached; clients use it to decide what to refresh
+func (s *CachedLinkMetadataService) GetFreshnessStatus(ctx context.Context, urls []string) (map[string]FreshnessDTO, error) {
+	return s.baseService.GetFreshnessStatus(ctx, urls)
+}
+
 func (s *CachedLinkMetadataService) IsKnownPlatform(domain string) bool {
 	return s.baseService.IsKnownPlatform(domain)
 }
@@ -72,9 +80,14 @@ func (s *CachedLinkMetadataService) GetPlatformInfo(domain string) *PlatformInfo
 	return s.baseService.GetPlatformInfo(domain)
 }
 
+// DetectPlatform is an in-memory registry lookup, so it is not cached
+func (s *CachedLinkMetadataService) DetectPlatform(urlString string) (*PlatformDetectionDTO, error) {
+	return s.baseService.DetectPlatform(urlString)
+}
+
 func (s *CachedLinkMetadataService) ListKnownPlatforms(ctx context.Context) ([]*PlatformInfo, error) {
 	// Platform list rarely changes, cache for longer
-	cacheKey := "platforms:known"
+	cacheKey := knownPlatformsCacheKey
 	
 	var result []*PlatformInfo
 	err := s.cache.GetOrSet(ctx, cacheKey, &result, 24*time.Hour, func() (interface{}, error) {
@@ -89,4 +102,43 @@ func (s *CachedLinkMetadataService) ListKnownPlatforms(ctx context.Context) ([]*
 	}
 	
 	return result, nil
-}
\ No newline at end of file
+}
+func (s *CachedLinkMetadataService) LoadPlatforms(ctx context.Context) error {
+	if err := s.baseService.LoadPlatforms(ctx); err != nil {
+		return err
+	}
+	s.invalidatePlatforms(ctx)
+	return nil
+}
+
+func (s *CachedLinkMetadataService) CreatePlatform(ctx context.Context, input CreatePlatformInput) (*PlatformInfo, error) {
+	result, err := s.baseService.CreatePlatform(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	s.invalidatePlatforms(ctx)
+	return result, nil
+}
+
+func (s *CachedLinkMetadataService) UpdatePlatform(ctx context.Context, domain string, input UpdatePlatformInput) (*PlatformInfo, error) {
+	result, err := s.baseService.UpdatePlatform(ctx, domain, input)
+	if err != nil {
+		return nil, err
+	}
+	s.invalidatePlatforms(ctx)
+	return result, nil
+}
+
+func (s *CachedLinkMetadataService) DeletePlatform(ctx context.Context, domain string) error {
+	if err := s.baseService.DeletePlatform(ctx, domain); err != nil {
+		return err
+	}
+	s.invalidatePlatforms(ctx)
+	return nil
+}
+
+func (s *CachedLinkMetadataService) invalidatePlatforms(ctx context.Context) {
+	if err := s.cache.Delete(ctx, knownPlatformsCacheKey); err != nil {
+		s.logger.Warnf("Failed to invalidate known platforms cache: %v", err)
+	}
+}
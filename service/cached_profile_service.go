@@ -0,0 +1,94 @@
+// service/cached_profile_service.go
+package service
+
+import (
+	"context"
+
+	"github.com/0xsj/mios.io/log"
+	"github.com/0xsj/mios.io/pkg/cache"
+	apperror "github.com/0xsj/mios.io/pkg/errors"
+)
+
+// CachedProfileService wraps the regular profile service, caching the
+// public metadata and embed payloads fetched by crawlers and embedding
+// sites. Profiles and their items are edited through other services, so
+// entries are not invalidated and expire after the profile TTL instead.
+type CachedProfileService struct {
+	baseService ProfileService
+	cache       cache.CacheService
+	keyBuilder  *cache.CacheKeyBuilder
+	logger      log.Logger
+}
+
+func NewCachedProfileService(
+	baseService ProfileService,
+	cacheService cache.CacheService,
+	logger log.Logger,
+) ProfileService {
+	return &CachedProfileService{
+		baseService: baseService,
+		cache:       cacheService,
+		keyBuilder:  cache.NewCacheKeyBuilder(),
+		logger:      logger,
+	}
+}
+
+func (s *CachedProfileService) GetProfileMeta(ctx context.Context, handle string) (*ProfileMetaDTO, error) {
+	cacheKey := s.keyBuilder.ProfileMeta(handle)
+
+	var result ProfileMetaDTO
+	err := s.cache.GetOrSet(ctx, cacheKey, &result, cache.GetProfileTTL(), func() (interface{}, error) {
+		s.logger.Debugf("Cache miss for profile metadata, fetching from database")
+		return s.baseService.GetProfileMeta(ctx, handle)
+	})
+
+	if err != nil {
+		// Hidden and unknown profiles are not cached, there is nothing to
+		// fall back to
+		if apperror.IsNotFound(err) {
+			return nil, err
+		}
+		s.logger.Errorf("Failed to get cached profile metadata: %v", err)
+		// Fallback to direct service call
+		return s.baseService.GetProfileMeta(ctx, handle)
+	}
+
+	return &result, nil
+}
+
+func (s *CachedProfileService) ResolveCanonical(ctx context.Context, requestedHandleOrDomain string) (string, bool, error) {
+	return s.baseService.ResolveCanonical(ctx, requestedHandleOrDomain)
+}
+
+func (s *CachedProfileService) GetEmbedProfile(ctx context.Context, handle string) (*ProfileEmbedDTO, error) {
+	cacheKey := s.keyBuilder.ProfileEmbed(handle)
+
+	var result ProfileEmbedDTO
+	err := s.cache.GetOrSet(ctx, cacheKey, &result, cache.GetProfileTTL(), func() (interface{}, error) {
+		s.logger.Debugf("Cache miss for embed profile, fetching from database")
+		return s.baseService.GetEmbedProfile(ctx, handle)
+	})
+
+	if err != nil {
+		if apperror.IsNotFound(err) {
+			return nil, err
+		}
+		s.logger.Errorf("Failed to get cached embed profile: %v", err)
+		// Fallback to direct service call
+		return s.baseService.GetEmbedProfile(ctx, handle)
+	}
+
+	return &result, nil
+}
+
+func (s *CachedProfileService) ListTemplates(ctx context.Context) ([]*ProfileTemplateDTO, error) {
+	return s.baseService.ListTemplates(ctx)
+}
+
+func (s *CachedProfileService) CopyFromTemplate(ctx context.Context, userID, templateID string) error {
+	return s.baseService.CopyFromTemplate(ctx, userID, templateID)
+}
+
+func (s *CachedProfileService) SetTemplate(ctx context.Context, userID string, isTemplate bool) error {
+	return s.baseService.SetTemplate(ctx, userID, isTemplate)
+}
@@ -0,0 +1,112 @@
+package service
+
+import (
+	"context"
+
+	"github.com/0xsj/mios.io/pkg/errors"
+	"github.com/0xsj/mios.io/repository"
+	"github.com/google/uuid"
+)
+
+// cloneRowOffset moves a clone one row below its source on both layouts so
+// the two don't sit exactly on top of each other
+const cloneRowOffset = 1
+
+// CloneContentItem copies an item into a new one owned by the same user.
+// The clone gets a fresh content ID so clients keying on it don't confuse
+// the two, and it starts out unpinned.
+func (s *contentService) CloneContentItem(ctx context.Context, itemIDStr string) (*ContentItemDTO, error) {
+	s.logger.Infof("Cloning content item with ID: %s", itemIDStr)
+
+	itemID, err := uuid.Parse(itemIDStr)
+	if err != nil {
+		s.logger.Warnf("Invalid item ID format: %v", err)
+		return nil, errors.NewBadRequestError("Invalid item ID format", err)
+	}
+
+	source, err := s.contentRepo.GetContentItem(ctx, itemID)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			s.logger.Infof("Content item not found with ID: %s", itemIDStr)
+			return nil, errors.NewNotFoundError("Content item not found", err)
+		}
+		s.logger.Errorf("Error retrieving content item: %v", err)
+		return nil, errors.Wrap(err, "Failed to retrieve content item")
+	}
+
+	owner, err := s.userRepo.GetUser(ctx, source.UserID)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			s.logger.Warnf("User not found with ID: %s", source.UserID)
+			return nil, errors.NewNotFoundError("User not found", err)
+		}
+		s.logger.Errorf("Error retrieving user: %v", err)
+		return nil, errors.Wrap(err, "Failed to retrieve user")
+	}
+
+	if err := s.enforceTypeMax(ctx, owner.UserID, source.ContentType); err != nil {
+		return nil, err
+	}
+
+	isActive := source.IsActive == nil || *source.IsActive
+	if isActive {
+		if err := s.enforceActiveItemLimit(ctx, owner); err != nil {
+			return nil, err
+		}
+	}
+
+	params := repository.CreateContentItemParams{
+		UserID:        source.UserID,
+		ContentID:     uuid.NewString(),
+		ContentType:   source.ContentType,
+		Title:         source.Title,
+		Href:          source.Href,
+		URL:           source.Url,
+		MediaType:     source.MediaType,
+		DesktopX:      source.DesktopX,
+		DesktopY:      offsetRow(source.DesktopY),
+		DesktopStyle:  source.DesktopStyle,
+		MobileX:       source.MobileX,
+		MobileY:       offsetRow(source.MobileY),
+		MobileStyle:   source.MobileStyle,
+		HAlign:        source.Halign,
+		VAlign:        source.Valign,
+		ContentData:   source.ContentData,
+		Overrides:     source.Overrides,
+		IsActive:      isActive,
+		PublishAt:     source.PublishAt,
+		UnpublishAt:   source.UnpublishAt,
+		CustomStyling: source.CustomStyling,
+		EmbedData:     source.EmbedData,
+		AutoEmbed:     source.AutoEmbed != nil && *source.AutoEmbed,
+	}
+
+	clone, err := s.contentRepo.CreateContentItem(ctx, params)
+	if err != nil {
+		s.logger.Errorf("Failed to clone content item: %v", err)
+		return nil, errors.Wrap(err, "Failed to clone content item")
+	}
+
+	s.recordHistory(ctx, repository.ContentHistoryEntry{
+		ItemID:  clone.ItemID,
+		ActorID: &clone.UserID,
+		Source:  HistorySourceUser,
+		Action:  HistoryActionCreated,
+		Changes: map[string]interface{}{
+			"cloned_from": &FieldChangeDTO{From: source.ItemID.String(), To: clone.ItemID.String()},
+		},
+	})
+
+	s.prefetcher.Prefetch(linkTarget(clone))
+
+	s.logger.Infof("Content item %s cloned to %s", itemIDStr, clone.ItemID)
+	return mapContentItemToDTO(clone), nil
+}
+
+func offsetRow(y *int32) *int32 {
+	if y == nil {
+		return nil
+	}
+	offset := *y + cloneRowOffset
+	return &offset
+}
@@ -0,0 +1,247 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"reflect"
+	"time"
+
+	db "github.com/0xsj/mios.io/db/sqlc"
+	"github.com/0xsj/mios.io/pkg/errors"
+	"github.com/0xsj/mios.io/pkg/ptr"
+	"github.com/0xsj/mios.io/repository"
+	"github.com/google/uuid"
+	"github.com/jackc/pgtype"
+)
+
+// Who made a content history entry: a user editing the item, or the system
+// acting on its own (scheduled publishing, dead-link deactivation)
+const (
+	HistorySourceUser   = "user"
+	HistorySourceSystem = "system"
+)
+
+// Content history actions
+const (
+	HistoryActionCreated          = "created"
+	HistoryActionUpdated          = "updated"
+	HistoryActionRevisionApproved = "revision_approved"
+	HistoryActionAutoDeactivated  = "auto_deactivated"
+	HistoryActionDeleted          = "deleted"
+	HistoryActionRestored         = "restored"
+)
+
+const (
+	defaultHistoryPageSize = 20
+	maxHistoryPageSize     = 100
+)
+
+// ContentHistoryDTO is one applied change to a content item. Changes maps
+// each changed field to its previous and new value.
+type ContentHistoryDTO struct {
+	ID        string                     `json:"id"`
+	ItemID    string                     `json:"item_id"`
+	ActorID   string                     `json:"actor_id,omitempty"`
+	Source    string                     `json:"source"`
+	Action    string                     `json:"action"`
+	Changes   map[string]*FieldChangeDTO `json:"changes,omitempty"`
+	CreatedAt string                     `json:"created_at,omitempty"`
+}
+
+type FieldChangeDTO struct {
+	From interface{} `json:"from"`
+	To   interface{} `json:"to"`
+}
+
+// ContentHistoryPageDTO is one page of an item's history, newest first
+type ContentHistoryPageDTO struct {
+	Entries    []*ContentHistoryDTO
+	Page       int
+	PageSize   int
+	TotalCount int64
+	TotalPages int
+}
+
+// GetContentHistory returns a page of the changes applied to an item. Only
+// the item's owner and admins may read it.
+func (s *contentService) GetContentHistory(ctx context.Context, actorIDStr, itemIDStr string, page, pageSize int) (*ContentHistoryPageDTO, error) {
+	s.logger.Debugf("Getting history for content item ID: %s (page %d)", itemIDStr, page)
+
+	actorID, err := uuid.Parse(actorIDStr)
+	if err != nil {
+		s.logger.Warnf("Invalid user ID format: %v", err)
+		return nil, errors.NewBadRequestError("Invalid user ID format", err)
+	}
+
+	itemID, err := uuid.Parse(itemIDStr)
+	if err != nil {
+		s.logger.Warnf("Invalid item ID format: %v", err)
+		return nil, errors.NewBadRequestError("Invalid item ID format", err)
+	}
+
+	item, err := s.contentRepo.GetContentItem(ctx, itemID)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, errors.NewNotFoundError("Content item not found", err)
+		}
+		s.logger.Errorf("Error retrieving content item: %v", err)
+		return nil, errors.Wrap(err, "Failed to retrieve content item")
+	}
+
+	if item.UserID != actorID {
+		isAdmin, err := s.isAdmin(ctx, actorID)
+		if err != nil {
+			return nil, err
+		}
+		if !isAdmin {
+			s.logger.Warnf("User %s is not allowed to view history of item %s", actorIDStr, itemIDStr)
+			return nil, errors.NewForbiddenError("Only the owner can view this item's history", nil)
+		}
+	}
+
+	if page < 1 {
+		page = 1
+	}
+	if pageSize <= 0 {
+		pageSize = defaultHistoryPageSize
+	}
+	if pageSize > maxHistoryPageSize {
+		pageSize = maxHistoryPageSize
+	}
+
+	total, err := s.historyRepo.CountHistory(ctx, itemID)
+	if err != nil {
+		s.logger.Errorf("Failed to count content history: %v", err)
+		return nil, errors.Wrap(err, "Failed to retrieve content history")
+	}
+
+	entries, err := s.historyRepo.ListHistory(ctx, itemID, pageSize, (page-1)*pageSize)
+	if err != nil {
+		s.logger.Errorf("Failed to list content history: %v", err)
+		return nil, errors.Wrap(err, "Failed to retrieve content history")
+	}
+
+	dtos := make([]*ContentHistoryDTO, len(entries))
+	for i, entry := range entries {
+		dtos[i] = mapContentHistoryToDTO(entry)
+	}
+
+	return &ContentHistoryPageDTO{
+		Entries:    dtos,
+		Page:       page,
+		PageSize:   pageSize,
+		TotalCount: total,
+		TotalPages: int(math.Ceil(float64(total) / float64(pageSize))),
+	}, nil
+}
+
+// recordHistory stores a history entry without failing the change it describes
+func (s *contentService) recordHistory(ctx context.Context, entry repository.ContentHistoryEntry) {
+	if err := s.historyRepo.Record(ctx, entry); err != nil {
+		s.logger.Warnf("Failed to record %s history for item ID %s: %v", entry.Action, entry.ItemID, err)
+	}
+}
+
+// diffContentItems returns the user-visible fields that differ between two
+// versions of an item, as {"from": ..., "to": ...} pairs keyed by field.
+func diffContentItems(before, after *db.ContentItem) map[string]interface{} {
+	changes := make(map[string]interface{})
+
+	diffString := func(field string, from, to *string) {
+		if ptr.GetValueOrEmpty(from) != ptr.GetValueOrEmpty(to) {
+			changes[field] = FieldChangeDTO{From: ptr.GetValueOrEmpty(from), To: ptr.GetValueOrEmpty(to)}
+		}
+	}
+	diffInt := func(field string, from, to *int32) {
+		if derefInt32(from) != derefInt32(to) {
+			changes[field] = FieldChangeDTO{From: derefInt32(from), To: derefInt32(to)}
+		}
+	}
+	diffTime := func(field string, from, to *time.Time) {
+		if formatOptionalTime(from) != formatOptionalTime(to) {
+			changes[field] = FieldChangeDTO{From: formatOptionalTime(from), To: formatOptionalTime(to)}
+		}
+	}
+	diffJSON := func(field string, from, to pgtype.JSONB) {
+		fromValue, toValue := decodeJSONB(from), decodeJSONB(to)
+		if !reflect.DeepEqual(fromValue, toValue) {
+			changes[field] = FieldChangeDTO{From: fromValue, To: toValue}
+		}
+	}
+
+	diffString("title", before.Title, after.Title)
+	diffString("href", before.Href, after.Href)
+	diffString("url", before.Url, after.Url)
+	diffString("media_type", before.MediaType, after.MediaType)
+	diffString("desktop_style", before.DesktopStyle, after.DesktopStyle)
+	diffString("mobile_style", before.MobileStyle, after.MobileStyle)
+	diffString("halign", before.Halign, after.Halign)
+	diffString("valign", before.Valign, after.Valign)
+	diffInt("desktop_x", before.DesktopX, after.DesktopX)
+	diffInt("desktop_y", before.DesktopY, after.DesktopY)
+	diffInt("mobile_x", before.MobileX, after.MobileX)
+	diffInt("mobile_y", before.MobileY, after.MobileY)
+	diffJSON("content_data", before.ContentData, after.ContentData)
+	diffJSON("overrides", before.Overrides, after.Overrides)
+	diffTime("publish_at", before.PublishAt, after.PublishAt)
+	diffTime("unpublish_at", before.UnpublishAt, after.UnpublishAt)
+
+	wasActive := before.IsActive != nil && *before.IsActive
+	isActive := after.IsActive != nil && *after.IsActive
+	if wasActive != isActive {
+		changes["is_active"] = FieldChangeDTO{From: wasActive, To: isActive}
+	}
+	if before.Pinned != after.Pinned {
+		changes["pinned"] = FieldChangeDTO{From: before.Pinned, To: after.Pinned}
+	}
+	if before.PinOrder != after.PinOrder {
+		changes["pin_order"] = FieldChangeDTO{From: before.PinOrder, To: after.PinOrder}
+	}
+
+	return changes
+}
+
+func decodeJSONB(value pgtype.JSONB) interface{} {
+	if value.Status != pgtype.Present {
+		return nil
+	}
+	var decoded interface{}
+	if err := json.Unmarshal(value.Bytes, &decoded); err != nil {
+		return nil
+	}
+	return decoded
+}
+
+func derefInt32(value *int32) int32 {
+	if value == nil {
+		return 0
+	}
+	return *value
+}
+
+func mapContentHistoryToDTO(entry *db.ContentHistory) *ContentHistoryDTO {
+	dto := &ContentHistoryDTO{
+		ID:     entry.HistoryID.String(),
+		ItemID: entry.ItemID.String(),
+		Source: entry.Source,
+		Action: entry.Action,
+	}
+
+	if entry.ActorID != nil {
+		dto.ActorID = entry.ActorID.String()
+	}
+
+	if entry.Changes.Status == pgtype.Present {
+		var changes map[string]*FieldChangeDTO
+		if err := json.Unmarshal(entry.Changes.Bytes, &changes); err == nil {
+			dto.Changes = changes
+		}
+	}
+
+	if entry.CreatedAt != nil {
+		dto.CreatedAt = entry.CreatedAt.Format(time.RFC3339)
+	}
+
+	return dto
+}
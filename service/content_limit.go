@@ -0,0 +1,74 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	db "github.com/0xsj/mios.io/db/sqlc"
+	"github.com/0xsj/mios.io/pkg/errors"
+	"github.com/google/uuid"
+)
+
+// enforceTypeMax rejects adding another item of contentType once the user
+// has as many as TypeLimits allows
+func (s *contentService) enforceTypeMax(ctx context.Context, userID uuid.UUID, contentType string) error {
+	limit, ok := s.config.TypeLimits[contentType]
+	if !ok || limit.Max <= 0 {
+		return nil
+	}
+
+	count, err := s.countItemsOfType(ctx, userID, contentType)
+	if err != nil {
+		return err
+	}
+	if count >= limit.Max {
+		s.logger.Warnf("User %s already has %d %s items (max %d)", userID, count, contentType, limit.Max)
+		return errors.NewValidationError(fmt.Sprintf("Only %d %s item(s) allowed", limit.Max, contentType), nil)
+	}
+	return nil
+}
+
+// activeItemLimit is how many active items the owner's current tier allows,
+// or zero for no limit. It is read on every check, so an upgrade lifts the
+// limit immediately.
+func (s *contentService) activeItemLimit(owner *db.User) int {
+	if owner.IsPremium != nil && *owner.IsPremium {
+		return s.config.MaxActiveItemsPremium
+	}
+	return s.config.MaxActiveItemsFree
+}
+
+// enforceActiveItemLimit rejects creating or publishing another item once
+// the owner has as many active items as their tier allows. Items above the
+// limit, e.g. left over from a downgrade, stay active; they only block new
+// ones until the owner is back under it.
+func (s *contentService) enforceActiveItemLimit(ctx context.Context, owner *db.User) error {
+	limit := s.activeItemLimit(owner)
+	if limit <= 0 {
+		return nil
+	}
+
+	count, err := s.contentRepo.CountActiveUserContentItems(ctx, owner.UserID)
+	if err != nil {
+		s.logger.Errorf("Failed to count active content items: %v", err)
+		return errors.Wrap(err, "Failed to count active content items")
+	}
+
+	if count >= int64(limit) {
+		s.logger.Warnf("User %s already has %d active items (max %d)", owner.UserID, count, limit)
+		return errors.NewValidationError(fmt.Sprintf("Only %d active item(s) allowed on your plan", limit), nil)
+	}
+	return nil
+}
+
+// PremiumStatusChanged reacts to a user moving between tiers. Limits are
+// looked up per request, so nothing is rewritten here: excess items are
+// kept on a downgrade, and drafts become publishable on an upgrade.
+func (s *contentService) PremiumStatusChanged(ctx context.Context, change PremiumStatusChange) {
+	limit := s.config.MaxActiveItemsFree
+	if change.IsPremium {
+		limit = s.config.MaxActiveItemsPremium
+	}
+	s.logger.Infof("User %s premium status changed from %v to %v, active item limit is now %d",
+		change.UserID, change.WasPremium, change.IsPremium, limit)
+}
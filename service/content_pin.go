@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	db "github.com/0xsj/mios.io/db/sqlc"
+	"github.com/0xsj/mios.io/pkg/errors"
+	"github.com/0xsj/mios.io/repository"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultMaxPinnedFree    = 3
+	defaultMaxPinnedPremium = 10
+)
+
+// PinInput pins or unpins an item. PinOrder places it among the user's
+// other pinned items, lowest first; it keeps its current value when nil.
+type PinInput struct {
+	Pinned   bool
+	PinOrder *int32
+}
+
+// SetContentItemPin pins or unpins one of the user's own items. Pinned items
+// render at the top of the profile regardless of their position.
+func (s *contentService) SetContentItemPin(ctx context.Context, userIDStr, itemIDStr string, input PinInput) (*ContentItemDTO, error) {
+	s.logger.Infof("Setting pinned=%v for content item ID: %s", input.Pinned, itemIDStr)
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		s.logger.Warnf("Invalid user ID format: %v", err)
+		return nil, errors.NewBadRequestError("Invalid user ID format", err)
+	}
+
+	itemID, err := uuid.Parse(itemIDStr)
+	if err != nil {
+		s.logger.Warnf("Invalid item ID format: %v", err)
+		return nil, errors.NewBadRequestError("Invalid item ID format", err)
+	}
+
+	existing, err := s.contentRepo.GetContentItem(ctx, itemID)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, errors.NewNotFoundError("Content item not found", err)
+		}
+		s.logger.Errorf("Error retrieving content item: %v", err)
+		return nil, errors.Wrap(err, "Failed to retrieve content item")
+	}
+
+	if existing.UserID != userID {
+		s.logger.Warnf("User %s attempted to pin item %s owned by %s", userIDStr, itemIDStr, existing.UserID)
+		return nil, errors.NewForbiddenError("You can only pin your own content items", nil)
+	}
+
+	if input.Pinned && !existing.Pinned {
+		owner, err := s.userRepo.GetUser(ctx, userID)
+		if err != nil {
+			s.logger.Errorf("Error retrieving user: %v", err)
+			return nil, errors.Wrap(err, "Failed to retrieve user")
+		}
+		if err := s.enforcePinLimit(ctx, owner, itemID); err != nil {
+			return nil, err
+		}
+	}
+
+	pinOrder := existing.PinOrder
+	if input.PinOrder != nil {
+		pinOrder = *input.PinOrder
+	}
+
+	if err := s.contentRepo.UpdateContentItemPin(ctx, itemID, input.Pinned, pinOrder); err != nil {
+		s.logger.Errorf("Failed to update content item pin: %v", err)
+		return nil, errors.Wrap(err, "Failed to update content item pin")
+	}
+
+	updated, err := s.contentRepo.GetContentItem(ctx, itemID)
+	if err != nil {
+		s.logger.Errorf("Failed to retrieve updated content item: %v", err)
+		return nil, errors.Wrap(err, "Failed to retrieve updated content item")
+	}
+
+	if changes := diffContentItems(existing, updated); len(changes) > 0 {
+		s.recordHistory(ctx, repository.ContentHistoryEntry{
+			ItemID:  itemID,
+			ActorID: &userID,
+			Source:  HistorySourceUser,
+			Action:  HistoryActionUpdated,
+			Changes: changes,
+		})
+	}
+
+	return mapContentItemToDTO(updated), nil
+}
+
+// enforcePinLimit rejects pinning another item once the owner has pinned as
+// many as their tier allows. itemID is the item being pinned, if it exists.
+func (s *contentService) enforcePinLimit(ctx context.Context, owner *db.User, itemID uuid.UUID) error {
+	limit := s.config.MaxPinnedFree
+	if owner.IsPremium != nil && *owner.IsPremium {
+		limit = s.config.MaxPinnedPremium
+	}
+
+	count, err := s.contentRepo.CountPinnedContentItems(ctx, owner.UserID, itemID)
+	if err != nil {
+		s.logger.Errorf("Failed to count pinned content items: %v", err)
+		return errors.Wrap(err, "Failed to count pinned content items")
+	}
+
+	if count >= int64(limit) {
+		s.logger.Warnf("User %s already has %d pinned items (max %d)", owner.UserID, count, limit)
+		return errors.NewValidationError(fmt.Sprintf("Only %d pinned item(s) allowed on your plan", limit), nil)
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package service
+
+import (
+	"time"
+
+	db "github.com/0xsj/mios.io/db/sqlc"
+	"github.com/0xsj/mios.io/pkg/errors"
+)
+
+// An item outside its publish window is hidden from visitors. Its owner
+// still sees it, flagged with one of these.
+const (
+	ScheduleStatusScheduled = "scheduled"
+	ScheduleStatusExpired   = "expired"
+)
+
+// scheduleStatus reports whether item is waiting for its publish_at or past
+// its unpublish_at at now. It's empty while the item is live.
+func scheduleStatus(item *db.ContentItem, now time.Time) string {
+	switch {
+	case item.PublishAt != nil && item.PublishAt.After(now):
+		return ScheduleStatusScheduled
+	case item.UnpublishAt != nil && !item.UnpublishAt.After(now):
+		return ScheduleStatusExpired
+	}
+	return ""
+}
+
+// validatePublishWindow rejects windows that close before they open. Either
+// end may be nil.
+func validatePublishWindow(publishAt, unpublishAt *time.Time) error {
+	if publishAt != nil && unpublishAt != nil && !unpublishAt.After(*publishAt) {
+		return errors.NewValidationError("unpublish_at must be after publish_at", nil)
+	}
+	return nil
+}
+
+// formatOptionalTime formats t as RFC 3339, or returns "" for nil
+func formatOptionalTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
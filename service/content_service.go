@@ -3,6 +3,11 @@ package service
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	db "github.com/0xsj/mios.io/db/sqlc"
@@ -16,16 +21,173 @@ import (
 type ContentService interface {
 	CreateContentItem(ctx context.Context, input CreateContentItemInput) (*ContentItemDTO, error)
 	GetContentItem(ctx context.Context, itemID string) (*ContentItemDTO, error)
-	GetUserContentItems(ctx context.Context, userID string) ([]*ContentItemDTO, error)
+	// GetUserContentItems lists a profile's items; private profiles are only
+	// listed for their owner, viewerID (empty when anonymous)
+	GetUserContentItems(ctx context.Context, userID, viewerID string) ([]*ContentItemDTO, error)
 	UpdateContentItem(ctx context.Context, itemID string, input UpdateContentItemInput) (*ContentItemDTO, error)
 	UpdateContentItemPosition(ctx context.Context, itemID string, input UpdatePositionInput) (*ContentItemDTO, error)
+	ReorderContentItems(ctx context.Context, userID string, items []PositionUpdate) error
 	DeleteContentItem(ctx context.Context, itemID string) error
+	CloneContentItem(ctx context.Context, itemID string) (*ContentItemDTO, error)
+
+	// Trash
+	ListDeletedContentItems(ctx context.Context, userID string) ([]*ContentItemDTO, error)
+	RestoreContentItem(ctx context.Context, userID, itemID string) (*ContentItemDTO, error)
+	PurgeDeletedContentItems(ctx context.Context) (int64, error)
+	StartTrashPurge(ctx context.Context, interval time.Duration)
+
+	// Approval workflow
+	SubmitContentUpdate(ctx context.Context, actorID, itemID string, input UpdateContentItemInput) (*ContentUpdateResultDTO, error)
+	ListPendingRevisions(ctx context.Context, actorID string) ([]*ContentRevisionDTO, error)
+	ApproveRevision(ctx context.Context, actorID, itemID, revisionID string) (*ContentItemDTO, error)
+	RejectRevision(ctx context.Context, actorID, itemID, revisionID string) (*ContentRevisionDTO, error)
+	SetApprovalRequired(ctx context.Context, ownerID string, required bool) error
+	AddCollaborator(ctx context.Context, ownerID, collaboratorID string) error
+	RemoveCollaborator(ctx context.Context, ownerID, collaboratorID string) error
+
+	// Content type rules
+	GetContentTypeSummary(ctx context.Context, userID string) ([]*ContentTypeSummaryDTO, error)
+	GetContentSummary(ctx context.Context, userID string) (*ContentSummaryDTO, error)
+
+	// Bulk operations
+	BulkUpdateStyle(ctx context.Context, userID string, itemIDs []string, stylePatch StylePatch) error
+
+	// Link health
+	SetLinkAutoDeactivate(ctx context.Context, userID, itemID string, enabled bool) error
+
+	// History
+	GetContentHistory(ctx context.Context, actorID, itemID string, page, pageSize int) (*ContentHistoryPageDTO, error)
+
+	// Pinning
+	SetContentItemPin(ctx context.Context, userID, itemID string, input PinInput) (*ContentItemDTO, error)
+
+	// Plan changes
+	PremiumStatusChanged(ctx context.Context, change PremiumStatusChange)
+}
+
+// maxBulkItems caps how many explicit item IDs one bulk request may name
+const maxBulkItems = 500
+
+// StylePatch is a style change applied to many items at once. Nil fields
+// are left untouched and Overrides is merged key by key into each item's
+// overrides. ApplyToType additionally selects every item of that type.
+type StylePatch struct {
+	DesktopStyle *string                `json:"desktop_style"`
+	MobileStyle  *string                `json:"mobile_style"`
+	Overrides    map[string]interface{} `json:"overrides"`
+	ApplyToType  string                 `json:"apply_to_type"`
+}
+
+// ContentTypeLimit bounds how many items of one content type a user may
+// have. Zero means no bound on that side.
+type ContentTypeLimit struct {
+	Min int64
+	Max int64
+}
+
+type ContentConfig struct {
+	TypeLimits map[string]ContentTypeLimit
+
+	// FieldLimits caps the length of titles, hrefs and URLs
+	FieldLimits FieldLimits
+
+	// RequireHTTPSLinks rejects plain http:// hrefs and URLs. Links to
+	// HTTPSUpgradeDomains (and their subdomains) are upgraded instead.
+	RequireHTTPSLinks   bool
+	HTTPSUpgradeDomains []string
+
+	// FallbackOrder orders a profile's items whose positions are unset or
+	// tied: ContentOrderNewestFirst (the default) or ContentOrderOldestFirst
+	FallbackOrder string
+
+	// MaxPinnedFree and MaxPinnedPremium cap how many items a user may pin
+	// per tier
+	MaxPinnedFree    int
+	MaxPinnedPremium int
+
+	// MaxActiveItemsFree and MaxActiveItemsPremium cap how many active items
+	// a user may have per tier. Zero means no limit.
+	MaxActiveItemsFree    int
+	MaxActiveItemsPremium int
+
+	// TrashRetention is how long a deleted item can be restored before it
+	// is purged
+	TrashRetention time.Duration
+}
+
+// Orders for content items that share a position
+const (
+	ContentOrderNewestFirst = "newest_first"
+	ContentOrderOldestFirst = "oldest_first"
+)
+
+// ParseContentTypeLimits reads rules written as "type:min:max", e.g.
+// "header:0:1" allows at most one header and "profile:1:0" requires one.
+func ParseContentTypeLimits(rules []string) (map[string]ContentTypeLimit, error) {
+	limits := make(map[string]ContentTypeLimit, len(rules))
+	for _, rule := range rules {
+		rule = strings.TrimSpace(rule)
+		if rule == "" {
+			continue
+		}
+
+		parts := strings.Split(rule, ":")
+		if len(parts) != 3 || parts[0] == "" {
+			return nil, fmt.Errorf("invalid content type limit %q, expected type:min:max", rule)
+		}
+
+		min, err := strconv.ParseInt(parts[1], 10, 64)
+		if err != nil || min < 0 {
+			return nil, fmt.Errorf("invalid minimum in content type limit %q", rule)
+		}
+
+		max, err := strconv.ParseInt(parts[2], 10, 64)
+		if err != nil || max < 0 {
+			return nil, fmt.Errorf("invalid maximum in content type limit %q", rule)
+		}
+
+		if max > 0 && min > max {
+			return nil, fmt.Errorf("minimum exceeds maximum in content type limit %q", rule)
+		}
+
+		limits[parts[0]] = ContentTypeLimit{Min: min, Max: max}
+	}
+	return limits, nil
+}
+
+type ContentTypeSummaryDTO struct {
+	ContentType string `json:"content_type"`
+	Count       int64  `json:"count"`
+	Min         int64  `json:"min,omitempty"`
+	Max         int64  `json:"max,omitempty"`
 }
 
+// ContentTypeRepost marks an item that re-shares another item. Its content
+// data holds the referenced item's ID under repostSourceKey.
+const ContentTypeRepost = "repost"
+
+const (
+	repostSourceKey = "source_item_id"
+	maxRepostDepth  = 5
+)
+
+// Content revision states
+const (
+	RevisionPending  = "pending"
+	RevisionApproved = "approved"
+	RevisionRejected = "rejected"
+)
+
 type contentService struct {
-	contentRepo repository.ContentRepository
-	userRepo    repository.UserRepository
-	logger      log.Logger
+	contentRepo    repository.ContentRepository
+	userRepo       repository.UserRepository
+	revisionRepo   repository.ContentRevisionRepository
+	linkHealthRepo repository.LinkHealthRepository
+	historyRepo    repository.ContentHistoryRepository
+	txManager      repository.TxManager
+	prefetcher     *LinkPrefetcher
+	config         ContentConfig
+	logger         log.Logger
 }
 
 type CreateContentItemInput struct {
@@ -46,6 +208,10 @@ type CreateContentItemInput struct {
 	VAlign       *string                `json:"valign"`
 	ContentData  map[string]interface{} `json:"content_data"`
 	Overrides    map[string]interface{} `json:"overrides"`
+	Pinned       *bool                  `json:"pinned"`
+	PinOrder     *int32                 `json:"pin_order"`
+	PublishAt    *time.Time             `json:"publish_at"`
+	UnpublishAt  *time.Time             `json:"unpublish_at"`
 }
 
 type UpdateContentItemInput struct {
@@ -60,6 +226,10 @@ type UpdateContentItemInput struct {
 	ContentData  map[string]interface{} `json:"content_data"`
 	Overrides    map[string]interface{} `json:"overrides"`
 	IsActive     *bool                  `json:"is_active"`
+	Pinned       *bool                  `json:"pinned"`
+	PinOrder     *int32                 `json:"pin_order"`
+	PublishAt    *time.Time             `json:"publish_at"`
+	UnpublishAt  *time.Time             `json:"unpublish_at"`
 }
 
 type UpdatePositionInput struct {
@@ -69,6 +239,16 @@ type UpdatePositionInput struct {
 	MobileY  *int32 `json:"mobile_y"`
 }
 
+// PositionUpdate places one item in a bulk reorder. All coordinates are
+// required, since the client saves its whole layout at once.
+type PositionUpdate struct {
+	ItemID   string `json:"item_id"`
+	DesktopX *int32 `json:"desktop_x"`
+	DesktopY *int32 `json:"desktop_y"`
+	MobileX  *int32 `json:"mobile_x"`
+	MobileY  *int32 `json:"mobile_y"`
+}
+
 type ContentItemDTO struct {
 	ID          string                 `json:"id"`
 	UserID      string                 `json:"user_id"`
@@ -85,8 +265,52 @@ type ContentItemDTO struct {
 	ContentData map[string]interface{} `json:"content_data,omitempty"`
 	Overrides   map[string]interface{} `json:"overrides,omitempty"`
 	IsActive    bool                   `json:"is_active"`
-	CreatedAt   string                 `json:"created_at,omitempty"`
-	UpdatedAt   string                 `json:"updated_at,omitempty"`
+	Pinned      bool                   `json:"pinned"`
+	PinOrder    int32                  `json:"pin_order"`
+	Repost      *RepostSourceDTO       `json:"repost,omitempty"`
+	PublishAt   string                 `json:"publish_at,omitempty"`
+	UnpublishAt string                 `json:"unpublish_at,omitempty"`
+	// ScheduleStatus is "scheduled" or "expired" for items outside their
+	// publish window, which only their owner gets to see
+	ScheduleStatus string `json:"schedule_status,omitempty"`
+	CreatedAt      string `json:"created_at,omitempty"`
+	UpdatedAt      string `json:"updated_at,omitempty"`
+	DeletedAt      string `json:"deleted_at,omitempty"`
+}
+
+// RepostSourceDTO is the current state of the item a repost points at,
+// attributed to its original owner
+type RepostSourceDTO struct {
+	ItemID      string                 `json:"item_id"`
+	UserID      string                 `json:"user_id"`
+	Handle      string                 `json:"handle,omitempty"`
+	ContentType string                 `json:"content_type"`
+	Title       string                 `json:"title,omitempty"`
+	Href        string                 `json:"href,omitempty"`
+	URL         string                 `json:"url,omitempty"`
+	MediaType   string                 `json:"media_type,omitempty"`
+	ContentData map[string]interface{} `json:"content_data,omitempty"`
+}
+
+// ContentRevisionDTO is a proposed change to a content item awaiting review
+type ContentRevisionDTO struct {
+	ID         string                  `json:"id"`
+	ItemID     string                  `json:"item_id"`
+	OwnerID    string                  `json:"owner_id"`
+	AuthorID   string                  `json:"author_id"`
+	Changes    *UpdateContentItemInput `json:"changes"`
+	Status     string                  `json:"status"`
+	ReviewedBy string                  `json:"reviewed_by,omitempty"`
+	ReviewedAt string                  `json:"reviewed_at,omitempty"`
+	CreatedAt  string                  `json:"created_at,omitempty"`
+}
+
+// ContentUpdateResultDTO reports whether an update went live immediately or
+// was held for the owner's approval
+type ContentUpdateResultDTO struct {
+	PendingApproval bool                `json:"pending_approval"`
+	Item            *ContentItemDTO     `json:"item,omitempty"`
+	Revision        *ContentRevisionDTO `json:"revision,omitempty"`
 }
 
 type PositionDTO struct {
@@ -105,11 +329,40 @@ type StyleDTO struct {
 	Mobile  string `json:"mobile,omitempty"`
 }
 
-func NewContentService(contentRepo repository.ContentRepository, userRepo repository.UserRepository, logger log.Logger) ContentService {
+func NewContentService(
+	contentRepo repository.ContentRepository,
+	userRepo repository.UserRepository,
+	revisionRepo repository.ContentRevisionRepository,
+	linkHealthRepo repository.LinkHealthRepository,
+	historyRepo repository.ContentHistoryRepository,
+	txManager repository.TxManager,
+	prefetcher *LinkPrefetcher,
+	config ContentConfig,
+	logger log.Logger,
+) ContentService {
+	if config.FallbackOrder != ContentOrderOldestFirst {
+		config.FallbackOrder = ContentOrderNewestFirst
+	}
+	if config.MaxPinnedFree <= 0 {
+		config.MaxPinnedFree = defaultMaxPinnedFree
+	}
+	if config.MaxPinnedPremium <= 0 {
+		config.MaxPinnedPremium = defaultMaxPinnedPremium
+	}
+	if config.TrashRetention <= 0 {
+		config.TrashRetention = defaultTrashRetention
+	}
+
 	return &contentService{
-		contentRepo: contentRepo,
-		userRepo:    userRepo,
-		logger:      logger,
+		contentRepo:    contentRepo,
+		userRepo:       userRepo,
+		revisionRepo:   revisionRepo,
+		linkHealthRepo: linkHealthRepo,
+		historyRepo:    historyRepo,
+		txManager:      txManager,
+		prefetcher:     prefetcher,
+		config:         config,
+		logger:         logger,
 	}
 }
 
@@ -123,7 +376,7 @@ func (s *contentService) CreateContentItem(ctx context.Context, input CreateCont
 	}
 
 	// Verify user exists
-	_, err = s.userRepo.GetUser(ctx, userID)
+	owner, err := s.userRepo.GetUser(ctx, userID)
 	if err != nil {
 		if errors.IsNotFound(err) {
 			s.logger.Warnf("User not found with ID: %s", input.UserID)
@@ -133,6 +386,39 @@ func (s *contentService) CreateContentItem(ctx context.Context, input CreateCont
 		return nil, errors.Wrap(err, "Failed to retrieve user")
 	}
 
+	if input.ContentType == ContentTypeRepost {
+		if err := s.validateRepostSource(ctx, uuid.Nil, input.ContentData); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := validateFieldLengths(s.config.FieldLimits.contentChecks(input.Title, input.Href, input.URL)...); err != nil {
+		return nil, err
+	}
+
+	if err := s.enforceHTTPSLinks(&input.Href, &input.URL); err != nil {
+		return nil, err
+	}
+
+	if err := validatePublishWindow(input.PublishAt, input.UnpublishAt); err != nil {
+		return nil, err
+	}
+
+	if err := s.enforceTypeMax(ctx, userID, input.ContentType); err != nil {
+		return nil, err
+	}
+
+	if err := s.enforceActiveItemLimit(ctx, owner); err != nil {
+		return nil, err
+	}
+
+	pinned := input.Pinned != nil && *input.Pinned
+	if pinned {
+		if err := s.enforcePinLimit(ctx, owner, uuid.Nil); err != nil {
+			return nil, err
+		}
+	}
+
 	// Process JSON data
 	var contentData, overrides pgtype.JSONB
 	if len(input.ContentData) > 0 {
@@ -159,24 +445,30 @@ func (s *contentService) CreateContentItem(ctx context.Context, input CreateCont
 
 	// Create content item
 	params := repository.CreateContentItemParams{
-		UserID:       userID,
-		ContentID:    input.ContentID,
-		ContentType:  input.ContentType,
-		Title:        input.Title,
-		Href:         input.Href,
-		URL:          input.URL,
-		MediaType:    input.MediaType,
-		DesktopX:     input.DesktopX,
-		DesktopY:     input.DesktopY,
-		DesktopStyle: input.DesktopStyle,
-		MobileX:      input.MobileX,
-		MobileY:      input.MobileY,
-		MobileStyle:  input.MobileStyle,
-		HAlign:       input.HAlign,
-		VAlign:       input.VAlign,
-		ContentData:  contentData,
-		Overrides:    overrides,
-		IsActive:     true,
+		UserID:        userID,
+		ContentID:     input.ContentID,
+		ContentType:   input.ContentType,
+		Title:         input.Title,
+		Href:          input.Href,
+		URL:           input.URL,
+		MediaType:     input.MediaType,
+		DesktopX:      input.DesktopX,
+		DesktopY:      input.DesktopY,
+		DesktopStyle:  input.DesktopStyle,
+		MobileX:       input.MobileX,
+		MobileY:       input.MobileY,
+		MobileStyle:   input.MobileStyle,
+		HAlign:        input.HAlign,
+		VAlign:        input.VAlign,
+		ContentData:   contentData,
+		Overrides:     overrides,
+		IsActive:      true,
+		Pinned:        pinned,
+		PinOrder:      derefInt32(input.PinOrder),
+		PublishAt:     input.PublishAt,
+		UnpublishAt:   input.UnpublishAt,
+		CustomStyling: pgtype.JSONB{Status: pgtype.Null},
+		EmbedData:     pgtype.JSONB{Status: pgtype.Null},
 	}
 
 	contentItem, err := s.contentRepo.CreateContentItem(ctx, params)
@@ -189,6 +481,15 @@ func (s *contentService) CreateContentItem(ctx context.Context, input CreateCont
 		return nil, errors.Wrap(err, "Failed to create content item")
 	}
 
+	s.recordHistory(ctx, repository.ContentHistoryEntry{
+		ItemID:  contentItem.ItemID,
+		ActorID: &userID,
+		Source:  HistorySourceUser,
+		Action:  HistoryActionCreated,
+	})
+
+	s.prefetcher.Prefetch(linkTarget(contentItem))
+
 	s.logger.Infof("Content item created successfully with ID: %s", contentItem.ItemID)
 	return mapContentItemToDTO(contentItem), nil
 }
@@ -212,11 +513,16 @@ func (s *contentService) GetContentItem(ctx context.Context, itemIDStr string) (
 		return nil, errors.Wrap(err, "Failed to retrieve content item")
 	}
 
+	dto := mapContentItemToDTO(contentItem)
+	if contentItem.ContentType == ContentTypeRepost {
+		dto.Repost = s.resolveReposts(ctx, []*db.ContentItem{contentItem}, "")[contentItem.ItemID]
+	}
+
 	s.logger.Debugf("Content item retrieved successfully with ID: %s", itemIDStr)
-	return mapContentItemToDTO(contentItem), nil
+	return dto, nil
 }
 
-func (s *contentService) GetUserContentItems(ctx context.Context, userIDStr string) ([]*ContentItemDTO, error) {
+func (s *contentService) GetUserContentItems(ctx context.Context, userIDStr, viewerID string) ([]*ContentItemDTO, error) {
 	s.logger.Debugf("Getting content items for user ID: %s", userIDStr)
 
 	userID, err := uuid.Parse(userIDStr)
@@ -225,8 +531,8 @@ func (s *contentService) GetUserContentItems(ctx context.Context, userIDStr stri
 		return nil, errors.NewBadRequestError("Invalid user ID format", err)
 	}
 
-	// Verify user exists
-	_, err = s.userRepo.GetUser(ctx, userID)
+	// Verify user exists and may be seen
+	user, err := s.userRepo.GetUser(ctx, userID)
 	if err != nil {
 		if errors.IsNotFound(err) {
 			s.logger.Infof("User not found with ID: %s", userIDStr)
@@ -235,23 +541,96 @@ func (s *contentService) GetUserContentItems(ctx context.Context, userIDStr stri
 		s.logger.Errorf("Error retrieving user: %v", err)
 		return nil, errors.Wrap(err, "Failed to retrieve user")
 	}
+	if isHiddenFrom(user, viewerID) {
+		s.logger.Debugf("Profile %s is private, hiding content items", userIDStr)
+		return nil, errors.NewNotFoundError("User not found", nil)
+	}
 
-	contentItems, err := s.contentRepo.GetUserContentItems(ctx, userID)
+	// Owners see their scheduled and expired items too, flagged as such;
+	// everyone else only gets what's currently published
+	var contentItems []*db.ContentItem
+	if viewerID == user.UserID.String() {
+		contentItems, err = s.contentRepo.GetUserContentItems(ctx, userID)
+	} else {
+		contentItems, err = s.contentRepo.GetPublishedUserContentItems(ctx, userID)
+	}
 	if err != nil {
 		s.logger.Errorf("Failed to retrieve content items: %v", err)
 		return nil, errors.Wrap(err, "Failed to retrieve content items")
 	}
 
-	dtos := make([]*ContentItemDTO, len(contentItems))
-	for i, item := range contentItems {
-		dtos[i] = mapContentItemToDTO(item)
+	sortContentItems(contentItems, s.config.FallbackOrder)
+
+	reposts := s.resolveReposts(ctx, contentItems, viewerID)
+
+	dtos := make([]*ContentItemDTO, 0, len(contentItems))
+	for _, item := range contentItems {
+		dto := mapContentItemToDTO(item)
+
+		// Reposts whose source can't be shown are hidden rather than
+		// rendered as empty cards
+		if item.ContentType == ContentTypeRepost {
+			source, ok := reposts[item.ItemID]
+			if !ok {
+				continue
+			}
+			dto.Repost = source
+		}
+
+		dtos = append(dtos, dto)
 	}
 
 	s.logger.Debugf("Retrieved %d content items for user ID: %s", len(dtos), userIDStr)
 	return dtos, nil
 }
 
+// sortContentItems puts pinned items first, in pin order, then orders items
+// by desktop position, top to bottom then left to right. Items with unset or
+// equal positions fall back to creation time in the given order and then to
+// item ID, so a profile renders the same way on every request.
+func sortContentItems(items []*db.ContentItem, fallbackOrder string) {
+	sort.SliceStable(items, func(i, j int) bool {
+		a, b := items[i], items[j]
+
+		if a.Pinned != b.Pinned {
+			return a.Pinned
+		}
+		if a.Pinned && a.PinOrder != b.PinOrder {
+			return a.PinOrder < b.PinOrder
+		}
+
+		if ay, by := derefInt32(a.DesktopY), derefInt32(b.DesktopY); ay != by {
+			return ay < by
+		}
+		if ax, bx := derefInt32(a.DesktopX), derefInt32(b.DesktopX); ax != bx {
+			return ax < bx
+		}
+
+		var aCreated, bCreated time.Time
+		if a.CreatedAt != nil {
+			aCreated = *a.CreatedAt
+		}
+		if b.CreatedAt != nil {
+			bCreated = *b.CreatedAt
+		}
+		if !aCreated.Equal(bCreated) {
+			if fallbackOrder == ContentOrderOldestFirst {
+				return aCreated.Before(bCreated)
+			}
+			return aCreated.After(bCreated)
+		}
+
+		return a.ItemID.String() < b.ItemID.String()
+	})
+}
+
 func (s *contentService) UpdateContentItem(ctx context.Context, itemIDStr string, input UpdateContentItemInput) (*ContentItemDTO, error) {
+	return s.updateContentItem(ctx, nil, HistoryActionUpdated, itemIDStr, input)
+}
+
+// updateContentItem applies an update and records it in the item's history
+// as action by actorID
+func (s *contentService) updateContentItem(ctx context.Context, actorID *uuid.UUID, action, itemIDStr string, input UpdateContentItemInput) (*ContentItemDTO, error) {
 	s.logger.Infof("Updating content item with ID: %s", itemIDStr)
 
 	itemID, err := uuid.Parse(itemIDStr)
@@ -261,7 +640,7 @@ func (s *contentService) UpdateContentItem(ctx context.Context, itemIDStr string
 	}
 
 	// Verify content item exists
-	_, err = s.contentRepo.GetContentItem(ctx, itemID)
+	existing, err := s.contentRepo.GetContentItem(ctx, itemID)
 	if err != nil {
 		if errors.IsNotFound(err) {
 			s.logger.Infof("Content item not found with ID: %s", itemIDStr)
@@ -271,6 +650,53 @@ func (s *contentService) UpdateContentItem(ctx context.Context, itemIDStr string
 		return nil, errors.Wrap(err, "Failed to retrieve content item")
 	}
 
+	if existing.ContentType == ContentTypeRepost && len(input.ContentData) > 0 {
+		if err := s.validateRepostSource(ctx, itemID, input.ContentData); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := validateFieldLengths(s.config.FieldLimits.contentChecks(input.Title, input.Href, input.URL)...); err != nil {
+		return nil, err
+	}
+
+	if err := s.enforceHTTPSLinks(&input.Href, &input.URL); err != nil {
+		return nil, err
+	}
+
+	// Check the window as it will be after the update, since only one end
+	// of it may be changing
+	publishAt, unpublishAt := existing.PublishAt, existing.UnpublishAt
+	if input.PublishAt != nil {
+		publishAt = input.PublishAt
+	}
+	if input.UnpublishAt != nil {
+		unpublishAt = input.UnpublishAt
+	}
+	if err := validatePublishWindow(publishAt, unpublishAt); err != nil {
+		return nil, err
+	}
+
+	pinning := input.Pinned != nil && *input.Pinned && !existing.Pinned
+	publishing := input.IsActive != nil && *input.IsActive && (existing.IsActive == nil || !*existing.IsActive)
+	if pinning || publishing {
+		owner, err := s.userRepo.GetUser(ctx, existing.UserID)
+		if err != nil {
+			s.logger.Errorf("Error retrieving content owner: %v", err)
+			return nil, errors.Wrap(err, "Failed to retrieve content owner")
+		}
+		if pinning {
+			if err := s.enforcePinLimit(ctx, owner, itemID); err != nil {
+				return nil, err
+			}
+		}
+		if publishing {
+			if err := s.enforceActiveItemLimit(ctx, owner); err != nil {
+				return nil, err
+			}
+		}
+	}
+
 	// Process JSON data
 	var contentData, overrides *pgtype.JSONB
 
@@ -310,6 +736,10 @@ func (s *contentService) UpdateContentItem(ctx context.Context, itemIDStr string
 		ContentData:  contentData,
 		Overrides:    overrides,
 		IsActive:     input.IsActive,
+		Pinned:       input.Pinned,
+		PinOrder:     input.PinOrder,
+		PublishAt:    input.PublishAt,
+		UnpublishAt:  input.UnpublishAt,
 	}
 
 	err = s.contentRepo.UpdateContentItem(ctx, params)
@@ -325,6 +755,20 @@ func (s *contentService) UpdateContentItem(ctx context.Context, itemIDStr string
 		return nil, errors.Wrap(err, "Failed to retrieve updated content item")
 	}
 
+	if changes := diffContentItems(existing, updatedItem); len(changes) > 0 {
+		s.recordHistory(ctx, repository.ContentHistoryEntry{
+			ItemID:  itemID,
+			ActorID: actorID,
+			Source:  HistorySourceUser,
+			Action:  action,
+			Changes: changes,
+		})
+	}
+
+	if target := linkTarget(updatedItem); target != linkTarget(existing) {
+		s.prefetcher.Prefetch(target)
+	}
+
 	s.logger.Infof("Content item updated successfully with ID: %s", itemIDStr)
 	return mapContentItemToDTO(updatedItem), nil
 }
@@ -375,6 +819,70 @@ func (s *contentService) UpdateContentItemPosition(ctx context.Context, itemIDSt
 	return mapContentItemToDTO(updatedItem), nil
 }
 
+// ReorderContentItems saves the position of many items in one statement.
+// The user must own every item; if anything fails, no item moves.
+func (s *contentService) ReorderContentItems(ctx context.Context, userIDStr string, items []PositionUpdate) error {
+	s.logger.Infof("Reordering %d content items for user ID: %s", len(items), userIDStr)
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		s.logger.Warnf("Invalid user ID format: %v", err)
+		return errors.NewBadRequestError("Invalid user ID format", err)
+	}
+
+	if len(items) == 0 {
+		return errors.NewValidationError("Provide at least one item to reorder", nil)
+	}
+	if len(items) > maxBulkItems {
+		return errors.NewValidationError(fmt.Sprintf("At most %d items can be reordered at once", maxBulkItems), nil)
+	}
+
+	seen := make(map[uuid.UUID]bool, len(items))
+	itemIDs := make([]uuid.UUID, 0, len(items))
+	positions := make([]repository.ItemPosition, 0, len(items))
+	for _, item := range items {
+		itemID, err := uuid.Parse(item.ItemID)
+		if err != nil {
+			s.logger.Warnf("Invalid item ID format: %v", err)
+			return errors.NewBadRequestError(fmt.Sprintf("Invalid item ID format: %s", item.ItemID), err)
+		}
+		if seen[itemID] {
+			return errors.NewValidationError(fmt.Sprintf("Item %s is listed more than once", itemID), nil)
+		}
+		if item.DesktopX == nil || item.DesktopY == nil || item.MobileX == nil || item.MobileY == nil {
+			return errors.NewValidationError(fmt.Sprintf("Item %s needs desktop and mobile coordinates", itemID), nil)
+		}
+		seen[itemID] = true
+		itemIDs = append(itemIDs, itemID)
+		positions = append(positions, repository.ItemPosition{
+			ItemID:   itemID,
+			DesktopX: *item.DesktopX,
+			DesktopY: *item.DesktopY,
+			MobileX:  *item.MobileX,
+			MobileY:  *item.MobileY,
+		})
+	}
+
+	if err := s.verifyItemsOwnership(ctx, userID, itemIDs); err != nil {
+		return err
+	}
+
+	updated, err := s.contentRepo.ReorderContentItems(ctx, userID, positions)
+	if err != nil {
+		s.logger.Errorf("Failed to reorder content items: %v", err)
+		return errors.Wrap(err, "Failed to reorder content items")
+	}
+	// The statement only applies when every item is still the user's, so
+	// a shortfall means items were deleted since the check and none moved
+	if updated != int64(len(positions)) {
+		s.logger.Warnf("Reorder for user ID %s updated %d of %d items", userIDStr, updated, len(positions))
+		return errors.NewConflictError("Content items changed while saving the layout, please reload and try again", nil)
+	}
+
+	s.logger.Infof("Reordered %d content items for user ID: %s", updated, userIDStr)
+	return nil
+}
+
 func (s *contentService) DeleteContentItem(ctx context.Context, itemIDStr string) error {
 	s.logger.Infof("Deleting content item with ID: %s", itemIDStr)
 
@@ -385,7 +893,7 @@ func (s *contentService) DeleteContentItem(ctx context.Context, itemIDStr string
 	}
 
 	// Verify content item exists
-	_, err = s.contentRepo.GetContentItem(ctx, itemID)
+	item, err := s.contentRepo.GetContentItem(ctx, itemID)
 	if err != nil {
 		if errors.IsNotFound(err) {
 			s.logger.Infof("Content item not found with ID: %s", itemIDStr)
@@ -395,88 +903,712 @@ func (s *contentService) DeleteContentItem(ctx context.Context, itemIDStr string
 		return errors.Wrap(err, "Failed to retrieve content item")
 	}
 
-	// Delete content item
+	if limit, ok := s.config.TypeLimits[item.ContentType]; ok && limit.Min > 0 {
+		count, err := s.countItemsOfType(ctx, item.UserID, item.ContentType)
+		if err != nil {
+			return err
+		}
+		if count <= limit.Min {
+			s.logger.Warnf("Refusing to delete item %s: user %s needs at least %d %s items",
+				itemIDStr, item.UserID, limit.Min, item.ContentType)
+			return errors.NewValidationError(
+				fmt.Sprintf("At least %d %s item(s) required", limit.Min, item.ContentType), nil)
+		}
+	}
+
+	// Move the item to the trash; it's purged after TrashRetention
 	err = s.contentRepo.DeleteContentItem(ctx, itemID)
 	if err != nil {
+		if errors.IsNotFound(err) {
+			return err
+		}
 		s.logger.Errorf("Failed to delete content item: %v", err)
 		return errors.Wrap(err, "Failed to delete content item")
 	}
 
+	s.recordHistory(ctx, repository.ContentHistoryEntry{
+		ItemID: itemID,
+		Source: HistorySourceUser,
+		Action: HistoryActionDeleted,
+	})
+
 	s.logger.Infof("Content item deleted successfully with ID: %s", itemIDStr)
 	return nil
 }
 
-func mapContentItemToDTO(item *db.ContentItem) *ContentItemDTO {
-	dto := &ContentItemDTO{
-		ID:          item.ItemID.String(),
-		UserID:      item.UserID.String(),
-		ContentID:   item.ContentID,
-		ContentType: item.ContentType,
-		IsActive:    item.IsActive != nil && *item.IsActive,
-		Position: PositionDTO{
-			Desktop: struct {
-				X int32 `json:"x"`
-				Y int32 `json:"y"`
-			}{
-				X: 0,
-				Y: 0,
-			},
-			Mobile: struct {
-				X int32 `json:"x"`
-				Y int32 `json:"y"`
-			}{
-				X: 0,
-				Y: 0,
-			},
-		},
-		Style: StyleDTO{},
+// SubmitContentUpdate applies an update on behalf of actorID. Owners and
+// admins edit directly; collaborators of an owner who requires approval get
+// a pending revision instead, and the live item is left untouched.
+func (s *contentService) SubmitContentUpdate(ctx context.Context, actorIDStr, itemIDStr string, input UpdateContentItemInput) (*ContentUpdateResultDTO, error) {
+	s.logger.Infof("Submitting update for content item ID: %s by user ID: %s", itemIDStr, actorIDStr)
+
+	actorID, err := uuid.Parse(actorIDStr)
+	if err != nil {
+		s.logger.Warnf("Invalid user ID format: %v", err)
+		return nil, errors.NewBadRequestError("Invalid user ID format", err)
 	}
 
-	if item.Title != nil {
-		dto.Title = *item.Title
+	itemID, err := uuid.Parse(itemIDStr)
+	if err != nil {
+		s.logger.Warnf("Invalid item ID format: %v", err)
+		return nil, errors.NewBadRequestError("Invalid item ID format", err)
 	}
 
-	if item.Href != nil {
-		dto.Href = *item.Href
+	item, err := s.contentRepo.GetContentItem(ctx, itemID)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			s.logger.Infof("Content item not found with ID: %s", itemIDStr)
+			return nil, errors.NewNotFoundError("Content item not found", err)
+		}
+		s.logger.Errorf("Error retrieving content item: %v", err)
+		return nil, errors.Wrap(err, "Failed to retrieve content item")
 	}
 
-	if item.Url != nil {
-		dto.URL = *item.Url
+	owner, err := s.userRepo.GetUser(ctx, item.UserID)
+	if err != nil {
+		s.logger.Errorf("Error retrieving content owner: %v", err)
+		return nil, errors.Wrap(err, "Failed to retrieve content owner")
 	}
 
-	if item.MediaType != nil {
-		dto.MediaType = *item.MediaType
+	needsReview := false
+	if actorID != owner.UserID {
+		isAdmin, err := s.isAdmin(ctx, actorID)
+		if err != nil {
+			return nil, err
+		}
+
+		if !isAdmin {
+			isCollaborator, err := s.revisionRepo.IsCollaborator(ctx, owner.UserID, actorID)
+			if err != nil {
+				s.logger.Errorf("Failed to check collaborator status: %v", err)
+				return nil, errors.Wrap(err, "Failed to check collaborator status")
+			}
+			if !isCollaborator {
+				s.logger.Warnf("User %s is not allowed to edit content item %s", actorIDStr, itemIDStr)
+				return nil, errors.NewForbiddenError("You are not allowed to edit this content item", nil)
+			}
+			needsReview = owner.RequiresContentApproval
+		}
 	}
 
-	if item.DesktopX != nil {
-		dto.Position.Desktop.X = *item.DesktopX
+	if !needsReview {
+		updated, err := s.updateContentItem(ctx, &actorID, HistoryActionUpdated, itemIDStr, input)
+		if err != nil {
+			return nil, err
+		}
+		return &ContentUpdateResultDTO{Item: updated}, nil
 	}
 
-	if item.DesktopY != nil {
-		dto.Position.Desktop.Y = *item.DesktopY
+	if item.ContentType == ContentTypeRepost && len(input.ContentData) > 0 {
+		if err := s.validateRepostSource(ctx, itemID, input.ContentData); err != nil {
+			return nil, err
+		}
 	}
 
-	if item.DesktopStyle != nil {
-		dto.Style.Desktop = *item.DesktopStyle
+	if err := validateFieldLengths(s.config.FieldLimits.contentChecks(input.Title, input.Href, input.URL)...); err != nil {
+		return nil, err
 	}
 
-	if item.MobileX != nil {
-		dto.Position.Mobile.X = *item.MobileX
+	if err := s.enforceHTTPSLinks(&input.Href, &input.URL); err != nil {
+		return nil, err
 	}
 
-	if item.MobileY != nil {
-		dto.Position.Mobile.Y = *item.MobileY
+	var changes pgtype.JSONB
+	changes.Status = pgtype.Present
+	changes.Bytes, err = json.Marshal(input)
+	if err != nil {
+		s.logger.Warnf("Failed to marshal revision changes: %v", err)
+		return nil, errors.NewValidationError("Invalid content update", err)
 	}
 
-	if item.MobileStyle != nil {
-		dto.Style.Mobile = *item.MobileStyle
+	revision, err := s.revisionRepo.CreateRevision(ctx, repository.CreateRevisionParams{
+		ItemID:   itemID,
+		OwnerID:  owner.UserID,
+		AuthorID: actorID,
+		Changes:  changes,
+	})
+	if err != nil {
+		s.logger.Errorf("Failed to create content revision: %v", err)
+		return nil, errors.Wrap(err, "Failed to create content revision")
 	}
 
-	if item.Halign != nil || item.Valign != nil {
-		dto.HAlign = make(map[string]string)
-		dto.VAlign = make(map[string]string)
+	s.logger.Infof("Content revision %s created for item ID: %s, awaiting approval", revision.RevisionID, itemIDStr)
+	return &ContentUpdateResultDTO{
+		PendingApproval: true,
+		Revision:        mapContentRevisionToDTO(revision),
+	}, nil
+}
 
-		if item.Halign != nil {
+// ListPendingRevisions returns revisions awaiting the actor's review. Admins
+// see pending revisions across all accounts.
+func (s *contentService) ListPendingRevisions(ctx context.Context, actorIDStr string) ([]*ContentRevisionDTO, error) {
+	s.logger.Debugf("Listing pending revisions for user ID: %s", actorIDStr)
+
+	actorID, err := uuid.Parse(actorIDStr)
+	if err != nil {
+		s.logger.Warnf("Invalid user ID format: %v", err)
+		return nil, errors.NewBadRequestError("Invalid user ID format", err)
+	}
+
+	isAdmin, err := s.isAdmin(ctx, actorID)
+	if err != nil {
+		return nil, err
+	}
+
+	var revisions []*db.ContentRevision
+	if isAdmin {
+		revisions, err = s.revisionRepo.ListPendingRevisions(ctx)
+	} else {
+		revisions, err = s.revisionRepo.ListPendingRevisionsByOwner(ctx, actorID)
+	}
+	if err != nil {
+		s.logger.Errorf("Failed to list pending revisions: %v", err)
+		return nil, errors.Wrap(err, "Failed to list pending revisions")
+	}
+
+	dtos := make([]*ContentRevisionDTO, len(revisions))
+	for i, revision := range revisions {
+		dtos[i] = mapContentRevisionToDTO(revision)
+	}
+
+	s.logger.Debugf("Retrieved %d pending revisions for user ID: %s", len(dtos), actorIDStr)
+	return dtos, nil
+}
+
+func (s *contentService) ApproveRevision(ctx context.Context, actorIDStr, itemIDStr, revisionIDStr string) (*ContentItemDTO, error) {
+	s.logger.Infof("Approving revision %s for content item ID: %s", revisionIDStr, itemIDStr)
+
+	actorID, revision, err := s.getReviewableRevision(ctx, actorIDStr, itemIDStr, revisionIDStr)
+	if err != nil {
+		return nil, err
+	}
+
+	var input UpdateContentItemInput
+	if err := json.Unmarshal(revision.Changes.Bytes, &input); err != nil {
+		s.logger.Errorf("Failed to decode revision %s: %v", revisionIDStr, err)
+		return nil, errors.NewInternalError("Stored revision is malformed", err)
+	}
+
+	// Claim the revision and apply its changes in one transaction. A
+	// concurrent review waits on the claim and then finds the revision taken,
+	// and a failed update rolls the claim back so the revision stays pending.
+	// The history credits the revision's author; the approval itself is on
+	// the revision.
+	var updated *ContentItemDTO
+	err = s.txManager.WithTransaction(ctx, func(ctx context.Context) error {
+		if _, err := s.revisionRepo.ReviewRevision(ctx, revision.RevisionID, RevisionApproved, actorID); err != nil {
+			if errors.IsNotFound(err) {
+				return errors.NewConflictError("Revision has already been reviewed", err)
+			}
+			s.logger.Errorf("Failed to approve revision: %v", err)
+			return errors.Wrap(err, "Failed to approve revision")
+		}
+
+		updated, err = s.updateContentItem(ctx, &revision.AuthorID, HistoryActionRevisionApproved, itemIDStr, input)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Infof("Revision %s approved and applied to content item ID: %s", revisionIDStr, itemIDStr)
+	return updated, nil
+}
+
+func (s *contentService) RejectRevision(ctx context.Context, actorIDStr, itemIDStr, revisionIDStr string) (*ContentRevisionDTO, error) {
+	s.logger.Infof("Rejecting revision %s for content item ID: %s", revisionIDStr, itemIDStr)
+
+	actorID, revision, err := s.getReviewableRevision(ctx, actorIDStr, itemIDStr, revisionIDStr)
+	if err != nil {
+		return nil, err
+	}
+
+	rejected, err := s.revisionRepo.ReviewRevision(ctx, revision.RevisionID, RevisionRejected, actorID)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, errors.NewConflictError("Revision has already been reviewed", err)
+		}
+		s.logger.Errorf("Failed to reject revision: %v", err)
+		return nil, errors.Wrap(err, "Failed to reject revision")
+	}
+
+	s.logger.Infof("Revision %s rejected for content item ID: %s", revisionIDStr, itemIDStr)
+	return mapContentRevisionToDTO(rejected), nil
+}
+
+func (s *contentService) SetApprovalRequired(ctx context.Context, ownerIDStr string, required bool) error {
+	s.logger.Infof("Setting content approval mode for user ID: %s to: %v", ownerIDStr, required)
+
+	ownerID, err := uuid.Parse(ownerIDStr)
+	if err != nil {
+		s.logger.Warnf("Invalid user ID format: %v", err)
+		return errors.NewBadRequestError("Invalid user ID format", err)
+	}
+
+	if err := s.userRepo.UpdateContentApproval(ctx, ownerID, required); err != nil {
+		s.logger.Errorf("Failed to update content approval mode: %v", err)
+		return errors.Wrap(err, "Failed to update content approval mode")
+	}
+
+	return nil
+}
+
+func (s *contentService) AddCollaborator(ctx context.Context, ownerIDStr, collaboratorIDStr string) error {
+	s.logger.Infof("Adding collaborator %s to account %s", collaboratorIDStr, ownerIDStr)
+
+	ownerID, collaboratorID, err := parseCollaboratorIDs(ownerIDStr, collaboratorIDStr)
+	if err != nil {
+		return err
+	}
+
+	if ownerID == collaboratorID {
+		return errors.NewValidationError("You cannot add yourself as a collaborator", nil)
+	}
+
+	if _, err := s.userRepo.GetUser(ctx, collaboratorID); err != nil {
+		if errors.IsNotFound(err) {
+			return errors.NewNotFoundError("Collaborator not found", err)
+		}
+		s.logger.Errorf("Error retrieving collaborator: %v", err)
+		return errors.Wrap(err, "Failed to retrieve collaborator")
+	}
+
+	if err := s.revisionRepo.AddCollaborator(ctx, ownerID, collaboratorID); err != nil {
+		s.logger.Errorf("Failed to add collaborator: %v", err)
+		return errors.Wrap(err, "Failed to add collaborator")
+	}
+
+	return nil
+}
+
+func (s *contentService) RemoveCollaborator(ctx context.Context, ownerIDStr, collaboratorIDStr string) error {
+	s.logger.Infof("Removing collaborator %s from account %s", collaboratorIDStr, ownerIDStr)
+
+	ownerID, collaboratorID, err := parseCollaboratorIDs(ownerIDStr, collaboratorIDStr)
+	if err != nil {
+		return err
+	}
+
+	if err := s.revisionRepo.RemoveCollaborator(ctx, ownerID, collaboratorID); err != nil {
+		s.logger.Errorf("Failed to remove collaborator: %v", err)
+		return errors.Wrap(err, "Failed to remove collaborator")
+	}
+
+	return nil
+}
+
+// getReviewableRevision loads a pending revision of itemID and checks that
+// the actor is the item's owner or an admin.
+func (s *contentService) getReviewableRevision(ctx context.Context, actorIDStr, itemIDStr, revisionIDStr string) (uuid.UUID, *db.ContentRevision, error) {
+	actorID, err := uuid.Parse(actorIDStr)
+	if err != nil {
+		s.logger.Warnf("Invalid user ID format: %v", err)
+		return uuid.Nil, nil, errors.NewBadRequestError("Invalid user ID format", err)
+	}
+
+	itemID, err := uuid.Parse(itemIDStr)
+	if err != nil {
+		s.logger.Warnf("Invalid item ID format: %v", err)
+		return uuid.Nil, nil, errors.NewBadRequestError("Invalid item ID format", err)
+	}
+
+	revisionID, err := uuid.Parse(revisionIDStr)
+	if err != nil {
+		s.logger.Warnf("Invalid revision ID format: %v", err)
+		return uuid.Nil, nil, errors.NewBadRequestError("Invalid revision ID format", err)
+	}
+
+	revision, err := s.revisionRepo.GetRevision(ctx, revisionID)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return uuid.Nil, nil, errors.NewNotFoundError("Revision not found", err)
+		}
+		s.logger.Errorf("Error retrieving revision: %v", err)
+		return uuid.Nil, nil, errors.Wrap(err, "Failed to retrieve revision")
+	}
+
+	if revision.ItemID != itemID {
+		return uuid.Nil, nil, errors.NewNotFoundError("Revision not found", nil)
+	}
+
+	if revision.Status != RevisionPending {
+		return uuid.Nil, nil, errors.NewConflictError("Revision has already been reviewed", nil)
+	}
+
+	if revision.OwnerID != actorID {
+		isAdmin, err := s.isAdmin(ctx, actorID)
+		if err != nil {
+			return uuid.Nil, nil, err
+		}
+		if !isAdmin {
+			s.logger.Warnf("User %s is not allowed to review revision %s", actorIDStr, revisionIDStr)
+			return uuid.Nil, nil, errors.NewForbiddenError("Only the owner can review this revision", nil)
+		}
+	}
+
+	return actorID, revision, nil
+}
+
+func (s *contentService) isAdmin(ctx context.Context, userID uuid.UUID) (bool, error) {
+	user, err := s.userRepo.GetUser(ctx, userID)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return false, errors.NewUnauthorizedError("User not found", err)
+		}
+		s.logger.Errorf("Error retrieving user: %v", err)
+		return false, errors.Wrap(err, "Failed to retrieve user")
+	}
+	return user.IsAdmin != nil && *user.IsAdmin, nil
+}
+
+func parseCollaboratorIDs(ownerIDStr, collaboratorIDStr string) (uuid.UUID, uuid.UUID, error) {
+	ownerID, err := uuid.Parse(ownerIDStr)
+	if err != nil {
+		return uuid.Nil, uuid.Nil, errors.NewBadRequestError("Invalid user ID format", err)
+	}
+
+	collaboratorID, err := uuid.Parse(collaboratorIDStr)
+	if err != nil {
+		return uuid.Nil, uuid.Nil, errors.NewBadRequestError("Invalid collaborator ID format", err)
+	}
+
+	return ownerID, collaboratorID, nil
+}
+
+func mapContentRevisionToDTO(revision *db.ContentRevision) *ContentRevisionDTO {
+	dto := &ContentRevisionDTO{
+		ID:       revision.RevisionID.String(),
+		ItemID:   revision.ItemID.String(),
+		OwnerID:  revision.OwnerID.String(),
+		AuthorID: revision.AuthorID.String(),
+		Status:   revision.Status,
+	}
+
+	if revision.Changes.Status == pgtype.Present {
+		var changes UpdateContentItemInput
+		if err := json.Unmarshal(revision.Changes.Bytes, &changes); err == nil {
+			dto.Changes = &changes
+		}
+	}
+
+	if revision.ReviewedBy != nil {
+		dto.ReviewedBy = revision.ReviewedBy.String()
+	}
+
+	if revision.ReviewedAt != nil {
+		dto.ReviewedAt = revision.ReviewedAt.Format(time.RFC3339)
+	}
+
+	if revision.CreatedAt != nil {
+		dto.CreatedAt = revision.CreatedAt.Format(time.RFC3339)
+	}
+
+	return dto
+}
+
+// GetContentTypeSummary reports how many items of each type the user has,
+// alongside any configured limits for that type
+func (s *contentService) GetContentTypeSummary(ctx context.Context, userIDStr string) ([]*ContentTypeSummaryDTO, error) {
+	s.logger.Debugf("Getting content type summary for user ID: %s", userIDStr)
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		s.logger.Warnf("Invalid user ID format: %v", err)
+		return nil, errors.NewBadRequestError("Invalid user ID format", err)
+	}
+
+	counts, err := s.contentRepo.CountUserContentItemsByType(ctx, userID)
+	if err != nil {
+		s.logger.Errorf("Failed to count content items by type: %v", err)
+		return nil, errors.Wrap(err, "Failed to count content items")
+	}
+
+	types := make(map[string]bool, len(counts)+len(s.config.TypeLimits))
+	for contentType := range counts {
+		types[contentType] = true
+	}
+	for contentType := range s.config.TypeLimits {
+		types[contentType] = true
+	}
+
+	summary := make([]*ContentTypeSummaryDTO, 0, len(types))
+	for contentType := range types {
+		limit := s.config.TypeLimits[contentType]
+		summary = append(summary, &ContentTypeSummaryDTO{
+			ContentType: contentType,
+			Count:       counts[contentType],
+			Min:         limit.Min,
+			Max:         limit.Max,
+		})
+	}
+
+	sort.Slice(summary, func(i, j int) bool {
+		return summary[i].ContentType < summary[j].ContentType
+	})
+
+	return summary, nil
+}
+
+func (s *contentService) countItemsOfType(ctx context.Context, userID uuid.UUID, contentType string) (int64, error) {
+	counts, err := s.contentRepo.CountUserContentItemsByType(ctx, userID)
+	if err != nil {
+		s.logger.Errorf("Failed to count content items by type: %v", err)
+		return 0, errors.Wrap(err, "Failed to count content items")
+	}
+	return counts[contentType], nil
+}
+
+// validateRepostSource checks that a repost references an existing item and
+// that following the chain of reposts never leads back to selfID.
+func (s *contentService) validateRepostSource(ctx context.Context, selfID uuid.UUID, contentData map[string]interface{}) error {
+	current, err := repostSourceID(contentData)
+	if err != nil {
+		s.logger.Warnf("Invalid repost source: %v", err)
+		return errors.NewValidationError("Repost content data must include a valid source_item_id", err)
+	}
+
+	visited := map[uuid.UUID]bool{}
+	if selfID != uuid.Nil {
+		visited[selfID] = true
+	}
+
+	for depth := 0; ; depth++ {
+		if visited[current] {
+			s.logger.Warnf("Repost cycle detected at item ID: %s", current)
+			return errors.NewValidationError("Repost would create a cycle", nil)
+		}
+		if depth >= maxRepostDepth {
+			return errors.NewValidationError("Repost chain is too deep", nil)
+		}
+		visited[current] = true
+
+		source, err := s.contentRepo.GetContentItem(ctx, current)
+		if err != nil {
+			if errors.IsNotFound(err) {
+				return errors.NewNotFoundError("Repost source not found", err)
+			}
+			s.logger.Errorf("Error retrieving repost source: %v", err)
+			return errors.Wrap(err, "Failed to retrieve repost source")
+		}
+
+		if source.ContentType != ContentTypeRepost {
+			return nil
+		}
+
+		current, err = repostSourceID(decodeContentData(source))
+		if err != nil {
+			return errors.NewValidationError("Repost source is malformed", err)
+		}
+	}
+}
+
+// resolveReposts follows each repost among items to its original item,
+// loading one level of every chain per query. A repost is left out of the
+// result when any item along its chain is deleted, inactive or outside its
+// publish window, or when the original's owner is gone or has hidden their
+// profile from viewerID.
+func (s *contentService) resolveReposts(ctx context.Context, items []*db.ContentItem, viewerID string) map[uuid.UUID]*RepostSourceDTO {
+	// pending maps each unresolved repost to the next item in its chain
+	pending := make(map[uuid.UUID]uuid.UUID)
+	for _, item := range items {
+		if item.ContentType != ContentTypeRepost {
+			continue
+		}
+		sourceID, err := repostSourceID(decodeContentData(item))
+		if err != nil {
+			s.logger.Warnf("Malformed repost item ID: %s: %v", item.ItemID, err)
+			continue
+		}
+		pending[item.ItemID] = sourceID
+	}
+
+	originals := make(map[uuid.UUID]*db.ContentItem, len(pending))
+	for depth := 0; len(pending) > 0; depth++ {
+		if depth >= maxRepostDepth {
+			s.logger.Warnf("Repost chain too deep for %d items", len(pending))
+			break
+		}
+
+		sources, err := s.contentRepo.GetContentItemsByIDs(ctx, uniqueIDs(pending))
+		if err != nil {
+			s.logger.Errorf("Error resolving repost sources: %v", err)
+			return nil
+		}
+		sourcesByID := make(map[uuid.UUID]*db.ContentItem, len(sources))
+		for _, source := range sources {
+			sourcesByID[source.ItemID] = source
+		}
+
+		now := time.Now()
+		next := make(map[uuid.UUID]uuid.UUID)
+		for repostID, sourceID := range pending {
+			source, ok := sourcesByID[sourceID]
+			if !ok || source.IsActive == nil || !*source.IsActive || scheduleStatus(source, now) != "" {
+				continue
+			}
+			if source.ContentType != ContentTypeRepost {
+				originals[repostID] = source
+				continue
+			}
+
+			nextID, err := repostSourceID(decodeContentData(source))
+			if err != nil {
+				s.logger.Warnf("Malformed repost item ID: %s: %v", source.ItemID, err)
+				continue
+			}
+			next[repostID] = nextID
+		}
+		pending = next
+	}
+
+	if len(originals) == 0 {
+		return nil
+	}
+
+	ownerIDs := make(map[uuid.UUID]uuid.UUID, len(originals))
+	for _, source := range originals {
+		ownerIDs[source.UserID] = source.UserID
+	}
+	owners, err := s.userRepo.GetUsersByIDs(ctx, uniqueIDs(ownerIDs))
+	if err != nil {
+		s.logger.Errorf("Error loading repost source owners: %v", err)
+		return nil
+	}
+	ownersByID := make(map[uuid.UUID]*db.User, len(owners))
+	for _, owner := range owners {
+		ownersByID[owner.UserID] = owner
+	}
+
+	resolved := make(map[uuid.UUID]*RepostSourceDTO, len(originals))
+	for repostID, source := range originals {
+		owner, ok := ownersByID[source.UserID]
+		if !ok || isHiddenFrom(owner, viewerID) {
+			continue
+		}
+
+		sourceDTO := mapContentItemToDTO(source)
+		resolved[repostID] = &RepostSourceDTO{
+			ItemID:      sourceDTO.ID,
+			UserID:      sourceDTO.UserID,
+			Handle:      owner.Handle,
+			ContentType: sourceDTO.ContentType,
+			Title:       sourceDTO.Title,
+			Href:        sourceDTO.Href,
+			URL:         sourceDTO.URL,
+			MediaType:   sourceDTO.MediaType,
+			ContentData: sourceDTO.ContentData,
+		}
+	}
+
+	return resolved
+}
+
+// uniqueIDs returns the distinct values of ids
+func uniqueIDs(ids map[uuid.UUID]uuid.UUID) []uuid.UUID {
+	seen := make(map[uuid.UUID]bool, len(ids))
+	unique := make([]uuid.UUID, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
+
+func repostSourceID(contentData map[string]interface{}) (uuid.UUID, error) {
+	raw, ok := contentData[repostSourceKey].(string)
+	if !ok {
+		return uuid.Nil, fmt.Errorf("missing %s", repostSourceKey)
+	}
+	return uuid.Parse(raw)
+}
+
+func decodeContentData(item *db.ContentItem) map[string]interface{} {
+	if item.ContentData.Status != pgtype.Present {
+		return nil
+	}
+
+	var contentData map[string]interface{}
+	if err := json.Unmarshal(item.ContentData.Bytes, &contentData); err != nil {
+		return nil
+	}
+	return contentData
+}
+
+func mapContentItemToDTO(item *db.ContentItem) *ContentItemDTO {
+	dto := &ContentItemDTO{
+		ID:          item.ItemID.String(),
+		UserID:      item.UserID.String(),
+		ContentID:   item.ContentID,
+		ContentType: item.ContentType,
+		IsActive:    item.IsActive != nil && *item.IsActive,
+		Pinned:      item.Pinned,
+		PinOrder:    item.PinOrder,
+		Position: PositionDTO{
+			Desktop: struct {
+				X int32 `json:"x"`
+				Y int32 `json:"y"`
+			}{
+				X: 0,
+				Y: 0,
+			},
+			Mobile: struct {
+				X int32 `json:"x"`
+				Y int32 `json:"y"`
+			}{
+				X: 0,
+				Y: 0,
+			},
+		},
+		Style: StyleDTO{},
+	}
+
+	if item.Title != nil {
+		dto.Title = *item.Title
+	}
+
+	if item.Href != nil {
+		dto.Href = *item.Href
+	}
+
+	if item.Url != nil {
+		dto.URL = *item.Url
+	}
+
+	if item.MediaType != nil {
+		dto.MediaType = *item.MediaType
+	}
+
+	if item.DesktopX != nil {
+		dto.Position.Desktop.X = *item.DesktopX
+	}
+
+	if item.DesktopY != nil {
+		dto.Position.Desktop.Y = *item.DesktopY
+	}
+
+	if item.DesktopStyle != nil {
+		dto.Style.Desktop = *item.DesktopStyle
+	}
+
+	if item.MobileX != nil {
+		dto.Position.Mobile.X = *item.MobileX
+	}
+
+	if item.MobileY != nil {
+		dto.Position.Mobile.Y = *item.MobileY
+	}
+
+	if item.MobileStyle != nil {
+		dto.Style.Mobile = *item.MobileStyle
+	}
+
+	if item.Halign != nil || item.Valign != nil {
+		dto.HAlign = make(map[string]string)
+		dto.VAlign = make(map[string]string)
+
+		if item.Halign != nil {
 			dto.HAlign["default"] = *item.Halign
 		}
 
@@ -507,5 +1639,211 @@ func mapContentItemToDTO(item *db.ContentItem) *ContentItemDTO {
 		dto.UpdatedAt = item.UpdatedAt.Format(time.RFC3339)
 	}
 
+	dto.PublishAt = formatOptionalTime(item.PublishAt)
+	dto.UnpublishAt = formatOptionalTime(item.UnpublishAt)
+	dto.ScheduleStatus = scheduleStatus(item, time.Now())
+	dto.DeletedAt = formatOptionalTime(item.DeletedAt)
+
 	return dto
 }
+
+// enforceHTTPSLinks applies the RequireHTTPSLinks policy to an item's href
+// and URL, upgrading known HTTPS domains in place and rejecting other
+// plain-HTTP links.
+func (s *contentService) enforceHTTPSLinks(href, mediaURL **string) error {
+	if !s.config.RequireHTTPSLinks {
+		return nil
+	}
+
+	for _, field := range []struct {
+		name  string
+		value **string
+	}{
+		{"href", href},
+		{"url", mediaURL},
+	} {
+		if *field.value == nil {
+			continue
+		}
+
+		raw := strings.TrimSpace(**field.value)
+		parsed, err := url.Parse(raw)
+		if err != nil || !strings.EqualFold(parsed.Scheme, "http") {
+			continue
+		}
+
+		parsed.Scheme = "https"
+		secure := parsed.String()
+
+		if s.isHTTPSUpgradeDomain(parsed.Hostname()) {
+			s.logger.Debugf("Upgrading %s to HTTPS for known domain %s", field.name, parsed.Hostname())
+			*field.value = &secure
+			continue
+		}
+
+		s.logger.Warnf("Rejected plain HTTP %s: %s", field.name, raw)
+		return errors.NewValidationError(
+			fmt.Sprintf("Only HTTPS links are allowed for %s, use %s instead", field.name, secure), nil)
+	}
+
+	return nil
+}
+
+func (s *contentService) isHTTPSUpgradeDomain(host string) bool {
+	host = strings.ToLower(host)
+	for _, domain := range s.config.HTTPSUpgradeDomains {
+		domain = strings.ToLower(strings.TrimSpace(domain))
+		if domain == "" {
+			continue
+		}
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return true
+		}
+	}
+	return false
+}
+
+// BulkUpdateStyle restyles the user's selected items in one statement.
+// Every explicitly named item must belong to the user.
+func (s *contentService) BulkUpdateStyle(ctx context.Context, userIDStr string, itemIDStrs []string, patch StylePatch) error {
+	s.logger.Infof("Bulk style update for user ID: %s on %d items (type selector: %q)", userIDStr, len(itemIDStrs), patch.ApplyToType)
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		s.logger.Warnf("Invalid user ID format: %v", err)
+		return errors.NewBadRequestError("Invalid user ID format", err)
+	}
+
+	if len(itemIDStrs) == 0 && patch.ApplyToType == "" {
+		return errors.NewValidationError("Provide item IDs or a content type to apply the style to", nil)
+	}
+	if len(itemIDStrs) > maxBulkItems {
+		return errors.NewValidationError(fmt.Sprintf("At most %d items can be updated at once", maxBulkItems), nil)
+	}
+	if patch.DesktopStyle == nil && patch.MobileStyle == nil && len(patch.Overrides) == 0 {
+		return errors.NewValidationError("Style patch is empty", nil)
+	}
+
+	seen := make(map[uuid.UUID]bool, len(itemIDStrs))
+	itemIDs := make([]uuid.UUID, 0, len(itemIDStrs))
+	for _, idStr := range itemIDStrs {
+		itemID, err := uuid.Parse(idStr)
+		if err != nil {
+			s.logger.Warnf("Invalid item ID format: %v", err)
+			return errors.NewBadRequestError(fmt.Sprintf("Invalid item ID format: %s", idStr), err)
+		}
+		if !seen[itemID] {
+			seen[itemID] = true
+			itemIDs = append(itemIDs, itemID)
+		}
+	}
+
+	if err := s.verifyItemsOwnership(ctx, userID, itemIDs); err != nil {
+		return err
+	}
+
+	params := repository.BulkStyleParams{
+		UserID:       userID,
+		ItemIDs:      itemIDs,
+		DesktopStyle: patch.DesktopStyle,
+		MobileStyle:  patch.MobileStyle,
+	}
+	if patch.ApplyToType != "" {
+		params.ContentType = &patch.ApplyToType
+	}
+	if len(patch.Overrides) > 0 {
+		var overrides pgtype.JSONB
+		overrides.Status = pgtype.Present
+		overrides.Bytes, err = json.Marshal(patch.Overrides)
+		if err != nil {
+			s.logger.Warnf("Failed to marshal overrides: %v", err)
+			return errors.NewValidationError("Invalid overrides format", err)
+		}
+		params.Overrides = &overrides
+	}
+
+	updated, err := s.contentRepo.BulkUpdateStyle(ctx, params)
+	if err != nil {
+		s.logger.Errorf("Failed to bulk update content style: %v", err)
+		return errors.Wrap(err, "Failed to update content styles")
+	}
+
+	s.logger.Infof("Bulk style update applied to %d items for user ID: %s", updated, userIDStr)
+	return nil
+}
+
+// OwnershipFailureDTO lists the items a bulk operation was refused for,
+// either because they don't exist or because another user owns them
+type OwnershipFailureDTO struct {
+	ItemIDs []string `json:"item_ids"`
+}
+
+// verifyItemsOwnership checks that userID owns every item with one query, so
+// bulk operations validate their whole selection before mutating anything.
+// The returned forbidden error details which items failed the check.
+func (s *contentService) verifyItemsOwnership(ctx context.Context, userID uuid.UUID, itemIDs []uuid.UUID) error {
+	if len(itemIDs) == 0 {
+		return nil
+	}
+
+	owners, err := s.contentRepo.GetItemsOwnership(ctx, itemIDs)
+	if err != nil {
+		s.logger.Errorf("Failed to verify content item ownership: %v", err)
+		return errors.Wrap(err, "Failed to verify content item ownership")
+	}
+
+	var failed []string
+	for _, itemID := range itemIDs {
+		if owner, ok := owners[itemID]; !ok || owner != userID {
+			failed = append(failed, itemID.String())
+		}
+	}
+
+	if len(failed) > 0 {
+		s.logger.Warnf("User %s does not own %d of %d requested items: %v", userID, len(failed), len(itemIDs), failed)
+		appErr := errors.NewForbiddenError("One or more content items do not belong to you", nil)
+		appErr.Details = OwnershipFailureDTO{ItemIDs: failed}
+		return appErr
+	}
+
+	return nil
+}
+
+// SetLinkAutoDeactivate lets an owner opt a single item out of (or back
+// into) automatic deactivation when its link keeps failing health checks.
+func (s *contentService) SetLinkAutoDeactivate(ctx context.Context, userIDStr, itemIDStr string, enabled bool) error {
+	s.logger.Infof("Setting link auto-deactivation for item ID: %s to: %v", itemIDStr, enabled)
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		s.logger.Warnf("Invalid user ID format: %v", err)
+		return errors.NewBadRequestError("Invalid user ID format", err)
+	}
+
+	itemID, err := uuid.Parse(itemIDStr)
+	if err != nil {
+		s.logger.Warnf("Invalid item ID format: %v", err)
+		return errors.NewBadRequestError("Invalid item ID format", err)
+	}
+
+	item, err := s.contentRepo.GetContentItem(ctx, itemID)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return errors.NewNotFoundError("Content item not found", err)
+		}
+		s.logger.Errorf("Error retrieving content item: %v", err)
+		return errors.Wrap(err, "Failed to retrieve content item")
+	}
+
+	if item.UserID != userID {
+		s.logger.Warnf("User %s attempted to change link settings of item %s owned by %s", userIDStr, itemIDStr, item.UserID)
+		return errors.NewForbiddenError("You can only change your own content items", nil)
+	}
+
+	if err := s.linkHealthRepo.SetAutoDeactivate(ctx, itemID, enabled); err != nil {
+		s.logger.Errorf("Failed to update link auto-deactivation: %v", err)
+		return errors.Wrap(err, "Failed to update link auto-deactivation")
+	}
+
+	return nil
+}
@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+
+	"github.com/0xsj/mios.io/pkg/errors"
+	"github.com/google/uuid"
+)
+
+// Content item states. An item with no active flag set has never been
+// published or hidden and counts as a draft.
+const (
+	ContentStateActive   = "active"
+	ContentStateInactive = "inactive"
+	ContentStateDraft    = "draft"
+)
+
+// ContentStateCountsDTO counts items by state
+type ContentStateCountsDTO struct {
+	Total    int64 `json:"total"`
+	Active   int64 `json:"active"`
+	Inactive int64 `json:"inactive"`
+	Draft    int64 `json:"draft"`
+}
+
+func (c *ContentStateCountsDTO) add(state string, count int64) {
+	c.Total += count
+	switch state {
+	case ContentStateActive:
+		c.Active += count
+	case ContentStateInactive:
+		c.Inactive += count
+	case ContentStateDraft:
+		c.Draft += count
+	}
+}
+
+// ContentSummaryDTO counts a user's items overall and per content type, so
+// editors can show counts without loading every item
+type ContentSummaryDTO struct {
+	UserID string `json:"user_id"`
+	ContentStateCountsDTO
+	Types map[string]*ContentStateCountsDTO `json:"types"`
+
+	// ActiveLimit is how many active items the user's plan allows, or zero
+	// for no limit
+	ActiveLimit int `json:"active_limit"`
+}
+
+// GetContentSummary counts the user's items by type and state with a single
+// grouped query
+func (s *contentService) GetContentSummary(ctx context.Context, userIDStr string) (*ContentSummaryDTO, error) {
+	s.logger.Debugf("Getting content summary for user ID: %s", userIDStr)
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		s.logger.Warnf("Invalid user ID format: %v", err)
+		return nil, errors.NewBadRequestError("Invalid user ID format", err)
+	}
+
+	counts, err := s.contentRepo.CountUserContentItemsByTypeAndState(ctx, userID)
+	if err != nil {
+		s.logger.Errorf("Failed to count content items by type and state: %v", err)
+		return nil, errors.Wrap(err, "Failed to count content items")
+	}
+
+	summary := &ContentSummaryDTO{
+		UserID: userIDStr,
+		Types:  make(map[string]*ContentStateCountsDTO),
+	}
+	for _, count := range counts {
+		typeCounts, ok := summary.Types[count.ContentType]
+		if !ok {
+			typeCounts = &ContentStateCountsDTO{}
+			summary.Types[count.ContentType] = typeCounts
+		}
+		typeCounts.add(count.State, count.Count)
+		summary.add(count.State, count.Count)
+	}
+
+	if s.config.MaxActiveItemsFree > 0 || s.config.MaxActiveItemsPremium > 0 {
+		owner, err := s.userRepo.GetUser(ctx, userID)
+		if err != nil {
+			if errors.IsNotFound(err) {
+				return nil, errors.NewNotFoundError("User not found", err)
+			}
+			s.logger.Errorf("Error retrieving user: %v", err)
+			return nil, errors.Wrap(err, "Failed to retrieve user")
+		}
+		summary.ActiveLimit = s.activeItemLimit(owner)
+	}
+
+	s.logger.Debugf("Content summary for user ID: %s covers %d items of %d types",
+		userIDStr, summary.Total, len(summary.Types))
+	return summary, nil
+}
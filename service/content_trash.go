@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/0xsj/mios.io/pkg/errors"
+	"github.com/0xsj/mios.io/repository"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultTrashRetention = 30 * 24 * time.Hour
+	trashPurgeBatchSize   = 500
+)
+
+// ListDeletedContentItems returns the user's trash, most recently deleted
+// first
+func (s *contentService) ListDeletedContentItems(ctx context.Context, userIDStr string) ([]*ContentItemDTO, error) {
+	s.logger.Debugf("Listing deleted content items for user ID: %s", userIDStr)
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		s.logger.Warnf("Invalid user ID format: %v", err)
+		return nil, errors.NewBadRequestError("Invalid user ID format", err)
+	}
+
+	items, err := s.contentRepo.ListDeletedUserContentItems(ctx, userID)
+	if err != nil {
+		s.logger.Errorf("Failed to list deleted content items: %v", err)
+		return nil, errors.Wrap(err, "Failed to list deleted content items")
+	}
+
+	dtos := make([]*ContentItemDTO, 0, len(items))
+	for _, item := range items {
+		dtos = append(dtos, mapContentItemToDTO(item))
+	}
+	return dtos, nil
+}
+
+// RestoreContentItem takes one of the user's items back out of the trash.
+// The owner's limits are checked again, since they may have added items in
+// the meantime.
+func (s *contentService) RestoreContentItem(ctx context.Context, userIDStr, itemIDStr string) (*ContentItemDTO, error) {
+	s.logger.Infof("Restoring content item with ID: %s for user ID: %s", itemIDStr, userIDStr)
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		s.logger.Warnf("Invalid user ID format: %v", err)
+		return nil, errors.NewBadRequestError("Invalid user ID format", err)
+	}
+
+	itemID, err := uuid.Parse(itemIDStr)
+	if err != nil {
+		s.logger.Warnf("Invalid item ID format: %v", err)
+		return nil, errors.NewBadRequestError("Invalid item ID format", err)
+	}
+
+	item, err := s.contentRepo.GetDeletedContentItem(ctx, itemID)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			s.logger.Infof("Deleted content item not found with ID: %s", itemIDStr)
+			return nil, errors.NewNotFoundError("Deleted content item not found", err)
+		}
+		s.logger.Errorf("Error retrieving deleted content item: %v", err)
+		return nil, errors.Wrap(err, "Failed to retrieve content item")
+	}
+
+	if item.UserID != userID {
+		s.logger.Warnf("User %s attempted to restore item %s owned by %s", userIDStr, itemIDStr, item.UserID)
+		return nil, errors.NewForbiddenError("You can only restore your own content items", nil)
+	}
+
+	owner, err := s.userRepo.GetUser(ctx, item.UserID)
+	if err != nil {
+		s.logger.Errorf("Error retrieving content owner: %v", err)
+		return nil, errors.Wrap(err, "Failed to retrieve content owner")
+	}
+
+	if err := s.enforceTypeMax(ctx, owner.UserID, item.ContentType); err != nil {
+		return nil, err
+	}
+	if item.IsActive != nil && *item.IsActive {
+		if err := s.enforceActiveItemLimit(ctx, owner); err != nil {
+			return nil, err
+		}
+	}
+	if item.Pinned {
+		if err := s.enforcePinLimit(ctx, owner, itemID); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.contentRepo.RestoreContentItem(ctx, itemID); err != nil {
+		if errors.IsNotFound(err) {
+			return nil, err
+		}
+		s.logger.Errorf("Failed to restore content item: %v", err)
+		return nil, errors.Wrap(err, "Failed to restore content item")
+	}
+
+	s.recordHistory(ctx, repository.ContentHistoryEntry{
+		ItemID: itemID,
+		Source: HistorySourceUser,
+		Action: HistoryActionRestored,
+	})
+
+	restored, err := s.contentRepo.GetContentItem(ctx, itemID)
+	if err != nil {
+		s.logger.Errorf("Failed to retrieve restored content item: %v", err)
+		return nil, errors.Wrap(err, "Failed to retrieve restored content item")
+	}
+
+	s.logger.Infof("Content item restored successfully with ID: %s", itemIDStr)
+	return mapContentItemToDTO(restored), nil
+}
+
+// PurgeDeletedContentItems permanently removes items that have been in the
+// trash longer than the retention period, in batches, and returns how many
+// it removed
+func (s *contentService) PurgeDeletedContentItems(ctx context.Context) (int64, error) {
+	cutoff := time.Now().Add(-s.config.TrashRetention)
+
+	var total int64
+	for ctx.Err() == nil {
+		rows, err := s.contentRepo.PurgeDeletedContentItems(ctx, cutoff, trashPurgeBatchSize)
+		if err != nil {
+			s.logger.Errorf("Failed to purge deleted content items: %v", err)
+			return total, errors.Wrap(err, "Failed to purge deleted content items")
+		}
+		total += rows
+		if rows < trashPurgeBatchSize {
+			break
+		}
+	}
+
+	s.logger.Infof("Purged %d content items deleted before %s", total, cutoff.Format(time.RFC3339))
+	return total, nil
+}
+
+// StartTrashPurge runs PurgeDeletedContentItems every interval until ctx is
+// cancelled
+func (s *contentService) StartTrashPurge(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.PurgeDeletedContentItems(ctx); err != nil {
+			s.logger.Errorf("Content trash purge run failed: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,182 @@
+package service
+
+import (
+	"context"
+	"net"
+	"strings"
+	"time"
+
+	db "github.com/0xsj/mios.io/db/sqlc"
+	apperror "github.com/0xsj/mios.io/pkg/errors"
+	"github.com/0xsj/mios.io/pkg/token"
+)
+
+// TXTResolver looks up the TXT records published for a DNS name;
+// *net.Resolver satisfies it
+type TXTResolver interface {
+	LookupTXT(ctx context.Context, name string) ([]string, error)
+}
+
+const (
+	// domainVerificationLabel is prepended to a custom domain to name the
+	// TXT record proving ownership
+	domainVerificationLabel = "_mios-verify"
+	domainTokenPrefix       = "mios-verify="
+	domainTokenLength       = 32
+	domainLookupTimeout     = 5 * time.Second
+)
+
+// DomainVerificationDTO tells the user which TXT record to publish and
+// whether it has been found
+type DomainVerificationDTO struct {
+	Domain      string `json:"domain"`
+	RecordType  string `json:"record_type"`
+	RecordName  string `json:"record_name"`
+	RecordValue string `json:"record_value"`
+	Verified    bool   `json:"verified"`
+	VerifiedAt  string `json:"verified_at,omitempty"`
+}
+
+// InitiateDomainVerification sets the user's custom domain and issues the
+// token to publish for it. Asking again for the same domain returns the
+// existing token, so verification already done is kept.
+func (s *userService) InitiateDomainVerification(ctx context.Context, id string, domain string) (*DomainVerificationDTO, error) {
+	s.logger.Infof("Initiating custom domain verification for user ID: %s", id)
+
+	userID, err := parseUUID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	domain = normalizeCustomDomain(domain)
+	if !isValidCustomDomain(domain) {
+		return nil, handleValidationError("Invalid domain", nil)
+	}
+
+	user, err := s.userRepo.GetUser(ctx, userID)
+	if err != nil {
+		s.logger.Errorf("Failed to get user with ID %s: %v", id, err)
+		return nil, err
+	}
+	if user.CustomDomain != nil && *user.CustomDomain == domain && user.CustomDomainToken != nil {
+		return mapDomainVerification(user), nil
+	}
+
+	verificationToken, err := token.GenerateRandomAlphanumeric(domainTokenLength)
+	if err != nil {
+		return nil, apperror.NewInternalError("Failed to generate verification token", err)
+	}
+	verificationToken = domainTokenPrefix + verificationToken
+
+	if err := s.userRepo.SetCustomDomainVerification(ctx, userID, domain, verificationToken); err != nil {
+		s.logger.Errorf("Failed to start verification of %s for user ID %s: %v", domain, id, err)
+		return nil, err
+	}
+
+	user.CustomDomain = &domain
+	user.CustomDomainToken = &verificationToken
+	user.CustomDomainVerifiedAt = nil
+	return mapDomainVerification(user), nil
+}
+
+// CheckDomainVerification looks for the user's TXT record and marks the
+// domain verified once it is published. Lookup failures leave the domain
+// unverified rather than failing, since DNS changes take time to appear.
+func (s *userService) CheckDomainVerification(ctx context.Context, id string) (*DomainVerificationDTO, error) {
+	s.logger.Debugf("Checking custom domain verification for user ID: %s", id)
+
+	userID, err := parseUUID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.GetUser(ctx, userID)
+	if err != nil {
+		s.logger.Errorf("Failed to get user with ID %s: %v", id, err)
+		return nil, err
+	}
+	if user.CustomDomain == nil || user.CustomDomainToken == nil {
+		return nil, apperror.NewNotFoundError("No custom domain verification has been started", nil)
+	}
+	if user.CustomDomainVerifiedAt != nil {
+		return mapDomainVerification(user), nil
+	}
+
+	domain, verificationToken := *user.CustomDomain, *user.CustomDomainToken
+	if !s.hasVerificationRecord(ctx, domain, verificationToken) {
+		return mapDomainVerification(user), nil
+	}
+
+	if err := s.userRepo.MarkCustomDomainVerified(ctx, userID, domain, verificationToken); err != nil {
+		s.logger.Errorf("Failed to mark %s verified for user ID %s: %v", domain, id, err)
+		return nil, err
+	}
+
+	now := time.Now()
+	user.CustomDomainVerifiedAt = &now
+	s.logger.Infof("Custom domain %s verified for user ID %s", domain, id)
+	return mapDomainVerification(user), nil
+}
+
+func (s *userService) hasVerificationRecord(ctx context.Context, domain, verificationToken string) bool {
+	resolver := s.config.DomainResolver
+	if resolver == nil {
+		resolver = net.DefaultResolver
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, domainLookupTimeout)
+	defer cancel()
+
+	records, err := resolver.LookupTXT(ctx, domainVerificationLabel+"."+domain)
+	if err != nil {
+		s.logger.Debugf("TXT lookup for %s failed: %v", domain, err)
+		return false
+	}
+	for _, record := range records {
+		if strings.TrimSpace(record) == verificationToken {
+			return true
+		}
+	}
+	return false
+}
+
+func mapDomainVerification(user *db.User) *DomainVerificationDTO {
+	dto := &DomainVerificationDTO{
+		Domain:      *user.CustomDomain,
+		RecordType:  "TXT",
+		RecordName:  domainVerificationLabel + "." + *user.CustomDomain,
+		RecordValue: *user.CustomDomainToken,
+		Verified:    user.CustomDomainVerifiedAt != nil,
+	}
+	if user.CustomDomainVerifiedAt != nil {
+		dto.VerifiedAt = user.CustomDomainVerifiedAt.Format(time.RFC3339)
+	}
+	return dto
+}
+
+func normalizeCustomDomain(domain string) string {
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(domain)), ".")
+}
+
+// isValidCustomDomain accepts host names of at least two labels whose top
+// level label isn't numeric, which rules out IP addresses
+func isValidCustomDomain(domain string) bool {
+	if len(domain) > 253 {
+		return false
+	}
+	labels := strings.Split(domain, ".")
+	if len(labels) < 2 {
+		return false
+	}
+	for _, label := range labels {
+		if len(label) == 0 || len(label) > 63 || label[0] == '-' || label[len(label)-1] == '-' {
+			return false
+		}
+		for _, r := range label {
+			if (r < 'a' || r > 'z') && (r < '0' || r > '9') && r != '-' {
+				return false
+			}
+		}
+	}
+	return strings.Trim(labels[len(labels)-1], "0123456789") != ""
+}
@@ -0,0 +1,64 @@
+package service
+
+import (
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/0xsj/mios.io/pkg/errors"
+)
+
+// FieldLimits caps the length, in characters, of free-text fields stored on
+// users and content items. Zero leaves a field unbounded.
+type FieldLimits struct {
+	Title int
+	Href  int
+	URL   int
+	Bio   int
+	Name  int
+}
+
+// lengthCheck pairs a field's value with its limit. A nil value means the
+// field was not provided and is skipped.
+type lengthCheck struct {
+	field string
+	value *string
+	max   int
+}
+
+// validateFieldLengths reports every field longer than its limit in one
+// validation error, so clients can flag all offending inputs at once
+func validateFieldLengths(checks ...lengthCheck) error {
+	var fields []errors.FieldError
+	for _, check := range checks {
+		if check.value == nil || check.max <= 0 {
+			continue
+		}
+		if utf8.RuneCountInString(*check.value) > check.max {
+			fields = append(fields, errors.FieldError{
+				Field:   check.field,
+				Message: fmt.Sprintf("must be at most %d characters", check.max),
+			})
+		}
+	}
+
+	if len(fields) == 0 {
+		return nil
+	}
+	return errors.NewFieldValidationError("One or more fields are too long", fields)
+}
+
+func (l FieldLimits) contentChecks(title, href, mediaURL *string) []lengthCheck {
+	return []lengthCheck{
+		{"title", title, l.Title},
+		{"href", href, l.Href},
+		{"url", mediaURL, l.URL},
+	}
+}
+
+func (l FieldLimits) profileChecks(firstName, lastName, bio *string) []lengthCheck {
+	return []lengthCheck{
+		{"first_name", firstName, l.Name},
+		{"last_name", lastName, l.Name},
+		{"bio", bio, l.Bio},
+	}
+}
@@ -0,0 +1,113 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/0xsj/mios.io/pkg/errors"
+	"github.com/0xsj/mios.io/pkg/storage"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultFilePageSize = 20
+	maxFilePageSize     = 100
+)
+
+// fileCategories are the categories files can be listed by
+var fileCategories = map[string]bool{
+	"avatar":  true,
+	"content": true,
+	"general": true,
+}
+
+type FileDTO struct {
+	Key         string    `json:"key"`
+	URL         string    `json:"url"`
+	Category    string    `json:"category"`
+	Size        int64     `json:"size"`
+	ContentType string    `json:"content_type"`
+	UploadedAt  time.Time `json:"uploaded_at"`
+}
+
+// FileListDTO is one page of a user's files, most recent first
+type FileListDTO struct {
+	Files      []*FileDTO
+	Page       int
+	PageSize   int
+	TotalCount int64
+	TotalPages int
+}
+
+// ListUserFiles returns a page of the files a user uploaded. An empty
+// category lists files of every category.
+func (s *fileService) ListUserFiles(ctx context.Context, userIDStr string, category string, page, pageSize int) (*FileListDTO, error) {
+	s.logger.Debugf("Listing files for user ID: %s (category %q, page %d)", userIDStr, category, page)
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		s.logger.Warnf("Invalid user ID format: %v", err)
+		return nil, errors.NewBadRequestError("Invalid user ID format", err)
+	}
+
+	if category != "" && !fileCategories[category] {
+		return nil, errors.NewValidationError(fmt.Sprintf("Unknown file category %q", category), nil)
+	}
+
+	if page < 1 {
+		page = 1
+	}
+	if pageSize <= 0 {
+		pageSize = defaultFilePageSize
+	}
+	if pageSize > maxFilePageSize {
+		pageSize = maxFilePageSize
+	}
+
+	total, err := s.fileRepo.CountUserFiles(ctx, userID, category)
+	if err != nil {
+		s.logger.Errorf("Failed to count files: %v", err)
+		return nil, errors.Wrap(err, "Failed to retrieve files")
+	}
+
+	files, err := s.fileRepo.ListUserFiles(ctx, userID, category, pageSize, (page-1)*pageSize)
+	if err != nil {
+		s.logger.Errorf("Failed to list files: %v", err)
+		return nil, errors.Wrap(err, "Failed to retrieve files")
+	}
+
+	opts := storage.GetURLOptions{
+		CDNDomain:  s.config.CDNDomain,
+		DisableCDN: s.useOrigin(),
+	}
+
+	dtos := make([]*FileDTO, len(files))
+	for i, file := range files {
+		url, err := s.storage.GetURL(ctx, file.FileKey, opts)
+		if err != nil {
+			s.logger.Errorf("Failed to get URL for file %s: %v", file.FileKey, err)
+			return nil, errors.Wrap(err, "Failed to get file URL")
+		}
+
+		dtos[i] = &FileDTO{
+			Key:         file.FileKey,
+			URL:         url,
+			Category:    file.Category,
+			Size:        file.Size,
+			ContentType: file.ContentType,
+		}
+		if file.CreatedAt != nil {
+			dtos[i].UploadedAt = *file.CreatedAt
+		}
+	}
+
+	return &FileListDTO{
+		Files:      dtos,
+		Page:       page,
+		PageSize:   pageSize,
+		TotalCount: total,
+		TotalPages: int(math.Ceil(float64(total) / float64(pageSize))),
+	}, nil
+}
@@ -0,0 +1,167 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	db "github.com/0xsj/mios.io/db/sqlc"
+	"github.com/0xsj/mios.io/pkg/errors"
+	"github.com/0xsj/mios.io/repository"
+	"github.com/google/uuid"
+)
+
+// publicFileCategories are served to anyone; URLs of files in every other
+// category are only handed to their owner and admins
+var publicFileCategories = map[string]bool{
+	"avatar":  true,
+	"content": true,
+}
+
+// PublicFileCategories lists the categories whose files anyone may fetch
+// without a signed URL
+func PublicFileCategories() []string {
+	categories := make([]string, 0, len(publicFileCategories))
+	for category := range publicFileCategories {
+		categories = append(categories, category)
+	}
+	sort.Strings(categories)
+	return categories
+}
+
+// recordFile stores who owns an uploaded file
+func (s *fileService) recordFile(ctx context.Context, key, userID, category, contentType string, size int64) error {
+	ownerID, err := uuid.Parse(userID)
+	if err != nil {
+		return errors.NewBadRequestError("Invalid user ID format", err)
+	}
+
+	_, err = s.fileRepo.CreateFile(ctx, repository.CreateFileParams{
+		Key:         key,
+		UserID:      ownerID,
+		Category:    category,
+		Size:        size,
+		ContentType: contentType,
+	})
+	if err != nil {
+		s.logger.Errorf("Failed to record file %s: %v", key, err)
+		return errors.Wrap(err, "Failed to record file")
+	}
+	return nil
+}
+
+// fileRecord returns the record of a file. Files uploaded before ownership
+// was recorded get their record on first access, with the owner and
+// category read from the key generateFileKey gave them.
+func (s *fileService) fileRecord(ctx context.Context, key string) (*db.File, error) {
+	file, err := s.fileRepo.GetFile(ctx, key)
+	if err == nil {
+		return file, nil
+	}
+	if !errors.IsNotFound(err) {
+		return nil, errors.Wrap(err, "Failed to retrieve file")
+	}
+
+	category, ownerID, ok := parseFileKey(key)
+	if !ok {
+		return nil, errors.NewNotFoundError("File not found", err)
+	}
+
+	info, statErr := s.storage.Stat(ctx, key)
+	if statErr != nil {
+		if errors.IsNotFound(statErr) {
+			return nil, errors.NewNotFoundError("File not found", err)
+		}
+		s.logger.Errorf("Failed to stat file %s: %v", key, statErr)
+		return nil, errors.Wrap(statErr, "Failed to retrieve file")
+	}
+
+	file, err = s.fileRepo.CreateFile(ctx, repository.CreateFileParams{
+		Key:         key,
+		UserID:      ownerID,
+		Category:    category,
+		Size:        info.Size,
+		ContentType: info.ContentType,
+	})
+	if err != nil {
+		// Another request may have backfilled it first
+		if existing, getErr := s.fileRepo.GetFile(ctx, key); getErr == nil {
+			return existing, nil
+		}
+		s.logger.Errorf("Failed to backfill record for file %s: %v", key, err)
+		return nil, errors.Wrap(err, "Failed to record file")
+	}
+
+	s.logger.Infof("Backfilled record for file %s owned by %s", key, ownerID)
+	return file, nil
+}
+
+// parseFileKey reads the category and owner out of a key built by
+// generateFileKey. Keys that could point outside the owner's directory
+// are rejected.
+func parseFileKey(key string) (string, uuid.UUID, bool) {
+	parts := strings.SplitN(key, "/", 3)
+	if len(parts) != 3 || !fileCategories[parts[0]] || strings.Contains(parts[2], "..") {
+		return "", uuid.Nil, false
+	}
+
+	ownerID, err := uuid.Parse(parts[1])
+	if err != nil {
+		return "", uuid.Nil, false
+	}
+	return parts[0], ownerID, true
+}
+
+// removeFile deletes a file and its record without checking ownership, to
+// clean up after uploads that failed part way
+func (s *fileService) removeFile(ctx context.Context, key string) {
+	if err := s.storage.Delete(ctx, key); err != nil {
+		s.logger.Warnf("Failed to clean up file %s: %v", key, err)
+	}
+	if err := s.fileRepo.DeleteFile(ctx, key); err != nil {
+		s.logger.Warnf("Failed to clean up file record %s: %v", key, err)
+	}
+}
+
+// ownedFile returns the record of a file if actorID owns it or is an admin,
+// and a forbidden error otherwise
+func (s *fileService) ownedFile(ctx context.Context, actorID, key string) (*db.File, error) {
+	file, err := s.fileRecord(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.authorizeFile(ctx, actorID, file); err != nil {
+		return nil, err
+	}
+	return file, nil
+}
+
+func (s *fileService) authorizeFile(ctx context.Context, actorID string, file *db.File) error {
+	if actorID == "" {
+		return errors.NewUnauthorizedError("Authentication required to access this file", nil)
+	}
+
+	userID, err := uuid.Parse(actorID)
+	if err != nil {
+		return errors.NewBadRequestError("Invalid user ID format", err)
+	}
+	if userID == file.UserID {
+		return nil
+	}
+
+	user, err := s.userRepo.GetUser(ctx, userID)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return errors.NewUnauthorizedError("User not found", err)
+		}
+		s.logger.Errorf("Error retrieving user: %v", err)
+		return errors.Wrap(err, "Failed to retrieve user")
+	}
+	if user.IsAdmin != nil && *user.IsAdmin {
+		return nil
+	}
+
+	s.logger.Warnf("User %s is not allowed to access file %s", actorID, file.FileKey)
+	return errors.NewForbiddenError("You are not allowed to access this file", nil)
+}
@@ -2,6 +2,7 @@
 package service
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"io"
@@ -12,6 +13,7 @@ import (
 	"github.com/0xsj/mios.io/log"
 	"github.com/0xsj/mios.io/pkg/errors"
 	"github.com/0xsj/mios.io/pkg/storage"
+	"github.com/0xsj/mios.io/repository"
 	"github.com/google/uuid"
 )
 
@@ -20,14 +22,26 @@ type FileService interface {
 	UploadUserAvatar(ctx context.Context, userID string, input UploadFileInput) (*FileUploadResult, error)
 	UploadContentMedia(ctx context.Context, userID string, input UploadFileInput) (*FileUploadResult, error)
 	GetPresignedUploadURL(ctx context.Context, input PresignedUploadInput) (*PresignedUploadResult, error)
-	DeleteFile(ctx context.Context, key string) error
-	GetFileURL(ctx context.Context, key string, expires time.Duration) (string, error)
+	// CompleteUpload records the size of a file the client uploaded to a
+	// presigned URL, once the upload has finished
+	CompleteUpload(ctx context.Context, userID, key string) (*FileDTO, error)
+	// DeleteFile and GetFileURL act on behalf of userID, who must own the
+	// file or be an admin. GetFileURL allows anyone for public categories.
+	DeleteFile(ctx context.Context, userID, key string) error
+	GetFileURL(ctx context.Context, userID, key string, expires time.Duration) (string, error)
+	ListUserFiles(ctx context.Context, userID string, category string, page, pageSize int) (*FileListDTO, error)
+	// GetPresignedDownloadURL returns a time-limited URL for a file, which
+	// may be private. expires must be between one minute and seven days.
+	GetPresignedDownloadURL(ctx context.Context, userID, key string, expires time.Duration) (string, error)
 }
 
 type fileService struct {
-	storage storage.Storage
-	logger  log.Logger
-	config  FileServiceConfig
+	storage    storage.Storage
+	cdnMonitor *storage.CDNMonitor
+	fileRepo   repository.FileRepository
+	userRepo   repository.UserRepository
+	logger     log.Logger
+	config     FileServiceConfig
 }
 
 type FileServiceConfig struct {
@@ -37,6 +51,7 @@ type FileServiceConfig struct {
 	AllowedVideoTypes []string
 	AllowedFileTypes  []string
 	CDNDomain         string
+	CDNFallback       bool // Hand out origin URLs while the CDN monitor reports the CDN down
 }
 
 type UploadFileInput struct {
@@ -54,6 +69,11 @@ type FileUploadResult struct {
 	ContentType string    `json:"content_type"`
 	Filename    string    `json:"filename"`
 	UploadedAt  time.Time `json:"uploaded_at"`
+
+	// Resized copies of avatar uploads by size in pixels, e.g. "128"
+	Variants map[string]string `json:"variants,omitempty"`
+
+	variantKeys []string
 }
 
 type PresignedUploadInput struct {
@@ -70,11 +90,22 @@ type PresignedUploadResult struct {
 	ExpiresAt time.Time         `json:"expires_at"`
 }
 
-func NewFileService(storage storage.Storage, config FileServiceConfig, logger log.Logger) FileService {
+// Bounds for the lifetime of presigned download URLs
+const (
+	MinDownloadURLExpiry = time.Minute
+	MaxDownloadURLExpiry = 7 * 24 * time.Hour
+)
+
+// NewFileService creates a file service. cdnMonitor may be nil when no CDN
+// is configured or its health is not checked.
+func NewFileService(storage storage.Storage, cdnMonitor *storage.CDNMonitor, fileRepo repository.FileRepository, userRepo repository.UserRepository, config FileServiceConfig, logger log.Logger) FileService {
 	return &fileService{
-		storage: storage,
-		logger:  logger,
-		config:  config,
+		storage:    storage,
+		cdnMonitor: cdnMonitor,
+		fileRepo:   fileRepo,
+		userRepo:   userRepo,
+		logger:     logger,
+		config:     config,
 	}
 }
 
@@ -84,6 +115,18 @@ func (s *fileService) UploadFile(ctx context.Context, input UploadFileInput) (*F
 		return nil, err
 	}
 
+	// The claimed type comes from the client, so check it against the content
+	detected, file, err := sniffContentType(input.File)
+	if err != nil {
+		s.logger.Errorf("Failed to read upload: %v", err)
+		return nil, errors.Wrap(err, "Failed to read file")
+	}
+	if !contentTypeMatches(input.ContentType, detected) {
+		s.logger.Warnf("Rejected upload %q claiming %s, content detected as %s", input.Filename, input.ContentType, detected.String())
+		return nil, errors.NewValidationError(fmt.Sprintf("File content does not match its type %s", input.ContentType), nil)
+	}
+	input.File = file
+
 	// Generate unique key
 	key := s.generateFileKey(input.UserID, input.Category, input.Filename)
 
@@ -98,6 +141,7 @@ func (s *fileService) UploadFile(ctx context.Context, input UploadFileInput) (*F
 		ContentType: input.ContentType,
 		MaxSize:     maxSize,
 		ACL:         "public-read",
+		DisableCDN:  s.useOrigin(),
 		Metadata: map[string]string{
 			"user-id":     input.UserID,
 			"category":    input.Category,
@@ -112,6 +156,13 @@ func (s *fileService) UploadFile(ctx context.Context, input UploadFileInput) (*F
 		return nil, errors.Wrap(err, "Failed to upload file")
 	}
 
+	if err := s.recordFile(ctx, result.Key, input.UserID, input.Category, result.ContentType, result.Size); err != nil {
+		if delErr := s.storage.Delete(ctx, result.Key); delErr != nil {
+			s.logger.Warnf("Failed to clean up unrecorded file %s: %v", result.Key, delErr)
+		}
+		return nil, err
+	}
+
 	s.logger.Infof("File uploaded successfully: %s", key)
 
 	return &FileUploadResult{
@@ -133,7 +184,34 @@ func (s *fileService) UploadUserAvatar(ctx context.Context, userID string, input
 		return nil, errors.NewValidationError("Avatar must be an image", nil)
 	}
 
-	return s.UploadFile(ctx, input)
+	// The upload is read once: stored as is and decoded for the variants
+	data, err := s.readAvatar(input.File)
+	if err != nil {
+		return nil, err
+	}
+
+	avatar, err := decodeAvatar(data)
+	if err != nil {
+		s.logger.Warnf("Rejected avatar upload for user %s: %v", userID, err)
+		return nil, err
+	}
+
+	input.File = bytes.NewReader(data)
+	result, err := s.UploadFile(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	variants, keys, err := s.uploadAvatarVariants(ctx, result.Key, avatar, input)
+	if err != nil {
+		s.logger.Errorf("Failed to create avatar variants for %s: %v", result.Key, err)
+		s.removeFile(ctx, result.Key)
+		return nil, errors.Wrap(err, "Failed to create avatar variants")
+	}
+
+	result.Variants = variants
+	result.variantKeys = keys
+	return result, nil
 }
 
 func (s *fileService) UploadContentMedia(ctx context.Context, userID string, input UploadFileInput) (*FileUploadResult, error) {
@@ -172,6 +250,12 @@ func (s *fileService) GetPresignedUploadURL(ctx context.Context, input Presigned
 		return nil, errors.Wrap(err, "Failed to generate upload URL")
 	}
 
+	// The client uploads directly to storage, so the size isn't known until
+	// it calls CompleteUpload
+	if err := s.recordFile(ctx, result.Key, input.UserID, input.Category, input.ContentType, 0); err != nil {
+		return nil, err
+	}
+
 	return &PresignedUploadResult{
 		UploadURL: result.UploadURL,
 		Key:       result.Key,
@@ -180,21 +264,85 @@ func (s *fileService) GetPresignedUploadURL(ctx context.Context, input Presigned
 	}, nil
 }
 
-func (s *fileService) DeleteFile(ctx context.Context, key string) error {
+func (s *fileService) CompleteUpload(ctx context.Context, userID, key string) (*FileDTO, error) {
+	file, err := s.ownedFile(ctx, userID, key)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := s.storage.Stat(ctx, key)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, errors.NewNotFoundError("File has not been uploaded yet", err)
+		}
+		s.logger.Errorf("Failed to stat uploaded file %s: %v", key, err)
+		return nil, errors.Wrap(err, "Failed to retrieve file")
+	}
+
+	if err := s.fileRepo.UpdateFileSize(ctx, key, info.Size); err != nil {
+		s.logger.Errorf("Failed to update size of file %s: %v", key, err)
+		return nil, errors.Wrap(err, "Failed to record file")
+	}
+
+	url, err := s.storage.GetURL(ctx, key, storage.GetURLOptions{
+		CDNDomain:  s.config.CDNDomain,
+		DisableCDN: s.useOrigin(),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to get file URL")
+	}
+
+	dto := &FileDTO{
+		Key:         key,
+		URL:         url,
+		Category:    file.Category,
+		Size:        info.Size,
+		ContentType: file.ContentType,
+	}
+	if file.CreatedAt != nil {
+		dto.UploadedAt = *file.CreatedAt
+	}
+
+	s.logger.Infof("Upload of %s completed, %d bytes", key, info.Size)
+	return dto, nil
+}
+
+func (s *fileService) DeleteFile(ctx context.Context, userID, key string) error {
+	if _, err := s.ownedFile(ctx, userID, key); err != nil {
+		return err
+	}
+
 	err := s.storage.Delete(ctx, key)
 	if err != nil {
 		s.logger.Errorf("Failed to delete file: %v", err)
 		return errors.Wrap(err, "Failed to delete file")
 	}
 
+	if err := s.fileRepo.DeleteFile(ctx, key); err != nil {
+		s.logger.Errorf("Failed to delete file record %s: %v", key, err)
+		return errors.Wrap(err, "Failed to delete file record")
+	}
+
 	s.logger.Infof("File deleted successfully: %s", key)
 	return nil
 }
 
-func (s *fileService) GetFileURL(ctx context.Context, key string, expires time.Duration) (string, error) {
+func (s *fileService) GetFileURL(ctx context.Context, userID, key string, expires time.Duration) (string, error) {
+	file, err := s.fileRecord(ctx, key)
+	if err != nil {
+		return "", err
+	}
+
+	if !publicFileCategories[file.Category] {
+		if err := s.authorizeFile(ctx, userID, file); err != nil {
+			return "", err
+		}
+	}
+
 	opts := storage.GetURLOptions{
-		Expires:   expires,
-		CDNDomain: s.config.CDNDomain,
+		Expires:    expires,
+		CDNDomain:  s.config.CDNDomain,
+		DisableCDN: s.useOrigin(),
 	}
 
 	url, err := s.storage.GetURL(ctx, key, opts)
@@ -205,8 +353,64 @@ func (s *fileService) GetFileURL(ctx context.Context, key string, expires time.D
 	return url, nil
 }
 
+func (s *fileService) GetPresignedDownloadURL(ctx context.Context, userID, key string, expires time.Duration) (string, error) {
+	if expires < MinDownloadURLExpiry || expires > MaxDownloadURLExpiry {
+		return "", errors.NewValidationError(
+			fmt.Sprintf("Download URLs must expire between %v and %v", MinDownloadURLExpiry, MaxDownloadURLExpiry), nil)
+	}
+
+	file, err := s.fileRecord(ctx, key)
+	if err != nil {
+		return "", err
+	}
+
+	if !publicFileCategories[file.Category] {
+		if err := s.authorizeFile(ctx, userID, file); err != nil {
+			return "", err
+		}
+	}
+
+	url, err := s.storage.GetPresignedDownloadURL(ctx, key, expires)
+	if err != nil {
+		s.logger.Errorf("Failed to generate download URL for %s: %v", key, err)
+		return "", errors.Wrap(err, "Failed to generate download URL")
+	}
+
+	return url, nil
+}
+
 // Helper methods
 
+// readAvatar reads an avatar upload into memory, enforcing MaxAvatarSize
+func (s *fileService) readAvatar(file io.Reader) ([]byte, error) {
+	reader := file
+	if s.config.MaxAvatarSize > 0 {
+		reader = io.LimitReader(file, s.config.MaxAvatarSize+1)
+	}
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		s.logger.Errorf("Failed to read avatar upload: %v", err)
+		return nil, errors.Wrap(err, "Failed to read avatar")
+	}
+
+	if s.config.MaxAvatarSize > 0 && int64(len(data)) > s.config.MaxAvatarSize {
+		return nil, errors.NewValidationError(
+			fmt.Sprintf("Avatar exceeds the maximum size of %d bytes", s.config.MaxAvatarSize), nil)
+	}
+	return data, nil
+}
+
+// useOrigin reports whether URLs should bypass the CDN because it is down and
+// falling back to direct storage URLs is enabled
+func (s *fileService) useOrigin() bool {
+	if !s.config.CDNFallback || s.cdnMonitor.Available() {
+		return false
+	}
+	s.logger.Debug("CDN is unavailable, using origin storage URL")
+	return true
+}
+
 func (s *fileService) generateFileKey(userID, category, filename string) string {
 	ext := filepath.Ext(filename)
 	uniqueID := uuid.New().String()
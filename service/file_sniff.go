@@ -0,0 +1,55 @@
+package service
+
+import (
+	"bytes"
+	"io"
+	"mime"
+	"strings"
+
+	"github.com/gabriel-vasile/mimetype"
+)
+
+// sniffLen is how much of an upload is inspected to detect its type; it
+// matches the detector's default read limit
+const sniffLen = 3072
+
+// claimedTypeAliases maps nonstandard content types clients send to the
+// name the detector uses
+var claimedTypeAliases = map[string]string{
+	"image/jpg": "image/jpeg",
+	"video/mov": "video/quicktime",
+}
+
+// sniffContentType detects an upload's type from its first bytes. The
+// returned reader yields the whole upload again, sniffed bytes included.
+func sniffContentType(r io.Reader) (*mimetype.MIME, io.Reader, error) {
+	head := make([]byte, sniffLen)
+	n, err := io.ReadFull(r, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return nil, nil, err
+	}
+	head = head[:n]
+
+	return mimetype.Detect(head), io.MultiReader(bytes.NewReader(head), r), nil
+}
+
+// contentTypeMatches reports whether content detected as detected may be
+// stored under the claimed type. A more specific detected type satisfies a
+// broader claim, so JSON passes as text/plain, but not the other way round.
+func contentTypeMatches(claimed string, detected *mimetype.MIME) bool {
+	mediaType, _, err := mime.ParseMediaType(claimed)
+	if err != nil {
+		return false
+	}
+	mediaType = strings.ToLower(mediaType)
+	if alias, ok := claimedTypeAliases[mediaType]; ok {
+		mediaType = alias
+	}
+
+	for m := detected; m != nil; m = m.Parent() {
+		if m.Is(mediaType) {
+			return true
+		}
+	}
+	return false
+}
@@ -3,8 +3,11 @@ package service
 
 import (
 	"context"
+	"io"
+	"time"
 
 	"github.com/0xsj/mios.io/log"
+	"github.com/0xsj/mios.io/pkg/live"
 	"github.com/0xsj/mios.io/pkg/metrics"
 )
 
@@ -34,6 +37,48 @@ func (s *InstrumentedAnalyticsService) RecordClick(ctx context.Context, input Re
 	return err
 }
 
+func (s *InstrumentedAnalyticsService) RecordInteraction(ctx context.Context, input RecordClickInput) error {
+	err := s.base.RecordInteraction(ctx, input)
+	// Only known types become label values, so callers can't add series
+	eventType := input.InteractionType
+	if eventType == "" {
+		eventType = InteractionClick
+	}
+	if !validInteractionTypes[eventType] {
+		eventType = "invalid"
+	}
+	s.metrics.RecordAnalyticsEvent(eventType)
+	
+	if err != nil {
+		s.metrics.RecordError("analytics_record_failure", "analytics_service", "error")
+	}
+	
+	return err
+}
+
+func (s *InstrumentedAnalyticsService) RecordClicksBatch(ctx context.Context, inputs []RecordClickInput) (*BatchResult, error) {
+	result, err := s.base.RecordClicksBatch(ctx, inputs)
+
+	if err != nil {
+		s.metrics.RecordError("analytics_record_failure", "analytics_service", "error")
+		return nil, err
+	}
+
+	for _, entry := range result.Results {
+		if !entry.Success {
+			s.metrics.RecordError("analytics_batch_entry_failure", "analytics_service", "warning")
+			continue
+		}
+		eventType := inputs[entry.Index].InteractionType
+		if eventType == "" {
+			eventType = InteractionClick
+		}
+		s.metrics.RecordAnalyticsEvent(eventType)
+	}
+
+	return result, nil
+}
+
 func (s *InstrumentedAnalyticsService) RecordPageView(ctx context.Context, input RecordPageViewInput) error {
 	err := s.base.RecordPageView(ctx, input)
 	s.metrics.RecordAnalyticsEvent("page_view")
@@ -45,6 +90,16 @@ func (s *InstrumentedAnalyticsService) RecordPageView(ctx context.Context, input
 	return err
 }
 
+func (s *InstrumentedAnalyticsService) SubscribeLiveEvents(ctx context.Context, userID string) (live.Subscription, error) {
+	sub, err := s.base.SubscribeLiveEvents(ctx, userID)
+
+	if err != nil {
+		s.metrics.RecordError("analytics_live_subscribe_failure", "analytics_service", "warning")
+	}
+
+	return sub, err
+}
+
 func (s *InstrumentedAnalyticsService) GetContentItemAnalytics(ctx context.Context, itemID string, page, pageSize int) (*ContentItemAnalyticsDTO, error) {
 	result, err := s.base.GetContentItemAnalytics(ctx, itemID, page, pageSize)
 	
@@ -75,6 +130,16 @@ func (s *InstrumentedAnalyticsService) GetUserAnalyticsByTimeRange(ctx context.C
 	return result, err
 }
 
+func (s *InstrumentedAnalyticsService) GetAnalyticsForDates(ctx context.Context, userID string, dates []string) (map[string]int64, error) {
+	result, err := s.base.GetAnalyticsForDates(ctx, userID, dates)
+
+	if err != nil {
+		s.metrics.RecordError("analytics_fetch_failure", "analytics_service", "warning")
+	}
+
+	return result, err
+}
+
 func (s *InstrumentedAnalyticsService) GetItemAnalyticsByTimeRange(ctx context.Context, itemID string, input TimeRangeInput) (*ItemTimeRangeAnalyticsDTO, error) {
 	result, err := s.base.GetItemAnalyticsByTimeRange(ctx, itemID, input)
 	
@@ -95,8 +160,8 @@ func (s *InstrumentedAnalyticsService) GetProfilePageViewsByTimeRange(ctx contex
 	return result, err
 }
 
-func (s *InstrumentedAnalyticsService) GetProfileDashboard(ctx context.Context, userID string, days int) (*ProfileDashboardDTO, error) {
-	result, err := s.base.GetProfileDashboard(ctx, userID, days)
+func (s *InstrumentedAnalyticsService) GetProfileDashboard(ctx context.Context, userID string, days int, includeBots bool) (*ProfileDashboardDTO, error) {
+	result, err := s.base.GetProfileDashboard(ctx, userID, days, includeBots)
 	
 	if err != nil {
 		s.metrics.RecordError("analytics_fetch_failure", "analytics_service", "warning")
@@ -105,6 +170,16 @@ func (s *InstrumentedAnalyticsService) GetProfileDashboard(ctx context.Context,
 	return result, err
 }
 
+func (s *InstrumentedAnalyticsService) GetSummaryCards(ctx context.Context, userID string, days int, includeBots bool) (*SummaryCardsDTO, error) {
+	result, err := s.base.GetSummaryCards(ctx, userID, days, includeBots)
+
+	if err != nil {
+		s.metrics.RecordError("analytics_fetch_failure", "analytics_service", "warning")
+	}
+
+	return result, err
+}
+
 func (s *InstrumentedAnalyticsService) GetReferrerAnalytics(ctx context.Context, userID string, input TimeRangeInput) (*ReferrerAnalyticsDTO, error) {
 	result, err := s.base.GetReferrerAnalytics(ctx, userID, input)
 	
@@ -115,3 +190,84 @@ func (s *InstrumentedAnalyticsService) GetReferrerAnalytics(ctx context.Context,
 	return result, err
 }
 
+func (s *InstrumentedAnalyticsService) GetDeviceBreakdown(ctx context.Context, userID string, input TimeRangeInput) (*DeviceBreakdownDTO, error) {
+	result, err := s.base.GetDeviceBreakdown(ctx, userID, input)
+
+	if err != nil {
+		s.metrics.RecordError("analytics_fetch_failure", "analytics_service", "warning")
+	}
+
+	return result, err
+}
+
+func (s *InstrumentedAnalyticsService) GetGeoAnalytics(ctx context.Context, userID string, input TimeRangeInput) (*GeoAnalyticsDTO, error) {
+	result, err := s.base.GetGeoAnalytics(ctx, userID, input)
+
+	if err != nil {
+		s.metrics.RecordError("analytics_fetch_failure", "analytics_service", "warning")
+	}
+
+	return result, err
+}
+
+func (s *InstrumentedAnalyticsService) GetCampaignAnalytics(ctx context.Context, userID string, input TimeRangeInput) (*CampaignAnalyticsDTO, error) {
+	result, err := s.base.GetCampaignAnalytics(ctx, userID, input)
+
+	if err != nil {
+		s.metrics.RecordError("analytics_fetch_failure", "analytics_service", "warning")
+	}
+
+	return result, err
+}
+
+func (s *InstrumentedAnalyticsService) RebuildRollups(ctx context.Context, userID string, start, end time.Time) error {
+	err := s.base.RebuildRollups(ctx, userID, start, end)
+
+	if err != nil {
+		s.metrics.RecordError("analytics_rollup_failure", "analytics_service", "error")
+	}
+
+	return err
+}
+
+func (s *InstrumentedAnalyticsService) StartRollupRebuild(userID string, start, end time.Time) (*RollupJobDTO, error) {
+	return s.base.StartRollupRebuild(userID, start, end)
+}
+
+func (s *InstrumentedAnalyticsService) GetRollupJob(jobID string) (*RollupJobDTO, error) {
+	return s.base.GetRollupJob(jobID)
+}
+
+func (s *InstrumentedAnalyticsService) StartRollupRefresh(ctx context.Context, interval time.Duration) {
+	s.base.StartRollupRefresh(ctx, interval)
+}
+
+func (s *InstrumentedAnalyticsService) GetRecentClicks(ctx context.Context, userID string, limit int) ([]*RecentClickDTO, error) {
+	result, err := s.base.GetRecentClicks(ctx, userID, limit)
+
+	if err != nil {
+		s.metrics.RecordError("analytics_fetch_failure", "analytics_service", "warning")
+	}
+
+	return result, err
+}
+
+func (s *InstrumentedAnalyticsService) ExportUserAnalytics(ctx context.Context, userID string, input ExportInput) (io.ReadCloser, error) {
+	reader, err := s.base.ExportUserAnalytics(ctx, userID, input)
+
+	if err != nil {
+		s.metrics.RecordError("analytics_export_failure", "analytics_service", "warning")
+	}
+
+	return reader, err
+}
+
+func (s *InstrumentedAnalyticsService) StartUserAnalyticsExport(ctx context.Context, userID string, input ExportInput) (*ExportJobDTO, error) {
+	job, err := s.base.StartUserAnalyticsExport(ctx, userID, input)
+
+	if err != nil {
+		s.metrics.RecordError("analytics_export_failure", "analytics_service", "warning")
+	}
+
+	return job, err
+}
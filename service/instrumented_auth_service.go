@@ -56,8 +56,8 @@ func (s *InstrumentedAuthService) RefreshToken(ctx context.Context, input Refres
 	return response, err
 }
 
-func (s *InstrumentedAuthService) GenerateResetToken(ctx context.Context, email string) error {
-	err := s.base.GenerateResetToken(ctx, email)
+func (s *InstrumentedAuthService) GenerateResetToken(ctx context.Context, email, redirectURI string) error {
+	err := s.base.GenerateResetToken(ctx, email, redirectURI)
 	
 	if err != nil {
 		s.metrics.RecordError("reset_token_generation_failure", "auth_service", "warning")
@@ -76,8 +76,28 @@ func (s *InstrumentedAuthService) ResetPassword(ctx context.Context, input Reset
 	return err
 }
 
-func (s *InstrumentedAuthService) Logout(ctx context.Context, userID string) error {
-	err := s.base.Logout(ctx, userID)
+func (s *InstrumentedAuthService) ChangePassword(ctx context.Context, userID, currentPassword, newPassword string) error {
+	err := s.base.ChangePassword(ctx, userID, currentPassword, newPassword)
+	
+	if err != nil {
+		s.metrics.RecordError("password_change_failure", "auth_service", "warning")
+	}
+	
+	return err
+}
+
+func (s *InstrumentedAuthService) Reauthenticate(ctx context.Context, userID, password, code string) error {
+	err := s.base.Reauthenticate(ctx, userID, password, code)
+
+	if err != nil {
+		s.metrics.RecordError("reauthentication_failure", "auth_service", "warning")
+	}
+
+	return err
+}
+
+func (s *InstrumentedAuthService) Logout(ctx context.Context, userID string, accessClaims *token.Claims) error {
+	err := s.base.Logout(ctx, userID, accessClaims)
 	
 	if err != nil {
 		s.metrics.RecordError("logout_failure", "auth_service", "warning")
@@ -86,6 +106,20 @@ func (s *InstrumentedAuthService) Logout(ctx context.Context, userID string) err
 	return err
 }
 
+func (s *InstrumentedAuthService) ListSessions(ctx context.Context, userID string) ([]SessionDTO, error) {
+	return s.base.ListSessions(ctx, userID)
+}
+
+func (s *InstrumentedAuthService) RevokeSession(ctx context.Context, userID, sessionID string) error {
+	err := s.base.RevokeSession(ctx, userID, sessionID)
+
+	if err != nil {
+		s.metrics.RecordError("session_revoke_failure", "auth_service", "warning")
+	}
+
+	return err
+}
+
 func (s *InstrumentedAuthService) ValidateToken(ctx context.Context, tokenStr string) (*token.Claims, error) {
 	claims, err := s.base.ValidateToken(ctx, tokenStr)
 	
@@ -96,6 +130,16 @@ func (s *InstrumentedAuthService) ValidateToken(ctx context.Context, tokenStr st
 	return claims, err
 }
 
+func (s *InstrumentedAuthService) ValidateRestoreToken(ctx context.Context, tokenStr string) (*token.Claims, error) {
+	claims, err := s.base.ValidateRestoreToken(ctx, tokenStr)
+	
+	if err != nil {
+		s.metrics.RecordError("token_validation_failure", "auth_service", "info")
+	}
+	
+	return claims, err
+}
+
 func (s *InstrumentedAuthService) IsEmailVerified(ctx context.Context, userID string) (bool, error) {
 	verified, err := s.base.IsEmailVerified(ctx, userID)
 	
@@ -106,8 +150,8 @@ func (s *InstrumentedAuthService) IsEmailVerified(ctx context.Context, userID st
 	return verified, err
 }
 
-func (s *InstrumentedAuthService) SendVerificationEmail(ctx context.Context, email, username, token string) error {
-	err := s.base.SendVerificationEmail(ctx, email, username, token)
+func (s *InstrumentedAuthService) SendVerificationEmail(ctx context.Context, email, username, locale, token string) error {
+	err := s.base.SendVerificationEmail(ctx, email, username, locale, token)
 	
 	status := "success"
 	if err != nil {
@@ -119,8 +163,8 @@ func (s *InstrumentedAuthService) SendVerificationEmail(ctx context.Context, ema
 	return err
 }
 
-func (s *InstrumentedAuthService) SendPasswordResetEmail(ctx context.Context, email, username, token string) error {
-	err := s.base.SendPasswordResetEmail(ctx, email, username, token)
+func (s *InstrumentedAuthService) SendPasswordResetEmail(ctx context.Context, email, username, locale, token string) error {
+	err := s.base.SendPasswordResetEmail(ctx, email, username, locale, token)
 	
 	status := "success"
 	if err != nil {
@@ -132,8 +176,8 @@ func (s *InstrumentedAuthService) SendPasswordResetEmail(ctx context.Context, em
 	return err
 }
 
-func (s *InstrumentedAuthService) SendPasswordChangedEmail(ctx context.Context, email, username string) error {
-	err := s.base.SendPasswordChangedEmail(ctx, email, username)
+func (s *InstrumentedAuthService) SendPasswordChangedEmail(ctx context.Context, email, username, locale string) error {
+	err := s.base.SendPasswordChangedEmail(ctx, email, username, locale)
 	
 	status := "success"
 	if err != nil {
@@ -145,8 +189,8 @@ func (s *InstrumentedAuthService) SendPasswordChangedEmail(ctx context.Context,
 	return err
 }
 
-func (s *InstrumentedAuthService) SendAccountLockedEmail(ctx context.Context, email, username, unlockTime string) error {
-	err := s.base.SendAccountLockedEmail(ctx, email, username, unlockTime)
+func (s *InstrumentedAuthService) SendAccountLockedEmail(ctx context.Context, email, username, locale, unlockTime string) error {
+	err := s.base.SendAccountLockedEmail(ctx, email, username, locale, unlockTime)
 	
 	status := "success"
 	if err != nil {
@@ -168,4 +212,83 @@ func (s *InstrumentedAuthService) VerifyEmail(ctx context.Context, token string)
 	// Remove the RecordBusinessEvent line - we don't need it
 	
 	return err
-}
\ No newline at end of file
+}
+func (s *InstrumentedAuthService) ResolveRedirect(redirectURI string) (string, error) {
+	return s.base.ResolveRedirect(redirectURI)
+}
+
+func (s *InstrumentedAuthService) CheckPasswordStrength(input PasswordStrengthInput) *PasswordStrengthDTO {
+	return s.base.CheckPasswordStrength(input)
+}
+
+func (s *InstrumentedAuthService) GetVerificationStatuses(ctx context.Context, userIDs []string) (map[string]bool, error) {
+	return s.base.GetVerificationStatuses(ctx, userIDs)
+}
+
+func (s *InstrumentedAuthService) CleanupUnverifiedAccounts(ctx context.Context, input CleanupUnverifiedInput) (*UnverifiedCleanupResultDTO, error) {
+	result, err := s.base.CleanupUnverifiedAccounts(ctx, input)
+
+	if err != nil {
+		s.metrics.RecordError("unverified_cleanup_failure", "auth_service", "error")
+	}
+
+	return result, err
+}
+
+func (s *InstrumentedAuthService) CreateInviteCodes(ctx context.Context, createdBy string, input CreateInviteCodesInput) ([]*InviteCodeDTO, error) {
+	codes, err := s.base.CreateInviteCodes(ctx, createdBy, input)
+
+	if err != nil {
+		s.metrics.RecordError("invite_code_creation_failure", "auth_service", "error")
+	}
+
+	return codes, err
+}
+
+func (s *InstrumentedAuthService) ListInviteCodes(ctx context.Context, limit, offset int) ([]*InviteCodeDTO, error) {
+	return s.base.ListInviteCodes(ctx, limit, offset)
+}
+
+func (s *InstrumentedAuthService) EnableTOTP(ctx context.Context, userID string) (*TOTPSetupDTO, error) {
+	setup, err := s.base.EnableTOTP(ctx, userID)
+
+	if err != nil {
+		s.metrics.RecordError("totp_enable_failure", "auth_service", "warning")
+	}
+
+	return setup, err
+}
+
+func (s *InstrumentedAuthService) VerifyTOTP(ctx context.Context, userID, code string) error {
+	err := s.base.VerifyTOTP(ctx, userID, code)
+
+	if err != nil {
+		s.metrics.RecordError("totp_verify_failure", "auth_service", "info")
+	}
+
+	return err
+}
+
+func (s *InstrumentedAuthService) LoginWithTOTP(ctx context.Context, input TOTPLoginInput) (*TokenResponse, error) {
+	response, err := s.base.LoginWithTOTP(ctx, input)
+
+	if err != nil {
+		s.metrics.RecordError("totp_login_failure", "auth_service", "info")
+	}
+
+	return response, err
+}
+
+func (s *InstrumentedAuthService) OAuthAuthorizeURL(provider, state string) (string, error) {
+	return s.base.OAuthAuthorizeURL(provider, state)
+}
+
+func (s *InstrumentedAuthService) OAuthLogin(ctx context.Context, provider, code string, client ClientInfo) (*TokenResponse, error) {
+	response, err := s.base.OAuthLogin(ctx, provider, code, client)
+
+	if err != nil {
+		s.metrics.RecordError("oauth_login_failure", "auth_service", "info")
+	}
+
+	return response, err
+}
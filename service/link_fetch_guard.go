@@ -0,0 +1,133 @@
+package service
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"syscall"
+	"time"
+
+	"github.com/0xsj/mios.io/pkg/errors"
+)
+
+// errBlockedAddress marks a fetch refused because it would reach an address
+// that is not on the public internet
+var errBlockedAddress = stderrors.New("address is not publicly routable")
+
+// maxFetchRedirects bounds how many redirects a metadata fetch follows
+const maxFetchRedirects = 5
+
+// blockedPrefixes are ranges that netip does not already classify as
+// private, loopback, link-local, multicast or unspecified but that still
+// must not be reachable from a user-supplied URL
+var blockedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),      // "this network"
+	netip.MustParsePrefix("100.64.0.0/10"),  // carrier-grade NAT, also Alibaba Cloud metadata
+	netip.MustParsePrefix("192.0.0.0/24"),   // IETF protocol assignments
+	netip.MustParsePrefix("198.18.0.0/15"),  // benchmarking
+	netip.MustParsePrefix("240.0.0.0/4"),    // reserved, includes broadcast
+	netip.MustParsePrefix("64:ff9b::/96"),   // NAT64, can embed any IPv4 address
+	netip.MustParsePrefix("64:ff9b:1::/48"), // local-use NAT64
+	netip.MustParsePrefix("2002::/16"),      // 6to4, can embed any IPv4 address
+}
+
+// isBlockedAddr reports whether addr is private, loopback, link-local (which
+// covers the 169.254.169.254 cloud metadata service), multicast or otherwise
+// reserved. IPv4-mapped IPv6 addresses are checked as IPv4.
+func isBlockedAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsValid() || !addr.IsGlobalUnicast() || addr.IsPrivate() {
+		return true
+	}
+	for _, prefix := range blockedPrefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkFetchURL refuses URLs that are not http(s) or whose host resolves to
+// a blocked address. A host that does not resolve is let through; the fetch
+// then fails the usual way. The dialer checks the address again when
+// connecting, so a DNS answer that changes in between is still caught.
+func checkFetchURL(ctx context.Context, target *url.URL) error {
+	if target.Scheme != "http" && target.Scheme != "https" {
+		return errors.NewValidationError("Only http and https URLs can be fetched", nil)
+	}
+
+	host := target.Hostname()
+	if host == "" {
+		return errors.NewValidationError("URL has no host", nil)
+	}
+
+	if addr, err := netip.ParseAddr(host); err == nil {
+		if isBlockedAddr(addr) {
+			return blockedURLError(host)
+		}
+		return nil
+	}
+
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		return nil
+	}
+	for _, addr := range addrs {
+		if isBlockedAddr(addr) {
+			return blockedURLError(host)
+		}
+	}
+	return nil
+}
+
+func blockedURLError(host string) error {
+	return errors.NewValidationError("URL points to a private or reserved address",
+		fmt.Errorf("%s: %w", host, errBlockedAddress))
+}
+
+// guardDial runs after DNS resolution, right before each connection, so it
+// sees the address actually being dialed
+func guardDial(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil || isBlockedAddr(addr) {
+		return fmt.Errorf("dialing %s: %w", address, errBlockedAddress)
+	}
+	return nil
+}
+
+// newFetchClient returns the HTTP client used for metadata and oEmbed
+// fetches. It never connects to blocked addresses, checks every redirect
+// target, and ignores proxy settings so the guard sees the real destination.
+func newFetchClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout:   5 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control:   guardDial,
+	}
+
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			DialContext:           dialer.DialContext,
+			ForceAttemptHTTP2:     true,
+			MaxIdleConns:          100,
+			IdleConnTimeout:       90 * time.Second,
+			TLSHandshakeTimeout:   10 * time.Second,
+			ExpectContinueTimeout: 1 * time.Second,
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= maxFetchRedirects {
+				return fmt.Errorf("stopped after %d redirects", maxFetchRedirects)
+			}
+			return checkFetchURL(req.Context(), req.URL)
+		},
+	}
+}
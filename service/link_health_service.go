@@ -0,0 +1,250 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	db "github.com/0xsj/mios.io/db/sqlc"
+	"github.com/0xsj/mios.io/log"
+	"github.com/0xsj/mios.io/pkg/email"
+	"github.com/0xsj/mios.io/pkg/errors"
+	"github.com/0xsj/mios.io/pkg/ptr"
+	"github.com/0xsj/mios.io/repository"
+)
+
+// LinkHealthService periodically checks content links and, when enabled,
+// deactivates items whose target keeps failing
+type LinkHealthService interface {
+	CheckLinks(ctx context.Context) (int, error)
+	StartHealthChecks(ctx context.Context)
+}
+
+// LinkHealthConfig controls the background link checker. Each link is
+// checked about once per CheckInterval; zero disables the checker. With
+// AutoDeactivate set, items failing FailureThreshold checks in a row are
+// deactivated and their owner is emailed.
+type LinkHealthConfig struct {
+	CheckInterval    time.Duration
+	FailureThreshold int
+	AutoDeactivate   bool
+}
+
+const (
+	defaultLinkFailureThreshold = 3
+	linkHealthBatchSize         = 200
+)
+
+type linkHealthService struct {
+	linkHealthRepo repository.LinkHealthRepository
+	historyRepo    repository.ContentHistoryRepository
+	emailClient    *email.EmailClient
+	client         *http.Client
+	config         LinkHealthConfig
+	logger         log.Logger
+	baseURL        string
+}
+
+func NewLinkHealthService(
+	linkHealthRepo repository.LinkHealthRepository,
+	historyRepo repository.ContentHistoryRepository,
+	emailClient *email.EmailClient,
+	config LinkHealthConfig,
+	logger log.Logger,
+	baseURL string,
+) LinkHealthService {
+	if config.FailureThreshold <= 0 {
+		config.FailureThreshold = defaultLinkFailureThreshold
+	}
+
+	return &linkHealthService{
+		linkHealthRepo: linkHealthRepo,
+		historyRepo:    historyRepo,
+		emailClient:    emailClient,
+		client:         newFetchClient(10 * time.Second),
+		config:         config,
+		logger:         logger,
+		baseURL:        baseURL,
+	}
+}
+
+// CheckLinks checks every active link not checked within the last interval
+// and returns how many items were deactivated
+func (s *linkHealthService) CheckLinks(ctx context.Context) (int, error) {
+	checkedBefore := time.Now().Add(-s.config.CheckInterval)
+	checked, deactivated := 0, 0
+
+	for {
+		links, err := s.linkHealthRepo.ListLinksForHealthCheck(ctx, checkedBefore, linkHealthBatchSize)
+		if err != nil {
+			s.logger.Errorf("Failed to list links for health check: %v", err)
+			return deactivated, errors.Wrap(err, "Failed to list links for health check")
+		}
+
+		recorded := 0
+		for _, link := range links {
+			if ctx.Err() != nil {
+				return deactivated, ctx.Err()
+			}
+
+			wasRecorded, wasDeactivated := s.checkLink(ctx, link)
+			if wasRecorded {
+				recorded++
+			}
+			if wasDeactivated {
+				deactivated++
+			}
+			checked++
+		}
+
+		// Stop once the backlog is drained, or when nothing in the batch could
+		// be recorded so the same links would be listed again
+		if len(links) < linkHealthBatchSize || recorded == 0 {
+			break
+		}
+	}
+
+	s.logger.Infof("Checked %d links, deactivated %d", checked, deactivated)
+	return deactivated, nil
+}
+
+// checkLink records one health check and applies the dead-link policy. It
+// reports whether the check was recorded and whether the item was
+// deactivated.
+func (s *linkHealthService) checkLink(ctx context.Context, link *db.ListLinksForHealthCheckRow) (bool, bool) {
+	status, ok := s.probe(ctx, link.TargetUrl)
+
+	var lastStatus *int32
+	if status > 0 {
+		code := int32(status)
+		lastStatus = &code
+	}
+
+	health, err := s.linkHealthRepo.RecordLinkCheck(ctx, link.ItemID, !ok, lastStatus)
+	if err != nil {
+		s.logger.Warnf("Failed to record health check for item %s: %v", link.ItemID, err)
+		return false, false
+	}
+
+	if ok || !s.config.AutoDeactivate || !link.AutoDeactivate ||
+		int(health.ConsecutiveFailures) < s.config.FailureThreshold {
+		return true, false
+	}
+
+	deactivated, err := s.linkHealthRepo.DeactivateDeadLink(ctx, link.ItemID)
+	if err != nil {
+		s.logger.Warnf("Failed to deactivate dead link %s: %v", link.ItemID, err)
+		return true, false
+	}
+	if !deactivated {
+		return true, false
+	}
+
+	s.logger.Infof("Deactivated item %s after %d failed checks of %s",
+		link.ItemID, health.ConsecutiveFailures, link.TargetUrl)
+
+	err = s.historyRepo.Record(ctx, repository.ContentHistoryEntry{
+		ItemID: link.ItemID,
+		Source: HistorySourceSystem,
+		Action: HistoryActionAutoDeactivated,
+		Changes: map[string]interface{}{
+			"is_active": FieldChangeDTO{From: true, To: false},
+		},
+	})
+	if err != nil {
+		s.logger.Warnf("Failed to record deactivation history for item %s: %v", link.ItemID, err)
+	}
+
+	if err := s.sendDeactivationEmail(link, int(health.ConsecutiveFailures)); err != nil {
+		s.logger.Warnf("Failed to email user %s about dead link %s: %v", link.UserID, link.ItemID, err)
+	}
+	return true, true
+}
+
+// probe requests the target and reports the status code and whether the
+// link is considered healthy. Non-HTTP targets such as mailto: are skipped.
+func (s *linkHealthService) probe(ctx context.Context, target string) (int, bool) {
+	parsed, err := url.Parse(target)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+		return 0, true
+	}
+
+	// Owners choose these URLs, so they get the same address checks as
+	// metadata fetches; a link into a private network counts as broken
+	if err := checkFetchURL(ctx, parsed); err != nil {
+		s.logger.Warnf("Refused health check of %s: %v", target, err)
+		return 0, false
+	}
+
+	status, err := s.request(ctx, http.MethodHead, target)
+	if err == nil && (status == http.StatusMethodNotAllowed || status == http.StatusNotImplemented) {
+		status, err = s.request(ctx, http.MethodGet, target)
+	}
+	if err != nil {
+		s.logger.Debugf("Health check of %s failed: %v", target, err)
+		return 0, false
+	}
+
+	return status, status < http.StatusBadRequest
+}
+
+func (s *linkHealthService) request(ctx context.Context, method, target string) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, method, target, nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("User-Agent", "Mozilla/5.0 (compatible; LinkHealthBot/1.0)")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	return resp.StatusCode, nil
+}
+
+func (s *linkHealthService) sendDeactivationEmail(link *db.ListLinksForHealthCheckRow, failures int) error {
+	title := link.TargetUrl
+	if link.Title != nil && *link.Title != "" {
+		title = *link.Title
+	}
+
+	data := map[string]interface{}{
+		"Username":  link.Username,
+		"Title":     title,
+		"TargetURL": link.TargetUrl,
+		"Failures":  failures,
+		"Link":      fmt.Sprintf("%s/content", s.baseURL),
+		"AppName":   "Your App Name",
+		"Year":      time.Now().Year(),
+	}
+
+	return s.emailClient.SendLocalizedTemplate([]string{link.Email}, "A Broken Link Was Removed From Your Profile",
+		"dead_link_deactivated.html", ptr.GetValueOrEmpty(link.Locale), data)
+}
+
+// StartHealthChecks runs CheckLinks every CheckInterval until ctx is cancelled
+func (s *linkHealthService) StartHealthChecks(ctx context.Context) {
+	if s.config.CheckInterval <= 0 {
+		s.logger.Info("Link health checks are disabled")
+		return
+	}
+
+	ticker := time.NewTicker(s.config.CheckInterval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.CheckLinks(ctx); err != nil {
+			s.logger.Errorf("Link health check run failed: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
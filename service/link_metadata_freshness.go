@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/0xsj/mios.io/pkg/errors"
+)
+
+// Freshness states of a URL's stored metadata
+const (
+	FreshnessFresh   = "fresh"   // stored and younger than the refresh threshold
+	FreshnessStale   = "stale"   // stored but due for a refresh
+	FreshnessPending = "pending" // nothing stored yet
+	FreshnessFailed  = "failed"  // the last fetch of the page failed
+)
+
+// maxFreshnessURLs bounds how many URLs one freshness check may cover
+const maxFreshnessURLs = 100
+
+// FreshnessDTO describes how current the stored metadata for a URL is.
+// UpdatedAt is empty while the URL is pending.
+type FreshnessDTO struct {
+	Status    string `json:"status"`
+	UpdatedAt string `json:"updated_at,omitempty"`
+}
+
+// GetFreshnessStatus reports the freshness of the stored metadata for each
+// URL with a single query, keyed by the URLs as given. It never fetches, so
+// clients can decide which cards to refresh.
+func (s *linkMetadataService) GetFreshnessStatus(ctx context.Context, urls []string) (map[string]FreshnessDTO, error) {
+	s.logger.Debugf("Getting metadata freshness for %d URLs", len(urls))
+
+	if len(urls) == 0 {
+		return map[string]FreshnessDTO{}, nil
+	}
+	if len(urls) > maxFreshnessURLs {
+		return nil, errors.NewValidationError(fmt.Sprintf("At most %d URLs can be checked at once", maxFreshnessURLs), nil)
+	}
+
+	// Metadata is stored under the normalized URL
+	normalized := make(map[string]string, len(urls))
+	lookup := make([]string, 0, len(urls))
+	for _, urlString := range urls {
+		normalizedURL, err := normalizeURL(urlString, s.platform)
+		if err != nil {
+			s.logger.Warnf("Invalid URL format: %v", err)
+			return nil, errors.NewValidationError(fmt.Sprintf("Invalid URL format: %s", urlString), err)
+		}
+		if _, seen := normalized[urlString]; !seen {
+			lookup = append(lookup, normalizedURL)
+		}
+		normalized[urlString] = normalizedURL
+	}
+
+	rows, err := s.repo.GetLinkMetadataFreshness(ctx, lookup)
+	if err != nil {
+		s.logger.Errorf("Failed to get metadata freshness: %v", err)
+		return nil, errors.Wrap(err, "Failed to retrieve metadata freshness")
+	}
+
+	stored := make(map[string]FreshnessDTO, len(rows))
+	for _, row := range rows {
+		status := FreshnessFresh
+		switch {
+		case row.FetchFailed:
+			status = FreshnessFailed
+		case time.Since(row.UpdatedAt) > metadataStaleAfter:
+			status = FreshnessStale
+		}
+		stored[row.URL] = FreshnessDTO{
+			Status:    status,
+			UpdatedAt: row.UpdatedAt.Format(time.RFC3339),
+		}
+	}
+
+	result := make(map[string]FreshnessDTO, len(normalized))
+	for urlString, normalizedURL := range normalized {
+		freshness, ok := stored[normalizedURL]
+		if !ok {
+			freshness = FreshnessDTO{Status: FreshnessPending}
+		}
+		result[urlString] = freshness
+	}
+
+	return result, nil
+}
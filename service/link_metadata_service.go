@@ -3,9 +3,16 @@ package service
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/json"
+	stderrors "errors"
 	"fmt"
+	"hash/fnv"
+	"io"
+	"mime"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 	"time"
 
@@ -22,7 +29,13 @@ type LinkMetadataService interface {
 	FetchAndStoreMetadata(ctx context.Context, urlString string) (*LinkMetadataDTO, error)
 	IsKnownPlatform(domain string) bool
 	GetPlatformInfo(domain string) *PlatformInfo
+	DetectPlatform(urlString string) (*PlatformDetectionDTO, error)
 	ListKnownPlatforms(ctx context.Context) ([]*PlatformInfo, error)
+	LoadPlatforms(ctx context.Context) error
+	CreatePlatform(ctx context.Context, input CreatePlatformInput) (*PlatformInfo, error)
+	UpdatePlatform(ctx context.Context, domain string, input UpdatePlatformInput) (*PlatformInfo, error)
+	DeletePlatform(ctx context.Context, domain string) error
+	GetFreshnessStatus(ctx context.Context, urls []string) (map[string]FreshnessDTO, error)
 }
 
 type PlatformInfo struct {
@@ -32,8 +45,46 @@ type PlatformInfo struct {
 	Color       string `json:"color"`
 	Icon        string `json:"icon"`
 	URLTemplate string `json:"url_template"`
+
+	// How metadata is gathered for this platform; empty means ScrapeStrategyHTML
+	ScrapeStrategy string `json:"scrape_strategy,omitempty"`
+	// oEmbed endpoint used by ScrapeStrategyOEmbed
+	OEmbedEndpoint string `json:"oembed_endpoint,omitempty"`
+	// Sent instead of the default User-Agent when fetching this platform
+	UserAgent string `json:"user_agent,omitempty"`
+	// Query parameters that identify what a link points to, e.g. "v" for
+	// YouTube videos; the rest are dropped from the URL metadata is stored
+	// under. Empty keeps every parameter that is not a known tracker.
+	QueryKeys []string `json:"query_keys,omitempty"`
+}
+
+// Scrape strategies a platform can use
+const (
+	ScrapeStrategyHTML   = "html"   // fetch the page and parse its head tags
+	ScrapeStrategyOEmbed = "oembed" // ask the platform's oEmbed endpoint
+	ScrapeStrategyAPI    = "api"    // metadata is only available through an authenticated API
+	ScrapeStrategySkip   = "skip"   // never fetch; store registry info only
+)
+
+var knownScrapeStrategies = map[string]bool{
+	ScrapeStrategyHTML:   true,
+	ScrapeStrategyOEmbed: true,
+	ScrapeStrategyAPI:    true,
+	ScrapeStrategySkip:   true,
 }
 
+const defaultScraperUserAgent = "Link Metadata Service 1.0"
+
+// Defaults for how long a page fetch may take and how much of a page is
+// parsed; metadata tags live in the head, so a truncated page still works
+const (
+	defaultFetchTimeout = 10 * time.Second
+	defaultMaxPageBytes = 2 << 20
+)
+
+// metadataStaleAfter is how old stored metadata can get before it is refreshed
+const metadataStaleAfter = 7 * 24 * time.Hour
+
 type LinkMetadataDTO struct {
 	ID            string `json:"id"`
 	URL           string `json:"url"`
@@ -42,39 +93,48 @@ type LinkMetadataDTO struct {
 	Description   string `json:"description,omitempty"`
 	FaviconURL    string `json:"favicon_url,omitempty"`
 	ImageURL      string `json:"image_url,omitempty"`
+	ImageSource   string `json:"image_source,omitempty"`
 	PlatformName  string `json:"platform_name,omitempty"`
 	PlatformType  string `json:"platform_type,omitempty"`
 	PlatformColor string `json:"platform_color,omitempty"`
 	IsVerified    bool   `json:"is_verified"`
+	CanonicalURL  string `json:"canonical_url,omitempty"`
 	CreatedAt     string `json:"created_at,omitempty"`
 	UpdatedAt     string `json:"updated_at,omitempty"`
 }
 
-// PlatformRegistry defines known platforms and their metadata
+// PlatformRegistry is the built-in set of known platforms. The platforms
+// table is seeded with it and replaces it once loaded; until then, or if
+// loading fails, lookups fall back to these entries.
 var PlatformRegistry = map[string]PlatformInfo{
 	"instagram.com": {
-		Domain:      "instagram.com",
-		Name:        "Instagram",
-		Type:        "social",
-		Color:       "#E1306C",
-		Icon:        "/assets/icons/instagram.svg",
-		URLTemplate: "https://instagram.com/{username}",
+		Domain:         "instagram.com",
+		Name:           "Instagram",
+		Type:           "social",
+		Color:          "#E1306C",
+		Icon:           "/assets/icons/instagram.svg",
+		URLTemplate:    "https://instagram.com/{username}",
+		ScrapeStrategy: ScrapeStrategyAPI,
 	},
 	"twitter.com": {
-		Domain:      "twitter.com",
-		Name:        "Twitter",
-		Type:        "social",
-		Color:       "#1DA1F2",
-		Icon:        "/assets/icons/twitter.svg",
-		URLTemplate: "https://twitter.com/{username}",
+		Domain:         "twitter.com",
+		Name:           "Twitter",
+		Type:           "social",
+		Color:          "#1DA1F2",
+		Icon:           "/assets/icons/twitter.svg",
+		URLTemplate:    "https://twitter.com/{username}",
+		ScrapeStrategy: ScrapeStrategyOEmbed,
+		OEmbedEndpoint: "https://publish.twitter.com/oembed",
 	},
 	"x.com": {
-		Domain:      "x.com",
-		Name:        "X",
-		Type:        "social",
-		Color:       "#000000",
-		Icon:        "/assets/icons/x.svg",
-		URLTemplate: "https://x.com/{username}",
+		Domain:         "x.com",
+		Name:           "X",
+		Type:           "social",
+		Color:          "#000000",
+		Icon:           "/assets/icons/x.svg",
+		URLTemplate:    "https://x.com/{username}",
+		ScrapeStrategy: ScrapeStrategyOEmbed,
+		OEmbedEndpoint: "https://publish.twitter.com/oembed",
 	},
 	"github.com": {
 		Domain:      "github.com",
@@ -85,44 +145,54 @@ var PlatformRegistry = map[string]PlatformInfo{
 		URLTemplate: "https://github.com/{username}",
 	},
 	"linkedin.com": {
-		Domain:      "linkedin.com",
-		Name:        "LinkedIn",
-		Type:        "professional",
-		Color:       "#0A66C2",
-		Icon:        "/assets/icons/linkedin.svg",
-		URLTemplate: "https://linkedin.com/in/{username}",
+		Domain:         "linkedin.com",
+		Name:           "LinkedIn",
+		Type:           "professional",
+		Color:          "#0A66C2",
+		Icon:           "/assets/icons/linkedin.svg",
+		URLTemplate:    "https://linkedin.com/in/{username}",
+		ScrapeStrategy: ScrapeStrategySkip,
 	},
 	"youtube.com": {
-		Domain:      "youtube.com",
-		Name:        "YouTube",
-		Type:        "video",
-		Color:       "#FF0000",
-		Icon:        "/assets/icons/youtube.svg",
-		URLTemplate: "https://youtube.com/{channel}",
+		Domain:         "youtube.com",
+		Name:           "YouTube",
+		Type:           "video",
+		Color:          "#FF0000",
+		Icon:           "/assets/icons/youtube.svg",
+		URLTemplate:    "https://youtube.com/{channel}",
+		ScrapeStrategy: ScrapeStrategyOEmbed,
+		OEmbedEndpoint: "https://www.youtube.com/oembed",
+		QueryKeys:      []string{"v", "list"},
 	},
 	"tiktok.com": {
-		Domain:      "tiktok.com",
-		Name:        "TikTok",
-		Type:        "video",
-		Color:       "#000000",
-		Icon:        "/assets/icons/tiktok.svg",
-		URLTemplate: "https://tiktok.com/@{username}",
+		Domain:         "tiktok.com",
+		Name:           "TikTok",
+		Type:           "video",
+		Color:          "#000000",
+		Icon:           "/assets/icons/tiktok.svg",
+		URLTemplate:    "https://tiktok.com/@{username}",
+		ScrapeStrategy: ScrapeStrategyOEmbed,
+		OEmbedEndpoint: "https://www.tiktok.com/oembed",
 	},
 	"facebook.com": {
-		Domain:      "facebook.com",
-		Name:        "Facebook",
-		Type:        "social",
-		Color:       "#1877F2",
-		Icon:        "/assets/icons/facebook.svg",
-		URLTemplate: "https://facebook.com/{username}",
+		Domain:         "facebook.com",
+		Name:           "Facebook",
+		Type:           "social",
+		Color:          "#1877F2",
+		Icon:           "/assets/icons/facebook.svg",
+		URLTemplate:    "https://facebook.com/{username}",
+		ScrapeStrategy: ScrapeStrategyAPI,
 	},
 	"spotify.com": {
-		Domain:      "spotify.com",
-		Name:        "Spotify",
-		Type:        "music",
-		Color:       "#1DB954",
-		Icon:        "/assets/icons/spotify.svg",
-		URLTemplate: "https://open.spotify.com/user/{username}",
+		Domain:         "spotify.com",
+		Name:           "Spotify",
+		Type:           "music",
+		Color:          "#1DB954",
+		Icon:           "/assets/icons/spotify.svg",
+		URLTemplate:    "https://open.spotify.com/user/{username}",
+		ScrapeStrategy: ScrapeStrategyOEmbed,
+		OEmbedEndpoint: "https://open.spotify.com/oembed",
+		QueryKeys:      []string{"si"},
 	},
 	"twitch.tv": {
 		Domain:      "twitch.tv",
@@ -131,22 +201,108 @@ var PlatformRegistry = map[string]PlatformInfo{
 		Color:       "#9146FF",
 		Icon:        "/assets/icons/twitch.svg",
 		URLTemplate: "https://twitch.tv/{username}",
+		UserAgent:   "facebookexternalhit/1.1",
 	},
 }
 
+// Image sources, in the order they can appear in an image fallback chain
+const (
+	ImageSourceOpenGraph    = "og_image"
+	ImageSourceTwitter      = "twitter_image"
+	ImageSourceLargestImage = "largest_image"
+	ImageSourcePlatformIcon = "platform_icon"
+	ImageSourcePlaceholder  = "placeholder"
+)
+
+// DefaultImageFallbackChain is used when no chain is configured
+var DefaultImageFallbackChain = []string{
+	ImageSourceOpenGraph,
+	ImageSourceTwitter,
+	ImageSourceLargestImage,
+	ImageSourcePlatformIcon,
+	ImageSourcePlaceholder,
+}
+
+var knownImageSources = map[string]bool{
+	ImageSourceOpenGraph:    true,
+	ImageSourceTwitter:      true,
+	ImageSourceLargestImage: true,
+	ImageSourcePlatformIcon: true,
+	ImageSourcePlaceholder:  true,
+}
+
+// LinkMetadataConfig contains configuration for the link metadata service
+type LinkMetadataConfig struct {
+	ImageFallbackChain []string // Order in which image sources are tried
+	ScrapeStrategies   []string // Per-domain "domain:strategy" overrides of the registry
+	UserAgent          string   // Default User-Agent for outbound fetches
+
+	FetchTimeout time.Duration // Limit on a whole page fetch, redirects included
+	MaxPageBytes int64         // How much of a page is read before parsing
+}
+
 type linkMetadataService struct {
-	repo   repository.LinkMetadataRepository
-	logger log.Logger
-	client *http.Client
+	repo       repository.LinkMetadataRepository
+	logger     log.Logger
+	client     *http.Client
+	imageChain []string
+	strategies map[string]string
+	userAgent  string
+	platforms  *platformCache
+
+	maxPageBytes int64
 }
 
-func NewLinkMetadataService(repo repository.LinkMetadataRepository, logger log.Logger) LinkMetadataService {
+func NewLinkMetadataService(repo repository.LinkMetadataRepository, config LinkMetadataConfig, logger log.Logger) LinkMetadataService {
+	imageChain := make([]string, 0, len(config.ImageFallbackChain))
+	for _, source := range config.ImageFallbackChain {
+		source = strings.TrimSpace(source)
+		if !knownImageSources[source] {
+			logger.Warnf("Ignoring unknown image fallback source: %q", source)
+			continue
+		}
+		imageChain = append(imageChain, source)
+	}
+	if len(imageChain) == 0 {
+		imageChain = DefaultImageFallbackChain
+	}
+
+	strategies := make(map[string]string, len(config.ScrapeStrategies))
+	for _, rule := range config.ScrapeStrategies {
+		domain, strategy, ok := strings.Cut(strings.TrimSpace(rule), ":")
+		domain = strings.ToLower(strings.TrimSpace(domain))
+		strategy = strings.ToLower(strings.TrimSpace(strategy))
+		if !ok || domain == "" || !knownScrapeStrategies[strategy] {
+			logger.Warnf("Ignoring invalid scrape strategy rule: %q", rule)
+			continue
+		}
+		strategies[domain] = strategy
+	}
+
+	userAgent := strings.TrimSpace(config.UserAgent)
+	if userAgent == "" {
+		userAgent = defaultScraperUserAgent
+	}
+
+	fetchTimeout := config.FetchTimeout
+	if fetchTimeout <= 0 {
+		fetchTimeout = defaultFetchTimeout
+	}
+	maxPageBytes := config.MaxPageBytes
+	if maxPageBytes <= 0 {
+		maxPageBytes = defaultMaxPageBytes
+	}
+
 	return &linkMetadataService{
-		repo:   repo,
-		logger: logger,
-		client: &http.Client{
-			Timeout: 10 * time.Second,
-		},
+		repo:       repo,
+		logger:     logger,
+		imageChain: imageChain,
+		strategies: strategies,
+		userAgent:  userAgent,
+		platforms:  newPlatformCache(PlatformRegistry),
+		client:     newFetchClient(fetchTimeout),
+
+		maxPageBytes: maxPageBytes,
 	}
 }
 
@@ -154,7 +310,7 @@ func (s *linkMetadataService) GetMetadata(ctx context.Context, urlString string)
 	s.logger.Debugf("Getting metadata for URL: %s", urlString)
 
 	// Clean and normalize the URL
-	normalizedURL, err := normalizeURL(urlString)
+	normalizedURL, err := normalizeURL(urlString, s.platform)
 	if err != nil {
 		s.logger.Warnf("Invalid URL format: %v", err)
 		return nil, errors.NewValidationError("Invalid URL format", err)
@@ -174,7 +330,7 @@ func (s *linkMetadataService) GetMetadata(ctx context.Context, urlString string)
 	}
 
 	// If metadata is older than a week, refresh it asynchronously
-	if metadata.UpdatedAt != nil && time.Since(*metadata.UpdatedAt) > 7*24*time.Hour {
+	if metadata.UpdatedAt != nil && time.Since(*metadata.UpdatedAt) > metadataStaleAfter {
 		s.logger.Debugf("Metadata for URL %s is older than a week, refreshing asynchronously", normalizedURL)
 		go func() {
 			bgCtx := context.Background()
@@ -197,15 +353,39 @@ func (s *linkMetadataService) FetchAndStoreMetadata(ctx context.Context, urlStri
 		return nil, errors.NewValidationError("Invalid URL format", err)
 	}
 
+	if err := checkFetchURL(ctx, parsedURL); err != nil {
+		s.logger.Warnf("Refusing to fetch %s: %v", urlString, err)
+		return nil, err
+	}
+
 	domain := parsedURL.Hostname()
 
 	// Check if it's a known platform
-	var platformName, platformType, platformColor *string
-	if platform, found := PlatformRegistry[domain]; found {
-		s.logger.Debugf("URL %s matches known platform: %s", urlString, platform.Name)
-		platformName = &platform.Name
-		platformType = &platform.Type
-		platformColor = &platform.Color
+	platform := s.platform(domain)
+	strategy := s.scrapeStrategy(domain, platform)
+
+	// Store under the same key GetMetadata looks up
+	stripInsignificantQuery(parsedURL, platform)
+	urlString = parsedURL.String()
+	if platform != nil {
+		s.logger.Debugf("URL %s matches known platform: %s (strategy %s)", urlString, platform.Name, strategy)
+	}
+
+	switch strategy {
+	case ScrapeStrategySkip, ScrapeStrategyAPI:
+		// No API clients exist yet, so API-only platforms get registry info
+		// just like skipped ones rather than a useless HTML scrape
+		s.logger.Debugf("Not fetching %s, storing registry info only", urlString)
+		return s.storeMetadata(ctx, urlString, domain, HTMLMetadata{}, platform, false)
+	case ScrapeStrategyOEmbed:
+		metadata, err := s.fetchOEmbed(ctx, urlString, platform)
+		if err == nil {
+			if metadata.FaviconURL == "" {
+				metadata.FaviconURL = fmt.Sprintf("%s://%s/favicon.ico", parsedURL.Scheme, domain)
+			}
+			return s.storeMetadata(ctx, urlString, domain, metadata, platform, false)
+		}
+		s.logger.Warnf("oEmbed lookup failed for %s, falling back to HTML: %v", urlString, err)
 	}
 
 	// Fetch page content
@@ -215,50 +395,71 @@ func (s *linkMetadataService) FetchAndStoreMetadata(ctx context.Context, urlStri
 		return nil, errors.NewExternalServiceError("Failed to create request", err)
 	}
 
-	req.Header.Set("User-Agent", "Link Metadata Service 1.0")
+	req.Header.Set("User-Agent", s.userAgentFor(platform))
 
 	resp, err := s.client.Do(req)
 	if err != nil {
+		// A redirect or changed DNS answer led to a blocked address
+		if stderrors.Is(err, errBlockedAddress) {
+			s.logger.Warnf("Refusing to fetch %s: %v", urlString, err)
+			return nil, errors.NewValidationError("URL points to a private or reserved address", err)
+		}
+
 		s.logger.Warnf("Failed to fetch URL: %v", err)
 
 		// Store minimal information if we can't fetch
-		createParams := repository.CreateLinkMetadataParams{
-			Domain:        domain,
-			URL:           urlString,
-			PlatformName:  platformName,
-			PlatformType:  platformType,
-			PlatformColor: platformColor,
-			IsVerified:    nil,
-		}
+		return s.storeMetadata(ctx, urlString, domain, HTMLMetadata{}, platform, true)
+	}
+	defer resp.Body.Close()
 
-		metadata, err := s.repo.CreateLinkMetadata(ctx, createParams)
-		if err != nil {
-			s.logger.Errorf("Failed to store minimal metadata: %v", err)
-			return nil, errors.Wrap(err, "Failed to store metadata")
-		}
+	// An error page's title and images would be wrong for the link card
+	if resp.StatusCode >= http.StatusBadRequest {
+		s.logger.Warnf("Fetching %s returned status %d", urlString, resp.StatusCode)
+		return s.storeMetadata(ctx, urlString, domain, HTMLMetadata{}, platform, true)
+	}
+
+	// Relative URLs in the page are relative to where the redirects ended
+	finalURL := resp.Request.URL
 
-		return mapLinkMetadataToDTO(metadata), nil
+	// Images, PDFs and other downloads have no metadata tags to scrape
+	if !isHTMLContentType(resp.Header.Get("Content-Type")) {
+		s.logger.Debugf("Not parsing %s, content type is %q", urlString, resp.Header.Get("Content-Type"))
+		return s.storeMetadata(ctx, urlString, domain, HTMLMetadata{CanonicalURL: finalURL.String()}, platform, false)
 	}
-	defer resp.Body.Close()
 
-	// Parse HTML to extract metadata
-	doc, err := html.Parse(resp.Body)
+	// Parse HTML to extract metadata, reading no more than the page size cap
+	doc, err := html.Parse(io.LimitReader(resp.Body, s.maxPageBytes))
 	if err != nil {
 		s.logger.Warnf("Failed to parse HTML: %v", err)
 		return nil, errors.NewExternalServiceError("Failed to parse page content", err)
 	}
 
-	// Extract metadata
+	// Extract metadata from HTML
+	metadata := extractMetadata(doc, finalURL.String())
+	metadata.CanonicalURL = finalURL.String()
+
+	if metadata.FaviconURL == "" {
+		// Try default favicon location
+		metadata.FaviconURL = fmt.Sprintf("%s://%s/favicon.ico", finalURL.Scheme, finalURL.Host)
+	}
+
+	return s.storeMetadata(ctx, urlString, domain, metadata, platform, false)
+}
+
+// storeMetadata creates or updates the stored metadata for a URL from what
+// was scraped and the platform registry entry, if any. fetchFailed records
+// that the page could not be fetched; earlier scraped fields are kept.
+func (s *linkMetadataService) storeMetadata(ctx context.Context, urlString, domain string, metadata HTMLMetadata, platform *PlatformInfo, fetchFailed bool) (*LinkMetadataDTO, error) {
 	var (
-		title       *string
-		description *string
-		faviconURL  *string
-		imageURL    *string
+		title         *string
+		description   *string
+		faviconURL    *string
+		canonicalURL  *string
+		platformName  *string
+		platformType  *string
+		platformColor *string
 	)
 
-	// Extract metadata from HTML
-	metadata := extractMetadata(doc, urlString)
-
 	if metadata.Title != "" {
 		title = &metadata.Title
 	}
@@ -269,16 +470,20 @@ func (s *linkMetadataService) FetchAndStoreMetadata(ctx context.Context, urlStri
 
 	if metadata.FaviconURL != "" {
 		faviconURL = &metadata.FaviconURL
-	} else {
-		// Try default favicon location
-		defaultFavicon := fmt.Sprintf("%s://%s/favicon.ico", parsedURL.Scheme, domain)
-		faviconURL = &defaultFavicon
 	}
 
-	if metadata.ImageURL != "" {
-		imageURL = &metadata.ImageURL
+	if metadata.CanonicalURL != "" {
+		canonicalURL = &metadata.CanonicalURL
+	}
+
+	if platform != nil {
+		platformName = &platform.Name
+		platformType = &platform.Type
+		platformColor = &platform.Color
 	}
 
+	imageURL, imageSource := s.resolveImage(metadata, domain)
+
 	// Check if we already have this URL in the database
 	existingMetadata, err := s.repo.GetLinkMetadataByURL(ctx, urlString)
 	if err == nil && existingMetadata != nil {
@@ -289,10 +494,13 @@ func (s *linkMetadataService) FetchAndStoreMetadata(ctx context.Context, urlStri
 			Description:   description,
 			FaviconURL:    faviconURL,
 			ImageURL:      imageURL,
+			ImageSource:   imageSource,
 			PlatformName:  platformName,
 			PlatformType:  platformType,
 			PlatformColor: platformColor,
 			IsVerified:    nil,
+			FetchFailed:   fetchFailed,
+			CanonicalURL:  canonicalURL,
 		}
 
 		updatedMetadata, err := s.repo.UpdateLinkMetadata(ctx, updateParams)
@@ -313,10 +521,13 @@ func (s *linkMetadataService) FetchAndStoreMetadata(ctx context.Context, urlStri
 		Description:   description,
 		FaviconURL:    faviconURL,
 		ImageURL:      imageURL,
+		ImageSource:   imageSource,
 		PlatformName:  platformName,
 		PlatformType:  platformType,
 		PlatformColor: platformColor,
 		IsVerified:    nil,
+		FetchFailed:   fetchFailed,
+		CanonicalURL:  canonicalURL,
 	}
 
 	newMetadata, err := s.repo.CreateLinkMetadata(ctx, createParams)
@@ -329,24 +540,155 @@ func (s *linkMetadataService) FetchAndStoreMetadata(ctx context.Context, urlStri
 	return mapLinkMetadataToDTO(newMetadata), nil
 }
 
+// oEmbedResponse holds the oEmbed fields used for link previews
+type oEmbedResponse struct {
+	Title        string `json:"title"`
+	AuthorName   string `json:"author_name"`
+	ThumbnailURL string `json:"thumbnail_url"`
+}
+
+// maxOEmbedResponseSize bounds how much of an oEmbed response is read
+const maxOEmbedResponseSize = 1 << 20
+
+// fetchOEmbed asks the platform's oEmbed endpoint to describe urlString
+func (s *linkMetadataService) fetchOEmbed(ctx context.Context, urlString string, platform *PlatformInfo) (HTMLMetadata, error) {
+	if platform == nil || platform.OEmbedEndpoint == "" {
+		return HTMLMetadata{}, fmt.Errorf("no oEmbed endpoint configured")
+	}
+
+	endpoint, err := url.Parse(platform.OEmbedEndpoint)
+	if err != nil {
+		return HTMLMetadata{}, err
+	}
+	query := endpoint.Query()
+	query.Set("url", urlString)
+	query.Set("format", "json")
+	endpoint.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint.String(), nil)
+	if err != nil {
+		return HTMLMetadata{}, err
+	}
+	req.Header.Set("User-Agent", s.userAgentFor(platform))
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return HTMLMetadata{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return HTMLMetadata{}, fmt.Errorf("oEmbed endpoint returned status %d", resp.StatusCode)
+	}
+
+	var oembed oEmbedResponse
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxOEmbedResponseSize)).Decode(&oembed); err != nil {
+		return HTMLMetadata{}, fmt.Errorf("decoding oEmbed response: %w", err)
+	}
+
+	return HTMLMetadata{
+		Title:       oembed.Title,
+		Description: oembed.AuthorName,
+		OGImageURL:  oembed.ThumbnailURL,
+	}, nil
+}
+
+// platform returns the registry entry for a domain, or nil if it is unknown.
+// Subdomains and aliases of a platform match it too, see domainFor.
+func (s *linkMetadataService) platform(domain string) *PlatformInfo {
+	platform, found := s.platforms.lookup(domain)
+	if !found {
+		return nil
+	}
+	if strategy, ok := s.strategies[domain]; ok {
+		platform.ScrapeStrategy = strategy
+	} else if strategy, ok := s.strategies[platform.Domain]; ok {
+		platform.ScrapeStrategy = strategy
+	}
+	return &platform
+}
+
+// scrapeStrategy picks how to fetch a domain: a configured override wins,
+// then the registry entry, then plain HTML scraping.
+func (s *linkMetadataService) scrapeStrategy(domain string, platform *PlatformInfo) string {
+	if strategy, ok := s.strategies[domain]; ok {
+		return strategy
+	}
+	if platform != nil && platform.ScrapeStrategy != "" {
+		return platform.ScrapeStrategy
+	}
+	return ScrapeStrategyHTML
+}
+
+func (s *linkMetadataService) userAgentFor(platform *PlatformInfo) string {
+	if platform != nil && platform.UserAgent != "" {
+		return platform.UserAgent
+	}
+	return s.userAgent
+}
+
+// isHTMLContentType reports whether a response is worth parsing for
+// metadata tags. A missing Content-Type is given the benefit of the doubt.
+func isHTMLContentType(contentType string) bool {
+	if strings.TrimSpace(contentType) == "" {
+		return true
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return mediaType == "text/html" || mediaType == "application/xhtml+xml"
+}
+
+// resolveImage walks the configured fallback chain and returns the first
+// image found along with the name of the source that produced it.
+func (s *linkMetadataService) resolveImage(metadata HTMLMetadata, domain string) (*string, *string) {
+	for _, source := range s.imageChain {
+		var candidate string
+
+		switch source {
+		case ImageSourceOpenGraph:
+			candidate = metadata.OGImageURL
+		case ImageSourceTwitter:
+			candidate = metadata.TwitterImageURL
+		case ImageSourceLargestImage:
+			candidate = metadata.LargestImageURL
+		case ImageSourcePlatformIcon:
+			if platform, found := s.platforms.lookup(domain); found {
+				candidate = platform.Icon
+			}
+		case ImageSourcePlaceholder:
+			candidate = generatePlaceholderImage(domain, s.platform(domain))
+		}
+
+		if candidate != "" {
+			src := source
+			s.logger.Debugf("Using %s as image for domain %s", source, domain)
+			return &candidate, &src
+		}
+	}
+
+	return nil, nil
+}
+
 func (s *linkMetadataService) IsKnownPlatform(domain string) bool {
-	_, found := PlatformRegistry[domain]
+	_, found := s.platforms.lookup(domain)
 	return found
 }
 
 func (s *linkMetadataService) GetPlatformInfo(domain string) *PlatformInfo {
-	if platform, found := PlatformRegistry[domain]; found {
-		return &platform
-	}
-	return nil
+	return s.platform(domain)
 }
 
 func (s *linkMetadataService) ListKnownPlatforms(ctx context.Context) ([]*PlatformInfo, error) {
-	platforms := make([]*PlatformInfo, 0, len(PlatformRegistry))
+	registry := s.platforms.list()
+	platforms := make([]*PlatformInfo, 0, len(registry))
 
-	for _, platform := range PlatformRegistry {
-		platformCopy := platform // Create a copy to avoid reference issues
-		platforms = append(platforms, &platformCopy)
+	for _, info := range registry {
+		if platform := s.platform(info.Domain); platform != nil {
+			platforms = append(platforms, platform)
+		}
 	}
 
 	return platforms, nil
@@ -354,12 +696,24 @@ func (s *linkMetadataService) ListKnownPlatforms(ctx context.Context) ([]*Platfo
 
 // Helper functions
 type HTMLMetadata struct {
-	Title       string
-	Description string
-	FaviconURL  string
-	ImageURL    string
+	Title           string
+	Description     string
+	FaviconURL      string
+	OGImageURL      string
+	TwitterImageURL string
+	LargestImageURL string
+
+	// CanonicalURL is where the fetch ended up after following redirects
+	CanonicalURL string
 }
 
+// Only the first few images in the body are considered "above the fold", and
+// anything smaller than this is most likely an icon or tracking pixel.
+const (
+	maxAboveFoldImages = 10
+	minImageDimension  = 100
+)
+
 func extractMetadata(n *html.Node, baseURL string) HTMLMetadata {
 	var metadata HTMLMetadata
 
@@ -397,11 +751,9 @@ func extractMetadata(n *html.Node, baseURL string) HTMLMetadata {
 						metadata.Description = content
 					}
 				case "og:image":
-					metadata.ImageURL = resolveURL(baseURL, content)
+					metadata.OGImageURL = resolveURL(baseURL, content)
 				case "twitter:image":
-					if metadata.ImageURL == "" {
-						metadata.ImageURL = resolveURL(baseURL, content)
-					}
+					metadata.TwitterImageURL = resolveURL(baseURL, content)
 				}
 			case atom.Link:
 				// Check for favicon
@@ -445,9 +797,83 @@ func extractMetadata(n *html.Node, baseURL string) HTMLMetadata {
 	}
 
 	findHead(n)
+	metadata.LargestImageURL = findLargestImage(n, baseURL)
 	return metadata
 }
 
+// findLargestImage returns the largest sized <img> among the first few in the document
+func findLargestImage(n *html.Node, baseURL string) string {
+	var (
+		largest     string
+		largestArea int
+		seen        int
+	)
+
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if seen >= maxAboveFoldImages {
+			return
+		}
+
+		if n.Type == html.ElementNode && n.DataAtom == atom.Img {
+			seen++
+
+			var src string
+			var width, height int
+			for _, attr := range n.Attr {
+				switch attr.Key {
+				case "src":
+					src = attr.Val
+				case "width":
+					width, _ = strconv.Atoi(strings.TrimSuffix(attr.Val, "px"))
+				case "height":
+					height, _ = strconv.Atoi(strings.TrimSuffix(attr.Val, "px"))
+				}
+			}
+
+			if src != "" && !strings.HasPrefix(src, "data:") &&
+				width >= minImageDimension && height >= minImageDimension &&
+				width*height > largestArea {
+				largest = resolveURL(baseURL, src)
+				largestArea = width * height
+			}
+		}
+
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+
+	walk(n)
+	return largest
+}
+
+// generatePlaceholderImage builds an inline SVG card showing the domain's
+// initial, in the platform's color when the domain belongs to one
+func generatePlaceholderImage(domain string, platform *PlatformInfo) string {
+	label := "?"
+	trimmed := strings.TrimPrefix(domain, "www.")
+	if trimmed != "" {
+		label = strings.ToUpper(trimmed[:1])
+	}
+
+	color := "#6B7280"
+	if platform != nil {
+		color = platform.Color
+	} else if domain != "" {
+		h := fnv.New32a()
+		h.Write([]byte(domain))
+		color = fmt.Sprintf("#%06X", h.Sum32()&0x7F7F7F)
+	}
+
+	svg := fmt.Sprintf(`<svg xmlns="http://www.w3.org/2000/svg" width="1200" height="630">`+
+		`<rect width="100%%" height="100%%" fill="%s"/>`+
+		`<text x="50%%" y="50%%" dy=".35em" text-anchor="middle" font-family="sans-serif" font-size="280" fill="#FFFFFF">%s</text>`+
+		`</svg>`, color, html.EscapeString(label))
+
+	return "data:image/svg+xml;base64," + base64.StdEncoding.EncodeToString([]byte(svg))
+}
+
 func extractTextContent(n *html.Node) string {
 	var text string
 	for c := n.FirstChild; c != nil; c = c.NextSibling {
@@ -475,24 +901,6 @@ func resolveURL(base, ref string) string {
 	return resolvedURL.String()
 }
 
-func normalizeURL(urlString string) (string, error) {
-	// Add scheme if missing
-	if !strings.HasPrefix(urlString, "http://") && !strings.HasPrefix(urlString, "https://") {
-		urlString = "https://" + urlString
-	}
-
-	parsedURL, err := url.Parse(urlString)
-	if err != nil {
-		return "", err
-	}
-
-	// Clean up URL by removing unnecessary parts
-	parsedURL.RawQuery = ""
-	parsedURL.Fragment = ""
-
-	return parsedURL.String(), nil
-}
-
 func mapLinkMetadataToDTO(metadata *db.LinkMetadatum) *LinkMetadataDTO {
 	dto := &LinkMetadataDTO{
 		ID:         metadata.MetadataID.String(),
@@ -517,6 +925,10 @@ func mapLinkMetadataToDTO(metadata *db.LinkMetadatum) *LinkMetadataDTO {
 		dto.ImageURL = *metadata.ImageUrl
 	}
 
+	if metadata.ImageSource != nil {
+		dto.ImageSource = *metadata.ImageSource
+	}
+
 	if metadata.PlatformName != nil {
 		dto.PlatformName = *metadata.PlatformName
 	}
@@ -529,6 +941,10 @@ func mapLinkMetadataToDTO(metadata *db.LinkMetadatum) *LinkMetadataDTO {
 		dto.PlatformColor = *metadata.PlatformColor
 	}
 
+	if metadata.CanonicalUrl != nil {
+		dto.CanonicalURL = *metadata.CanonicalUrl
+	}
+
 	if metadata.CreatedAt != nil {
 		dto.CreatedAt = metadata.CreatedAt.Format(time.RFC3339)
 	}
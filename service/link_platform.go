@@ -0,0 +1,386 @@
+package service
+
+import (
+	"context"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	db "github.com/0xsj/mios.io/db/sqlc"
+	"github.com/0xsj/mios.io/pkg/errors"
+	"github.com/0xsj/mios.io/repository"
+)
+
+// platformAliases maps short and alternate domains to the registry domain
+// of the platform they belong to
+var platformAliases = map[string]string{
+	"youtu.be":   "youtube.com",
+	"fb.com":     "facebook.com",
+	"fb.me":      "facebook.com",
+	"instagr.am": "instagram.com",
+	"lnkd.in":    "linkedin.com",
+	"spoti.fi":   "spotify.com",
+}
+
+// PlatformDetectionDTO is the registry entry a URL belongs to, if any.
+// Domain is the registry domain the URL's host was matched to.
+type PlatformDetectionDTO struct {
+	Known  bool   `json:"known"`
+	Domain string `json:"domain,omitempty"`
+	Name   string `json:"name,omitempty"`
+	Type   string `json:"type,omitempty"`
+	Color  string `json:"color,omitempty"`
+	Icon   string `json:"icon,omitempty"`
+}
+
+// platformCache is the in-memory platform registry. It starts out as the
+// built-in PlatformRegistry, is replaced by the platforms table once
+// LoadPlatforms succeeds, and is updated in place when an admin changes a
+// platform, so lookups never hit the database.
+type platformCache struct {
+	mu        sync.RWMutex
+	platforms map[string]PlatformInfo
+}
+
+func newPlatformCache(platforms map[string]PlatformInfo) *platformCache {
+	c := &platformCache{}
+	c.replace(platforms)
+	return c
+}
+
+// replace swaps in a new registry; the map is copied
+func (c *platformCache) replace(platforms map[string]PlatformInfo) {
+	copied := make(map[string]PlatformInfo, len(platforms))
+	for domain, info := range platforms {
+		copied[domain] = info
+	}
+
+	c.mu.Lock()
+	c.platforms = copied
+	c.mu.Unlock()
+}
+
+func (c *platformCache) put(info PlatformInfo) {
+	c.mu.Lock()
+	c.platforms[info.Domain] = info
+	c.mu.Unlock()
+}
+
+func (c *platformCache) remove(domain string) {
+	c.mu.Lock()
+	delete(c.platforms, domain)
+	c.mu.Unlock()
+}
+
+func (c *platformCache) get(domain string) (PlatformInfo, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	info, found := c.platforms[domain]
+	return info, found
+}
+
+// list returns every platform ordered by domain
+func (c *platformCache) list() []PlatformInfo {
+	c.mu.RLock()
+	platforms := make([]PlatformInfo, 0, len(c.platforms))
+	for _, info := range c.platforms {
+		platforms = append(platforms, info)
+	}
+	c.mu.RUnlock()
+
+	sort.Slice(platforms, func(i, j int) bool {
+		return platforms[i].Domain < platforms[j].Domain
+	})
+	return platforms
+}
+
+// domainFor returns the registry domain a host belongs to, or "" when it
+// matches no platform. Hosts are lowercased, aliases such as youtu.be are
+// resolved, and subdomains are stripped one label at a time, so
+// www.youtube.com and m.youtube.com both match youtube.com.
+func (c *platformCache) domainFor(host string) string {
+	host = normalizePlatformDomain(host)
+
+	for strings.Contains(host, ".") {
+		if alias, ok := platformAliases[host]; ok {
+			host = alias
+		}
+		if _, found := c.get(host); found {
+			return host
+		}
+		_, host, _ = strings.Cut(host, ".")
+	}
+
+	return ""
+}
+
+// lookup returns the registry entry for a host, see domainFor
+func (c *platformCache) lookup(host string) (PlatformInfo, bool) {
+	domain := c.domainFor(host)
+	if domain == "" {
+		return PlatformInfo{}, false
+	}
+	return c.get(domain)
+}
+
+func normalizePlatformDomain(domain string) string {
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(domain)), ".")
+}
+
+// DetectPlatform matches a URL's domain against the platform registry
+// without any outbound request, so clients can show platform branding while
+// the user is still typing a link. A missing scheme is assumed to be https.
+func (s *linkMetadataService) DetectPlatform(urlString string) (*PlatformDetectionDTO, error) {
+	urlString = strings.TrimSpace(urlString)
+	if !strings.Contains(urlString, "://") {
+		urlString = "https://" + urlString
+	}
+
+	parsedURL, err := url.Parse(urlString)
+	if err != nil || parsedURL.Hostname() == "" {
+		s.logger.Debugf("Cannot detect platform for invalid URL %q: %v", urlString, err)
+		return nil, errors.NewValidationError("Invalid URL format", err)
+	}
+
+	platform := s.platform(parsedURL.Hostname())
+	if platform == nil {
+		return &PlatformDetectionDTO{Known: false}, nil
+	}
+
+	return &PlatformDetectionDTO{
+		Known:  true,
+		Domain: platform.Domain,
+		Name:   platform.Name,
+		Type:   platform.Type,
+		Color:  platform.Color,
+		Icon:   platform.Icon,
+	}, nil
+}
+
+var (
+	platformDomainPattern = regexp.MustCompile(`^([a-z0-9]([a-z0-9-]*[a-z0-9])?\.)+[a-z]{2,}$`)
+	platformColorPattern  = regexp.MustCompile(`^#[0-9A-Fa-f]{6}$`)
+)
+
+// CreatePlatformInput is a new registry entry. Optional fields may be empty.
+type CreatePlatformInput struct {
+	Domain         string
+	Name           string
+	Type           string
+	Color          string
+	Icon           string
+	URLTemplate    string
+	ScrapeStrategy string
+	OEmbedEndpoint string
+	UserAgent      string
+	QueryKeys      []string
+}
+
+// UpdatePlatformInput changes the fields of a registry entry that are not
+// nil; an empty string clears an optional field, and an empty QueryKeys
+// clears the query keys
+type UpdatePlatformInput struct {
+	Name           *string
+	Type           *string
+	Color          *string
+	Icon           *string
+	URLTemplate    *string
+	ScrapeStrategy *string
+	OEmbedEndpoint *string
+	UserAgent      *string
+	QueryKeys      []string
+}
+
+// LoadPlatforms replaces the in-memory registry with the platforms table.
+// On error the registry is left as it was.
+func (s *linkMetadataService) LoadPlatforms(ctx context.Context) error {
+	rows, err := s.repo.ListPlatforms(ctx)
+	if err != nil {
+		s.logger.Errorf("Failed to load platforms: %v", err)
+		return err
+	}
+
+	platforms := make(map[string]PlatformInfo, len(rows))
+	for _, row := range rows {
+		platforms[row.Domain] = mapPlatformToInfo(row)
+	}
+	s.platforms.replace(platforms)
+
+	s.logger.Infof("Loaded %d platforms", len(platforms))
+	return nil
+}
+
+func (s *linkMetadataService) CreatePlatform(ctx context.Context, input CreatePlatformInput) (*PlatformInfo, error) {
+	info := PlatformInfo{
+		Domain:         normalizePlatformDomain(input.Domain),
+		Name:           strings.TrimSpace(input.Name),
+		Type:           strings.TrimSpace(input.Type),
+		Color:          strings.TrimSpace(input.Color),
+		Icon:           strings.TrimSpace(input.Icon),
+		URLTemplate:    strings.TrimSpace(input.URLTemplate),
+		ScrapeStrategy: strings.ToLower(strings.TrimSpace(input.ScrapeStrategy)),
+		OEmbedEndpoint: strings.TrimSpace(input.OEmbedEndpoint),
+		UserAgent:      strings.TrimSpace(input.UserAgent),
+		QueryKeys:      normalizeQueryKeys(input.QueryKeys),
+	}
+	if err := validatePlatform(info); err != nil {
+		return nil, err
+	}
+
+	row, err := s.repo.CreatePlatform(ctx, repository.CreatePlatformParams{
+		Domain:         info.Domain,
+		Name:           info.Name,
+		Type:           info.Type,
+		Color:          info.Color,
+		Icon:           info.Icon,
+		URLTemplate:    info.URLTemplate,
+		ScrapeStrategy: info.ScrapeStrategy,
+		OEmbedEndpoint: info.OEmbedEndpoint,
+		UserAgent:      info.UserAgent,
+		QueryKeys:      info.QueryKeys,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	created := mapPlatformToInfo(row)
+	s.platforms.put(created)
+
+	s.logger.Infof("Platform %s added to the registry", created.Domain)
+	return &created, nil
+}
+
+func (s *linkMetadataService) UpdatePlatform(ctx context.Context, domain string, input UpdatePlatformInput) (*PlatformInfo, error) {
+	domain = normalizePlatformDomain(domain)
+	current, found := s.platforms.get(domain)
+	if !found {
+		return nil, errors.NewNotFoundError("Platform not found", nil)
+	}
+
+	trim := func(value *string) *string {
+		if value == nil {
+			return nil
+		}
+		trimmed := strings.TrimSpace(*value)
+		return &trimmed
+	}
+	params := repository.UpdatePlatformParams{
+		Domain:         domain,
+		Name:           trim(input.Name),
+		Type:           trim(input.Type),
+		Color:          trim(input.Color),
+		Icon:           trim(input.Icon),
+		URLTemplate:    trim(input.URLTemplate),
+		ScrapeStrategy: trim(input.ScrapeStrategy),
+		OEmbedEndpoint: trim(input.OEmbedEndpoint),
+		UserAgent:      trim(input.UserAgent),
+	}
+	if params.ScrapeStrategy != nil {
+		*params.ScrapeStrategy = strings.ToLower(*params.ScrapeStrategy)
+	}
+	if input.QueryKeys != nil {
+		params.QueryKeys = normalizeQueryKeys(input.QueryKeys)
+	}
+
+	// Validate the entry as it will be stored, not just the changed fields
+	merged := current
+	for _, field := range []struct {
+		dest  *string
+		value *string
+	}{
+		{&merged.Name, params.Name},
+		{&merged.Type, params.Type},
+		{&merged.Color, params.Color},
+		{&merged.Icon, params.Icon},
+		{&merged.URLTemplate, params.URLTemplate},
+		{&merged.ScrapeStrategy, params.ScrapeStrategy},
+		{&merged.OEmbedEndpoint, params.OEmbedEndpoint},
+		{&merged.UserAgent, params.UserAgent},
+	} {
+		if field.value != nil {
+			*field.dest = *field.value
+		}
+	}
+	if params.QueryKeys != nil {
+		merged.QueryKeys = params.QueryKeys
+	}
+	if err := validatePlatform(merged); err != nil {
+		return nil, err
+	}
+
+	row, err := s.repo.UpdatePlatform(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	updated := mapPlatformToInfo(row)
+	s.platforms.put(updated)
+
+	s.logger.Infof("Platform %s updated in the registry", updated.Domain)
+	return &updated, nil
+}
+
+func (s *linkMetadataService) DeletePlatform(ctx context.Context, domain string) error {
+	domain = normalizePlatformDomain(domain)
+	if err := s.repo.DeletePlatform(ctx, domain); err != nil {
+		return err
+	}
+
+	s.platforms.remove(domain)
+
+	s.logger.Infof("Platform %s removed from the registry", domain)
+	return nil
+}
+
+func validatePlatform(info PlatformInfo) error {
+	if !platformDomainPattern.MatchString(info.Domain) {
+		return errors.NewValidationError("Domain must be a hostname such as example.com", nil)
+	}
+	if _, aliased := platformAliases[info.Domain]; aliased {
+		return errors.NewValidationError("Domain is an alias of another platform", nil)
+	}
+	if info.Name == "" {
+		return errors.NewValidationError("Name is required", nil)
+	}
+	if info.Type == "" {
+		return errors.NewValidationError("Type is required", nil)
+	}
+	if !platformColorPattern.MatchString(info.Color) {
+		return errors.NewValidationError("Color must be a hex color such as #1DA1F2", nil)
+	}
+	if info.ScrapeStrategy != "" && !knownScrapeStrategies[info.ScrapeStrategy] {
+		return errors.NewValidationError("Unknown scrape strategy, expected one of html, oembed, api, skip", nil)
+	}
+	if info.OEmbedEndpoint != "" {
+		endpoint, err := url.Parse(info.OEmbedEndpoint)
+		if err != nil || (endpoint.Scheme != "http" && endpoint.Scheme != "https") || endpoint.Host == "" {
+			return errors.NewValidationError("oEmbed endpoint must be an http or https URL", err)
+		}
+	}
+	if info.ScrapeStrategy == ScrapeStrategyOEmbed && info.OEmbedEndpoint == "" {
+		return errors.NewValidationError("The oembed scrape strategy needs an oEmbed endpoint", nil)
+	}
+	for _, key := range info.QueryKeys {
+		if strings.ContainsAny(key, "&=#?") {
+			return errors.NewValidationError("Query keys must be bare parameter names such as v", nil)
+		}
+	}
+	return nil
+}
+
+func mapPlatformToInfo(platform *db.Platform) PlatformInfo {
+	return PlatformInfo{
+		Domain:         platform.Domain,
+		Name:           platform.Name,
+		Type:           platform.Type,
+		Color:          platform.Color,
+		Icon:           platform.Icon,
+		URLTemplate:    platform.UrlTemplate,
+		ScrapeStrategy: platform.ScrapeStrategy,
+		OEmbedEndpoint: platform.OembedEndpoint,
+		UserAgent:      platform.UserAgent,
+		QueryKeys:      platform.QueryKeys,
+	}
+}
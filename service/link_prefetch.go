@@ -0,0 +1,163 @@
+// service/link_prefetch.go
+package service
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	db "github.com/0xsj/mios.io/db/sqlc"
+	"github.com/0xsj/mios.io/log"
+)
+
+const (
+	defaultPrefetchConcurrency = 4
+
+	// prefetchTimeout bounds one background fetch, including storing it
+	prefetchTimeout = 30 * time.Second
+
+	// After prefetchBreakerThreshold failures in a row prefetching pauses
+	// for prefetchBreakerCooldown; links are still fetched lazily meanwhile
+	prefetchBreakerThreshold = 5
+	prefetchBreakerCooldown  = time.Minute
+)
+
+// LinkPrefetcher warms link metadata in the background when a content item
+// gets a link, so the first profile render does not wait on the scrape.
+// Prefetching is best effort: when every slot is busy, the URL is already
+// being fetched or the breaker is open the URL is skipped and left to the
+// lazy fetch on first view.
+type LinkPrefetcher struct {
+	metadata LinkMetadataService
+	slots    chan struct{}
+	logger   log.Logger
+
+	mu          sync.Mutex
+	inFlight    map[string]bool
+	failures    int
+	pausedUntil time.Time
+}
+
+func NewLinkPrefetcher(metadata LinkMetadataService, concurrency int, logger log.Logger) *LinkPrefetcher {
+	if concurrency <= 0 {
+		concurrency = defaultPrefetchConcurrency
+	}
+
+	return &LinkPrefetcher{
+		metadata: metadata,
+		slots:    make(chan struct{}, concurrency),
+		logger:   logger,
+		inFlight: make(map[string]bool),
+	}
+}
+
+// Prefetch starts fetching metadata for urlString and returns immediately.
+// It is safe to call on a nil prefetcher.
+func (p *LinkPrefetcher) Prefetch(urlString string) {
+	if p == nil || urlString == "" {
+		return
+	}
+
+	// FetchAndStoreMetadata applies the platform's query keys
+	normalizedURL, err := normalizeURL(urlString, nil)
+	if err != nil {
+		p.logger.Debugf("Not prefetching invalid URL %q: %v", urlString, err)
+		return
+	}
+
+	if !p.begin(normalizedURL) {
+		return
+	}
+
+	select {
+	case p.slots <- struct{}{}:
+	default:
+		p.logger.Debugf("Prefetch slots busy, leaving %s to be fetched on first view", normalizedURL)
+		p.finish(normalizedURL, nil)
+		return
+	}
+
+	go func() {
+		defer func() { <-p.slots }()
+
+		ctx, cancel := context.WithTimeout(context.Background(), prefetchTimeout)
+		defer cancel()
+
+		_, err := p.metadata.FetchAndStoreMetadata(ctx, normalizedURL)
+		if err != nil {
+			p.logger.Warnf("Failed to prefetch metadata for URL %s: %v", normalizedURL, err)
+		} else {
+			p.logger.Debugf("Prefetched metadata for URL: %s", normalizedURL)
+		}
+		p.finish(normalizedURL, err)
+	}()
+}
+
+// Wait blocks until every started prefetch has finished
+func (p *LinkPrefetcher) Wait() {
+	for i := 0; i < cap(p.slots); i++ {
+		p.slots <- struct{}{}
+	}
+	for i := 0; i < cap(p.slots); i++ {
+		<-p.slots
+	}
+}
+
+// begin claims a URL unless it is already being fetched or the breaker is
+// open
+func (p *LinkPrefetcher) begin(normalizedURL string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if time.Now().Before(p.pausedUntil) {
+		p.logger.Debugf("Prefetching paused after repeated failures, skipping %s", normalizedURL)
+		return false
+	}
+	if p.inFlight[normalizedURL] {
+		return false
+	}
+	p.inFlight[normalizedURL] = true
+	return true
+}
+
+func (p *LinkPrefetcher) finish(normalizedURL string, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	delete(p.inFlight, normalizedURL)
+	if err == nil {
+		p.failures = 0
+		return
+	}
+
+	p.failures++
+	if p.failures >= prefetchBreakerThreshold {
+		p.logger.Warnf("Pausing link prefetching for %v after %d failures in a row", prefetchBreakerCooldown, p.failures)
+		p.pausedUntil = time.Now().Add(prefetchBreakerCooldown)
+		p.failures = 0
+	}
+}
+
+// linkTarget is the web address a content item links to, matching the
+// COALESCE(href, url) the link health checks use. Links that are not web
+// pages (mailto:, tel:) have no metadata to fetch.
+func linkTarget(item *db.ContentItem) string {
+	for _, target := range []*string{item.Href, item.Url} {
+		if target == nil || strings.TrimSpace(*target) == "" {
+			continue
+		}
+		trimmed := strings.TrimSpace(*target)
+		parsed, err := url.Parse(trimmed)
+		if err != nil {
+			return ""
+		}
+		switch strings.ToLower(parsed.Scheme) {
+		case "", "http", "https":
+			return trimmed
+		}
+		return ""
+	}
+	return ""
+}
@@ -0,0 +1,101 @@
+package service
+
+import (
+	"net/url"
+	"strings"
+)
+
+// trackingQueryParams are query parameters that only record how a visitor
+// arrived, never what the link points to. Parameters starting with "utm_"
+// are dropped as well.
+var trackingQueryParams = map[string]bool{
+	"fbclid":  true,
+	"gclid":   true,
+	"dclid":   true,
+	"msclkid": true,
+	"yclid":   true,
+	"twclid":  true,
+	"ttclid":  true,
+	"igshid":  true,
+	"mc_cid":  true,
+	"mc_eid":  true,
+	"_ga":     true,
+	"ref":     true,
+	"ref_src": true,
+}
+
+func isTrackingQueryParam(key string) bool {
+	key = strings.ToLower(key)
+	return strings.HasPrefix(key, "utm_") || trackingQueryParams[key]
+}
+
+// normalizeURL returns the URL link metadata is stored under: https is
+// assumed when the scheme is missing, the fragment is dropped, and so are
+// query parameters that do not change what the link points to (see
+// stripInsignificantQuery). platformFor finds the registry entry for a
+// host; when nil, only tracking parameters are dropped.
+func normalizeURL(urlString string, platformFor func(host string) *PlatformInfo) (string, error) {
+	// Add scheme if missing
+	if !strings.HasPrefix(urlString, "http://") && !strings.HasPrefix(urlString, "https://") {
+		urlString = "https://" + urlString
+	}
+
+	parsedURL, err := url.Parse(urlString)
+	if err != nil {
+		return "", err
+	}
+
+	var platform *PlatformInfo
+	if platformFor != nil {
+		platform = platformFor(parsedURL.Hostname())
+	}
+	stripInsignificantQuery(parsedURL, platform)
+
+	return parsedURL.String(), nil
+}
+
+// stripInsignificantQuery drops the fragment and every query parameter
+// that does not identify the linked resource. Platforms with QueryKeys keep
+// only those, so youtube.com/watch?v=abc&feature=share becomes
+// youtube.com/watch?v=abc; elsewhere everything but tracking parameters is
+// kept. The remaining parameters are sorted so equivalent URLs match.
+func stripInsignificantQuery(parsedURL *url.URL, platform *PlatformInfo) {
+	parsedURL.Fragment = ""
+	if parsedURL.RawQuery == "" {
+		return
+	}
+
+	query := parsedURL.Query()
+	kept := make(url.Values, len(query))
+	if platform != nil && len(platform.QueryKeys) > 0 {
+		for _, key := range platform.QueryKeys {
+			if values, ok := query[key]; ok {
+				kept[key] = values
+			}
+		}
+	} else {
+		for key, values := range query {
+			if !isTrackingQueryParam(key) {
+				kept[key] = values
+			}
+		}
+	}
+
+	parsedURL.RawQuery = kept.Encode()
+}
+
+// normalizeQueryKeys trims and dedupes configured query keys. It never
+// returns nil, so the result can be stored as is.
+func normalizeQueryKeys(keys []string) []string {
+	normalized := make([]string, 0, len(keys))
+	seen := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		key = strings.TrimSpace(key)
+		if key == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		normalized = append(normalized, key)
+	}
+	return normalized
+}
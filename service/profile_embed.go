@@ -0,0 +1,91 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	apperror "github.com/0xsj/mios.io/pkg/errors"
+)
+
+// EmbedChannel is the referrer the embed widget records its page views and
+// clicks with, so embedded traffic shows up as its own channel
+const EmbedChannel = "embed"
+
+// ProfileEmbedDTO is what the embed widget renders: the profile's published
+// links, in profile order. UserID is needed to record analytics events.
+type ProfileEmbedDTO struct {
+	Handle      string          `json:"handle"`
+	DisplayName string          `json:"display_name"`
+	UserID      string          `json:"user_id"`
+	Links       []*EmbedLinkDTO `json:"links"`
+}
+
+// EmbedLinkDTO is one link in the embed widget
+type EmbedLinkDTO struct {
+	ItemID string `json:"item_id"`
+	Title  string `json:"title"`
+	URL    string `json:"url"`
+}
+
+// GetEmbedProfile returns the active items of a public profile that link
+// somewhere. Drafts, inactive and unpublished items and items without a
+// target are left out.
+func (s *profileService) GetEmbedProfile(ctx context.Context, handle string) (*ProfileEmbedDTO, error) {
+	s.logger.Debugf("Getting embed profile for handle: %s", handle)
+
+	user, err := s.userRepo.GetUserByHandle(ctx, handle)
+	if err != nil {
+		if apperror.IsNotFound(err) {
+			return nil, apperror.NewNotFoundError("Profile not found", err)
+		}
+		s.logger.Errorf("Failed to get user by handle %s: %v", handle, err)
+		return nil, err
+	}
+
+	// Profiles that haven't finished onboarding are not public yet, and
+	// private ones never are
+	if user.Onboarded == nil || !*user.Onboarded || isHiddenFrom(user, "") {
+		s.logger.Debugf("Profile %s is not public, hiding embed", handle)
+		return nil, apperror.NewNotFoundError("Profile not found", nil)
+	}
+
+	items, err := s.contentRepo.GetPublishedUserContentItems(ctx, user.UserID)
+	if err != nil {
+		s.logger.Errorf("Failed to get content items for user %s: %v", user.UserID, err)
+		return nil, err
+	}
+	sortContentItems(items, s.contentConfig.FallbackOrder)
+
+	dto := &ProfileEmbedDTO{
+		Handle:      user.Handle,
+		DisplayName: profileDisplayName(user),
+		UserID:      user.UserID.String(),
+		Links:       make([]*EmbedLinkDTO, 0, len(items)),
+	}
+	for _, item := range items {
+		if item.IsActive == nil || !*item.IsActive {
+			continue
+		}
+
+		target := strings.TrimSpace(getValueOrEmpty(item.Href))
+		if target == "" {
+			target = strings.TrimSpace(getValueOrEmpty(item.Url))
+		}
+		if target == "" {
+			continue
+		}
+
+		title := strings.TrimSpace(getValueOrEmpty(item.Title))
+		if title == "" {
+			title = target
+		}
+		dto.Links = append(dto.Links, &EmbedLinkDTO{
+			ItemID: item.ItemID.String(),
+			Title:  title,
+			URL:    target,
+		})
+	}
+
+	s.logger.Debugf("Retrieved %d embed links for handle: %s", len(dto.Links), handle)
+	return dto, nil
+}
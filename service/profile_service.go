@@ -0,0 +1,386 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	db "github.com/0xsj/mios.io/db/sqlc"
+	"github.com/0xsj/mios.io/log"
+	apperror "github.com/0xsj/mios.io/pkg/errors"
+	"github.com/0xsj/mios.io/repository"
+	"github.com/google/uuid"
+)
+
+type ProfileService interface {
+	GetProfileMeta(ctx context.Context, handle string) (*ProfileMetaDTO, error)
+	ResolveCanonical(ctx context.Context, requestedHandleOrDomain string) (canonical string, redirect bool, err error)
+
+	// Embedding
+	GetEmbedProfile(ctx context.Context, handle string) (*ProfileEmbedDTO, error)
+
+	// Templates
+	ListTemplates(ctx context.Context) ([]*ProfileTemplateDTO, error)
+	CopyFromTemplate(ctx context.Context, userID, templateID string) error
+	SetTemplate(ctx context.Context, userID string, isTemplate bool) error
+}
+
+// ProfileMetaDTO is the lightweight, crawler-facing view of a profile
+type ProfileMetaDTO struct {
+	Handle      string `json:"handle"`
+	DisplayName string `json:"display_name"`
+	Bio         string `json:"bio,omitempty"`
+	AvatarURL   string `json:"avatar_url,omitempty"`
+	IsVerified  bool   `json:"is_verified"`
+	LinkCount   int64  `json:"link_count"`
+	UpdatedAt   string `json:"updated_at,omitempty"`
+}
+
+// ProfileConfig controls how profile URLs are served
+type ProfileConfig struct {
+	// CanonicalRedirects makes non-canonical profile URLs (old handles,
+	// custom domains) answer with a redirect instead of the profile itself
+	CanonicalRedirects bool
+}
+
+// ProfileTemplateDTO describes a curated profile new users can start from
+type ProfileTemplateDTO struct {
+	TemplateID  string `json:"template_id"`
+	Handle      string `json:"handle"`
+	DisplayName string `json:"display_name"`
+	Bio         string `json:"bio,omitempty"`
+	AvatarURL   string `json:"avatar_url,omitempty"`
+	ItemCount   int64  `json:"item_count"`
+}
+
+type profileService struct {
+	userRepo      repository.UserRepository
+	authRepo      repository.AuthRepository
+	contentRepo   repository.ContentRepository
+	contentConfig ContentConfig
+	config        ProfileConfig
+	logger        log.Logger
+}
+
+func NewProfileService(
+	userRepo repository.UserRepository,
+	authRepo repository.AuthRepository,
+	contentRepo repository.ContentRepository,
+	contentConfig ContentConfig,
+	config ProfileConfig,
+	logger log.Logger,
+) ProfileService {
+	return &profileService{
+		userRepo:      userRepo,
+		authRepo:      authRepo,
+		contentRepo:   contentRepo,
+		contentConfig: contentConfig,
+		config:        config,
+		logger:        logger,
+	}
+}
+
+func (s *profileService) GetProfileMeta(ctx context.Context, handle string) (*ProfileMetaDTO, error) {
+	s.logger.Debugf("Getting profile metadata for handle: %s", handle)
+
+	user, err := s.userRepo.GetUserByHandle(ctx, handle)
+	if err != nil {
+		if apperror.IsNotFound(err) {
+			return nil, apperror.NewNotFoundError("Profile not found", err)
+		}
+		s.logger.Errorf("Failed to get user by handle %s: %v", handle, err)
+		return nil, err
+	}
+
+	// Profiles that haven't finished onboarding are not public yet, and
+	// private ones never are. Metadata is what search engines index, so
+	// unlisted profiles don't get any either.
+	if user.Onboarded == nil || !*user.Onboarded || isHiddenFrom(user, "") || user.Visibility == VisibilityUnlisted {
+		s.logger.Debugf("Profile %s is not public, hiding metadata", handle)
+		return nil, apperror.NewNotFoundError("Profile not found", nil)
+	}
+
+	linkCount, err := s.contentRepo.CountActiveUserContentItems(ctx, user.UserID)
+	if err != nil {
+		s.logger.Errorf("Failed to count content items for user %s: %v", user.UserID, err)
+		return nil, err
+	}
+
+	isVerified := false
+	auth, err := s.authRepo.GetAuthByUserID(ctx, user.UserID)
+	if err != nil {
+		s.logger.Warnf("Failed to get auth record for user %s, treating as unverified: %v", user.UserID, err)
+	} else if auth.IsEmailVerified != nil {
+		isVerified = *auth.IsEmailVerified
+	}
+
+	dto := &ProfileMetaDTO{
+		Handle:      user.Handle,
+		DisplayName: profileDisplayName(user),
+		IsVerified:  isVerified,
+		LinkCount:   linkCount,
+	}
+
+	if user.Bio != nil {
+		dto.Bio = *user.Bio
+	}
+
+	if user.ProfileImageUrl != nil {
+		dto.AvatarURL = *user.ProfileImageUrl
+	}
+
+	if user.UpdatedAt != nil {
+		dto.UpdatedAt = user.UpdatedAt.Format(time.RFC3339)
+	}
+
+	s.logger.Debugf("Retrieved profile metadata for handle: %s", handle)
+	return dto, nil
+}
+
+// ResolveCanonical maps a requested handle or custom domain to the profile's
+// current handle. Previous handles and custom domains resolve to that handle,
+// and redirect reports whether the caller should send the client there.
+// Custom domains only resolve once their owner has verified them, and private
+// profiles don't resolve at all.
+func (s *profileService) ResolveCanonical(ctx context.Context, requested string) (string, bool, error) {
+	s.logger.Debugf("Resolving canonical profile for: %s", requested)
+
+	requested = strings.TrimSpace(requested)
+	if requested == "" {
+		return "", false, apperror.NewBadRequestError("Handle cannot be empty", nil)
+	}
+
+	user, err := s.userRepo.GetUserByHandle(ctx, requested)
+	if err == nil {
+		if isHiddenFrom(user, "") {
+			return "", false, apperror.NewNotFoundError("Profile not found", nil)
+		}
+		return user.Handle, false, nil
+	}
+	if !apperror.IsNotFound(err) {
+		s.logger.Errorf("Failed to get user by handle %s: %v", requested, err)
+		return "", false, err
+	}
+
+	user, err = s.findByPreviousHandle(ctx, requested)
+	if err != nil {
+		return "", false, err
+	}
+
+	if user == nil && strings.Contains(requested, ".") {
+		domainUser, err := s.userRepo.GetUserByCustomDomain(ctx, requested)
+		if err == nil {
+			if domainUser.CustomDomainVerifiedAt != nil {
+				user = domainUser
+			}
+		} else if !apperror.IsNotFound(err) {
+			s.logger.Errorf("Failed to get user by custom domain %s: %v", requested, err)
+			return "", false, err
+		}
+	}
+
+	if user == nil || isHiddenFrom(user, "") {
+		return "", false, apperror.NewNotFoundError("Profile not found", nil)
+	}
+
+	s.logger.Debugf("Resolved %s to canonical handle %s", requested, user.Handle)
+	return user.Handle, s.config.CanonicalRedirects, nil
+}
+
+// findByPreviousHandle returns the user who used to own handle, or nil when
+// the handle has no history.
+func (s *profileService) findByPreviousHandle(ctx context.Context, handle string) (*db.User, error) {
+	userID, err := s.userRepo.GetUserIDByPreviousHandle(ctx, handle)
+	if err != nil {
+		if apperror.IsNotFound(err) {
+			return nil, nil
+		}
+		s.logger.Errorf("Failed to look up previous handle %s: %v", handle, err)
+		return nil, err
+	}
+
+	user, err := s.userRepo.GetUser(ctx, userID)
+	if err != nil {
+		if apperror.IsNotFound(err) {
+			return nil, nil
+		}
+		s.logger.Errorf("Failed to get user %s for previous handle %s: %v", userID, handle, err)
+		return nil, err
+	}
+	return user, nil
+}
+
+func (s *profileService) ListTemplates(ctx context.Context) ([]*ProfileTemplateDTO, error) {
+	s.logger.Debug("Listing profile templates")
+
+	templates, err := s.userRepo.ListTemplateUsers(ctx)
+	if err != nil {
+		s.logger.Errorf("Failed to list template profiles: %v", err)
+		return nil, err
+	}
+
+	result := make([]*ProfileTemplateDTO, 0, len(templates))
+	for _, template := range templates {
+		itemCount, err := s.contentRepo.CountActiveUserContentItems(ctx, template.UserID)
+		if err != nil {
+			s.logger.Errorf("Failed to count content items for template %s: %v", template.UserID, err)
+			return nil, err
+		}
+
+		result = append(result, &ProfileTemplateDTO{
+			TemplateID:  template.UserID.String(),
+			Handle:      template.Handle,
+			DisplayName: profileDisplayName(template),
+			Bio:         getValueOrEmpty(template.Bio),
+			AvatarURL:   getValueOrEmpty(template.ProfileImageUrl),
+			ItemCount:   itemCount,
+		})
+	}
+
+	s.logger.Debugf("Retrieved %d profile templates", len(result))
+	return result, nil
+}
+
+// CopyFromTemplate clones the active content items of a template profile
+// into the user's account, keeping positions, styles and URLs as a starting
+// point. Per-type content limits apply to the combined result.
+func (s *profileService) CopyFromTemplate(ctx context.Context, userID, templateID string) error {
+	s.logger.Infof("Copying template %s into account %s", templateID, userID)
+
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		return apperror.NewValidationError("Invalid user ID format", err)
+	}
+
+	templateUUID, err := uuid.Parse(templateID)
+	if err != nil {
+		return apperror.NewValidationError("Invalid template ID format", err)
+	}
+
+	if userUUID == templateUUID {
+		return apperror.NewBadRequestError("Cannot copy a profile into itself", nil)
+	}
+
+	template, err := s.userRepo.GetUser(ctx, templateUUID)
+	if err != nil {
+		if apperror.IsNotFound(err) {
+			return apperror.NewNotFoundError("Template not found", err)
+		}
+		s.logger.Errorf("Failed to get template %s: %v", templateID, err)
+		return err
+	}
+	if !template.IsTemplate {
+		return apperror.NewNotFoundError("Template not found", nil)
+	}
+
+	items, err := s.contentRepo.GetUserContentItems(ctx, templateUUID)
+	if err != nil {
+		s.logger.Errorf("Failed to get template items for %s: %v", templateID, err)
+		return err
+	}
+
+	if err := s.checkTemplateLimits(ctx, userUUID, items); err != nil {
+		return err
+	}
+
+	if err := s.checkTemplateFieldLengths(items); err != nil {
+		return err
+	}
+
+	copied, err := s.contentRepo.CopyContentItems(ctx, templateUUID, userUUID)
+	if err != nil {
+		s.logger.Errorf("Failed to copy template %s into account %s: %v", templateID, userID, err)
+		return apperror.Wrap(err, "Failed to copy template")
+	}
+
+	s.logger.Infof("Copied %d items from template %s into account %s", copied, templateID, userID)
+	return nil
+}
+
+// checkTemplateLimits rejects a copy that would push the user past a
+// configured per-type maximum.
+func (s *profileService) checkTemplateLimits(ctx context.Context, userID uuid.UUID, items []*db.ContentItem) error {
+	if len(s.contentConfig.TypeLimits) == 0 {
+		return nil
+	}
+
+	incoming := make(map[string]int64)
+	for _, item := range items {
+		if item.IsActive != nil && *item.IsActive {
+			incoming[item.ContentType]++
+		}
+	}
+
+	existing, err := s.contentRepo.CountUserContentItemsByType(ctx, userID)
+	if err != nil {
+		s.logger.Errorf("Failed to count content items for user %s: %v", userID, err)
+		return err
+	}
+
+	for contentType, count := range incoming {
+		limit, ok := s.contentConfig.TypeLimits[contentType]
+		if !ok || limit.Max <= 0 {
+			continue
+		}
+		if existing[contentType]+count > limit.Max {
+			s.logger.Warnf("Template copy would give user %s %d %s items (max %d)",
+				userID, existing[contentType]+count, contentType, limit.Max)
+			return apperror.NewValidationError(
+				fmt.Sprintf("Copying this template would exceed the limit of %d %s item(s)", limit.Max, contentType), nil)
+		}
+	}
+
+	return nil
+}
+
+// checkTemplateFieldLengths applies the content field limits to the items a
+// template copy would import, since they bypass CreateContentItem.
+func (s *profileService) checkTemplateFieldLengths(items []*db.ContentItem) error {
+	limits := s.contentConfig.FieldLimits
+
+	var checks []lengthCheck
+	for i, item := range items {
+		if item.IsActive == nil || !*item.IsActive {
+			continue
+		}
+		for _, check := range limits.contentChecks(item.Title, item.Href, item.Url) {
+			check.field = fmt.Sprintf("items[%d].%s", i, check.field)
+			checks = append(checks, check)
+		}
+	}
+
+	return validateFieldLengths(checks...)
+}
+
+func (s *profileService) SetTemplate(ctx context.Context, userID string, isTemplate bool) error {
+	s.logger.Infof("Setting template status for user %s to %v", userID, isTemplate)
+
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		return apperror.NewValidationError("Invalid user ID format", err)
+	}
+
+	if _, err := s.userRepo.GetUser(ctx, userUUID); err != nil {
+		if apperror.IsNotFound(err) {
+			return apperror.NewNotFoundError("User not found", err)
+		}
+		return err
+	}
+
+	if err := s.userRepo.UpdateTemplateStatus(ctx, userUUID, isTemplate); err != nil {
+		s.logger.Errorf("Failed to update template status for user %s: %v", userID, err)
+		return err
+	}
+
+	return nil
+}
+
+// profileDisplayName prefers the user's full name and falls back to the handle
+func profileDisplayName(user *db.User) string {
+	name := strings.TrimSpace(getValueOrEmpty(user.FirstName) + " " + getValueOrEmpty(user.LastName))
+	if name == "" {
+		return user.Handle
+	}
+	return name
+}
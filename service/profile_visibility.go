@@ -0,0 +1,67 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	db "github.com/0xsj/mios.io/db/sqlc"
+	apperror "github.com/0xsj/mios.io/pkg/errors"
+)
+
+// Profile visibilities. Unlisted profiles are served to anyone with the link
+// but kept out of listings; private ones only to their owner.
+const (
+	VisibilityPublic   = "public"
+	VisibilityUnlisted = "unlisted"
+	VisibilityPrivate  = "private"
+)
+
+func isValidVisibility(visibility string) bool {
+	switch visibility {
+	case VisibilityPublic, VisibilityUnlisted, VisibilityPrivate:
+		return true
+	}
+	return false
+}
+
+// isHiddenFrom reports whether user's profile must be answered with a 404 for
+// viewerID, which is empty for anonymous requests
+func isHiddenFrom(user *db.User, viewerID string) bool {
+	return user.Visibility == VisibilityPrivate && user.UserID.String() != viewerID
+}
+
+// VisibleTo reports whether the profile may be shown to viewerID, which is
+// empty for anonymous requests
+func (u *UserDTO) VisibleTo(viewerID string) bool {
+	return u.Visibility != VisibilityPrivate || u.ID == viewerID
+}
+
+func (s *userService) UpdateVisibility(ctx context.Context, id string, visibility string) (*UserDTO, error) {
+	s.logger.Infof("Updating visibility for user ID: %s to: %s", id, visibility)
+
+	userID, err := parseUUID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !isValidVisibility(visibility) {
+		return nil, handleValidationError("Visibility must be public, unlisted or private", nil)
+	}
+
+	start := time.Now()
+	err = s.userRepo.UpdateVisibility(ctx, userID, visibility)
+	if err != nil {
+		s.logger.Errorf("Failed to update visibility for user ID %s: %v", id, err)
+		return nil, err
+	}
+
+	updatedUser, err := s.userRepo.GetUser(ctx, userID)
+	if err != nil {
+		s.logger.Errorf("Failed to get updated user with ID %s: %v", id, err)
+		return nil, apperror.NewInternalError("Failed to retrieve updated user", err)
+	}
+
+	duration := time.Since(start)
+	s.logger.Infof("Visibility for user ID %s updated successfully in %v", id, duration)
+	return mapUserToDTO(updatedUser), nil
+}
@@ -0,0 +1,509 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	db "github.com/0xsj/mios.io/db/sqlc"
+	"github.com/0xsj/mios.io/log"
+	"github.com/0xsj/mios.io/pkg/email"
+	"github.com/0xsj/mios.io/pkg/errors"
+	"github.com/0xsj/mios.io/pkg/ptr"
+	"github.com/0xsj/mios.io/repository"
+	"github.com/google/uuid"
+)
+
+// Report frequencies. A "once" report is sent a single time and its
+// subscription removed; the others repeat until unsubscribed.
+const (
+	ReportFrequencyOnce    = "once"
+	ReportFrequencyDaily   = "daily"
+	ReportFrequencyWeekly  = "weekly"
+	ReportFrequencyMonthly = "monthly"
+)
+
+// Metrics a report can include. A subscription without metrics gets all of
+// them.
+const (
+	ReportMetricViews          = "views"
+	ReportMetricClicks         = "clicks"
+	ReportMetricUniqueVisitors = "unique_visitors"
+	ReportMetricConversionRate = "conversion_rate"
+	ReportMetricTopItems       = "top_items"
+	ReportMetricTopReferrers   = "top_referrers"
+)
+
+var reportMetrics = []string{
+	ReportMetricViews,
+	ReportMetricClicks,
+	ReportMetricUniqueVisitors,
+	ReportMetricConversionRate,
+	ReportMetricTopItems,
+	ReportMetricTopReferrers,
+}
+
+// Days of analytics each report covers
+var reportPeriodDays = map[string]int{
+	ReportFrequencyOnce:    30,
+	ReportFrequencyDaily:   1,
+	ReportFrequencyWeekly:  7,
+	ReportFrequencyMonthly: 30,
+}
+
+const (
+	reportBatchSize        = 100
+	defaultReportInterval  = 15 * time.Minute
+	unsubscribeTokenLength = 32
+)
+
+// ReportService manages scheduled analytics email reports
+type ReportService interface {
+	ListSubscriptions(ctx context.Context, userID string) ([]*ReportSubscriptionDTO, error)
+	Subscribe(ctx context.Context, userID string, input ReportSubscriptionInput) (*ReportSubscriptionDTO, error)
+	Unsubscribe(ctx context.Context, userID, subscriptionID string) error
+	UnsubscribeByToken(ctx context.Context, token string) error
+	SendDueReports(ctx context.Context) (int, error)
+	StartReportScheduler(ctx context.Context, interval time.Duration)
+}
+
+// ReportConfig lists the frequencies only premium users may subscribe to
+type ReportConfig struct {
+	PremiumFrequencies []string
+}
+
+// ReportSubscriptionInput describes a new subscription. SendAt sets when
+// the first report goes out; it defaults to now for one-off reports and to
+// one period from now for recurring ones.
+type ReportSubscriptionInput struct {
+	Frequency string
+	Metrics   []string
+	SendAt    *time.Time
+}
+
+type ReportSubscriptionDTO struct {
+	ID         string   `json:"id"`
+	Frequency  string   `json:"frequency"`
+	Metrics    []string `json:"metrics"`
+	NextSendAt string   `json:"next_send_at"`
+	LastSentAt string   `json:"last_sent_at,omitempty"`
+	CreatedAt  string   `json:"created_at,omitempty"`
+}
+
+type reportService struct {
+	reportRepo       repository.ReportSubscriptionRepository
+	userRepo         repository.UserRepository
+	analyticsService AnalyticsService
+	emailQueue       *email.Queue
+	config           ReportConfig
+	logger           log.Logger
+	baseURL          string
+}
+
+func NewReportService(
+	reportRepo repository.ReportSubscriptionRepository,
+	userRepo repository.UserRepository,
+	analyticsService AnalyticsService,
+	emailQueue *email.Queue,
+	config ReportConfig,
+	logger log.Logger,
+	baseURL string,
+) ReportService {
+	if config.PremiumFrequencies == nil {
+		config.PremiumFrequencies = []string{ReportFrequencyDaily}
+	}
+
+	return &reportService{
+		reportRepo:       reportRepo,
+		userRepo:         userRepo,
+		analyticsService: analyticsService,
+		emailQueue:       emailQueue,
+		config:           config,
+		logger:           logger,
+		baseURL:          baseURL,
+	}
+}
+
+func (s *reportService) ListSubscriptions(ctx context.Context, userIDStr string) ([]*ReportSubscriptionDTO, error) {
+	s.logger.Debugf("Listing report subscriptions for user ID: %s", userIDStr)
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		s.logger.Warnf("Invalid user ID format: %v", err)
+		return nil, errors.NewBadRequestError("Invalid user ID format", err)
+	}
+
+	subscriptions, err := s.reportRepo.ListUserSubscriptions(ctx, userID)
+	if err != nil {
+		s.logger.Errorf("Failed to list report subscriptions: %v", err)
+		return nil, errors.Wrap(err, "Failed to retrieve report subscriptions")
+	}
+
+	dtos := make([]*ReportSubscriptionDTO, len(subscriptions))
+	for i, subscription := range subscriptions {
+		dtos[i] = mapReportSubscriptionToDTO(subscription)
+	}
+	return dtos, nil
+}
+
+func (s *reportService) Subscribe(ctx context.Context, userIDStr string, input ReportSubscriptionInput) (*ReportSubscriptionDTO, error) {
+	s.logger.Infof("Subscribing user ID: %s to %s analytics reports", userIDStr, input.Frequency)
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		s.logger.Warnf("Invalid user ID format: %v", err)
+		return nil, errors.NewBadRequestError("Invalid user ID format", err)
+	}
+
+	if _, ok := reportPeriodDays[input.Frequency]; !ok {
+		return nil, errors.NewBadRequestError(fmt.Sprintf("Unsupported report frequency: %s", input.Frequency), nil)
+	}
+
+	metrics, err := normalizeReportMetrics(input.Metrics)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.GetUser(ctx, userID)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, errors.NewNotFoundError("User not found", err)
+		}
+		s.logger.Errorf("Error retrieving user: %v", err)
+		return nil, errors.Wrap(err, "Failed to retrieve user")
+	}
+
+	isPremium := user.IsPremium != nil && *user.IsPremium
+	if !isPremium && s.isPremiumFrequency(input.Frequency) {
+		s.logger.Warnf("User %s is not premium and cannot subscribe to %s reports", userIDStr, input.Frequency)
+		return nil, errors.NewForbiddenError(fmt.Sprintf("%s reports are only available on premium accounts", input.Frequency), nil)
+	}
+
+	now := time.Now()
+	nextSendAt := now
+	if input.Frequency != ReportFrequencyOnce {
+		nextSendAt = nextReportTime(input.Frequency, now)
+	}
+	if input.SendAt != nil {
+		if input.SendAt.Before(now) {
+			return nil, errors.NewBadRequestError("send_at must be in the future", nil)
+		}
+		nextSendAt = *input.SendAt
+	}
+
+	token, err := generateUnsubscribeToken()
+	if err != nil {
+		s.logger.Errorf("Failed to generate unsubscribe token: %v", err)
+		return nil, errors.NewInternalError("Failed to create report subscription", err)
+	}
+
+	subscription, err := s.reportRepo.CreateSubscription(ctx, repository.CreateReportSubscriptionParams{
+		UserID:           userID,
+		Frequency:        input.Frequency,
+		Metrics:          metrics,
+		UnsubscribeToken: token,
+		NextSendAt:       nextSendAt,
+	})
+	if err != nil {
+		s.logger.Errorf("Failed to create report subscription: %v", err)
+		return nil, errors.Wrap(err, "Failed to create report subscription")
+	}
+
+	s.logger.Infof("User ID: %s subscribed to %s reports (%s)", userIDStr, input.Frequency, subscription.SubscriptionID)
+	return mapReportSubscriptionToDTO(subscription), nil
+}
+
+func (s *reportService) Unsubscribe(ctx context.Context, userIDStr, subscriptionIDStr string) error {
+	s.logger.Infof("Removing report subscription %s for user ID: %s", subscriptionIDStr, userIDStr)
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		s.logger.Warnf("Invalid user ID format: %v", err)
+		return errors.NewBadRequestError("Invalid user ID format", err)
+	}
+
+	subscriptionID, err := uuid.Parse(subscriptionIDStr)
+	if err != nil {
+		s.logger.Warnf("Invalid subscription ID format: %v", err)
+		return errors.NewBadRequestError("Invalid subscription ID format", err)
+	}
+
+	if err := s.reportRepo.DeleteSubscription(ctx, userID, subscriptionID); err != nil {
+		if errors.IsNotFound(err) {
+			return err
+		}
+		s.logger.Errorf("Failed to delete report subscription: %v", err)
+		return errors.Wrap(err, "Failed to delete report subscription")
+	}
+
+	return nil
+}
+
+// UnsubscribeByToken removes the subscription behind a report's
+// unsubscribe link, so recipients can stop reports without signing in
+func (s *reportService) UnsubscribeByToken(ctx context.Context, token string) error {
+	if token == "" {
+		return errors.NewBadRequestError("Unsubscribe token is required", nil)
+	}
+
+	if err := s.reportRepo.DeleteSubscriptionByToken(ctx, token); err != nil {
+		if errors.IsNotFound(err) {
+			return errors.NewNotFoundError("This report subscription no longer exists", err)
+		}
+		s.logger.Errorf("Failed to unsubscribe by token: %v", err)
+		return errors.Wrap(err, "Failed to unsubscribe from report")
+	}
+
+	return nil
+}
+
+// SendDueReports queues a report email for every subscription whose send
+// time has passed. Each subscription is claimed before its report is built,
+// so a report is queued at most once even with several instances running.
+func (s *reportService) SendDueReports(ctx context.Context) (int, error) {
+	now := time.Now()
+
+	due, err := s.reportRepo.ListDueSubscriptions(ctx, now, reportBatchSize)
+	if err != nil {
+		s.logger.Errorf("Failed to list due report subscriptions: %v", err)
+		return 0, errors.Wrap(err, "Failed to list due report subscriptions")
+	}
+
+	sent := 0
+	for _, row := range due {
+		subscription := row.ReportSubscription
+
+		claimed, err := s.claimSubscription(ctx, &subscription, now)
+		if err != nil {
+			s.logger.Warnf("Failed to claim report subscription %s: %v", subscription.SubscriptionID, err)
+			continue
+		}
+		if !claimed {
+			continue
+		}
+
+		if err := s.sendReport(ctx, row); err != nil {
+			s.logger.Warnf("Failed to send %s report to user %s: %v", subscription.Frequency, subscription.UserID, err)
+			continue
+		}
+		sent++
+	}
+
+	if len(due) > 0 {
+		s.logger.Infof("Queued %d of %d due analytics reports", sent, len(due))
+	}
+	return sent, nil
+}
+
+// claimSubscription takes a one-off subscription out of the table, or moves
+// a recurring one on to its next send time
+func (s *reportService) claimSubscription(ctx context.Context, subscription *db.ReportSubscription, now time.Time) (bool, error) {
+	if subscription.Frequency == ReportFrequencyOnce {
+		err := s.reportRepo.DeleteSubscription(ctx, subscription.UserID, subscription.SubscriptionID)
+		if errors.IsNotFound(err) {
+			return false, nil
+		}
+		return err == nil, err
+	}
+
+	next := nextReportTime(subscription.Frequency, subscription.NextSendAt)
+	if !next.After(now) {
+		// The scheduler was down for more than a period; don't send a
+		// backlog of reports, just resume the cadence from now
+		next = nextReportTime(subscription.Frequency, now)
+	}
+	return s.reportRepo.ClaimSubscription(ctx, subscription.SubscriptionID, subscription.NextSendAt, next)
+}
+
+func (s *reportService) sendReport(ctx context.Context, row *db.ListDueReportSubscriptionsRow) error {
+	subscription := row.ReportSubscription
+	userID := subscription.UserID.String()
+	days := reportPeriodDays[subscription.Frequency]
+
+	metrics := subscription.Metrics
+	if len(metrics) == 0 {
+		metrics = reportMetrics
+	}
+	included := make(map[string]bool, len(metrics))
+	for _, metric := range metrics {
+		included[metric] = true
+	}
+
+	summary, err := s.analyticsService.GetSummaryCards(ctx, userID, days, false)
+	if err != nil {
+		return err
+	}
+
+	data := map[string]interface{}{
+		"Username":        row.Username,
+		"Link":            fmt.Sprintf("%s/analytics", s.baseURL),
+		"UnsubscribeLink": fmt.Sprintf("%s/api/reports/unsubscribe?token=%s", s.baseURL, url.QueryEscape(subscription.UnsubscribeToken)),
+		"AppName":         "Your App Name",
+		"Year":            time.Now().Year(),
+		"Period":          reportPeriodLabel(days),
+		"Frequency":       subscription.Frequency,
+		"Metrics":         reportSummaryRows(summary, included),
+	}
+
+	if included[ReportMetricTopItems] || included[ReportMetricTopReferrers] {
+		dashboard, err := s.analyticsService.GetProfileDashboard(ctx, userID, days, false)
+		if err != nil {
+			return err
+		}
+		if included[ReportMetricTopItems] {
+			data["TopItems"] = dashboard.TopItems
+		}
+		if included[ReportMetricTopReferrers] {
+			data["TopReferrers"] = dashboard.TopReferrers
+		}
+	}
+
+	subject := "Your Analytics Report"
+	if subscription.Frequency != ReportFrequencyOnce {
+		subject = fmt.Sprintf("Your %s%s Analytics Report",
+			strings.ToUpper(subscription.Frequency[:1]), subscription.Frequency[1:])
+	}
+
+	return s.emailQueue.EnqueueLocalizedTemplate([]string{row.Email}, subject, "analytics_report.html",
+		ptr.GetValueOrEmpty(row.Locale), data)
+}
+
+// StartReportScheduler sends due reports every interval until ctx is
+// cancelled
+func (s *reportService) StartReportScheduler(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = defaultReportInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.SendDueReports(ctx); err != nil {
+			s.logger.Errorf("Analytics report run failed: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *reportService) isPremiumFrequency(frequency string) bool {
+	for _, premium := range s.config.PremiumFrequencies {
+		if strings.EqualFold(strings.TrimSpace(premium), frequency) {
+			return true
+		}
+	}
+	return false
+}
+
+// reportMetricRow is one headline figure in a report email
+type reportMetricRow struct {
+	Label  string
+	Value  string
+	Change string
+}
+
+func reportSummaryRows(summary *SummaryCardsDTO, included map[string]bool) []reportMetricRow {
+	var rows []reportMetricRow
+	if included[ReportMetricViews] {
+		rows = append(rows, reportMetricRow{"Profile views", fmt.Sprintf("%d", summary.TotalViews), formatReportChange(summary.ViewsChange)})
+	}
+	if included[ReportMetricClicks] {
+		rows = append(rows, reportMetricRow{"Link clicks", fmt.Sprintf("%d", summary.TotalClicks), formatReportChange(summary.ClicksChange)})
+	}
+	if included[ReportMetricUniqueVisitors] {
+		rows = append(rows, reportMetricRow{"Unique visitors", fmt.Sprintf("%d", summary.UniqueVisitors), formatReportChange(summary.UniqueVisitorsChange)})
+	}
+	if included[ReportMetricConversionRate] {
+		rows = append(rows, reportMetricRow{"Conversion rate", fmt.Sprintf("%.1f%%", summary.ConversionRate), formatReportChange(summary.ConversionRateChange)})
+	}
+	return rows
+}
+
+func formatReportChange(change *float64) string {
+	if change == nil {
+		return "n/a"
+	}
+	return fmt.Sprintf("%+.1f%%", *change)
+}
+
+func reportPeriodLabel(days int) string {
+	if days == 1 {
+		return "the last day"
+	}
+	return fmt.Sprintf("the last %d days", days)
+}
+
+// normalizeReportMetrics validates and de-duplicates requested metrics
+func normalizeReportMetrics(metrics []string) ([]string, error) {
+	normalized := make([]string, 0, len(metrics))
+	seen := make(map[string]bool, len(metrics))
+	for _, metric := range metrics {
+		metric = strings.ToLower(strings.TrimSpace(metric))
+		if !isReportMetric(metric) {
+			return nil, errors.NewBadRequestError(fmt.Sprintf("Unsupported report metric: %s", metric), nil)
+		}
+		if !seen[metric] {
+			seen[metric] = true
+			normalized = append(normalized, metric)
+		}
+	}
+	return normalized, nil
+}
+
+func isReportMetric(metric string) bool {
+	for _, known := range reportMetrics {
+		if metric == known {
+			return true
+		}
+	}
+	return false
+}
+
+func nextReportTime(frequency string, from time.Time) time.Time {
+	switch frequency {
+	case ReportFrequencyDaily:
+		return from.AddDate(0, 0, 1)
+	case ReportFrequencyWeekly:
+		return from.AddDate(0, 0, 7)
+	default:
+		return from.AddDate(0, 1, 0)
+	}
+}
+
+func generateUnsubscribeToken() (string, error) {
+	bytes := make([]byte, unsubscribeTokenLength)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(bytes), nil
+}
+
+func mapReportSubscriptionToDTO(subscription *db.ReportSubscription) *ReportSubscriptionDTO {
+	dto := &ReportSubscriptionDTO{
+		ID:         subscription.SubscriptionID.String(),
+		Frequency:  subscription.Frequency,
+		Metrics:    subscription.Metrics,
+		NextSendAt: subscription.NextSendAt.Format(time.RFC3339),
+	}
+
+	if dto.Metrics == nil {
+		dto.Metrics = []string{}
+	}
+	if subscription.LastSentAt != nil {
+		dto.LastSentAt = subscription.LastSentAt.Format(time.RFC3339)
+	}
+	if subscription.CreatedAt != nil {
+		dto.CreatedAt = subscription.CreatedAt.Format(time.RFC3339)
+	}
+
+	return dto
+}
@@ -0,0 +1,65 @@
+package service
+
+import (
+	"strings"
+
+	"github.com/0xsj/mios.io/pkg/errors"
+)
+
+// ReservedHandles are handles nobody may take, on top of the built-in list
+// of route names and brand terms. Matching ignores case.
+type ReservedHandles struct {
+	// Exact blocks handles equal to one of these words
+	Exact []string
+	// Substrings blocks handles containing one of these words
+	Substrings []string
+}
+
+// defaultReservedHandles collide with the API's path segments or pages the
+// frontend serves at the top level
+var defaultReservedHandles = []string{
+	"about", "account", "analytics", "api", "app", "assets", "auth",
+	"blog", "content", "dashboard", "docs", "download", "embed", "files",
+	"health", "help", "home", "link-metadata", "login", "logout", "me",
+	"metrics", "mios", "oauth", "pricing", "privacy", "profile", "profiles",
+	"register", "reports", "root", "settings", "signin", "signup", "static",
+	"status", "support", "system", "templates", "terms", "upload", "user",
+	"users", "www",
+}
+
+// defaultReservedHandleSubstrings would let an account pass itself off as
+// staff wherever they appear
+var defaultReservedHandleSubstrings = []string{"admin", "moderator"}
+
+// IsReserved reports whether handle is blocked by an exact or substring match
+func (r ReservedHandles) IsReserved(handle string) bool {
+	handle = strings.ToLower(handle)
+	for _, lists := range [][]string{defaultReservedHandles, r.Exact} {
+		for _, word := range lists {
+			if handle == strings.ToLower(strings.TrimSpace(word)) {
+				return true
+			}
+		}
+	}
+	for _, lists := range [][]string{defaultReservedHandleSubstrings, r.Substrings} {
+		for _, word := range lists {
+			word = strings.ToLower(strings.TrimSpace(word))
+			if word != "" && strings.Contains(handle, word) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// validateHandle checks a handle that is about to be taken, both its format
+// and that it isn't reserved
+func validateHandle(handle string, reserved ReservedHandles) error {
+	if !isValidHandle(handle) {
+		return errors.NewValidationError("Invalid handle format", nil)
+	}
+	if reserved.IsReserved(handle) {
+		return errors.NewValidationError("This handle is reserved", nil)
+	}
+	return nil
+}
@@ -0,0 +1,295 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/0xsj/mios.io/log"
+	"github.com/0xsj/mios.io/pkg/email"
+	"github.com/0xsj/mios.io/pkg/errors"
+	"github.com/0xsj/mios.io/repository"
+)
+
+// RetentionService applies the per-tier analytics retention policy
+type RetentionService interface {
+	RetentionWindow(isPremium bool) time.Duration
+	SendPurgeWarnings(ctx context.Context) (int, error)
+	StartPurgeWarnings(ctx context.Context, interval time.Duration)
+	AnonymizeAnalytics(ctx context.Context) (int64, error)
+	PurgeExpiredAnalytics(ctx context.Context) (int64, error)
+	PurgeAnalytics(ctx context.Context) (*AnalyticsPurgeDTO, error)
+	StartRetentionEnforcement(ctx context.Context, interval time.Duration)
+}
+
+// RetentionConfig sets how long raw analytics are kept per tier and how
+// many days ahead of a purge users are warned. WarningDays of zero turns
+// warnings off.
+//
+// Rows older than AnonymizeDays keep their counts and referrers but lose the
+// visitor's user agent, and their IP is replaced by a hash salted with
+// AnonymizationSalt (or cleared when no salt is set). AnonymizeDays of zero
+// keeps visitor data until the row is purged.
+//
+// RetentionDays caps how long any analytics are kept, whatever the owner's
+// tier. Zero sets no cap, leaving only the tier windows.
+type RetentionConfig struct {
+	FreeDays      int
+	PremiumDays   int
+	WarningDays   int
+	RetentionDays int
+
+	AnonymizeDays     int
+	AnonymizationSalt string
+}
+
+const (
+	defaultFreeRetentionDays    = 90
+	defaultPremiumRetentionDays = 365
+	purgeWarningBatchSize       = 200
+	retentionBatchSize          = 1000
+)
+
+type retentionService struct {
+	analyticsRepo repository.AnalyticsRepository
+	emailClient   *email.EmailClient
+	config        RetentionConfig
+	logger        log.Logger
+	baseURL       string
+}
+
+func NewRetentionService(
+	analyticsRepo repository.AnalyticsRepository,
+	emailClient *email.EmailClient,
+	config RetentionConfig,
+	logger log.Logger,
+	baseURL string,
+) RetentionService {
+	if config.FreeDays <= 0 {
+		config.FreeDays = defaultFreeRetentionDays
+	}
+	if config.PremiumDays <= 0 {
+		config.PremiumDays = defaultPremiumRetentionDays
+	}
+
+	return &retentionService{
+		analyticsRepo: analyticsRepo,
+		emailClient:   emailClient,
+		config:        config,
+		logger:        logger,
+		baseURL:       baseURL,
+	}
+}
+
+func (s *retentionService) RetentionWindow(isPremium bool) time.Duration {
+	days := s.config.FreeDays
+	if isPremium {
+		days = s.config.PremiumDays
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// SendPurgeWarnings emails every user whose oldest analytics fall out of
+// their retention window within the warning period. A user is warned at
+// most once per retention window.
+func (s *retentionService) SendPurgeWarnings(ctx context.Context) (int, error) {
+	if s.config.WarningDays <= 0 {
+		return 0, nil
+	}
+
+	now := time.Now()
+	warningPeriod := time.Duration(s.config.WarningDays) * 24 * time.Hour
+	freeCutoff := now.Add(-s.RetentionWindow(false))
+	premiumCutoff := now.Add(-s.RetentionWindow(true))
+
+	candidates, err := s.analyticsRepo.ListUsersDueForPurgeWarning(ctx, repository.PurgeWarningParams{
+		FreeWarnBefore:      freeCutoff.Add(warningPeriod),
+		PremiumWarnBefore:   premiumCutoff.Add(warningPeriod),
+		FreeRewarnBefore:    freeCutoff,
+		PremiumRewarnBefore: premiumCutoff,
+		Limit:               purgeWarningBatchSize,
+	})
+	if err != nil {
+		s.logger.Errorf("Failed to list users due for purge warnings: %v", err)
+		return 0, errors.Wrap(err, "Failed to list users due for purge warnings")
+	}
+
+	sent := 0
+	for _, candidate := range candidates {
+		purgeDate := candidate.OldestEvent.Add(s.RetentionWindow(candidate.IsPremium))
+		if purgeDate.Before(now) {
+			purgeDate = now
+		}
+
+		if err := s.sendPurgeWarningEmail(candidate, purgeDate); err != nil {
+			s.logger.Warnf("Failed to send purge warning to user %s: %v", candidate.UserID, err)
+			continue
+		}
+
+		if err := s.analyticsRepo.MarkPurgeWarned(ctx, candidate.UserID); err != nil {
+			s.logger.Warnf("Failed to record purge warning for user %s: %v", candidate.UserID, err)
+			continue
+		}
+		sent++
+	}
+
+	s.logger.Infof("Sent %d of %d analytics purge warnings", sent, len(candidates))
+	return sent, nil
+}
+
+func (s *retentionService) sendPurgeWarningEmail(candidate repository.PurgeWarningCandidate, purgeDate time.Time) error {
+	retentionDays := s.config.FreeDays
+	if candidate.IsPremium {
+		retentionDays = s.config.PremiumDays
+	}
+
+	data := map[string]interface{}{
+		"Username":      candidate.Username,
+		"Link":          fmt.Sprintf("%s/analytics", s.baseURL),
+		"AppName":       "Your App Name",
+		"Year":          time.Now().Year(),
+		"PurgeDate":     purgeDate.Format("January 2, 2006"),
+		"RetentionDays": retentionDays,
+		"IsPremium":     candidate.IsPremium,
+	}
+
+	return s.emailClient.SendLocalizedTemplate([]string{candidate.Email}, "Your Older Analytics Will Be Deleted Soon",
+		"analytics_purge_warning.html", candidate.Locale, data)
+}
+
+// StartPurgeWarnings sends warnings every interval until ctx is cancelled
+func (s *retentionService) StartPurgeWarnings(ctx context.Context, interval time.Duration) {
+	if s.config.WarningDays <= 0 {
+		s.logger.Info("Analytics purge warnings are disabled")
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.SendPurgeWarnings(ctx); err != nil {
+			s.logger.Errorf("Analytics purge warning run failed: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// AnonymizeAnalytics strips visitor data from analytics older than the
+// anonymization window, in batches, and returns how many rows it changed
+func (s *retentionService) AnonymizeAnalytics(ctx context.Context) (int64, error) {
+	if s.config.AnonymizeDays <= 0 {
+		return 0, nil
+	}
+
+	before := time.Now().Add(-time.Duration(s.config.AnonymizeDays) * 24 * time.Hour)
+
+	var total int64
+	for ctx.Err() == nil {
+		rows, err := s.analyticsRepo.AnonymizeAnalytics(ctx, repository.AnonymizeParams{
+			Before: before,
+			Salt:   s.config.AnonymizationSalt,
+			Limit:  retentionBatchSize,
+		})
+		if err != nil {
+			s.logger.Errorf("Failed to anonymize analytics: %v", err)
+			return total, errors.Wrap(err, "Failed to anonymize analytics")
+		}
+		total += rows
+		if rows < retentionBatchSize {
+			break
+		}
+	}
+
+	s.logger.Infof("Anonymized %d analytics entries older than %d days", total, s.config.AnonymizeDays)
+	return total, nil
+}
+
+// PurgeExpiredAnalytics deletes analytics that have aged out of their
+// owner's tier retention window, in batches, and returns how many it removed
+func (s *retentionService) PurgeExpiredAnalytics(ctx context.Context) (int64, error) {
+	now := time.Now()
+
+	var total int64
+	for ctx.Err() == nil {
+		rows, err := s.analyticsRepo.DeleteExpiredAnalytics(ctx, repository.PurgeParams{
+			FreeBefore:    now.Add(-s.RetentionWindow(false)),
+			PremiumBefore: now.Add(-s.RetentionWindow(true)),
+			Limit:         retentionBatchSize,
+		})
+		if err != nil {
+			s.logger.Errorf("Failed to purge expired analytics: %v", err)
+			return total, errors.Wrap(err, "Failed to purge expired analytics")
+		}
+		total += rows
+		if rows < retentionBatchSize {
+			break
+		}
+	}
+
+	s.logger.Infof("Purged %d expired analytics entries", total)
+	return total, nil
+}
+
+// AnalyticsPurgeDTO reports what one purge run removed. Cutoff is empty
+// when no global retention cap is configured.
+type AnalyticsPurgeDTO struct {
+	ExpiredRemoved   int64  `json:"expired_removed"`
+	RetentionRemoved int64  `json:"retention_removed"`
+	TotalRemoved     int64  `json:"total_removed"`
+	Cutoff           string `json:"cutoff,omitempty"`
+}
+
+// PurgeAnalytics removes analytics past their tier window and then anything
+// older than the global retention cap
+func (s *retentionService) PurgeAnalytics(ctx context.Context) (*AnalyticsPurgeDTO, error) {
+	expired, err := s.PurgeExpiredAnalytics(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &AnalyticsPurgeDTO{ExpiredRemoved: expired, TotalRemoved: expired}
+	if s.config.RetentionDays <= 0 {
+		return result, nil
+	}
+
+	cutoff := time.Now().Add(-time.Duration(s.config.RetentionDays) * 24 * time.Hour)
+	removed, err := s.analyticsRepo.PurgeAnalyticsOlderThan(ctx, cutoff)
+	if err != nil {
+		s.logger.Errorf("Failed to purge analytics older than %d days: %v", s.config.RetentionDays, err)
+		return nil, errors.Wrap(err, "Failed to purge analytics past retention")
+	}
+
+	s.logger.Infof("Purged %d analytics entries older than %d days", removed, s.config.RetentionDays)
+	result.RetentionRemoved = removed
+	result.TotalRemoved += removed
+	result.Cutoff = cutoff.UTC().Format(time.RFC3339)
+	return result, nil
+}
+
+// StartRetentionEnforcement anonymizes and then purges old analytics every
+// interval until ctx is cancelled
+func (s *retentionService) StartRetentionEnforcement(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.AnonymizeAnalytics(ctx); err != nil {
+			s.logger.Errorf("Analytics anonymization run failed: %v", err)
+		}
+		if _, err := s.PurgeAnalytics(ctx); err != nil {
+			s.logger.Errorf("Analytics purge run failed: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
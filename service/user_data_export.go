@@ -0,0 +1,190 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"time"
+
+	db "github.com/0xsj/mios.io/db/sqlc"
+	apperror "github.com/0xsj/mios.io/pkg/errors"
+	"github.com/0xsj/mios.io/repository"
+	"github.com/google/uuid"
+)
+
+// UserDataExport is everything stored about a user. Analytics entries can
+// run into the millions, so they aren't held here but read in batches while
+// WriteJSON runs.
+type UserDataExport struct {
+	ExportedAt   time.Time         `json:"exported_at"`
+	User         *UserDTO          `json:"user"`
+	Auth         *AuthMetadataDTO  `json:"auth,omitempty"`
+	ContentItems []*ContentItemDTO `json:"content_items"`
+	Files        []*FileDTO        `json:"files"`
+
+	userID        uuid.UUID
+	analyticsRepo repository.AnalyticsRepository
+	batchSize     int
+}
+
+// AuthMetadataDTO is the user's login state without any secrets: no
+// password hash, reset or verification tokens, or TOTP secrets
+type AuthMetadataDTO struct {
+	EmailVerified       bool   `json:"email_verified"`
+	TwoFactorEnabled    bool   `json:"two_factor_enabled"`
+	FailedLoginAttempts int32  `json:"failed_login_attempts"`
+	LastLogin           string `json:"last_login,omitempty"`
+	LockedUntil         string `json:"locked_until,omitempty"`
+	CreatedAt           string `json:"created_at,omitempty"`
+	UpdatedAt           string `json:"updated_at,omitempty"`
+}
+
+// ExportUserData gathers the user's record, content, uploaded files and
+// login metadata. Users who signed up through OAuth may have no auth
+// record, in which case Auth is left out.
+func (s *userService) ExportUserData(ctx context.Context, id string) (*UserDataExport, error) {
+	s.logger.Infof("Exporting data for user ID: %s", id)
+
+	userID, err := parseUUID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.GetUser(ctx, userID)
+	if err != nil {
+		s.logger.Errorf("Failed to get user with ID %s: %v", id, err)
+		return nil, err
+	}
+
+	export := &UserDataExport{
+		ExportedAt:    time.Now().UTC(),
+		User:          mapUserToDTO(user),
+		ContentItems:  []*ContentItemDTO{},
+		Files:         []*FileDTO{},
+		userID:        userID,
+		analyticsRepo: s.analyticsRepo,
+		batchSize:     defaultExportBatchSize,
+	}
+
+	auth, err := s.authRepo.GetAuthByUserID(ctx, userID)
+	switch {
+	case err == nil:
+		export.Auth = mapAuthMetadata(auth)
+	case !apperror.IsNotFound(err):
+		s.logger.Errorf("Failed to get auth record for user ID %s: %v", id, err)
+		return nil, apperror.Wrap(err, "Failed to export user data")
+	}
+
+	items, err := s.contentRepo.GetUserContentItems(ctx, userID)
+	if err != nil {
+		s.logger.Errorf("Failed to get content items for user ID %s: %v", id, err)
+		return nil, apperror.Wrap(err, "Failed to export user data")
+	}
+	// Items in the trash are still stored, so they're exported too
+	deleted, err := s.contentRepo.ListDeletedUserContentItems(ctx, userID)
+	if err != nil {
+		s.logger.Errorf("Failed to get deleted content items for user ID %s: %v", id, err)
+		return nil, apperror.Wrap(err, "Failed to export user data")
+	}
+	for _, item := range append(items, deleted...) {
+		export.ContentItems = append(export.ContentItems, mapContentItemToDTO(item))
+	}
+
+	for page := 1; ; page++ {
+		files, err := s.fileService.ListUserFiles(ctx, id, "", page, maxFilePageSize)
+		if err != nil {
+			s.logger.Errorf("Failed to list files for user ID %s: %v", id, err)
+			return nil, apperror.Wrap(err, "Failed to export user data")
+		}
+		export.Files = append(export.Files, files.Files...)
+		if page >= files.TotalPages {
+			break
+		}
+	}
+
+	return export, nil
+}
+
+func mapAuthMetadata(auth *db.Auth) *AuthMetadataDTO {
+	dto := &AuthMetadataDTO{
+		EmailVerified:    auth.IsEmailVerified != nil && *auth.IsEmailVerified,
+		TwoFactorEnabled: auth.TotpEnabled,
+	}
+	if auth.FailedLoginAttempts != nil {
+		dto.FailedLoginAttempts = *auth.FailedLoginAttempts
+	}
+	for _, field := range []struct {
+		value *time.Time
+		dest  *string
+	}{
+		{auth.LastLogin, &dto.LastLogin},
+		{auth.LockedUntil, &dto.LockedUntil},
+		{auth.CreatedAt, &dto.CreatedAt},
+		{auth.UpdatedAt, &dto.UpdatedAt},
+	} {
+		if field.value != nil {
+			*field.dest = field.value.Format(time.RFC3339)
+		}
+	}
+	return dto
+}
+
+// WriteJSON writes the export as one JSON object, streaming the user's
+// analytics into its "analytics" array a batch at a time
+func (e *UserDataExport) WriteJSON(ctx context.Context, w io.Writer) error {
+	head, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	buffered := bufio.NewWriter(w)
+	// Reopen the marshalled object so the analytics array can follow
+	buffered.Write(head[:len(head)-1])
+	buffered.WriteString(`,"analytics":[`)
+
+	params := repository.ExportPageParams{
+		UserID:  e.userID,
+		EndDate: e.ExportedAt,
+		Limit:   e.batchSize,
+	}
+
+	first := true
+	for {
+		if err := ctx.Err(); err != nil {
+			return apperror.Wrap(err, "user data export cancelled")
+		}
+
+		rows, err := e.analyticsRepo.ListAnalyticsForExport(ctx, params)
+		if err != nil {
+			return err
+		}
+
+		for _, row := range rows {
+			entry, err := json.Marshal(mapAnalyticToDTO(&row.Analytic))
+			if err != nil {
+				return err
+			}
+			if !first {
+				buffered.WriteByte(',')
+			}
+			first = false
+			buffered.Write(entry)
+		}
+
+		if err := buffered.Flush(); err != nil {
+			return err
+		}
+
+		if len(rows) < params.Limit {
+			break
+		}
+
+		last := rows[len(rows)-1].Analytic
+		params.AfterClickedAt = *last.ClickedAt
+		params.AfterID = last.AnalyticsID
+	}
+
+	buffered.WriteString("]}")
+	return buffered.Flush()
+}
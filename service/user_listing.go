@@ -0,0 +1,93 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"unicode/utf8"
+
+	apperror "github.com/0xsj/mios.io/pkg/errors"
+	"github.com/0xsj/mios.io/repository"
+)
+
+const (
+	defaultUserPageSize = 20
+	maxUserPageSize     = 100
+	maxUserSearchLength = 100
+)
+
+// ListUsersInput filters and pages the user list. Search matches part of
+// the username, handle or email, case-insensitively; nil flags match either
+// value.
+type ListUsersInput struct {
+	Search    string
+	IsPremium *bool
+	IsAdmin   *bool
+	Onboarded *bool
+	Verified  *bool
+	Page      int
+	PageSize  int
+}
+
+// UserListDTO is one page of users, most recently created first
+type UserListDTO struct {
+	Users      []*UserDTO
+	Page       int
+	PageSize   int
+	TotalCount int64
+	TotalPages int
+}
+
+func (s *userService) ListUsers(ctx context.Context, input ListUsersInput) (*UserListDTO, error) {
+	s.logger.Debugf("Listing users (search %q, page %d)", input.Search, input.Page)
+
+	search := strings.TrimSpace(input.Search)
+	if utf8.RuneCountInString(search) > maxUserSearchLength {
+		return nil, handleValidationError(fmt.Sprintf("Search must be at most %d characters", maxUserSearchLength), nil)
+	}
+
+	page, pageSize := input.Page, input.PageSize
+	if page < 1 {
+		page = 1
+	}
+	if pageSize <= 0 {
+		pageSize = defaultUserPageSize
+	}
+	if pageSize > maxUserPageSize {
+		pageSize = maxUserPageSize
+	}
+
+	filter := repository.UserFilter{
+		Search:    search,
+		IsPremium: input.IsPremium,
+		IsAdmin:   input.IsAdmin,
+		Onboarded: input.Onboarded,
+		Verified:  input.Verified,
+	}
+
+	total, err := s.userRepo.CountUsers(ctx, filter)
+	if err != nil {
+		s.logger.Errorf("Failed to count users: %v", err)
+		return nil, apperror.Wrap(err, "Failed to retrieve users")
+	}
+
+	users, err := s.userRepo.ListUsers(ctx, filter, pageSize, (page-1)*pageSize)
+	if err != nil {
+		s.logger.Errorf("Failed to list users: %v", err)
+		return nil, apperror.Wrap(err, "Failed to retrieve users")
+	}
+
+	dtos := make([]*UserDTO, len(users))
+	for i, user := range users {
+		dtos[i] = mapUserToDTO(user)
+	}
+
+	return &UserListDTO{
+		Users:      dtos,
+		Page:       page,
+		PageSize:   pageSize,
+		TotalCount: total,
+		TotalPages: int(math.Ceil(float64(total) / float64(pageSize))),
+	}, nil
+}
@@ -2,12 +2,16 @@ package service
 
 import (
 	"context"
+	"fmt"
 	"regexp"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/0xsj/mios.io/log"
+	"github.com/0xsj/mios.io/pkg/email"
 	apperror "github.com/0xsj/mios.io/pkg/errors"
+	"github.com/0xsj/mios.io/pkg/token"
 
 	db "github.com/0xsj/mios.io/db/sqlc"
 	"github.com/0xsj/mios.io/repository"
@@ -20,14 +24,51 @@ type UserService interface {
 	GetUserByUsername(ctx context.Context, username string) (*UserDTO, error)
 	GetUserByHandle(ctx context.Context, handle string) (*UserDTO, error)
 	GetUserByEmail(ctx context.Context, email string) (*UserDTO, error)
+	IsHandleAvailable(ctx context.Context, handle string) (bool, error)
+	ListUsers(ctx context.Context, input ListUsersInput) (*UserListDTO, error)
 	UpdateUser(ctx context.Context, id string, input UpdateUserInput) (*UserDTO, error)
 	UpdateHandle(ctx context.Context, id string, handle string) (*UserDTO, error)
+	InitiateDomainVerification(ctx context.Context, id string, domain string) (*DomainVerificationDTO, error)
+	CheckDomainVerification(ctx context.Context, id string) (*DomainVerificationDTO, error)
 	UpdatePremiumStatus(ctx context.Context, id string, isPremium bool) (*UserDTO, error)
 	UpdateAdminStatus(ctx context.Context, id string, isAdmin bool) (*UserDTO, error)
 	UpdateOnboardedStatus(ctx context.Context, id string, onboarded bool) (*UserDTO, error)
+	UpdateAnalyticsEnabled(ctx context.Context, id string, enabled bool) (*UserDTO, error)
+	UpdateLocale(ctx context.Context, id string, locale string) (*UserDTO, error)
+	UpdateVisibility(ctx context.Context, id string, visibility string) (*UserDTO, error)
+	ExportUserData(ctx context.Context, id string) (*UserDataExport, error)
 	DeleteUser(ctx context.Context, id string) error
+	RequestAccountDeletion(ctx context.Context, id string) (*AccountDeletionDTO, error)
+	CancelAccountDeletion(ctx context.Context, id string) (*UserDTO, error)
+	PurgeDeletedUsers(ctx context.Context) (int64, error)
+	StartDeletionPurge(ctx context.Context, interval time.Duration)
+	ListAvatars(ctx context.Context, id string) ([]*AvatarDTO, error)
+	UploadAvatar(ctx context.Context, id string, input UploadFileInput) (*AvatarDTO, error)
+	SetActiveAvatar(ctx context.Context, id string, avatarID string) (*UserDTO, error)
+	TransferHandle(ctx context.Context, id string, input HandleTransferInput) (*HandleTransferDTO, error)
+	ClaimHandle(ctx context.Context, id string, input HandleClaimInput) (*UserDTO, error)
+	OnPremiumStatusChange(handler PremiumStatusHandler)
+	SetReauthenticator(reauth Reauthenticator)
 }
 
+// Reauthenticator confirms a user's credentials before a sensitive change,
+// counting failures towards the account lockout; AuthService is one
+type Reauthenticator interface {
+	Reauthenticate(ctx context.Context, userID, password, code string) error
+}
+
+// PremiumStatusChange is emitted when a user is upgraded to or downgraded
+// from premium
+type PremiumStatusChange struct {
+	UserID     string
+	IsPremium  bool
+	WasPremium bool
+}
+
+// PremiumStatusHandler reacts to a premium status change, e.g. by dropping
+// cached data that depends on the user's plan
+type PremiumStatusHandler func(ctx context.Context, change PremiumStatusChange)
+
 type CreateUserInput struct {
 	Username        string `json:"username" binding:"required"`
 	Handle          string `json:"handle" binding:"required"`
@@ -54,6 +95,30 @@ type UpdateUserInput struct {
 	CustomDomain    *string `json:"custom_domain"`
 }
 
+// ReauthInput carries the credentials sensitive account actions ask for
+// again. Code is only needed when two-factor authentication is enabled.
+type ReauthInput struct {
+	Password string `json:"password" binding:"required"`
+	Code     string `json:"code"`
+}
+
+type HandleTransferInput struct {
+	TargetHandle string `json:"target_handle" binding:"required"`
+	ReauthInput
+}
+
+type HandleClaimInput struct {
+	Handle string `json:"handle" binding:"required"`
+	ReauthInput
+}
+
+type HandleTransferDTO struct {
+	Handle       string `json:"handle"`
+	TargetHandle string `json:"target_handle"`
+	NewHandle    string `json:"new_handle"`
+	ExpiresAt    string `json:"expires_at"`
+}
+
 type UserDTO struct {
 	ID              string `json:"id"`
 	Username        string `json:"username"`
@@ -65,22 +130,109 @@ type UserDTO struct {
 	ProfileImageURL string `json:"profile_image_url,omitempty"`
 	LayoutVersion   string `json:"layout_version,omitempty"`
 	CustomDomain    string `json:"custom_domain,omitempty"`
-	IsPremium       bool   `json:"is_premium"`
-	IsAdmin         bool   `json:"is_admin"`
-	Onboarded       bool   `json:"onboarded"`
-	CreatedAt       string `json:"created_at,omitempty"`
-	UpdatedAt       string `json:"updated_at,omitempty"`
+	// CustomDomainVerified is true once ownership of CustomDomain is proven
+	CustomDomainVerified bool `json:"custom_domain_verified"`
+	IsPremium            bool `json:"is_premium"`
+	IsAdmin              bool `json:"is_admin"`
+	Onboarded            bool `json:"onboarded"`
+	// AnalyticsEnabled is false when the owner turned off visitor tracking
+	AnalyticsEnabled bool `json:"analytics_enabled"`
+	// Locale picks the language of emails; empty uses the default
+	Locale string `json:"locale,omitempty"`
+	// Visibility is public, unlisted or private
+	Visibility string `json:"visibility"`
+	CreatedAt  string `json:"created_at,omitempty"`
+	UpdatedAt  string `json:"updated_at,omitempty"`
+}
+
+type AvatarDTO struct {
+	ID        string `json:"id"`
+	URL       string `json:"url"`
+	IsActive  bool   `json:"is_active"`
+	CreatedAt string `json:"created_at,omitempty"`
 }
 
+// UserConfig holds per-tier limits for user-owned resources.
+type UserConfig struct {
+	MaxAvatarsFree    int
+	MaxAvatarsPremium int
+
+	// HandleTransferTTL is how long a released handle stays reserved for
+	// the target account
+	HandleTransferTTL time.Duration
+
+	// FieldLimits caps the length of names and bios
+	FieldLimits FieldLimits
+
+	// ReservedHandles adds to the handles nobody may take
+	ReservedHandles ReservedHandles
+
+	// DomainResolver looks up custom domain verification records; nil uses
+	// the system resolver
+	DomainResolver TXTResolver
+
+	// DeletionGracePeriod is how long a deleted account can be restored
+	// before it is purged
+	DeletionGracePeriod time.Duration
+}
+
+const defaultHandleTransferTTL = time.Hour
+
+// Audit log actions recorded by the user service
+const (
+	AuditHandleTransferInitiated  = "handle.transfer_initiated"
+	AuditHandleTransferClaimed    = "handle.transfer_claimed"
+	AuditAccountDeletionRequested = "account.deletion_requested"
+	AuditAccountDeletionCancelled = "account.deletion_cancelled"
+)
+
 type userService struct {
-	userRepo repository.UserRepository
-	logger   log.Logger
+	userRepo      repository.UserRepository
+	authRepo      repository.AuthRepository
+	sessionRepo   repository.SessionRepository
+	avatarRepo    repository.UserAvatarRepository
+	auditRepo     repository.AuditRepository
+	contentRepo   repository.ContentRepository
+	analyticsRepo repository.AnalyticsRepository
+	fileService   FileService
+	config        UserConfig
+	logger        log.Logger
+
+	mu              sync.RWMutex
+	premiumHandlers []PremiumStatusHandler
+	reauth          Reauthenticator
 }
 
-func NewUserService(userRepo repository.UserRepository, logger log.Logger) UserService {
+func NewUserService(
+	userRepo repository.UserRepository,
+	authRepo repository.AuthRepository,
+	sessionRepo repository.SessionRepository,
+	avatarRepo repository.UserAvatarRepository,
+	auditRepo repository.AuditRepository,
+	contentRepo repository.ContentRepository,
+	analyticsRepo repository.AnalyticsRepository,
+	fileService FileService,
+	config UserConfig,
+	logger log.Logger,
+) UserService {
+	if config.HandleTransferTTL <= 0 {
+		config.HandleTransferTTL = defaultHandleTransferTTL
+	}
+	if config.DeletionGracePeriod <= 0 {
+		config.DeletionGracePeriod = defaultDeletionGracePeriod
+	}
+
 	return &userService{
-		userRepo: userRepo,
-		logger:   logger,
+		userRepo:      userRepo,
+		authRepo:      authRepo,
+		sessionRepo:   sessionRepo,
+		avatarRepo:    avatarRepo,
+		auditRepo:     auditRepo,
+		contentRepo:   contentRepo,
+		analyticsRepo: analyticsRepo,
+		fileService:   fileService,
+		config:        config,
+		logger:        logger,
 	}
 }
 
@@ -107,8 +259,12 @@ func (s *userService) CreateUser(ctx context.Context, input CreateUserInput) (*U
 		return nil, handleValidationError("Invalid email format", nil)
 	}
 
-	if !isValidHandle(input.Handle) {
-		return nil, handleValidationError("Invalid handle format", nil)
+	if err := validateHandle(input.Handle, s.config.ReservedHandles); err != nil {
+		return nil, err
+	}
+
+	if err := validateFieldLengths(s.config.FieldLimits.profileChecks(&input.FirstName, &input.LastName, &input.Bio)...); err != nil {
+		return nil, err
 	}
 
 	params := repository.CreateUserParams{
@@ -208,6 +364,28 @@ func (s *userService) GetUserByEmail(ctx context.Context, email string) (*UserDT
 	return mapUserToDTO(user), nil
 }
 
+// IsHandleAvailable reports whether a handle could be registered or moved
+// to right now; reserved handles never are. It is called as the user types,
+// so it costs at most one query.
+func (s *userService) IsHandleAvailable(ctx context.Context, handle string) (bool, error) {
+	s.logger.Debugf("Checking availability of handle: %s", handle)
+
+	if !isValidHandle(handle) {
+		return false, handleValidationError("Invalid handle format", nil)
+	}
+	if s.config.ReservedHandles.IsReserved(handle) {
+		return false, nil
+	}
+
+	taken, err := s.userRepo.IsHandleTaken(ctx, handle)
+	if err != nil {
+		s.logger.Errorf("Failed to check availability of handle %s: %v", handle, err)
+		return false, apperror.Wrap(err, "Failed to check handle availability")
+	}
+
+	return !taken, nil
+}
+
 func (s *userService) UpdateUser(ctx context.Context, id string, input UpdateUserInput) (*UserDTO, error) {
 	s.logger.Infof("Updating user with ID: %s", id)
 
@@ -216,6 +394,10 @@ func (s *userService) UpdateUser(ctx context.Context, id string, input UpdateUse
 		return nil, err
 	}
 
+	if err := validateFieldLengths(s.config.FieldLimits.profileChecks(input.FirstName, input.LastName, input.Bio)...); err != nil {
+		return nil, err
+	}
+
 	start := time.Now()
 	currentUser, err := s.userRepo.GetUser(ctx, userID)
 	if err != nil {
@@ -282,17 +464,40 @@ func (s *userService) UpdateHandle(ctx context.Context, id string, handle string
 		return nil, err
 	}
 
-	if !isValidHandle(handle) {
-		return nil, handleValidationError("Invalid handle format", nil)
+	if err := validateHandle(handle, s.config.ReservedHandles); err != nil {
+		return nil, err
 	}
 
 	start := time.Now()
+	currentUser, err := s.userRepo.GetUser(ctx, userID)
+	if err != nil {
+		s.logger.Errorf("Failed to get user with ID %s: %v", id, err)
+		return nil, err
+	}
+
+	if currentUser.Handle != handle {
+		reserved, err := s.userRepo.IsHandleReserved(ctx, handle)
+		if err != nil {
+			return nil, err
+		}
+		if reserved {
+			return nil, apperror.NewConflictError("Handle is reserved for a pending transfer", nil)
+		}
+	}
+
 	err = s.userRepo.UpdateHandle(ctx, userID, handle)
 	if err != nil {
 		s.logger.Errorf("Failed to update handle for user ID %s: %v", id, err)
 		return nil, err
 	}
 
+	// Keep the old handle so links to it can redirect to the new one
+	if currentUser.Handle != handle {
+		if err := s.userRepo.RecordHandleChange(ctx, userID, currentUser.Handle); err != nil {
+			s.logger.Warnf("Failed to record previous handle for user ID %s: %v", id, err)
+		}
+	}
+
 	updatedUser, err := s.userRepo.GetUser(ctx, userID)
 	if err != nil {
 		s.logger.Errorf("Failed to get updated user with ID %s: %v", id, err)
@@ -313,6 +518,13 @@ func (s *userService) UpdatePremiumStatus(ctx context.Context, id string, isPrem
 	}
 
 	start := time.Now()
+	user, err := s.userRepo.GetUser(ctx, userID)
+	if err != nil {
+		s.logger.Errorf("Failed to get user with ID %s: %v", id, err)
+		return nil, err
+	}
+	wasPremium := user.IsPremium != nil && *user.IsPremium
+
 	err = s.userRepo.UpdatePremiumStatus(ctx, userID, isPremium)
 	if err != nil {
 		s.logger.Errorf("Failed to update premium status for user ID %s: %v", id, err)
@@ -325,11 +537,46 @@ func (s *userService) UpdatePremiumStatus(ctx context.Context, id string, isPrem
 		return nil, apperror.NewInternalError("Failed to retrieve updated user", err)
 	}
 
+	if wasPremium != isPremium {
+		s.emitPremiumStatusChange(ctx, PremiumStatusChange{
+			UserID:     userID.String(),
+			IsPremium:  isPremium,
+			WasPremium: wasPremium,
+		})
+	}
+
 	duration := time.Since(start)
 	s.logger.Infof("Premium status for user ID %s updated successfully in %v", id, duration)
 	return mapUserToDTO(updatedUser), nil
 }
 
+// OnPremiumStatusChange registers a handler that runs after a user's
+// premium status actually changes
+func (s *userService) OnPremiumStatusChange(handler PremiumStatusHandler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.premiumHandlers = append(s.premiumHandlers, handler)
+}
+
+// SetReauthenticator sets what confirms credentials for handle transfers and
+// claims. It is set after construction since the auth service depends on
+// this one.
+func (s *userService) SetReauthenticator(reauth Reauthenticator) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.reauth = reauth
+}
+
+func (s *userService) emitPremiumStatusChange(ctx context.Context, change PremiumStatusChange) {
+	s.mu.RLock()
+	handlers := s.premiumHandlers
+	s.mu.RUnlock()
+
+	for _, handler := range handlers {
+		handler(ctx, change)
+	}
+}
+
 func (s *userService) UpdateAdminStatus(ctx context.Context, id string, isAdmin bool) (*UserDTO, error) {
 	s.logger.Infof("Updating admin status for user ID: %s to: %v", id, isAdmin)
 
@@ -382,6 +629,72 @@ func (s *userService) UpdateOnboardedStatus(ctx context.Context, id string, onbo
 	return mapUserToDTO(updatedUser), nil
 }
 
+// UpdateAnalyticsEnabled turns visitor tracking on the user's profile on or
+// off. While off, clicks and page views are dropped rather than recorded.
+func (s *userService) UpdateAnalyticsEnabled(ctx context.Context, id string, enabled bool) (*UserDTO, error) {
+	s.logger.Infof("Updating analytics tracking for user ID: %s to: %v", id, enabled)
+
+	userID, err := parseUUID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	err = s.userRepo.UpdateAnalyticsEnabled(ctx, userID, enabled)
+	if err != nil {
+		s.logger.Errorf("Failed to update analytics tracking for user ID %s: %v", id, err)
+		return nil, err
+	}
+
+	updatedUser, err := s.userRepo.GetUser(ctx, userID)
+	if err != nil {
+		s.logger.Errorf("Failed to get updated user with ID %s: %v", id, err)
+		return nil, apperror.NewInternalError("Failed to retrieve updated user", err)
+	}
+
+	duration := time.Since(start)
+	s.logger.Infof("Analytics tracking for user ID %s updated successfully in %v", id, duration)
+	return mapUserToDTO(updatedUser), nil
+}
+
+// UpdateLocale sets the language the user's emails are sent in. The locale
+// is stored normalized, e.g. "es-mx" as "es-MX"; an empty locale clears it
+// so emails use the configured fallback.
+func (s *userService) UpdateLocale(ctx context.Context, id string, locale string) (*UserDTO, error) {
+	s.logger.Infof("Updating locale for user ID: %s to: %q", id, locale)
+
+	userID, err := parseUUID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var stored *string
+	if strings.TrimSpace(locale) != "" {
+		normalized, ok := email.NormalizeLocale(locale)
+		if !ok {
+			return nil, handleValidationError("Locale must be a language tag such as \"es\" or \"es-MX\"", nil)
+		}
+		stored = &normalized
+	}
+
+	start := time.Now()
+	err = s.userRepo.UpdateLocale(ctx, userID, stored)
+	if err != nil {
+		s.logger.Errorf("Failed to update locale for user ID %s: %v", id, err)
+		return nil, err
+	}
+
+	updatedUser, err := s.userRepo.GetUser(ctx, userID)
+	if err != nil {
+		s.logger.Errorf("Failed to get updated user with ID %s: %v", id, err)
+		return nil, apperror.NewInternalError("Failed to retrieve updated user", err)
+	}
+
+	duration := time.Since(start)
+	s.logger.Infof("Locale for user ID %s updated successfully in %v", id, duration)
+	return mapUserToDTO(updatedUser), nil
+}
+
 func (s *userService) DeleteUser(ctx context.Context, id string) error {
 	s.logger.Warnf("Deleting user with ID: %s", id)
 
@@ -408,6 +721,272 @@ func (s *userService) DeleteUser(ctx context.Context, id string) error {
 	return nil
 }
 
+func (s *userService) ListAvatars(ctx context.Context, id string) ([]*AvatarDTO, error) {
+	s.logger.Debugf("Listing avatars for user ID: %s", id)
+
+	userID, err := parseUUID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	avatars, err := s.avatarRepo.ListAvatars(ctx, userID)
+	if err != nil {
+		s.logger.Errorf("Failed to list avatars for user ID %s: %v", id, err)
+		return nil, err
+	}
+
+	result := make([]*AvatarDTO, 0, len(avatars))
+	for _, avatar := range avatars {
+		result = append(result, mapAvatarToDTO(avatar))
+	}
+	return result, nil
+}
+
+// UploadAvatar stores a new avatar image for the user without activating it.
+// Users may keep a limited number of avatars depending on their tier.
+func (s *userService) UploadAvatar(ctx context.Context, id string, input UploadFileInput) (*AvatarDTO, error) {
+	s.logger.Infof("Uploading avatar for user ID: %s", id)
+
+	userID, err := parseUUID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.GetUser(ctx, userID)
+	if err != nil {
+		s.logger.Errorf("Failed to find user %s for avatar upload: %v", id, err)
+		return nil, err
+	}
+
+	count, err := s.avatarRepo.CountAvatars(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := s.maxAvatars(user.IsPremium != nil && *user.IsPremium)
+	if count >= int64(limit) {
+		s.logger.Warnf("User %s reached the avatar limit of %d", id, limit)
+		return nil, apperror.NewForbiddenError(
+			fmt.Sprintf("You can store at most %d avatars on your plan", limit), nil)
+	}
+
+	upload, err := s.fileService.UploadUserAvatar(ctx, id, input)
+	if err != nil {
+		return nil, err
+	}
+
+	avatar, err := s.avatarRepo.CreateAvatar(ctx, userID, upload.Key, upload.URL)
+	if err != nil {
+		s.logger.Errorf("Failed to record avatar for user ID %s: %v", id, err)
+		for _, key := range append([]string{upload.Key}, upload.variantKeys...) {
+			if delErr := s.fileService.DeleteFile(ctx, id, key); delErr != nil {
+				s.logger.Warnf("Failed to clean up avatar file %s: %v", key, delErr)
+			}
+		}
+		return nil, err
+	}
+
+	s.logger.Infof("Avatar %s uploaded for user ID: %s", avatar.AvatarID, id)
+	return mapAvatarToDTO(avatar), nil
+}
+
+// SetActiveAvatar switches the user's active avatar and updates their
+// profile image URL to match.
+func (s *userService) SetActiveAvatar(ctx context.Context, id string, avatarID string) (*UserDTO, error) {
+	s.logger.Infof("Setting active avatar %s for user ID: %s", avatarID, id)
+
+	userID, err := parseUUID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	avatarUUID, err := uuid.Parse(avatarID)
+	if err != nil {
+		return nil, apperror.NewBadRequestError("Invalid avatar ID format", err)
+	}
+
+	if err := s.avatarRepo.ActivateAvatar(ctx, userID, avatarUUID); err != nil {
+		s.logger.Errorf("Failed to activate avatar %s for user ID %s: %v", avatarID, id, err)
+		return nil, err
+	}
+
+	user, err := s.userRepo.GetUser(ctx, userID)
+	if err != nil {
+		return nil, apperror.NewInternalError("Failed to retrieve updated user", err)
+	}
+
+	return mapUserToDTO(user), nil
+}
+
+func (s *userService) maxAvatars(isPremium bool) int {
+	if isPremium {
+		return s.config.MaxAvatarsPremium
+	}
+	return s.config.MaxAvatarsFree
+}
+
+// TransferHandle releases the user's handle into a reservation that only the
+// target account can claim, and moves the user onto a generated handle. The
+// old handle is not kept for redirects since it now belongs to the target.
+func (s *userService) TransferHandle(ctx context.Context, id string, input HandleTransferInput) (*HandleTransferDTO, error) {
+	s.logger.Infof("Transferring handle of user ID: %s to account with handle: %s", id, input.TargetHandle)
+
+	userID, err := parseUUID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.reauthenticate(ctx, userID, input.ReauthInput); err != nil {
+		return nil, err
+	}
+
+	source, err := s.userRepo.GetUser(ctx, userID)
+	if err != nil {
+		s.logger.Errorf("Failed to get user with ID %s: %v", id, err)
+		return nil, err
+	}
+
+	target, err := s.userRepo.GetUserByHandle(ctx, input.TargetHandle)
+	if err != nil {
+		if apperror.IsNotFound(err) {
+			return nil, apperror.NewNotFoundError("Target account not found", err)
+		}
+		return nil, err
+	}
+
+	if target.UserID == source.UserID {
+		return nil, apperror.NewBadRequestError("Cannot transfer a handle to the same account", nil)
+	}
+
+	replacement, err := generateReplacementHandle()
+	if err != nil {
+		return nil, apperror.NewInternalError("Failed to generate replacement handle", err)
+	}
+
+	expiresAt := time.Now().Add(s.config.HandleTransferTTL)
+	err = s.userRepo.ReleaseHandleForTransfer(ctx, repository.ReleaseHandleParams{
+		FromUserID:        source.UserID,
+		ToUserID:          target.UserID,
+		Handle:            source.Handle,
+		ReplacementHandle: replacement,
+		ExpiresAt:         expiresAt,
+	})
+	if err != nil {
+		s.logger.Errorf("Failed to release handle %s for user ID %s: %v", source.Handle, id, err)
+		return nil, err
+	}
+
+	s.audit(ctx, repository.AuditEntry{
+		ActorID:  source.UserID,
+		Action:   AuditHandleTransferInitiated,
+		TargetID: &target.UserID,
+		Details: map[string]interface{}{
+			"handle":             source.Handle,
+			"replacement_handle": replacement,
+			"expires_at":         expiresAt.Format(time.RFC3339),
+		},
+	})
+
+	s.logger.Infof("Handle %s reserved for user ID %s until %v", source.Handle, target.UserID, expiresAt)
+	return &HandleTransferDTO{
+		Handle:       source.Handle,
+		TargetHandle: target.Handle,
+		NewHandle:    replacement,
+		ExpiresAt:    expiresAt.Format(time.RFC3339),
+	}, nil
+}
+
+// ClaimHandle takes a handle another account reserved for this user. The
+// user's previous handle is kept so links to it keep redirecting.
+func (s *userService) ClaimHandle(ctx context.Context, id string, input HandleClaimInput) (*UserDTO, error) {
+	s.logger.Infof("User ID: %s claiming handle: %s", id, input.Handle)
+
+	userID, err := parseUUID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.reauthenticate(ctx, userID, input.ReauthInput); err != nil {
+		return nil, err
+	}
+
+	currentUser, err := s.userRepo.GetUser(ctx, userID)
+	if err != nil {
+		s.logger.Errorf("Failed to get user with ID %s: %v", id, err)
+		return nil, err
+	}
+
+	if err := s.userRepo.ClaimHandleTransfer(ctx, userID, input.Handle); err != nil {
+		s.logger.Warnf("Failed to claim handle %s for user ID %s: %v", input.Handle, id, err)
+		return nil, err
+	}
+
+	if err := s.userRepo.RecordHandleChange(ctx, userID, currentUser.Handle); err != nil {
+		s.logger.Warnf("Failed to record previous handle for user ID %s: %v", id, err)
+	}
+
+	s.audit(ctx, repository.AuditEntry{
+		ActorID: userID,
+		Action:  AuditHandleTransferClaimed,
+		Details: map[string]interface{}{
+			"handle":          input.Handle,
+			"previous_handle": currentUser.Handle,
+		},
+	})
+
+	updatedUser, err := s.userRepo.GetUser(ctx, userID)
+	if err != nil {
+		return nil, apperror.NewInternalError("Failed to retrieve updated user", err)
+	}
+
+	s.logger.Infof("Handle %s claimed by user ID: %s", input.Handle, id)
+	return mapUserToDTO(updatedUser), nil
+}
+
+// reauthenticate confirms the caller's credentials before a sensitive
+// account change
+func (s *userService) reauthenticate(ctx context.Context, userID uuid.UUID, input ReauthInput) error {
+	s.mu.RLock()
+	reauth := s.reauth
+	s.mu.RUnlock()
+
+	if reauth == nil {
+		s.logger.Errorf("Re-authentication requested for user ID %s but no authenticator is set", userID)
+		return apperror.NewInternalError("Re-authentication is not available", nil)
+	}
+
+	return reauth.Reauthenticate(ctx, userID.String(), input.Password, input.Code)
+}
+
+// audit records an entry without failing the action it describes
+func (s *userService) audit(ctx context.Context, entry repository.AuditEntry) {
+	if err := s.auditRepo.Record(ctx, entry); err != nil {
+		s.logger.Warnf("Failed to record audit entry %s for user ID %s: %v", entry.Action, entry.ActorID, err)
+	}
+}
+
+func generateReplacementHandle() (string, error) {
+	suffix, err := token.GenerateRandomString(8, "abcdefghijklmnopqrstuvwxyz0123456789")
+	if err != nil {
+		return "", err
+	}
+	return "user-" + suffix, nil
+}
+
+func mapAvatarToDTO(avatar *db.UserAvatar) *AvatarDTO {
+	dto := &AvatarDTO{
+		ID:       avatar.AvatarID.String(),
+		URL:      avatar.Url,
+		IsActive: avatar.IsActive,
+	}
+
+	if avatar.CreatedAt != nil {
+		dto.CreatedAt = avatar.CreatedAt.String()
+	}
+
+	return dto
+}
+
 func mapUserToDTO(user *db.User) *UserDTO {
 	dto := &UserDTO{
 		ID:        user.UserID.String(),
@@ -417,6 +996,9 @@ func mapUserToDTO(user *db.User) *UserDTO {
 		IsPremium: user.IsPremium != nil && *user.IsPremium,
 		IsAdmin:   user.IsAdmin != nil && *user.IsAdmin,
 		Onboarded: user.Onboarded != nil && *user.Onboarded,
+
+		AnalyticsEnabled: user.AnalyticsEnabled,
+		Visibility:       user.Visibility,
 	}
 
 	if user.FirstName != nil {
@@ -441,6 +1023,11 @@ func mapUserToDTO(user *db.User) *UserDTO {
 
 	if user.CustomDomain != nil {
 		dto.CustomDomain = *user.CustomDomain
+		dto.CustomDomainVerified = user.CustomDomainVerifiedAt != nil
+	}
+
+	if user.Locale != nil {
+		dto.Locale = *user.Locale
 	}
 
 	if user.CreatedAt != nil {
@@ -0,0 +1,320 @@
+// test/unit/account_deletion_test.go
+package unit
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/0xsj/mios.io/api/auth"
+	"github.com/0xsj/mios.io/api/user"
+	db "github.com/0xsj/mios.io/db/sqlc"
+	"github.com/0xsj/mios.io/log"
+	"github.com/0xsj/mios.io/middleware"
+	"github.com/0xsj/mios.io/pkg/errors"
+	"github.com/0xsj/mios.io/pkg/password"
+	"github.com/0xsj/mios.io/repository"
+	"github.com/0xsj/mios.io/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+const deletionTestPassword = "correct-horse-battery-staple"
+
+// deletionUserRepo hides its one user once soft deleted, like the real
+// lookups, and serves purges from a fixed number of deleted rows
+type deletionUserRepo struct {
+	repository.UserRepository
+	user    *db.User
+	pending int64
+	cutoffs []time.Time
+}
+
+func (r *deletionUserRepo) GetUser(ctx context.Context, userID uuid.UUID) (*db.User, error) {
+	if r.user.DeletedAt != nil {
+		return nil, errors.NewNotFoundError("User not found", nil)
+	}
+	return r.user, nil
+}
+
+func (r *deletionUserRepo) GetUserIncludingDeleted(ctx context.Context, userID uuid.UUID) (*db.User, error) {
+	return r.user, nil
+}
+
+func (r *deletionUserRepo) GetUserByEmailIncludingDeleted(ctx context.Context, email string) (*db.User, error) {
+	if email != r.user.Email {
+		return nil, errors.NewNotFoundError("User not found", nil)
+	}
+	return r.user, nil
+}
+
+func (r *deletionUserRepo) SoftDeleteUser(ctx context.Context, userID uuid.UUID) (time.Time, error) {
+	if r.user.DeletedAt != nil {
+		return time.Time{}, errors.NewNotFoundError("User not found", nil)
+	}
+	now := time.Now()
+	r.user.DeletedAt = &now
+	return now, nil
+}
+
+func (r *deletionUserRepo) RestoreUser(ctx context.Context, userID uuid.UUID) error {
+	if r.user.DeletedAt == nil {
+		return errors.NewNotFoundError("No pending account deletion found", nil)
+	}
+	r.user.DeletedAt = nil
+	return nil
+}
+
+func (r *deletionUserRepo) PurgeDeletedUsers(ctx context.Context, deletedBefore time.Time, limit int) (int64, error) {
+	r.cutoffs = append(r.cutoffs, deletedBefore)
+	removed := min(r.pending, int64(limit))
+	r.pending -= removed
+	return removed, nil
+}
+
+// deletionAuthRepo holds one password and counts refresh tokens still usable
+type deletionAuthRepo struct {
+	repository.AuthRepository
+	auth          *db.Auth
+	refreshTokens int
+}
+
+func (r *deletionAuthRepo) GetAuthByUserID(ctx context.Context, userID uuid.UUID) (*db.Auth, error) {
+	return r.auth, nil
+}
+
+func (r *deletionAuthRepo) UpdateLastLogin(ctx context.Context, userID uuid.UUID) error {
+	return nil
+}
+
+func (r *deletionAuthRepo) StoreRefreshToken(ctx context.Context, params repository.StoreRefreshTokenParams) error {
+	r.refreshTokens++
+	return nil
+}
+
+func (r *deletionAuthRepo) ListUnverifiedUsersCreatedBefore(ctx context.Context, before time.Time, limit int) ([]repository.UnverifiedUser, error) {
+	return []repository.UnverifiedUser{{UserID: r.auth.UserID, Username: "jane", Email: "jane@example.com"}}, nil
+}
+
+func (r *deletionAuthRepo) InvalidateUserRefreshTokens(ctx context.Context, userID uuid.UUID) error {
+	r.refreshTokens = 0
+	return nil
+}
+
+type deletionSessionRepo struct {
+	repository.SessionRepository
+	sessions map[uuid.UUID]*db.AuthSession
+}
+
+func (r *deletionSessionRepo) CreateSession(ctx context.Context, params repository.CreateSessionParams) (*db.AuthSession, error) {
+	session := &db.AuthSession{SessionID: params.SessionID, UserID: params.UserID, ExpiresAt: params.ExpiresAt}
+	r.sessions[params.SessionID] = session
+	return session, nil
+}
+
+func (r *deletionSessionRepo) GetSession(ctx context.Context, sessionID uuid.UUID) (*db.AuthSession, error) {
+	session, ok := r.sessions[sessionID]
+	if !ok {
+		return nil, errors.NewNotFoundError("Session not found", nil)
+	}
+	return session, nil
+}
+
+func (r *deletionSessionRepo) RevokeUserSessions(ctx context.Context, userID uuid.UUID) error {
+	now := time.Now()
+	for _, session := range r.sessions {
+		if session.UserID == userID && session.RevokedAt == nil {
+			session.RevokedAt = &now
+		}
+	}
+	return nil
+}
+
+type recordingAuditRepo struct {
+	repository.AuditRepository
+	entries []repository.AuditEntry
+}
+
+func (r *recordingAuditRepo) Record(ctx context.Context, entry repository.AuditEntry) error {
+	r.entries = append(r.entries, entry)
+	return nil
+}
+
+type AccountDeletionTestSuite struct {
+	suite.Suite
+	ctx      context.Context
+	repo     *deletionUserRepo
+	auth     *deletionAuthRepo
+	sessions *deletionSessionRepo
+	audit    *recordingAuditRepo
+	svc      service.UserService
+}
+
+func (suite *AccountDeletionTestSuite) SetupTest() {
+	suite.ctx = context.Background()
+	suite.repo = &deletionUserRepo{user: &db.User{UserID: uuid.New(), Handle: "jane", Username: "jane", Email: "jane@example.com"}}
+	suite.audit = &recordingAuditRepo{}
+
+	hash, salt, err := password.HashPassword(deletionTestPassword)
+	require.NoError(suite.T(), err)
+	suite.auth = &deletionAuthRepo{auth: &db.Auth{UserID: suite.repo.user.UserID, PasswordHash: &hash, Salt: &salt}}
+	suite.sessions = &deletionSessionRepo{sessions: map[uuid.UUID]*db.AuthSession{}}
+
+	logger := log.Development().WithLayer("AccountDeletionTest")
+	suite.svc = service.NewUserService(suite.repo, suite.auth, suite.sessions, nil, suite.audit, nil, nil, nil, service.UserConfig{}, logger)
+}
+
+func (suite *AccountDeletionTestSuite) TestDeletedUserIsHiddenUntilRestored() {
+	id := suite.repo.user.UserID.String()
+
+	deletion, err := suite.svc.RequestAccountDeletion(suite.ctx, id)
+	require.NoError(suite.T(), err)
+
+	deletedAt, err := time.Parse(time.RFC3339, deletion.DeletedAt)
+	require.NoError(suite.T(), err)
+	purgeAt, err := time.Parse(time.RFC3339, deletion.PurgeAt)
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 30*24*time.Hour, purgeAt.Sub(deletedAt), "grace period defaults to 30 days")
+
+	_, err = suite.svc.GetUser(suite.ctx, id)
+	assert.True(suite.T(), errors.IsNotFound(err))
+	_, err = suite.svc.RequestAccountDeletion(suite.ctx, id)
+	assert.True(suite.T(), errors.IsNotFound(err), "an account can only be deleted once")
+
+	user, err := suite.svc.CancelAccountDeletion(suite.ctx, id)
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "jane", user.Handle)
+
+	_, err = suite.svc.GetUser(suite.ctx, id)
+	assert.NoError(suite.T(), err)
+
+	require.Len(suite.T(), suite.audit.entries, 2)
+	assert.Equal(suite.T(), service.AuditAccountDeletionRequested, suite.audit.entries[0].Action)
+	assert.Equal(suite.T(), service.AuditAccountDeletionCancelled, suite.audit.entries[1].Action)
+}
+
+func (suite *AccountDeletionTestSuite) TestCancelWithoutPendingDeletion() {
+	_, err := suite.svc.CancelAccountDeletion(suite.ctx, suite.repo.user.UserID.String())
+	assert.True(suite.T(), errors.IsNotFound(err))
+}
+
+func (suite *AccountDeletionTestSuite) TestPurgeRemovesExpiredInBatches() {
+	logger := log.Development().WithLayer("AccountDeletionTest")
+	svc := service.NewUserService(suite.repo, suite.auth, suite.sessions, nil, suite.audit, nil, nil, nil, service.UserConfig{
+		DeletionGracePeriod: 7 * 24 * time.Hour,
+	}, logger)
+	suite.repo.pending = 250
+
+	before := time.Now()
+	removed, err := svc.PurgeDeletedUsers(suite.ctx)
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), int64(250), removed)
+
+	require.Len(suite.T(), suite.repo.cutoffs, 3)
+	assert.WithinDuration(suite.T(), before.Add(-7*24*time.Hour), suite.repo.cutoffs[0], time.Second)
+}
+
+// TestUnverifiedCleanupSoftDeletes checks stale unverified accounts get the
+// deletion grace period instead of being removed outright
+func (suite *AccountDeletionTestSuite) TestUnverifiedCleanupSoftDeletes() {
+	logger := log.Development().WithLayer("AccountDeletionTest")
+	authService := service.NewAuthService(suite.repo, suite.auth, nil, nil, suite.sessions, nil, nil,
+		"account-deletion-test-secret", 0, service.AuthConfig{AccountDeleter: suite.svc}, logger, "http://localhost")
+
+	result, err := authService.CleanupUnverifiedAccounts(suite.ctx, service.CleanupUnverifiedInput{
+		OlderThan: 24 * time.Hour,
+		Action:    service.UnverifiedActionDelete,
+	})
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 1, result.Processed)
+	assert.NotNil(suite.T(), suite.repo.user.DeletedAt)
+
+	_, err = suite.svc.CancelAccountDeletion(suite.ctx, suite.repo.user.UserID.String())
+	assert.NoError(suite.T(), err, "a cleaned up account can still be restored")
+}
+
+// TestOwnerCanSignInAndRestore runs the self-service flow over HTTP: a
+// deleted account's sessions stop working, and signing in again only yields
+// a token for the restore endpoint
+func (suite *AccountDeletionTestSuite) TestOwnerCanSignInAndRestore() {
+	logger := log.Development().WithLayer("AccountDeletionTest")
+	authService := service.NewAuthService(suite.repo, suite.auth, nil, nil, suite.sessions, nil, nil,
+		"account-deletion-test-secret", 0, service.AuthConfig{}, logger, "http://localhost")
+	authHandler := auth.NewHandler(authService, logger)
+	userHandler := user.NewHandler(suite.svc, logger)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/api/auth/login", authHandler.Login)
+	router.GET("/api/users/:id", middleware.AuthMiddleware(authService, logger), userHandler.GetUser)
+	router.DELETE("/api/users/:id", middleware.AuthMiddleware(authService, logger), userHandler.DeleteUser)
+	router.POST("/api/users/:id/restore", middleware.AccountRestoreMiddleware(authService, logger), userHandler.CancelAccountDeletion)
+
+	id := suite.repo.user.UserID.String()
+	userPath := "/api/users/" + id
+
+	status, login := suite.serve(router, http.MethodPost, "/api/auth/login", "", `{"email":"jane@example.com","password":"`+deletionTestPassword+`"}`)
+	require.Equal(suite.T(), http.StatusOK, status)
+	accessToken, _ := login["access_token"].(string)
+	require.NotEmpty(suite.T(), accessToken)
+	require.Equal(suite.T(), 1, suite.auth.refreshTokens)
+
+	status, _ = suite.serve(router, http.MethodDelete, userPath, accessToken, "")
+	require.Equal(suite.T(), http.StatusOK, status)
+	assert.Zero(suite.T(), suite.auth.refreshTokens, "refresh tokens are invalidated on deletion")
+
+	status, _ = suite.serve(router, http.MethodGet, userPath, accessToken, "")
+	assert.Equal(suite.T(), http.StatusUnauthorized, status, "sessions are revoked on deletion")
+
+	status, login = suite.serve(router, http.MethodPost, "/api/auth/login", "", `{"email":"jane@example.com","password":"`+deletionTestPassword+`"}`)
+	require.Equal(suite.T(), http.StatusOK, status)
+	assert.Equal(suite.T(), true, login["pending_deletion"])
+	assert.Empty(suite.T(), login["access_token"])
+	restoreToken, _ := login["restore_token"].(string)
+	require.NotEmpty(suite.T(), restoreToken)
+
+	status, _ = suite.serve(router, http.MethodGet, userPath, restoreToken, "")
+	assert.Equal(suite.T(), http.StatusUnauthorized, status, "a restore token only opens the restore endpoint")
+	status, _ = suite.serve(router, http.MethodPost, "/api/users/"+uuid.NewString()+"/restore", restoreToken, "")
+	assert.Equal(suite.T(), http.StatusForbidden, status)
+
+	status, _ = suite.serve(router, http.MethodPost, userPath+"/restore", restoreToken, "")
+	require.Equal(suite.T(), http.StatusOK, status)
+	assert.Nil(suite.T(), suite.repo.user.DeletedAt)
+
+	status, _ = suite.serve(router, http.MethodPost, userPath+"/restore", restoreToken, "")
+	assert.Equal(suite.T(), http.StatusUnauthorized, status, "the restore token is spent once the account is back")
+
+	status, login = suite.serve(router, http.MethodPost, "/api/auth/login", "", `{"email":"jane@example.com","password":"`+deletionTestPassword+`"}`)
+	require.Equal(suite.T(), http.StatusOK, status)
+	assert.NotEmpty(suite.T(), login["access_token"])
+}
+
+// serve sends one request through the router and returns the status and the
+// response's data object
+func (suite *AccountDeletionTestSuite) serve(router http.Handler, method, path, bearer, body string) (int, map[string]any) {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if bearer != "" {
+		req.Header.Set("Authorization", "Bearer "+bearer)
+	}
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	var resp struct {
+		Data map[string]any `json:"data"`
+	}
+	_ = json.Unmarshal(rec.Body.Bytes(), &resp)
+	return rec.Code, resp.Data
+}
+
+func TestAccountDeletionTestSuite(t *testing.T) {
+	suite.Run(t, new(AccountDeletionTestSuite))
+}
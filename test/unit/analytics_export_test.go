@@ -0,0 +1,170 @@
+// test/unit/analytics_export_test.go
+package unit
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"testing"
+	"time"
+
+	db "github.com/0xsj/mios.io/db/sqlc"
+	"github.com/0xsj/mios.io/log"
+	"github.com/0xsj/mios.io/repository"
+	"github.com/0xsj/mios.io/service"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+// exportAnalyticsRepo serves rows in clicked_at order from the cursor it is
+// given, recording each page request
+type exportAnalyticsRepo struct {
+	repository.AnalyticsRepository
+	rows  []*db.ListUserAnalyticsForExportRow
+	pages []repository.ExportPageParams
+}
+
+func (r *exportAnalyticsRepo) ListAnalyticsForExport(ctx context.Context, params repository.ExportPageParams) ([]*db.ListUserAnalyticsForExportRow, error) {
+	r.pages = append(r.pages, params)
+
+	var page []*db.ListUserAnalyticsForExportRow
+	for _, row := range r.rows {
+		if params.AfterID != uuid.Nil && !row.Analytic.ClickedAt.After(params.AfterClickedAt) {
+			continue
+		}
+		page = append(page, row)
+		if len(page) == params.Limit {
+			break
+		}
+	}
+	return page, nil
+}
+
+type exportUserRepo struct {
+	repository.UserRepository
+	user *db.User
+}
+
+func (r *exportUserRepo) GetUser(ctx context.Context, userID uuid.UUID) (*db.User, error) {
+	return r.user, nil
+}
+
+type AnalyticsExportTestSuite struct {
+	suite.Suite
+	userID uuid.UUID
+	repo   *exportAnalyticsRepo
+	svc    service.AnalyticsService
+}
+
+func (suite *AnalyticsExportTestSuite) SetupTest() {
+	suite.userID = uuid.New()
+	suite.repo = &exportAnalyticsRepo{}
+
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	pageView := false
+	for i := 0; i < 5; i++ {
+		clickedAt := base.Add(time.Duration(i) * time.Minute)
+		contentType := "link"
+		suite.repo.rows = append(suite.repo.rows, &db.ListUserAnalyticsForExportRow{
+			Analytic: db.Analytic{
+				AnalyticsID:     uuid.New(),
+				ItemID:          uuid.New(),
+				UserID:          suite.userID,
+				ClickedAt:       &clickedAt,
+				PageView:        &pageView,
+				InteractionType: "click",
+			},
+			ContentType: &contentType,
+		})
+	}
+
+	users := &exportUserRepo{user: &db.User{UserID: suite.userID, Username: "tester"}}
+	suite.svc = service.NewAnalyticsService(suite.repo, nil, users, nil, nil, nil, nil,
+		service.AnalyticsExportConfig{BatchSize: 2},
+		service.AnalyticsConfig{},
+		log.Development().WithLayer("AnalyticsExportTest"))
+}
+
+func (suite *AnalyticsExportTestSuite) TestStreamsEveryRowInBatches() {
+	reader, err := suite.svc.ExportUserAnalytics(context.Background(), suite.userID.String(), service.ExportInput{
+		StartDate: "2025-01-01T00:00:00Z",
+		EndDate:   "2025-01-02T00:00:00Z",
+	})
+	require.NoError(suite.T(), err)
+	defer reader.Close()
+
+	body, err := io.ReadAll(reader)
+	require.NoError(suite.T(), err)
+
+	var entries []service.AnalyticsDTO
+	require.NoError(suite.T(), json.Unmarshal(body, &entries))
+	require.Len(suite.T(), entries, 5)
+	for i, entry := range entries {
+		assert.Equal(suite.T(), suite.repo.rows[i].Analytic.AnalyticsID.String(), entry.ID)
+	}
+
+	// Two full pages and a short final one, each continuing from the last row
+	require.Len(suite.T(), suite.repo.pages, 3)
+	assert.Equal(suite.T(), uuid.Nil, suite.repo.pages[0].AfterID)
+	assert.Equal(suite.T(), suite.repo.rows[1].Analytic.AnalyticsID, suite.repo.pages[1].AfterID)
+	assert.Equal(suite.T(), suite.repo.rows[3].Analytic.AnalyticsID, suite.repo.pages[2].AfterID)
+}
+
+func (suite *AnalyticsExportTestSuite) TestEmptyRangeIsEmptyArray() {
+	suite.repo.rows = nil
+
+	reader, err := suite.svc.ExportUserAnalytics(context.Background(), suite.userID.String(), service.ExportInput{
+		StartDate: "2025-01-01T00:00:00Z",
+		EndDate:   "2025-01-02T00:00:00Z",
+	})
+	require.NoError(suite.T(), err)
+	defer reader.Close()
+
+	body, err := io.ReadAll(reader)
+	require.NoError(suite.T(), err)
+	assert.JSONEq(suite.T(), "[]", string(body))
+}
+
+func (suite *AnalyticsExportTestSuite) TestCSVHasHeaderAndOneLinePerRow() {
+	referrer := "=HYPERLINK(\"http://evil.example\")"
+	suite.repo.rows[0].Analytic.Referrer = &referrer
+	suite.repo.rows[1].ContentType = nil
+
+	reader, err := suite.svc.ExportUserAnalytics(context.Background(), suite.userID.String(), service.ExportInput{
+		StartDate: "2025-01-01T00:00:00Z",
+		EndDate:   "2025-01-02T00:00:00Z",
+		Format:    service.ExportFormatCSV,
+	})
+	require.NoError(suite.T(), err)
+	defer reader.Close()
+
+	records, err := csv.NewReader(reader).ReadAll()
+	require.NoError(suite.T(), err)
+	require.Len(suite.T(), records, 6)
+
+	assert.Equal(suite.T(), []string{"item_id", "content_type", "clicked_at", "ip_address", "user_agent", "referrer", "page_view"}, records[0])
+	assert.Equal(suite.T(), suite.repo.rows[0].Analytic.ItemID.String(), records[1][0])
+	assert.Equal(suite.T(), "link", records[1][1])
+	assert.Equal(suite.T(), "2025-01-01T00:00:00Z", records[1][2])
+	assert.Equal(suite.T(), "'"+referrer, records[1][5])
+	assert.Equal(suite.T(), "false", records[1][6])
+
+	// A deleted item has no content type left to report
+	assert.Equal(suite.T(), "", records[2][1])
+}
+
+func (suite *AnalyticsExportTestSuite) TestRejectsUnknownFormat() {
+	_, err := suite.svc.ExportUserAnalytics(context.Background(), suite.userID.String(), service.ExportInput{
+		StartDate: "2025-01-01T00:00:00Z",
+		EndDate:   "2025-01-02T00:00:00Z",
+		Format:    "xml",
+	})
+	assert.Error(suite.T(), err)
+}
+
+func TestAnalyticsExportTestSuite(t *testing.T) {
+	suite.Run(t, new(AnalyticsExportTestSuite))
+}
@@ -0,0 +1,112 @@
+// test/unit/analytics_granularity_test.go
+package unit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	db "github.com/0xsj/mios.io/db/sqlc"
+	"github.com/0xsj/mios.io/log"
+	"github.com/0xsj/mios.io/repository"
+	"github.com/0xsj/mios.io/service"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+// bucketAnalyticsRepo returns fixed buckets and records the params asked for
+type bucketAnalyticsRepo struct {
+	repository.AnalyticsRepository
+	buckets []repository.DailyAnalytics
+	calls   []repository.TimeRangeParams
+}
+
+func (r *bucketAnalyticsRepo) GetUserAnalyticsByTimeRange(ctx context.Context, params repository.TimeRangeParams) ([]repository.DailyAnalytics, error) {
+	r.calls = append(r.calls, params)
+	return r.buckets, nil
+}
+
+type AnalyticsGranularityTestSuite struct {
+	suite.Suite
+	user *db.User
+	repo *bucketAnalyticsRepo
+	svc  service.AnalyticsService
+}
+
+func (suite *AnalyticsGranularityTestSuite) SetupTest() {
+	suite.user = &db.User{UserID: uuid.New(), Username: "buckets"}
+	suite.repo = &bucketAnalyticsRepo{}
+	suite.svc = service.NewAnalyticsService(suite.repo, nil, &exportUserRepo{user: suite.user}, nil, nil, nil, nil,
+		service.AnalyticsExportConfig{},
+		service.AnalyticsConfig{},
+		log.Development().WithLayer("AnalyticsGranularityTest"))
+}
+
+func (suite *AnalyticsGranularityTestSuite) query(granularity, start, end string) (*service.TimeRangeAnalyticsDTO, error) {
+	return suite.svc.GetUserAnalyticsByTimeRange(context.Background(), suite.user.UserID.String(), service.TimeRangeInput{
+		StartDate:   start,
+		EndDate:     end,
+		Granularity: granularity,
+	})
+}
+
+func (suite *AnalyticsGranularityTestSuite) TestDefaultsToDailyBuckets() {
+	suite.repo.buckets = []repository.DailyAnalytics{
+		{Day: time.Date(2025, 6, 2, 0, 0, 0, 0, time.UTC), Clicks: 4},
+		{Day: time.Date(2025, 6, 3, 0, 0, 0, 0, time.UTC), Clicks: 1},
+	}
+
+	result, err := suite.query("", "2025-06-01T00:00:00Z", "2025-06-30T23:59:59Z")
+	require.NoError(suite.T(), err)
+
+	require.Len(suite.T(), suite.repo.calls, 1)
+	assert.Equal(suite.T(), service.GranularityDay, suite.repo.calls[0].Granularity)
+	assert.Equal(suite.T(), service.GranularityDay, result.Granularity)
+	assert.Equal(suite.T(), int64(5), result.TotalClicks)
+	assert.Equal(suite.T(), "2025-06-02", result.DailyClicks[0].Date)
+	assert.Equal(suite.T(), "Monday", result.DailyClicks[0].DayOfWeek)
+}
+
+func (suite *AnalyticsGranularityTestSuite) TestHourlyBucketsCarryTimestamps() {
+	suite.repo.buckets = []repository.DailyAnalytics{
+		{Day: time.Date(2025, 6, 2, 9, 0, 0, 0, time.UTC), Clicks: 2},
+		{Day: time.Date(2025, 6, 2, 14, 0, 0, 0, time.UTC), Clicks: 3},
+	}
+
+	result, err := suite.query(service.GranularityHour, "2025-06-02T00:00:00Z", "2025-06-02T23:59:59Z")
+	require.NoError(suite.T(), err)
+
+	assert.Equal(suite.T(), service.GranularityHour, suite.repo.calls[0].Granularity)
+	require.Len(suite.T(), result.DailyClicks, 2)
+	assert.Equal(suite.T(), "2025-06-02T09:00:00Z", result.DailyClicks[0].Date)
+	assert.Equal(suite.T(), "2025-06-02T14:00:00Z", result.DailyClicks[1].Date)
+	assert.Equal(suite.T(), "Monday", result.DailyClicks[1].DayOfWeek)
+}
+
+func (suite *AnalyticsGranularityTestSuite) TestWeeklyBucketsUseTheWeekStart() {
+	suite.repo.buckets = []repository.DailyAnalytics{
+		{Day: time.Date(2025, 6, 9, 0, 0, 0, 0, time.UTC), Clicks: 12},
+	}
+
+	result, err := suite.query(service.GranularityWeek, "2025-06-01T00:00:00Z", "2025-06-30T23:59:59Z")
+	require.NoError(suite.T(), err)
+
+	assert.Equal(suite.T(), service.GranularityWeek, suite.repo.calls[0].Granularity)
+	assert.Equal(suite.T(), "2025-06-09", result.DailyClicks[0].Date)
+}
+
+func (suite *AnalyticsGranularityTestSuite) TestRejectsInvalidGranularity() {
+	_, err := suite.query("minute", "2025-06-01T00:00:00Z", "2025-06-02T00:00:00Z")
+	assert.Error(suite.T(), err)
+
+	_, err = suite.query(service.GranularityHour, "2025-01-01T00:00:00Z", "2025-06-01T00:00:00Z")
+	assert.Error(suite.T(), err, "hourly buckets over months are refused")
+
+	assert.Empty(suite.T(), suite.repo.calls)
+}
+
+func TestAnalyticsGranularityTestSuite(t *testing.T) {
+	suite.Run(t, new(AnalyticsGranularityTestSuite))
+}
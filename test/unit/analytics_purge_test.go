@@ -0,0 +1,75 @@
+// test/unit/analytics_purge_test.go
+package unit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/0xsj/mios.io/log"
+	"github.com/0xsj/mios.io/repository"
+	"github.com/0xsj/mios.io/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+// purgeAnalyticsRepo reports a fixed number of removed rows and records the
+// cutoffs it was asked to purge before
+type purgeAnalyticsRepo struct {
+	repository.AnalyticsRepository
+	expired int64
+	older   int64
+	cutoffs []time.Time
+}
+
+func (r *purgeAnalyticsRepo) DeleteExpiredAnalytics(ctx context.Context, params repository.PurgeParams) (int64, error) {
+	removed := r.expired
+	r.expired = 0
+	return removed, nil
+}
+
+func (r *purgeAnalyticsRepo) PurgeAnalyticsOlderThan(ctx context.Context, cutoff time.Time) (int64, error) {
+	r.cutoffs = append(r.cutoffs, cutoff)
+	return r.older, nil
+}
+
+type AnalyticsPurgeTestSuite struct {
+	suite.Suite
+	repo *purgeAnalyticsRepo
+}
+
+func (suite *AnalyticsPurgeTestSuite) SetupTest() {
+	suite.repo = &purgeAnalyticsRepo{expired: 12, older: 30}
+}
+
+func (suite *AnalyticsPurgeTestSuite) service(retentionDays int) service.RetentionService {
+	return service.NewRetentionService(suite.repo, nil, service.RetentionConfig{RetentionDays: retentionDays},
+		log.Development().WithLayer("AnalyticsPurgeTest"), "https://example.com")
+}
+
+func (suite *AnalyticsPurgeTestSuite) TestZeroRetentionDaysKeepsAnalytics() {
+	result, err := suite.service(0).PurgeAnalytics(context.Background())
+	require.NoError(suite.T(), err)
+
+	assert.Empty(suite.T(), suite.repo.cutoffs)
+	assert.Equal(suite.T(), int64(12), result.ExpiredRemoved)
+	assert.Equal(suite.T(), int64(0), result.RetentionRemoved)
+	assert.Equal(suite.T(), int64(12), result.TotalRemoved)
+	assert.Empty(suite.T(), result.Cutoff)
+}
+
+func (suite *AnalyticsPurgeTestSuite) TestRetentionDaysSetTheCutoff() {
+	result, err := suite.service(30).PurgeAnalytics(context.Background())
+	require.NoError(suite.T(), err)
+
+	require.Len(suite.T(), suite.repo.cutoffs, 1)
+	assert.WithinDuration(suite.T(), time.Now().Add(-30*24*time.Hour), suite.repo.cutoffs[0], time.Minute)
+	assert.Equal(suite.T(), int64(30), result.RetentionRemoved)
+	assert.Equal(suite.T(), int64(42), result.TotalRemoved)
+	assert.NotEmpty(suite.T(), result.Cutoff)
+}
+
+func TestAnalyticsPurgeTestSuite(t *testing.T) {
+	suite.Run(t, new(AnalyticsPurgeTestSuite))
+}
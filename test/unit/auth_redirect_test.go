@@ -0,0 +1,79 @@
+// test/unit/auth_redirect_test.go
+package unit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/0xsj/mios.io/log"
+	"github.com/0xsj/mios.io/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type AuthRedirectTestSuite struct {
+	suite.Suite
+	authService service.AuthService
+}
+
+func (suite *AuthRedirectTestSuite) SetupSuite() {
+	logger := log.Development().WithLayer("AuthRedirectTest")
+	suite.authService = service.NewAuthService(
+		nil, nil, nil, nil, nil, nil, nil,
+		"test-secret",
+		time.Hour,
+		service.AuthConfig{AllowedRedirectHosts: []string{"app.example.com", "localhost:3000"}},
+		logger,
+		"https://api.example.com",
+	)
+}
+
+func (suite *AuthRedirectTestSuite) TestEmptyRedirect() {
+	redirect, err := suite.authService.ResolveRedirect("")
+	require.NoError(suite.T(), err)
+	assert.Empty(suite.T(), redirect)
+}
+
+func (suite *AuthRedirectTestSuite) TestRelativePathResolvesToOwnOrigin() {
+	redirect, err := suite.authService.ResolveRedirect("/dashboard?tab=links")
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "https://api.example.com/dashboard?tab=links", redirect)
+}
+
+func (suite *AuthRedirectTestSuite) TestAllowedHosts() {
+	allowed := []string{
+		"https://api.example.com/welcome",
+		"https://app.example.com/welcome",
+		"https://APP.example.com:8443/welcome",
+		"http://localhost:3000/verified",
+	}
+
+	for _, target := range allowed {
+		_, err := suite.authService.ResolveRedirect(target)
+		assert.NoError(suite.T(), err, target)
+	}
+}
+
+func (suite *AuthRedirectTestSuite) TestRejectedTargets() {
+	rejected := []string{
+		"https://evil.com/phish",
+		"//evil.com/phish",
+		"/\\evil.com/phish",
+		"https://app.example.com.evil.com/",
+		"https://user@evil.com/",
+		"https://app.example.com@evil.com/",
+		"javascript:alert(1)",
+		"http://localhost:4000/",
+		"dashboard",
+	}
+
+	for _, target := range rejected {
+		_, err := suite.authService.ResolveRedirect(target)
+		assert.Error(suite.T(), err, target)
+	}
+}
+
+func TestAuthRedirectTestSuite(t *testing.T) {
+	suite.Run(t, new(AuthRedirectTestSuite))
+}
@@ -0,0 +1,393 @@
+// test/unit/auth_session_test.go
+package unit
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	db "github.com/0xsj/mios.io/db/sqlc"
+	"github.com/0xsj/mios.io/log"
+	"github.com/0xsj/mios.io/pkg/email"
+	"github.com/0xsj/mios.io/pkg/errors"
+	"github.com/0xsj/mios.io/pkg/password"
+	"github.com/0xsj/mios.io/repository"
+	"github.com/0xsj/mios.io/service"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+const sessionTestPassword = "correct-horse-battery-staple"
+
+type sessionUserRepo struct {
+	repository.UserRepository
+	user *db.User
+}
+
+func (r *sessionUserRepo) GetUser(ctx context.Context, userID uuid.UUID) (*db.User, error) {
+	if userID != r.user.UserID {
+		return nil, errors.NewNotFoundError("User not found", nil)
+	}
+	return r.user, nil
+}
+
+func (r *sessionUserRepo) GetUserByEmailIncludingDeleted(ctx context.Context, email string) (*db.User, error) {
+	if email != r.user.Email {
+		return nil, errors.NewNotFoundError("User not found", nil)
+	}
+	return r.user, nil
+}
+
+// sessionAuthRepo holds one account's auth record and the refresh tokens
+// issued to it, consumed the way the queries do
+type sessionAuthRepo struct {
+	repository.AuthRepository
+	auth     *db.Auth
+	tokens   map[uuid.UUID]*db.RefreshToken
+	lockouts []time.Time
+}
+
+func (r *sessionAuthRepo) GetAuthByUserID(ctx context.Context, userID uuid.UUID) (*db.Auth, error) {
+	return r.auth, nil
+}
+
+func (r *sessionAuthRepo) UpdateLastLogin(ctx context.Context, userID uuid.UUID) error {
+	now := time.Now()
+	attempts := int32(0)
+	r.auth.LastLogin = &now
+	r.auth.FailedLoginAttempts = &attempts
+	return nil
+}
+
+func (r *sessionAuthRepo) IncrementFailedLoginAttempts(ctx context.Context, userID uuid.UUID) (int, error) {
+	attempts := int32(1)
+	if r.auth.FailedLoginAttempts != nil {
+		attempts = *r.auth.FailedLoginAttempts + 1
+	}
+	r.auth.FailedLoginAttempts = &attempts
+	return int(attempts), nil
+}
+
+func (r *sessionAuthRepo) SetAccountLockout(ctx context.Context, userID uuid.UUID, lockedUntil time.Time) error {
+	attempts := int32(0)
+	r.auth.LockedUntil = &lockedUntil
+	r.auth.FailedLoginAttempts = &attempts
+	r.lockouts = append(r.lockouts, lockedUntil)
+	return nil
+}
+
+func (r *sessionAuthRepo) StoreRefreshToken(ctx context.Context, params repository.StoreRefreshTokenParams) error {
+	r.tokens[params.TokenID] = &db.RefreshToken{
+		TokenID:   params.TokenID,
+		SessionID: params.SessionID,
+		UserID:    params.UserID,
+		ExpiresAt: params.ExpiresAt,
+	}
+	return nil
+}
+
+func (r *sessionAuthRepo) ConsumeRefreshToken(ctx context.Context, tokenID uuid.UUID) (bool, error) {
+	issued, ok := r.tokens[tokenID]
+	if !ok || issued.ConsumedAt != nil || !issued.ExpiresAt.After(time.Now()) {
+		return false, nil
+	}
+	now := time.Now()
+	issued.ConsumedAt = &now
+	return true, nil
+}
+
+func (r *sessionAuthRepo) GetRefreshToken(ctx context.Context, tokenID uuid.UUID) (*db.RefreshToken, error) {
+	issued, ok := r.tokens[tokenID]
+	if !ok {
+		return nil, errors.NewNotFoundError("Refresh token not found", nil)
+	}
+	return issued, nil
+}
+
+func (r *sessionAuthRepo) InvalidateRefreshTokenFamily(ctx context.Context, sessionID uuid.UUID) error {
+	now := time.Now()
+	for _, issued := range r.tokens {
+		if issued.SessionID == sessionID && issued.ConsumedAt == nil {
+			issued.ConsumedAt = &now
+		}
+	}
+	return nil
+}
+
+// sessionStore keeps sessions with the hash of their current refresh token
+type sessionStore struct {
+	repository.SessionRepository
+	sessions map[uuid.UUID]*db.AuthSession
+}
+
+func (r *sessionStore) CreateSession(ctx context.Context, params repository.CreateSessionParams) (*db.AuthSession, error) {
+	session := &db.AuthSession{
+		SessionID:        params.SessionID,
+		UserID:           params.UserID,
+		RefreshTokenHash: params.RefreshTokenHash,
+		ExpiresAt:        params.ExpiresAt,
+	}
+	r.sessions[params.SessionID] = session
+	return session, nil
+}
+
+func (r *sessionStore) GetSession(ctx context.Context, sessionID uuid.UUID) (*db.AuthSession, error) {
+	session, ok := r.sessions[sessionID]
+	if !ok {
+		return nil, errors.NewNotFoundError("Session not found", nil)
+	}
+	return session, nil
+}
+
+func (r *sessionStore) RotateSessionToken(ctx context.Context, sessionID uuid.UUID, oldHash, newHash string, expiresAt time.Time) (*db.AuthSession, error) {
+	session, ok := r.sessions[sessionID]
+	if !ok || session.RevokedAt != nil || session.RefreshTokenHash != oldHash {
+		return nil, errors.NewNotFoundError("Session not found", nil)
+	}
+	session.RefreshTokenHash = newHash
+	session.ExpiresAt = expiresAt
+	return session, nil
+}
+
+func (r *sessionStore) RevokeSession(ctx context.Context, userID, sessionID uuid.UUID) (bool, error) {
+	session, ok := r.sessions[sessionID]
+	if !ok || session.UserID != userID || session.RevokedAt != nil {
+		return false, nil
+	}
+	now := time.Now()
+	session.RevokedAt = &now
+	return true, nil
+}
+
+func (r *sessionStore) RevokeUserSessions(ctx context.Context, userID uuid.UUID) error {
+	now := time.Now()
+	for _, session := range r.sessions {
+		if session.UserID == userID && session.RevokedAt == nil {
+			session.RevokedAt = &now
+		}
+	}
+	return nil
+}
+
+// newSessionFakes sets up one account that signs in with sessionTestPassword
+func newSessionFakes(t *testing.T) (*sessionUserRepo, *sessionAuthRepo, *sessionStore) {
+	user := &db.User{UserID: uuid.New(), Username: "jane", Email: "jane@example.com"}
+
+	hash, salt, err := password.HashPassword(sessionTestPassword)
+	require.NoError(t, err)
+
+	return &sessionUserRepo{user: user},
+		&sessionAuthRepo{auth: &db.Auth{UserID: user.UserID, PasswordHash: &hash, Salt: &salt}, tokens: map[uuid.UUID]*db.RefreshToken{}},
+		&sessionStore{sessions: map[uuid.UUID]*db.AuthSession{}}
+}
+
+// smtpSink is a minimal SMTP server that records the subject of every
+// message it accepts
+type smtpSink struct {
+	listener net.Listener
+	mu       sync.Mutex
+	subjects []string
+}
+
+// newSessionEmail returns an email client using the real templates that
+// delivers to a local sink
+func newSessionEmail(t *testing.T) (*email.EmailClient, *smtpSink) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	sink := &smtpSink{listener: listener}
+	go sink.serve()
+
+	host, port, err := net.SplitHostPort(listener.Addr().String())
+	require.NoError(t, err)
+	t.Setenv("EMAIL_HOST", host)
+	t.Setenv("EMAIL_PORT", port)
+
+	templates, err := email.NewTemplateManager("../../pkg/email/templates", "en")
+	require.NoError(t, err)
+	return email.NewEmailClient(log.Development().WithLayer("SessionEmail"), templates), sink
+}
+
+func (s *smtpSink) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *smtpSink) handle(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+
+	reply("220 localhost")
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		switch command := strings.ToUpper(strings.TrimSpace(line)); {
+		case strings.HasPrefix(command, "DATA"):
+			reply("354 go ahead")
+			s.readMessage(reader)
+			reply("250 queued")
+		case strings.HasPrefix(command, "QUIT"):
+			reply("221 bye")
+			return
+		default:
+			reply("250 ok")
+		}
+	}
+}
+
+func (s *smtpSink) readMessage(reader *bufio.Reader) {
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil || strings.TrimRight(line, "\r\n") == "." {
+			return
+		}
+		if subject, ok := strings.CutPrefix(line, "Subject: "); ok {
+			s.mu.Lock()
+			s.subjects = append(s.subjects, strings.TrimSpace(subject))
+			s.mu.Unlock()
+		}
+	}
+}
+
+func (s *smtpSink) sent() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.subjects...)
+}
+
+type AuthSessionTestSuite struct {
+	suite.Suite
+	ctx      context.Context
+	users    *sessionUserRepo
+	auth     *sessionAuthRepo
+	sessions *sessionStore
+	sink     *smtpSink
+	svc      service.AuthService
+}
+
+func (suite *AuthSessionTestSuite) SetupTest() {
+	suite.ctx = context.Background()
+	suite.users, suite.auth, suite.sessions = newSessionFakes(suite.T())
+
+	emailClient, sink := newSessionEmail(suite.T())
+	suite.sink = sink
+
+	suite.svc = service.NewAuthService(suite.users, suite.auth, nil, nil, suite.sessions, emailClient, nil,
+		"auth-session-test-secret", 0, service.AuthConfig{}, log.Development().WithLayer("AuthSessionTest"), "http://localhost")
+}
+
+func (suite *AuthSessionTestSuite) login() *service.TokenResponse {
+	tokens, err := suite.svc.Login(suite.ctx, service.LoginInput{Email: "jane@example.com", Password: sessionTestPassword})
+	require.NoError(suite.T(), err)
+	return tokens
+}
+
+func (suite *AuthSessionTestSuite) TestLogoutOnlyEndsTheCallersSession() {
+	laptop := suite.login()
+	phone := suite.login()
+
+	claims, err := suite.svc.ValidateToken(suite.ctx, laptop.AccessToken)
+	require.NoError(suite.T(), err)
+	require.NoError(suite.T(), suite.svc.Logout(suite.ctx, suite.users.user.UserID.String(), claims))
+
+	_, err = suite.svc.RefreshToken(suite.ctx, service.RefreshTokenRequest{RefreshToken: laptop.RefreshToken})
+	assert.Error(suite.T(), err, "the logged out session can no longer refresh")
+
+	refreshed, err := suite.svc.RefreshToken(suite.ctx, service.RefreshTokenRequest{RefreshToken: phone.RefreshToken})
+	require.NoError(suite.T(), err, "other devices stay signed in")
+	_, err = suite.svc.ValidateToken(suite.ctx, refreshed.AccessToken)
+	assert.NoError(suite.T(), err)
+}
+
+func (suite *AuthSessionTestSuite) TestLogoutRejectsAnotherUsersToken() {
+	tokens := suite.login()
+	claims, err := suite.svc.ValidateToken(suite.ctx, tokens.AccessToken)
+	require.NoError(suite.T(), err)
+
+	err = suite.svc.Logout(suite.ctx, uuid.NewString(), claims)
+	assert.Error(suite.T(), err)
+
+	_, err = suite.svc.ValidateToken(suite.ctx, tokens.AccessToken)
+	assert.NoError(suite.T(), err)
+}
+
+func (suite *AuthSessionTestSuite) refresh(tokens *service.TokenResponse) (*service.TokenResponse, error) {
+	return suite.svc.RefreshToken(suite.ctx, service.RefreshTokenRequest{RefreshToken: tokens.RefreshToken})
+}
+
+func (suite *AuthSessionTestSuite) sessionOf(tokens *service.TokenResponse) *db.AuthSession {
+	claims, err := suite.svc.ValidateToken(suite.ctx, tokens.AccessToken)
+	require.NoError(suite.T(), err)
+	return suite.sessions.sessions[uuid.MustParse(claims.SessionID)]
+}
+
+func (suite *AuthSessionTestSuite) TestRefreshRotatesRepeatedly() {
+	tokens := suite.login()
+	session := suite.sessionOf(tokens)
+
+	first, err := suite.refresh(tokens)
+	require.NoError(suite.T(), err)
+	second, err := suite.refresh(first)
+	require.NoError(suite.T(), err, "the rotated token refreshes in turn")
+
+	assert.NotEqual(suite.T(), first.RefreshToken, second.RefreshToken)
+	assert.Equal(suite.T(), session.SessionID, suite.sessionOf(second).SessionID, "rotation keeps the session")
+	assert.Nil(suite.T(), session.RevokedAt)
+	assert.Len(suite.T(), suite.auth.tokens, 3)
+	assert.Empty(suite.T(), suite.sink.sent())
+}
+
+// TestReusedRefreshTokenEndsTheSession replays a token that was already
+// rotated, as a thief holding a copy would
+func (suite *AuthSessionTestSuite) TestReusedRefreshTokenEndsTheSession() {
+	stolen := suite.login()
+	session := suite.sessionOf(stolen)
+
+	current, err := suite.refresh(stolen)
+	require.NoError(suite.T(), err)
+
+	_, err = suite.refresh(stolen)
+	assert.Error(suite.T(), err)
+
+	assert.NotNil(suite.T(), session.RevokedAt, "the session is revoked")
+	for _, issued := range suite.auth.tokens {
+		assert.NotNil(suite.T(), issued.ConsumedAt, "every token of the family is invalidated")
+	}
+	assert.Contains(suite.T(), suite.sink.sent(), "Security Alert: A Session Was Signed Out")
+
+	_, err = suite.refresh(current)
+	assert.Error(suite.T(), err, "the legitimate holder must sign in again")
+	_, err = suite.svc.ValidateToken(suite.ctx, current.AccessToken)
+	assert.Error(suite.T(), err)
+}
+
+func (suite *AuthSessionTestSuite) TestRevokedSessionTokenIsRejected() {
+	tokens := suite.login()
+	session := suite.sessionOf(tokens)
+	require.NoError(suite.T(), suite.svc.RevokeSession(suite.ctx, suite.users.user.UserID.String(), session.SessionID.String()))
+
+	_, err := suite.refresh(tokens)
+	assert.Error(suite.T(), err)
+	_, err = suite.svc.ValidateToken(suite.ctx, tokens.AccessToken)
+	assert.Error(suite.T(), err)
+	assert.Empty(suite.T(), suite.sink.sent(), "a revoked session is not mistaken for token reuse")
+}
+
+func TestAuthSessionTestSuite(t *testing.T) {
+	suite.Run(t, new(AuthSessionTestSuite))
+}
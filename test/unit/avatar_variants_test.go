@@ -0,0 +1,201 @@
+// test/unit/avatar_variants_test.go
+package unit
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	stderrors "errors"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/0xsj/mios.io/log"
+	"github.com/0xsj/mios.io/pkg/errors"
+	"github.com/0xsj/mios.io/pkg/storage"
+	"github.com/0xsj/mios.io/service"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+const avatarBaseURL = "http://origin.test/uploads"
+
+type AvatarVariantsTestSuite struct {
+	suite.Suite
+	dir    string
+	userID string
+	svc    service.FileService
+}
+
+func (suite *AvatarVariantsTestSuite) SetupTest() {
+	dir, err := os.MkdirTemp("", "avatar_variants_test_*")
+	require.NoError(suite.T(), err)
+	suite.dir = dir
+	suite.userID = uuid.New().String()
+
+	logger := log.Development().WithLayer("AvatarVariantsTest")
+	suite.svc = service.NewFileService(storage.NewLocalStorage(dir, avatarBaseURL, logger), nil, newMemoryFileRepo(), nil, service.FileServiceConfig{
+		MaxAvatarSize:     1 << 20,
+		AllowedImageTypes: []string{"image/jpeg", "image/png", "image/gif", "image/webp"},
+	}, logger)
+}
+
+func (suite *AvatarVariantsTestSuite) TearDownTest() {
+	os.RemoveAll(suite.dir)
+}
+
+func (suite *AvatarVariantsTestSuite) upload(data []byte, contentType, filename string) (*service.FileUploadResult, error) {
+	return suite.svc.UploadUserAvatar(context.Background(), suite.userID, service.UploadFileInput{
+		File:        bytes.NewReader(data),
+		Filename:    filename,
+		ContentType: contentType,
+	})
+}
+
+// stored decodes the file an upload URL points to
+func (suite *AvatarVariantsTestSuite) stored(url string) (image.Image, string) {
+	data, err := os.ReadFile(filepath.Join(suite.dir, strings.TrimPrefix(url, avatarBaseURL+"/")))
+	require.NoError(suite.T(), err)
+	img, format, err := image.Decode(bytes.NewReader(data))
+	require.NoError(suite.T(), err)
+	return img, format
+}
+
+func (suite *AvatarVariantsTestSuite) storedFiles() int {
+	count := 0
+	filepath.Walk(suite.dir, func(path string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() {
+			count++
+		}
+		return nil
+	})
+	return count
+}
+
+func (suite *AvatarVariantsTestSuite) assertRejected(err error) {
+	var appErr *errors.AppError
+	require.True(suite.T(), stderrors.As(err, &appErr), err)
+	assert.Equal(suite.T(), "VALIDATION_ERROR", appErr.Code)
+	assert.Zero(suite.T(), suite.storedFiles(), "nothing is stored")
+}
+
+// solidImage returns a w×h image whose left half is left and right half right
+func solidImage(w, h int, left, right color.Color) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			if x < w/2 {
+				img.Set(x, y, left)
+			} else {
+				img.Set(x, y, right)
+			}
+		}
+	}
+	return img
+}
+
+func encodePNG(t *testing.T, img image.Image) []byte {
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img))
+	return buf.Bytes()
+}
+
+// withOrientation inserts an EXIF segment with the given orientation after
+// the JPEG's start of image marker
+func withOrientation(data []byte, orientation uint16) []byte {
+	tiff := []byte("MM\x00\x2a\x00\x00\x00\x08\x00\x01")
+	tiff = append(tiff, 0x01, 0x12, 0x00, 0x03, 0x00, 0x00, 0x00, 0x01)
+	tiff = binary.BigEndian.AppendUint16(tiff, orientation)
+	tiff = append(tiff, 0, 0, 0, 0, 0, 0)
+
+	payload := append([]byte("Exif\x00\x00"), tiff...)
+	segment := []byte{0xFF, 0xE1}
+	segment = binary.BigEndian.AppendUint16(segment, uint16(len(payload)+2))
+	segment = append(segment, payload...)
+
+	out := append([]byte{}, data[:2]...)
+	out = append(out, segment...)
+	return append(out, data[2:]...)
+}
+
+// withAnimationControl turns a PNG into an APNG header by inserting an acTL
+// chunk after IHDR
+func withAnimationControl(data []byte) []byte {
+	chunk := binary.BigEndian.AppendUint32(nil, 8)
+	body := append([]byte("acTL"), 0, 0, 0, 2, 0, 0, 0, 0)
+	chunk = append(chunk, body...)
+	chunk = binary.BigEndian.AppendUint32(chunk, crc32.ChecksumIEEE(body))
+
+	const ihdrEnd = 8 + 12 + 13
+	out := append([]byte{}, data[:ihdrEnd]...)
+	out = append(out, chunk...)
+	return append(out, data[ihdrEnd:]...)
+}
+
+func (suite *AvatarVariantsTestSuite) TestProducesSquareVariants() {
+	red, blue := color.NRGBA{255, 0, 0, 255}, color.NRGBA{0, 0, 255, 255}
+	result, err := suite.upload(encodePNG(suite.T(), solidImage(300, 200, red, blue)), "image/png", "me.png")
+	require.NoError(suite.T(), err)
+
+	require.Len(suite.T(), result.Variants, 3)
+	for name, size := range map[string]int{"64": 64, "128": 128, "256": 200} {
+		url, ok := result.Variants[name]
+		require.True(suite.T(), ok, name)
+		img, format := suite.stored(url)
+		assert.Equal(suite.T(), "png", format)
+		assert.Equal(suite.T(), image.Rect(0, 0, size, size), img.Bounds(), "variant %s is never upscaled", name)
+	}
+
+	original, _ := suite.stored(result.URL)
+	assert.Equal(suite.T(), image.Rect(0, 0, 300, 200), original.Bounds(), "the original is stored as uploaded")
+}
+
+func (suite *AvatarVariantsTestSuite) TestAppliesEXIFOrientation() {
+	var buf bytes.Buffer
+	img := solidImage(100, 100, color.NRGBA{255, 0, 0, 255}, color.NRGBA{0, 0, 255, 255})
+	require.NoError(suite.T(), jpeg.Encode(&buf, img, &jpeg.Options{Quality: 95}))
+
+	// Orientation 6 is shown turned 90° clockwise: the left half on top
+	result, err := suite.upload(withOrientation(buf.Bytes(), 6), "image/jpeg", "me.jpg")
+	require.NoError(suite.T(), err)
+
+	variant, format := suite.stored(result.Variants["64"])
+	assert.Equal(suite.T(), "jpeg", format)
+	top, bottom := color.NRGBAModel.Convert(variant.At(32, 8)).(color.NRGBA), color.NRGBAModel.Convert(variant.At(32, 56)).(color.NRGBA)
+	assert.Greater(suite.T(), top.R, top.B, "top is red")
+	assert.Greater(suite.T(), bottom.B, bottom.R, "bottom is blue")
+}
+
+func (suite *AvatarVariantsTestSuite) TestRejectsAnimatedAvatars() {
+	data := encodePNG(suite.T(), solidImage(64, 64, color.White, color.Black))
+	_, err := suite.upload(withAnimationControl(data), "image/png", "me.png")
+	suite.assertRejected(err)
+}
+
+func (suite *AvatarVariantsTestSuite) TestRejectsUndecodableFormats() {
+	var buf bytes.Buffer
+	require.NoError(suite.T(), gif.Encode(&buf, solidImage(64, 64, color.White, color.Black), nil))
+	_, err := suite.upload(buf.Bytes(), "image/gif", "me.gif")
+	suite.assertRejected(err)
+
+	_, err = suite.upload([]byte("not an image"), "image/png", "me.png")
+	suite.assertRejected(err)
+}
+
+func (suite *AvatarVariantsTestSuite) TestRejectsOversizedAvatars() {
+	_, err := suite.upload(make([]byte, 1<<20+1), "image/png", "me.png")
+	suite.assertRejected(err)
+}
+
+func TestAvatarVariantsTestSuite(t *testing.T) {
+	suite.Run(t, new(AvatarVariantsTestSuite))
+}
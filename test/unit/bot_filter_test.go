@@ -0,0 +1,130 @@
+// test/unit/bot_filter_test.go
+package unit
+
+import (
+	"context"
+	"testing"
+
+	db "github.com/0xsj/mios.io/db/sqlc"
+	"github.com/0xsj/mios.io/log"
+	"github.com/0xsj/mios.io/pkg/useragent"
+	"github.com/0xsj/mios.io/repository"
+	"github.com/0xsj/mios.io/service"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+// recordingAnalyticsRepo keeps what the service asked it to store or filter
+type recordingAnalyticsRepo struct {
+	repository.AnalyticsRepository
+	clicks      []repository.CreateAnalyticsParams
+	views       []repository.CreatePageViewParams
+	totalsCalls []repository.TimeRangeParams
+}
+
+func (r *recordingAnalyticsRepo) CreateAnalyticsEntry(ctx context.Context, params repository.CreateAnalyticsParams) (*db.Analytic, error) {
+	r.clicks = append(r.clicks, params)
+	return &db.Analytic{}, nil
+}
+
+func (r *recordingAnalyticsRepo) CreatePageViewEntry(ctx context.Context, params repository.CreatePageViewParams) (*db.Analytic, error) {
+	r.views = append(r.views, params)
+	return &db.Analytic{}, nil
+}
+
+func (r *recordingAnalyticsRepo) GetPeriodTotals(ctx context.Context, params repository.TimeRangeParams) (*repository.PeriodTotals, error) {
+	r.totalsCalls = append(r.totalsCalls, params)
+	return &repository.PeriodTotals{}, nil
+}
+
+type BotFilterTestSuite struct {
+	suite.Suite
+	user   *db.User
+	itemID uuid.UUID
+	repo   *recordingAnalyticsRepo
+}
+
+func (suite *BotFilterTestSuite) SetupTest() {
+	suite.user = &db.User{UserID: uuid.New(), Username: "tester", AnalyticsEnabled: true}
+	suite.itemID = uuid.New()
+	suite.repo = &recordingAnalyticsRepo{}
+}
+
+func (suite *BotFilterTestSuite) newService(config service.AnalyticsConfig) service.AnalyticsService {
+	content := &pinContentRepo{items: map[uuid.UUID]*db.ContentItem{
+		suite.itemID: {ItemID: suite.itemID, UserID: suite.user.UserID},
+	}}
+	return service.NewAnalyticsService(suite.repo, content, &exportUserRepo{user: suite.user}, nil, nil, nil, nil,
+		service.AnalyticsExportConfig{},
+		config,
+		log.Development().WithLayer("BotFilterTest"))
+}
+
+func (suite *BotFilterTestSuite) click(svc service.AnalyticsService, ua string) {
+	err := svc.RecordClick(context.Background(), service.RecordClickInput{
+		ItemID:    suite.itemID.String(),
+		UserID:    suite.user.UserID.String(),
+		UserAgent: ua,
+	})
+	require.NoError(suite.T(), err)
+}
+
+func (suite *BotFilterTestSuite) TestFlagsBotsWithDefaultPatterns() {
+	svc := suite.newService(service.AnalyticsConfig{})
+
+	suite.click(svc, uaGooglebot)
+	suite.click(svc, uaChromeWindows)
+	suite.click(svc, "")
+
+	require.Len(suite.T(), suite.repo.clicks, 3, "bot traffic is flagged, not dropped")
+	assert.True(suite.T(), suite.repo.clicks[0].IsBot)
+	assert.False(suite.T(), suite.repo.clicks[1].IsBot)
+	assert.False(suite.T(), suite.repo.clicks[2].IsBot)
+
+	err := svc.RecordPageView(context.Background(), service.RecordPageViewInput{
+		ProfileID: suite.itemID.String(),
+		UserID:    suite.user.UserID.String(),
+		UserAgent: "Mozilla/5.0 (compatible; bingbot/2.0)",
+	})
+	require.NoError(suite.T(), err)
+	require.Len(suite.T(), suite.repo.views, 1)
+	assert.True(suite.T(), suite.repo.views[0].IsBot)
+}
+
+func (suite *BotFilterTestSuite) TestConfiguredPatternsReplaceDefaults() {
+	svc := suite.newService(service.AnalyticsConfig{BotPatterns: []string{"Python-Requests"}})
+
+	suite.click(svc, "python-requests/2.31.0")
+	suite.click(svc, uaGooglebot)
+
+	require.Len(suite.T(), suite.repo.clicks, 2)
+	assert.True(suite.T(), suite.repo.clicks[0].IsBot)
+	assert.False(suite.T(), suite.repo.clicks[1].IsBot)
+}
+
+func (suite *BotFilterTestSuite) TestSummaryExcludesBotsUnlessAsked() {
+	svc := suite.newService(service.AnalyticsConfig{})
+
+	_, err := svc.GetSummaryCards(context.Background(), suite.user.UserID.String(), 7, false)
+	require.NoError(suite.T(), err)
+	_, err = svc.GetSummaryCards(context.Background(), suite.user.UserID.String(), 7, true)
+	require.NoError(suite.T(), err)
+
+	require.Len(suite.T(), suite.repo.totalsCalls, 4)
+	assert.False(suite.T(), suite.repo.totalsCalls[0].IncludeBots)
+	assert.False(suite.T(), suite.repo.totalsCalls[1].IncludeBots)
+	assert.True(suite.T(), suite.repo.totalsCalls[2].IncludeBots)
+	assert.True(suite.T(), suite.repo.totalsCalls[3].IncludeBots)
+}
+
+func (suite *BotFilterTestSuite) TestIsBotIgnoresCaseAndBlankPatterns() {
+	assert.True(suite.T(), useragent.IsBot("Mozilla/5.0 (compatible; AhrefsBot/7.0)", []string{" ", "BOT"}))
+	assert.False(suite.T(), useragent.IsBot(uaSafariIPhone, useragent.DefaultBotPatterns))
+	assert.False(suite.T(), useragent.IsBot("", []string{""}))
+}
+
+func TestBotFilterTestSuite(t *testing.T) {
+	suite.Run(t, new(BotFilterTestSuite))
+}
@@ -0,0 +1,111 @@
+// test/unit/bulk_ownership_test.go
+package unit
+
+import (
+	"context"
+	stderrors "errors"
+	"testing"
+
+	db "github.com/0xsj/mios.io/db/sqlc"
+	"github.com/0xsj/mios.io/log"
+	"github.com/0xsj/mios.io/pkg/errors"
+	"github.com/0xsj/mios.io/repository"
+	"github.com/0xsj/mios.io/service"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+// ownershipContentRepo answers ownership lookups from a fixed table and
+// counts how often it is asked
+type ownershipContentRepo struct {
+	repository.ContentRepository
+	owners         map[uuid.UUID]uuid.UUID
+	ownershipCalls int
+	styled         *repository.BulkStyleParams
+}
+
+func (r *ownershipContentRepo) GetItemsOwnership(ctx context.Context, itemIDs []uuid.UUID) (map[uuid.UUID]uuid.UUID, error) {
+	r.ownershipCalls++
+	owners := make(map[uuid.UUID]uuid.UUID)
+	for _, id := range itemIDs {
+		if owner, ok := r.owners[id]; ok {
+			owners[id] = owner
+		}
+	}
+	return owners, nil
+}
+
+func (r *ownershipContentRepo) BulkUpdateStyle(ctx context.Context, params repository.BulkStyleParams) (int64, error) {
+	r.styled = &params
+	return int64(len(params.ItemIDs)), nil
+}
+
+type BulkOwnershipTestSuite struct {
+	suite.Suite
+	userID uuid.UUID
+	repo   *ownershipContentRepo
+	svc    service.ContentService
+	owned  []uuid.UUID
+}
+
+func (suite *BulkOwnershipTestSuite) SetupTest() {
+	suite.userID = uuid.New()
+	suite.repo = &ownershipContentRepo{owners: make(map[uuid.UUID]uuid.UUID)}
+
+	suite.owned = nil
+	for i := 0; i < 3; i++ {
+		id := uuid.New()
+		suite.repo.owners[id] = suite.userID
+		suite.owned = append(suite.owned, id)
+	}
+
+	user := &db.User{UserID: suite.userID, Username: "tester"}
+	suite.svc = service.NewContentService(suite.repo, &exportUserRepo{user: user}, nil, nil, &discardHistoryRepo{}, nil, nil,
+		service.ContentConfig{},
+		log.Development().WithLayer("BulkOwnershipTest"))
+}
+
+func (suite *BulkOwnershipTestSuite) bulkStyle(itemIDs []uuid.UUID) error {
+	ids := make([]string, len(itemIDs))
+	for i, id := range itemIDs {
+		ids[i] = id.String()
+	}
+	style := "card"
+	return suite.svc.BulkUpdateStyle(context.Background(), suite.userID.String(), ids,
+		service.StylePatch{DesktopStyle: &style})
+}
+
+func (suite *BulkOwnershipTestSuite) TestOwnedItemsAreVerifiedInOneLookup() {
+	err := suite.bulkStyle(suite.owned)
+	require.NoError(suite.T(), err)
+
+	assert.Equal(suite.T(), 1, suite.repo.ownershipCalls)
+	require.NotNil(suite.T(), suite.repo.styled)
+	assert.Len(suite.T(), suite.repo.styled.ItemIDs, 3)
+}
+
+func (suite *BulkOwnershipTestSuite) TestReportsEachItemThatFailed() {
+	foreign := uuid.New()
+	suite.repo.owners[foreign] = uuid.New()
+	missing := uuid.New()
+
+	err := suite.bulkStyle([]uuid.UUID{suite.owned[0], foreign, suite.owned[1], missing})
+	require.Error(suite.T(), err)
+
+	var appErr *errors.AppError
+	require.True(suite.T(), stderrors.As(err, &appErr))
+	assert.Equal(suite.T(), "FORBIDDEN", appErr.Code)
+
+	details, ok := appErr.Details.(service.OwnershipFailureDTO)
+	require.True(suite.T(), ok)
+	assert.Equal(suite.T(), []string{foreign.String(), missing.String()}, details.ItemIDs)
+
+	assert.Equal(suite.T(), 1, suite.repo.ownershipCalls)
+	assert.Nil(suite.T(), suite.repo.styled, "nothing may be mutated when ownership fails")
+}
+
+func TestBulkOwnershipTestSuite(t *testing.T) {
+	suite.Run(t, new(BulkOwnershipTestSuite))
+}
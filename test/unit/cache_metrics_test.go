@@ -0,0 +1,98 @@
+// test/unit/cache_metrics_test.go
+package unit
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/0xsj/mios.io/config"
+	"github.com/0xsj/mios.io/log"
+	"github.com/0xsj/mios.io/pkg/cache"
+	"github.com/0xsj/mios.io/pkg/redis"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type CacheMetricsTestSuite struct {
+	suite.Suite
+	cache cache.CacheService
+}
+
+func (suite *CacheMetricsTestSuite) SetupTest() {
+	server := miniredis.RunT(suite.T())
+	host, port, err := net.SplitHostPort(server.Addr())
+	require.NoError(suite.T(), err)
+
+	logger := log.Development().WithLayer("CacheMetricsTest")
+	client, err := redis.NewClient(config.Config{RedisHost: host, RedisPort: port}, logger)
+	require.NoError(suite.T(), err)
+	suite.T().Cleanup(func() { client.Close() })
+
+	suite.cache = cache.NewRedisCache(client, logger, "test", nil, sharedMetrics())
+}
+
+func (suite *CacheMetricsTestSuite) operations(operation, result string) float64 {
+	return testutil.ToFloat64(sharedMetrics().CacheOperationsTotal.WithLabelValues(operation, result))
+}
+
+func (suite *CacheMetricsTestSuite) hitRatio(cacheType string) float64 {
+	return testutil.ToFloat64(sharedMetrics().CacheHitRatio.WithLabelValues(cacheType))
+}
+
+func (suite *CacheMetricsTestSuite) TestGetRecordsHitsAndMisses() {
+	ctx := context.Background()
+	misses, hits, sets := suite.operations("get", "miss"), suite.operations("get", "hit"), suite.operations("set", "success")
+
+	var value string
+	found, err := suite.cache.Get(ctx, "gettest:user:1", &value)
+	require.NoError(suite.T(), err)
+	require.False(suite.T(), found)
+	assert.Equal(suite.T(), 0.0, suite.hitRatio("gettest"))
+
+	require.NoError(suite.T(), suite.cache.Set(ctx, "gettest:user:1", "cached", time.Minute))
+	for i := 0; i < 3; i++ {
+		_, err := suite.cache.Get(ctx, "gettest:user:1", &value)
+		require.NoError(suite.T(), err)
+	}
+
+	assert.Equal(suite.T(), misses+1, suite.operations("get", "miss"))
+	assert.Equal(suite.T(), hits+3, suite.operations("get", "hit"))
+	assert.Equal(suite.T(), sets+1, suite.operations("set", "success"))
+	assert.Equal(suite.T(), 0.75, suite.hitRatio("gettest"), "3 of the last 4 lookups hit")
+}
+
+func (suite *CacheMetricsTestSuite) TestGetOrSetDistinguishesCachedFromFetched() {
+	ctx := context.Background()
+	fetched, served := suite.operations("get_or_set", "miss"), suite.operations("get_or_set", "hit")
+	gets := suite.operations("get", "miss") + suite.operations("get", "hit")
+	fetch := func() (interface{}, error) { return "fresh", nil }
+
+	var value string
+	require.NoError(suite.T(), suite.cache.GetOrSet(ctx, "getorsettest:user:1", &value, time.Minute, fetch))
+	require.NoError(suite.T(), suite.cache.GetOrSet(ctx, "getorsettest:user:1", &value, time.Minute, fetch))
+
+	assert.Equal(suite.T(), fetched+1, suite.operations("get_or_set", "miss"))
+	assert.Equal(suite.T(), served+1, suite.operations("get_or_set", "hit"))
+	assert.Equal(suite.T(), gets, suite.operations("get", "miss")+suite.operations("get", "hit"), "lookups are counted once")
+	assert.Equal(suite.T(), 0.5, suite.hitRatio("getorsettest"))
+}
+
+func (suite *CacheMetricsTestSuite) TestDeletesAreRecorded() {
+	ctx := context.Background()
+	deletes, patterns := suite.operations("delete", "success"), suite.operations("delete_pattern", "success")
+
+	require.NoError(suite.T(), suite.cache.Delete(ctx, "deletetest:user:1"))
+	require.NoError(suite.T(), suite.cache.DeletePattern(ctx, "deletetest:*"))
+
+	assert.Equal(suite.T(), deletes+1, suite.operations("delete", "success"))
+	assert.Equal(suite.T(), patterns+1, suite.operations("delete_pattern", "success"))
+}
+
+func TestCacheMetricsTestSuite(t *testing.T) {
+	suite.Run(t, new(CacheMetricsTestSuite))
+}
@@ -0,0 +1,110 @@
+// test/unit/cache_stampede_test.go
+package unit
+
+import (
+	"context"
+	stderrors "errors"
+	"net"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/0xsj/mios.io/config"
+	"github.com/0xsj/mios.io/log"
+	"github.com/0xsj/mios.io/pkg/cache"
+	"github.com/0xsj/mios.io/pkg/redis"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type dashboard struct {
+	Clicks int      `json:"clicks"`
+	Top    []string `json:"top"`
+}
+
+type CacheStampedeTestSuite struct {
+	suite.Suite
+	cache cache.CacheService
+}
+
+func (suite *CacheStampedeTestSuite) SetupTest() {
+	server := miniredis.RunT(suite.T())
+	host, port, err := net.SplitHostPort(server.Addr())
+	require.NoError(suite.T(), err)
+
+	logger := log.Development().WithLayer("CacheStampedeTest")
+	client, err := redis.NewClient(config.Config{RedisHost: host, RedisPort: port}, logger)
+	require.NoError(suite.T(), err)
+	suite.T().Cleanup(func() { client.Close() })
+
+	suite.cache = cache.NewRedisCache(client, logger, "test", nil, nil)
+}
+
+// stampede makes concurrent GetOrSet calls on one key and returns what each
+// caller got
+func (suite *CacheStampedeTestSuite) stampede(callers int, fetchFn func() (interface{}, error)) ([]*dashboard, []error) {
+	results := make([]*dashboard, callers)
+	errs := make([]error, callers)
+
+	var wg sync.WaitGroup
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i] = &dashboard{}
+			errs[i] = suite.cache.GetOrSet(context.Background(), "dashboard", results[i], time.Minute, fetchFn)
+		}(i)
+	}
+	wg.Wait()
+	return results, errs
+}
+
+// slowFetch counts its calls and takes long enough for every caller to miss
+func slowFetch(calls *atomic.Int32, value interface{}, err error) func() (interface{}, error) {
+	return func() (interface{}, error) {
+		calls.Add(1)
+		time.Sleep(100 * time.Millisecond)
+		return value, err
+	}
+}
+
+func (suite *CacheStampedeTestSuite) TestConcurrentMissesShareOneFetch() {
+	var calls atomic.Int32
+	results, errs := suite.stampede(20, slowFetch(&calls, dashboard{Clicks: 7, Top: []string{"a", "b"}}, nil))
+
+	assert.Equal(suite.T(), int32(1), calls.Load())
+	for i := range results {
+		require.NoError(suite.T(), errs[i])
+		assert.Equal(suite.T(), &dashboard{Clicks: 7, Top: []string{"a", "b"}}, results[i])
+	}
+
+	results[0].Top[0] = "changed"
+	assert.Equal(suite.T(), "a", results[1].Top[0], "callers don't share memory")
+
+	var cached dashboard
+	found, err := suite.cache.Get(context.Background(), "dashboard", &cached)
+	require.NoError(suite.T(), err)
+	assert.True(suite.T(), found, "the shared result is cached")
+}
+
+func (suite *CacheStampedeTestSuite) TestErrorsReachEveryWaiter() {
+	var calls atomic.Int32
+	fetchErr := stderrors.New("database unavailable")
+	_, errs := suite.stampede(10, slowFetch(&calls, nil, fetchErr))
+
+	assert.Equal(suite.T(), int32(1), calls.Load())
+	for _, err := range errs {
+		assert.ErrorIs(suite.T(), err, fetchErr)
+	}
+
+	_, errs = suite.stampede(1, slowFetch(&calls, dashboard{Clicks: 1}, nil))
+	require.NoError(suite.T(), errs[0])
+	assert.Equal(suite.T(), int32(2), calls.Load(), "failures aren't cached")
+}
+
+func TestCacheStampedeTestSuite(t *testing.T) {
+	suite.Run(t, new(CacheStampedeTestSuite))
+}
@@ -0,0 +1,130 @@
+// test/unit/campaign_analytics_test.go
+package unit
+
+import (
+	"context"
+	"testing"
+
+	db "github.com/0xsj/mios.io/db/sqlc"
+	"github.com/0xsj/mios.io/log"
+	"github.com/0xsj/mios.io/repository"
+	"github.com/0xsj/mios.io/service"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type campaignAnalyticsRepo struct {
+	recordingAnalyticsRepo
+	counts []repository.CampaignCount
+}
+
+func (r *campaignAnalyticsRepo) GetCampaignCounts(ctx context.Context, params repository.TimeRangeParams) ([]repository.CampaignCount, error) {
+	return r.counts, nil
+}
+
+type CampaignAnalyticsTestSuite struct {
+	suite.Suite
+	user   *db.User
+	itemID uuid.UUID
+	repo   *campaignAnalyticsRepo
+	svc    service.AnalyticsService
+}
+
+func (suite *CampaignAnalyticsTestSuite) SetupTest() {
+	suite.user = &db.User{UserID: uuid.New(), Username: "tester", AnalyticsEnabled: true}
+	suite.itemID = uuid.New()
+	suite.repo = &campaignAnalyticsRepo{}
+
+	content := &pinContentRepo{items: map[uuid.UUID]*db.ContentItem{
+		suite.itemID: {ItemID: suite.itemID, UserID: suite.user.UserID},
+	}}
+	suite.svc = service.NewAnalyticsService(suite.repo, content, &exportUserRepo{user: suite.user}, nil, nil, nil, nil,
+		service.AnalyticsExportConfig{},
+		service.AnalyticsConfig{},
+		log.Development().WithLayer("CampaignAnalyticsTest"))
+}
+
+func (suite *CampaignAnalyticsTestSuite) TestReadsTagsFromReferrer() {
+	err := suite.svc.RecordClick(context.Background(), service.RecordClickInput{
+		ItemID:   suite.itemID.String(),
+		UserID:   suite.user.UserID.String(),
+		Referrer: "https://example.com/post?utm_source=Newsletter&utm_medium=email&utm_campaign=spring_sale",
+	})
+	require.NoError(suite.T(), err)
+
+	require.Len(suite.T(), suite.repo.clicks, 1)
+	assert.Equal(suite.T(), repository.UTMParams{
+		Source:   "newsletter",
+		Medium:   "email",
+		Campaign: "spring_sale",
+	}, suite.repo.clicks[0].UTM)
+}
+
+func (suite *CampaignAnalyticsTestSuite) TestExplicitTagsWinOverReferrer() {
+	err := suite.svc.RecordPageView(context.Background(), service.RecordPageViewInput{
+		ProfileID: suite.itemID.String(),
+		UserID:    suite.user.UserID.String(),
+		Referrer:  "https://example.com/?utm_source=twitter",
+		UTMSource: "instagram",
+	})
+	require.NoError(suite.T(), err)
+
+	require.Len(suite.T(), suite.repo.views, 1)
+	assert.Equal(suite.T(), repository.UTMParams{Source: "instagram"}, suite.repo.views[0].UTM)
+}
+
+func (suite *CampaignAnalyticsTestSuite) TestUntaggedReferrerStoresNoTags() {
+	err := suite.svc.RecordClick(context.Background(), service.RecordClickInput{
+		ItemID:   suite.itemID.String(),
+		UserID:   suite.user.UserID.String(),
+		Referrer: "https://google.com/search?q=mios",
+	})
+	require.NoError(suite.T(), err)
+
+	require.Len(suite.T(), suite.repo.clicks, 1)
+	assert.Equal(suite.T(), repository.UTMParams{}, suite.repo.clicks[0].UTM)
+}
+
+func (suite *CampaignAnalyticsTestSuite) TestGroupsBySourceMediumAndCampaign() {
+	suite.repo.counts = []repository.CampaignCount{
+		{Source: "newsletter", Medium: "email", Campaign: "spring_sale", Clicks: 6, PageViews: 4},
+		{Source: "twitter", Medium: "social", Campaign: "spring_sale", Clicks: 3, PageViews: 2},
+		{Source: "twitter", Clicks: 1},
+		{Clicks: 2, PageViews: 2},
+	}
+
+	result, err := suite.svc.GetCampaignAnalytics(context.Background(), suite.user.UserID.String(), service.TimeRangeInput{
+		StartDate: "2025-01-01T00:00:00Z",
+		EndDate:   "2025-01-31T23:59:59Z",
+	})
+	require.NoError(suite.T(), err)
+
+	assert.Equal(suite.T(), int64(20), result.TotalCount)
+	assert.Equal(suite.T(), map[string]int64{"newsletter": 10, "twitter": 6, service.CampaignDirect: 4}, entryCounts(result.Sources))
+	assert.Equal(suite.T(), map[string]int64{"email": 10, "social": 5, service.CampaignNotSet: 1, service.CampaignDirect: 4}, entryCounts(result.Mediums))
+	assert.Equal(suite.T(), map[string]int64{"spring_sale": 15, service.CampaignNotSet: 1, service.CampaignDirect: 4}, entryCounts(result.Campaigns))
+	assert.Equal(suite.T(), "spring_sale", result.Campaigns[0].Name)
+	assert.InDelta(suite.T(), 75.0, result.Campaigns[0].Percentage, 0.001)
+}
+
+func (suite *CampaignAnalyticsTestSuite) TestRejectsReversedRange() {
+	_, err := suite.svc.GetCampaignAnalytics(context.Background(), suite.user.UserID.String(), service.TimeRangeInput{
+		StartDate: "2025-02-01T00:00:00Z",
+		EndDate:   "2025-01-01T00:00:00Z",
+	})
+	require.Error(suite.T(), err)
+}
+
+func entryCounts(entries []*service.BreakdownEntryDTO) map[string]int64 {
+	counts := make(map[string]int64, len(entries))
+	for _, entry := range entries {
+		counts[entry.Name] = entry.Count
+	}
+	return counts
+}
+
+func TestCampaignAnalyticsTestSuite(t *testing.T) {
+	suite.Run(t, new(CampaignAnalyticsTestSuite))
+}
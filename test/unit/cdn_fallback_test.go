@@ -0,0 +1,129 @@
+// test/unit/cdn_fallback_test.go
+package unit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	db "github.com/0xsj/mios.io/db/sqlc"
+	"github.com/0xsj/mios.io/log"
+	"github.com/0xsj/mios.io/pkg/storage"
+	"github.com/0xsj/mios.io/service"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type CDNFallbackTestSuite struct {
+	suite.Suite
+	cdn        *httptest.Server
+	cdnStatus  atomic.Int32
+	monitor    *storage.CDNMonitor
+	storageDir string
+	logger     log.Logger
+}
+
+func (suite *CDNFallbackTestSuite) SetupTest() {
+	suite.cdnStatus.Store(http.StatusOK)
+	suite.cdn = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(int(suite.cdnStatus.Load()))
+	}))
+
+	suite.logger = log.Development().WithLayer("CDNFallbackTest")
+	suite.monitor = storage.NewCDNMonitor(storage.CDNMonitorConfig{
+		Domain:           suite.cdn.URL,
+		Timeout:          time.Second,
+		FailureThreshold: 2,
+	}, suite.logger)
+
+	dir, err := os.MkdirTemp("", "cdn_fallback_test_*")
+	require.NoError(suite.T(), err)
+	suite.storageDir = dir
+}
+
+func (suite *CDNFallbackTestSuite) TearDownTest() {
+	suite.cdn.Close()
+	os.RemoveAll(suite.storageDir)
+}
+
+func (suite *CDNFallbackTestSuite) newFileService(fallback bool) service.FileService {
+	local := storage.NewLocalStorage(suite.storageDir, "http://origin.test/uploads", suite.logger)
+	files := newMemoryFileRepo(&db.File{FileKey: "content/a.png", UserID: uuid.New(), Category: "content"})
+	return service.NewFileService(local, suite.monitor, files, nil, service.FileServiceConfig{
+		CDNDomain:   suite.cdn.URL,
+		CDNFallback: fallback,
+	}, suite.logger)
+}
+
+func (suite *CDNFallbackTestSuite) TestMonitorNeedsConsecutiveFailures() {
+	ctx := context.Background()
+
+	status := suite.monitor.Check(ctx)
+	assert.True(suite.T(), status.Available)
+
+	suite.cdnStatus.Store(http.StatusNotFound)
+	assert.True(suite.T(), suite.monitor.Check(ctx).Available, "a 404 still means the CDN is serving")
+
+	suite.cdnStatus.Store(http.StatusBadGateway)
+	status = suite.monitor.Check(ctx)
+	assert.True(suite.T(), status.Available, "one failure is below the threshold")
+	assert.Equal(suite.T(), 1, status.ConsecutiveFailures)
+
+	status = suite.monitor.Check(ctx)
+	assert.False(suite.T(), status.Available)
+	assert.NotEmpty(suite.T(), status.LastError)
+
+	suite.cdnStatus.Store(http.StatusOK)
+	status = suite.monitor.Check(ctx)
+	assert.True(suite.T(), status.Available, "one success brings the CDN back")
+	assert.Zero(suite.T(), status.ConsecutiveFailures)
+}
+
+func (suite *CDNFallbackTestSuite) TestUnreachableCDNIsDown() {
+	suite.cdn.Close()
+
+	suite.monitor.Check(context.Background())
+	status := suite.monitor.Check(context.Background())
+	assert.False(suite.T(), status.Available)
+}
+
+func (suite *CDNFallbackTestSuite) TestFileURLFallsBackToOrigin() {
+	ctx := context.Background()
+	svc := suite.newFileService(true)
+
+	url, err := svc.GetFileURL(ctx, "", "content/a.png", 0)
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), suite.cdn.URL+"/content/a.png", url)
+
+	suite.cdnStatus.Store(http.StatusServiceUnavailable)
+	suite.monitor.Check(ctx)
+	suite.monitor.Check(ctx)
+
+	url, err = svc.GetFileURL(ctx, "", "content/a.png", 0)
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "http://origin.test/uploads/content/a.png", url)
+}
+
+func (suite *CDNFallbackTestSuite) TestFallbackDisabledKeepsCDN() {
+	ctx := context.Background()
+	svc := suite.newFileService(false)
+
+	suite.cdnStatus.Store(http.StatusServiceUnavailable)
+	suite.monitor.Check(ctx)
+	suite.monitor.Check(ctx)
+	require.False(suite.T(), suite.monitor.Available())
+
+	url, err := svc.GetFileURL(ctx, "", "content/a.png", 0)
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), suite.cdn.URL+"/content/a.png", url)
+}
+
+func TestCDNFallbackSuite(t *testing.T) {
+	suite.Run(t, new(CDNFallbackTestSuite))
+}
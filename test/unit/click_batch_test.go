@@ -0,0 +1,113 @@
+// test/unit/click_batch_test.go
+package unit
+
+import (
+	"context"
+	"testing"
+
+	db "github.com/0xsj/mios.io/db/sqlc"
+	"github.com/0xsj/mios.io/log"
+	"github.com/0xsj/mios.io/repository"
+	"github.com/0xsj/mios.io/service"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type batchAnalyticsRepo struct {
+	recordingAnalyticsRepo
+	batches [][]repository.CreateAnalyticsParams
+}
+
+func (r *batchAnalyticsRepo) CreateAnalyticsEntries(ctx context.Context, entries []repository.CreateAnalyticsParams) (int64, error) {
+	r.batches = append(r.batches, entries)
+	return int64(len(entries)), nil
+}
+
+type ClickBatchTestSuite struct {
+	suite.Suite
+	user   *db.User
+	itemID uuid.UUID
+	repo   *batchAnalyticsRepo
+	svc    service.AnalyticsService
+}
+
+func (suite *ClickBatchTestSuite) SetupTest() {
+	suite.user = &db.User{UserID: uuid.New(), Username: "tester", AnalyticsEnabled: true}
+	suite.itemID = uuid.New()
+	suite.repo = &batchAnalyticsRepo{}
+
+	content := &pinContentRepo{items: map[uuid.UUID]*db.ContentItem{
+		suite.itemID: {ItemID: suite.itemID, UserID: suite.user.UserID},
+	}}
+	suite.svc = service.NewAnalyticsService(suite.repo, content, &exportUserRepo{user: suite.user}, nil, nil, nil, nil,
+		service.AnalyticsExportConfig{},
+		service.AnalyticsConfig{MaxBatchSize: 3},
+		log.Development().WithLayer("ClickBatchTest"))
+}
+
+func (suite *ClickBatchTestSuite) click() service.RecordClickInput {
+	return service.RecordClickInput{
+		ItemID: suite.itemID.String(),
+		UserID: suite.user.UserID.String(),
+	}
+}
+
+func (suite *ClickBatchTestSuite) TestBadEntriesDoNotFailTheBatch() {
+	invalidID := suite.click()
+	invalidID.ItemID = "not-a-uuid"
+	missingItem := suite.click()
+	missingItem.ItemID = uuid.NewString()
+	copied := suite.click()
+	copied.InteractionType = service.InteractionCopy
+
+	result, err := suite.svc.RecordClicksBatch(context.Background(), []service.RecordClickInput{
+		suite.click(), invalidID, copied,
+	})
+	require.NoError(suite.T(), err)
+
+	assert.Equal(suite.T(), 3, result.Total)
+	assert.Equal(suite.T(), 2, result.Succeeded)
+	assert.Equal(suite.T(), 1, result.Failed)
+	assert.True(suite.T(), result.Results[0].Success)
+	assert.False(suite.T(), result.Results[1].Success)
+	assert.Equal(suite.T(), "Invalid item ID format", result.Results[1].Error)
+	assert.True(suite.T(), result.Results[2].Success)
+
+	require.Len(suite.T(), suite.repo.batches, 1)
+	require.Len(suite.T(), suite.repo.batches[0], 2)
+	assert.Equal(suite.T(), service.InteractionClick, suite.repo.batches[0][0].InteractionType)
+	assert.Equal(suite.T(), service.InteractionCopy, suite.repo.batches[0][1].InteractionType)
+
+	result, err = suite.svc.RecordClicksBatch(context.Background(), []service.RecordClickInput{missingItem})
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 1, result.Failed)
+	assert.Equal(suite.T(), "Content item not found", result.Results[0].Error)
+	assert.Len(suite.T(), suite.repo.batches, 1, "nothing to insert when every entry fails")
+}
+
+func (suite *ClickBatchTestSuite) TestDisabledAnalyticsSucceedWithoutStoring() {
+	suite.user.AnalyticsEnabled = false
+
+	result, err := suite.svc.RecordClicksBatch(context.Background(), []service.RecordClickInput{suite.click()})
+	require.NoError(suite.T(), err)
+
+	assert.Equal(suite.T(), 1, result.Succeeded)
+	assert.Empty(suite.T(), suite.repo.batches)
+}
+
+func (suite *ClickBatchTestSuite) TestRejectsEmptyAndOversizedBatches() {
+	_, err := suite.svc.RecordClicksBatch(context.Background(), nil)
+	assert.Error(suite.T(), err)
+
+	_, err = suite.svc.RecordClicksBatch(context.Background(), []service.RecordClickInput{
+		suite.click(), suite.click(), suite.click(), suite.click(),
+	})
+	assert.Error(suite.T(), err)
+	assert.Empty(suite.T(), suite.repo.batches)
+}
+
+func TestClickBatchTestSuite(t *testing.T) {
+	suite.Run(t, new(ClickBatchTestSuite))
+}
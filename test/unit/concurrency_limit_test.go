@@ -0,0 +1,107 @@
+// test/unit/concurrency_limit_test.go
+package unit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/0xsj/mios.io/log"
+	"github.com/0xsj/mios.io/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type ConcurrencyLimitTestSuite struct {
+	suite.Suite
+	limiter *middleware.ConcurrencyLimiter
+	router  *gin.Engine
+	entered chan struct{}
+	release chan struct{}
+}
+
+func (suite *ConcurrencyLimitTestSuite) SetupTest() {
+	suite.entered = make(chan struct{}, 4)
+	suite.release = make(chan struct{})
+	suite.limiter = middleware.NewConcurrencyLimiter(middleware.ConcurrencyLimitConfig{
+		MaxInFlight: 2,
+		RetryAfter:  1500 * time.Millisecond,
+		ExemptPaths: []string{"/health"},
+	}, nil, log.Development().WithLayer("ConcurrencyLimitTest"))
+
+	gin.SetMode(gin.TestMode)
+	suite.router = gin.New()
+	suite.router.Use(suite.limiter.Middleware())
+	suite.router.GET("/slow", func(c *gin.Context) {
+		suite.entered <- struct{}{}
+		<-suite.release
+		c.Status(http.StatusOK)
+	})
+	suite.router.GET("/health", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+}
+
+// saturate starts MaxInFlight slow requests and waits until all hold a slot
+func (suite *ConcurrencyLimitTestSuite) saturate() <-chan int {
+	codes := make(chan int, 2)
+	for i := 0; i < 2; i++ {
+		go func() {
+			codes <- suite.get("/slow").Code
+		}()
+	}
+	for i := 0; i < 2; i++ {
+		<-suite.entered
+	}
+	return codes
+}
+
+func (suite *ConcurrencyLimitTestSuite) get(path string) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	suite.router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+	return recorder
+}
+
+func (suite *ConcurrencyLimitTestSuite) TestShedsWhenSaturated() {
+	codes := suite.saturate()
+	assert.Equal(suite.T(), 2, suite.limiter.InFlight())
+
+	shed := suite.get("/slow")
+	assert.Equal(suite.T(), http.StatusServiceUnavailable, shed.Code)
+	assert.Equal(suite.T(), "2", shed.Header().Get("Retry-After"))
+	assert.Equal(suite.T(), int64(1), suite.limiter.Shed())
+
+	close(suite.release)
+	for i := 0; i < 2; i++ {
+		assert.Equal(suite.T(), http.StatusOK, <-codes)
+	}
+	assert.Equal(suite.T(), 0, suite.limiter.InFlight())
+}
+
+func (suite *ConcurrencyLimitTestSuite) TestExemptPathsBypassTheLimit() {
+	codes := suite.saturate()
+
+	assert.Equal(suite.T(), http.StatusOK, suite.get("/health").Code)
+	assert.Equal(suite.T(), int64(0), suite.limiter.Shed())
+
+	close(suite.release)
+	for i := 0; i < 2; i++ {
+		<-codes
+	}
+}
+
+func (suite *ConcurrencyLimitTestSuite) TestSlotsAreReleased() {
+	close(suite.release)
+	for i := 0; i < 5; i++ {
+		require.Equal(suite.T(), http.StatusOK, suite.get("/slow").Code)
+		<-suite.entered
+	}
+	assert.Equal(suite.T(), int64(0), suite.limiter.Shed())
+}
+
+func TestConcurrencyLimitTestSuite(t *testing.T) {
+	suite.Run(t, new(ConcurrencyLimitTestSuite))
+}
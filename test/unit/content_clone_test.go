@@ -0,0 +1,138 @@
+// test/unit/content_clone_test.go
+package unit
+
+import (
+	"context"
+	stderrors "errors"
+	"testing"
+
+	db "github.com/0xsj/mios.io/db/sqlc"
+	"github.com/0xsj/mios.io/log"
+	"github.com/0xsj/mios.io/pkg/errors"
+	"github.com/0xsj/mios.io/repository"
+	"github.com/0xsj/mios.io/service"
+	"github.com/google/uuid"
+	"github.com/jackc/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+// cloneContentRepo keeps every field of the items it creates
+type cloneContentRepo struct {
+	limitContentRepo
+}
+
+func (r *cloneContentRepo) CreateContentItem(ctx context.Context, params repository.CreateContentItemParams) (*db.ContentItem, error) {
+	isActive := params.IsActive
+	item := &db.ContentItem{
+		ItemID:        uuid.New(),
+		UserID:        params.UserID,
+		ContentID:     params.ContentID,
+		ContentType:   params.ContentType,
+		Title:         params.Title,
+		Href:          params.Href,
+		DesktopX:      params.DesktopX,
+		DesktopY:      params.DesktopY,
+		DesktopStyle:  params.DesktopStyle,
+		MobileX:       params.MobileX,
+		MobileY:       params.MobileY,
+		Halign:        params.HAlign,
+		ContentData:   params.ContentData,
+		Overrides:     params.Overrides,
+		IsActive:      &isActive,
+		Pinned:        params.Pinned,
+		CustomStyling: params.CustomStyling,
+		EmbedData:     params.EmbedData,
+		AutoEmbed:     &params.AutoEmbed,
+	}
+	r.items[item.ItemID] = item
+	return item, nil
+}
+
+type ContentCloneTestSuite struct {
+	suite.Suite
+	ctx    context.Context
+	user   *db.User
+	source *db.ContentItem
+	repo   *cloneContentRepo
+}
+
+func (suite *ContentCloneTestSuite) SetupTest() {
+	suite.ctx = context.Background()
+	suite.user = &db.User{UserID: uuid.New(), Username: "tester"}
+
+	title, href, style, halign := "Shop", "https://example.com/shop", "card", "center"
+	x, y := int32(2), int32(4)
+	active, autoEmbed := true, true
+	suite.source = &db.ContentItem{
+		ItemID:        uuid.New(),
+		UserID:        suite.user.UserID,
+		ContentID:     "shop-link",
+		ContentType:   "link",
+		Title:         &title,
+		Href:          &href,
+		DesktopX:      &x,
+		DesktopY:      &y,
+		DesktopStyle:  &style,
+		MobileX:       &x,
+		Halign:        &halign,
+		ContentData:   pgtype.JSONB{Bytes: []byte(`{"icon":"cart"}`), Status: pgtype.Present},
+		Overrides:     pgtype.JSONB{Status: pgtype.Null},
+		IsActive:      &active,
+		Pinned:        true,
+		CustomStyling: pgtype.JSONB{Bytes: []byte(`{"color":"#ff0000"}`), Status: pgtype.Present},
+		EmbedData:     pgtype.JSONB{Bytes: []byte(`{"provider":"youtube"}`), Status: pgtype.Present},
+		AutoEmbed:     &autoEmbed,
+	}
+	suite.repo = &cloneContentRepo{limitContentRepo{pinContentRepo{items: map[uuid.UUID]*db.ContentItem{
+		suite.source.ItemID: suite.source,
+	}}}}
+}
+
+func (suite *ContentCloneTestSuite) service(config service.ContentConfig) service.ContentService {
+	return service.NewContentService(suite.repo, &exportUserRepo{user: suite.user}, nil, nil, &discardHistoryRepo{}, nil, nil,
+		config, log.Development().WithLayer("ContentCloneTest"))
+}
+
+func (suite *ContentCloneTestSuite) TestCopiesItemWithFreshIdentity() {
+	clone, err := suite.service(service.ContentConfig{}).CloneContentItem(suite.ctx, suite.source.ItemID.String())
+	require.NoError(suite.T(), err)
+
+	assert.NotEqual(suite.T(), suite.source.ItemID.String(), clone.ID)
+	assert.NotEqual(suite.T(), suite.source.ContentID, clone.ContentID)
+	assert.Equal(suite.T(), suite.user.UserID.String(), clone.UserID)
+	assert.Equal(suite.T(), "Shop", clone.Title)
+	assert.Equal(suite.T(), "card", clone.Style.Desktop)
+	assert.Equal(suite.T(), "center", clone.HAlign["default"])
+	assert.Equal(suite.T(), "cart", clone.ContentData["icon"])
+
+	assert.Equal(suite.T(), int32(2), clone.Position.Desktop.X)
+	assert.Equal(suite.T(), int32(5), clone.Position.Desktop.Y, "clone sits a row below its source")
+	assert.Nil(suite.T(), suite.repo.items[uuid.MustParse(clone.ID)].MobileY, "unset positions stay unset")
+	assert.False(suite.T(), clone.Pinned)
+
+	stored := suite.repo.items[uuid.MustParse(clone.ID)]
+	assert.Equal(suite.T(), suite.source.CustomStyling, stored.CustomStyling)
+	assert.Equal(suite.T(), suite.source.EmbedData, stored.EmbedData)
+	require.NotNil(suite.T(), stored.AutoEmbed)
+	assert.True(suite.T(), *stored.AutoEmbed)
+}
+
+func (suite *ContentCloneTestSuite) TestRespectsActiveItemLimit() {
+	_, err := suite.service(service.ContentConfig{MaxActiveItemsFree: 1}).CloneContentItem(suite.ctx, suite.source.ItemID.String())
+
+	var appErr *errors.AppError
+	require.True(suite.T(), stderrors.As(err, &appErr), err)
+	assert.Equal(suite.T(), "VALIDATION_ERROR", appErr.Code)
+	assert.Len(suite.T(), suite.repo.items, 1)
+}
+
+func (suite *ContentCloneTestSuite) TestUnknownItem() {
+	_, err := suite.service(service.ContentConfig{}).CloneContentItem(suite.ctx, uuid.NewString())
+	assert.True(suite.T(), errors.IsNotFound(err))
+}
+
+func TestContentCloneTestSuite(t *testing.T) {
+	suite.Run(t, new(ContentCloneTestSuite))
+}
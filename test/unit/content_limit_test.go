@@ -0,0 +1,179 @@
+// test/unit/content_limit_test.go
+package unit
+
+import (
+	"context"
+	stderrors "errors"
+	"testing"
+
+	db "github.com/0xsj/mios.io/db/sqlc"
+	"github.com/0xsj/mios.io/log"
+	"github.com/0xsj/mios.io/pkg/errors"
+	"github.com/0xsj/mios.io/repository"
+	"github.com/0xsj/mios.io/service"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+// limitContentRepo adds creating, updating and counting to the in-memory
+// items of pinContentRepo
+type limitContentRepo struct {
+	pinContentRepo
+}
+
+func (r *limitContentRepo) CreateContentItem(ctx context.Context, params repository.CreateContentItemParams) (*db.ContentItem, error) {
+	isActive := params.IsActive
+	item := &db.ContentItem{ItemID: uuid.New(), UserID: params.UserID, ContentType: params.ContentType, IsActive: &isActive}
+	r.items[item.ItemID] = item
+	return item, nil
+}
+
+func (r *limitContentRepo) UpdateContentItem(ctx context.Context, params repository.UpdateContentItemParams) error {
+	if params.IsActive != nil {
+		isActive := *params.IsActive
+		r.items[params.ItemID].IsActive = &isActive
+	}
+	return nil
+}
+
+func (r *limitContentRepo) CountActiveUserContentItems(ctx context.Context, userID uuid.UUID) (int64, error) {
+	var count int64
+	for _, item := range r.items {
+		if item.UserID == userID && item.IsActive != nil && *item.IsActive {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *limitContentRepo) CountUserContentItemsByTypeAndState(ctx context.Context, userID uuid.UUID) ([]repository.ContentStateCount, error) {
+	active, err := r.CountActiveUserContentItems(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return []repository.ContentStateCount{{ContentType: "link", State: service.ContentStateActive, Count: active}}, nil
+}
+
+// premiumUserRepo lets the premium status of its one user change
+type premiumUserRepo struct {
+	exportUserRepo
+}
+
+func (r *premiumUserRepo) UpdatePremiumStatus(ctx context.Context, userID uuid.UUID, isPremium bool) error {
+	r.user.IsPremium = &isPremium
+	return nil
+}
+
+type ContentLimitTestSuite struct {
+	suite.Suite
+	ctx     context.Context
+	user    *db.User
+	draft   uuid.UUID
+	repo    *limitContentRepo
+	content service.ContentService
+	users   service.UserService
+	changes []service.PremiumStatusChange
+}
+
+func (suite *ContentLimitTestSuite) SetupTest() {
+	suite.ctx = context.Background()
+	isPremium := false
+	suite.user = &db.User{UserID: uuid.New(), Username: "limited", IsPremium: &isPremium}
+	suite.repo = &limitContentRepo{pinContentRepo{items: make(map[uuid.UUID]*db.ContentItem)}}
+
+	active := true
+	for i := 0; i < 2; i++ {
+		id := uuid.New()
+		suite.repo.items[id] = &db.ContentItem{ItemID: id, UserID: suite.user.UserID, ContentType: "link", IsActive: &active}
+	}
+	suite.draft = uuid.New()
+	suite.repo.items[suite.draft] = &db.ContentItem{ItemID: suite.draft, UserID: suite.user.UserID, ContentType: "link"}
+
+	logger := log.Development().WithLayer("ContentLimitTest")
+	userRepo := &premiumUserRepo{exportUserRepo{user: suite.user}}
+	base := service.NewContentService(suite.repo, userRepo, nil, nil, &discardHistoryRepo{}, nil, nil,
+		service.ContentConfig{MaxActiveItemsFree: 2}, logger)
+	suite.content = service.NewCachedContentService(base, newMemoryCache(), logger)
+	suite.users = service.NewUserService(userRepo, nil, nil, nil, nil, nil, nil, nil, service.UserConfig{}, logger)
+
+	suite.changes = nil
+	suite.users.OnPremiumStatusChange(suite.content.PremiumStatusChanged)
+	suite.users.OnPremiumStatusChange(func(ctx context.Context, change service.PremiumStatusChange) {
+		suite.changes = append(suite.changes, change)
+	})
+}
+
+func (suite *ContentLimitTestSuite) create() error {
+	_, err := suite.content.CreateContentItem(suite.ctx, service.CreateContentItemInput{
+		UserID:      suite.user.UserID.String(),
+		ContentType: "link",
+	})
+	return err
+}
+
+func (suite *ContentLimitTestSuite) publishDraft() error {
+	active := true
+	_, err := suite.content.UpdateContentItem(suite.ctx, suite.draft.String(), service.UpdateContentItemInput{IsActive: &active})
+	return err
+}
+
+func (suite *ContentLimitTestSuite) assertLimited(err error) {
+	var appErr *errors.AppError
+	require.True(suite.T(), stderrors.As(err, &appErr), err)
+	assert.Equal(suite.T(), "VALIDATION_ERROR", appErr.Code)
+}
+
+func (suite *ContentLimitTestSuite) summaryLimit() int {
+	summary, err := suite.content.GetContentSummary(suite.ctx, suite.user.UserID.String())
+	require.NoError(suite.T(), err)
+	return summary.ActiveLimit
+}
+
+func (suite *ContentLimitTestSuite) TestUpgradeLiftsTheLimit() {
+	suite.assertLimited(suite.create())
+	suite.assertLimited(suite.publishDraft())
+	assert.Equal(suite.T(), 2, suite.summaryLimit())
+
+	_, err := suite.users.UpdatePremiumStatus(suite.ctx, suite.user.UserID.String(), true)
+	require.NoError(suite.T(), err)
+
+	require.Len(suite.T(), suite.changes, 1)
+	assert.Equal(suite.T(), service.PremiumStatusChange{UserID: suite.user.UserID.String(), IsPremium: true}, suite.changes[0])
+	assert.Equal(suite.T(), 0, suite.summaryLimit(), "the cached summary is dropped")
+
+	require.NoError(suite.T(), suite.publishDraft())
+	require.NoError(suite.T(), suite.create())
+}
+
+func (suite *ContentLimitTestSuite) TestDowngradeKeepsItemsButBlocksNewOnes() {
+	_, err := suite.users.UpdatePremiumStatus(suite.ctx, suite.user.UserID.String(), true)
+	require.NoError(suite.T(), err)
+	require.NoError(suite.T(), suite.publishDraft())
+	require.NoError(suite.T(), suite.create())
+	assert.Equal(suite.T(), 0, suite.summaryLimit())
+
+	_, err = suite.users.UpdatePremiumStatus(suite.ctx, suite.user.UserID.String(), false)
+	require.NoError(suite.T(), err)
+	require.Len(suite.T(), suite.changes, 2)
+	assert.True(suite.T(), suite.changes[1].WasPremium)
+	assert.False(suite.T(), suite.changes[1].IsPremium)
+
+	count, err := suite.repo.CountActiveUserContentItems(suite.ctx, suite.user.UserID)
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), int64(4), count, "excess items stay active")
+	assert.Equal(suite.T(), 2, suite.summaryLimit())
+
+	suite.assertLimited(suite.create())
+}
+
+func (suite *ContentLimitTestSuite) TestUnchangedStatusEmitsNothing() {
+	_, err := suite.users.UpdatePremiumStatus(suite.ctx, suite.user.UserID.String(), false)
+	require.NoError(suite.T(), err)
+	assert.Empty(suite.T(), suite.changes)
+}
+
+func TestContentLimitTestSuite(t *testing.T) {
+	suite.Run(t, new(ContentLimitTestSuite))
+}
@@ -0,0 +1,176 @@
+// test/unit/content_order_test.go
+package unit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	db "github.com/0xsj/mios.io/db/sqlc"
+	"github.com/0xsj/mios.io/log"
+	"github.com/0xsj/mios.io/repository"
+	"github.com/0xsj/mios.io/service"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+// orderContentRepo returns the user's items in whatever order it holds them,
+// like a query whose ORDER BY has ties
+type orderContentRepo struct {
+	repository.ContentRepository
+	items []*db.ContentItem
+}
+
+func (r *orderContentRepo) GetUserContentItems(ctx context.Context, userID uuid.UUID) ([]*db.ContentItem, error) {
+	items := make([]*db.ContentItem, len(r.items))
+	copy(items, r.items)
+	return items, nil
+}
+
+// GetPublishedUserContentItems applies the publish window like the real
+// query does
+func (r *orderContentRepo) GetPublishedUserContentItems(ctx context.Context, userID uuid.UUID) ([]*db.ContentItem, error) {
+	now := time.Now()
+	var items []*db.ContentItem
+	for _, item := range r.items {
+		if item.PublishAt != nil && item.PublishAt.After(now) {
+			continue
+		}
+		if item.UnpublishAt != nil && !item.UnpublishAt.After(now) {
+			continue
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+func (r *orderContentRepo) ListDeletedUserContentItems(ctx context.Context, userID uuid.UUID) ([]*db.ContentItem, error) {
+	return nil, nil
+}
+
+type orderUserRepo struct {
+	repository.UserRepository
+}
+
+func (r *orderUserRepo) GetUser(ctx context.Context, userID uuid.UUID) (*db.User, error) {
+	return &db.User{UserID: userID, Username: "tester"}, nil
+}
+
+type ContentOrderTestSuite struct {
+	suite.Suite
+	userID uuid.UUID
+	repo   *orderContentRepo
+
+	// oldest, middle and newest are zero-position items created in that
+	// order; twinA and twinB share a creation time, twinA sorting first by ID
+	oldest, middle, newest, twinA, twinB *db.ContentItem
+}
+
+func (suite *ContentOrderTestSuite) SetupTest() {
+	suite.userID = uuid.New()
+
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	item := func(id string, createdAt time.Time) *db.ContentItem {
+		zero := int32(0)
+		return &db.ContentItem{
+			ItemID:      uuid.MustParse(id),
+			UserID:      suite.userID,
+			ContentType: "link",
+			DesktopX:    &zero,
+			DesktopY:    &zero,
+			CreatedAt:   &createdAt,
+		}
+	}
+
+	suite.oldest = item("00000000-0000-0000-0000-000000000005", base)
+	suite.middle = item("00000000-0000-0000-0000-000000000004", base.Add(time.Minute))
+	suite.twinA = item("00000000-0000-0000-0000-000000000001", base.Add(2*time.Minute))
+	suite.twinB = item("00000000-0000-0000-0000-000000000002", base.Add(2*time.Minute))
+	suite.newest = item("00000000-0000-0000-0000-000000000003", base.Add(3*time.Minute))
+
+	suite.repo = &orderContentRepo{}
+}
+
+func (suite *ContentOrderTestSuite) render(order string, items ...*db.ContentItem) []string {
+	suite.repo.items = items
+	svc := service.NewContentService(suite.repo, &orderUserRepo{}, nil, nil, nil, nil, nil,
+		service.ContentConfig{FallbackOrder: order},
+		log.Development().WithLayer("ContentOrderTest"))
+
+	dtos, err := svc.GetUserContentItems(context.Background(), suite.userID.String(), "")
+	require.NoError(suite.T(), err)
+
+	ids := make([]string, len(dtos))
+	for i, dto := range dtos {
+		ids[i] = dto.ID
+	}
+	return ids
+}
+
+func itemIDs(items ...*db.ContentItem) []string {
+	result := make([]string, len(items))
+	for i, item := range items {
+		result[i] = item.ItemID.String()
+	}
+	return result
+}
+
+func (suite *ContentOrderTestSuite) TestZeroPositionItemsRenderNewestFirstByDefault() {
+	expected := itemIDs(suite.newest, suite.twinA, suite.twinB, suite.middle, suite.oldest)
+
+	assert.Equal(suite.T(), expected,
+		suite.render("", suite.oldest, suite.twinB, suite.newest, suite.middle, suite.twinA))
+	assert.Equal(suite.T(), expected,
+		suite.render("", suite.twinA, suite.middle, suite.oldest, suite.newest, suite.twinB))
+}
+
+func (suite *ContentOrderTestSuite) TestZeroPositionItemsRenderInCreationOrder() {
+	expected := itemIDs(suite.oldest, suite.middle, suite.twinA, suite.twinB, suite.newest)
+
+	assert.Equal(suite.T(), expected,
+		suite.render(service.ContentOrderOldestFirst, suite.newest, suite.twinB, suite.oldest, suite.twinA, suite.middle))
+	assert.Equal(suite.T(), expected,
+		suite.render(service.ContentOrderOldestFirst, suite.twinB, suite.twinA, suite.middle, suite.newest, suite.oldest))
+}
+
+func (suite *ContentOrderTestSuite) TestPositionsTakePrecedence() {
+	top := int32(0)
+	lower := int32(100)
+	suite.oldest.DesktopY = &lower
+
+	assert.Equal(suite.T(), itemIDs(suite.newest, suite.middle, suite.oldest),
+		suite.render(service.ContentOrderNewestFirst, suite.oldest, suite.middle, suite.newest))
+
+	suite.newest.DesktopY = &lower
+	suite.middle.DesktopY = &top
+	assert.Equal(suite.T(), itemIDs(suite.middle, suite.newest, suite.oldest),
+		suite.render(service.ContentOrderNewestFirst, suite.oldest, suite.newest, suite.middle))
+}
+
+func (suite *ContentOrderTestSuite) TestPinnedItemsRenderFirstInPinOrder() {
+	top := int32(0)
+	lower := int32(100)
+	suite.oldest.DesktopY = &lower
+	suite.middle.DesktopY = &lower
+	suite.newest.DesktopY = &top
+
+	suite.oldest.Pinned, suite.oldest.PinOrder = true, 2
+	suite.middle.Pinned, suite.middle.PinOrder = true, 1
+
+	// Pinned items jump ahead of better-positioned ones, and pins sharing
+	// an order fall back to the usual ordering
+	assert.Equal(suite.T(), itemIDs(suite.middle, suite.oldest, suite.newest),
+		suite.render(service.ContentOrderNewestFirst, suite.newest, suite.oldest, suite.middle))
+
+	suite.oldest.PinOrder = 1
+	assert.Equal(suite.T(), itemIDs(suite.middle, suite.oldest, suite.newest),
+		suite.render(service.ContentOrderNewestFirst, suite.oldest, suite.newest, suite.middle))
+	assert.Equal(suite.T(), itemIDs(suite.oldest, suite.middle, suite.newest),
+		suite.render(service.ContentOrderOldestFirst, suite.newest, suite.middle, suite.oldest))
+}
+
+func TestContentOrderTestSuite(t *testing.T) {
+	suite.Run(t, new(ContentOrderTestSuite))
+}
@@ -0,0 +1,123 @@
+// test/unit/content_pin_test.go
+package unit
+
+import (
+	"context"
+	stderrors "errors"
+	"testing"
+
+	db "github.com/0xsj/mios.io/db/sqlc"
+	"github.com/0xsj/mios.io/log"
+	"github.com/0xsj/mios.io/pkg/errors"
+	"github.com/0xsj/mios.io/repository"
+	"github.com/0xsj/mios.io/service"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+// pinContentRepo holds one user's items and applies pin updates to them
+type pinContentRepo struct {
+	repository.ContentRepository
+	items map[uuid.UUID]*db.ContentItem
+}
+
+func (r *pinContentRepo) GetContentItem(ctx context.Context, itemID uuid.UUID) (*db.ContentItem, error) {
+	item, ok := r.items[itemID]
+	if !ok {
+		return nil, errors.NewNotFoundError("content item not found", nil)
+	}
+	copied := *item
+	return &copied, nil
+}
+
+func (r *pinContentRepo) CountPinnedContentItems(ctx context.Context, userID, excludeItemID uuid.UUID) (int64, error) {
+	var count int64
+	for id, item := range r.items {
+		if item.UserID == userID && item.Pinned && id != excludeItemID {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *pinContentRepo) UpdateContentItemPin(ctx context.Context, itemID uuid.UUID, pinned bool, pinOrder int32) error {
+	r.items[itemID].Pinned = pinned
+	r.items[itemID].PinOrder = pinOrder
+	return nil
+}
+
+type discardHistoryRepo struct {
+	repository.ContentHistoryRepository
+}
+
+func (r *discardHistoryRepo) Record(ctx context.Context, entry repository.ContentHistoryEntry) error {
+	return nil
+}
+
+type ContentPinTestSuite struct {
+	suite.Suite
+	user  *db.User
+	repo  *pinContentRepo
+	svc   service.ContentService
+	items []uuid.UUID
+}
+
+func (suite *ContentPinTestSuite) SetupTest() {
+	isPremium := false
+	suite.user = &db.User{UserID: uuid.New(), Username: "tester", IsPremium: &isPremium}
+	suite.repo = &pinContentRepo{items: make(map[uuid.UUID]*db.ContentItem)}
+
+	suite.items = nil
+	for i := 0; i < 3; i++ {
+		id := uuid.New()
+		suite.repo.items[id] = &db.ContentItem{ItemID: id, UserID: suite.user.UserID, ContentType: "link"}
+		suite.items = append(suite.items, id)
+	}
+
+	suite.svc = service.NewContentService(suite.repo, &exportUserRepo{user: suite.user}, nil, nil, &discardHistoryRepo{}, nil, nil,
+		service.ContentConfig{MaxPinnedFree: 2, MaxPinnedPremium: 3},
+		log.Development().WithLayer("ContentPinTest"))
+}
+
+func (suite *ContentPinTestSuite) pin(itemID uuid.UUID, order int32) (*service.ContentItemDTO, error) {
+	return suite.svc.SetContentItemPin(context.Background(), suite.user.UserID.String(), itemID.String(),
+		service.PinInput{Pinned: true, PinOrder: &order})
+}
+
+func (suite *ContentPinTestSuite) TestPinLimitDependsOnTier() {
+	for i, id := range suite.items[:2] {
+		item, err := suite.pin(id, int32(i))
+		require.NoError(suite.T(), err)
+		assert.True(suite.T(), item.Pinned)
+		assert.Equal(suite.T(), int32(i), item.PinOrder)
+	}
+
+	_, err := suite.pin(suite.items[2], 5)
+	var appErr *errors.AppError
+	require.True(suite.T(), stderrors.As(err, &appErr))
+	assert.Equal(suite.T(), "VALIDATION_ERROR", appErr.Code)
+
+	// Reordering an already pinned item doesn't count against the limit
+	_, err = suite.pin(suite.items[0], 7)
+	require.NoError(suite.T(), err)
+
+	*suite.user.IsPremium = true
+	_, err = suite.pin(suite.items[2], 5)
+	require.NoError(suite.T(), err)
+}
+
+func (suite *ContentPinTestSuite) TestOnlyOwnerCanPin() {
+	_, err := suite.svc.SetContentItemPin(context.Background(), uuid.New().String(), suite.items[0].String(),
+		service.PinInput{Pinned: true})
+
+	var appErr *errors.AppError
+	require.True(suite.T(), stderrors.As(err, &appErr))
+	assert.Equal(suite.T(), "FORBIDDEN", appErr.Code)
+	assert.False(suite.T(), suite.repo.items[suite.items[0]].Pinned)
+}
+
+func TestContentPinTestSuite(t *testing.T) {
+	suite.Run(t, new(ContentPinTestSuite))
+}
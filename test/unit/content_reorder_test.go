@@ -0,0 +1,131 @@
+// test/unit/content_reorder_test.go
+package unit
+
+import (
+	"context"
+	stderrors "errors"
+	"testing"
+
+	db "github.com/0xsj/mios.io/db/sqlc"
+	"github.com/0xsj/mios.io/log"
+	"github.com/0xsj/mios.io/pkg/errors"
+	"github.com/0xsj/mios.io/repository"
+	"github.com/0xsj/mios.io/service"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+// reorderContentRepo applies a reorder only when every item is still owned,
+// like the single guarded UPDATE it stands in for. deletedAfterCheck is an
+// item that passes the ownership check but is gone by the time of the update.
+type reorderContentRepo struct {
+	ownershipContentRepo
+	deletedAfterCheck uuid.UUID
+	applied           []repository.ItemPosition
+}
+
+func (r *reorderContentRepo) ReorderContentItems(ctx context.Context, userID uuid.UUID, positions []repository.ItemPosition) (int64, error) {
+	for _, position := range positions {
+		if r.owners[position.ItemID] != userID || position.ItemID == r.deletedAfterCheck {
+			return 0, nil
+		}
+	}
+	r.applied = positions
+	return int64(len(positions)), nil
+}
+
+type ContentReorderTestSuite struct {
+	suite.Suite
+	ctx    context.Context
+	userID uuid.UUID
+	owned  []uuid.UUID
+	repo   *reorderContentRepo
+	svc    service.ContentService
+}
+
+func (suite *ContentReorderTestSuite) SetupTest() {
+	suite.ctx = context.Background()
+	suite.userID = uuid.New()
+	suite.repo = &reorderContentRepo{ownershipContentRepo: ownershipContentRepo{owners: make(map[uuid.UUID]uuid.UUID)}}
+
+	suite.owned = nil
+	for i := 0; i < 3; i++ {
+		id := uuid.New()
+		suite.repo.owners[id] = suite.userID
+		suite.owned = append(suite.owned, id)
+	}
+
+	user := &db.User{UserID: suite.userID, Username: "tester"}
+	suite.svc = service.NewContentService(suite.repo, &exportUserRepo{user: user}, nil, nil, &discardHistoryRepo{}, nil, nil,
+		service.ContentConfig{},
+		log.Development().WithLayer("ContentReorderTest"))
+}
+
+func reorderPosition(itemID uuid.UUID, y int32) service.PositionUpdate {
+	x := int32(0)
+	return service.PositionUpdate{ItemID: itemID.String(), DesktopX: &x, DesktopY: &y, MobileX: &x, MobileY: &y}
+}
+
+func (suite *ContentReorderTestSuite) TestAppliesWholeLayout() {
+	err := suite.svc.ReorderContentItems(suite.ctx, suite.userID.String(), []service.PositionUpdate{
+		reorderPosition(suite.owned[2], 0),
+		reorderPosition(suite.owned[0], 1),
+		reorderPosition(suite.owned[1], 2),
+	})
+	require.NoError(suite.T(), err)
+
+	require.Len(suite.T(), suite.repo.applied, 3)
+	assert.Equal(suite.T(), suite.owned[2], suite.repo.applied[0].ItemID)
+	assert.Equal(suite.T(), int32(2), suite.repo.applied[2].MobileY)
+	assert.Equal(suite.T(), 1, suite.repo.ownershipCalls)
+}
+
+func (suite *ContentReorderTestSuite) TestForeignItemBlocksWholeBatch() {
+	foreign := uuid.New()
+	suite.repo.owners[foreign] = uuid.New()
+
+	err := suite.svc.ReorderContentItems(suite.ctx, suite.userID.String(), []service.PositionUpdate{
+		reorderPosition(suite.owned[0], 0),
+		reorderPosition(foreign, 1),
+	})
+	var appErr *errors.AppError
+	require.True(suite.T(), stderrors.As(err, &appErr), err)
+	assert.Equal(suite.T(), "FORBIDDEN", appErr.Code)
+	assert.Nil(suite.T(), suite.repo.applied)
+}
+
+func (suite *ContentReorderTestSuite) TestItemLostAfterCheckIsAConflict() {
+	suite.repo.deletedAfterCheck = suite.owned[1]
+
+	err := suite.svc.ReorderContentItems(suite.ctx, suite.userID.String(), []service.PositionUpdate{
+		reorderPosition(suite.owned[0], 0),
+		reorderPosition(suite.owned[1], 1),
+	})
+	var appErr *errors.AppError
+	require.True(suite.T(), stderrors.As(err, &appErr), err)
+	assert.Equal(suite.T(), "CONFLICT", appErr.Code)
+	assert.Nil(suite.T(), suite.repo.applied)
+}
+
+func (suite *ContentReorderTestSuite) TestRejectsIncompleteRequests() {
+	incomplete := reorderPosition(suite.owned[0], 0)
+	incomplete.MobileY = nil
+
+	for name, items := range map[string][]service.PositionUpdate{
+		"empty":     nil,
+		"duplicate": {reorderPosition(suite.owned[0], 0), reorderPosition(suite.owned[0], 1)},
+		"missing":   {incomplete},
+	} {
+		err := suite.svc.ReorderContentItems(suite.ctx, suite.userID.String(), items)
+		var appErr *errors.AppError
+		require.True(suite.T(), stderrors.As(err, &appErr), name)
+		assert.Equal(suite.T(), "VALIDATION_ERROR", appErr.Code, name)
+	}
+	assert.Zero(suite.T(), suite.repo.ownershipCalls)
+}
+
+func TestContentReorderTestSuite(t *testing.T) {
+	suite.Run(t, new(ContentReorderTestSuite))
+}
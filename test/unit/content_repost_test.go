@@ -0,0 +1,214 @@
+// test/unit/content_repost_test.go
+package unit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	db "github.com/0xsj/mios.io/db/sqlc"
+	"github.com/0xsj/mios.io/log"
+	"github.com/0xsj/mios.io/repository"
+	"github.com/0xsj/mios.io/service"
+	"github.com/google/uuid"
+	"github.com/jackc/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+// repostContentRepo holds items across users and counts the batch lookups
+// used to resolve reposts
+type repostContentRepo struct {
+	repository.ContentRepository
+	items       map[uuid.UUID]*db.ContentItem
+	batchLoads  int
+	singleLoads int
+}
+
+func (r *repostContentRepo) GetContentItem(ctx context.Context, itemID uuid.UUID) (*db.ContentItem, error) {
+	r.singleLoads++
+	return nil, nil
+}
+
+func (r *repostContentRepo) GetContentItemsByIDs(ctx context.Context, itemIDs []uuid.UUID) ([]*db.ContentItem, error) {
+	r.batchLoads++
+	var items []*db.ContentItem
+	for _, id := range itemIDs {
+		if item, ok := r.items[id]; ok && item.DeletedAt == nil {
+			items = append(items, item)
+		}
+	}
+	return items, nil
+}
+
+func (r *repostContentRepo) GetUserContentItems(ctx context.Context, userID uuid.UUID) ([]*db.ContentItem, error) {
+	var items []*db.ContentItem
+	for _, item := range r.items {
+		if item.UserID == userID && item.DeletedAt == nil {
+			items = append(items, item)
+		}
+	}
+	return items, nil
+}
+
+func (r *repostContentRepo) GetPublishedUserContentItems(ctx context.Context, userID uuid.UUID) ([]*db.ContentItem, error) {
+	return r.GetUserContentItems(ctx, userID)
+}
+
+type repostUserRepo struct {
+	repository.UserRepository
+	users      map[uuid.UUID]*db.User
+	batchLoads int
+}
+
+func (r *repostUserRepo) GetUser(ctx context.Context, userID uuid.UUID) (*db.User, error) {
+	return r.users[userID], nil
+}
+
+func (r *repostUserRepo) GetUsersByIDs(ctx context.Context, userIDs []uuid.UUID) ([]*db.User, error) {
+	r.batchLoads++
+	var users []*db.User
+	for _, id := range userIDs {
+		if user, ok := r.users[id]; ok && user.DeletedAt == nil {
+			users = append(users, user)
+		}
+	}
+	return users, nil
+}
+
+type ContentRepostTestSuite struct {
+	suite.Suite
+	ctx       context.Context
+	reposter  *db.User
+	creator   *db.User
+	contents  *repostContentRepo
+	users     *repostUserRepo
+	svc       service.ContentService
+	createdAt time.Time
+}
+
+func (suite *ContentRepostTestSuite) SetupTest() {
+	suite.ctx = context.Background()
+	suite.reposter = &db.User{UserID: uuid.New(), Handle: "reposter", Visibility: service.VisibilityPublic}
+	suite.creator = &db.User{UserID: uuid.New(), Handle: "creator", Visibility: service.VisibilityPublic}
+	suite.contents = &repostContentRepo{items: map[uuid.UUID]*db.ContentItem{}}
+	suite.users = &repostUserRepo{users: map[uuid.UUID]*db.User{
+		suite.reposter.UserID: suite.reposter,
+		suite.creator.UserID:  suite.creator,
+	}}
+	suite.svc = service.NewContentService(suite.contents, suite.users, nil, nil, nil, nil, nil,
+		service.ContentConfig{}, log.Development().WithLayer("ContentRepostTest"))
+	suite.createdAt = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+}
+
+func (suite *ContentRepostTestSuite) add(owner *db.User, contentType, title string) *db.ContentItem {
+	active := true
+	suite.createdAt = suite.createdAt.Add(time.Minute)
+	createdAt := suite.createdAt
+	item := &db.ContentItem{
+		ItemID:      uuid.New(),
+		UserID:      owner.UserID,
+		ContentType: contentType,
+		Title:       &title,
+		IsActive:    &active,
+		ContentData: pgtype.JSONB{Status: pgtype.Null},
+		CreatedAt:   &createdAt,
+	}
+	suite.contents.items[item.ItemID] = item
+	return item
+}
+
+func (suite *ContentRepostTestSuite) repost(owner *db.User, source *db.ContentItem) *db.ContentItem {
+	item := suite.add(owner, service.ContentTypeRepost, "")
+	item.ContentData = pgtype.JSONB{
+		Bytes:  []byte(`{"source_item_id":"` + source.ItemID.String() + `"}`),
+		Status: pgtype.Present,
+	}
+	return item
+}
+
+// profile renders the reposter's profile for viewerID and maps each repost
+// shown to the title of the original it resolved to
+func (suite *ContentRepostTestSuite) profile(viewerID string) map[string]string {
+	dtos, err := suite.svc.GetUserContentItems(suite.ctx, suite.reposter.UserID.String(), viewerID)
+	require.NoError(suite.T(), err)
+
+	shown := map[string]string{}
+	for _, dto := range dtos {
+		if dto.ContentType == service.ContentTypeRepost {
+			require.NotNil(suite.T(), dto.Repost)
+			shown[dto.ID] = dto.Repost.Title
+		}
+	}
+	return shown
+}
+
+func (suite *ContentRepostTestSuite) TestResolvesChainsWithOneQueryPerLevel() {
+	direct := suite.add(suite.creator, "link", "direct")
+	nested := suite.add(suite.creator, "link", "nested")
+	middle := suite.repost(suite.creator, nested)
+
+	first := suite.repost(suite.reposter, direct)
+	second := suite.repost(suite.reposter, middle)
+	third := suite.repost(suite.reposter, direct)
+
+	shown := suite.profile("")
+
+	assert.Equal(suite.T(), map[string]string{
+		first.ItemID.String():  "direct",
+		second.ItemID.String(): "nested",
+		third.ItemID.String():  "direct",
+	}, shown)
+	assert.Equal(suite.T(), 2, suite.contents.batchLoads)
+	assert.Equal(suite.T(), 0, suite.contents.singleLoads)
+	assert.Equal(suite.T(), 1, suite.users.batchLoads)
+
+	dtos, err := suite.svc.GetUserContentItems(suite.ctx, suite.reposter.UserID.String(), "")
+	require.NoError(suite.T(), err)
+	for _, dto := range dtos {
+		assert.Equal(suite.T(), "creator", dto.Repost.Handle)
+	}
+}
+
+func (suite *ContentRepostTestSuite) TestHidesRepostsOfUnavailableSources() {
+	visible := suite.repost(suite.reposter, suite.add(suite.creator, "link", "visible"))
+
+	deleted := suite.add(suite.creator, "link", "deleted")
+	deletedAt := time.Now()
+	deleted.DeletedAt = &deletedAt
+	suite.repost(suite.reposter, deleted)
+
+	inactive := suite.add(suite.creator, "link", "inactive")
+	off := false
+	inactive.IsActive = &off
+	suite.repost(suite.reposter, inactive)
+
+	scheduled := suite.add(suite.creator, "link", "scheduled")
+	later := time.Now().Add(time.Hour)
+	scheduled.PublishAt = &later
+	suite.repost(suite.reposter, scheduled)
+
+	// A deleted item in the middle of a chain hides it too
+	suite.repost(suite.reposter, suite.repost(suite.creator, deleted))
+
+	gone := &db.User{UserID: uuid.New(), Handle: "gone", DeletedAt: &deletedAt}
+	suite.users.users[gone.UserID] = gone
+	suite.repost(suite.reposter, suite.add(gone, "link", "owner deleted"))
+
+	assert.Equal(suite.T(), map[string]string{visible.ItemID.String(): "visible"}, suite.profile(""))
+}
+
+func (suite *ContentRepostTestSuite) TestPrivateSourcesOnlyShowToTheirOwner() {
+	suite.creator.Visibility = service.VisibilityPrivate
+	private := suite.repost(suite.reposter, suite.add(suite.creator, "link", "private"))
+
+	assert.Empty(suite.T(), suite.profile(""))
+	assert.Empty(suite.T(), suite.profile(suite.reposter.UserID.String()))
+	assert.Equal(suite.T(), map[string]string{private.ItemID.String(): "private"},
+		suite.profile(suite.creator.UserID.String()))
+}
+
+func TestContentRepostTestSuite(t *testing.T) {
+	suite.Run(t, new(ContentRepostTestSuite))
+}
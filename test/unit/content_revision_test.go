@@ -0,0 +1,282 @@
+// test/unit/content_revision_test.go
+package unit
+
+import (
+	"context"
+	stderrors "errors"
+	"testing"
+	"time"
+
+	db "github.com/0xsj/mios.io/db/sqlc"
+	"github.com/0xsj/mios.io/log"
+	"github.com/0xsj/mios.io/pkg/errors"
+	"github.com/0xsj/mios.io/repository"
+	"github.com/0xsj/mios.io/service"
+	"github.com/google/uuid"
+	"github.com/jackc/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+// revisionContentRepo applies title updates to the in-memory items
+type revisionContentRepo struct {
+	pinContentRepo
+}
+
+func (r *revisionContentRepo) UpdateContentItem(ctx context.Context, params repository.UpdateContentItemParams) error {
+	if params.Title != nil {
+		title := *params.Title
+		r.items[params.ItemID].Title = &title
+	}
+	return nil
+}
+
+// memoryRevisionRepo keeps revisions and collaborators in memory. A review
+// only claims a revision that is still pending, like the query does.
+type memoryRevisionRepo struct {
+	repository.ContentRevisionRepository
+	revisions     map[uuid.UUID]*db.ContentRevision
+	collaborators map[uuid.UUID]uuid.UUID
+
+	// beforeReview runs inside ReviewRevision, standing in for a reviewer
+	// that gets there first
+	beforeReview func(revision *db.ContentRevision)
+}
+
+func (r *memoryRevisionRepo) CreateRevision(ctx context.Context, params repository.CreateRevisionParams) (*db.ContentRevision, error) {
+	revision := &db.ContentRevision{
+		RevisionID: uuid.New(),
+		ItemID:     params.ItemID,
+		OwnerID:    params.OwnerID,
+		AuthorID:   params.AuthorID,
+		Changes:    params.Changes,
+		Status:     service.RevisionPending,
+	}
+	r.revisions[revision.RevisionID] = revision
+	return revision, nil
+}
+
+func (r *memoryRevisionRepo) GetRevision(ctx context.Context, revisionID uuid.UUID) (*db.ContentRevision, error) {
+	revision, ok := r.revisions[revisionID]
+	if !ok {
+		return nil, errors.NewNotFoundError("Revision not found", nil)
+	}
+	copied := *revision
+	return &copied, nil
+}
+
+func (r *memoryRevisionRepo) ReviewRevision(ctx context.Context, revisionID uuid.UUID, status string, reviewerID uuid.UUID) (*db.ContentRevision, error) {
+	revision, ok := r.revisions[revisionID]
+	if ok && r.beforeReview != nil {
+		r.beforeReview(revision)
+	}
+	if !ok || revision.Status != service.RevisionPending {
+		return nil, errors.NewNotFoundError("pending content revision not found", nil)
+	}
+	now := time.Now()
+	revision.Status = status
+	revision.ReviewedBy = &reviewerID
+	revision.ReviewedAt = &now
+	copied := *revision
+	return &copied, nil
+}
+
+func (r *memoryRevisionRepo) IsCollaborator(ctx context.Context, ownerID, collaboratorID uuid.UUID) (bool, error) {
+	return r.collaborators[collaboratorID] == ownerID, nil
+}
+
+// revisionTxManager runs the function directly and, like a rollback, puts
+// back the revisions it changed when it fails
+type revisionTxManager struct {
+	revisions    *memoryRevisionRepo
+	transactions int
+}
+
+func (m *revisionTxManager) WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	m.transactions++
+	saved := make(map[uuid.UUID]db.ContentRevision, len(m.revisions.revisions))
+	for id, revision := range m.revisions.revisions {
+		saved[id] = *revision
+	}
+
+	if err := fn(ctx); err != nil {
+		for id, revision := range saved {
+			restored := revision
+			m.revisions.revisions[id] = &restored
+		}
+		return err
+	}
+	return nil
+}
+
+type ContentRevisionTestSuite struct {
+	suite.Suite
+	ctx          context.Context
+	owner        *db.User
+	collaborator *db.User
+	admin        *db.User
+	stranger     *db.User
+	item         *db.ContentItem
+	contents     *revisionContentRepo
+	revisions    *memoryRevisionRepo
+	tx           *revisionTxManager
+	svc          service.ContentService
+}
+
+func (suite *ContentRevisionTestSuite) SetupTest() {
+	suite.ctx = context.Background()
+	isAdmin := true
+	suite.owner = &db.User{UserID: uuid.New(), Username: "owner", RequiresContentApproval: true}
+	suite.collaborator = &db.User{UserID: uuid.New(), Username: "collaborator"}
+	suite.admin = &db.User{UserID: uuid.New(), Username: "admin", IsAdmin: &isAdmin}
+	suite.stranger = &db.User{UserID: uuid.New(), Username: "stranger"}
+
+	title := "Original"
+	active := true
+	suite.item = &db.ContentItem{
+		ItemID:      uuid.New(),
+		UserID:      suite.owner.UserID,
+		ContentType: "link",
+		Title:       &title,
+		IsActive:    &active,
+		ContentData: pgtype.JSONB{Status: pgtype.Null},
+		Overrides:   pgtype.JSONB{Status: pgtype.Null},
+	}
+	suite.contents = &revisionContentRepo{pinContentRepo{items: map[uuid.UUID]*db.ContentItem{suite.item.ItemID: suite.item}}}
+	suite.revisions = &memoryRevisionRepo{
+		revisions:     map[uuid.UUID]*db.ContentRevision{},
+		collaborators: map[uuid.UUID]uuid.UUID{suite.collaborator.UserID: suite.owner.UserID},
+	}
+	suite.tx = &revisionTxManager{revisions: suite.revisions}
+
+	users := &repostUserRepo{users: map[uuid.UUID]*db.User{}}
+	for _, user := range []*db.User{suite.owner, suite.collaborator, suite.admin, suite.stranger} {
+		users.users[user.UserID] = user
+	}
+	suite.svc = service.NewContentService(suite.contents, users, suite.revisions, nil, &discardHistoryRepo{}, suite.tx, nil,
+		service.ContentConfig{}, log.Development().WithLayer("ContentRevisionTest"))
+}
+
+// propose has the collaborator retitle the item, returning the pending revision
+func (suite *ContentRevisionTestSuite) propose(title string) *service.ContentRevisionDTO {
+	result, err := suite.svc.SubmitContentUpdate(suite.ctx, suite.collaborator.UserID.String(), suite.item.ItemID.String(),
+		service.UpdateContentItemInput{Title: &title})
+	require.NoError(suite.T(), err)
+	require.True(suite.T(), result.PendingApproval)
+	require.NotNil(suite.T(), result.Revision)
+	return result.Revision
+}
+
+func (suite *ContentRevisionTestSuite) approve(actor *db.User, revision *service.ContentRevisionDTO) (*service.ContentItemDTO, error) {
+	return suite.svc.ApproveRevision(suite.ctx, actor.UserID.String(), suite.item.ItemID.String(), revision.ID)
+}
+
+func (suite *ContentRevisionTestSuite) reject(actor *db.User, revision *service.ContentRevisionDTO) (*service.ContentRevisionDTO, error) {
+	return suite.svc.RejectRevision(suite.ctx, actor.UserID.String(), suite.item.ItemID.String(), revision.ID)
+}
+
+func (suite *ContentRevisionTestSuite) assertCode(err error, code string) {
+	var appErr *errors.AppError
+	require.True(suite.T(), stderrors.As(err, &appErr), err)
+	assert.Equal(suite.T(), code, appErr.Code)
+}
+
+func (suite *ContentRevisionTestSuite) title() string {
+	return *suite.contents.items[suite.item.ItemID].Title
+}
+
+func (suite *ContentRevisionTestSuite) status(revision *service.ContentRevisionDTO) string {
+	return suite.revisions.revisions[uuid.MustParse(revision.ID)].Status
+}
+
+func (suite *ContentRevisionTestSuite) TestCollaboratorEditWaitsForApproval() {
+	revision := suite.propose("Proposed")
+
+	assert.Equal(suite.T(), service.RevisionPending, revision.Status)
+	assert.Equal(suite.T(), suite.collaborator.UserID.String(), revision.AuthorID)
+	assert.Equal(suite.T(), "Original", suite.title(), "the live item is unchanged until approval")
+}
+
+func (suite *ContentRevisionTestSuite) TestOwnerApproves() {
+	revision := suite.propose("Proposed")
+
+	updated, err := suite.approve(suite.owner, revision)
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "Proposed", updated.Title)
+	assert.Equal(suite.T(), "Proposed", suite.title())
+	assert.Equal(suite.T(), service.RevisionApproved, suite.status(revision))
+	assert.Equal(suite.T(), 1, suite.tx.transactions)
+}
+
+func (suite *ContentRevisionTestSuite) TestAdminRejects() {
+	revision := suite.propose("Proposed")
+
+	rejected, err := suite.reject(suite.admin, revision)
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), service.RevisionRejected, rejected.Status)
+	assert.Equal(suite.T(), suite.admin.UserID.String(), rejected.ReviewedBy)
+	assert.Equal(suite.T(), "Original", suite.title())
+}
+
+func (suite *ContentRevisionTestSuite) TestOthersCannotReview() {
+	revision := suite.propose("Proposed")
+
+	_, err := suite.approve(suite.stranger, revision)
+	suite.assertCode(err, "FORBIDDEN")
+	_, err = suite.reject(suite.collaborator, revision)
+	suite.assertCode(err, "FORBIDDEN")
+
+	assert.Equal(suite.T(), service.RevisionPending, suite.status(revision))
+	assert.Equal(suite.T(), "Original", suite.title())
+}
+
+func (suite *ContentRevisionTestSuite) TestStrangersCannotEdit() {
+	title := "Defaced"
+	_, err := suite.svc.SubmitContentUpdate(suite.ctx, suite.stranger.UserID.String(), suite.item.ItemID.String(),
+		service.UpdateContentItemInput{Title: &title})
+	suite.assertCode(err, "FORBIDDEN")
+	assert.Empty(suite.T(), suite.revisions.revisions)
+}
+
+func (suite *ContentRevisionTestSuite) TestReviewingTwiceConflicts() {
+	revision := suite.propose("Proposed")
+
+	_, err := suite.approve(suite.owner, revision)
+	require.NoError(suite.T(), err)
+
+	_, err = suite.approve(suite.owner, revision)
+	suite.assertCode(err, "CONFLICT")
+	_, err = suite.reject(suite.admin, revision)
+	suite.assertCode(err, "CONFLICT")
+	assert.Equal(suite.T(), service.RevisionApproved, suite.status(revision))
+}
+
+// TestApprovalLosingARaceChangesNothing has a reject land between the
+// approver's check and its claim: the approval conflicts and the changes
+// are never applied
+func (suite *ContentRevisionTestSuite) TestApprovalLosingARaceChangesNothing() {
+	revision := suite.propose("Proposed")
+	suite.revisions.beforeReview = func(pending *db.ContentRevision) {
+		pending.Status = service.RevisionRejected
+	}
+
+	_, err := suite.approve(suite.owner, revision)
+	suite.assertCode(err, "CONFLICT")
+	assert.Equal(suite.T(), "Original", suite.title())
+}
+
+// TestFailedApplyLeavesRevisionPending rolls the claim back when the changes
+// can't be applied, so the revision can be reviewed again
+func (suite *ContentRevisionTestSuite) TestFailedApplyLeavesRevisionPending() {
+	revision := suite.propose("Proposed")
+	delete(suite.contents.items, suite.item.ItemID)
+
+	_, err := suite.approve(suite.owner, revision)
+	assert.True(suite.T(), errors.IsNotFound(err), err)
+	assert.Equal(suite.T(), service.RevisionPending, suite.status(revision))
+}
+
+func TestContentRevisionTestSuite(t *testing.T) {
+	suite.Run(t, new(ContentRevisionTestSuite))
+}
@@ -0,0 +1,88 @@
+// test/unit/content_schedule_test.go
+package unit
+
+import (
+	"context"
+	stderrors "errors"
+	"testing"
+	"time"
+
+	db "github.com/0xsj/mios.io/db/sqlc"
+	"github.com/0xsj/mios.io/log"
+	"github.com/0xsj/mios.io/pkg/errors"
+	"github.com/0xsj/mios.io/service"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type ContentScheduleTestSuite struct {
+	suite.Suite
+	ctx                      context.Context
+	userID                   uuid.UUID
+	live, scheduled, expired *db.ContentItem
+	svc                      service.ContentService
+}
+
+func (suite *ContentScheduleTestSuite) SetupTest() {
+	suite.ctx = context.Background()
+	suite.userID = uuid.New()
+
+	lastWeek, yesterday := time.Now().Add(-7*24*time.Hour), time.Now().Add(-24*time.Hour)
+	tomorrow, nextWeek := time.Now().Add(24*time.Hour), time.Now().Add(7*24*time.Hour)
+	item := func(publishAt, unpublishAt *time.Time) *db.ContentItem {
+		return &db.ContentItem{ItemID: uuid.New(), UserID: suite.userID, ContentType: "link", PublishAt: publishAt, UnpublishAt: unpublishAt}
+	}
+	suite.live = item(&yesterday, &tomorrow)
+	suite.scheduled = item(&tomorrow, &nextWeek)
+	suite.expired = item(&lastWeek, &yesterday)
+
+	repo := &orderContentRepo{items: []*db.ContentItem{suite.live, suite.scheduled, suite.expired}}
+	suite.svc = service.NewContentService(repo, &orderUserRepo{}, nil, nil, &discardHistoryRepo{}, nil, nil,
+		service.ContentConfig{}, log.Development().WithLayer("ContentScheduleTest"))
+}
+
+func (suite *ContentScheduleTestSuite) TestVisitorsOnlySeeLiveItems() {
+	dtos, err := suite.svc.GetUserContentItems(suite.ctx, suite.userID.String(), "")
+	require.NoError(suite.T(), err)
+
+	require.Len(suite.T(), dtos, 1)
+	assert.Equal(suite.T(), suite.live.ItemID.String(), dtos[0].ID)
+	assert.Empty(suite.T(), dtos[0].ScheduleStatus)
+}
+
+func (suite *ContentScheduleTestSuite) TestOwnerSeesFlaggedItems() {
+	dtos, err := suite.svc.GetUserContentItems(suite.ctx, suite.userID.String(), suite.userID.String())
+	require.NoError(suite.T(), err)
+
+	statuses := make(map[string]string)
+	for _, dto := range dtos {
+		statuses[dto.ID] = dto.ScheduleStatus
+	}
+	assert.Equal(suite.T(), map[string]string{
+		suite.live.ItemID.String():      "",
+		suite.scheduled.ItemID.String(): service.ScheduleStatusScheduled,
+		suite.expired.ItemID.String():   service.ScheduleStatusExpired,
+	}, statuses)
+}
+
+func (suite *ContentScheduleTestSuite) TestRejectsWindowThatClosesBeforeItOpens() {
+	publishAt := time.Now().Add(time.Hour)
+	unpublishAt := publishAt.Add(-time.Minute)
+
+	_, err := suite.svc.CreateContentItem(suite.ctx, service.CreateContentItemInput{
+		UserID:      suite.userID.String(),
+		ContentID:   "promo",
+		ContentType: "link",
+		PublishAt:   &publishAt,
+		UnpublishAt: &unpublishAt,
+	})
+	var appErr *errors.AppError
+	require.True(suite.T(), stderrors.As(err, &appErr), err)
+	assert.Equal(suite.T(), "VALIDATION_ERROR", appErr.Code)
+}
+
+func TestContentScheduleTestSuite(t *testing.T) {
+	suite.Run(t, new(ContentScheduleTestSuite))
+}
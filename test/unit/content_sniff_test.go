@@ -0,0 +1,99 @@
+// test/unit/content_sniff_test.go
+package unit
+
+import (
+	"bytes"
+	"context"
+	stderrors "errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/0xsj/mios.io/log"
+	"github.com/0xsj/mios.io/pkg/errors"
+	"github.com/0xsj/mios.io/pkg/storage"
+	"github.com/0xsj/mios.io/service"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type ContentSniffTestSuite struct {
+	suite.Suite
+	dir string
+	svc service.FileService
+}
+
+func (suite *ContentSniffTestSuite) SetupTest() {
+	dir, err := os.MkdirTemp("", "content_sniff_test_*")
+	require.NoError(suite.T(), err)
+	suite.dir = dir
+
+	logger := log.Development().WithLayer("ContentSniffTest")
+	suite.svc = service.NewFileService(storage.NewLocalStorage(dir, avatarBaseURL, logger), nil, newMemoryFileRepo(), nil, service.FileServiceConfig{
+		AllowedImageTypes: []string{"image/jpeg", "image/jpg", "image/png", "image/gif", "image/webp"},
+		AllowedVideoTypes: []string{"video/mp4", "video/webm"},
+		AllowedFileTypes:  []string{"application/pdf", "text/plain", "application/json"},
+	}, logger)
+}
+
+func (suite *ContentSniffTestSuite) TearDownTest() {
+	os.RemoveAll(suite.dir)
+}
+
+func (suite *ContentSniffTestSuite) upload(data []byte, contentType string) (*service.FileUploadResult, error) {
+	return suite.svc.UploadContentMedia(context.Background(), uuid.New().String(), service.UploadFileInput{
+		File:        bytes.NewReader(data),
+		Filename:    "upload",
+		ContentType: contentType,
+	})
+}
+
+func (suite *ContentSniffTestSuite) assertStored(data []byte, contentType string) {
+	result, err := suite.upload(data, contentType)
+	require.NoError(suite.T(), err, contentType)
+
+	stored, err := os.ReadFile(filepath.Join(suite.dir, result.Key))
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), data, stored, "the sniffed bytes are stored too")
+}
+
+func (suite *ContentSniffTestSuite) assertRejected(data []byte, contentType string) {
+	_, err := suite.upload(data, contentType)
+	var appErr *errors.AppError
+	require.True(suite.T(), stderrors.As(err, &appErr), "%s: %v", contentType, err)
+	assert.Equal(suite.T(), "VALIDATION_ERROR", appErr.Code)
+}
+
+func (suite *ContentSniffTestSuite) TestMatchingContentIsStoredWhole() {
+	img := image.NewNRGBA(image.Rect(0, 0, 200, 200))
+	for i := range img.Pix {
+		img.Pix[i] = uint8(i * 7)
+	}
+	suite.assertStored(encodePNG(suite.T(), img), "image/png")
+
+	var jpg bytes.Buffer
+	require.NoError(suite.T(), jpeg.Encode(&jpg, solidImage(32, 32, color.White, color.Black), nil))
+	suite.assertStored(jpg.Bytes(), "image/jpg")
+
+	document := []byte(`{"items": [` + strings.Repeat(`"entry", `, 1000) + `"last"]}`)
+	suite.assertStored(document, "application/json")
+	suite.assertStored(document, "text/plain")
+}
+
+func (suite *ContentSniffTestSuite) TestSpoofedTypesAreRejected() {
+	executable := append([]byte("\x7fELF\x02\x01\x01\x00"), make([]byte, 64)...)
+	suite.assertRejected(executable, "image/png")
+	suite.assertRejected([]byte("just some notes"), "application/pdf")
+	suite.assertRejected([]byte("just some notes"), "application/json")
+	suite.assertRejected(encodePNG(suite.T(), solidImage(8, 8, color.White, color.Black)), "image/jpeg")
+}
+
+func TestContentSniffTestSuite(t *testing.T) {
+	suite.Run(t, new(ContentSniffTestSuite))
+}
@@ -0,0 +1,161 @@
+// test/unit/content_summary_test.go
+package unit
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/0xsj/mios.io/log"
+	"github.com/0xsj/mios.io/repository"
+	"github.com/0xsj/mios.io/service"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type summaryContentRepo struct {
+	repository.ContentRepository
+	counts []repository.ContentStateCount
+}
+
+func (r *summaryContentRepo) CountUserContentItemsByTypeAndState(ctx context.Context, userID uuid.UUID) ([]repository.ContentStateCount, error) {
+	return r.counts, nil
+}
+
+// memoryCache is an in-process cache.CacheService that stores JSON like
+// the Redis cache does
+type memoryCache struct {
+	entries map[string][]byte
+}
+
+func newMemoryCache() *memoryCache {
+	return &memoryCache{entries: make(map[string][]byte)}
+}
+
+func (c *memoryCache) Get(ctx context.Context, key string, dest interface{}) (bool, error) {
+	data, ok := c.entries[key]
+	if !ok {
+		return false, nil
+	}
+	return true, json.Unmarshal(data, dest)
+}
+
+func (c *memoryCache) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	c.entries[key] = data
+	return nil
+}
+
+func (c *memoryCache) Delete(ctx context.Context, keys ...string) error {
+	for _, key := range keys {
+		delete(c.entries, key)
+	}
+	return nil
+}
+
+func (c *memoryCache) DeletePattern(ctx context.Context, pattern string) error {
+	prefix := strings.TrimSuffix(pattern, "*")
+	for key := range c.entries {
+		if strings.HasPrefix(key, prefix) {
+			delete(c.entries, key)
+		}
+	}
+	return nil
+}
+
+func (c *memoryCache) GetOrSet(ctx context.Context, key string, dest interface{}, ttl time.Duration, fetchFn func() (interface{}, error)) error {
+	if found, err := c.Get(ctx, key, dest); found && err == nil {
+		return nil
+	}
+	value, err := fetchFn()
+	if err != nil {
+		return err
+	}
+	if err := c.Set(ctx, key, value, ttl); err != nil {
+		return err
+	}
+	_, err = c.Get(ctx, key, dest)
+	return err
+}
+
+// countingContentService counts summary reads and creates items for userID
+type countingContentService struct {
+	service.ContentService
+	userID    string
+	summaries int
+}
+
+func (s *countingContentService) GetContentSummary(ctx context.Context, userID string) (*service.ContentSummaryDTO, error) {
+	s.summaries++
+	return &service.ContentSummaryDTO{UserID: userID}, nil
+}
+
+func (s *countingContentService) CreateContentItem(ctx context.Context, input service.CreateContentItemInput) (*service.ContentItemDTO, error) {
+	return &service.ContentItemDTO{ID: uuid.NewString(), UserID: s.userID}, nil
+}
+
+type ContentSummaryTestSuite struct {
+	suite.Suite
+	userID string
+}
+
+func (suite *ContentSummaryTestSuite) SetupTest() {
+	suite.userID = uuid.NewString()
+}
+
+func (suite *ContentSummaryTestSuite) TestCountsByTypeAndState() {
+	repo := &summaryContentRepo{counts: []repository.ContentStateCount{
+		{ContentType: "link", State: service.ContentStateActive, Count: 5},
+		{ContentType: "link", State: service.ContentStateInactive, Count: 2},
+		{ContentType: "text", State: service.ContentStateActive, Count: 1},
+		{ContentType: "text", State: service.ContentStateDraft, Count: 3},
+	}}
+	svc := service.NewContentService(repo, nil, nil, nil, nil, nil, nil, service.ContentConfig{},
+		log.Development().WithLayer("ContentSummaryTest"))
+
+	summary, err := svc.GetContentSummary(context.Background(), suite.userID)
+	require.NoError(suite.T(), err)
+
+	assert.Equal(suite.T(), service.ContentStateCountsDTO{Total: 11, Active: 6, Inactive: 2, Draft: 3}, summary.ContentStateCountsDTO)
+	require.Len(suite.T(), summary.Types, 2)
+	assert.Equal(suite.T(), service.ContentStateCountsDTO{Total: 7, Active: 5, Inactive: 2}, *summary.Types["link"])
+	assert.Equal(suite.T(), service.ContentStateCountsDTO{Total: 4, Active: 1, Draft: 3}, *summary.Types["text"])
+}
+
+func (suite *ContentSummaryTestSuite) TestRejectsInvalidUserID() {
+	svc := service.NewContentService(&summaryContentRepo{}, nil, nil, nil, nil, nil, nil, service.ContentConfig{},
+		log.Development().WithLayer("ContentSummaryTest"))
+
+	_, err := svc.GetContentSummary(context.Background(), "not-a-uuid")
+	assert.Error(suite.T(), err)
+}
+
+func (suite *ContentSummaryTestSuite) TestCachedUntilContentChanges() {
+	base := &countingContentService{userID: suite.userID}
+	svc := service.NewCachedContentService(base, newMemoryCache(), log.Development().WithLayer("ContentSummaryTest"))
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		_, err := svc.GetContentSummary(ctx, suite.userID)
+		require.NoError(suite.T(), err)
+	}
+	assert.Equal(suite.T(), 1, base.summaries)
+
+	_, err := svc.CreateContentItem(ctx, service.CreateContentItemInput{UserID: suite.userID})
+	require.NoError(suite.T(), err)
+
+	_, err = svc.GetContentSummary(ctx, suite.userID)
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 2, base.summaries)
+}
+
+func TestContentSummaryTestSuite(t *testing.T) {
+	suite.Run(t, new(ContentSummaryTestSuite))
+}
@@ -0,0 +1,180 @@
+// test/unit/content_trash_test.go
+package unit
+
+import (
+	"context"
+	stderrors "errors"
+	"testing"
+	"time"
+
+	db "github.com/0xsj/mios.io/db/sqlc"
+	"github.com/0xsj/mios.io/log"
+	"github.com/0xsj/mios.io/pkg/errors"
+	"github.com/0xsj/mios.io/service"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+// trashContentRepo soft deletes its in-memory items, hiding them from the
+// regular lookups like the real queries do
+type trashContentRepo struct {
+	limitContentRepo
+	cutoffs []time.Time
+}
+
+func (r *trashContentRepo) GetContentItem(ctx context.Context, itemID uuid.UUID) (*db.ContentItem, error) {
+	if item, ok := r.items[itemID]; ok && item.DeletedAt != nil {
+		return nil, errors.NewNotFoundError("content item not found", nil)
+	}
+	return r.limitContentRepo.GetContentItem(ctx, itemID)
+}
+
+func (r *trashContentRepo) CountActiveUserContentItems(ctx context.Context, userID uuid.UUID) (int64, error) {
+	var count int64
+	for _, item := range r.items {
+		if item.UserID == userID && item.DeletedAt == nil && item.IsActive != nil && *item.IsActive {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *trashContentRepo) DeleteContentItem(ctx context.Context, itemID uuid.UUID) error {
+	now := time.Now()
+	r.items[itemID].DeletedAt = &now
+	return nil
+}
+
+func (r *trashContentRepo) GetDeletedContentItem(ctx context.Context, itemID uuid.UUID) (*db.ContentItem, error) {
+	item, ok := r.items[itemID]
+	if !ok || item.DeletedAt == nil {
+		return nil, errors.NewNotFoundError("deleted content item not found", nil)
+	}
+	copied := *item
+	return &copied, nil
+}
+
+func (r *trashContentRepo) ListDeletedUserContentItems(ctx context.Context, userID uuid.UUID) ([]*db.ContentItem, error) {
+	var items []*db.ContentItem
+	for _, item := range r.items {
+		if item.UserID == userID && item.DeletedAt != nil {
+			items = append(items, item)
+		}
+	}
+	return items, nil
+}
+
+func (r *trashContentRepo) RestoreContentItem(ctx context.Context, itemID uuid.UUID) error {
+	r.items[itemID].DeletedAt = nil
+	return nil
+}
+
+func (r *trashContentRepo) PurgeDeletedContentItems(ctx context.Context, deletedBefore time.Time, limit int) (int64, error) {
+	r.cutoffs = append(r.cutoffs, deletedBefore)
+	var removed int64
+	for id, item := range r.items {
+		if item.DeletedAt != nil && item.DeletedAt.Before(deletedBefore) && removed < int64(limit) {
+			delete(r.items, id)
+			removed++
+		}
+	}
+	return removed, nil
+}
+
+type ContentTrashTestSuite struct {
+	suite.Suite
+	ctx  context.Context
+	user *db.User
+	item *db.ContentItem
+	repo *trashContentRepo
+}
+
+func (suite *ContentTrashTestSuite) SetupTest() {
+	suite.ctx = context.Background()
+	suite.user = &db.User{UserID: uuid.New(), Username: "tester"}
+
+	active := true
+	suite.item = &db.ContentItem{ItemID: uuid.New(), UserID: suite.user.UserID, ContentType: "link", IsActive: &active}
+	suite.repo = &trashContentRepo{limitContentRepo: limitContentRepo{pinContentRepo{items: map[uuid.UUID]*db.ContentItem{
+		suite.item.ItemID: suite.item,
+	}}}}
+}
+
+func (suite *ContentTrashTestSuite) service(config service.ContentConfig) service.ContentService {
+	return service.NewContentService(suite.repo, &exportUserRepo{user: suite.user}, nil, nil, &discardHistoryRepo{}, nil, nil,
+		config, log.Development().WithLayer("ContentTrashTest"))
+}
+
+func (suite *ContentTrashTestSuite) TestDeletedItemCanBeRestored() {
+	svc := suite.service(service.ContentConfig{})
+	itemID := suite.item.ItemID.String()
+
+	require.NoError(suite.T(), svc.DeleteContentItem(suite.ctx, itemID))
+	_, err := svc.GetContentItem(suite.ctx, itemID)
+	assert.True(suite.T(), errors.IsNotFound(err), "trashed items are hidden")
+
+	trash, err := svc.ListDeletedContentItems(suite.ctx, suite.user.UserID.String())
+	require.NoError(suite.T(), err)
+	require.Len(suite.T(), trash, 1)
+	assert.NotEmpty(suite.T(), trash[0].DeletedAt)
+
+	restored, err := svc.RestoreContentItem(suite.ctx, suite.user.UserID.String(), itemID)
+	require.NoError(suite.T(), err)
+	assert.Empty(suite.T(), restored.DeletedAt)
+
+	_, err = svc.GetContentItem(suite.ctx, itemID)
+	assert.NoError(suite.T(), err)
+	_, err = svc.RestoreContentItem(suite.ctx, suite.user.UserID.String(), itemID)
+	assert.True(suite.T(), errors.IsNotFound(err), "only trashed items can be restored")
+}
+
+func (suite *ContentTrashTestSuite) TestOnlyOwnerCanRestore() {
+	svc := suite.service(service.ContentConfig{})
+	require.NoError(suite.T(), svc.DeleteContentItem(suite.ctx, suite.item.ItemID.String()))
+
+	_, err := svc.RestoreContentItem(suite.ctx, uuid.NewString(), suite.item.ItemID.String())
+	var appErr *errors.AppError
+	require.True(suite.T(), stderrors.As(err, &appErr), err)
+	assert.Equal(suite.T(), "FORBIDDEN", appErr.Code)
+	assert.NotNil(suite.T(), suite.item.DeletedAt, "item stays in the trash")
+}
+
+func (suite *ContentTrashTestSuite) TestRestoreRespectsActiveItemLimit() {
+	svc := suite.service(service.ContentConfig{MaxActiveItemsFree: 1})
+	require.NoError(suite.T(), svc.DeleteContentItem(suite.ctx, suite.item.ItemID.String()))
+
+	active := true
+	replacement := &db.ContentItem{ItemID: uuid.New(), UserID: suite.user.UserID, ContentType: "link", IsActive: &active}
+	suite.repo.items[replacement.ItemID] = replacement
+
+	_, err := svc.RestoreContentItem(suite.ctx, suite.user.UserID.String(), suite.item.ItemID.String())
+	var appErr *errors.AppError
+	require.True(suite.T(), stderrors.As(err, &appErr), err)
+	assert.Equal(suite.T(), "VALIDATION_ERROR", appErr.Code)
+	assert.NotNil(suite.T(), suite.item.DeletedAt, "item stays in the trash")
+}
+
+func (suite *ContentTrashTestSuite) TestPurgeRemovesOnlyExpiredItems() {
+	expiredAt := time.Now().Add(-40 * 24 * time.Hour)
+	suite.item.DeletedAt = &expiredAt
+
+	recentAt := time.Now().Add(-24 * time.Hour)
+	recent := &db.ContentItem{ItemID: uuid.New(), UserID: suite.user.UserID, DeletedAt: &recentAt}
+	suite.repo.items[recent.ItemID] = recent
+
+	before := time.Now()
+	removed, err := suite.service(service.ContentConfig{}).PurgeDeletedContentItems(suite.ctx)
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), int64(1), removed)
+	assert.Contains(suite.T(), suite.repo.items, recent.ItemID)
+
+	require.Len(suite.T(), suite.repo.cutoffs, 1)
+	assert.WithinDuration(suite.T(), before.Add(-30*24*time.Hour), suite.repo.cutoffs[0], time.Second,
+		"trash is kept for 30 days by default")
+}
+
+func TestContentTrashTestSuite(t *testing.T) {
+	suite.Run(t, new(ContentTrashTestSuite))
+}
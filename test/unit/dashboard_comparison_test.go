@@ -0,0 +1,123 @@
+// test/unit/dashboard_comparison_test.go
+package unit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	db "github.com/0xsj/mios.io/db/sqlc"
+	"github.com/0xsj/mios.io/log"
+	"github.com/0xsj/mios.io/repository"
+	"github.com/0xsj/mios.io/service"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+// dashboardAnalyticsRepo serves empty dashboard series and returns period
+// totals in the order they are asked for
+type dashboardAnalyticsRepo struct {
+	repository.AnalyticsRepository
+	totals      []*repository.PeriodTotals
+	totalsCalls []repository.TimeRangeParams
+}
+
+func (r *dashboardAnalyticsRepo) GetProfilePageViews(ctx context.Context, userID uuid.UUID, includeBots bool) (int64, error) {
+	return 0, nil
+}
+
+func (r *dashboardAnalyticsRepo) GetUserItemClickCount(ctx context.Context, userID uuid.UUID, includeBots bool) (int64, error) {
+	return 0, nil
+}
+
+func (r *dashboardAnalyticsRepo) GetUniqueVisitors(ctx context.Context, params repository.TimeRangeParams) (int64, error) {
+	return 0, nil
+}
+
+func (r *dashboardAnalyticsRepo) GetProfilePageViewsByDate(ctx context.Context, params repository.TimeRangeParams) ([]repository.DailyAnalytics, error) {
+	return nil, nil
+}
+
+func (r *dashboardAnalyticsRepo) GetUniqueVisitorsByDay(ctx context.Context, params repository.TimeRangeParams) ([]repository.VisitorAnalytics, error) {
+	return nil, nil
+}
+
+func (r *dashboardAnalyticsRepo) GetTopContentItemsByClicks(ctx context.Context, params repository.TopItemsParams) ([]repository.TopContentItem, error) {
+	return nil, nil
+}
+
+func (r *dashboardAnalyticsRepo) GetReferrerAnalytics(ctx context.Context, params repository.ReferrerParams) ([]repository.ReferrerStats, error) {
+	return nil, nil
+}
+
+func (r *dashboardAnalyticsRepo) GetPeriodTotals(ctx context.Context, params repository.TimeRangeParams) (*repository.PeriodTotals, error) {
+	r.totalsCalls = append(r.totalsCalls, params)
+	return r.totals[len(r.totalsCalls)-1], nil
+}
+
+type DashboardComparisonTestSuite struct {
+	suite.Suite
+	user *db.User
+	repo *dashboardAnalyticsRepo
+	svc  service.AnalyticsService
+}
+
+func (suite *DashboardComparisonTestSuite) SetupTest() {
+	suite.user = &db.User{UserID: uuid.New(), Username: "tester", AnalyticsEnabled: true}
+	suite.repo = &dashboardAnalyticsRepo{}
+	suite.svc = service.NewAnalyticsService(suite.repo, &pinContentRepo{}, &exportUserRepo{user: suite.user}, nil, nil, nil, nil,
+		service.AnalyticsExportConfig{},
+		service.AnalyticsConfig{},
+		log.Development().WithLayer("DashboardComparisonTest"))
+}
+
+func (suite *DashboardComparisonTestSuite) TestComparesWithPreviousPeriod() {
+	suite.repo.totals = []*repository.PeriodTotals{
+		{Views: 150, Clicks: 30, UniqueVisitors: 40},
+		{Views: 100, Clicks: 40, UniqueVisitors: 40},
+	}
+
+	dashboard, err := suite.svc.GetProfileDashboard(context.Background(), suite.user.UserID.String(), 7, false)
+	require.NoError(suite.T(), err)
+	require.NotNil(suite.T(), dashboard.Comparison)
+
+	comparison := dashboard.Comparison
+	assert.Equal(suite.T(), int64(150), comparison.Current.Views)
+	assert.Equal(suite.T(), int64(100), comparison.Previous.Views)
+	assert.Equal(suite.T(), int64(40), comparison.Previous.Clicks)
+	require.NotNil(suite.T(), comparison.ViewsChange)
+	assert.InDelta(suite.T(), 50.0, *comparison.ViewsChange, 0.001)
+	require.NotNil(suite.T(), comparison.ClicksChange)
+	assert.InDelta(suite.T(), -25.0, *comparison.ClicksChange, 0.001)
+	require.NotNil(suite.T(), comparison.UniqueVisitorsChange)
+	assert.InDelta(suite.T(), 0.0, *comparison.UniqueVisitorsChange, 0.001)
+
+	// The previous window ends where the current one starts and is as long
+	require.Len(suite.T(), suite.repo.totalsCalls, 2)
+	current, previous := suite.repo.totalsCalls[0], suite.repo.totalsCalls[1]
+	assert.Equal(suite.T(), current.StartDate, previous.EndDate)
+	assert.Equal(suite.T(), current.EndDate.Sub(current.StartDate), previous.EndDate.Sub(previous.StartDate))
+	assert.WithinDuration(suite.T(), time.Now().AddDate(0, 0, -14), previous.StartDate, time.Minute)
+}
+
+func (suite *DashboardComparisonTestSuite) TestEmptyPreviousPeriodHasNoChange() {
+	suite.repo.totals = []*repository.PeriodTotals{
+		{Views: 10, Clicks: 2, UniqueVisitors: 5},
+		{},
+	}
+
+	dashboard, err := suite.svc.GetProfileDashboard(context.Background(), suite.user.UserID.String(), 7, false)
+	require.NoError(suite.T(), err)
+
+	comparison := dashboard.Comparison
+	assert.Zero(suite.T(), comparison.Previous.Views)
+	assert.Nil(suite.T(), comparison.ViewsChange)
+	assert.Nil(suite.T(), comparison.ClicksChange)
+	assert.Nil(suite.T(), comparison.UniqueVisitorsChange)
+}
+
+func TestDashboardComparisonSuite(t *testing.T) {
+	suite.Run(t, new(DashboardComparisonTestSuite))
+}
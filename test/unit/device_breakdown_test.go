@@ -0,0 +1,122 @@
+// test/unit/device_breakdown_test.go
+package unit
+
+import (
+	"context"
+	"testing"
+
+	db "github.com/0xsj/mios.io/db/sqlc"
+	"github.com/0xsj/mios.io/log"
+	"github.com/0xsj/mios.io/pkg/useragent"
+	"github.com/0xsj/mios.io/repository"
+	"github.com/0xsj/mios.io/service"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+const (
+	uaChromeWindows  = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36"
+	uaSafariIPhone   = "Mozilla/5.0 (iPhone; CPU iPhone OS 17_4 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.4 Mobile/15E148 Safari/604.1"
+	uaChromeAndroid  = "Mozilla/5.0 (Linux; Android 14; Pixel 8) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Mobile Safari/537.36"
+	uaAndroidTablet  = "Mozilla/5.0 (Linux; Android 13; SM-X700) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36"
+	uaSafariIPad     = "Mozilla/5.0 (iPad; CPU OS 17_4 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.4 Mobile/15E148 Safari/604.1"
+	uaEdgeMac        = "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36 Edg/124.0.0.0"
+	uaFirefoxLinux   = "Mozilla/5.0 (X11; Linux x86_64; rv:125.0) Gecko/20100101 Firefox/125.0"
+	uaGooglebot      = "Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)"
+	uaChromeOnIPhone = "Mozilla/5.0 (iPhone; CPU iPhone OS 17_4 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) CriOS/124.0.6367.88 Mobile/15E148 Safari/604.1"
+)
+
+type userAgentAnalyticsRepo struct {
+	repository.AnalyticsRepository
+	counts []repository.UserAgentCount
+}
+
+func (r *userAgentAnalyticsRepo) GetUserAgentCounts(ctx context.Context, params repository.TimeRangeParams) ([]repository.UserAgentCount, error) {
+	return r.counts, nil
+}
+
+type DeviceBreakdownTestSuite struct {
+	suite.Suite
+	userID uuid.UUID
+	repo   *userAgentAnalyticsRepo
+	svc    service.AnalyticsService
+}
+
+func (suite *DeviceBreakdownTestSuite) SetupTest() {
+	suite.userID = uuid.New()
+	suite.repo = &userAgentAnalyticsRepo{}
+
+	users := &exportUserRepo{user: &db.User{UserID: suite.userID, Username: "tester"}}
+	suite.svc = service.NewAnalyticsService(suite.repo, nil, users, nil, nil, nil, nil,
+		service.AnalyticsExportConfig{},
+		service.AnalyticsConfig{},
+		log.Development().WithLayer("DeviceBreakdownTest"))
+}
+
+func (suite *DeviceBreakdownTestSuite) TestParseUserAgents() {
+	cases := []struct {
+		ua   string
+		want useragent.Info
+	}{
+		{uaChromeWindows, useragent.Info{DeviceType: useragent.DeviceDesktop, OS: "Windows", Browser: "Chrome"}},
+		{uaSafariIPhone, useragent.Info{DeviceType: useragent.DeviceMobile, OS: "iOS", Browser: "Safari"}},
+		{uaChromeAndroid, useragent.Info{DeviceType: useragent.DeviceMobile, OS: "Android", Browser: "Chrome"}},
+		{uaAndroidTablet, useragent.Info{DeviceType: useragent.DeviceTablet, OS: "Android", Browser: "Chrome"}},
+		{uaSafariIPad, useragent.Info{DeviceType: useragent.DeviceTablet, OS: "iOS", Browser: "Safari"}},
+		{uaEdgeMac, useragent.Info{DeviceType: useragent.DeviceDesktop, OS: "macOS", Browser: "Edge"}},
+		{uaFirefoxLinux, useragent.Info{DeviceType: useragent.DeviceDesktop, OS: "Linux", Browser: "Firefox"}},
+		{uaGooglebot, useragent.Info{DeviceType: useragent.DeviceBot, OS: useragent.Other, Browser: useragent.Other}},
+		{uaChromeOnIPhone, useragent.Info{DeviceType: useragent.DeviceMobile, OS: "iOS", Browser: "Chrome"}},
+		{"", useragent.Info{DeviceType: useragent.Unknown, OS: useragent.Unknown, Browser: useragent.Unknown}},
+	}
+
+	for _, tc := range cases {
+		assert.Equal(suite.T(), tc.want, useragent.Parse(tc.ua), tc.ua)
+	}
+}
+
+func (suite *DeviceBreakdownTestSuite) TestAggregatesCountsAndPercentages() {
+	suite.repo.counts = []repository.UserAgentCount{
+		{UserAgent: uaChromeWindows, Count: 5},
+		{UserAgent: uaSafariIPhone, Count: 3},
+		{UserAgent: uaChromeAndroid, Count: 2},
+	}
+
+	breakdown, err := suite.svc.GetDeviceBreakdown(context.Background(), suite.userID.String(), service.TimeRangeInput{
+		StartDate: "2025-01-01T00:00:00Z",
+		EndDate:   "2025-02-01T00:00:00Z",
+	})
+	require.NoError(suite.T(), err)
+
+	assert.Equal(suite.T(), int64(10), breakdown.TotalCount)
+
+	require.Len(suite.T(), breakdown.Devices, 2)
+	assert.Equal(suite.T(), useragent.DeviceDesktop, breakdown.Devices[0].Name)
+	assert.Equal(suite.T(), 50.0, breakdown.Devices[0].Percentage)
+	assert.Equal(suite.T(), useragent.DeviceMobile, breakdown.Devices[1].Name)
+	assert.Equal(suite.T(), int64(5), breakdown.Devices[1].Count)
+
+	require.Len(suite.T(), breakdown.Browsers, 2)
+	assert.Equal(suite.T(), "Chrome", breakdown.Browsers[0].Name)
+	assert.Equal(suite.T(), 70.0, breakdown.Browsers[0].Percentage)
+
+	// Equal counts fall back to name order
+	require.Len(suite.T(), breakdown.OperatingSystems, 3)
+	assert.Equal(suite.T(), "Windows", breakdown.OperatingSystems[0].Name)
+	assert.Equal(suite.T(), "iOS", breakdown.OperatingSystems[1].Name)
+	assert.Equal(suite.T(), "Android", breakdown.OperatingSystems[2].Name)
+}
+
+func (suite *DeviceBreakdownTestSuite) TestRejectsInvertedRange() {
+	_, err := suite.svc.GetDeviceBreakdown(context.Background(), suite.userID.String(), service.TimeRangeInput{
+		StartDate: "2025-02-01T00:00:00Z",
+		EndDate:   "2025-01-01T00:00:00Z",
+	})
+	assert.Error(suite.T(), err)
+}
+
+func TestDeviceBreakdownTestSuite(t *testing.T) {
+	suite.Run(t, new(DeviceBreakdownTestSuite))
+}
@@ -0,0 +1,145 @@
+// test/unit/domain_verification_test.go
+package unit
+
+import (
+	"context"
+	stderrors "errors"
+	"net"
+	"testing"
+	"time"
+
+	db "github.com/0xsj/mios.io/db/sqlc"
+	"github.com/0xsj/mios.io/log"
+	"github.com/0xsj/mios.io/pkg/errors"
+	"github.com/0xsj/mios.io/service"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+// domainUserRepo stores custom domain verification on its one user and
+// resolves the domain like GetUserByCustomDomain
+type domainUserRepo struct {
+	exportUserRepo
+}
+
+func (r *domainUserRepo) SetCustomDomainVerification(ctx context.Context, userID uuid.UUID, domain, token string) error {
+	r.user.CustomDomain = &domain
+	r.user.CustomDomainToken = &token
+	r.user.CustomDomainVerifiedAt = nil
+	return nil
+}
+
+func (r *domainUserRepo) MarkCustomDomainVerified(ctx context.Context, userID uuid.UUID, domain, token string) error {
+	now := time.Now()
+	r.user.CustomDomainVerifiedAt = &now
+	return nil
+}
+
+func (r *domainUserRepo) GetUserByHandle(ctx context.Context, handle string) (*db.User, error) {
+	return nil, errors.NewNotFoundError("User not found", nil)
+}
+
+func (r *domainUserRepo) GetUserIDByPreviousHandle(ctx context.Context, handle string) (uuid.UUID, error) {
+	return uuid.Nil, errors.NewNotFoundError("Handle not found", nil)
+}
+
+func (r *domainUserRepo) GetUserByCustomDomain(ctx context.Context, domain string) (*db.User, error) {
+	if r.user.CustomDomain == nil || *r.user.CustomDomain != domain {
+		return nil, errors.NewNotFoundError("User not found", nil)
+	}
+	return r.user, nil
+}
+
+// fakeTXTResolver serves TXT records from a map, failing like a missing
+// name for anything else
+type fakeTXTResolver map[string][]string
+
+func (r fakeTXTResolver) LookupTXT(ctx context.Context, name string) ([]string, error) {
+	if records, ok := r[name]; ok {
+		return records, nil
+	}
+	return nil, &net.DNSError{Err: "no such host", Name: name, IsNotFound: true}
+}
+
+type DomainVerificationTestSuite struct {
+	suite.Suite
+	ctx      context.Context
+	user     *db.User
+	dns      fakeTXTResolver
+	users    service.UserService
+	profiles service.ProfileService
+}
+
+func (suite *DomainVerificationTestSuite) SetupTest() {
+	suite.ctx = context.Background()
+	suite.user = &db.User{UserID: uuid.New(), Handle: "jane"}
+	suite.dns = fakeTXTResolver{}
+
+	logger := log.Development().WithLayer("DomainVerificationTest")
+	repo := &domainUserRepo{exportUserRepo{user: suite.user}}
+	suite.users = service.NewUserService(repo, nil, nil, nil, nil, nil, nil, nil, service.UserConfig{DomainResolver: suite.dns}, logger)
+	suite.profiles = service.NewProfileService(repo, nil, nil, service.ContentConfig{}, service.ProfileConfig{}, logger)
+}
+
+func (suite *DomainVerificationTestSuite) TestVerifiesPublishedRecord() {
+	id := suite.user.UserID.String()
+
+	pending, err := suite.users.InitiateDomainVerification(suite.ctx, id, " Jane.Example.com. ")
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "jane.example.com", pending.Domain)
+	assert.Equal(suite.T(), "TXT", pending.RecordType)
+	assert.Equal(suite.T(), "_mios-verify.jane.example.com", pending.RecordName)
+	assert.NotEmpty(suite.T(), pending.RecordValue)
+	assert.False(suite.T(), pending.Verified)
+
+	status, err := suite.users.CheckDomainVerification(suite.ctx, id)
+	require.NoError(suite.T(), err)
+	assert.False(suite.T(), status.Verified)
+
+	_, _, err = suite.profiles.ResolveCanonical(suite.ctx, "jane.example.com")
+	assert.True(suite.T(), errors.IsNotFound(err), "unverified domains should not resolve")
+
+	suite.dns[pending.RecordName] = []string{"v=spf1 -all", pending.RecordValue}
+	status, err = suite.users.CheckDomainVerification(suite.ctx, id)
+	require.NoError(suite.T(), err)
+	assert.True(suite.T(), status.Verified)
+	assert.NotEmpty(suite.T(), status.VerifiedAt)
+
+	handle, _, err := suite.profiles.ResolveCanonical(suite.ctx, "jane.example.com")
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "jane", handle)
+}
+
+func (suite *DomainVerificationTestSuite) TestReinitiatingKeepsToken() {
+	id := suite.user.UserID.String()
+
+	first, err := suite.users.InitiateDomainVerification(suite.ctx, id, "jane.example.com")
+	require.NoError(suite.T(), err)
+	again, err := suite.users.InitiateDomainVerification(suite.ctx, id, "jane.example.com")
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), first.RecordValue, again.RecordValue)
+
+	other, err := suite.users.InitiateDomainVerification(suite.ctx, id, "links.example.org")
+	require.NoError(suite.T(), err)
+	assert.NotEqual(suite.T(), first.RecordValue, other.RecordValue)
+}
+
+func (suite *DomainVerificationTestSuite) TestRejectsInvalidDomains() {
+	for _, domain := range []string{"localhost", "192.168.1.10", "-bad.example.com", "exa mple.com", "https://example.com"} {
+		_, err := suite.users.InitiateDomainVerification(suite.ctx, suite.user.UserID.String(), domain)
+		var appErr *errors.AppError
+		require.True(suite.T(), stderrors.As(err, &appErr), domain)
+		assert.Equal(suite.T(), "VALIDATION_ERROR", appErr.Code, domain)
+	}
+}
+
+func (suite *DomainVerificationTestSuite) TestCheckWithoutDomain() {
+	_, err := suite.users.CheckDomainVerification(suite.ctx, suite.user.UserID.String())
+	assert.True(suite.T(), errors.IsNotFound(err))
+}
+
+func TestDomainVerificationTestSuite(t *testing.T) {
+	suite.Run(t, new(DomainVerificationTestSuite))
+}
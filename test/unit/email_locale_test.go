@@ -0,0 +1,105 @@
+// test/unit/email_locale_test.go
+package unit
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/0xsj/mios.io/pkg/email"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type EmailLocaleTestSuite struct {
+	suite.Suite
+	dir     string
+	manager *email.TemplateManager
+}
+
+func (suite *EmailLocaleTestSuite) SetupTest() {
+	dir := suite.T().TempDir()
+	suite.dir = dir
+	templates := map[string]string{
+		"welcome.html":    `<html lang="{{.Locale}}" dir="{{.Dir}}">Welcome {{.Username}}</html>`,
+		"fr/welcome.html": `<html lang="{{.Locale}}" dir="{{.Dir}}">Bienvenue {{.Username}}</html>`,
+		"es/welcome.html": `<html lang="{{.Locale}}" dir="{{.Dir}}">Bienvenido {{.Username}}</html>`,
+		"ar/welcome.html": `<html lang="{{.Locale}}" dir="{{.Dir}}">مرحبا {{.Username}}</html>`,
+		"es-MX/only.html": `hola`,
+	}
+	for name, content := range templates {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		require.NoError(suite.T(), os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(suite.T(), os.WriteFile(path, []byte(content), 0o644))
+	}
+
+	manager, err := email.NewTemplateManager(dir, "fr")
+	require.NoError(suite.T(), err)
+	suite.manager = manager
+}
+
+func (suite *EmailLocaleTestSuite) TestLocaleChain() {
+	assert.Equal(suite.T(), []string{"es-MX", "es", "en"}, email.LocaleChain("es-MX", "en"))
+	assert.Equal(suite.T(), []string{"pt-BR", "pt", "en"}, email.LocaleChain("pt_br", "en"))
+	assert.Equal(suite.T(), []string{"zh-Hant-TW", "zh-Hant", "zh", "en-GB", "en"}, email.LocaleChain("zh-hant-tw", "en-GB"))
+	assert.Equal(suite.T(), []string{"en-US", "en"}, email.LocaleChain("en-US", "en"))
+	assert.Equal(suite.T(), []string{"en"}, email.LocaleChain("", "en"))
+	assert.Equal(suite.T(), []string{"en"}, email.LocaleChain("not a locale", "en"))
+}
+
+func (suite *EmailLocaleTestSuite) TestTextDirection() {
+	for _, locale := range []string{"ar", "ar-EG", "he", "fa-IR", "ur", "az-Arab"} {
+		assert.Equal(suite.T(), email.DirRTL, email.TextDirection(locale), locale)
+	}
+	for _, locale := range []string{"en", "es-MX", "", "az", "uz-Latn"} {
+		assert.Equal(suite.T(), email.DirLTR, email.TextDirection(locale), locale)
+	}
+}
+
+func (suite *EmailLocaleTestSuite) TestResolveFallsBackThroughTheChain() {
+	cases := []struct {
+		locale, key, resolved string
+	}{
+		{"es-MX", "es/welcome.html", "es"},
+		{"es", "es/welcome.html", "es"},
+		{"de-AT", "fr/welcome.html", "fr"},
+		{"", "fr/welcome.html", "fr"},
+	}
+	for _, tc := range cases {
+		key, resolved, err := suite.manager.Resolve("welcome.html", tc.locale)
+		require.NoError(suite.T(), err, tc.locale)
+		assert.Equal(suite.T(), tc.key, key, tc.locale)
+		assert.Equal(suite.T(), tc.resolved, resolved, tc.locale)
+	}
+
+	_, _, err := suite.manager.Resolve("only.html", "es")
+	assert.Error(suite.T(), err, "a translation alone is not a fallback for other locales")
+
+	_, _, err = suite.manager.Resolve("missing.html", "es")
+	assert.Error(suite.T(), err)
+}
+
+func (suite *EmailLocaleTestSuite) TestResolveUsesTopLevelTemplateLast() {
+	manager, err := email.NewTemplateManager(suite.dir, "de")
+	require.NoError(suite.T(), err)
+
+	key, resolved, err := manager.Resolve("welcome.html", "it")
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "welcome.html", key)
+	assert.Equal(suite.T(), "de", resolved)
+}
+
+func (suite *EmailLocaleTestSuite) TestRenderLocalizedPassesDirection() {
+	body, err := suite.manager.RenderLocalized("welcome.html", "ar-SA", map[string]interface{}{"Username": "sam"})
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), `<html lang="ar" dir="rtl">مرحبا sam</html>`, body)
+
+	body, err = suite.manager.RenderLocalized("welcome.html", "es-MX", map[string]interface{}{"Username": "sam"})
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), `<html lang="es" dir="ltr">Bienvenido sam</html>`, body)
+}
+
+func TestEmailLocaleTestSuite(t *testing.T) {
+	suite.Run(t, new(EmailLocaleTestSuite))
+}
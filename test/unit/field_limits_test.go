@@ -0,0 +1,64 @@
+// test/unit/field_limits_test.go
+package unit
+
+import (
+	"context"
+	stderrors "errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/0xsj/mios.io/log"
+	"github.com/0xsj/mios.io/pkg/errors"
+	"github.com/0xsj/mios.io/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type FieldLimitsTestSuite struct {
+	suite.Suite
+	authService service.AuthService
+}
+
+func (suite *FieldLimitsTestSuite) SetupSuite() {
+	logger := log.Development().WithLayer("FieldLimitsTest")
+	suite.authService = service.NewAuthService(
+		nil, nil, nil, nil, nil, nil, nil,
+		"test-secret",
+		time.Hour,
+		service.AuthConfig{FieldLimits: service.FieldLimits{Bio: 10, Name: 5}},
+		logger,
+		"https://api.example.com",
+	)
+}
+
+func (suite *FieldLimitsTestSuite) TestRegisterReportsEveryOversizedField() {
+	_, err := suite.authService.Register(context.Background(), service.RegisterInput{
+		Username:  "tester",
+		Handle:    "tester",
+		Email:     "tester@example.com",
+		Password:  "Str0ng!Passw0rd",
+		FirstName: "Maximilian",
+		LastName:  "Lee",
+		Bio:       strings.Repeat("é", 11),
+	})
+	require.Error(suite.T(), err)
+
+	var appErr *errors.AppError
+	require.True(suite.T(), stderrors.As(err, &appErr))
+	assert.Equal(suite.T(), "VALIDATION_ERROR", appErr.Code)
+
+	fields, ok := appErr.Details.([]errors.FieldError)
+	require.True(suite.T(), ok)
+
+	names := make([]string, len(fields))
+	for i, field := range fields {
+		names[i] = field.Field
+	}
+	assert.ElementsMatch(suite.T(), []string{"first_name", "bio"}, names)
+}
+
+func TestFieldLimitsTestSuite(t *testing.T) {
+	suite.Run(t, new(FieldLimitsTestSuite))
+}
@@ -0,0 +1,96 @@
+// test/unit/file_listing_test.go
+package unit
+
+import (
+	"context"
+	stderrors "errors"
+	"testing"
+	"time"
+
+	db "github.com/0xsj/mios.io/db/sqlc"
+	"github.com/0xsj/mios.io/log"
+	"github.com/0xsj/mios.io/pkg/errors"
+	"github.com/0xsj/mios.io/pkg/storage"
+	"github.com/0xsj/mios.io/service"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type FileListingTestSuite struct {
+	suite.Suite
+	userID uuid.UUID
+	svc    service.FileService
+}
+
+func (suite *FileListingTestSuite) SetupTest() {
+	suite.userID = uuid.New()
+	base := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	file := func(key, category string, age time.Duration, owner uuid.UUID) *db.File {
+		createdAt := base.Add(-age)
+		return &db.File{FileKey: key, UserID: owner, Category: category, Size: 10, ContentType: "image/png", CreatedAt: &createdAt}
+	}
+
+	files := newMemoryFileRepo(
+		file("avatars/old.png", "avatar", 3*time.Hour, suite.userID),
+		file("content/a.png", "content", 2*time.Hour, suite.userID),
+		file("content/b.png", "content", time.Hour, suite.userID),
+		file("general/new.png", "general", 0, suite.userID),
+		file("content/theirs.png", "content", 0, uuid.New()),
+	)
+
+	logger := log.Development().WithLayer("FileListingTest")
+	suite.svc = service.NewFileService(storage.NewLocalStorage(suite.T().TempDir(), avatarBaseURL, logger), nil, files, nil, service.FileServiceConfig{}, logger)
+}
+
+func fileKeys(list *service.FileListDTO) []string {
+	keys := make([]string, len(list.Files))
+	for i, file := range list.Files {
+		keys[i] = file.Key
+	}
+	return keys
+}
+
+func (suite *FileListingTestSuite) TestListsOwnFilesMostRecentFirst() {
+	list, err := suite.svc.ListUserFiles(context.Background(), suite.userID.String(), "", 1, 0)
+	require.NoError(suite.T(), err)
+
+	assert.Equal(suite.T(), []string{"general/new.png", "content/b.png", "content/a.png", "avatars/old.png"}, fileKeys(list))
+	assert.Equal(suite.T(), int64(4), list.TotalCount)
+
+	first := list.Files[0]
+	assert.Equal(suite.T(), avatarBaseURL+"/general/new.png", first.URL)
+	assert.Equal(suite.T(), "general", first.Category)
+	assert.Equal(suite.T(), int64(10), first.Size)
+	assert.Equal(suite.T(), "image/png", first.ContentType)
+	assert.Equal(suite.T(), time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC), first.UploadedAt)
+}
+
+func (suite *FileListingTestSuite) TestFiltersByCategoryAndPages() {
+	ctx := context.Background()
+
+	list, err := suite.svc.ListUserFiles(ctx, suite.userID.String(), "content", 1, 1)
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), []string{"content/b.png"}, fileKeys(list))
+	assert.Equal(suite.T(), 2, list.TotalPages)
+
+	list, err = suite.svc.ListUserFiles(ctx, suite.userID.String(), "content", 2, 1)
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), []string{"content/a.png"}, fileKeys(list))
+
+	list, err = suite.svc.ListUserFiles(ctx, suite.userID.String(), "content", 3, 1)
+	require.NoError(suite.T(), err)
+	assert.Empty(suite.T(), list.Files)
+}
+
+func (suite *FileListingTestSuite) TestRejectsUnknownCategory() {
+	_, err := suite.svc.ListUserFiles(context.Background(), suite.userID.String(), "secrets", 1, 20)
+	var appErr *errors.AppError
+	require.True(suite.T(), stderrors.As(err, &appErr), err)
+	assert.Equal(suite.T(), "VALIDATION_ERROR", appErr.Code)
+}
+
+func TestFileListingTestSuite(t *testing.T) {
+	suite.Run(t, new(FileListingTestSuite))
+}
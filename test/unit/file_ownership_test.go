@@ -0,0 +1,303 @@
+// test/unit/file_ownership_test.go
+package unit
+
+import (
+	"bytes"
+	"context"
+	stderrors "errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	db "github.com/0xsj/mios.io/db/sqlc"
+	"github.com/0xsj/mios.io/log"
+	"github.com/0xsj/mios.io/pkg/errors"
+	"github.com/0xsj/mios.io/pkg/storage"
+	"github.com/0xsj/mios.io/repository"
+	"github.com/0xsj/mios.io/service"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+// memoryFileRepo is safe for concurrent use, as avatar variants are
+// recorded in parallel
+type memoryFileRepo struct {
+	mu    sync.Mutex
+	files map[string]*db.File
+}
+
+func newMemoryFileRepo(files ...*db.File) *memoryFileRepo {
+	repo := &memoryFileRepo{files: make(map[string]*db.File)}
+	for _, file := range files {
+		repo.files[file.FileKey] = file
+	}
+	return repo
+}
+
+func (r *memoryFileRepo) CreateFile(ctx context.Context, params repository.CreateFileParams) (*db.File, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	file := &db.File{
+		FileKey:     params.Key,
+		UserID:      params.UserID,
+		Category:    params.Category,
+		Size:        params.Size,
+		ContentType: params.ContentType,
+		CreatedAt:   &now,
+	}
+	r.files[params.Key] = file
+	return file, nil
+}
+
+func (r *memoryFileRepo) GetFile(ctx context.Context, key string) (*db.File, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	file, ok := r.files[key]
+	if !ok {
+		return nil, errors.NewNotFoundError("file not found", nil)
+	}
+	return file, nil
+}
+
+func (r *memoryFileRepo) DeleteFile(ctx context.Context, key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.files, key)
+	return nil
+}
+
+func (r *memoryFileRepo) UpdateFileSize(ctx context.Context, key string, size int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if file, ok := r.files[key]; ok {
+		file.Size = size
+	}
+	return nil
+}
+
+// userFiles returns a user's files in the order the database lists them
+func (r *memoryFileRepo) userFiles(userID uuid.UUID, category string) []*db.File {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var files []*db.File
+	for _, file := range r.files {
+		if file.UserID == userID && (category == "" || file.Category == category) {
+			files = append(files, file)
+		}
+	}
+	sort.Slice(files, func(i, j int) bool {
+		if !files[i].CreatedAt.Equal(*files[j].CreatedAt) {
+			return files[i].CreatedAt.After(*files[j].CreatedAt)
+		}
+		return files[i].FileKey < files[j].FileKey
+	})
+	return files
+}
+
+func (r *memoryFileRepo) ListUserFiles(ctx context.Context, userID uuid.UUID, category string, limit, offset int) ([]*db.File, error) {
+	files := r.userFiles(userID, category)
+	if offset >= len(files) {
+		return nil, nil
+	}
+	return files[offset:min(offset+limit, len(files))], nil
+}
+
+func (r *memoryFileRepo) CountUserFiles(ctx context.Context, userID uuid.UUID, category string) (int64, error) {
+	return int64(len(r.userFiles(userID, category))), nil
+}
+
+type fileUsersRepo struct {
+	repository.UserRepository
+	users map[uuid.UUID]*db.User
+}
+
+func (r *fileUsersRepo) GetUser(ctx context.Context, userID uuid.UUID) (*db.User, error) {
+	user, ok := r.users[userID]
+	if !ok {
+		return nil, errors.NewNotFoundError("user not found", nil)
+	}
+	return user, nil
+}
+
+type FileOwnershipTestSuite struct {
+	suite.Suite
+	dir      string
+	owner    uuid.UUID
+	stranger uuid.UUID
+	admin    uuid.UUID
+	files    *memoryFileRepo
+	svc      service.FileService
+}
+
+func (suite *FileOwnershipTestSuite) SetupTest() {
+	dir, err := os.MkdirTemp("", "file_ownership_test_*")
+	require.NoError(suite.T(), err)
+	suite.dir = dir
+
+	suite.owner, suite.stranger, suite.admin = uuid.New(), uuid.New(), uuid.New()
+	isAdmin, notAdmin := true, false
+	users := &fileUsersRepo{users: map[uuid.UUID]*db.User{
+		suite.owner:    {UserID: suite.owner, IsAdmin: &notAdmin},
+		suite.stranger: {UserID: suite.stranger, IsAdmin: &notAdmin},
+		suite.admin:    {UserID: suite.admin, IsAdmin: &isAdmin},
+	}}
+
+	logger := log.Development().WithLayer("FileOwnershipTest")
+	suite.files = newMemoryFileRepo()
+	suite.svc = service.NewFileService(storage.NewLocalStorage(dir, avatarBaseURL, logger), nil, suite.files, users, service.FileServiceConfig{
+		AllowedFileTypes: []string{"text/plain"},
+	}, logger)
+}
+
+func (suite *FileOwnershipTestSuite) TearDownTest() {
+	os.RemoveAll(suite.dir)
+}
+
+func (suite *FileOwnershipTestSuite) upload(category string) string {
+	result, err := suite.svc.UploadFile(context.Background(), service.UploadFileInput{
+		File:        bytes.NewReader([]byte("some notes")),
+		Filename:    "notes.txt",
+		ContentType: "text/plain",
+		Category:    category,
+		UserID:      suite.owner.String(),
+	})
+	require.NoError(suite.T(), err)
+	return result.Key
+}
+
+func (suite *FileOwnershipTestSuite) assertCode(err error, code string) {
+	var appErr *errors.AppError
+	require.True(suite.T(), stderrors.As(err, &appErr), err)
+	assert.Equal(suite.T(), code, appErr.Code)
+}
+
+func (suite *FileOwnershipTestSuite) assertStored(key string, stored bool) {
+	_, err := os.Stat(filepath.Join(suite.dir, key))
+	assert.Equal(suite.T(), stored, err == nil, key)
+	_, err = suite.files.GetFile(context.Background(), key)
+	assert.Equal(suite.T(), stored, err == nil, key)
+}
+
+func (suite *FileOwnershipTestSuite) TestUploadRecordsOwner() {
+	key := suite.upload("general")
+
+	file, err := suite.files.GetFile(context.Background(), key)
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), suite.owner, file.UserID)
+	assert.Equal(suite.T(), "general", file.Category)
+	assert.Equal(suite.T(), "text/plain", file.ContentType)
+	assert.Equal(suite.T(), int64(len("some notes")), file.Size)
+}
+
+func (suite *FileOwnershipTestSuite) TestOnlyOwnerOrAdminDeletes() {
+	ctx := context.Background()
+	key := suite.upload("general")
+
+	suite.assertCode(suite.svc.DeleteFile(ctx, suite.stranger.String(), key), "FORBIDDEN")
+	suite.assertStored(key, true)
+
+	require.NoError(suite.T(), suite.svc.DeleteFile(ctx, suite.owner.String(), key))
+	suite.assertStored(key, false)
+
+	key = suite.upload("general")
+	require.NoError(suite.T(), suite.svc.DeleteFile(ctx, suite.admin.String(), key))
+	suite.assertStored(key, false)
+
+	suite.assertCode(suite.svc.DeleteFile(ctx, suite.owner.String(), key), "NOT_FOUND")
+}
+
+func (suite *FileOwnershipTestSuite) TestPrivateFileURLs() {
+	ctx := context.Background()
+	key := suite.upload("general")
+
+	_, err := suite.svc.GetFileURL(ctx, "", key, 0)
+	suite.assertCode(err, "UNAUTHORIZED")
+	_, err = suite.svc.GetFileURL(ctx, suite.stranger.String(), key, 0)
+	suite.assertCode(err, "FORBIDDEN")
+
+	for _, actor := range []uuid.UUID{suite.owner, suite.admin} {
+		url, err := suite.svc.GetFileURL(ctx, actor.String(), key, 0)
+		require.NoError(suite.T(), err)
+		assert.Equal(suite.T(), avatarBaseURL+"/"+key, url)
+	}
+}
+
+func (suite *FileOwnershipTestSuite) TestPublicFileURLs() {
+	key := suite.upload("content")
+
+	url, err := suite.svc.GetFileURL(context.Background(), "", key, 0)
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), avatarBaseURL+"/"+key, url)
+}
+
+// store writes a file straight to storage, as uploads from before ownership
+// was recorded and presigned uploads do
+func (suite *FileOwnershipTestSuite) store(key, content string) {
+	path := filepath.Join(suite.dir, key)
+	require.NoError(suite.T(), os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(suite.T(), os.WriteFile(path, []byte(content), 0644))
+}
+
+func (suite *FileOwnershipTestSuite) TestUnrecordedFilesAreBackfilled() {
+	ctx := context.Background()
+	key := "general/" + suite.owner.String() + "/2024/01/02/legacy.txt"
+	suite.store(key, "legacy notes")
+
+	_, err := suite.svc.GetFileURL(ctx, suite.stranger.String(), key, 0)
+	suite.assertCode(err, "FORBIDDEN")
+
+	url, err := suite.svc.GetFileURL(ctx, suite.owner.String(), key, 0)
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), avatarBaseURL+"/"+key, url)
+
+	file, err := suite.files.GetFile(ctx, key)
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), suite.owner, file.UserID)
+	assert.Equal(suite.T(), "general", file.Category)
+	assert.Equal(suite.T(), int64(len("legacy notes")), file.Size)
+
+	_, err = suite.svc.GetFileURL(ctx, suite.owner.String(), "general/"+suite.owner.String()+"/2024/01/02/missing.txt", 0)
+	suite.assertCode(err, "NOT_FOUND")
+	_, err = suite.svc.GetFileURL(ctx, suite.owner.String(), "elsewhere/legacy.txt", 0)
+	suite.assertCode(err, "NOT_FOUND")
+	_, err = suite.svc.GetFileURL(ctx, suite.owner.String(), "general/"+suite.owner.String()+"/../../general/"+suite.owner.String()+"/2024/01/02/legacy.txt", 0)
+	suite.assertCode(err, "NOT_FOUND")
+}
+
+func (suite *FileOwnershipTestSuite) TestCompleteUploadRecordsSize() {
+	ctx := context.Background()
+	key := "general/" + suite.owner.String() + "/2024/01/02/presigned.txt"
+	_, err := suite.files.CreateFile(ctx, repository.CreateFileParams{
+		Key:         key,
+		UserID:      suite.owner,
+		Category:    "general",
+		ContentType: "text/plain",
+	})
+	require.NoError(suite.T(), err)
+
+	_, err = suite.svc.CompleteUpload(ctx, suite.owner.String(), key)
+	suite.assertCode(err, "NOT_FOUND")
+
+	suite.store(key, "uploaded directly")
+
+	_, err = suite.svc.CompleteUpload(ctx, suite.stranger.String(), key)
+	suite.assertCode(err, "FORBIDDEN")
+
+	file, err := suite.svc.CompleteUpload(ctx, suite.owner.String(), key)
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), int64(len("uploaded directly")), file.Size)
+
+	record, err := suite.files.GetFile(ctx, key)
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), int64(len("uploaded directly")), record.Size)
+}
+
+func TestFileOwnershipTestSuite(t *testing.T) {
+	suite.Run(t, new(FileOwnershipTestSuite))
+}
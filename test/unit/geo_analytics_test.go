@@ -0,0 +1,174 @@
+// test/unit/geo_analytics_test.go
+package unit
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+
+	db "github.com/0xsj/mios.io/db/sqlc"
+	"github.com/0xsj/mios.io/log"
+	"github.com/0xsj/mios.io/pkg/geoip"
+	"github.com/0xsj/mios.io/repository"
+	"github.com/0xsj/mios.io/service"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type ipAnalyticsRepo struct {
+	repository.AnalyticsRepository
+	counts []repository.IPAddressCount
+}
+
+func (r *ipAnalyticsRepo) GetIPAddressCounts(ctx context.Context, params repository.TimeRangeParams) ([]repository.IPAddressCount, error) {
+	return r.counts, nil
+}
+
+// stubGeoLookup resolves addresses from a fixed table
+type stubGeoLookup map[string]geoip.Location
+
+func (s stubGeoLookup) Lookup(ip net.IP) (geoip.Location, bool) {
+	location, ok := s[ip.String()]
+	return location, ok
+}
+
+type GeoAnalyticsTestSuite struct {
+	suite.Suite
+	userID uuid.UUID
+	repo   *ipAnalyticsRepo
+	svc    service.AnalyticsService
+}
+
+func (suite *GeoAnalyticsTestSuite) SetupTest() {
+	suite.userID = uuid.New()
+	suite.repo = &ipAnalyticsRepo{}
+
+	lookup := stubGeoLookup{
+		"81.2.69.142":   {Country: "United Kingdom", City: "London"},
+		"81.2.69.160":   {Country: "United Kingdom", City: "London"},
+		"89.160.20.112": {Country: "Sweden", City: "Linköping"},
+		"2.125.160.216": {Country: "United Kingdom"},
+	}
+
+	users := &exportUserRepo{user: &db.User{UserID: suite.userID, Username: "tester"}}
+	suite.svc = service.NewAnalyticsService(suite.repo, nil, users, nil, nil, lookup, nil,
+		service.AnalyticsExportConfig{},
+		service.AnalyticsConfig{},
+		log.Development().WithLayer("GeoAnalyticsTest"))
+}
+
+func (suite *GeoAnalyticsTestSuite) TestAggregatesCountriesAndCities() {
+	suite.repo.counts = []repository.IPAddressCount{
+		{IPAddress: "81.2.69.142", Count: 4},
+		{IPAddress: "81.2.69.160", Count: 2},
+		{IPAddress: "89.160.20.112", Count: 3},
+		{IPAddress: "2.125.160.216", Count: 1},
+	}
+
+	geo, err := suite.svc.GetGeoAnalytics(context.Background(), suite.userID.String(), service.TimeRangeInput{
+		StartDate: "2025-01-01T00:00:00Z",
+		EndDate:   "2025-02-01T00:00:00Z",
+	})
+	require.NoError(suite.T(), err)
+
+	assert.Equal(suite.T(), int64(10), geo.TotalCount)
+
+	require.Len(suite.T(), geo.Countries, 2)
+	assert.Equal(suite.T(), "United Kingdom", geo.Countries[0].Name)
+	assert.Equal(suite.T(), int64(7), geo.Countries[0].Count)
+	assert.Equal(suite.T(), 70.0, geo.Countries[0].Percentage)
+	assert.Equal(suite.T(), "Sweden", geo.Countries[1].Name)
+
+	// An address located only to a country has no city
+	require.Len(suite.T(), geo.Cities, 3)
+	assert.Equal(suite.T(), "London, United Kingdom", geo.Cities[0].Name)
+	assert.Equal(suite.T(), int64(6), geo.Cities[0].Count)
+	assert.Equal(suite.T(), "Linköping, Sweden", geo.Cities[1].Name)
+	assert.Equal(suite.T(), geoip.Unknown, geo.Cities[2].Name)
+}
+
+func (suite *GeoAnalyticsTestSuite) TestBucketsUnlocatableAddressesAsUnknown() {
+	suite.repo.counts = []repository.IPAddressCount{
+		{IPAddress: "81.2.69.142", Count: 1},
+		{IPAddress: "2001:db8::1", Count: 2},
+		{IPAddress: "192.168.1.10", Count: 3},
+		{IPAddress: "127.0.0.1", Count: 1},
+		{IPAddress: "203.0.113.7", Count: 1},
+		{IPAddress: "", Count: 2},
+	}
+
+	geo, err := suite.svc.GetGeoAnalytics(context.Background(), suite.userID.String(), service.TimeRangeInput{
+		StartDate: "2025-01-01T00:00:00Z",
+		EndDate:   "2025-02-01T00:00:00Z",
+	})
+	require.NoError(suite.T(), err)
+
+	assert.Equal(suite.T(), int64(10), geo.TotalCount)
+	require.Len(suite.T(), geo.Countries, 2)
+	assert.Equal(suite.T(), geoip.Unknown, geo.Countries[0].Name)
+	assert.Equal(suite.T(), int64(9), geo.Countries[0].Count)
+	assert.Equal(suite.T(), 90.0, geo.Countries[0].Percentage)
+}
+
+func (suite *GeoAnalyticsTestSuite) TestLimitKeepsTopEntries() {
+	suite.repo.counts = []repository.IPAddressCount{
+		{IPAddress: "81.2.69.142", Count: 4},
+		{IPAddress: "89.160.20.112", Count: 3},
+		{IPAddress: "10.0.0.1", Count: 1},
+	}
+
+	geo, err := suite.svc.GetGeoAnalytics(context.Background(), suite.userID.String(), service.TimeRangeInput{
+		StartDate: "2025-01-01T00:00:00Z",
+		EndDate:   "2025-02-01T00:00:00Z",
+		Limit:     1,
+	})
+	require.NoError(suite.T(), err)
+
+	require.Len(suite.T(), geo.Countries, 1)
+	assert.Equal(suite.T(), "United Kingdom", geo.Countries[0].Name)
+	assert.Equal(suite.T(), int64(8), geo.TotalCount)
+}
+
+func (suite *GeoAnalyticsTestSuite) TestDatabaseLookup() {
+	csv := `network,country,city
+# test ranges
+81.2.69.0/24,United Kingdom,London
+89.160.20.112/28,Sweden,Linköping
+2001:db8::/32,Nowhere,Nowhere
+2.125.160.0/20,United Kingdom
+`
+	database, err := geoip.Load(strings.NewReader(csv))
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 3, database.Len())
+
+	location, ok := database.Lookup(net.ParseIP("81.2.69.255"))
+	assert.True(suite.T(), ok)
+	assert.Equal(suite.T(), geoip.Location{Country: "United Kingdom", City: "London"}, location)
+
+	location, ok = database.Lookup(net.ParseIP("89.160.20.127"))
+	assert.True(suite.T(), ok)
+	assert.Equal(suite.T(), "Sweden", location.Country)
+
+	location, ok = database.Lookup(net.ParseIP("2.125.175.1"))
+	assert.True(suite.T(), ok)
+	assert.Equal(suite.T(), "", location.City)
+
+	_, ok = database.Lookup(net.ParseIP("89.160.20.128"))
+	assert.False(suite.T(), ok)
+	_, ok = database.Lookup(net.ParseIP("1.1.1.1"))
+	assert.False(suite.T(), ok)
+	_, ok = database.Lookup(net.ParseIP("2001:db8::1"))
+	assert.False(suite.T(), ok)
+}
+
+func (suite *GeoAnalyticsTestSuite) TestDatabaseRejectsMalformedRows() {
+	_, err := geoip.Load(strings.NewReader("not-a-network,Somewhere\n"))
+	assert.Error(suite.T(), err)
+}
+
+func TestGeoAnalyticsTestSuite(t *testing.T) {
+	suite.Run(t, new(GeoAnalyticsTestSuite))
+}
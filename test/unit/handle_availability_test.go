@@ -0,0 +1,68 @@
+// test/unit/handle_availability_test.go
+package unit
+
+import (
+	"context"
+	stderrors "errors"
+	"testing"
+
+	"github.com/0xsj/mios.io/log"
+	"github.com/0xsj/mios.io/pkg/errors"
+	"github.com/0xsj/mios.io/repository"
+	"github.com/0xsj/mios.io/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+// takenHandleRepo answers IsHandleTaken from a fixed set and counts lookups
+type takenHandleRepo struct {
+	repository.UserRepository
+	taken   map[string]bool
+	lookups int
+}
+
+func (r *takenHandleRepo) IsHandleTaken(ctx context.Context, handle string) (bool, error) {
+	r.lookups++
+	return r.taken[handle], nil
+}
+
+type HandleAvailabilityTestSuite struct {
+	suite.Suite
+	repo *takenHandleRepo
+	svc  service.UserService
+}
+
+func (suite *HandleAvailabilityTestSuite) SetupTest() {
+	suite.repo = &takenHandleRepo{taken: map[string]bool{"alice": true}}
+	logger := log.Development().WithLayer("HandleAvailabilityTest")
+	suite.svc = service.NewUserService(suite.repo, nil, nil, nil, nil, nil, nil, nil, service.UserConfig{}, logger)
+}
+
+func (suite *HandleAvailabilityTestSuite) TestReportsTakenAndFreeHandles() {
+	ctx := context.Background()
+
+	available, err := suite.svc.IsHandleAvailable(ctx, "alice")
+	require.NoError(suite.T(), err)
+	assert.False(suite.T(), available)
+
+	available, err = suite.svc.IsHandleAvailable(ctx, "alice-2")
+	require.NoError(suite.T(), err)
+	assert.True(suite.T(), available)
+
+	assert.Equal(suite.T(), 2, suite.repo.lookups)
+}
+
+func (suite *HandleAvailabilityTestSuite) TestRejectsInvalidHandles() {
+	for _, handle := range []string{"a", "has space", "semi;colon"} {
+		_, err := suite.svc.IsHandleAvailable(context.Background(), handle)
+		var appErr *errors.AppError
+		require.True(suite.T(), stderrors.As(err, &appErr), handle)
+		assert.Equal(suite.T(), "VALIDATION_ERROR", appErr.Code, handle)
+	}
+	assert.Zero(suite.T(), suite.repo.lookups)
+}
+
+func TestHandleAvailabilityTestSuite(t *testing.T) {
+	suite.Run(t, new(HandleAvailabilityTestSuite))
+}
@@ -0,0 +1,121 @@
+// test/unit/instrumented_db_test.go
+package unit
+
+import (
+	"context"
+	stderrors "errors"
+	"testing"
+
+	db "github.com/0xsj/mios.io/db/sqlc"
+	"github.com/0xsj/mios.io/repository"
+	"github.com/google/uuid"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+// fakeDBTX answers every statement with err, and queries with rows empty rows
+type fakeDBTX struct {
+	err     error
+	rowsErr error
+}
+
+func (f *fakeDBTX) Exec(context.Context, string, ...interface{}) (pgconn.CommandTag, error) {
+	return pgconn.CommandTag("DELETE 1"), f.err
+}
+
+func (f *fakeDBTX) Query(context.Context, string, ...interface{}) (pgx.Rows, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &fakeRows{err: f.rowsErr}, nil
+}
+
+func (f *fakeDBTX) QueryRow(context.Context, string, ...interface{}) pgx.Row {
+	return fakeRow{err: f.err}
+}
+
+type fakeRows struct {
+	pgx.Rows
+	err error
+}
+
+func (r *fakeRows) Next() bool { return false }
+func (r *fakeRows) Err() error { return r.err }
+func (r *fakeRows) Close()     {}
+
+type fakeRow struct{ err error }
+
+func (r fakeRow) Scan(...interface{}) error { return r.err }
+
+type InstrumentedDBTestSuite struct {
+	suite.Suite
+	base    *fakeDBTX
+	queries *db.Queries
+}
+
+func (suite *InstrumentedDBTestSuite) SetupTest() {
+	suite.base = &fakeDBTX{}
+	suite.queries = db.New(repository.NewInstrumentedDB(suite.base, sharedMetrics()))
+}
+
+func (suite *InstrumentedDBTestSuite) queryCount(operation, table, status string) float64 {
+	return testutil.ToFloat64(sharedMetrics().DBQueriesTotal.WithLabelValues(operation, table, status))
+}
+
+// assertRecorded runs fn and checks it recorded exactly one query
+func (suite *InstrumentedDBTestSuite) assertRecorded(operation, table, status string, fn func()) {
+	before := suite.queryCount(operation, table, status)
+	fn()
+	assert.Equal(suite.T(), before+1, suite.queryCount(operation, table, status), "%s %s %s", operation, table, status)
+}
+
+func (suite *InstrumentedDBTestSuite) TestLabelsQueriesByVerbAndTable() {
+	ctx := context.Background()
+
+	suite.assertRecorded("SELECT", "files", "success", func() {
+		suite.queries.GetFileRecord(ctx, "key")
+	})
+	suite.assertRecorded("DELETE", "files", "success", func() {
+		suite.queries.DeleteFileRecord(ctx, "key")
+	})
+	suite.assertRecorded("SELECT", "files", "success", func() {
+		_, err := suite.queries.ListUserFiles(ctx, db.ListUserFilesParams{UserID: uuid.New(), Limit: 10})
+		require.NoError(suite.T(), err)
+	})
+	suite.assertRecorded("UPDATE", "users", "success", func() {
+		suite.queries.ClaimHandleTransfer(ctx, db.ClaimHandleTransferParams{Handle: "alice", ToUserID: uuid.New()})
+	})
+	suite.assertRecorded("SELECT", "account_collaborators", "success", func() {
+		suite.queries.IsAccountCollaborator(ctx, db.IsAccountCollaboratorParams{})
+	})
+}
+
+func (suite *InstrumentedDBTestSuite) TestRecordsFailures() {
+	ctx := context.Background()
+
+	suite.base.err = pgx.ErrNoRows
+	suite.assertRecorded("SELECT", "files", "success", func() {
+		_, err := suite.queries.GetFileRecord(ctx, "key")
+		assert.ErrorIs(suite.T(), err, pgx.ErrNoRows, "errors are passed through")
+	})
+
+	suite.base.err = stderrors.New("connection reset")
+	suite.assertRecorded("DELETE", "files", "error", func() {
+		suite.queries.DeleteFileRecord(ctx, "key")
+	})
+
+	suite.base.err = nil
+	suite.base.rowsErr = stderrors.New("connection reset")
+	suite.assertRecorded("SELECT", "files", "error", func() {
+		_, err := suite.queries.ListUserFiles(ctx, db.ListUserFilesParams{UserID: uuid.New(), Limit: 10})
+		assert.Error(suite.T(), err)
+	})
+}
+
+func TestInstrumentedDBTestSuite(t *testing.T) {
+	suite.Run(t, new(InstrumentedDBTestSuite))
+}
@@ -0,0 +1,146 @@
+// test/unit/l1_cache_test.go
+package unit
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/0xsj/mios.io/config"
+	"github.com/0xsj/mios.io/log"
+	"github.com/0xsj/mios.io/pkg/cache"
+	"github.com/0xsj/mios.io/pkg/redis"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type L1CacheTestSuite struct {
+	suite.Suite
+	server *miniredis.Miniredis
+	client *redis.Client
+	bus    *cache.InvalidationBus
+	logger log.Logger
+}
+
+func (suite *L1CacheTestSuite) SetupTest() {
+	suite.server = miniredis.RunT(suite.T())
+	host, port, err := net.SplitHostPort(suite.server.Addr())
+	require.NoError(suite.T(), err)
+
+	suite.logger = log.Development().WithLayer("L1CacheTest")
+	suite.client, err = redis.NewClient(config.Config{RedisHost: host, RedisPort: port}, suite.logger)
+	require.NoError(suite.T(), err)
+	suite.T().Cleanup(func() { suite.client.Close() })
+
+	suite.bus = cache.NewInvalidationBus(suite.client, suite.logger, "")
+}
+
+func (suite *L1CacheTestSuite) newCache(maxEntries int, ttl time.Duration) cache.CacheService {
+	redisCache := cache.NewRedisCache(suite.client, suite.logger, "test", suite.bus, nil)
+	return cache.NewL1Cache(redisCache, "test", suite.bus, cache.L1Config{MaxEntries: maxEntries, TTL: ttl}, suite.logger)
+}
+
+// changeInRedis rewrites a value behind the cache's back, so reads that
+// still return the old value were served from memory
+func (suite *L1CacheTestSuite) changeInRedis(key, value string) {
+	require.NoError(suite.T(), suite.server.Set("test:"+key, `"`+value+`"`))
+}
+
+func (suite *L1CacheTestSuite) get(c cache.CacheService, key string) (string, bool) {
+	var value string
+	found, err := c.Get(context.Background(), key, &value)
+	require.NoError(suite.T(), err)
+	return value, found
+}
+
+func (suite *L1CacheTestSuite) set(c cache.CacheService, key, value string) {
+	require.NoError(suite.T(), c.Set(context.Background(), key, value, time.Hour))
+}
+
+func (suite *L1CacheTestSuite) TestServesRepeatedReadsFromMemory() {
+	c := suite.newCache(10, time.Minute)
+	suite.set(c, "metadata:url:1", "cached")
+	suite.changeInRedis("metadata:url:1", "changed")
+
+	value, found := suite.get(c, "metadata:url:1")
+	assert.True(suite.T(), found)
+	assert.Equal(suite.T(), "cached", value)
+
+	var fetched string
+	err := c.GetOrSet(context.Background(), "metadata:url:1", &fetched, time.Hour, func() (interface{}, error) {
+		suite.Fail("fetch should not run on a cached key")
+		return nil, nil
+	})
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "cached", fetched)
+}
+
+func (suite *L1CacheTestSuite) TestEntriesExpireAfterTheL1TTL() {
+	c := suite.newCache(10, 50*time.Millisecond)
+	suite.set(c, "metadata:url:1", "cached")
+	suite.changeInRedis("metadata:url:1", "changed")
+
+	time.Sleep(60 * time.Millisecond)
+	value, _ := suite.get(c, "metadata:url:1")
+	assert.Equal(suite.T(), "changed", value)
+}
+
+func (suite *L1CacheTestSuite) TestEvictsTheLeastRecentlyUsedEntry() {
+	c := suite.newCache(2, time.Minute)
+	suite.set(c, "a", "a1")
+	suite.set(c, "b", "b1")
+	suite.get(c, "a")
+	suite.set(c, "c", "c1")
+
+	suite.changeInRedis("a", "a2")
+	suite.changeInRedis("b", "b2")
+
+	a, _ := suite.get(c, "a")
+	b, _ := suite.get(c, "b")
+	assert.Equal(suite.T(), "a1", a, "recently read entries are kept")
+	assert.Equal(suite.T(), "b2", b, "the least recently used entry is evicted")
+}
+
+func (suite *L1CacheTestSuite) TestDeletesClearBothLayers() {
+	c := suite.newCache(10, time.Minute)
+	suite.set(c, "content:user:1", "items")
+	suite.set(c, "content:user:1:summary", "summary")
+	suite.set(c, "analytics:user:1:days:7", "analytics")
+
+	require.NoError(suite.T(), c.DeletePattern(context.Background(), "content:user:1*"))
+	_, found := suite.get(c, "content:user:1")
+	assert.False(suite.T(), found)
+	_, found = suite.get(c, "content:user:1:summary")
+	assert.False(suite.T(), found)
+	_, found = suite.get(c, "analytics:user:1:days:7")
+	assert.True(suite.T(), found, "entries outside the pattern are kept")
+
+	require.NoError(suite.T(), c.Delete(context.Background(), "analytics:user:1:days:7"))
+	_, found = suite.get(c, "analytics:user:1:days:7")
+	assert.False(suite.T(), found)
+}
+
+func (suite *L1CacheTestSuite) TestDropsEntriesDeletedByOtherInstances() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go suite.bus.Listen(ctx)
+
+	c := suite.newCache(10, time.Minute)
+	suite.set(c, "content:user:1", "items")
+
+	other := cache.NewRedisCache(suite.client, suite.logger, "test", suite.bus, nil)
+	assert.Eventually(suite.T(), func() bool {
+		// Resent until the listener has subscribed
+		require.NoError(suite.T(), other.DeletePattern(context.Background(), "content:user:*"))
+		suite.changeInRedis("content:user:1", "changed")
+		value, _ := suite.get(c, "content:user:1")
+		return value == "changed"
+	}, time.Second, 20*time.Millisecond)
+}
+
+func TestL1CacheTestSuite(t *testing.T) {
+	suite.Run(t, new(L1CacheTestSuite))
+}
@@ -0,0 +1,114 @@
+// test/unit/link_fetch_guard_test.go
+package unit
+
+import (
+	"context"
+	stderrors "errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	db "github.com/0xsj/mios.io/db/sqlc"
+	"github.com/0xsj/mios.io/log"
+	"github.com/0xsj/mios.io/pkg/errors"
+	"github.com/0xsj/mios.io/repository"
+	"github.com/0xsj/mios.io/service"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type LinkFetchGuardTestSuite struct {
+	suite.Suite
+	svc service.LinkMetadataService
+}
+
+func (suite *LinkFetchGuardTestSuite) SetupTest() {
+	// Blocked URLs are refused before anything is fetched or stored
+	var repo repository.LinkMetadataRepository
+	suite.svc = service.NewLinkMetadataService(repo, service.LinkMetadataConfig{},
+		log.Development().WithLayer("LinkFetchGuardTest"))
+}
+
+func (suite *LinkFetchGuardTestSuite) assertRefused(url string) {
+	_, err := suite.svc.FetchAndStoreMetadata(context.Background(), url)
+	require.Error(suite.T(), err, url)
+
+	var appErr *errors.AppError
+	require.True(suite.T(), stderrors.As(err, &appErr), url)
+	assert.Equal(suite.T(), "VALIDATION_ERROR", appErr.Code, url)
+}
+
+func (suite *LinkFetchGuardTestSuite) TestRefusesPrivateAndReservedAddresses() {
+	for _, url := range []string{
+		"http://169.254.169.254/latest/meta-data/",
+		"http://100.100.100.200/latest/meta-data/",
+		"http://[fd00:ec2::254]/latest/meta-data/",
+		"http://127.0.0.1:6379/",
+		"http://localhost:8080/admin",
+		"http://[::1]/",
+		"http://[::ffff:169.254.169.254]/",
+		"http://10.0.0.5/",
+		"http://172.16.3.4/",
+		"http://192.168.1.1/",
+		"http://0.0.0.0/",
+		"http://[fe80::1]/",
+	} {
+		suite.assertRefused(url)
+	}
+}
+
+func (suite *LinkFetchGuardTestSuite) TestRefusesOtherSchemes() {
+	for _, url := range []string{"file:///etc/passwd", "gopher://example.com/", "example.com/no-scheme"} {
+		suite.assertRefused(url)
+	}
+}
+
+// healthCheckRepo serves a fixed batch of links and records each check
+type healthCheckRepo struct {
+	repository.LinkHealthRepository
+	links  []*db.ListLinksForHealthCheckRow
+	failed map[string]bool
+}
+
+func (r *healthCheckRepo) ListLinksForHealthCheck(ctx context.Context, checkedBefore time.Time, limit int) ([]*db.ListLinksForHealthCheckRow, error) {
+	links := r.links
+	r.links = nil
+	return links, nil
+}
+
+func (r *healthCheckRepo) RecordLinkCheck(ctx context.Context, itemID uuid.UUID, failed bool, status *int32) (*db.ContentLinkHealth, error) {
+	r.failed[itemID.String()] = failed
+	return &db.ContentLinkHealth{ItemID: itemID}, nil
+}
+
+func (suite *LinkFetchGuardTestSuite) TestHealthCheckRefusesPrivateHrefs() {
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+	}))
+	defer server.Close()
+
+	repo := &healthCheckRepo{failed: map[string]bool{}}
+	for _, href := range []string{server.URL + "/admin", "http://169.254.169.254/latest/meta-data/", "http://10.0.0.5/"} {
+		repo.links = append(repo.links, &db.ListLinksForHealthCheckRow{ItemID: uuid.New(), TargetUrl: href})
+	}
+	links := repo.links
+
+	svc := service.NewLinkHealthService(repo, nil, nil, service.LinkHealthConfig{CheckInterval: time.Hour},
+		log.Development().WithLayer("LinkFetchGuardTest"), "http://localhost")
+	_, err := svc.CheckLinks(context.Background())
+	require.NoError(suite.T(), err)
+
+	assert.Zero(suite.T(), hits.Load(), "loopback hrefs are never requested")
+	for _, link := range links {
+		assert.True(suite.T(), repo.failed[link.ItemID.String()], link.TargetUrl)
+	}
+}
+
+func TestLinkFetchGuardTestSuite(t *testing.T) {
+	suite.Run(t, new(LinkFetchGuardTestSuite))
+}
@@ -0,0 +1,110 @@
+// test/unit/link_freshness_test.go
+package unit
+
+import (
+	"context"
+	stderrors "errors"
+	"testing"
+	"time"
+
+	"github.com/0xsj/mios.io/log"
+	"github.com/0xsj/mios.io/pkg/errors"
+	"github.com/0xsj/mios.io/repository"
+	"github.com/0xsj/mios.io/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+// freshnessMetadataRepo answers freshness lookups from a fixed set of rows
+type freshnessMetadataRepo struct {
+	repository.LinkMetadataRepository
+	rows    []repository.LinkMetadataFreshness
+	queries [][]string
+}
+
+func (r *freshnessMetadataRepo) GetLinkMetadataFreshness(ctx context.Context, urls []string) ([]repository.LinkMetadataFreshness, error) {
+	r.queries = append(r.queries, urls)
+
+	wanted := make(map[string]bool, len(urls))
+	for _, url := range urls {
+		wanted[url] = true
+	}
+
+	var result []repository.LinkMetadataFreshness
+	for _, row := range r.rows {
+		if wanted[row.URL] {
+			result = append(result, row)
+		}
+	}
+	return result, nil
+}
+
+type LinkFreshnessTestSuite struct {
+	suite.Suite
+	repo *freshnessMetadataRepo
+	svc  service.LinkMetadataService
+}
+
+func (suite *LinkFreshnessTestSuite) SetupTest() {
+	now := time.Now()
+	suite.repo = &freshnessMetadataRepo{rows: []repository.LinkMetadataFreshness{
+		{URL: "https://fresh.example.com", UpdatedAt: now.Add(-time.Hour)},
+		{URL: "https://stale.example.com", UpdatedAt: now.AddDate(0, 0, -30)},
+		{URL: "https://failed.example.com", UpdatedAt: now.Add(-time.Hour), FetchFailed: true},
+	}}
+	suite.svc = service.NewLinkMetadataService(suite.repo, service.LinkMetadataConfig{},
+		log.Development().WithLayer("LinkFreshnessTest"))
+}
+
+func (suite *LinkFreshnessTestSuite) TestReportsStatusPerURL() {
+	urls := []string{
+		"https://fresh.example.com",
+		"https://stale.example.com",
+		"https://failed.example.com",
+		"https://new.example.com",
+	}
+
+	freshness, err := suite.svc.GetFreshnessStatus(context.Background(), urls)
+	require.NoError(suite.T(), err)
+	require.Len(suite.T(), freshness, 4)
+
+	assert.Equal(suite.T(), service.FreshnessFresh, freshness[urls[0]].Status)
+	assert.Equal(suite.T(), service.FreshnessStale, freshness[urls[1]].Status)
+	assert.Equal(suite.T(), service.FreshnessFailed, freshness[urls[2]].Status)
+	assert.Equal(suite.T(), service.FreshnessPending, freshness[urls[3]].Status)
+	assert.NotEmpty(suite.T(), freshness[urls[0]].UpdatedAt)
+	assert.Empty(suite.T(), freshness[urls[3]].UpdatedAt)
+
+	require.Len(suite.T(), suite.repo.queries, 1, "all URLs are checked in one query")
+}
+
+func (suite *LinkFreshnessTestSuite) TestKeysResultsByRequestedURL() {
+	// Query strings are dropped when metadata is stored
+	requested := "fresh.example.com?utm_source=profile"
+
+	freshness, err := suite.svc.GetFreshnessStatus(context.Background(), []string{requested})
+	require.NoError(suite.T(), err)
+
+	assert.Equal(suite.T(), service.FreshnessFresh, freshness[requested].Status)
+	assert.Equal(suite.T(), []string{"https://fresh.example.com"}, suite.repo.queries[0])
+}
+
+func (suite *LinkFreshnessTestSuite) TestRejectsTooManyURLs() {
+	urls := make([]string, 101)
+	for i := range urls {
+		urls[i] = "https://example.com"
+	}
+
+	_, err := suite.svc.GetFreshnessStatus(context.Background(), urls)
+	require.Error(suite.T(), err)
+
+	var appErr *errors.AppError
+	require.True(suite.T(), stderrors.As(err, &appErr))
+	assert.Equal(suite.T(), "VALIDATION_ERROR", appErr.Code)
+	assert.Empty(suite.T(), suite.repo.queries)
+}
+
+func TestLinkFreshnessSuite(t *testing.T) {
+	suite.Run(t, new(LinkFreshnessTestSuite))
+}
@@ -0,0 +1,162 @@
+// test/unit/link_prefetch_test.go
+package unit
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	db "github.com/0xsj/mios.io/db/sqlc"
+	"github.com/0xsj/mios.io/log"
+	"github.com/0xsj/mios.io/repository"
+	"github.com/0xsj/mios.io/service"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+// fetchRecordingMetadataService records the URLs it is asked to fetch.
+// Fetches wait on release when it is set.
+type fetchRecordingMetadataService struct {
+	service.LinkMetadataService
+	mu      sync.Mutex
+	fetched []string
+	release chan struct{}
+	err     error
+}
+
+func (s *fetchRecordingMetadataService) FetchAndStoreMetadata(ctx context.Context, urlString string) (*service.LinkMetadataDTO, error) {
+	if s.release != nil {
+		<-s.release
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.fetched = append(s.fetched, urlString)
+	return &service.LinkMetadataDTO{URL: urlString}, s.err
+}
+
+func (s *fetchRecordingMetadataService) urls() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.fetched...)
+}
+
+// prefetchContentRepo stores created items and applies href/url updates
+type prefetchContentRepo struct {
+	pinContentRepo
+}
+
+func (r *prefetchContentRepo) CreateContentItem(ctx context.Context, params repository.CreateContentItemParams) (*db.ContentItem, error) {
+	item := &db.ContentItem{
+		ItemID:      uuid.New(),
+		UserID:      params.UserID,
+		ContentType: params.ContentType,
+		Href:        params.Href,
+		Url:         params.URL,
+	}
+	r.items[item.ItemID] = item
+	return item, nil
+}
+
+func (r *prefetchContentRepo) UpdateContentItem(ctx context.Context, params repository.UpdateContentItemParams) error {
+	item := r.items[params.ItemID]
+	if params.Href != nil {
+		item.Href = params.Href
+	}
+	if params.URL != nil {
+		item.Url = params.URL
+	}
+	return nil
+}
+
+type LinkPrefetchTestSuite struct {
+	suite.Suite
+	user       *db.User
+	repo       *prefetchContentRepo
+	metadata   *fetchRecordingMetadataService
+	prefetcher *service.LinkPrefetcher
+	svc        service.ContentService
+}
+
+func (suite *LinkPrefetchTestSuite) SetupTest() {
+	suite.user = &db.User{UserID: uuid.New(), Username: "tester"}
+	suite.repo = &prefetchContentRepo{pinContentRepo{items: make(map[uuid.UUID]*db.ContentItem)}}
+	suite.metadata = &fetchRecordingMetadataService{}
+
+	logger := log.Development().WithLayer("LinkPrefetchTest")
+	suite.prefetcher = service.NewLinkPrefetcher(suite.metadata, 2, logger)
+	suite.svc = service.NewContentService(suite.repo, &exportUserRepo{user: suite.user}, nil, nil, &discardHistoryRepo{},
+		nil, suite.prefetcher, service.ContentConfig{}, logger)
+}
+
+func (suite *LinkPrefetchTestSuite) create(href string) *service.ContentItemDTO {
+	item, err := suite.svc.CreateContentItem(context.Background(), service.CreateContentItemInput{
+		UserID:      suite.user.UserID.String(),
+		ContentID:   "link",
+		ContentType: "link",
+		Href:        &href,
+	})
+	require.NoError(suite.T(), err)
+	return item
+}
+
+func (suite *LinkPrefetchTestSuite) TestCreatingALinkWarmsItsMetadata() {
+	suite.create("https://example.com/post?ref=profile")
+	suite.prefetcher.Wait()
+
+	assert.Equal(suite.T(), []string{"https://example.com/post"}, suite.metadata.urls())
+}
+
+func (suite *LinkPrefetchTestSuite) TestUpdateOnlyPrefetchesChangedLinks() {
+	item := suite.create("https://example.com")
+	suite.prefetcher.Wait()
+
+	title := "Renamed"
+	_, err := suite.svc.UpdateContentItem(context.Background(), item.ID, service.UpdateContentItemInput{Title: &title})
+	require.NoError(suite.T(), err)
+
+	href := "https://example.org/new"
+	_, err = suite.svc.UpdateContentItem(context.Background(), item.ID, service.UpdateContentItemInput{Href: &href})
+	require.NoError(suite.T(), err)
+	suite.prefetcher.Wait()
+
+	assert.Equal(suite.T(), []string{"https://example.com", "https://example.org/new"}, suite.metadata.urls())
+}
+
+func (suite *LinkPrefetchTestSuite) TestSkipsNonWebLinks() {
+	suite.create("mailto:hello@example.com")
+	suite.prefetcher.Wait()
+
+	assert.Empty(suite.T(), suite.metadata.urls())
+}
+
+func (suite *LinkPrefetchTestSuite) TestBusySlotsSkipInsteadOfQueueing() {
+	suite.metadata.release = make(chan struct{})
+	for i := 0; i < 4; i++ {
+		suite.prefetcher.Prefetch(fmt.Sprintf("https://example.com/%d", i))
+	}
+	close(suite.metadata.release)
+	suite.prefetcher.Wait()
+
+	assert.Len(suite.T(), suite.metadata.urls(), 2)
+}
+
+func (suite *LinkPrefetchTestSuite) TestRepeatedFailuresPausePrefetching() {
+	suite.metadata.err = fmt.Errorf("store unavailable")
+	for i := 0; i < 5; i++ {
+		suite.prefetcher.Prefetch(fmt.Sprintf("https://example.com/%d", i))
+		suite.prefetcher.Wait()
+	}
+
+	suite.metadata.err = nil
+	suite.prefetcher.Prefetch("https://example.com/after")
+	suite.prefetcher.Wait()
+
+	assert.Len(suite.T(), suite.metadata.urls(), 5)
+}
+
+func TestLinkPrefetchTestSuite(t *testing.T) {
+	suite.Run(t, new(LinkPrefetchTestSuite))
+}
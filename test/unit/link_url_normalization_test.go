@@ -0,0 +1,90 @@
+// test/unit/link_url_normalization_test.go
+package unit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	db "github.com/0xsj/mios.io/db/sqlc"
+	"github.com/0xsj/mios.io/log"
+	"github.com/0xsj/mios.io/pkg/errors"
+	"github.com/0xsj/mios.io/repository"
+	"github.com/0xsj/mios.io/service"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+// urlKeyedMetadataRepo serves stored metadata by URL and records lookups
+type urlKeyedMetadataRepo struct {
+	repository.LinkMetadataRepository
+	rows    map[string]*db.LinkMetadatum
+	lookups []string
+}
+
+func (r *urlKeyedMetadataRepo) GetLinkMetadataByURL(ctx context.Context, url string) (*db.LinkMetadatum, error) {
+	r.lookups = append(r.lookups, url)
+	row, ok := r.rows[url]
+	if !ok {
+		return nil, errors.NewNotFoundError("link metadata not found", nil)
+	}
+	return row, nil
+}
+
+func (r *urlKeyedMetadataRepo) store(url, title string) {
+	now := time.Now()
+	r.rows[url] = &db.LinkMetadatum{MetadataID: uuid.New(), Url: url, Title: &title, UpdatedAt: &now}
+}
+
+type LinkURLNormalizationTestSuite struct {
+	suite.Suite
+	repo *urlKeyedMetadataRepo
+	svc  service.LinkMetadataService
+}
+
+func (suite *LinkURLNormalizationTestSuite) SetupTest() {
+	suite.repo = &urlKeyedMetadataRepo{rows: make(map[string]*db.LinkMetadatum)}
+	suite.svc = service.NewLinkMetadataService(suite.repo, service.LinkMetadataConfig{},
+		log.Development().WithLayer("LinkURLNormalizationTest"))
+}
+
+func (suite *LinkURLNormalizationTestSuite) title(url string) string {
+	metadata, err := suite.svc.GetMetadata(context.Background(), url)
+	require.NoError(suite.T(), err, url)
+	return metadata.Title
+}
+
+func (suite *LinkURLNormalizationTestSuite) TestYouTubeVideosStayDistinct() {
+	suite.repo.store("https://www.youtube.com/watch?v=first", "First video")
+	suite.repo.store("https://www.youtube.com/watch?v=second", "Second video")
+
+	assert.Equal(suite.T(), "First video", suite.title("https://www.youtube.com/watch?v=first"))
+	assert.Equal(suite.T(), "Second video", suite.title("https://www.youtube.com/watch?v=second"))
+	assert.Equal(suite.T(), "First video",
+		suite.title("https://www.youtube.com/watch?feature=share&v=first&utm_source=newsletter#comments"),
+		"parameters other than the platform's query keys are dropped")
+}
+
+func (suite *LinkURLNormalizationTestSuite) TestSpotifyTracksStayDistinct() {
+	suite.repo.store("https://open.spotify.com/track/4uLU6hMCjMI75M1A2tKUQC?si=abc", "First track")
+	suite.repo.store("https://open.spotify.com/track/7GhIk7Il098yCjg4BQjzvb?si=abc", "Second track")
+
+	assert.Equal(suite.T(), "First track",
+		suite.title("https://open.spotify.com/track/4uLU6hMCjMI75M1A2tKUQC?si=abc&utm_medium=social"))
+	assert.Equal(suite.T(), "Second track",
+		suite.title("https://open.spotify.com/track/7GhIk7Il098yCjg4BQjzvb?si=abc&fbclid=xyz"))
+}
+
+func (suite *LinkURLNormalizationTestSuite) TestUnknownSitesKeepNonTrackingParameters() {
+	suite.repo.store("https://shop.example.com/item?color=red&id=7", "Red item")
+
+	assert.Equal(suite.T(), "Red item",
+		suite.title("shop.example.com/item?id=7&utm_campaign=spring&color=red&gclid=abc&ref=bio"))
+	assert.Equal(suite.T(), "https://shop.example.com/item?color=red&id=7", suite.repo.lookups[0])
+}
+
+func TestLinkURLNormalizationTestSuite(t *testing.T) {
+	suite.Run(t, new(LinkURLNormalizationTestSuite))
+}
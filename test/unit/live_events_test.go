@@ -0,0 +1,183 @@
+// test/unit/live_events_test.go
+package unit
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/0xsj/mios.io/api/analytics"
+	db "github.com/0xsj/mios.io/db/sqlc"
+	"github.com/0xsj/mios.io/log"
+	"github.com/0xsj/mios.io/pkg/live"
+	"github.com/0xsj/mios.io/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type LiveEventsTestSuite struct {
+	suite.Suite
+	user   *db.User
+	itemID uuid.UUID
+	broker *live.MemoryBroker
+	svc    service.AnalyticsService
+	logger log.Logger
+}
+
+func (suite *LiveEventsTestSuite) SetupTest() {
+	suite.user = &db.User{UserID: uuid.New(), Username: "tester", AnalyticsEnabled: true}
+	suite.itemID = uuid.New()
+	suite.broker = live.NewMemoryBroker()
+	suite.logger = log.Development().WithLayer("LiveEventsTest")
+
+	content := &pinContentRepo{items: map[uuid.UUID]*db.ContentItem{
+		suite.itemID: {ItemID: suite.itemID, UserID: suite.user.UserID},
+	}}
+	suite.svc = service.NewAnalyticsService(&recordingAnalyticsRepo{}, content, &exportUserRepo{user: suite.user}, nil, nil, nil, suite.broker,
+		service.AnalyticsExportConfig{},
+		service.AnalyticsConfig{},
+		suite.logger)
+}
+
+func (suite *LiveEventsTestSuite) nextEvent(sub live.Subscription) service.LiveEventDTO {
+	select {
+	case payload := <-sub.Messages():
+		var event service.LiveEventDTO
+		require.NoError(suite.T(), json.Unmarshal(payload, &event))
+		return event
+	case <-time.After(time.Second):
+		suite.T().Fatal("no live event received")
+		return service.LiveEventDTO{}
+	}
+}
+
+func (suite *LiveEventsTestSuite) TestRecordingPublishesToOwner() {
+	ctx := context.Background()
+	sub, err := suite.svc.SubscribeLiveEvents(ctx, suite.user.UserID.String())
+	require.NoError(suite.T(), err)
+	defer sub.Close()
+
+	require.NoError(suite.T(), suite.svc.RecordClick(ctx, service.RecordClickInput{
+		ItemID:    suite.itemID.String(),
+		UserID:    suite.user.UserID.String(),
+		UserAgent: uaGooglebot,
+		Referrer:  "https://example.com",
+	}))
+	event := suite.nextEvent(sub)
+	assert.Equal(suite.T(), service.LiveEventInteraction, event.Type)
+	assert.Equal(suite.T(), service.InteractionClick, event.InteractionType)
+	assert.Equal(suite.T(), suite.itemID.String(), event.ItemID)
+	assert.Equal(suite.T(), "https://example.com", event.Referrer)
+	assert.True(suite.T(), event.IsBot)
+	assert.NotEmpty(suite.T(), event.OccurredAt)
+
+	require.NoError(suite.T(), suite.svc.RecordPageView(ctx, service.RecordPageViewInput{
+		ProfileID: suite.itemID.String(),
+		UserID:    suite.user.UserID.String(),
+	}))
+	event = suite.nextEvent(sub)
+	assert.Equal(suite.T(), service.LiveEventPageView, event.Type)
+	assert.False(suite.T(), event.IsBot)
+}
+
+func (suite *LiveEventsTestSuite) TestDisabledAnalyticsPublishNothing() {
+	suite.user.AnalyticsEnabled = false
+	ctx := context.Background()
+	sub, err := suite.svc.SubscribeLiveEvents(ctx, suite.user.UserID.String())
+	require.NoError(suite.T(), err)
+	defer sub.Close()
+
+	require.NoError(suite.T(), suite.svc.RecordClick(ctx, service.RecordClickInput{
+		ItemID: suite.itemID.String(),
+		UserID: suite.user.UserID.String(),
+	}))
+
+	select {
+	case <-sub.Messages():
+		suite.T().Fatal("dropped events must not be streamed")
+	default:
+	}
+}
+
+func (suite *LiveEventsTestSuite) TestSubscribeWithoutBroker() {
+	svc := service.NewAnalyticsService(&recordingAnalyticsRepo{}, nil, &exportUserRepo{user: suite.user}, nil, nil, nil, nil,
+		service.AnalyticsExportConfig{},
+		service.AnalyticsConfig{},
+		suite.logger)
+
+	_, err := svc.SubscribeLiveEvents(context.Background(), suite.user.UserID.String())
+	require.Error(suite.T(), err)
+}
+
+func (suite *LiveEventsTestSuite) newServer(authUserID string) *httptest.Server {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("user_id", authUserID)
+		c.Next()
+	})
+	handler := analytics.NewHandler(suite.svc, suite.logger)
+	router.GET("/api/analytics/users/:id/live", handler.StreamLiveEvents)
+	return httptest.NewServer(router)
+}
+
+func (suite *LiveEventsTestSuite) TestStreamRelaysEventsAndCleansUp() {
+	userID := suite.user.UserID.String()
+	server := suite.newServer(userID)
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/api/analytics/users/"+userID+"/live", nil)
+	require.NoError(suite.T(), err)
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(suite.T(), err)
+	defer resp.Body.Close()
+	assert.Equal(suite.T(), http.StatusOK, resp.StatusCode)
+	assert.Equal(suite.T(), "text/event-stream", resp.Header.Get("Content-Type"))
+	assert.Equal(suite.T(), 1, suite.broker.Subscribers(userID))
+
+	require.NoError(suite.T(), suite.svc.RecordClick(context.Background(), service.RecordClickInput{
+		ItemID: suite.itemID.String(),
+		UserID: userID,
+	}))
+
+	reader := bufio.NewReader(resp.Body)
+	line, err := reader.ReadString('\n')
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "event: analytics\n", line)
+	line, err = reader.ReadString('\n')
+	require.NoError(suite.T(), err)
+	assert.True(suite.T(), strings.HasPrefix(line, "data: {"))
+	assert.Contains(suite.T(), line, suite.itemID.String())
+
+	cancel()
+	assert.Eventually(suite.T(), func() bool {
+		return suite.broker.Subscribers(userID) == 0
+	}, time.Second, 10*time.Millisecond, "subscription must be closed when the client disconnects")
+}
+
+func (suite *LiveEventsTestSuite) TestStreamRejectsOtherUsers() {
+	server := suite.newServer(uuid.NewString())
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/api/analytics/users/" + suite.user.UserID.String() + "/live")
+	require.NoError(suite.T(), err)
+	defer resp.Body.Close()
+
+	assert.Equal(suite.T(), http.StatusForbidden, resp.StatusCode)
+	assert.Equal(suite.T(), 0, suite.broker.Subscribers(suite.user.UserID.String()))
+}
+
+func TestLiveEventsTestSuite(t *testing.T) {
+	suite.Run(t, new(LiveEventsTestSuite))
+}
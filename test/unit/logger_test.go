@@ -0,0 +1,103 @@
+// test/unit/logger_test.go
+package unit
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/0xsj/mios.io/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type LoggerTestSuite struct {
+	suite.Suite
+	output *bytes.Buffer
+	config log.Config
+}
+
+func (suite *LoggerTestSuite) SetupTest() {
+	suite.output = &bytes.Buffer{}
+	suite.config = log.DevelopmentConfig()
+	suite.config.Writer = suite.output
+	suite.config.DisableColors = true
+}
+
+func (suite *LoggerTestSuite) TestParseLevel() {
+	levels := map[string]log.LogLevel{
+		"debug":   log.DebugLevel,
+		"INFO":    log.InfoLevel,
+		" warn ":  log.WarnLevel,
+		"warning": log.WarnLevel,
+		"error":   log.ErrorLevel,
+	}
+	for name, want := range levels {
+		level, err := log.ParseLevel(name)
+		require.NoError(suite.T(), err, name)
+		assert.Equal(suite.T(), want, level, name)
+	}
+
+	_, err := log.ParseLevel("verbose")
+	assert.Error(suite.T(), err)
+}
+
+func (suite *LoggerTestSuite) TestParseFormat() {
+	format, err := log.ParseFormat("json")
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), log.JSONFormat, format)
+
+	format, err = log.ParseFormat("console")
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), log.TextFormat, format)
+
+	_, err = log.ParseFormat("xml")
+	assert.Error(suite.T(), err)
+}
+
+func (suite *LoggerTestSuite) TestJSONFormatInDevelopment() {
+	suite.config.Format = log.JSONFormat
+	logger := log.New(suite.config).WithLayer("Test")
+
+	logger.Info("structured")
+
+	var entry map[string]any
+	require.NoError(suite.T(), json.Unmarshal(suite.output.Bytes(), &entry))
+	assert.Equal(suite.T(), "INFO", entry["level"])
+	assert.Equal(suite.T(), "structured", entry["message"])
+	assert.Equal(suite.T(), "development", entry["environment"])
+	assert.Equal(suite.T(), "Test", entry["component"])
+}
+
+func (suite *LoggerTestSuite) TestSetLevelAppliesToDerivedLoggers() {
+	suite.config.Level = log.InfoLevel
+	root := log.New(suite.config)
+	derived := root.WithLayer("Service").With("handler", "Test")
+
+	derived.Debug("hidden")
+	assert.Empty(suite.T(), suite.output.String())
+
+	root.SetLevel(log.DebugLevel)
+	derived.Debug("shown")
+	assert.Contains(suite.T(), suite.output.String(), "shown")
+	assert.Equal(suite.T(), log.DebugLevel, derived.Level())
+
+	suite.output.Reset()
+	derived.SetLevel(log.ErrorLevel)
+	root.Warn("suppressed")
+	assert.Empty(suite.T(), suite.output.String())
+	assert.Equal(suite.T(), log.ErrorLevel, root.Level())
+}
+
+func (suite *LoggerTestSuite) TestLevelString() {
+	for _, name := range []string{"debug", "info", "warn", "error", "fatal", "panic"} {
+		level, err := log.ParseLevel(name)
+		require.NoError(suite.T(), err)
+		assert.Equal(suite.T(), name, level.String())
+	}
+}
+
+func TestLoggerSuite(t *testing.T) {
+	suite.Run(t, new(LoggerTestSuite))
+}
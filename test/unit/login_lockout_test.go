@@ -0,0 +1,107 @@
+// test/unit/login_lockout_test.go
+package unit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/0xsj/mios.io/log"
+	"github.com/0xsj/mios.io/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+const lockoutThreshold = 3
+
+type LoginLockoutTestSuite struct {
+	suite.Suite
+	ctx  context.Context
+	auth *sessionAuthRepo
+	sink *smtpSink
+	svc  service.AuthService
+}
+
+func (suite *LoginLockoutTestSuite) SetupTest() {
+	suite.ctx = context.Background()
+	users, auth, sessions := newSessionFakes(suite.T())
+	suite.auth = auth
+
+	emailClient, sink := newSessionEmail(suite.T())
+	suite.sink = sink
+
+	suite.svc = service.NewAuthService(users, auth, nil, nil, sessions, emailClient, nil,
+		"login-lockout-test-secret", 0, service.AuthConfig{MaxFailedLoginAttempts: lockoutThreshold, LockoutDuration: time.Hour},
+		log.Development().WithLayer("LoginLockoutTest"), "http://localhost")
+}
+
+func (suite *LoginLockoutTestSuite) login(password string) error {
+	_, err := suite.svc.Login(suite.ctx, service.LoginInput{Email: "jane@example.com", Password: password})
+	return err
+}
+
+// lockedEmails counts the lock notifications sent so far
+func (suite *LoginLockoutTestSuite) lockedEmails() int {
+	count := 0
+	for _, subject := range suite.sink.sent() {
+		if subject == "Your Account Has Been Temporarily Locked" {
+			count++
+		}
+	}
+	return count
+}
+
+// expireLock moves the lock into the past, as if its duration had passed
+func (suite *LoginLockoutTestSuite) expireLock() {
+	lapsed := time.Now().Add(-time.Minute)
+	suite.auth.auth.LockedUntil = &lapsed
+}
+
+func (suite *LoginLockoutTestSuite) TestLocksOnTheNthFailure() {
+	for i := 1; i < lockoutThreshold; i++ {
+		assert.Error(suite.T(), suite.login("wrong"))
+		assert.Empty(suite.T(), suite.auth.lockouts, "failure %d stays under the threshold", i)
+	}
+
+	assert.Error(suite.T(), suite.login("wrong"))
+	require.Len(suite.T(), suite.auth.lockouts, 1, "the threshold failure locks the account")
+	assert.Equal(suite.T(), 1, suite.lockedEmails())
+
+	// Further attempts are refused outright and neither relock nor email
+	assert.Error(suite.T(), suite.login("wrong"))
+	assert.Len(suite.T(), suite.auth.lockouts, 1)
+	assert.Equal(suite.T(), 1, suite.lockedEmails())
+}
+
+func (suite *LoginLockoutTestSuite) TestLapsedLockStartsCountingAfresh() {
+	for i := 0; i < lockoutThreshold; i++ {
+		assert.Error(suite.T(), suite.login("wrong"))
+	}
+	require.Len(suite.T(), suite.auth.lockouts, 1)
+	suite.expireLock()
+
+	assert.Error(suite.T(), suite.login("wrong"))
+	assert.Len(suite.T(), suite.auth.lockouts, 1, "one wrong password after the lock lapses does not relock")
+	assert.Equal(suite.T(), 1, suite.lockedEmails())
+
+	for i := 1; i < lockoutThreshold; i++ {
+		assert.Error(suite.T(), suite.login("wrong"))
+	}
+	assert.Len(suite.T(), suite.auth.lockouts, 2, "a full run of failures locks it again")
+	assert.Equal(suite.T(), 2, suite.lockedEmails())
+}
+
+func (suite *LoginLockoutTestSuite) TestLapsedLockAllowsTheRightPassword() {
+	for i := 0; i < lockoutThreshold; i++ {
+		assert.Error(suite.T(), suite.login("wrong"))
+	}
+	assert.Error(suite.T(), suite.login(sessionTestPassword), "the right password is refused while locked")
+
+	suite.expireLock()
+	assert.NoError(suite.T(), suite.login(sessionTestPassword))
+}
+
+func TestLoginLockoutTestSuite(t *testing.T) {
+	suite.Run(t, new(LoginLockoutTestSuite))
+}
@@ -0,0 +1,129 @@
+// test/unit/metrics_test.go
+package unit
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	api "github.com/0xsj/mios.io/api/server"
+	"github.com/0xsj/mios.io/config"
+	"github.com/0xsj/mios.io/log"
+	"github.com/0xsj/mios.io/pkg/errors"
+	"github.com/0xsj/mios.io/pkg/metrics"
+	"github.com/0xsj/mios.io/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+// NewMetrics registers with the default registry, so it can only run once
+// per test binary
+var (
+	testMetricsOnce sync.Once
+	testMetrics     *metrics.Metrics
+)
+
+func sharedMetrics() *metrics.Metrics {
+	testMetricsOnce.Do(func() { testMetrics = metrics.NewMetrics() })
+	return testMetrics
+}
+
+// typeCheckingAnalyticsService rejects interaction types the way the real
+// service does
+type typeCheckingAnalyticsService struct {
+	service.AnalyticsService
+}
+
+func (s *typeCheckingAnalyticsService) RecordInteraction(ctx context.Context, input service.RecordClickInput) error {
+	if input.InteractionType != "" && input.InteractionType != service.InteractionShare {
+		return errors.NewValidationError("Invalid interaction type", nil)
+	}
+	return nil
+}
+
+type MetricsTestSuite struct {
+	suite.Suite
+	server *httptest.Server
+}
+
+func (suite *MetricsTestSuite) SetupTest() {
+	gin.SetMode(gin.TestMode)
+	server, err := api.NewServer(config.Config{}, nil, log.Development().WithLayer("MetricsTest"), nil, nil, sharedMetrics())
+	require.NoError(suite.T(), err)
+
+	server.Router().GET("/api/users/:id", func(c *gin.Context) {
+		c.String(http.StatusOK, "user")
+	})
+	suite.server = httptest.NewServer(server.Router())
+}
+
+func (suite *MetricsTestSuite) TearDownTest() {
+	suite.server.Close()
+}
+
+func (suite *MetricsTestSuite) get(path string) (int, string) {
+	resp, err := http.Get(suite.server.URL + path)
+	require.NoError(suite.T(), err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(suite.T(), err)
+	return resp.StatusCode, string(body)
+}
+
+func (suite *MetricsTestSuite) TestRequestsAreRecordedByRouteTemplate() {
+	suite.get("/api/users/6f1c2a8e-3b5d-4c7e-9f0a-1b2c3d4e5f60")
+	suite.get("/api/users/42")
+	suite.get("/no/such/route/7")
+
+	status, body := suite.get("/metrics")
+	require.Equal(suite.T(), http.StatusOK, status)
+
+	assert.Contains(suite.T(), body, `http_requests_total{endpoint="/api/users/:id",method="GET",status_class="2xx",status_code="200"}`)
+	assert.Contains(suite.T(), body, `http_requests_total{endpoint="unknown",method="GET",status_class="4xx",status_code="404"}`)
+	assert.NotContains(suite.T(), body, "6f1c2a8e", "concrete IDs never become labels")
+	assert.NotContains(suite.T(), body, "/no/such/route", "unmatched paths never become labels")
+}
+
+func (suite *MetricsTestSuite) TestInteractionTypesAreBoundedLabels() {
+	analytics := service.NewInstrumentedAnalyticsService(&typeCheckingAnalyticsService{}, sharedMetrics(),
+		log.Development().WithLayer("MetricsTest"))
+
+	assert.NoError(suite.T(), analytics.RecordInteraction(context.Background(), service.RecordClickInput{InteractionType: "share"}))
+	assert.Error(suite.T(), analytics.RecordInteraction(context.Background(), service.RecordClickInput{InteractionType: "made-up-type-1234"}))
+
+	_, body := suite.get("/metrics")
+	assert.Contains(suite.T(), body, `analytics_events_total{event_type="share"}`)
+	assert.Contains(suite.T(), body, `analytics_events_total{event_type="invalid"}`)
+	assert.NotContains(suite.T(), body, "made-up-type-1234", "unknown types never become labels")
+}
+
+func (suite *MetricsTestSuite) TestMetricsRouteIsOnlyServedWithMetrics() {
+	server, err := api.NewServer(config.Config{}, nil, log.Development().WithLayer("MetricsTest"), nil, nil, nil)
+	require.NoError(suite.T(), err)
+
+	recorder := httptest.NewRecorder()
+	server.Router().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(suite.T(), http.StatusNotFound, recorder.Code)
+}
+
+func TestMetricsTestSuite(t *testing.T) {
+	suite.Run(t, new(MetricsTestSuite))
+}
+
+func TestNormalizeEndpoint(t *testing.T) {
+	cases := map[string]string{
+		"/api/users/:id": "/api/users/:id",
+		"/api/users/6F1C2A8E-3B5D-4C7E-9F0A-1B2C3D4E5F60/content": "/api/users/:id/content",
+		"/api/analytics/items/123/clicks":                         "/api/analytics/items/:id/clicks",
+		"/api/profiles/alice/meta":                                "/api/profiles/alice/meta",
+		"":                                                        "",
+	}
+	for path, want := range cases {
+		assert.Equal(t, want, metrics.NormalizeEndpoint(path), path)
+	}
+}
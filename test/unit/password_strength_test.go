@@ -0,0 +1,113 @@
+// test/unit/password_strength_test.go
+package unit
+
+import (
+	"context"
+	stderrors "errors"
+	"testing"
+	"time"
+
+	"github.com/0xsj/mios.io/log"
+	"github.com/0xsj/mios.io/pkg/errors"
+	"github.com/0xsj/mios.io/pkg/password"
+	"github.com/0xsj/mios.io/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type PasswordStrengthTestSuite struct {
+	suite.Suite
+	authService service.AuthService
+}
+
+func (suite *PasswordStrengthTestSuite) SetupSuite() {
+	logger := log.Development().WithLayer("PasswordStrengthTest")
+	suite.authService = service.NewAuthService(
+		nil, nil, nil, nil, nil, nil, nil,
+		"test-secret",
+		time.Hour,
+		service.AuthConfig{},
+		logger,
+		"https://api.example.com",
+	)
+}
+
+func (suite *PasswordStrengthTestSuite) TestRejectsRuleCompliantButGuessablePasswords() {
+	cases := map[string]error{
+		"Password1!":   password.ErrPasswordTooCommon,
+		"P@ssw0rd123!": password.ErrPasswordTooCommon,
+		"Abcdefgh1!":   password.ErrPasswordSequential,
+		"Asdfghjk1!":   password.ErrPasswordKeyboardPattern,
+		"Aaaaaaaaa1!":  password.ErrPasswordRepeated,
+	}
+
+	for candidate, reason := range cases {
+		err := password.ValidatePassword(candidate, password.DefaultPasswordConfig())
+		assert.ErrorIs(suite.T(), err, reason, candidate)
+	}
+}
+
+func (suite *PasswordStrengthTestSuite) TestUserInputsCountAsGuessable() {
+	candidate := "Johnsmith!9"
+
+	assert.NoError(suite.T(), password.ValidatePassword(candidate, password.DefaultPasswordConfig()))
+	assert.ErrorIs(suite.T(),
+		password.ValidatePassword(candidate, password.DefaultPasswordConfig(), "john.smith@example.com", "johnsmith"),
+		password.ErrPasswordPersonalInfo)
+}
+
+func (suite *PasswordStrengthTestSuite) TestScoresRiseWithUnpredictability() {
+	weak := password.EstimateStrength("password")
+	strong := password.EstimateStrength("kT9#mQ2$xLv!")
+
+	assert.Equal(suite.T(), 0, weak.Score)
+	assert.Equal(suite.T(), password.MaxScore, strong.Score)
+	assert.Nil(suite.T(), strong.Reason)
+	assert.Greater(suite.T(), strong.Guesses, weak.Guesses)
+}
+
+func (suite *PasswordStrengthTestSuite) TestMinScoreZeroSkipsEstimate() {
+	config := password.DefaultPasswordConfig()
+	config.MinScore = 0
+
+	assert.NoError(suite.T(), password.ValidatePassword("Password1!", config))
+}
+
+func (suite *PasswordStrengthTestSuite) TestRegisterReportsReason() {
+	_, err := suite.authService.Register(context.Background(), service.RegisterInput{
+		Username: "tester",
+		Handle:   "tester",
+		Email:    "tester@example.com",
+		Password: "Password1!",
+	})
+	require.Error(suite.T(), err)
+	assert.ErrorIs(suite.T(), err, password.ErrPasswordTooCommon)
+
+	var appErr *errors.AppError
+	require.True(suite.T(), stderrors.As(err, &appErr))
+	assert.Equal(suite.T(), "VALIDATION_ERROR", appErr.Code)
+
+	fields, ok := appErr.Details.([]errors.FieldError)
+	require.True(suite.T(), ok)
+	require.Len(suite.T(), fields, 1)
+	assert.Equal(suite.T(), "password", fields[0].Field)
+	assert.Equal(suite.T(), password.ErrPasswordTooCommon.Error(), fields[0].Message)
+}
+
+func (suite *PasswordStrengthTestSuite) TestCheckPasswordStrength() {
+	weak := suite.authService.CheckPasswordStrength(service.PasswordStrengthInput{Password: "Password1!"})
+	assert.False(suite.T(), weak.Acceptable)
+	assert.Equal(suite.T(), password.DefaultMinScore, weak.MinScore)
+	assert.Less(suite.T(), weak.Score, weak.MinScore)
+	assert.Equal(suite.T(), password.ErrPasswordTooCommon.Error(), weak.Reason)
+
+	strong := suite.authService.CheckPasswordStrength(service.PasswordStrengthInput{Password: "Lantern-Harbor-42!"})
+	assert.True(suite.T(), strong.Acceptable)
+	assert.Empty(suite.T(), strong.Reason)
+	assert.GreaterOrEqual(suite.T(), strong.Score, strong.MinScore)
+}
+
+func TestPasswordStrengthTestSuite(t *testing.T) {
+	suite.Run(t, new(PasswordStrengthTestSuite))
+}
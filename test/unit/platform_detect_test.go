@@ -0,0 +1,72 @@
+// test/unit/platform_detect_test.go
+package unit
+
+import (
+	"testing"
+
+	"github.com/0xsj/mios.io/log"
+	"github.com/0xsj/mios.io/repository"
+	"github.com/0xsj/mios.io/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type PlatformDetectTestSuite struct {
+	suite.Suite
+	svc service.LinkMetadataService
+}
+
+func (suite *PlatformDetectTestSuite) SetupTest() {
+	// Detection never touches the repository
+	var repo repository.LinkMetadataRepository
+	suite.svc = service.NewLinkMetadataService(repo, service.LinkMetadataConfig{},
+		log.Development().WithLayer("PlatformDetectTest"))
+}
+
+func (suite *PlatformDetectTestSuite) TestMatchesNormalizedDomains() {
+	cases := map[string]string{
+		"https://github.com/octocat":         "github.com",
+		"https://www.YouTube.com/watch?v=1":  "youtube.com",
+		"m.youtube.com/@channel":             "youtube.com",
+		"youtu.be/dQw4w9WgXcQ":               "youtube.com",
+		"https://open.spotify.com/artist/1":  "spotify.com",
+		"http://vm.tiktok.com/abc":           "tiktok.com",
+		"https://www.instagram.com./profile": "instagram.com",
+	}
+	for url, domain := range cases {
+		detection, err := suite.svc.DetectPlatform(url)
+		require.NoError(suite.T(), err, url)
+		assert.True(suite.T(), detection.Known, url)
+		assert.Equal(suite.T(), domain, detection.Domain, url)
+		assert.Equal(suite.T(), service.PlatformRegistry[domain].Color, detection.Color, url)
+		assert.NotEmpty(suite.T(), detection.Name, url)
+		assert.NotEmpty(suite.T(), detection.Icon, url)
+	}
+}
+
+func (suite *PlatformDetectTestSuite) TestUnknownDomains() {
+	for _, url := range []string{"https://example.com", "notgithub.com/foo", "https://github.com.evil.example"} {
+		detection, err := suite.svc.DetectPlatform(url)
+		require.NoError(suite.T(), err, url)
+		assert.Equal(suite.T(), &service.PlatformDetectionDTO{Known: false}, detection, url)
+	}
+}
+
+func (suite *PlatformDetectTestSuite) TestRejectsURLsWithoutAHost() {
+	_, err := suite.svc.DetectPlatform("https://")
+	assert.Error(suite.T(), err)
+}
+
+func (suite *PlatformDetectTestSuite) TestSubdomainsShareThePlatformInfo() {
+	assert.True(suite.T(), suite.svc.IsKnownPlatform("www.github.com"))
+	assert.False(suite.T(), suite.svc.IsKnownPlatform("github.io"))
+
+	info := suite.svc.GetPlatformInfo("gist.github.com")
+	require.NotNil(suite.T(), info)
+	assert.Equal(suite.T(), "GitHub", info.Name)
+}
+
+func TestPlatformDetectTestSuite(t *testing.T) {
+	suite.Run(t, new(PlatformDetectTestSuite))
+}
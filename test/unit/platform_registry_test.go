@@ -0,0 +1,185 @@
+// test/unit/platform_registry_test.go
+package unit
+
+import (
+	"context"
+	"testing"
+
+	db "github.com/0xsj/mios.io/db/sqlc"
+	"github.com/0xsj/mios.io/log"
+	"github.com/0xsj/mios.io/pkg/errors"
+	"github.com/0xsj/mios.io/repository"
+	"github.com/0xsj/mios.io/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+// platformTableRepo keeps the platforms table in memory
+type platformTableRepo struct {
+	repository.LinkMetadataRepository
+	rows    map[string]*db.Platform
+	listErr error
+}
+
+func (r *platformTableRepo) ListPlatforms(ctx context.Context) ([]*db.Platform, error) {
+	if r.listErr != nil {
+		return nil, r.listErr
+	}
+	rows := make([]*db.Platform, 0, len(r.rows))
+	for _, row := range r.rows {
+		copied := *row
+		rows = append(rows, &copied)
+	}
+	return rows, nil
+}
+
+func (r *platformTableRepo) CreatePlatform(ctx context.Context, params repository.CreatePlatformParams) (*db.Platform, error) {
+	if _, exists := r.rows[params.Domain]; exists {
+		return nil, errors.NewConflictError("Platform already exists", nil)
+	}
+	row := &db.Platform{
+		Domain:         params.Domain,
+		Name:           params.Name,
+		Type:           params.Type,
+		Color:          params.Color,
+		Icon:           params.Icon,
+		UrlTemplate:    params.URLTemplate,
+		ScrapeStrategy: params.ScrapeStrategy,
+		OembedEndpoint: params.OEmbedEndpoint,
+		UserAgent:      params.UserAgent,
+	}
+	r.rows[row.Domain] = row
+	copied := *row
+	return &copied, nil
+}
+
+func (r *platformTableRepo) UpdatePlatform(ctx context.Context, params repository.UpdatePlatformParams) (*db.Platform, error) {
+	row, exists := r.rows[params.Domain]
+	if !exists {
+		return nil, errors.NewNotFoundError("Platform not found", nil)
+	}
+	for _, field := range []struct {
+		dest  *string
+		value *string
+	}{
+		{&row.Name, params.Name},
+		{&row.Type, params.Type},
+		{&row.Color, params.Color},
+		{&row.Icon, params.Icon},
+		{&row.UrlTemplate, params.URLTemplate},
+		{&row.ScrapeStrategy, params.ScrapeStrategy},
+		{&row.OembedEndpoint, params.OEmbedEndpoint},
+		{&row.UserAgent, params.UserAgent},
+	} {
+		if field.value != nil {
+			*field.dest = *field.value
+		}
+	}
+	copied := *row
+	return &copied, nil
+}
+
+func (r *platformTableRepo) DeletePlatform(ctx context.Context, domain string) error {
+	if _, exists := r.rows[domain]; !exists {
+		return errors.NewNotFoundError("Platform not found", nil)
+	}
+	delete(r.rows, domain)
+	return nil
+}
+
+type PlatformRegistryTestSuite struct {
+	suite.Suite
+	ctx  context.Context
+	repo *platformTableRepo
+	svc  service.LinkMetadataService
+}
+
+func (suite *PlatformRegistryTestSuite) SetupTest() {
+	suite.ctx = context.Background()
+	suite.repo = &platformTableRepo{rows: map[string]*db.Platform{
+		"github.com": {Domain: "github.com", Name: "GitHub", Type: "dev", Color: "#333333"},
+		"bsky.app":   {Domain: "bsky.app", Name: "Bluesky", Type: "social", Color: "#1185FE"},
+	}}
+	suite.svc = service.NewLinkMetadataService(suite.repo, service.LinkMetadataConfig{},
+		log.Development().WithLayer("PlatformRegistryTest"))
+}
+
+func (suite *PlatformRegistryTestSuite) TestUsesBuiltInRegistryUntilLoaded() {
+	assert.True(suite.T(), suite.svc.IsKnownPlatform("youtube.com"))
+	assert.False(suite.T(), suite.svc.IsKnownPlatform("bsky.app"))
+
+	require.NoError(suite.T(), suite.svc.LoadPlatforms(suite.ctx))
+
+	assert.False(suite.T(), suite.svc.IsKnownPlatform("youtube.com"), "the table replaces the built-in registry")
+	assert.True(suite.T(), suite.svc.IsKnownPlatform("www.bsky.app"))
+
+	platforms, err := suite.svc.ListKnownPlatforms(suite.ctx)
+	require.NoError(suite.T(), err)
+	require.Len(suite.T(), platforms, 2)
+	assert.Equal(suite.T(), "bsky.app", platforms[0].Domain)
+	assert.Equal(suite.T(), "github.com", platforms[1].Domain)
+}
+
+func (suite *PlatformRegistryTestSuite) TestFailedLoadKeepsTheCurrentRegistry() {
+	suite.repo.listErr = errors.NewInternalError("database unavailable", nil)
+
+	assert.Error(suite.T(), suite.svc.LoadPlatforms(suite.ctx))
+	assert.True(suite.T(), suite.svc.IsKnownPlatform("youtube.com"))
+}
+
+func (suite *PlatformRegistryTestSuite) TestChangesAreVisibleWithoutReloading() {
+	require.NoError(suite.T(), suite.svc.LoadPlatforms(suite.ctx))
+
+	created, err := suite.svc.CreatePlatform(suite.ctx, service.CreatePlatformInput{
+		Domain: " Mastodon.Social. ",
+		Name:   "Mastodon",
+		Type:   "social",
+		Color:  "#6364FF",
+	})
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "mastodon.social", created.Domain)
+	assert.Equal(suite.T(), "Mastodon", suite.svc.GetPlatformInfo("mastodon.social").Name)
+
+	color := "#0085FF"
+	updated, err := suite.svc.UpdatePlatform(suite.ctx, "bsky.app", service.UpdatePlatformInput{Color: &color})
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "Bluesky", updated.Name)
+	assert.Equal(suite.T(), color, suite.svc.GetPlatformInfo("bsky.app").Color)
+
+	require.NoError(suite.T(), suite.svc.DeletePlatform(suite.ctx, "github.com"))
+	assert.False(suite.T(), suite.svc.IsKnownPlatform("github.com"))
+
+	detection, err := suite.svc.DetectPlatform("https://gist.github.com/octocat")
+	require.NoError(suite.T(), err)
+	assert.False(suite.T(), detection.Known)
+}
+
+func (suite *PlatformRegistryTestSuite) TestValidatesPlatforms() {
+	require.NoError(suite.T(), suite.svc.LoadPlatforms(suite.ctx))
+
+	invalid := []service.CreatePlatformInput{
+		{Domain: "not a domain", Name: "X", Type: "social", Color: "#000000"},
+		{Domain: "youtu.be", Name: "YouTube", Type: "video", Color: "#FF0000"},
+		{Domain: "example.com", Type: "social", Color: "#000000"},
+		{Domain: "example.com", Name: "Example", Type: "social", Color: "red"},
+		{Domain: "example.com", Name: "Example", Type: "social", Color: "#000000", ScrapeStrategy: "crawl"},
+		{Domain: "example.com", Name: "Example", Type: "social", Color: "#000000", ScrapeStrategy: "oembed"},
+	}
+	for _, input := range invalid {
+		_, err := suite.svc.CreatePlatform(suite.ctx, input)
+		assert.Error(suite.T(), err, input)
+	}
+	assert.Len(suite.T(), suite.repo.rows, 2)
+
+	strategy := "oembed"
+	_, err := suite.svc.UpdatePlatform(suite.ctx, "bsky.app", service.UpdatePlatformInput{ScrapeStrategy: &strategy})
+	assert.Error(suite.T(), err, "the merged entry has no oEmbed endpoint")
+
+	_, err = suite.svc.UpdatePlatform(suite.ctx, "example.com", service.UpdatePlatformInput{ScrapeStrategy: &strategy})
+	assert.Error(suite.T(), err)
+}
+
+func TestPlatformRegistryTestSuite(t *testing.T) {
+	suite.Run(t, new(PlatformRegistryTestSuite))
+}
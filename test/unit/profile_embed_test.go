@@ -0,0 +1,141 @@
+// test/unit/profile_embed_test.go
+package unit
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/0xsj/mios.io/api/profile"
+	db "github.com/0xsj/mios.io/db/sqlc"
+	"github.com/0xsj/mios.io/log"
+	"github.com/0xsj/mios.io/pkg/errors"
+	"github.com/0xsj/mios.io/repository"
+	"github.com/0xsj/mios.io/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+// handleUserRepo finds its one user by handle
+type handleUserRepo struct {
+	repository.UserRepository
+	user *db.User
+}
+
+func (r *handleUserRepo) GetUserByHandle(ctx context.Context, handle string) (*db.User, error) {
+	if handle != r.user.Handle {
+		return nil, errors.NewNotFoundError("user not found", nil)
+	}
+	return r.user, nil
+}
+
+func (r *handleUserRepo) GetUserIDByPreviousHandle(ctx context.Context, handle string) (uuid.UUID, error) {
+	return uuid.Nil, errors.NewNotFoundError("handle not found", nil)
+}
+
+type ProfileEmbedTestSuite struct {
+	suite.Suite
+	user   *db.User
+	server *httptest.Server
+}
+
+func (suite *ProfileEmbedTestSuite) SetupTest() {
+	onboarded := true
+	suite.user = &db.User{UserID: uuid.New(), Username: "embedded", Handle: "embedded", Onboarded: &onboarded}
+
+	active, inactive := true, false
+	str := func(s string) *string { return &s }
+	contentRepo := &orderContentRepo{items: []*db.ContentItem{
+		{ItemID: uuid.New(), UserID: suite.user.UserID, Title: str("Shop"), Href: str("https://shop.example.com"), IsActive: &active},
+		{ItemID: uuid.New(), UserID: suite.user.UserID, Title: str("Draft"), Href: str("https://draft.example.com"), IsActive: &inactive},
+		{ItemID: uuid.New(), UserID: suite.user.UserID, Title: str("Bio text"), IsActive: &active},
+		{ItemID: uuid.New(), UserID: suite.user.UserID, Url: str("https://blog.example.com"), IsActive: &active, Pinned: true},
+	}}
+
+	logger := log.Development().WithLayer("ProfileEmbedTest")
+	svc := service.NewProfileService(&handleUserRepo{user: suite.user}, nil, contentRepo,
+		service.ContentConfig{}, service.ProfileConfig{}, logger)
+	handler := profile.NewHandler(svc, logger)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/api/profiles/:handle/links", handler.GetEmbedLinks)
+	router.GET("/api/profiles/:handle/embed.js", handler.GetEmbedScript)
+	suite.server = httptest.NewServer(router)
+}
+
+func (suite *ProfileEmbedTestSuite) TearDownTest() {
+	suite.server.Close()
+}
+
+func (suite *ProfileEmbedTestSuite) get(path, etag string) *http.Response {
+	req, err := http.NewRequest(http.MethodGet, suite.server.URL+path, nil)
+	require.NoError(suite.T(), err)
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(suite.T(), err)
+	return resp
+}
+
+func (suite *ProfileEmbedTestSuite) TestLinksOnlyIncludePublishedTargets() {
+	resp := suite.get("/api/profiles/embedded/links", "")
+	defer resp.Body.Close()
+	require.Equal(suite.T(), http.StatusOK, resp.StatusCode)
+	assert.Equal(suite.T(), "public, max-age=300", resp.Header.Get("Cache-Control"))
+
+	var body struct {
+		Data service.ProfileEmbedDTO `json:"data"`
+	}
+	require.NoError(suite.T(), json.NewDecoder(resp.Body).Decode(&body))
+	assert.Equal(suite.T(), suite.user.UserID.String(), body.Data.UserID)
+	require.Len(suite.T(), body.Data.Links, 2)
+	assert.Equal(suite.T(), "https://blog.example.com", body.Data.Links[0].URL, "pinned first")
+	assert.Equal(suite.T(), "https://blog.example.com", body.Data.Links[0].Title, "untitled links show their target")
+	assert.Equal(suite.T(), "Shop", body.Data.Links[1].Title)
+}
+
+func (suite *ProfileEmbedTestSuite) TestHiddenUntilOnboarded() {
+	onboarded := false
+	suite.user.Onboarded = &onboarded
+
+	resp := suite.get("/api/profiles/embedded/links", "")
+	defer resp.Body.Close()
+	assert.Equal(suite.T(), http.StatusNotFound, resp.StatusCode)
+}
+
+func (suite *ProfileEmbedTestSuite) TestScriptIsCacheable() {
+	resp := suite.get("/api/profiles/embedded/embed.js", "")
+	script, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	require.NoError(suite.T(), err)
+
+	require.Equal(suite.T(), http.StatusOK, resp.StatusCode)
+	assert.Contains(suite.T(), resp.Header.Get("Content-Type"), "application/javascript")
+	assert.Contains(suite.T(), resp.Header.Get("Cache-Control"), "max-age=86400")
+	assert.Contains(suite.T(), string(script), `var handle = "embedded";`)
+	assert.Contains(suite.T(), string(script), `var channel = "embed";`)
+
+	etag := resp.Header.Get("ETag")
+	require.NotEmpty(suite.T(), etag)
+	resp = suite.get("/api/profiles/embedded/embed.js", etag)
+	resp.Body.Close()
+	assert.Equal(suite.T(), http.StatusNotModified, resp.StatusCode)
+}
+
+func (suite *ProfileEmbedTestSuite) TestScriptForUnknownProfile() {
+	resp := suite.get("/api/profiles/nobody/embed.js", "")
+	resp.Body.Close()
+	assert.Equal(suite.T(), http.StatusNotFound, resp.StatusCode)
+}
+
+func TestProfileEmbedTestSuite(t *testing.T) {
+	suite.Run(t, new(ProfileEmbedTestSuite))
+}
@@ -0,0 +1,151 @@
+// test/unit/profile_visibility_test.go
+package unit
+
+import (
+	"context"
+	stderrors "errors"
+	"testing"
+
+	db "github.com/0xsj/mios.io/db/sqlc"
+	"github.com/0xsj/mios.io/log"
+	"github.com/0xsj/mios.io/pkg/errors"
+	"github.com/0xsj/mios.io/service"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+// visibilityUserRepo serves its one user by ID or handle and lets its
+// visibility change
+type visibilityUserRepo struct {
+	exportUserRepo
+}
+
+func (r *visibilityUserRepo) GetUserByHandle(ctx context.Context, handle string) (*db.User, error) {
+	if handle != r.user.Handle {
+		return nil, errors.NewNotFoundError("User not found", nil)
+	}
+	return r.user, nil
+}
+
+func (r *visibilityUserRepo) UpdateVisibility(ctx context.Context, userID uuid.UUID, visibility string) error {
+	r.user.Visibility = visibility
+	return nil
+}
+
+type ProfileVisibilityTestSuite struct {
+	suite.Suite
+	ctx       context.Context
+	user      *db.User
+	itemID    uuid.UUID
+	views     *recordingAnalyticsRepo
+	users     service.UserService
+	content   service.ContentService
+	profiles  service.ProfileService
+	analytics service.AnalyticsService
+}
+
+func (suite *ProfileVisibilityTestSuite) SetupTest() {
+	suite.ctx = context.Background()
+	onboarded := true
+	suite.user = &db.User{
+		UserID:           uuid.New(),
+		Handle:           "jane",
+		Onboarded:        &onboarded,
+		AnalyticsEnabled: true,
+		Visibility:       service.VisibilityPublic,
+	}
+	suite.itemID = uuid.New()
+	suite.views = &recordingAnalyticsRepo{}
+
+	logger := log.Development().WithLayer("ProfileVisibilityTest")
+	userRepo := &visibilityUserRepo{exportUserRepo{user: suite.user}}
+	items := &pinContentRepo{items: map[uuid.UUID]*db.ContentItem{
+		suite.itemID: {ItemID: suite.itemID, UserID: suite.user.UserID},
+	}}
+
+	suite.users = service.NewUserService(userRepo, nil, nil, nil, nil, nil, nil, nil, service.UserConfig{}, logger)
+	suite.content = service.NewContentService(&orderContentRepo{}, userRepo, nil, nil, nil, nil, nil, service.ContentConfig{}, logger)
+	suite.profiles = service.NewProfileService(userRepo, nil, nil, service.ContentConfig{}, service.ProfileConfig{}, logger)
+	suite.analytics = service.NewAnalyticsService(suite.views, items, userRepo, nil, nil, nil, nil,
+		service.AnalyticsExportConfig{}, service.AnalyticsConfig{}, logger)
+}
+
+func (suite *ProfileVisibilityTestSuite) setVisibility(visibility string) {
+	_, err := suite.users.UpdateVisibility(suite.ctx, suite.user.UserID.String(), visibility)
+	require.NoError(suite.T(), err)
+}
+
+func (suite *ProfileVisibilityTestSuite) recordView() error {
+	return suite.analytics.RecordPageView(suite.ctx, service.RecordPageViewInput{
+		ProfileID: suite.itemID.String(),
+		UserID:    suite.user.UserID.String(),
+	})
+}
+
+func (suite *ProfileVisibilityTestSuite) TestPrivateProfileHiddenFromOthers() {
+	suite.setVisibility(service.VisibilityPrivate)
+	owner := suite.user.UserID.String()
+
+	_, err := suite.content.GetUserContentItems(suite.ctx, owner, "")
+	assert.True(suite.T(), errors.IsNotFound(err), "anonymous viewers get a 404")
+	_, err = suite.content.GetUserContentItems(suite.ctx, owner, uuid.NewString())
+	assert.True(suite.T(), errors.IsNotFound(err), "other users get a 404")
+	_, err = suite.content.GetUserContentItems(suite.ctx, owner, owner)
+	assert.NoError(suite.T(), err, "owners still see their own profile")
+
+	_, _, err = suite.profiles.ResolveCanonical(suite.ctx, "jane")
+	assert.True(suite.T(), errors.IsNotFound(err))
+
+	assert.True(suite.T(), errors.IsNotFound(suite.recordView()))
+	assert.Empty(suite.T(), suite.views.views)
+}
+
+func (suite *ProfileVisibilityTestSuite) TestUnlistedProfileStillServed() {
+	suite.setVisibility(service.VisibilityUnlisted)
+
+	_, err := suite.content.GetUserContentItems(suite.ctx, suite.user.UserID.String(), "")
+	assert.NoError(suite.T(), err)
+
+	handle, _, err := suite.profiles.ResolveCanonical(suite.ctx, "jane")
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "jane", handle)
+
+	require.NoError(suite.T(), suite.recordView())
+	assert.Len(suite.T(), suite.views.views, 1)
+}
+
+func (suite *ProfileVisibilityTestSuite) TestUnlistedProfileHasNoMetadata() {
+	suite.setVisibility(service.VisibilityUnlisted)
+
+	_, err := suite.profiles.GetProfileMeta(suite.ctx, "jane")
+	assert.True(suite.T(), errors.IsNotFound(err), "crawlers must not index unlisted profiles")
+
+	suite.setVisibility(service.VisibilityPrivate)
+	_, err = suite.profiles.GetProfileMeta(suite.ctx, "jane")
+	assert.True(suite.T(), errors.IsNotFound(err))
+}
+
+func (suite *ProfileVisibilityTestSuite) TestVisibleTo() {
+	user, err := suite.users.UpdateVisibility(suite.ctx, suite.user.UserID.String(), service.VisibilityPrivate)
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), service.VisibilityPrivate, user.Visibility)
+	assert.False(suite.T(), user.VisibleTo(""))
+	assert.True(suite.T(), user.VisibleTo(suite.user.UserID.String()))
+
+	user.Visibility = service.VisibilityUnlisted
+	assert.True(suite.T(), user.VisibleTo(""))
+}
+
+func (suite *ProfileVisibilityTestSuite) TestRejectsUnknownVisibility() {
+	_, err := suite.users.UpdateVisibility(suite.ctx, suite.user.UserID.String(), "friends")
+	var appErr *errors.AppError
+	require.True(suite.T(), stderrors.As(err, &appErr), err)
+	assert.Equal(suite.T(), "VALIDATION_ERROR", appErr.Code)
+	assert.Equal(suite.T(), service.VisibilityPublic, suite.user.Visibility)
+}
+
+func TestProfileVisibilityTestSuite(t *testing.T) {
+	suite.Run(t, new(ProfileVisibilityTestSuite))
+}
@@ -0,0 +1,255 @@
+// test/unit/rate_limit_test.go
+package unit
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/0xsj/mios.io/config"
+	"github.com/0xsj/mios.io/log"
+	"github.com/0xsj/mios.io/middleware"
+	"github.com/0xsj/mios.io/pkg/redis"
+	"github.com/0xsj/mios.io/pkg/token"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type RateLimitTestSuite struct {
+	suite.Suite
+	server *miniredis.Miniredis
+	client *redis.Client
+	status int
+}
+
+func (suite *RateLimitTestSuite) SetupTest() {
+	suite.server = miniredis.RunT(suite.T())
+	host, port, err := net.SplitHostPort(suite.server.Addr())
+	require.NoError(suite.T(), err)
+
+	suite.client, err = redis.NewClient(config.Config{RedisHost: host, RedisPort: port}, log.Development().WithLayer("RateLimitTest"))
+	require.NoError(suite.T(), err)
+	suite.T().Cleanup(func() { suite.client.Close() })
+	suite.status = http.StatusOK
+}
+
+func (suite *RateLimitTestSuite) limiter(cfg middleware.RateLimitConfig) gin.HandlerFunc {
+	limiter, err := middleware.NewRateLimiter(suite.client, log.Development().WithLayer("RateLimitTest"), cfg)
+	require.NoError(suite.T(), err)
+	return limiter.Middleware()
+}
+
+func (suite *RateLimitTestSuite) router(cfg middleware.RateLimitConfig) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(suite.limiter(cfg))
+	router.GET("/ping", func(c *gin.Context) {
+		c.Status(suite.status)
+	})
+	return router
+}
+
+func (suite *RateLimitTestSuite) request(router *gin.Engine, at time.Time) *httptest.ResponseRecorder {
+	suite.server.SetTime(at)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/ping", nil))
+	return rec
+}
+
+// limited sends a request at the given time and reports whether it was
+// rejected
+func (suite *RateLimitTestSuite) limited(router *gin.Engine, at time.Time) bool {
+	rec := suite.request(router, at)
+
+	var body struct {
+		Code string `json:"code"`
+	}
+	json.Unmarshal(rec.Body.Bytes(), &body)
+	return body.Code == "RATE_LIMIT_EXCEEDED"
+}
+
+func (suite *RateLimitTestSuite) config() middleware.RateLimitConfig {
+	cfg := middleware.DefaultRateLimit()
+	cfg.RequestsPerMinute = 5
+	cfg.BurstSize = 100
+	return cfg
+}
+
+func (suite *RateLimitTestSuite) TestLimitHoldsAcrossWindowBoundary() {
+	router := suite.router(suite.config())
+	minute := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	for i := 0; i < 5; i++ {
+		at := minute.Add(59*time.Second + time.Duration(i)*100*time.Millisecond)
+		assert.False(suite.T(), suite.limited(router, at), "request %d before the boundary", i)
+	}
+	assert.True(suite.T(), suite.limited(router, minute.Add(time.Minute+time.Second)), "the limit rolls over the boundary")
+
+	assert.False(suite.T(), suite.limited(router, minute.Add(2*time.Minute)), "a minute after the first request")
+}
+
+func (suite *RateLimitTestSuite) TestRejectionsAskClientsToRetryLater() {
+	router := suite.router(suite.config())
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	for i := 0; i < 5; i++ {
+		rec := suite.request(router, start.Add(time.Duration(i)*time.Second))
+		require.Equal(suite.T(), http.StatusOK, rec.Code)
+		assert.Empty(suite.T(), rec.Header().Get("Retry-After"))
+	}
+
+	rec := suite.request(router, start.Add(20*time.Second+300*time.Millisecond))
+	assert.Equal(suite.T(), http.StatusTooManyRequests, rec.Code)
+	assert.Equal(suite.T(), "40", rec.Header().Get("Retry-After"), "the first request leaves the window in 39.7s")
+	assert.Equal(suite.T(), "0", rec.Header().Get("X-RateLimit-Remaining"))
+}
+
+func (suite *RateLimitTestSuite) TestRejectedRequestsAreNotCounted() {
+	router := suite.router(suite.config())
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	for i := 0; i < 5; i++ {
+		suite.limited(router, start)
+	}
+	for i := 0; i < 10; i++ {
+		assert.True(suite.T(), suite.limited(router, start.Add(30*time.Second)))
+	}
+	assert.False(suite.T(), suite.limited(router, start.Add(61*time.Second)), "only the allowed requests fill the window")
+}
+
+func (suite *RateLimitTestSuite) TestSkipSuccessfulOnlyCountsFailures() {
+	cfg := suite.config()
+	cfg.SkipSuccessful = true
+	router := suite.router(cfg)
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	for i := 0; i < 20; i++ {
+		assert.False(suite.T(), suite.limited(router, start.Add(time.Duration(i)*time.Second)))
+	}
+
+	suite.status = http.StatusBadRequest
+	for i := 0; i < 5; i++ {
+		assert.False(suite.T(), suite.limited(router, start.Add(30*time.Second)))
+	}
+	assert.True(suite.T(), suite.limited(router, start.Add(31*time.Second)))
+}
+
+func (suite *RateLimitTestSuite) TestBurstLimit() {
+	cfg := suite.config()
+	cfg.RequestsPerMinute = 100
+	cfg.BurstSize = 2
+	router := suite.router(cfg)
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	assert.False(suite.T(), suite.limited(router, start))
+	assert.False(suite.T(), suite.limited(router, start.Add(5*time.Second)))
+	assert.True(suite.T(), suite.limited(router, start.Add(9*time.Second)))
+	assert.False(suite.T(), suite.limited(router, start.Add(11*time.Second)), "the first request left the burst window")
+}
+
+func (suite *RateLimitTestSuite) TestPremiumUsersGetHigherLimits() {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("claims", &token.Claims{UserID: c.GetHeader("X-User"), IsPremium: c.GetHeader("X-Premium") == "true"})
+		c.Set("user_id", c.GetHeader("X-User"))
+	})
+	cfg := suite.config()
+	cfg.KeyGenerator = middleware.UserBasedKeyGenerator
+	router.Use(suite.limiter(cfg))
+	router.GET("/ping", func(c *gin.Context) {})
+
+	send := func(user string, premium bool) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+		req.Header.Set("X-User", user)
+		req.Header.Set("X-Premium", strconv.FormatBool(premium))
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	for i := 0; i < 10; i++ {
+		rec := send("premium", true)
+		require.Equal(suite.T(), http.StatusOK, rec.Code, "premium request %d", i)
+		assert.Equal(suite.T(), "10", rec.Header().Get("X-RateLimit-Limit"))
+	}
+	assert.Equal(suite.T(), http.StatusTooManyRequests, send("premium", true).Code)
+
+	for i := 0; i < 5; i++ {
+		require.Equal(suite.T(), http.StatusOK, send("free", false).Code)
+	}
+	assert.Equal(suite.T(), http.StatusTooManyRequests, send("free", false).Code)
+}
+
+func (suite *RateLimitTestSuite) TestLimitsResolvedPerRoute() {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.RateLimitFor(suite.client, log.Development().WithLayer("RateLimitTest"), func(c *gin.Context) middleware.RateLimitConfig {
+		cfg := suite.config()
+		if strings.HasPrefix(c.FullPath(), "/export") {
+			cfg.RequestsPerMinute = 1
+			cfg.Scope = "export"
+		}
+		return cfg
+	}))
+	router.GET("/ping", func(c *gin.Context) {})
+	router.GET("/export", func(c *gin.Context) {})
+
+	send := func(path string) int {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec.Code
+	}
+
+	assert.Equal(suite.T(), http.StatusOK, send("/export"))
+	assert.Equal(suite.T(), http.StatusTooManyRequests, send("/export"))
+	for i := 0; i < 5; i++ {
+		assert.Equal(suite.T(), http.StatusOK, send("/ping"), "the export limit has its own counter")
+	}
+	assert.Equal(suite.T(), http.StatusTooManyRequests, send("/ping"))
+}
+
+func (suite *RateLimitTestSuite) TestAllowlistedIPsBypassLimits() {
+	cfg := suite.config()
+	cfg.RequestsPerMinute = 1
+	cfg.AllowedIPs = []string{"10.1.2.3", "192.168.0.0/16", "2001:db8::/32"}
+	router := suite.router(cfg)
+
+	send := func(ip string) int {
+		req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+		req.RemoteAddr = net.JoinHostPort(ip, "4000")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	for _, ip := range []string{"10.1.2.3", "192.168.40.7", "2001:db8::1"} {
+		for i := 0; i < 3; i++ {
+			assert.Equal(suite.T(), http.StatusOK, send(ip), ip)
+		}
+	}
+
+	assert.Equal(suite.T(), http.StatusOK, send("10.1.2.4"))
+	assert.Equal(suite.T(), http.StatusTooManyRequests, send("10.1.2.4"), "neighbouring addresses are limited")
+}
+
+func (suite *RateLimitTestSuite) TestInvalidAllowlistIsRejected() {
+	for _, entry := range []string{"10.1.2", "10.0.0.0/33", "example.com"} {
+		cfg := suite.config()
+		cfg.AllowedIPs = []string{"10.0.0.1", entry}
+		_, err := middleware.NewRateLimiter(suite.client, log.Development().WithLayer("RateLimitTest"), cfg)
+		assert.Error(suite.T(), err, entry)
+	}
+}
+
+func TestRateLimitTestSuite(t *testing.T) {
+	suite.Run(t, new(RateLimitTestSuite))
+}
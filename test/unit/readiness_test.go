@@ -0,0 +1,95 @@
+// test/unit/readiness_test.go
+package unit
+
+import (
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	api "github.com/0xsj/mios.io/api/server"
+	"github.com/0xsj/mios.io/config"
+	"github.com/0xsj/mios.io/log"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type ReadinessTestSuite struct {
+	suite.Suite
+	server *api.Server
+}
+
+func (suite *ReadinessTestSuite) SetupTest() {
+	gin.SetMode(gin.TestMode)
+	server, err := api.NewServer(config.Config{ReadinessTimeout: 50 * time.Millisecond}, nil, log.Development().WithLayer("ReadinessTest"), nil, nil, nil)
+	require.NoError(suite.T(), err)
+	suite.server = server
+}
+
+func (suite *ReadinessTestSuite) get(path string) (int, map[string]interface{}) {
+	recorder := httptest.NewRecorder()
+	suite.server.Router().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+
+	var body map[string]interface{}
+	require.NoError(suite.T(), json.Unmarshal(recorder.Body.Bytes(), &body))
+	return recorder.Code, body
+}
+
+func dependencyUp(context.Context) error { return nil }
+
+// dependencyHangs blocks until the readiness timeout expires
+func dependencyHangs(ctx context.Context) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func (suite *ReadinessTestSuite) TestReadyWhenDependenciesAnswer() {
+	suite.server.AddReadinessCheck("database", dependencyUp)
+	suite.server.AddReadinessCheck("redis", dependencyUp)
+
+	status, body := suite.get("/health/ready")
+	require.Equal(suite.T(), http.StatusOK, status)
+
+	dependencies := body["data"].(map[string]interface{})["dependencies"].(map[string]interface{})
+	for _, name := range []string{"database", "redis"} {
+		dependency := dependencies[name].(map[string]interface{})
+		assert.Equal(suite.T(), "up", dependency["status"], name)
+		assert.Contains(suite.T(), dependency, "latency_ms", name)
+	}
+}
+
+func (suite *ReadinessTestSuite) TestUnavailableWhenADependencyIsDown() {
+	suite.server.AddReadinessCheck("database", dependencyUp)
+	suite.server.AddReadinessCheck("redis", func(context.Context) error { return stderrors.New("connection refused") })
+
+	start := time.Now()
+	suite.server.AddReadinessCheck("search", dependencyHangs)
+	status, body := suite.get("/health/ready")
+	assert.Less(suite.T(), time.Since(start), time.Second, "slow dependencies are cut off")
+
+	require.Equal(suite.T(), http.StatusServiceUnavailable, status)
+	assert.Equal(suite.T(), "SERVICE_UNAVAILABLE", body["code"])
+
+	details := body["details"].(map[string]interface{})
+	assert.Equal(suite.T(), "up", details["database"].(map[string]interface{})["status"])
+	redis := details["redis"].(map[string]interface{})
+	assert.Equal(suite.T(), "down", redis["status"])
+	assert.Equal(suite.T(), "connection refused", redis["error"])
+	assert.Equal(suite.T(), "down", details["search"].(map[string]interface{})["status"])
+}
+
+func (suite *ReadinessTestSuite) TestLivenessIgnoresDependencies() {
+	suite.server.AddReadinessCheck("database", dependencyHangs)
+
+	status, _ := suite.get("/health/live")
+	assert.Equal(suite.T(), http.StatusOK, status)
+}
+
+func TestReadinessTestSuite(t *testing.T) {
+	suite.Run(t, new(ReadinessTestSuite))
+}
@@ -0,0 +1,112 @@
+// test/unit/reauthentication_test.go
+package unit
+
+import (
+	"context"
+	stderrors "errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/0xsj/mios.io/log"
+	"github.com/0xsj/mios.io/pkg/errors"
+	"github.com/0xsj/mios.io/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type ReauthenticationTestSuite struct {
+	suite.Suite
+	ctx         context.Context
+	users       *sessionUserRepo
+	auth        *sessionAuthRepo
+	sink        *smtpSink
+	svc         service.UserService
+	authService service.AuthService
+}
+
+func (suite *ReauthenticationTestSuite) SetupTest() {
+	suite.ctx = context.Background()
+	var sessions *sessionStore
+	suite.users, suite.auth, sessions = newSessionFakes(suite.T())
+
+	emailClient, sink := newSessionEmail(suite.T())
+	suite.sink = sink
+
+	logger := log.Development().WithLayer("ReauthenticationTest")
+	suite.authService = service.NewAuthService(suite.users, suite.auth, nil, nil, sessions, emailClient, nil,
+		"reauthentication-test-secret", 0, service.AuthConfig{MaxFailedLoginAttempts: 3, LockoutDuration: time.Hour},
+		logger, "http://localhost")
+	suite.svc = service.NewUserService(suite.users, suite.auth, sessions, nil, nil, nil, nil, nil, service.UserConfig{}, logger)
+	suite.svc.SetReauthenticator(suite.authService)
+}
+
+func (suite *ReauthenticationTestSuite) transfer(password string) error {
+	_, err := suite.svc.TransferHandle(suite.ctx, suite.users.user.UserID.String(), service.HandleTransferInput{
+		TargetHandle: "someone",
+		ReauthInput:  service.ReauthInput{Password: password},
+	})
+	return err
+}
+
+func (suite *ReauthenticationTestSuite) claim(password string) error {
+	_, err := suite.svc.ClaimHandle(suite.ctx, suite.users.user.UserID.String(), service.HandleClaimInput{
+		Handle:      "someone",
+		ReauthInput: service.ReauthInput{Password: password},
+	})
+	return err
+}
+
+func (suite *ReauthenticationTestSuite) TestWrongPasswordsLockTheAccount() {
+	assert.Error(suite.T(), suite.transfer("wrong-1"))
+	assert.Error(suite.T(), suite.claim("wrong-2"))
+	assert.Empty(suite.T(), suite.auth.lockouts)
+
+	assert.Error(suite.T(), suite.transfer("wrong-3"))
+	require.Len(suite.T(), suite.auth.lockouts, 1, "re-authentication failures count towards the lockout")
+	assert.Contains(suite.T(), suite.sink.sent(), "Your Account Has Been Temporarily Locked")
+
+	// Even the right password is refused until the lock lapses
+	err := suite.transfer(sessionTestPassword)
+	var appErr *errors.AppError
+	require.True(suite.T(), stderrors.As(err, &appErr), err)
+	assert.Equal(suite.T(), "FORBIDDEN", appErr.Code)
+	assert.Equal(suite.T(), int32(0), *suite.auth.auth.FailedLoginAttempts, "attempts while locked are not counted")
+}
+
+func (suite *ReauthenticationTestSuite) TestWrongTwoFactorCodeIsCounted() {
+	secret := "JBSWY3DPEHPK3PXP"
+	suite.auth.auth.TotpEnabled = true
+	suite.auth.auth.TotpSecret = &secret
+
+	err := suite.transfer(sessionTestPassword)
+	assert.Error(suite.T(), err, "the password alone is not enough")
+	require.NotNil(suite.T(), suite.auth.auth.FailedLoginAttempts)
+	assert.Equal(suite.T(), int32(1), *suite.auth.auth.FailedLoginAttempts)
+}
+
+// TestChangePasswordCountsWrongPasswords checks a wrong current password is
+// a validation error, not a 401 clients read as an expired session, and
+// that it counts towards the lockout
+func (suite *ReauthenticationTestSuite) TestChangePasswordCountsWrongPasswords() {
+	userID := suite.users.user.UserID.String()
+
+	for i := 0; i < 3; i++ {
+		err := suite.authService.ChangePassword(suite.ctx, userID, "wrong", "a-brand-new-passphrase-42")
+		var appErr *errors.AppError
+		require.True(suite.T(), stderrors.As(err, &appErr), err)
+		assert.Equal(suite.T(), "VALIDATION_ERROR", appErr.Code)
+		assert.Equal(suite.T(), http.StatusBadRequest, appErr.Status)
+	}
+	require.Len(suite.T(), suite.auth.lockouts, 1)
+
+	err := suite.authService.ChangePassword(suite.ctx, userID, sessionTestPassword, "a-brand-new-passphrase-42")
+	var appErr *errors.AppError
+	require.True(suite.T(), stderrors.As(err, &appErr), err)
+	assert.Equal(suite.T(), "FORBIDDEN", appErr.Code, "a locked account cannot change its password")
+}
+
+func TestReauthenticationTestSuite(t *testing.T) {
+	suite.Run(t, new(ReauthenticationTestSuite))
+}
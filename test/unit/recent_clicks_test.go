@@ -0,0 +1,116 @@
+// test/unit/recent_clicks_test.go
+package unit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	db "github.com/0xsj/mios.io/db/sqlc"
+	"github.com/0xsj/mios.io/log"
+	"github.com/0xsj/mios.io/pkg/geoip"
+	"github.com/0xsj/mios.io/repository"
+	"github.com/0xsj/mios.io/service"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+// recentClicksRepo returns fixed rows and records the limit it was asked for
+type recentClicksRepo struct {
+	repository.AnalyticsRepository
+	rows  []*db.ListRecentClicksRow
+	limit int
+}
+
+func (r *recentClicksRepo) ListRecentClicks(ctx context.Context, userID uuid.UUID, limit int) ([]*db.ListRecentClicksRow, error) {
+	r.limit = limit
+	if len(r.rows) > limit {
+		return r.rows[:limit], nil
+	}
+	return r.rows, nil
+}
+
+type RecentClicksTestSuite struct {
+	suite.Suite
+	user   *db.User
+	itemID uuid.UUID
+	repo   *recentClicksRepo
+}
+
+func (suite *RecentClicksTestSuite) SetupTest() {
+	suite.user = &db.User{UserID: uuid.New(), Username: "recent"}
+	suite.itemID = uuid.New()
+
+	now := time.Now()
+	at := func(ago time.Duration) *time.Time {
+		clickedAt := now.Add(-ago)
+		return &clickedAt
+	}
+	suite.repo = &recentClicksRepo{rows: []*db.ListRecentClicksRow{
+		{ItemID: suite.itemID, Title: "Shop", Referrer: "https://www.Instagram.com/p/abc", IpAddress: "8.8.8.8", InteractionType: "click", ClickedAt: at(10 * time.Second)},
+		{ItemID: suite.itemID, Title: "Shop", Referrer: "newsletter", IpAddress: "1.1.1.1", InteractionType: "click", ClickedAt: at(5 * time.Minute)},
+		{ItemID: suite.itemID, Title: "Shop", InteractionType: "copy", ClickedAt: at(3 * time.Hour)},
+		{ItemID: suite.itemID, Title: "Shop", InteractionType: "click", ClickedAt: at(50 * time.Hour)},
+	}}
+}
+
+func (suite *RecentClicksTestSuite) newService(lookup geoip.Lookup) service.AnalyticsService {
+	return service.NewAnalyticsService(suite.repo, nil, &exportUserRepo{user: suite.user}, nil, nil, lookup, nil,
+		service.AnalyticsExportConfig{},
+		service.AnalyticsConfig{},
+		log.Development().WithLayer("RecentClicksTest"))
+}
+
+func (suite *RecentClicksTestSuite) TestDescribesEachClick() {
+	svc := suite.newService(stubGeoLookup{"8.8.8.8": {Country: "US"}})
+
+	clicks, err := svc.GetRecentClicks(context.Background(), suite.user.UserID.String(), 0)
+	require.NoError(suite.T(), err)
+	require.Len(suite.T(), clicks, 4)
+
+	assert.Equal(suite.T(), suite.itemID.String(), clicks[0].ItemID)
+	assert.Equal(suite.T(), "Shop", clicks[0].ItemTitle)
+	assert.Equal(suite.T(), "instagram.com", clicks[0].Channel)
+	assert.Equal(suite.T(), "US", clicks[0].Country)
+	assert.Equal(suite.T(), "just now", clicks[0].RelativeTime)
+	assert.NotEmpty(suite.T(), clicks[0].ClickedAt)
+
+	assert.Equal(suite.T(), "newsletter", clicks[1].Channel)
+	assert.Equal(suite.T(), geoip.Unknown, clicks[1].Country)
+	assert.Equal(suite.T(), "5m ago", clicks[1].RelativeTime)
+
+	assert.Equal(suite.T(), service.CampaignDirect, clicks[2].Channel)
+	assert.Equal(suite.T(), "copy", clicks[2].InteractionType)
+	assert.Equal(suite.T(), "3h ago", clicks[2].RelativeTime)
+	assert.Equal(suite.T(), "2d ago", clicks[3].RelativeTime)
+}
+
+func (suite *RecentClicksTestSuite) TestCountryNeedsGeolocation() {
+	clicks, err := suite.newService(nil).GetRecentClicks(context.Background(), suite.user.UserID.String(), 0)
+	require.NoError(suite.T(), err)
+	for _, click := range clicks {
+		assert.Empty(suite.T(), click.Country)
+	}
+}
+
+func (suite *RecentClicksTestSuite) TestLimitIsBounded() {
+	svc := suite.newService(nil)
+
+	_, err := svc.GetRecentClicks(context.Background(), suite.user.UserID.String(), 0)
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 20, suite.repo.limit)
+
+	clicks, err := svc.GetRecentClicks(context.Background(), suite.user.UserID.String(), 2)
+	require.NoError(suite.T(), err)
+	assert.Len(suite.T(), clicks, 2)
+
+	_, err = svc.GetRecentClicks(context.Background(), suite.user.UserID.String(), 5000)
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 100, suite.repo.limit)
+}
+
+func TestRecentClicksTestSuite(t *testing.T) {
+	suite.Run(t, new(RecentClicksTestSuite))
+}
@@ -0,0 +1,103 @@
+// test/unit/referrer_attribution_test.go
+package unit
+
+import (
+	"context"
+	"net/url"
+	"testing"
+
+	db "github.com/0xsj/mios.io/db/sqlc"
+	"github.com/0xsj/mios.io/log"
+	"github.com/0xsj/mios.io/repository"
+	"github.com/0xsj/mios.io/service"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type ReferrerAttributionTestSuite struct {
+	suite.Suite
+	user   *db.User
+	itemID uuid.UUID
+	repo   *recordingAnalyticsRepo
+}
+
+func (suite *ReferrerAttributionTestSuite) SetupTest() {
+	suite.user = &db.User{UserID: uuid.New(), Username: "attribution", AnalyticsEnabled: true}
+	suite.itemID = uuid.New()
+	suite.repo = &recordingAnalyticsRepo{}
+}
+
+func (suite *ReferrerAttributionTestSuite) newService(config service.AnalyticsConfig) service.AnalyticsService {
+	content := &pinContentRepo{items: map[uuid.UUID]*db.ContentItem{
+		suite.itemID: {ItemID: suite.itemID, UserID: suite.user.UserID},
+	}}
+	return service.NewAnalyticsService(suite.repo, content, &exportUserRepo{user: suite.user}, nil, nil, nil, nil,
+		service.AnalyticsExportConfig{},
+		config,
+		log.Development().WithLayer("ReferrerAttributionTest"))
+}
+
+func (suite *ReferrerAttributionTestSuite) click(svc service.AnalyticsService, referrer, rawQuery string) repository.CreateAnalyticsParams {
+	query, err := url.ParseQuery(rawQuery)
+	require.NoError(suite.T(), err)
+
+	err = svc.RecordClick(context.Background(), service.RecordClickInput{
+		ItemID:   suite.itemID.String(),
+		UserID:   suite.user.UserID.String(),
+		Referrer: referrer,
+		Query:    query,
+	})
+	require.NoError(suite.T(), err)
+	return suite.repo.clicks[len(suite.repo.clicks)-1]
+}
+
+func (suite *ReferrerAttributionTestSuite) TestPrecedence() {
+	svc := suite.newService(service.AnalyticsConfig{})
+
+	cases := []struct {
+		referrer, query, expected string
+	}{
+		{"", "", ""},
+		{"https://t.co/abc", "", "https://t.co/abc"},
+		{"", "ref=Newsletter", "newsletter"},
+		{"https://t.co/abc", "ref=newsletter", "newsletter"},
+		{"", "utm_source=instagram", "instagram"},
+		{"", "ref=bio&utm_source=instagram", "bio"},
+		{"https://t.co/abc", "ref=%20%20", "https://t.co/abc"},
+	}
+	for _, tc := range cases {
+		recorded := suite.click(svc, tc.referrer, tc.query)
+		assert.Equal(suite.T(), tc.expected, recorded.Referrer, tc)
+	}
+}
+
+func (suite *ReferrerAttributionTestSuite) TestQueryTagsFillInMissingUTM() {
+	svc := suite.newService(service.AnalyticsConfig{})
+
+	recorded := suite.click(svc, "", "utm_source=Instagram&utm_medium=social&utm_campaign=launch")
+	assert.Equal(suite.T(), repository.UTMParams{Source: "instagram", Medium: "social", Campaign: "launch"}, recorded.UTM)
+
+	err := svc.RecordPageView(context.Background(), service.RecordPageViewInput{
+		ProfileID: suite.itemID.String(),
+		UserID:    suite.user.UserID.String(),
+		UTMSource: "email",
+		Query:     url.Values{"utm_source": {"instagram"}, "ref": {"bio"}},
+	})
+	require.NoError(suite.T(), err)
+	require.Len(suite.T(), suite.repo.views, 1)
+	assert.Equal(suite.T(), "bio", suite.repo.views[0].Referrer)
+	assert.Equal(suite.T(), "email", suite.repo.views[0].UTM.Source, "tags sent with the event win")
+}
+
+func (suite *ReferrerAttributionTestSuite) TestConfiguredParamsReplaceDefaults() {
+	svc := suite.newService(service.AnalyticsConfig{ReferrerParams: []string{" Source ", "ref"}})
+
+	assert.Equal(suite.T(), "tiktok", suite.click(svc, "", "ref=bio&source=tiktok").Referrer)
+	assert.Equal(suite.T(), "", suite.click(svc, "", "utm_source=instagram").Referrer)
+}
+
+func TestReferrerAttributionTestSuite(t *testing.T) {
+	suite.Run(t, new(ReferrerAttributionTestSuite))
+}
@@ -0,0 +1,201 @@
+// test/unit/report_subscription_test.go
+package unit
+
+import (
+	"context"
+	stderrors "errors"
+	"testing"
+	"time"
+
+	db "github.com/0xsj/mios.io/db/sqlc"
+	"github.com/0xsj/mios.io/log"
+	"github.com/0xsj/mios.io/pkg/email"
+	"github.com/0xsj/mios.io/pkg/errors"
+	"github.com/0xsj/mios.io/repository"
+	"github.com/0xsj/mios.io/service"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type claimedReport struct {
+	id     uuid.UUID
+	dueAt  time.Time
+	nextAt time.Time
+}
+
+type stubReportRepo struct {
+	repository.ReportSubscriptionRepository
+	created []repository.CreateReportSubscriptionParams
+	due     []*db.ListDueReportSubscriptionsRow
+	claimed []claimedReport
+	deleted []uuid.UUID
+}
+
+func (r *stubReportRepo) CreateSubscription(ctx context.Context, params repository.CreateReportSubscriptionParams) (*db.ReportSubscription, error) {
+	r.created = append(r.created, params)
+	return &db.ReportSubscription{
+		SubscriptionID:   uuid.New(),
+		UserID:           params.UserID,
+		Frequency:        params.Frequency,
+		Metrics:          params.Metrics,
+		UnsubscribeToken: params.UnsubscribeToken,
+		NextSendAt:       params.NextSendAt,
+	}, nil
+}
+
+func (r *stubReportRepo) ListDueSubscriptions(ctx context.Context, dueBefore time.Time, limit int) ([]*db.ListDueReportSubscriptionsRow, error) {
+	return r.due, nil
+}
+
+func (r *stubReportRepo) ClaimSubscription(ctx context.Context, subscriptionID uuid.UUID, dueAt, nextSendAt time.Time) (bool, error) {
+	r.claimed = append(r.claimed, claimedReport{id: subscriptionID, dueAt: dueAt, nextAt: nextSendAt})
+	return true, nil
+}
+
+func (r *stubReportRepo) DeleteSubscription(ctx context.Context, userID, subscriptionID uuid.UUID) error {
+	for _, id := range r.deleted {
+		if id == subscriptionID {
+			return errors.NewNotFoundError("Report subscription not found", nil)
+		}
+	}
+	r.deleted = append(r.deleted, subscriptionID)
+	return nil
+}
+
+type stubReportAnalytics struct {
+	service.AnalyticsService
+	dashboards int
+}
+
+func (s *stubReportAnalytics) GetSummaryCards(ctx context.Context, userID string, days int, includeBots bool) (*service.SummaryCardsDTO, error) {
+	return &service.SummaryCardsDTO{UserID: userID, TotalViews: 10, TotalClicks: 4}, nil
+}
+
+func (s *stubReportAnalytics) GetProfileDashboard(ctx context.Context, userID string, days int, includeBots bool) (*service.ProfileDashboardDTO, error) {
+	s.dashboards++
+	return &service.ProfileDashboardDTO{UserID: userID}, nil
+}
+
+type ReportSubscriptionTestSuite struct {
+	suite.Suite
+	user      *db.User
+	repo      *stubReportRepo
+	analytics *stubReportAnalytics
+	svc       service.ReportService
+}
+
+func (suite *ReportSubscriptionTestSuite) SetupTest() {
+	isPremium := false
+	suite.user = &db.User{UserID: uuid.New(), Username: "tester", Email: "tester@example.com", IsPremium: &isPremium}
+	suite.repo = &stubReportRepo{}
+	suite.analytics = &stubReportAnalytics{}
+
+	logger := log.Development().WithLayer("ReportSubscriptionTest")
+	suite.svc = service.NewReportService(suite.repo, &exportUserRepo{user: suite.user}, suite.analytics,
+		email.NewQueue(nil, logger, 10), service.ReportConfig{}, logger, "http://localhost")
+}
+
+func (suite *ReportSubscriptionTestSuite) TestSubscribeValidatesInput() {
+	ctx := context.Background()
+	userID := suite.user.UserID.String()
+
+	_, err := suite.svc.Subscribe(ctx, userID, service.ReportSubscriptionInput{Frequency: "hourly"})
+	suite.assertErrorCode("BAD_REQUEST", err)
+
+	_, err = suite.svc.Subscribe(ctx, userID, service.ReportSubscriptionInput{
+		Frequency: service.ReportFrequencyWeekly,
+		Metrics:   []string{"views", "bounce_rate"},
+	})
+	suite.assertErrorCode("BAD_REQUEST", err)
+
+	past := time.Now().Add(-time.Hour)
+	_, err = suite.svc.Subscribe(ctx, userID, service.ReportSubscriptionInput{
+		Frequency: service.ReportFrequencyOnce,
+		SendAt:    &past,
+	})
+	suite.assertErrorCode("BAD_REQUEST", err)
+
+	assert.Empty(suite.T(), suite.repo.created)
+}
+
+func (suite *ReportSubscriptionTestSuite) TestDailyReportsRequirePremium() {
+	ctx := context.Background()
+	userID := suite.user.UserID.String()
+
+	_, err := suite.svc.Subscribe(ctx, userID, service.ReportSubscriptionInput{Frequency: service.ReportFrequencyDaily})
+	suite.assertErrorCode("FORBIDDEN", err)
+
+	*suite.user.IsPremium = true
+	subscription, err := suite.svc.Subscribe(ctx, userID, service.ReportSubscriptionInput{Frequency: service.ReportFrequencyDaily})
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), service.ReportFrequencyDaily, subscription.Frequency)
+}
+
+func (suite *ReportSubscriptionTestSuite) TestSubscribeNormalizesMetrics() {
+	subscription, err := suite.svc.Subscribe(context.Background(), suite.user.UserID.String(), service.ReportSubscriptionInput{
+		Frequency: service.ReportFrequencyWeekly,
+		Metrics:   []string{"Views", "clicks", "views"},
+	})
+	require.NoError(suite.T(), err)
+
+	assert.Equal(suite.T(), []string{"views", "clicks"}, subscription.Metrics)
+	require.Len(suite.T(), suite.repo.created, 1)
+	assert.Len(suite.T(), suite.repo.created[0].UnsubscribeToken, 64)
+	assert.True(suite.T(), suite.repo.created[0].NextSendAt.After(time.Now().Add(6*24*time.Hour)))
+}
+
+func (suite *ReportSubscriptionTestSuite) TestSendDueReports() {
+	dueAt := time.Now().Add(-time.Minute)
+	weekly := uuid.New()
+	once := uuid.New()
+	suite.repo.due = []*db.ListDueReportSubscriptionsRow{
+		{
+			ReportSubscription: db.ReportSubscription{
+				SubscriptionID: weekly, UserID: suite.user.UserID, Frequency: service.ReportFrequencyWeekly,
+				Metrics: []string{"views"}, UnsubscribeToken: "weekly-token", NextSendAt: dueAt,
+			},
+			Username: suite.user.Username,
+			Email:    suite.user.Email,
+		},
+		{
+			ReportSubscription: db.ReportSubscription{
+				SubscriptionID: once, UserID: suite.user.UserID, Frequency: service.ReportFrequencyOnce,
+				UnsubscribeToken: "once-token", NextSendAt: dueAt,
+			},
+			Username: suite.user.Username,
+			Email:    suite.user.Email,
+		},
+	}
+
+	sent, err := suite.svc.SendDueReports(context.Background())
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 2, sent)
+
+	// The weekly report moves on a week from when it was due; the one-off
+	// report is removed instead
+	require.Len(suite.T(), suite.repo.claimed, 1)
+	assert.Equal(suite.T(), weekly, suite.repo.claimed[0].id)
+	assert.True(suite.T(), suite.repo.claimed[0].nextAt.Equal(dueAt.AddDate(0, 0, 7)))
+	assert.Equal(suite.T(), []uuid.UUID{once}, suite.repo.deleted)
+
+	// Only the one-off report asked for every metric, top items included
+	assert.Equal(suite.T(), 1, suite.analytics.dashboards)
+
+	// A one-off report already taken by another run is not sent again
+	suite.repo.due = suite.repo.due[1:]
+	sent, err = suite.svc.SendDueReports(context.Background())
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 0, sent)
+}
+
+func (suite *ReportSubscriptionTestSuite) assertErrorCode(code string, err error) {
+	var appErr *errors.AppError
+	require.True(suite.T(), stderrors.As(err, &appErr), "expected an AppError, got %v", err)
+	assert.Equal(suite.T(), code, appErr.Code)
+}
+
+func TestReportSubscriptionTestSuite(t *testing.T) {
+	suite.Run(t, new(ReportSubscriptionTestSuite))
+}
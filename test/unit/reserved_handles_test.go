@@ -0,0 +1,60 @@
+// test/unit/reserved_handles_test.go
+package unit
+
+import (
+	"context"
+	stderrors "errors"
+	"testing"
+
+	"github.com/0xsj/mios.io/log"
+	"github.com/0xsj/mios.io/pkg/errors"
+	"github.com/0xsj/mios.io/service"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReservedHandlesMatching(t *testing.T) {
+	reserved := service.ReservedHandles{
+		Exact:      []string{" Shop "},
+		Substrings: []string{"official"},
+	}
+
+	tests := []struct {
+		handle   string
+		reserved bool
+	}{
+		{"api", true},
+		{"Settings", true},
+		{"login", true},
+		{"apis", false},
+		{"superadmin", true},
+		{"ADMIN_jane", true},
+		{"shop", true},
+		{"shopper", false},
+		{"the-official-one", true},
+		{"jane_doe", false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.reserved, reserved.IsReserved(tt.handle), tt.handle)
+	}
+}
+
+func TestReservedHandlesRejected(t *testing.T) {
+	ctx := context.Background()
+	repo := &takenHandleRepo{}
+	logger := log.Development().WithLayer("ReservedHandlesTest")
+	svc := service.NewUserService(repo, nil, nil, nil, nil, nil, nil, nil, service.UserConfig{
+		ReservedHandles: service.ReservedHandles{Exact: []string{"shop"}},
+	}, logger)
+
+	_, err := svc.UpdateHandle(ctx, uuid.NewString(), "Shop")
+	var appErr *errors.AppError
+	require.True(t, stderrors.As(err, &appErr), err)
+	assert.Equal(t, "VALIDATION_ERROR", appErr.Code)
+
+	available, err := svc.IsHandleAvailable(ctx, "admin")
+	require.NoError(t, err)
+	assert.False(t, available)
+	assert.Zero(t, repo.lookups)
+}
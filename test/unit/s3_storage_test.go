@@ -0,0 +1,163 @@
+// test/unit/s3_storage_test.go
+package unit
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/0xsj/mios.io/log"
+	"github.com/0xsj/mios.io/pkg/errors"
+	"github.com/0xsj/mios.io/pkg/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+const s3TestTimeout = 200 * time.Millisecond
+
+// S3StorageTestSuite points the S3 client at a local server standing in for
+// S3, whose handler each test sets
+type S3StorageTestSuite struct {
+	suite.Suite
+	ctx     context.Context
+	handler http.HandlerFunc
+	storage *storage.S3Storage
+}
+
+func (suite *S3StorageTestSuite) SetupTest() {
+	suite.ctx = context.Background()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		suite.handler(w, r)
+	}))
+	suite.T().Cleanup(server.Close)
+
+	suite.T().Setenv("AWS_ENDPOINT_URL", server.URL)
+	suite.T().Setenv("AWS_EC2_METADATA_DISABLED", "true")
+
+	var err error
+	suite.storage, err = storage.NewS3Storage(storage.S3Config{
+		Region:           "us-east-1",
+		Bucket:           "media",
+		AccessKeyID:      "test",
+		SecretKey:        "test",
+		MaxRetries:       -1,
+		OperationTimeout: s3TestTimeout,
+	}, log.Development().WithLayer("S3StorageTest"))
+	require.NoError(suite.T(), err)
+}
+
+// respondWithError answers every request with an S3 error document
+func (suite *S3StorageTestSuite) respondWithError(status int, code string) {
+	suite.handler = func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/xml")
+		w.WriteHeader(status)
+		fmt.Fprintf(w, `<?xml version="1.0" encoding="UTF-8"?><Error><Code>%s</Code><Message>%s</Message></Error>`, code, code)
+	}
+}
+
+func (suite *S3StorageTestSuite) assertStatus(err error, status int) {
+	var appErr *errors.AppError
+	require.True(suite.T(), stderrors.As(err, &appErr), err)
+	assert.Equal(suite.T(), status, appErr.Status)
+}
+
+func (suite *S3StorageTestSuite) TestMissingKeyIsNotFound() {
+	suite.respondWithError(http.StatusNotFound, "NoSuchKey")
+
+	_, err := suite.storage.Download(suite.ctx, "missing.png")
+	assert.True(suite.T(), errors.IsNotFound(err), err)
+}
+
+func (suite *S3StorageTestSuite) TestThrottlingIsRetryable() {
+	suite.respondWithError(http.StatusServiceUnavailable, "SlowDown")
+
+	_, err := suite.storage.Download(suite.ctx, "busy.png")
+	suite.assertStatus(err, http.StatusServiceUnavailable)
+	err = suite.storage.Delete(suite.ctx, "busy.png")
+	suite.assertStatus(err, http.StatusServiceUnavailable)
+}
+
+func (suite *S3StorageTestSuite) TestTimeoutIsRetryable() {
+	suite.handler = func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * s3TestTimeout):
+		}
+	}
+
+	_, err := suite.storage.Download(suite.ctx, "slow.png")
+	suite.assertStatus(err, http.StatusServiceUnavailable)
+	_, err = suite.storage.Stat(suite.ctx, "slow.png")
+	suite.assertStatus(err, http.StatusServiceUnavailable)
+}
+
+func (suite *S3StorageTestSuite) TestAccessDeniedIsPermanent() {
+	suite.respondWithError(http.StatusForbidden, "AccessDenied")
+
+	_, err := suite.storage.Download(suite.ctx, "secret.png")
+	var appErr *errors.AppError
+	require.True(suite.T(), stderrors.As(err, &appErr), err)
+	assert.Equal(suite.T(), "EXTERNAL_SERVICE_ERROR", appErr.Code)
+	assert.NotEqual(suite.T(), http.StatusServiceUnavailable, appErr.Status)
+}
+
+// TestDownloadOutlivesTheOperationTimeout streams a body that takes longer
+// than the timeout: only getting the response is bounded by it
+func (suite *S3StorageTestSuite) TestDownloadOutlivesTheOperationTimeout() {
+	suite.handler = func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", "10")
+		w.WriteHeader(http.StatusOK)
+		io.WriteString(w, "first")
+		w.(http.Flusher).Flush()
+		time.Sleep(2 * s3TestTimeout)
+		io.WriteString(w, "-last")
+	}
+
+	body, err := suite.storage.Download(suite.ctx, "large.bin")
+	require.NoError(suite.T(), err)
+	defer body.Close()
+
+	data, err := io.ReadAll(body)
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "first-last", string(data))
+}
+
+// TestClosingTheDownloadEndsTheRequest closes a body the server is still
+// sending, which must release the request rather than leave it hanging
+func (suite *S3StorageTestSuite) TestClosingTheDownloadEndsTheRequest() {
+	ended := make(chan struct{})
+	suite.handler = func(w http.ResponseWriter, r *http.Request) {
+		defer close(ended)
+		w.Header().Set("Content-Length", "1024")
+		w.WriteHeader(http.StatusOK)
+		io.WriteString(w, "partial")
+		w.(http.Flusher).Flush()
+		select {
+		case <-r.Context().Done():
+		case <-time.After(10 * time.Second):
+		}
+	}
+
+	body, err := suite.storage.Download(suite.ctx, "abandoned.bin")
+	require.NoError(suite.T(), err)
+	buf := make([]byte, 7)
+	_, err = io.ReadFull(body, buf)
+	require.NoError(suite.T(), err)
+	require.NoError(suite.T(), body.Close())
+
+	select {
+	case <-ended:
+	case <-time.After(5 * time.Second):
+		suite.T().Fatal("the request was still open after the body was closed")
+	}
+}
+
+func TestS3StorageTestSuite(t *testing.T) {
+	suite.Run(t, new(S3StorageTestSuite))
+}
@@ -0,0 +1,111 @@
+// test/unit/server_shutdown_test.go
+package unit
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	api "github.com/0xsj/mios.io/api/server"
+	"github.com/0xsj/mios.io/config"
+	"github.com/0xsj/mios.io/log"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type ServerShutdownTestSuite struct {
+	suite.Suite
+	server  *api.Server
+	addr    string
+	started chan struct{}
+	stopped chan error
+}
+
+func (suite *ServerShutdownTestSuite) SetupTest() {
+	gin.SetMode(gin.TestMode)
+	server, err := api.NewServer(config.Config{}, nil, log.Development().WithLayer("ServerShutdownTest"), nil, nil, nil)
+	require.NoError(suite.T(), err)
+	suite.server = server
+	suite.started = make(chan struct{}, 1)
+
+	server.Router().GET("/slow", func(c *gin.Context) {
+		suite.started <- struct{}{}
+		time.Sleep(200 * time.Millisecond)
+		c.String(http.StatusOK, "done")
+	})
+	server.Router().GET("/stream", func(c *gin.Context) {
+		suite.started <- struct{}{}
+		<-c.Request.Context().Done()
+	})
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(suite.T(), err)
+	suite.addr = listener.Addr().String()
+	listener.Close()
+
+	suite.stopped = make(chan error, 1)
+	go func() {
+		suite.stopped <- server.Start(suite.addr)
+	}()
+	require.Eventually(suite.T(), func() bool {
+		conn, err := net.Dial("tcp", suite.addr)
+		if err == nil {
+			conn.Close()
+		}
+		return err == nil
+	}, 2*time.Second, 10*time.Millisecond)
+}
+
+// get sends a request in the background and delivers its status
+func (suite *ServerShutdownTestSuite) get(path string) <-chan int {
+	status := make(chan int, 1)
+	go func() {
+		resp, err := http.Get("http://" + suite.addr + path)
+		if err != nil {
+			status <- 0
+			return
+		}
+		resp.Body.Close()
+		status <- resp.StatusCode
+	}()
+	<-suite.started
+	return status
+}
+
+func (suite *ServerShutdownTestSuite) TestDrainsInFlightRequests() {
+	status := suite.get("/slow")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	require.NoError(suite.T(), suite.server.Shutdown(ctx))
+
+	assert.Equal(suite.T(), http.StatusOK, <-status, "the in-flight request finished")
+	assert.ErrorIs(suite.T(), <-suite.stopped, http.ErrServerClosed)
+
+	_, err := http.Get("http://" + suite.addr + "/slow")
+	assert.Error(suite.T(), err, "new connections are refused")
+}
+
+func (suite *ServerShutdownTestSuite) TestClosesConnectionsAtDeadline() {
+	status := suite.get("/stream")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	assert.Error(suite.T(), suite.server.Shutdown(ctx))
+	assert.Less(suite.T(), time.Since(start), time.Second)
+
+	select {
+	case <-status:
+	case <-time.After(time.Second):
+		suite.T().Fatal("the open stream was not closed")
+	}
+}
+
+func TestServerShutdownTestSuite(t *testing.T) {
+	suite.Run(t, new(ServerShutdownTestSuite))
+}
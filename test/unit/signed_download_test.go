@@ -0,0 +1,117 @@
+// test/unit/signed_download_test.go
+package unit
+
+import (
+	"context"
+	stderrors "errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	db "github.com/0xsj/mios.io/db/sqlc"
+	"github.com/0xsj/mios.io/log"
+	"github.com/0xsj/mios.io/pkg/errors"
+	"github.com/0xsj/mios.io/pkg/storage"
+	"github.com/0xsj/mios.io/service"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+const downloadBaseURL = "http://origin.test/downloads"
+
+type SignedDownloadTestSuite struct {
+	suite.Suite
+	dir      string
+	owner    uuid.UUID
+	stranger uuid.UUID
+	local    *storage.LocalStorage
+	handler  http.Handler
+	svc      service.FileService
+}
+
+func (suite *SignedDownloadTestSuite) SetupTest() {
+	suite.dir = suite.T().TempDir()
+	suite.owner, suite.stranger = uuid.New(), uuid.New()
+	require.NoError(suite.T(), os.MkdirAll(filepath.Join(suite.dir, "general"), 0755))
+	require.NoError(suite.T(), os.WriteFile(filepath.Join(suite.dir, "general", "notes.txt"), []byte("private notes"), 0644))
+
+	logger := log.Development().WithLayer("SignedDownloadTest")
+	suite.local = storage.NewLocalStorage(suite.dir, avatarBaseURL, logger).
+		WithSignedDownloads(storage.NewURLSigner("test-secret"), downloadBaseURL)
+	suite.handler = suite.local.DownloadHandler("/downloads")
+
+	isAdmin := false
+	users := &fileUsersRepo{users: map[uuid.UUID]*db.User{
+		suite.owner:    {UserID: suite.owner, IsAdmin: &isAdmin},
+		suite.stranger: {UserID: suite.stranger, IsAdmin: &isAdmin},
+	}}
+	files := newMemoryFileRepo(&db.File{FileKey: "general/notes.txt", UserID: suite.owner, Category: "general"})
+	suite.svc = service.NewFileService(suite.local, nil, files, users, service.FileServiceConfig{}, logger)
+}
+
+// download requests a signed URL from the download handler
+func (suite *SignedDownloadTestSuite) download(signed string) *httptest.ResponseRecorder {
+	target := strings.TrimPrefix(signed, strings.TrimSuffix(downloadBaseURL, "/downloads"))
+	rec := httptest.NewRecorder()
+	suite.handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+	return rec
+}
+
+func (suite *SignedDownloadTestSuite) TestSignedURLDownloadsFile() {
+	signed, err := suite.svc.GetPresignedDownloadURL(context.Background(), suite.owner.String(), "general/notes.txt", time.Hour)
+	require.NoError(suite.T(), err)
+	assert.True(suite.T(), strings.HasPrefix(signed, downloadBaseURL+"/general/notes.txt?"), signed)
+
+	rec := suite.download(signed)
+	require.Equal(suite.T(), http.StatusOK, rec.Code)
+	body, _ := io.ReadAll(rec.Body)
+	assert.Equal(suite.T(), "private notes", string(body))
+	assert.Equal(suite.T(), "private, no-store", rec.Header().Get("Cache-Control"))
+}
+
+func (suite *SignedDownloadTestSuite) TestTamperedOrExpiredURLsAreRejected() {
+	signed, err := suite.local.GetPresignedDownloadURL(context.Background(), "general/notes.txt", time.Hour)
+	require.NoError(suite.T(), err)
+
+	parsed, err := url.Parse(signed)
+	require.NoError(suite.T(), err)
+	query := parsed.Query()
+	query.Set("expires", "9999999999")
+	parsed.RawQuery = query.Encode()
+	assert.Equal(suite.T(), http.StatusForbidden, suite.download(parsed.String()).Code, "extended expiry")
+
+	other := strings.Replace(signed, "general/notes.txt", "general/other.txt", 1)
+	assert.Equal(suite.T(), http.StatusForbidden, suite.download(other).Code, "different key")
+
+	expired, err := suite.local.GetPresignedDownloadURL(context.Background(), "general/notes.txt", -time.Minute)
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), http.StatusForbidden, suite.download(expired).Code, "expired")
+}
+
+func (suite *SignedDownloadTestSuite) TestExpiryMustBeInRange() {
+	for _, expires := range []time.Duration{30 * time.Second, 8 * 24 * time.Hour} {
+		_, err := suite.svc.GetPresignedDownloadURL(context.Background(), suite.owner.String(), "general/notes.txt", expires)
+		var appErr *errors.AppError
+		require.True(suite.T(), stderrors.As(err, &appErr), err)
+		assert.Equal(suite.T(), "VALIDATION_ERROR", appErr.Code, expires)
+	}
+}
+
+func (suite *SignedDownloadTestSuite) TestPrivateFilesNeedTheirOwner() {
+	_, err := suite.svc.GetPresignedDownloadURL(context.Background(), suite.stranger.String(), "general/notes.txt", time.Hour)
+	var appErr *errors.AppError
+	require.True(suite.T(), stderrors.As(err, &appErr), err)
+	assert.Equal(suite.T(), "FORBIDDEN", appErr.Code)
+}
+
+func TestSignedDownloadTestSuite(t *testing.T) {
+	suite.Run(t, new(SignedDownloadTestSuite))
+}
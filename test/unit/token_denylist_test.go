@@ -0,0 +1,98 @@
+// test/unit/token_denylist_test.go
+package unit
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/0xsj/mios.io/config"
+	"github.com/0xsj/mios.io/log"
+	"github.com/0xsj/mios.io/pkg/redis"
+	"github.com/0xsj/mios.io/pkg/token"
+	"github.com/0xsj/mios.io/service"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+const denylistTokenExpiry = 15 * time.Minute
+
+type TokenDenylistTestSuite struct {
+	suite.Suite
+	ctx      context.Context
+	server   *miniredis.Miniredis
+	users    *sessionUserRepo
+	sessions *sessionStore
+	svc      service.AuthService
+}
+
+func (suite *TokenDenylistTestSuite) SetupTest() {
+	suite.ctx = context.Background()
+	suite.server = miniredis.RunT(suite.T())
+	host, port, err := net.SplitHostPort(suite.server.Addr())
+	require.NoError(suite.T(), err)
+
+	logger := log.Development().WithLayer("TokenDenylistTest")
+	client, err := redis.NewClient(config.Config{RedisHost: host, RedisPort: port}, logger)
+	require.NoError(suite.T(), err)
+	suite.T().Cleanup(func() { client.Close() })
+
+	var auth *sessionAuthRepo
+	suite.users, auth, suite.sessions = newSessionFakes(suite.T())
+	suite.svc = service.NewAuthService(suite.users, auth, nil, nil, suite.sessions, nil, client,
+		"token-denylist-test-secret", denylistTokenExpiry, service.AuthConfig{}, logger, "http://localhost")
+}
+
+// loginClaims signs in and returns the access token with its claims
+func (suite *TokenDenylistTestSuite) loginClaims() (string, *token.Claims) {
+	tokens, err := suite.svc.Login(suite.ctx, service.LoginInput{Email: "jane@example.com", Password: sessionTestPassword})
+	require.NoError(suite.T(), err)
+	claims, err := suite.svc.ValidateToken(suite.ctx, tokens.AccessToken)
+	require.NoError(suite.T(), err)
+	return tokens.AccessToken, claims
+}
+
+func (suite *TokenDenylistTestSuite) logout(claims *token.Claims) {
+	require.NoError(suite.T(), suite.svc.Logout(suite.ctx, suite.users.user.UserID.String(), claims))
+}
+
+func (suite *TokenDenylistTestSuite) TestLoggedOutTokenIsDenied() {
+	accessToken, claims := suite.loginClaims()
+	suite.logout(claims)
+
+	// Reopen the session so only the denylist stands in the way
+	suite.sessions.sessions[uuid.MustParse(claims.SessionID)].RevokedAt = nil
+
+	_, err := suite.svc.ValidateToken(suite.ctx, accessToken)
+	assert.Error(suite.T(), err)
+}
+
+func (suite *TokenDenylistTestSuite) TestEntryLivesAsLongAsTheToken() {
+	_, claims := suite.loginClaims()
+	suite.logout(claims)
+
+	ttl := suite.server.TTL("auth:denylist:" + claims.ID)
+	assert.InDelta(suite.T(), time.Until(claims.ExpiresAt.Time).Seconds(), ttl.Seconds(), 2)
+	assert.LessOrEqual(suite.T(), ttl, denylistTokenExpiry)
+
+	suite.server.FastForward(ttl)
+	assert.False(suite.T(), suite.server.Exists("auth:denylist:"+claims.ID), "the entry goes once the token has expired")
+}
+
+func (suite *TokenDenylistTestSuite) TestOtherSessionsAreUnaffected() {
+	_, laptop := suite.loginClaims()
+	phoneToken, phone := suite.loginClaims()
+	suite.logout(laptop)
+
+	assert.False(suite.T(), suite.server.Exists("auth:denylist:"+phone.ID))
+	_, err := suite.svc.ValidateToken(suite.ctx, phoneToken)
+	assert.NoError(suite.T(), err)
+}
+
+func TestTokenDenylistTestSuite(t *testing.T) {
+	suite.Run(t, new(TokenDenylistTestSuite))
+}
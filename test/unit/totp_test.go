@@ -0,0 +1,60 @@
+// test/unit/totp_test.go
+package unit
+
+import (
+	"encoding/base32"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/0xsj/mios.io/pkg/totp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type TOTPTestSuite struct {
+	suite.Suite
+	secret string
+}
+
+func (suite *TOTPTestSuite) SetupSuite() {
+	// RFC 6238 SHA1 test key
+	suite.secret = base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+}
+
+func (suite *TOTPTestSuite) TestRFCVectors() {
+	vectors := map[int64]string{
+		59:         "287082",
+		1111111109: "081804",
+		1234567890: "005924",
+		2000000000: "279037",
+	}
+
+	for unix, want := range vectors {
+		code, err := totp.Code(suite.secret, time.Unix(unix, 0))
+		require.NoError(suite.T(), err)
+		assert.Equal(suite.T(), want, code, unix)
+	}
+}
+
+func (suite *TOTPTestSuite) TestValidateAllowsSkew() {
+	now := time.Unix(1234567890, 0)
+	previous, err := totp.Code(suite.secret, now.Add(-totp.Period))
+	require.NoError(suite.T(), err)
+
+	assert.True(suite.T(), totp.Validate(previous, suite.secret, now, 1))
+	assert.False(suite.T(), totp.Validate(previous, suite.secret, now, 0))
+	assert.False(suite.T(), totp.Validate("12345", suite.secret, now, 1))
+}
+
+func (suite *TOTPTestSuite) TestURI() {
+	uri := totp.URI("mios.io", "user@example.com", suite.secret)
+	assert.True(suite.T(), strings.HasPrefix(uri, "otpauth://totp/mios.io:user@example.com?"))
+	assert.Contains(suite.T(), uri, "secret="+suite.secret)
+	assert.Contains(suite.T(), uri, "issuer=mios.io")
+}
+
+func TestTOTPTestSuite(t *testing.T) {
+	suite.Run(t, new(TOTPTestSuite))
+}
@@ -0,0 +1,93 @@
+// test/unit/unique_clicks_test.go
+package unit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	db "github.com/0xsj/mios.io/db/sqlc"
+	"github.com/0xsj/mios.io/log"
+	"github.com/0xsj/mios.io/repository"
+	"github.com/0xsj/mios.io/service"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+// uniqueClicksAnalyticsRepo returns fixed counts and keeps the window the
+// service asked for
+type uniqueClicksAnalyticsRepo struct {
+	repository.AnalyticsRepository
+	totalClicks  int64
+	uniqueClicks int64
+	since        time.Time
+	window       time.Duration
+}
+
+func (r *uniqueClicksAnalyticsRepo) GetContentItemClickCount(ctx context.Context, itemID uuid.UUID) (int64, error) {
+	return r.totalClicks, nil
+}
+
+func (r *uniqueClicksAnalyticsRepo) GetContentItemUniqueClickCount(ctx context.Context, itemID uuid.UUID, since time.Time, window time.Duration) (int64, error) {
+	r.since = since
+	r.window = window
+	return r.uniqueClicks, nil
+}
+
+func (r *uniqueClicksAnalyticsRepo) GetItemAnalytics(ctx context.Context, itemID uuid.UUID, limit, offset int) ([]*db.Analytic, error) {
+	return nil, nil
+}
+
+func (r *uniqueClicksAnalyticsRepo) GetItemInteractionBreakdown(ctx context.Context, itemID uuid.UUID) ([]repository.InteractionStats, error) {
+	return nil, nil
+}
+
+type UniqueClicksTestSuite struct {
+	suite.Suite
+	user   *db.User
+	itemID uuid.UUID
+	repo   *uniqueClicksAnalyticsRepo
+}
+
+func (suite *UniqueClicksTestSuite) SetupTest() {
+	suite.user = &db.User{UserID: uuid.New(), Username: "tester"}
+	suite.itemID = uuid.New()
+	suite.repo = &uniqueClicksAnalyticsRepo{totalClicks: 12, uniqueClicks: 5}
+}
+
+func (suite *UniqueClicksTestSuite) newService(config service.AnalyticsConfig) service.AnalyticsService {
+	content := &pinContentRepo{items: map[uuid.UUID]*db.ContentItem{
+		suite.itemID: {ItemID: suite.itemID, UserID: suite.user.UserID},
+	}}
+	return service.NewAnalyticsService(suite.repo, content, &exportUserRepo{user: suite.user}, nil, nil, nil, nil,
+		service.AnalyticsExportConfig{},
+		config,
+		log.Development().WithLayer("UniqueClicksTest"))
+}
+
+func (suite *UniqueClicksTestSuite) TestReportsTotalAndUniqueClicks() {
+	svc := suite.newService(service.AnalyticsConfig{})
+
+	analytics, err := svc.GetContentItemAnalytics(context.Background(), suite.itemID.String(), 1, 10)
+	require.NoError(suite.T(), err)
+
+	assert.Equal(suite.T(), int64(12), analytics.TotalClicks)
+	assert.Equal(suite.T(), int64(5), analytics.UniqueClicks)
+	assert.True(suite.T(), suite.repo.since.IsZero(), "unique clicks cover the same all-time range as the total")
+	assert.Equal(suite.T(), 24*time.Hour, suite.repo.window, "default window is one day")
+}
+
+func (suite *UniqueClicksTestSuite) TestUsesConfiguredWindow() {
+	svc := suite.newService(service.AnalyticsConfig{UniqueClickWindow: time.Hour})
+
+	_, err := svc.GetContentItemAnalytics(context.Background(), suite.itemID.String(), 1, 10)
+	require.NoError(suite.T(), err)
+
+	assert.Equal(suite.T(), time.Hour, suite.repo.window)
+}
+
+func TestUniqueClicksSuite(t *testing.T) {
+	suite.Run(t, new(UniqueClicksTestSuite))
+}
@@ -0,0 +1,75 @@
+// test/unit/user_analytics_test.go
+package unit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	db "github.com/0xsj/mios.io/db/sqlc"
+	"github.com/0xsj/mios.io/log"
+	"github.com/0xsj/mios.io/pkg/ptr"
+	"github.com/0xsj/mios.io/repository"
+	"github.com/0xsj/mios.io/service"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type listingAnalyticsRepo struct {
+	repository.AnalyticsRepository
+	entries []*db.Analytic
+}
+
+func (r *listingAnalyticsRepo) GetUserItemClickCount(ctx context.Context, userID uuid.UUID, includeBots bool) (int64, error) {
+	return int64(len(r.entries)), nil
+}
+
+func (r *listingAnalyticsRepo) GetUserAnalytics(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*db.Analytic, error) {
+	return r.entries, nil
+}
+
+type UserAnalyticsTestSuite struct {
+	suite.Suite
+	user *db.User
+}
+
+func (suite *UserAnalyticsTestSuite) SetupTest() {
+	suite.user = &db.User{UserID: uuid.New(), Username: "listing"}
+}
+
+func (suite *UserAnalyticsTestSuite) TestNullPageViewIsTreatedAsClick() {
+	clickedAt := time.Now()
+	repo := &listingAnalyticsRepo{entries: []*db.Analytic{
+		{
+			AnalyticsID:     uuid.New(),
+			ItemID:          uuid.New(),
+			UserID:          suite.user.UserID,
+			ClickedAt:       &clickedAt,
+			InteractionType: "click",
+		},
+		{
+			AnalyticsID: uuid.New(),
+			UserID:      suite.user.UserID,
+			PageView:    ptr.Bool(true),
+		},
+	}}
+	svc := service.NewAnalyticsService(repo, nil, &exportUserRepo{user: suite.user}, nil, nil, nil, nil,
+		service.AnalyticsExportConfig{},
+		service.AnalyticsConfig{},
+		log.Development().WithLayer("UserAnalyticsTest"))
+
+	result, err := svc.GetUserAnalytics(context.Background(), suite.user.UserID.String(), 1, 10)
+	require.NoError(suite.T(), err)
+	require.Len(suite.T(), result.ClickData, 2)
+
+	assert.False(suite.T(), result.ClickData[0].PageView)
+	assert.Equal(suite.T(), "click", result.ClickData[0].InteractionType)
+	assert.Equal(suite.T(), clickedAt.Format(time.RFC3339), result.ClickData[0].ClickedAt)
+	assert.True(suite.T(), result.ClickData[1].PageView)
+}
+
+func TestUserAnalyticsTestSuite(t *testing.T) {
+	suite.Run(t, new(UserAnalyticsTestSuite))
+}
@@ -0,0 +1,142 @@
+// test/unit/user_data_export_test.go
+package unit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	db "github.com/0xsj/mios.io/db/sqlc"
+	"github.com/0xsj/mios.io/log"
+	"github.com/0xsj/mios.io/pkg/errors"
+	"github.com/0xsj/mios.io/pkg/storage"
+	"github.com/0xsj/mios.io/repository"
+	"github.com/0xsj/mios.io/service"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+// exportAuthRepo serves one auth record, or none for OAuth-only users
+type exportAuthRepo struct {
+	repository.AuthRepository
+	auth *db.Auth
+}
+
+func (r *exportAuthRepo) GetAuthByUserID(ctx context.Context, userID uuid.UUID) (*db.Auth, error) {
+	if r.auth == nil {
+		return nil, errors.NewNotFoundError("Auth record not found", nil)
+	}
+	return r.auth, nil
+}
+
+type UserDataExportTestSuite struct {
+	suite.Suite
+	ctx       context.Context
+	user      *db.User
+	auth      *exportAuthRepo
+	analytics *exportAnalyticsRepo
+	svc       service.UserService
+}
+
+func (suite *UserDataExportTestSuite) SetupTest() {
+	suite.ctx = context.Background()
+	suite.user = &db.User{UserID: uuid.New(), Handle: "jane", Email: "jane@example.com"}
+
+	passwordHash, secret := "hashed-password", "totp-secret"
+	verified := true
+	suite.auth = &exportAuthRepo{auth: &db.Auth{
+		UserID:          suite.user.UserID,
+		PasswordHash:    &passwordHash,
+		IsEmailVerified: &verified,
+		TotpSecret:      &secret,
+		TotpEnabled:     true,
+	}}
+
+	suite.analytics = &exportAnalyticsRepo{}
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 3; i++ {
+		clickedAt := base.Add(time.Duration(i) * time.Minute)
+		suite.analytics.rows = append(suite.analytics.rows, &db.ListUserAnalyticsForExportRow{
+			Analytic: db.Analytic{AnalyticsID: uuid.New(), UserID: suite.user.UserID, ClickedAt: &clickedAt},
+		})
+	}
+
+	createdAt := base
+	files := newMemoryFileRepo(&db.File{
+		FileKey: "avatars/jane.png", UserID: suite.user.UserID, Category: "avatar",
+		Size: 10, ContentType: "image/png", CreatedAt: &createdAt,
+	})
+	items := &orderContentRepo{items: []*db.ContentItem{
+		{ItemID: uuid.New(), UserID: suite.user.UserID, ContentType: "link"},
+		{ItemID: uuid.New(), UserID: suite.user.UserID, ContentType: "text"},
+	}}
+
+	logger := log.Development().WithLayer("UserDataExportTest")
+	fileService := service.NewFileService(storage.NewLocalStorage(suite.T().TempDir(), avatarBaseURL, logger), nil, files, nil, service.FileServiceConfig{}, logger)
+	suite.svc = service.NewUserService(&exportUserRepo{user: suite.user}, suite.auth, nil, nil, nil, items, suite.analytics, fileService, service.UserConfig{}, logger)
+}
+
+func (suite *UserDataExportTestSuite) decode() map[string]json.RawMessage {
+	export, err := suite.svc.ExportUserData(suite.ctx, suite.user.UserID.String())
+	require.NoError(suite.T(), err)
+
+	var buf bytes.Buffer
+	require.NoError(suite.T(), export.WriteJSON(suite.ctx, &buf))
+
+	var doc map[string]json.RawMessage
+	require.NoError(suite.T(), json.Unmarshal(buf.Bytes(), &doc), buf.String())
+	return doc
+}
+
+func (suite *UserDataExportTestSuite) TestBundlesEverything() {
+	doc := suite.decode()
+
+	var user service.UserDTO
+	require.NoError(suite.T(), json.Unmarshal(doc["user"], &user))
+	assert.Equal(suite.T(), "jane", user.Handle)
+
+	var items, files, analytics []map[string]any
+	require.NoError(suite.T(), json.Unmarshal(doc["content_items"], &items))
+	require.NoError(suite.T(), json.Unmarshal(doc["files"], &files))
+	require.NoError(suite.T(), json.Unmarshal(doc["analytics"], &analytics))
+	assert.Len(suite.T(), items, 2)
+	require.Len(suite.T(), files, 1)
+	assert.Equal(suite.T(), "avatars/jane.png", files[0]["key"])
+	assert.Len(suite.T(), analytics, 3)
+
+	var auth service.AuthMetadataDTO
+	require.NoError(suite.T(), json.Unmarshal(doc["auth"], &auth))
+	assert.True(suite.T(), auth.EmailVerified)
+	assert.True(suite.T(), auth.TwoFactorEnabled)
+	assert.NotContains(suite.T(), string(doc["auth"]), "hashed-password")
+	assert.NotContains(suite.T(), string(doc["auth"]), "totp-secret")
+}
+
+func (suite *UserDataExportTestSuite) TestStreamsAnalyticsInBatches() {
+	for i := 0; i < 2500; i++ {
+		clickedAt := time.Date(2025, 2, 1, 0, 0, i, 0, time.UTC)
+		suite.analytics.rows = append(suite.analytics.rows, &db.ListUserAnalyticsForExportRow{
+			Analytic: db.Analytic{AnalyticsID: uuid.New(), UserID: suite.user.UserID, ClickedAt: &clickedAt},
+		})
+	}
+
+	var analytics []map[string]any
+	require.NoError(suite.T(), json.Unmarshal(suite.decode()["analytics"], &analytics))
+	assert.Len(suite.T(), analytics, 2503)
+	assert.Len(suite.T(), suite.analytics.pages, 3)
+}
+
+func (suite *UserDataExportTestSuite) TestOmitsMissingAuthRecord() {
+	suite.auth.auth = nil
+	doc := suite.decode()
+	assert.NotContains(suite.T(), doc, "auth")
+	assert.Contains(suite.T(), doc, "user")
+}
+
+func TestUserDataExportTestSuite(t *testing.T) {
+	suite.Run(t, new(UserDataExportTestSuite))
+}
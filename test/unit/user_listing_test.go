@@ -0,0 +1,151 @@
+// test/unit/user_listing_test.go
+package unit
+
+import (
+	"context"
+	stderrors "errors"
+	"strings"
+	"testing"
+
+	db "github.com/0xsj/mios.io/db/sqlc"
+	"github.com/0xsj/mios.io/log"
+	"github.com/0xsj/mios.io/pkg/errors"
+	"github.com/0xsj/mios.io/repository"
+	"github.com/0xsj/mios.io/service"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+// listingUserRepo filters its users in memory the way the ListUsers query
+// does, minus the LIKE escaping
+type listingUserRepo struct {
+	repository.UserRepository
+	users   []*db.User
+	filters []repository.UserFilter
+}
+
+func (r *listingUserRepo) matching(filter repository.UserFilter) []*db.User {
+	r.filters = append(r.filters, filter)
+	flag := func(want, got *bool) bool {
+		return want == nil || (got != nil && *got == *want)
+	}
+
+	var matched []*db.User
+	for _, user := range r.users {
+		search := strings.ToLower(filter.Search)
+		if search != "" &&
+			!strings.Contains(strings.ToLower(user.Username), search) &&
+			!strings.Contains(strings.ToLower(user.Email), search) {
+			continue
+		}
+		if flag(filter.IsPremium, user.IsPremium) && flag(filter.IsAdmin, user.IsAdmin) {
+			matched = append(matched, user)
+		}
+	}
+	return matched
+}
+
+func (r *listingUserRepo) ListUsers(ctx context.Context, filter repository.UserFilter, limit, offset int) ([]*db.User, error) {
+	matched := r.matching(filter)
+	if offset >= len(matched) {
+		return nil, nil
+	}
+	end := offset + limit
+	if end > len(matched) {
+		end = len(matched)
+	}
+	return matched[offset:end], nil
+}
+
+func (r *listingUserRepo) CountUsers(ctx context.Context, filter repository.UserFilter) (int64, error) {
+	return int64(len(r.matching(filter))), nil
+}
+
+type UserListingTestSuite struct {
+	suite.Suite
+	repo *listingUserRepo
+	svc  service.UserService
+}
+
+func (suite *UserListingTestSuite) SetupTest() {
+	premium, free := true, false
+	user := func(username string, isPremium *bool) *db.User {
+		return &db.User{UserID: uuid.New(), Username: username, Email: username + "@example.com", IsPremium: isPremium, IsAdmin: &free}
+	}
+
+	suite.repo = &listingUserRepo{users: []*db.User{
+		user("alice", &premium),
+		user("bob", &free),
+		user("carol", &premium),
+		user("alicia", &free),
+	}}
+	logger := log.Development().WithLayer("UserListingTest")
+	suite.svc = service.NewUserService(suite.repo, nil, nil, nil, nil, nil, nil, nil, service.UserConfig{}, logger)
+}
+
+func usernames(list *service.UserListDTO) []string {
+	names := make([]string, len(list.Users))
+	for i, user := range list.Users {
+		names[i] = user.Username
+	}
+	return names
+}
+
+func (suite *UserListingTestSuite) TestSearchesAndFilters() {
+	ctx := context.Background()
+
+	list, err := suite.svc.ListUsers(ctx, service.ListUsersInput{Search: "  ALI "})
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), []string{"alice", "alicia"}, usernames(list))
+	assert.Equal(suite.T(), int64(2), list.TotalCount)
+	assert.Equal(suite.T(), "ALI", suite.repo.filters[0].Search)
+
+	premium := true
+	list, err = suite.svc.ListUsers(ctx, service.ListUsersInput{IsPremium: &premium})
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), []string{"alice", "carol"}, usernames(list))
+
+	list, err = suite.svc.ListUsers(ctx, service.ListUsersInput{Search: "ali", IsPremium: &premium})
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), []string{"alice"}, usernames(list))
+
+	unverified := false
+	_, err = suite.svc.ListUsers(ctx, service.ListUsersInput{Verified: &unverified})
+	require.NoError(suite.T(), err)
+	last := suite.repo.filters[len(suite.repo.filters)-1]
+	require.NotNil(suite.T(), last.Verified)
+	assert.False(suite.T(), *last.Verified)
+}
+
+func (suite *UserListingTestSuite) TestPaginates() {
+	ctx := context.Background()
+
+	list, err := suite.svc.ListUsers(ctx, service.ListUsersInput{Page: 2, PageSize: 3})
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), []string{"alicia"}, usernames(list))
+	assert.Equal(suite.T(), int64(4), list.TotalCount)
+	assert.Equal(suite.T(), 2, list.TotalPages)
+
+	list, err = suite.svc.ListUsers(ctx, service.ListUsersInput{Page: -1, PageSize: 1000})
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 1, list.Page)
+	assert.Equal(suite.T(), 100, list.PageSize)
+	assert.Len(suite.T(), list.Users, 4)
+
+	list, err = suite.svc.ListUsers(ctx, service.ListUsersInput{})
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 20, list.PageSize)
+}
+
+func (suite *UserListingTestSuite) TestRejectsLongSearch() {
+	_, err := suite.svc.ListUsers(context.Background(), service.ListUsersInput{Search: strings.Repeat("a", 101)})
+	var appErr *errors.AppError
+	require.True(suite.T(), stderrors.As(err, &appErr), err)
+	assert.Equal(suite.T(), "VALIDATION_ERROR", appErr.Code)
+}
+
+func TestUserListingTestSuite(t *testing.T) {
+	suite.Run(t, new(UserListingTestSuite))
+}